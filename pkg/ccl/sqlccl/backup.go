@@ -377,7 +377,7 @@ func makeBackupDescriptor(
 
 	for _, desc := range sqlDescs {
 		if dbDesc := desc.GetDatabase(); dbDesc != nil {
-			if err := p.CheckPrivilege(dbDesc, privilege.SELECT); err != nil {
+			if err := p.CheckPrivilege(ctx, dbDesc, privilege.SELECT); err != nil {
 				return BackupDescriptor{}, err
 			}
 		}
@@ -391,7 +391,7 @@ func makeBackupDescriptor(
 	}
 
 	for _, desc := range tables {
-		if err := p.CheckPrivilege(desc, privilege.SELECT); err != nil {
+		if err := p.CheckPrivilege(ctx, desc, privilege.SELECT); err != nil {
 			return BackupDescriptor{}, err
 		}
 	}
@@ -397,14 +397,15 @@ func convertRecord(
 		}
 	}
 
+	parse := parser.Parser{}
+	evalCtx := parser.EvalContext{}
+
 	ri, err := sqlbase.MakeRowInserter(nil /* txn */, tableDesc, nil, /* fkTables */
-		tableDesc.Columns, false /* checkFKs */, &sqlbase.DatumAlloc{})
+		tableDesc.Columns, false /* checkFKs */, &evalCtx, &sqlbase.DatumAlloc{})
 	if err != nil {
 		return errors.Wrap(err, "make row inserter")
 	}
 
-	parse := parser.Parser{}
-	evalCtx := parser.EvalContext{}
 	// Although we don't yet support DEFAULT expressions on visible columns,
 	// we do on hidden columns (which is only the default _rowid one). This
 	// allows those expressions to run.
@@ -161,7 +161,7 @@ func Load(
 			})
 
 			ri, err = sqlbase.MakeRowInserter(nil, tableDesc, nil, tableDesc.Columns,
-				true, &sqlbase.DatumAlloc{})
+				true, &evalCtx, &sqlbase.DatumAlloc{})
 			if err != nil {
 				return BackupDescriptor{}, errors.Wrap(err, "make row inserter")
 			}
@@ -670,6 +670,105 @@ func Example_zone() {
 	// DELETE 0
 }
 
+func Example_zone_partition() {
+	c := newCLITest(cliTestParams{})
+	defer c.cleanup()
+
+	c.RunWithArgs([]string{"sql", "-e", "create database t; create table t.f (x int primary key) " +
+		"partition by list (x) (partition p0 values in (1), partition p1 values in (2))"})
+	c.Run("zone set t.f.p0 --file=./testdata/zone_attrs.yaml")
+	c.Run("zone set t.f.nonexistent --file=./testdata/zone_attrs.yaml")
+	c.Run("zone ls")
+	c.Run("zone get t.f.p0")
+	c.Run("zone get t.f.p1")
+	c.Run("zone get t.f")
+	c.Run("zone set t.f --file=./testdata/zone_range_max_bytes.yaml")
+	c.Run("zone rm t.f")
+	c.Run("zone ls")
+	c.Run("zone rm t.f.p0")
+	c.Run("zone ls")
+	c.Run("zone rm t.f.p0")
+	c.RunWithArgs([]string{"sql", "-e", "create index i on t.f (x) partition by list (x) (partition i1 values in (1))"})
+	c.Run("zone set t.f.i1 --file=./testdata/zone_attrs.yaml")
+	c.Run("zone ls")
+	c.RunWithArgs([]string{"sql", "-e", "drop index t.f@i"})
+	c.Run("zone ls")
+
+	// Output:
+	// sql -e create database t; create table t.f (x int primary key) partition by list (x) (partition p0 values in (1), partition p1 values in (2))
+	// CREATE TABLE
+	// zone set t.f.p0 --file=./testdata/zone_attrs.yaml
+	// range_min_bytes: 1048576
+	// range_max_bytes: 67108864
+	// gc:
+	//   ttlseconds: 90000
+	// num_replicas: 1
+	// constraints: [us-east-1a, ssd]
+	// zone set t.f.nonexistent --file=./testdata/zone_attrs.yaml
+	// partition "nonexistent" does not exist on table "f"
+	// zone ls
+	// .default
+	// t.f.p0
+	// zone get t.f.p0
+	// t.f.p0
+	// range_min_bytes: 1048576
+	// range_max_bytes: 67108864
+	// gc:
+	//   ttlseconds: 90000
+	// num_replicas: 1
+	// constraints: [us-east-1a, ssd]
+	// zone get t.f.p1
+	// .default
+	// range_min_bytes: 1048576
+	// range_max_bytes: 67108864
+	// gc:
+	//   ttlseconds: 90000
+	// num_replicas: 1
+	// constraints: []
+	// zone get t.f
+	// .default
+	// range_min_bytes: 1048576
+	// range_max_bytes: 67108864
+	// gc:
+	//   ttlseconds: 90000
+	// num_replicas: 1
+	// constraints: []
+	// zone set t.f --file=./testdata/zone_range_max_bytes.yaml
+	// range_min_bytes: 1048576
+	// range_max_bytes: 134217728
+	// gc:
+	//   ttlseconds: 90000
+	// num_replicas: 3
+	// constraints: []
+	// zone rm t.f
+	// DELETE 1
+	// zone ls
+	// .default
+	// t.f.p0
+	// zone rm t.f.p0
+	// DELETE 1
+	// zone ls
+	// .default
+	// zone rm t.f.p0
+	// DELETE 0
+	// sql -e create index i on t.f (x) partition by list (x) (partition i1 values in (1))
+	// CREATE INDEX
+	// zone set t.f.i1 --file=./testdata/zone_attrs.yaml
+	// range_min_bytes: 1048576
+	// range_max_bytes: 67108864
+	// gc:
+	//   ttlseconds: 90000
+	// num_replicas: 1
+	// constraints: [us-east-1a, ssd]
+	// zone ls
+	// .default
+	// t.f.i1
+	// sql -e drop index t.f@i
+	// DROP INDEX
+	// zone ls
+	// .default
+}
+
 func Example_sql() {
 	c := newCLITest(cliTestParams{})
 	defer c.cleanup()
@@ -750,7 +849,7 @@ func Example_sql() {
 	// sql -e copy t.f from stdin
 	// woops! COPY has confused this client! Suggestion: use 'psql' for COPY
 	// user ls --echo-sql
-	// > SELECT username FROM system.users
+	// > SELECT username FROM system.users WHERE "isRole" = false
 	// 0 rows
 	// username
 }
@@ -1711,14 +1810,20 @@ writing ` + os.DevNull + `
   debug/nodes/1/ranges/14
   debug/nodes/1/ranges/15
   debug/nodes/1/ranges/16
+  debug/nodes/1/ranges/17
+  debug/nodes/1/ranges/18
+  debug/nodes/1/ranges/19
   debug/schema/system@details
   debug/schema/system/descriptor
   debug/schema/system/eventlog
+  debug/schema/system/functions
   debug/schema/system/jobs
   debug/schema/system/lease
   debug/schema/system/namespace
   debug/schema/system/rangelog
+  debug/schema/system/role_members
   debug/schema/system/settings
+  debug/schema/system/table_statistics
   debug/schema/system/ui
   debug/schema/system/users
   debug/schema/system/web_sessions
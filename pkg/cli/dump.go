@@ -107,7 +107,9 @@ func runDump(cmd *cobra.Command, args []string) error {
 	}
 	if dumpCtx.dumpMode != dumpSchemaOnly {
 		for _, md := range mds {
-			if md.isView {
+			// Views have no data, and the values of sequences are not
+			// stored as rows and cannot be dumped this way.
+			if md.descriptorType != "table" {
 				continue
 			}
 			if err := dumpTableData(w, conn, ts, md); err != nil {
@@ -142,7 +144,8 @@ type tableMetadata struct {
 	columnTypes  map[string]string
 	createStmt   string
 	dependsOn    []int64
-	isView       bool
+	// descriptorType is one of "table", "view" or "sequence".
+	descriptorType string
 }
 
 // getDumpMetadata retrieves the table information for the specified table(s).
@@ -313,7 +316,7 @@ func getMetadataForTable(
 	}
 
 	vals, err = conn.QueryRow(fmt.Sprintf(`
-		SELECT create_statement, descriptor_type = 'view'
+		SELECT create_statement, descriptor_type
 		FROM %s.crdb_internal.create_statements
 		AS OF SYSTEM TIME '%s'
 		WHERE descriptor_name = $1
@@ -323,7 +326,7 @@ func getMetadataForTable(
 		return tableMetadata{}, err
 	}
 	create := vals[0].(string)
-	descType := vals[1].(bool)
+	descType := vals[1].(string)
 
 	rows, err = conn.Query(fmt.Sprintf(`
 		SELECT dependson_id
@@ -351,15 +354,15 @@ func getMetadataForTable(
 	}
 
 	return tableMetadata{
-		ID:           tableID,
-		name:         name,
-		numIndexCols: numIndexCols,
-		idxColNames:  idxColNames.String(),
-		columnNames:  colnames.String(),
-		columnTypes:  coltypes,
-		createStmt:   create,
-		dependsOn:    refs,
-		isView:       descType,
+		ID:             tableID,
+		name:           name,
+		numIndexCols:   numIndexCols,
+		idxColNames:    idxColNames.String(),
+		columnNames:    colnames.String(),
+		columnTypes:    coltypes,
+		createStmt:     create,
+		dependsOn:      refs,
+		descriptorType: descType,
 	}, nil
 }
 
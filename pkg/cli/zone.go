@@ -110,16 +110,60 @@ func queryZone(conn *sqlConn, id sqlbase.ID) (config.ZoneConfig, bool, error) {
 	return zone, true, unmarshalProto(vals[0], &zone)
 }
 
+// queryZonePath returns the zone config that applies to the last element of
+// path, along with the ID of the object it was found on. Zone configs which
+// only store the subzones of a table are skipped.
 func queryZonePath(conn *sqlConn, path []sqlbase.ID) (sqlbase.ID, config.ZoneConfig, error) {
 	for i := len(path) - 1; i >= 0; i-- {
 		zone, found, err := queryZone(conn, path[i])
-		if err != nil || found {
+		if err != nil || (found && !zone.IsSubzonePlaceholder()) {
 			return path[i], zone, err
 		}
 	}
 	return 0, config.ZoneConfig{}, nil
 }
 
+func queryTableDescriptor(conn *sqlConn, id sqlbase.ID) (*sqlbase.TableDescriptor, error) {
+	rows, err := makeQuery(`SELECT descriptor FROM system.descriptor WHERE id = $1`, id)(conn)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	if len(rows.Columns()) != 1 {
+		return nil, fmt.Errorf("unexpected result columns: %d", len(rows.Columns()))
+	}
+	vals := make([]driver.Value, 1)
+	if err := rows.Next(vals); err != nil {
+		return nil, err
+	}
+	desc := &sqlbase.Descriptor{}
+	if err := unmarshalProto(vals[0], desc); err != nil {
+		return nil, err
+	}
+	tableDesc := desc.GetTable()
+	if tableDesc == nil {
+		return nil, fmt.Errorf("%s is not a table", desc.GetName())
+	}
+	return tableDesc, nil
+}
+
+// queryPartitionIndex returns the descriptor of the table with the given ID
+// and the ID of its index containing the named partition.
+func queryPartitionIndex(
+	conn *sqlConn, id sqlbase.ID, partition string,
+) (*sqlbase.TableDescriptor, sqlbase.IndexID, error) {
+	tableDesc, err := queryTableDescriptor(conn, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	index := tableDesc.FindIndexByPartitionName(partition)
+	if index == nil {
+		return nil, 0, fmt.Errorf("partition %q does not exist on table %q", partition, tableDesc.Name)
+	}
+	return tableDesc, index.ID, nil
+}
+
 func queryDescriptors(conn *sqlConn) (map[sqlbase.ID]*sqlbase.Descriptor, error) {
 	rows, err := makeQuery(`SELECT descriptor FROM system.descriptor`)(conn)
 	if err != nil {
@@ -197,39 +241,45 @@ func queryDescriptorIDPath(conn *sqlConn, names []string) ([]sqlbase.ID, error)
 	return path, nil
 }
 
-func parseZoneName(s string) ([]string, error) {
+// parseZoneName splits a zone name into the names of the database and table
+// it refers to, and the name of the table's partition, if any.
+func parseZoneName(s string) (names []string, partition string, err error) {
 	switch t := strings.ToLower(s); s {
 	case defaultZoneName, metaZoneName, timeseriesZoneName, systemZoneName:
-		return []string{t}, nil
+		return []string{t}, "", nil
 	}
 
 	// TODO(knz): we are passing a name that might not be escaped correctly.
 	// See #8389.
 	tn, err := parser.ParseTableName(s)
 	if err != nil {
-		return nil, fmt.Errorf("malformed name: %s", s)
+		return nil, "", fmt.Errorf("malformed name: %s", s)
+	}
+	// A name with three parts is parsed as prefix.database.table, but names
+	// database.table.partition here.
+	if tn.PrefixOriginallySpecified {
+		return []string{string(tn.PrefixName), tn.Database()}, tn.Table(), nil
 	}
 	// This is a bit of a hack: "." is not a valid database name.
 	// We use this to detect when a database name was not specified, in
 	// which case we interpret the table name as a database name below.
 	if err := tn.QualifyWithDatabase("."); err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	var names []string
 	if n := tn.Database(); n != "." {
 		names = append(names, n)
 	}
 	names = append(names, tn.Table())
-	return names, nil
+	return names, "", nil
 }
 
 // A getZoneCmd command displays a zone config.
 var getZoneCmd = &cobra.Command{
-	Use:   "get [options] <database[.table]>",
+	Use:   "get [options] <database[.table[.partition]]>",
 	Short: "fetches and displays the zone config",
 	Long: `
-Fetches and displays the zone configuration for the specified database or
-table.
+Fetches and displays the zone configuration for the specified database, table
+or partition.
 `,
 	RunE: MaybeDecorateGRPCError(runGetZone),
 }
@@ -241,7 +291,7 @@ func runGetZone(cmd *cobra.Command, args []string) error {
 		return usageAndError(cmd)
 	}
 
-	names, err := parseZoneName(args[0])
+	names, partition, err := parseZoneName(args[0])
 	if err != nil {
 		return err
 	}
@@ -261,6 +311,29 @@ func runGetZone(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if partition != "" {
+		// A partition without a zone config of its own falls back to the zone
+		// config of its table, found below.
+		tableID := path[len(path)-1]
+		_, indexID, err := queryPartitionIndex(conn, tableID, partition)
+		if err != nil {
+			return err
+		}
+		tableZone, _, err := queryZone(conn, tableID)
+		if err != nil {
+			return err
+		}
+		if subzone := tableZone.GetSubzone(uint32(indexID), partition); subzone != nil {
+			fmt.Println(strings.Join(append(names, partition), "."))
+			res, err := yaml.Marshal(subzone.Config)
+			if err != nil {
+				return err
+			}
+			fmt.Print(string(res))
+			return nil
+		}
+	}
+
 	id, zone, err := queryZonePath(conn, path)
 	if err != nil {
 		return err
@@ -326,7 +399,7 @@ func runLsZones(cmd *cobra.Command, args []string) error {
 	// Loop over the zones and determine the name for each based on the name of
 	// the corresponding descriptor.
 	var output []string
-	for id := range zones {
+	for id, zone := range zones {
 		if id == 0 {
 			// We handle the default zone below.
 			continue
@@ -344,7 +417,14 @@ func runLsZones(cmd *cobra.Command, args []string) error {
 			name = parser.Name(dbDesc.GetName()).String() + "."
 		}
 		name += parser.Name(desc.GetName()).String()
-		output = append(output, name)
+		if !zone.IsSubzonePlaceholder() {
+			output = append(output, name)
+		}
+		for _, subzone := range zone.Subzones {
+			if subzone.PartitionName != "" {
+				output = append(output, name+"."+parser.Name(subzone.PartitionName).String())
+			}
+		}
 	}
 
 	for id, zoneName := range specialZonesByID {
@@ -363,10 +443,10 @@ func runLsZones(cmd *cobra.Command, args []string) error {
 
 // A rmZoneCmd command removes a zone config.
 var rmZoneCmd = &cobra.Command{
-	Use:   "rm [options] <database[.table]>",
+	Use:   "rm [options] <database[.table[.partition]]>",
 	Short: "remove a zone config",
 	Long: `
-Remove an existing zone config for the specified database or table.
+Remove an existing zone config for the specified database, table or partition.
 `,
 	RunE: MaybeDecorateGRPCError(runRmZone),
 }
@@ -376,7 +456,7 @@ func runRmZone(cmd *cobra.Command, args []string) error {
 		return usageAndError(cmd)
 	}
 
-	names, err := parseZoneName(args[0])
+	names, partition, err := parseZoneName(args[0])
 	if err != nil {
 		return err
 	}
@@ -401,27 +481,69 @@ func runRmZone(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("unable to remove special zone %s", args[0])
 		}
 
-		if err := runQueryAndFormatResults(conn, os.Stdout,
-			makeQuery(`DELETE FROM system.zones WHERE id=$1`, id)); err != nil {
+		zone, _, err := queryZone(conn, id)
+		if err != nil {
 			return err
 		}
+		if partition == "" && len(zone.Subzones) == 0 {
+			return runQueryAndFormatResults(conn, os.Stdout,
+				makeQuery(`DELETE FROM system.zones WHERE id=$1`, id))
+		}
 
+		// The zone config of a table also stores the zone configs of its
+		// partitions, so removing one of them must preserve the others.
+		deleted := false
+		if partition == "" {
+			if !zone.IsSubzonePlaceholder() {
+				zone = config.ZoneConfig{Subzones: zone.Subzones, SubzoneSpans: zone.SubzoneSpans}
+				deleted = true
+			}
+		} else {
+			tableDesc, indexID, err := queryPartitionIndex(conn, id, partition)
+			if err != nil {
+				return err
+			}
+			if deleted = zone.DeleteSubzone(uint32(indexID), partition); deleted {
+				zone.SubzoneSpans, err = sqlbase.GenerateSubzoneSpans(tableDesc, zone.Subzones)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		if !deleted {
+			fmt.Println("DELETE 0")
+			return nil
+		}
+
+		if zone.NumReplicas == 0 && len(zone.Subzones) == 0 {
+			_, _, _, err = runQuery(conn, makeQuery(`DELETE FROM system.zones WHERE id=$1`, id), false)
+		} else {
+			err = upsertZone(conn, id, &zone)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Println("DELETE 1")
 		return nil
 	})
 }
 
 // A setZoneCmd command creates a new or updates an existing zone config.
 var setZoneCmd = &cobra.Command{
-	Use:   "set [options] <database[.table]> -f file.yaml",
+	Use:   "set [options] <database[.table[.partition]]> -f file.yaml",
 	Short: "create or update zone config for object ID",
 	Long: `
-Create or update the zone config for the specified database or table to the
-specified zone-config from the given file ("-" for stdin).
+Create or update the zone config for the specified database, table or
+partition to the specified zone-config from the given file ("-" for stdin).
 
 The zone config format has the following YAML schema:
 
   num_replicas: <num>
   constraints: [comma-separated attribute list]
+  replica_constraints:
+  - num_replicas: <num>
+    constraints: [comma-separated attribute list]
+  lease_preferences: [[comma-separated attribute list], ...]
   range_min_bytes: <size-in-bytes>
   range_max_bytes: <size-in-bytes>
   gc:
@@ -433,8 +555,25 @@ num_replicas: 3
 constraints: [ssd, -mem]
 EOF
 
+To place two replicas of a table in us-east and one in us-west, and to keep
+its leases in us-east, run:
+$ cockroach zone set db.tbl -f - << EOF
+num_replicas: 3
+replica_constraints:
+- num_replicas: 2
+  constraints: [+region=us-east]
+- num_replicas: 1
+  constraints: [+region=us-west]
+lease_preferences: [[+region=us-east]]
+EOF
+
+To store the rows of the "europe" partition of a table in Europe, run:
+$ cockroach zone set db.tbl.europe -f - << EOF
+constraints: [+region=eu]
+EOF
+
 Note that the specified zone config is merged with the existing zone config for
-the database or table.
+the database, table or partition.
 `,
 	RunE: MaybeDecorateGRPCError(runSetZone),
 }
@@ -471,7 +610,7 @@ func runSetZone(cmd *cobra.Command, args []string) error {
 	}
 	defer conn.Close()
 
-	names, err := parseZoneName(args[0])
+	names, partition, err := parseZoneName(args[0])
 	if err != nil {
 		return err
 	}
@@ -491,10 +630,34 @@ func runSetZone(cmd *cobra.Command, args []string) error {
 				"try setting your config on the entire \"system\" database instead")
 		}
 
-		_, zone, err := queryZonePath(conn, path)
+		id := path[len(path)-1]
+		// The zone config of a table stores the zone configs of its
+		// partitions, which are carried over when it is updated.
+		ownZone, _, err := queryZone(conn, id)
 		if err != nil {
 			return err
 		}
+
+		var tableDesc *sqlbase.TableDescriptor
+		var indexID sqlbase.IndexID
+		var subzone *config.Subzone
+		if partition != "" {
+			tableDesc, indexID, err = queryPartitionIndex(conn, id, partition)
+			if err != nil {
+				return err
+			}
+			subzone = ownZone.GetSubzone(uint32(indexID), partition)
+		}
+		var zone config.ZoneConfig
+		if subzone != nil {
+			zone = subzone.Config
+		} else {
+			_, zone, err = queryZonePath(conn, path)
+			if err != nil {
+				return err
+			}
+			zone.Subzones, zone.SubzoneSpans = nil, nil
+		}
 		// Convert it to proto and marshal it again to put into the table. This is a
 		// bit more tedious than taking protos directly, but yaml is a more widely
 		// understood format.
@@ -512,15 +675,21 @@ func runSetZone(cmd *cobra.Command, args []string) error {
 			return err
 		}
 
-		buf, err := protoutil.Marshal(&zone)
-		if err != nil {
-			return fmt.Errorf("unable to parse zone config file %q: %s", args[1], err)
+		if partition != "" {
+			ownZone.SetSubzone(config.Subzone{
+				IndexID:       uint32(indexID),
+				PartitionName: partition,
+				Config:        zone,
+			})
+			ownZone.SubzoneSpans, err = sqlbase.GenerateSubzoneSpans(tableDesc, ownZone.Subzones)
+			if err != nil {
+				return err
+			}
+			err = upsertZone(conn, id, &ownZone)
+		} else {
+			zone.Subzones, zone.SubzoneSpans = ownZone.Subzones, ownZone.SubzoneSpans
+			err = upsertZone(conn, id, &zone)
 		}
-
-		id := path[len(path)-1]
-		_, _, _, err = runQuery(conn, makeQuery(
-			`UPSERT INTO system.zones (id, config) VALUES ($1, $2)`,
-			id, buf), false)
 		if err != nil {
 			return err
 		}
@@ -534,6 +703,17 @@ func runSetZone(cmd *cobra.Command, args []string) error {
 	})
 }
 
+func upsertZone(conn *sqlConn, id sqlbase.ID, zone *config.ZoneConfig) error {
+	buf, err := protoutil.Marshal(zone)
+	if err != nil {
+		return fmt.Errorf("unable to marshal zone config: %s", err)
+	}
+	_, _, _, err = runQuery(conn, makeQuery(
+		`UPSERT INTO system.zones (id, config) VALUES ($1, $2)`,
+		id, buf), false)
+	return err
+}
+
 var zoneCmds = []*cobra.Command{
 	getZoneCmd,
 	lsZonesCmd,
@@ -176,6 +176,114 @@ func (z ZoneConfig) Validate() error {
 		return fmt.Errorf("RangeMinBytes %d is greater than or equal to RangeMaxBytes %d",
 			z.RangeMinBytes, z.RangeMaxBytes)
 	}
+	var constrainedReplicas int32
+	for _, rc := range z.ReplicaConstraints {
+		if rc.NumReplicas <= 0 {
+			return fmt.Errorf("replica constraints %v must apply to at least one replica",
+				rc.Constraints.Constraints)
+		}
+		if len(rc.Constraints.Constraints) == 0 {
+			return fmt.Errorf("replica constraints for %d replicas must include at least one constraint",
+				rc.NumReplicas)
+		}
+		constrainedReplicas += rc.NumReplicas
+	}
+	if constrainedReplicas > z.NumReplicas {
+		return fmt.Errorf("replica constraints apply to %d replicas, but only %d replicas are configured",
+			constrainedReplicas, z.NumReplicas)
+	}
+	for _, lp := range z.LeasePreferences {
+		if len(lp.Constraints) == 0 {
+			return fmt.Errorf("lease preferences must include at least one constraint")
+		}
+	}
+	for _, subzone := range z.Subzones {
+		if len(subzone.Config.Subzones) > 0 || len(subzone.Config.SubzoneSpans) > 0 {
+			return fmt.Errorf("subzone for index %d partition %q may not have subzones of its own",
+				subzone.IndexID, subzone.PartitionName)
+		}
+		if err := subzone.Config.Validate(); err != nil {
+			return errors.Wrapf(err, "subzone for index %d partition %q",
+				subzone.IndexID, subzone.PartitionName)
+		}
+	}
+	for _, span := range z.SubzoneSpans {
+		if span.SubzoneIndex < 0 || int(span.SubzoneIndex) >= len(z.Subzones) {
+			return fmt.Errorf("subzone span [%s, %s) refers to nonexistent subzone %d",
+				span.Key, span.EndKey, span.SubzoneIndex)
+		}
+	}
+	return nil
+}
+
+// IsSubzonePlaceholder returns whether the zone config exists only to store
+// the subzones of a table. The rest of a placeholder's config is inherited
+// from the table's parent. Placeholders are marked by a NumReplicas of zero,
+// which is otherwise invalid.
+func (z *ZoneConfig) IsSubzonePlaceholder() bool {
+	return z.NumReplicas == 0 && len(z.Subzones) > 0
+}
+
+// GetSubzone returns the subzone for the given index and partition, or nil
+// if there is none.
+func (z *ZoneConfig) GetSubzone(indexID uint32, partition string) *Subzone {
+	for i := range z.Subzones {
+		if z.Subzones[i].IndexID == indexID && z.Subzones[i].PartitionName == partition {
+			return &z.Subzones[i]
+		}
+	}
+	return nil
+}
+
+// SetSubzone installs subzone, replacing any existing subzone for the same
+// index and partition. The caller is responsible for regenerating
+// SubzoneSpans when a subzone is added.
+func (z *ZoneConfig) SetSubzone(subzone Subzone) {
+	if existing := z.GetSubzone(subzone.IndexID, subzone.PartitionName); existing != nil {
+		existing.Config = subzone.Config
+		return
+	}
+	z.Subzones = append(z.Subzones, subzone)
+}
+
+// DeleteSubzone removes the subzone for the given index and partition, along
+// with its spans. It returns false if there was no such subzone.
+func (z *ZoneConfig) DeleteSubzone(indexID uint32, partition string) bool {
+	idx := -1
+	for i, subzone := range z.Subzones {
+		if subzone.IndexID == indexID && subzone.PartitionName == partition {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		return false
+	}
+	z.Subzones = append(z.Subzones[:idx], z.Subzones[idx+1:]...)
+
+	spans := z.SubzoneSpans[:0]
+	for _, span := range z.SubzoneSpans {
+		switch {
+		case span.SubzoneIndex == int32(idx):
+			continue
+		case span.SubzoneIndex > int32(idx):
+			span.SubzoneIndex--
+		}
+		spans = append(spans, span)
+	}
+	z.SubzoneSpans = spans
+	return true
+}
+
+// GetSubzoneForKey returns the subzone governing key, or nil if key is not
+// within any of the SubzoneSpans.
+func (z *ZoneConfig) GetSubzoneForKey(key roachpb.RKey) *Subzone {
+	i := sort.Search(len(z.SubzoneSpans), func(i int) bool {
+		return key.Less(roachpb.RKey(z.SubzoneSpans[i].EndKey))
+	})
+	if i < len(z.SubzoneSpans) && !key.Less(roachpb.RKey(z.SubzoneSpans[i].Key)) {
+		return &z.Subzones[z.SubzoneSpans[i].SubzoneIndex]
+	}
 	return nil
 }
 
@@ -373,7 +481,14 @@ func (s SystemConfig) GetZoneConfigForKey(key roachpb.RKey) (ZoneConfig, error)
 		objectID = keys.SystemRangesID
 	}
 
-	return s.getZoneConfigForID(objectID)
+	zone, err := s.getZoneConfigForID(objectID)
+	if err != nil {
+		return ZoneConfig{}, err
+	}
+	if subzone := zone.GetSubzoneForKey(key); subzone != nil {
+		return subzone.Config, nil
+	}
+	return zone, nil
 }
 
 // getZoneConfigForID looks up the zone config for the object (table or database)
@@ -435,7 +550,9 @@ var StaticSplits = []struct {
 // of the system-config tables (i.e. /table/0), and at certain points within the
 // system ranges that come before the system tables. The system-config range is
 // somewhat special in that it can contain multiple SQL tables
-// (/table/0-/table/<max-system-config-desc>) within a single range.
+// (/table/0-/table/<max-system-config-desc>) within a single range. Splits are
+// also required at the boundaries of the spans of a table's subzones, so that
+// each partition can be placed according to its own zone config.
 func (s SystemConfig) ComputeSplitKey(startKey, endKey roachpb.RKey) roachpb.RKey {
 	// Before dealing with splits necessitated by SQL tables, handle all of the
 	// static splits earlier in the keyspace. Note that this list must be kept in
@@ -456,6 +573,12 @@ func (s SystemConfig) ComputeSplitKey(startKey, endKey roachpb.RKey) roachpb.RKe
 
 	// If the above iteration over the static split points didn't decide anything,
 	// the key range must be somewhere in the SQL table part of the keyspace.
+	// Ranges must be split at the boundaries of the subzones of the table
+	// containing startKey before moving on to the next table.
+	if splitKey := s.subzoneSplitKey(startKey, endKey); splitKey != nil {
+		return splitKey
+	}
+
 	startID, ok := ObjectIDForKey(startKey)
 	if !ok || startID <= keys.MaxSystemConfigDescID {
 		// The start key is either:
@@ -517,6 +640,44 @@ func (s SystemConfig) ComputeSplitKey(startKey, endKey roachpb.RKey) roachpb.RKe
 	return findSplitKey(startID, endID)
 }
 
+// subzoneSplitKey returns the first boundary of the subzone spans of the user
+// table containing startKey that lies strictly between startKey and endKey,
+// or nil if there is none.
+func (s SystemConfig) subzoneSplitKey(startKey, endKey roachpb.RKey) roachpb.RKey {
+	id, ok := ObjectIDForKey(startKey)
+	if !ok || id <= keys.MaxSystemConfigDescID {
+		return nil
+	}
+	testingLock.Lock()
+	hook := ZoneConfigHook
+	testingLock.Unlock()
+	if hook == nil {
+		return nil
+	}
+	zone, found, err := hook(s, id)
+	if err != nil {
+		log.Errorf(context.TODO(), "unable to determine zone config for table %d: %s", id, err)
+		return nil
+	}
+	if !found {
+		return nil
+	}
+	// SubzoneSpans are sorted and non-overlapping, so the first boundary after
+	// startKey is the only candidate.
+	for _, span := range zone.SubzoneSpans {
+		for _, key := range []roachpb.RKey{roachpb.RKey(span.Key), roachpb.RKey(span.EndKey)} {
+			if !startKey.Less(key) {
+				continue
+			}
+			if key.Less(endKey) {
+				return key
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
 // NeedsSplit returns whether the range [startKey, endKey) needs a split due
 // to zone configs.
 func (s SystemConfig) NeedsSplit(startKey, endKey roachpb.RKey) bool {
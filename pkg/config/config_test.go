@@ -427,6 +427,97 @@ func TestGetZoneConfigForKey(t *testing.T) {
 	}
 }
 
+// TestSubzones verifies that ranges are split at the boundaries of the spans
+// of a table's subzones and that keys within those spans use the config of
+// their subzone.
+func TestSubzones(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const id = keys.MaxReservedDescID + 1
+	key := func(suffix string) roachpb.RKey {
+		return testutils.MakeKey(keys.MakeTablePrefix(id), roachpb.RKey(suffix))
+	}
+
+	tableZone := config.DefaultZoneConfig()
+	tableZone.NumReplicas = 1
+	subzone0, subzone1 := config.DefaultZoneConfig(), config.DefaultZoneConfig()
+	subzone0.NumReplicas = 5
+	subzone1.NumReplicas = 7
+	tableZone.Subzones = []config.Subzone{
+		{IndexID: 1, PartitionName: "p0", Config: subzone0},
+		{IndexID: 1, PartitionName: "p1", Config: subzone1},
+	}
+	tableZone.SubzoneSpans = []config.SubzoneSpan{
+		{Key: roachpb.Key(key("b")), EndKey: roachpb.Key(key("d")), SubzoneIndex: 0},
+		{Key: roachpb.Key(key("f")), EndKey: roachpb.Key(key("g")), SubzoneIndex: 1},
+	}
+
+	originalZoneConfigHook := config.ZoneConfigHook
+	defer func() {
+		config.ZoneConfigHook = originalZoneConfigHook
+	}()
+	config.ZoneConfigHook = func(_ config.SystemConfig, objectID uint32) (config.ZoneConfig, bool, error) {
+		if objectID == id {
+			return tableZone, true, nil
+		}
+		return config.ZoneConfig{}, false, nil
+	}
+	cfg := config.SystemConfig{}
+
+	splitTestCases := []struct {
+		start, end roachpb.RKey
+		split      roachpb.RKey
+	}{
+		{keys.MakeTablePrefix(id), key("a"), nil},
+		{keys.MakeTablePrefix(id), key("c"), key("b")},
+		{key("b"), key("z"), key("d")},
+		{key("c"), key("e"), key("d")},
+		{key("d"), key("z"), key("f")},
+		{key("e"), key("f"), nil},
+		{key("f"), key("g"), nil},
+		{key("f"), key("h"), key("g")},
+	}
+	for i, tc := range splitTestCases {
+		if splitKey := cfg.ComputeSplitKey(tc.start, tc.end); !splitKey.Equal(tc.split) {
+			t.Errorf("%d: expected split key %v, got %v", i, tc.split, splitKey)
+		}
+	}
+
+	zoneTestCases := []struct {
+		key         roachpb.RKey
+		numReplicas int32
+	}{
+		{keys.MakeTablePrefix(id), 1},
+		{key("a"), 1},
+		{key("b"), 5},
+		{key("c"), 5},
+		{key("d"), 1},
+		{key("f"), 7},
+		{key("g"), 1},
+	}
+	for i, tc := range zoneTestCases {
+		zone, err := cfg.GetZoneConfigForKey(tc.key)
+		if err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+		if zone.NumReplicas != tc.numReplicas {
+			t.Errorf("%d: expected %d replicas, got %d", i, tc.numReplicas, zone.NumReplicas)
+		}
+	}
+
+	tableZone.DeleteSubzone(1, "p0")
+	if zone, err := cfg.GetZoneConfigForKey(key("f")); err != nil {
+		t.Fatal(err)
+	} else if zone.NumReplicas != 7 {
+		t.Errorf("expected 7 replicas after deleting subzone, got %d", zone.NumReplicas)
+	}
+	if zone, err := cfg.GetZoneConfigForKey(key("b")); err != nil {
+		t.Fatal(err)
+	} else if zone.NumReplicas != 1 {
+		t.Errorf("expected 1 replica after deleting subzone, got %d", zone.NumReplicas)
+	}
+}
+
 func TestZoneConfigValidate(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -465,6 +556,91 @@ func TestZoneConfigValidate(t *testing.T) {
 			},
 			"is greater than or equal to RangeMaxBytes",
 		},
+		{
+			config.ZoneConfig{
+				NumReplicas:   3,
+				RangeMaxBytes: config.DefaultZoneConfig().RangeMaxBytes,
+				ReplicaConstraints: []config.ReplicaConstraints{
+					{NumReplicas: 2, Constraints: config.Constraints{Constraints: []config.Constraint{{Value: "a"}}}},
+					{NumReplicas: 1, Constraints: config.Constraints{Constraints: []config.Constraint{{Value: "b"}}}},
+				},
+				LeasePreferences: []config.Constraints{
+					{Constraints: []config.Constraint{{Value: "a"}}},
+				},
+			},
+			"",
+		},
+		{
+			config.ZoneConfig{
+				NumReplicas:   3,
+				RangeMaxBytes: config.DefaultZoneConfig().RangeMaxBytes,
+				ReplicaConstraints: []config.ReplicaConstraints{
+					{NumReplicas: 2, Constraints: config.Constraints{Constraints: []config.Constraint{{Value: "a"}}}},
+					{NumReplicas: 2, Constraints: config.Constraints{Constraints: []config.Constraint{{Value: "b"}}}},
+				},
+			},
+			"replica constraints apply to 4 replicas, but only 3 replicas are configured",
+		},
+		{
+			config.ZoneConfig{
+				NumReplicas:   3,
+				RangeMaxBytes: config.DefaultZoneConfig().RangeMaxBytes,
+				ReplicaConstraints: []config.ReplicaConstraints{
+					{NumReplicas: 0, Constraints: config.Constraints{Constraints: []config.Constraint{{Value: "a"}}}},
+				},
+			},
+			"must apply to at least one replica",
+		},
+		{
+			config.ZoneConfig{
+				NumReplicas:   3,
+				RangeMaxBytes: config.DefaultZoneConfig().RangeMaxBytes,
+				ReplicaConstraints: []config.ReplicaConstraints{
+					{NumReplicas: 1},
+				},
+			},
+			"must include at least one constraint",
+		},
+		{
+			config.ZoneConfig{
+				NumReplicas:      3,
+				RangeMaxBytes:    config.DefaultZoneConfig().RangeMaxBytes,
+				LeasePreferences: []config.Constraints{{}},
+			},
+			"lease preferences must include at least one constraint",
+		},
+		{
+			config.ZoneConfig{
+				NumReplicas:   3,
+				RangeMaxBytes: config.DefaultZoneConfig().RangeMaxBytes,
+				Subzones: []config.Subzone{
+					{IndexID: 1, PartitionName: "p", Config: config.ZoneConfig{NumReplicas: 2}},
+				},
+			},
+			"at least 3 replicas are required",
+		},
+		{
+			config.ZoneConfig{
+				NumReplicas:   3,
+				RangeMaxBytes: config.DefaultZoneConfig().RangeMaxBytes,
+				Subzones: []config.Subzone{
+					{IndexID: 1, PartitionName: "p", Config: config.ZoneConfig{
+						NumReplicas:   3,
+						RangeMaxBytes: config.DefaultZoneConfig().RangeMaxBytes,
+						Subzones:      []config.Subzone{{IndexID: 1}},
+					}},
+				},
+			},
+			"may not have subzones of its own",
+		},
+		{
+			config.ZoneConfig{
+				NumReplicas:   3,
+				RangeMaxBytes: config.DefaultZoneConfig().RangeMaxBytes,
+				SubzoneSpans:  []config.SubzoneSpan{{Key: roachpb.Key("a"), EndKey: roachpb.Key("b")}},
+			},
+			"refers to nonexistent subzone 0",
+		},
 	}
 	for i, c := range testCases {
 		err := c.cfg.Validate()
@@ -504,6 +680,44 @@ func TestZoneConfigMarshalYAML(t *testing.T) {
 				},
 			},
 		},
+		ReplicaConstraints: []config.ReplicaConstraints{
+			{
+				NumReplicas: 1,
+				Constraints: config.Constraints{
+					Constraints: []config.Constraint{
+						{
+							Type:  config.Constraint_REQUIRED,
+							Key:   "region",
+							Value: "us-east",
+						},
+					},
+				},
+			},
+		},
+		LeasePreferences: []config.Constraints{
+			{
+				Constraints: []config.Constraint{
+					{
+						Type:  config.Constraint_REQUIRED,
+						Key:   "region",
+						Value: "us-east",
+					},
+				},
+			},
+			{
+				Constraints: []config.Constraint{
+					{
+						Type:  config.Constraint_REQUIRED,
+						Key:   "region",
+						Value: "us-west",
+					},
+					{
+						Type:  config.Constraint_POSITIVE,
+						Value: "ssd",
+					},
+				},
+			},
+		},
 	}
 
 	expected := `range_min_bytes: 1
@@ -512,6 +726,10 @@ gc:
   ttlseconds: 1
 num_replicas: 1
 constraints: [foo, +duck=foo, -duck=foo]
+replica_constraints:
+- num_replicas: 1
+  constraints: [+region=us-east]
+lease_preferences: [[+region=us-east], [+region=us-west, ssd]]
 `
 
 	body, err := yaml.Marshal(original)
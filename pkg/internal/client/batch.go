@@ -260,6 +260,8 @@ func (b *Batch) fillResults() error {
 			case *roachpb.ImportRequest:
 			case *roachpb.AdminScatterRequest:
 			case *roachpb.AddSSTableRequest:
+			case *roachpb.SubsumeRequest:
+			case *roachpb.RangeStatsRequest:
 			}
 			// Fill up the resume span.
 			if result.Err == nil && reply != nil && reply.Header().ResumeSpan != nil {
@@ -239,6 +239,10 @@ func (r *RSG) GenerateRandomArg(typ parser.Type) string {
 	case parser.TypeUUID:
 		u := uuid.MakeV4()
 		v = fmt.Sprintf(`'%s'`, u)
+	case parser.TypeINet:
+		v = inetArgs[r.Intn(len(inetArgs))]
+	case parser.TypeJSON:
+		v = jsonArgs[r.Intn(len(jsonArgs))]
 	case parser.TypeOid,
 		parser.TypeRegClass,
 		parser.TypeRegNamespace,
@@ -274,3 +278,18 @@ var boolArgs = map[int]string{
 	0: "false",
 	1: "true",
 }
+
+var inetArgs = map[int]string{
+	0: `'0.0.0.0/0'`,
+	1: `'192.168.1.2/24'`,
+	2: `'::1'`,
+	3: `'2001:db8::/32'`,
+}
+
+var jsonArgs = map[int]string{
+	0: `'null'`,
+	1: `'1'`,
+	2: `'"a"'`,
+	3: `'[1, "b", null]'`,
+	4: `'{"a": {"b": [true]}}'`,
+}
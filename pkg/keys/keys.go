@@ -600,6 +600,15 @@ func DecodeTablePrefix(key roachpb.Key) ([]byte, uint64, error) {
 	return encoding.DecodeUvarintAscending(key)
 }
 
+// MakeSequenceKey returns the key used to store the value of the sequence
+// with the given descriptor ID. The key lives in the sequence's table span,
+// laid out as if it were the only row of a primary index with ID 1.
+func MakeSequenceKey(tableID uint32) []byte {
+	key := MakeTablePrefix(tableID)
+	key = encoding.EncodeUvarintAscending(key, 1)
+	return MakeFamilyKey(key, 0)
+}
+
 // MakeFamilyKey returns the key for the family in the given row by appending to
 // the passed key.
 func MakeFamilyKey(key []byte, famID uint32) []byte {
@@ -29,6 +29,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/rpc"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
 	"github.com/cockroachdb/cockroach/pkg/util/grpcutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -431,11 +432,21 @@ func (ds *DistSender) sendSingleRange(
 	// Rearrange the replicas so that those replicas with long common
 	// prefix of attributes end up first. If there's no prefix, this is a
 	// no-op.
-	replicas.OptimizeReplicaOrder(ds.getNodeDescriptor())
+	nodeDesc := ds.getNodeDescriptor()
+	replicas.OptimizeReplicaOrder(nodeDesc)
+
+	// Historical reads which any replica can serve are sent to the closest
+	// replica first; if it cannot serve them after all, they are redirected
+	// to the lease holder. The local replica, if any, stays in front since
+	// its locality matches exactly.
+	followerRead := ds.canSendToFollower(ba)
+	if followerRead && nodeDesc != nil {
+		replicas.SortByLocality(nodeDesc.Locality)
+	}
 
 	// If this request needs to go to a lease holder and we know who that is, move
 	// it to the front.
-	if !(ba.IsReadOnly() && ba.ReadConsistency == roachpb.INCONSISTENT) {
+	if !(ba.IsReadOnly() && ba.ReadConsistency == roachpb.INCONSISTENT) && !followerRead {
 		if leaseHolder, ok := ds.leaseHolderCache.Lookup(ctx, desc.RangeID); ok {
 			if i := replicas.FindReplica(leaseHolder.StoreID); i >= 0 {
 				replicas.MoveToFront(i)
@@ -462,6 +473,18 @@ func (ds *DistSender) sendSingleRange(
 	return br, pErr
 }
 
+// canSendToFollower returns true if the batch is a consistent read at a
+// timestamp old enough to be below the closed timestamp of the range, in
+// which case any replica is expected to be able to serve it.
+func (ds *DistSender) canSendToFollower(ba roachpb.BatchRequest) bool {
+	ts, ok := storagebase.FollowerReadTimestamp(ba)
+	if !ok {
+		return false
+	}
+	threshold := storagebase.FollowerReadThreshold(&ds.st.SV, ds.clock.Now())
+	return threshold != (hlc.Timestamp{}) && !threshold.Less(ts)
+}
+
 // initAndVerifyBatch initializes timestamp-related information and
 // verifies batch constraints before splitting.
 func (ds *DistSender) initAndVerifyBatch(
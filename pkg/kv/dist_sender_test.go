@@ -33,7 +33,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/rpc"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -367,6 +369,72 @@ func TestSendRPCOrder(t *testing.T) {
 	}
 }
 
+// TestCanSendToFollower verifies that only consistent reads below the follower
+// read threshold are sent to the nearest replica rather than the lease holder.
+func TestCanSendToFollower(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+
+	g, _ := makeGossip(t, stopper)
+	manual := hlc.NewManualClock((100 * time.Second).Nanoseconds())
+	st := cluster.MakeTestingClusterSettings()
+	ds := NewDistSender(DistSenderConfig{
+		AmbientCtx:        log.AmbientContext{Tracer: tracing.NewTracer()},
+		Clock:             hlc.NewClock(manual.UnixNano, time.Nanosecond),
+		Settings:          st,
+		RangeDescriptorDB: defaultMockRangeDescriptorDB,
+	}, g)
+
+	ts := func(sec int) hlc.Timestamp {
+		return hlc.Timestamp{WallTime: (time.Duration(sec) * time.Second).Nanoseconds()}
+	}
+	key := roachpb.Key("a")
+	batch := func(args roachpb.Request, h roachpb.Header) roachpb.BatchRequest {
+		ba := roachpb.BatchRequest{Header: h}
+		ba.Add(args)
+		return ba
+	}
+	get := &roachpb.GetRequest{Span: roachpb.Span{Key: key}}
+	put := &roachpb.PutRequest{Span: roachpb.Span{Key: key}}
+	txn := roachpb.MakeTransaction("test", key, 0, 0, ts(50), 0)
+	txn.MaxTimestamp = ts(60)
+
+	// With the default target duration of 30s, reads at or below 55s are
+	// routed to followers.
+	testCases := []struct {
+		ba  roachpb.BatchRequest
+		exp bool
+	}{
+		{batch(get, roachpb.Header{Timestamp: ts(50)}), true},
+		{batch(get, roachpb.Header{Timestamp: ts(55)}), true},
+		{batch(get, roachpb.Header{Timestamp: ts(56)}), false},
+		{batch(get, roachpb.Header{}), false},
+		{batch(get, roachpb.Header{Timestamp: ts(50), ReadConsistency: roachpb.INCONSISTENT}), false},
+		// A transaction may observe values up to its max timestamp.
+		{batch(get, roachpb.Header{Timestamp: ts(50), Txn: &txn}), false},
+		{batch(put, roachpb.Header{Timestamp: ts(50)}), false},
+	}
+
+	for i, c := range testCases {
+		if ds.canSendToFollower(c.ba) {
+			t.Errorf("%d: expected the batch not to be sent to a follower with follower reads disabled", i)
+		}
+	}
+	storagebase.FollowerReadsEnabled.Override(&st.SV, true)
+	for i, c := range testCases {
+		if actual := ds.canSendToFollower(c.ba); actual != c.exp {
+			t.Errorf("%d: expected %t, but found %t", i, c.exp, actual)
+		}
+	}
+	storagebase.ClosedTimestampTargetDuration.Override(&st.SV, 0)
+	for i, c := range testCases {
+		if ds.canSendToFollower(c.ba) {
+			t.Errorf("%d: expected the batch not to be sent to a follower without a closed timestamp", i)
+		}
+	}
+}
+
 type MockRangeDescriptorDB func(roachpb.RKey, bool) ([]roachpb.RangeDescriptor, []roachpb.RangeDescriptor, *roachpb.Error)
 
 func (mdb MockRangeDescriptorDB) RangeLookup(
@@ -565,6 +633,215 @@ func TestRetryOnNotLeaseHolderError(t *testing.T) {
 	}
 }
 
+// followerReadTransport is a mock transport which tries the replicas in the
+// order it was given, honoring MoveToFront, and records the replicas it sent
+// to. All replicas but the lease holder reject the batch.
+type followerReadTransport struct {
+	replicas    ReplicaSlice
+	args        roachpb.BatchRequest
+	leaseHolder roachpb.ReplicaDescriptor
+	sent        *[]roachpb.StoreID
+}
+
+func (f *followerReadTransport) IsExhausted() bool {
+	return len(f.replicas) == 0
+}
+
+func (f *followerReadTransport) SendNext(_ context.Context, done chan<- BatchCall) {
+	replica := f.replicas[0].ReplicaDescriptor
+	f.replicas = f.replicas[1:]
+	*f.sent = append(*f.sent, replica.StoreID)
+	reply := f.args.CreateReply()
+	if replica != f.leaseHolder {
+		reply.Error = roachpb.NewError(&roachpb.NotLeaseHolderError{
+			Replica:     replica,
+			LeaseHolder: &f.leaseHolder,
+			CustomMsg:   "follower read not possible",
+		})
+	}
+	done <- BatchCall{Reply: reply}
+}
+
+func (f *followerReadTransport) NextReplica() roachpb.ReplicaDescriptor {
+	if f.IsExhausted() {
+		return roachpb.ReplicaDescriptor{}
+	}
+	return f.replicas[0].ReplicaDescriptor
+}
+
+func (f *followerReadTransport) MoveToFront(replica roachpb.ReplicaDescriptor) {
+	if i := f.replicas.FindReplica(replica.StoreID); i >= 0 {
+		f.replicas.MoveToFront(i)
+	}
+}
+
+func (*followerReadTransport) Close() {
+}
+
+// TestFollowerReadFallbackToLeaseHolder verifies that historical reads are
+// sent to the local replica first, and that the DistSender retries them on
+// the lease holder when the replica cannot serve them.
+func TestFollowerReadFallbackToLeaseHolder(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+
+	g, _ := makeGossip(t, stopper)
+	// Gossip the two nodes referred to in testRangeDescriptor2.
+	for i := 2; i <= 3; i++ {
+		nd := &roachpb.NodeDescriptor{
+			NodeID:  roachpb.NodeID(i),
+			Address: util.MakeUnresolvedAddr("tcp", fmt.Sprintf("node%d", i)),
+		}
+		if err := g.AddInfoProto(gossip.MakeNodeIDKey(roachpb.NodeID(i)), nd, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	leaseHolder := testRangeDescriptor2.Replicas[2]
+	var sent []roachpb.StoreID
+	manual := hlc.NewManualClock((100 * time.Second).Nanoseconds())
+	st := cluster.MakeTestingClusterSettings()
+	storagebase.FollowerReadsEnabled.Override(&st.SV, true)
+	ds := NewDistSender(DistSenderConfig{
+		AmbientCtx: log.AmbientContext{Tracer: tracing.NewTracer()},
+		Clock:      hlc.NewClock(manual.UnixNano, time.Nanosecond),
+		Settings:   st,
+		TestingKnobs: DistSenderTestingKnobs{
+			TransportFactory: func(
+				_ SendOptions, _ *rpc.Context, replicas ReplicaSlice, args roachpb.BatchRequest,
+			) (Transport, error) {
+				return &followerReadTransport{
+					replicas:    replicas,
+					args:        args,
+					leaseHolder: leaseHolder,
+					sent:        &sent,
+				}, nil
+			},
+		},
+		RangeDescriptorDB: threeReplicaMockRangeDescriptorDB,
+	}, g)
+	ds.leaseHolderCache.Update(context.TODO(), testRangeDescriptor2.RangeID, leaseHolder)
+
+	testCases := []struct {
+		ts  hlc.Timestamp
+		exp []roachpb.StoreID
+	}{
+		// A recent read goes straight to the lease holder.
+		{hlc.Timestamp{WallTime: (90 * time.Second).Nanoseconds()}, []roachpb.StoreID{3}},
+		// A historical read goes to the local replica first, which redirects it
+		// to the lease holder.
+		{hlc.Timestamp{WallTime: (50 * time.Second).Nanoseconds()}, []roachpb.StoreID{1, 3}},
+	}
+	for i, c := range testCases {
+		sent = nil
+		get := roachpb.NewGet(roachpb.Key("a"))
+		if _, pErr := client.SendWrappedWith(
+			context.Background(), ds, roachpb.Header{Timestamp: c.ts}, get,
+		); pErr != nil {
+			t.Fatalf("%d: %s", i, pErr)
+		}
+		if !reflect.DeepEqual(c.exp, sent) {
+			t.Errorf("%d: expected the batch to be sent to stores %v, but found %v", i, c.exp, sent)
+		}
+	}
+}
+
+// TestFollowerReadRoutingByLocality verifies that only historical reads which
+// followers can serve are sent to the replica closest in locality, while all
+// other batches keep the usual replica order.
+func TestFollowerReadRoutingByLocality(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+
+	region := func(r string) roachpb.Locality {
+		return roachpb.Locality{Tiers: []roachpb.Tier{{Key: "region", Value: r}}}
+	}
+	// The local node has no replica of the range, and the replica in its
+	// region is listed last.
+	g, _ := makeGossip(t, stopper)
+	localities := map[roachpb.NodeID]roachpb.Locality{
+		1: region("eu"),
+		2: region("us-east"),
+		3: region("us-west"),
+		4: region("eu"),
+	}
+	for nodeID, locality := range localities {
+		nd := &roachpb.NodeDescriptor{
+			NodeID:   nodeID,
+			Address:  util.MakeUnresolvedAddr("tcp", fmt.Sprintf("node%d", nodeID)),
+			Locality: locality,
+		}
+		if err := g.AddInfoProto(gossip.MakeNodeIDKey(nodeID), nd, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+	}
+	desc := roachpb.RangeDescriptor{
+		RangeID:  2,
+		StartKey: roachpb.RKey("a"),
+		EndKey:   roachpb.RKey("z"),
+		Replicas: []roachpb.ReplicaDescriptor{
+			{NodeID: 2, StoreID: 2},
+			{NodeID: 3, StoreID: 3},
+			{NodeID: 4, StoreID: 4},
+		},
+	}
+
+	leaseHolder := desc.Replicas[0]
+	var sent []roachpb.StoreID
+	manual := hlc.NewManualClock((100 * time.Second).Nanoseconds())
+	st := cluster.MakeTestingClusterSettings()
+	storagebase.FollowerReadsEnabled.Override(&st.SV, true)
+	ds := NewDistSender(DistSenderConfig{
+		AmbientCtx: log.AmbientContext{Tracer: tracing.NewTracer()},
+		Clock:      hlc.NewClock(manual.UnixNano, time.Nanosecond),
+		Settings:   st,
+		TestingKnobs: DistSenderTestingKnobs{
+			TransportFactory: func(
+				_ SendOptions, _ *rpc.Context, replicas ReplicaSlice, args roachpb.BatchRequest,
+			) (Transport, error) {
+				return &followerReadTransport{
+					replicas:    replicas,
+					args:        args,
+					leaseHolder: leaseHolder,
+					sent:        &sent,
+				}, nil
+			},
+		},
+		RangeDescriptorDB: MockRangeDescriptorDB(func(key roachpb.RKey, _ bool) ([]roachpb.RangeDescriptor, []roachpb.RangeDescriptor, *roachpb.Error) {
+			if bytes.HasPrefix(key, keys.Meta2Prefix) {
+				return []roachpb.RangeDescriptor{testMetaRangeDescriptor}, nil, nil
+			}
+			return []roachpb.RangeDescriptor{desc}, nil, nil
+		}),
+	}, g)
+
+	testCases := []struct {
+		ts  hlc.Timestamp
+		exp []roachpb.StoreID
+	}{
+		// A recent read tries the replicas in the order of the descriptor,
+		// whose first replica is the lease holder.
+		{hlc.Timestamp{WallTime: (90 * time.Second).Nanoseconds()}, []roachpb.StoreID{2}},
+		// A historical read goes to the replica in the same region first, which
+		// redirects it to the lease holder.
+		{hlc.Timestamp{WallTime: (50 * time.Second).Nanoseconds()}, []roachpb.StoreID{4, 2}},
+	}
+	for i, c := range testCases {
+		sent = nil
+		get := roachpb.NewGet(roachpb.Key("a"))
+		if _, pErr := client.SendWrappedWith(
+			context.Background(), ds, roachpb.Header{Timestamp: c.ts}, get,
+		); pErr != nil {
+			t.Fatalf("%d: %s", i, pErr)
+		}
+		if !reflect.DeepEqual(c.exp, sent) {
+			t.Errorf("%d: expected the batch to be sent to stores %v, but found %v", i, c.exp, sent)
+		}
+	}
+}
+
 // TestRetryOnDescriptorLookupError verifies that the DistSender retries a descriptor
 // lookup on any error.
 func TestRetryOnDescriptorLookupError(t *testing.T) {
@@ -15,6 +15,8 @@
 package kv
 
 import (
+	"sort"
+
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/gossip"
@@ -126,6 +128,19 @@ func (rs ReplicaSlice) SortByCommonAttributePrefix(attrs []string) int {
 	return len(attrs)
 }
 
+// SortByLocality rearranges the ReplicaSlice so that the replicas whose
+// localities are the least diverse from the given locality come first. The
+// relative order of replicas with equally diverse localities is preserved.
+func (rs ReplicaSlice) SortByLocality(locality roachpb.Locality) {
+	if len(locality.Tiers) == 0 {
+		return
+	}
+	sort.SliceStable(rs, func(i, j int) bool {
+		return locality.DiversityScore(rs[i].NodeDesc.Locality) <
+			locality.DiversityScore(rs[j].NodeDesc.Locality)
+	})
+}
+
 // MoveToFront moves the replica at the given index to the front
 // of the slice, keeping the order of the remaining elements stable.
 // The function will panic when invoked with an invalid index.
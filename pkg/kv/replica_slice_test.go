@@ -113,6 +113,47 @@ func TestReplicaSetMoveToFront(t *testing.T) {
 	}
 }
 
+func TestReplicaSliceSortByLocality(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	locality := func(region, zone string) roachpb.Locality {
+		return roachpb.Locality{Tiers: []roachpb.Tier{
+			{Key: "region", Value: region},
+			{Key: "zone", Value: zone},
+		}}
+	}
+	localities := []roachpb.Locality{
+		locality("eu", "a"),
+		locality("us-east", "b"),
+		locality("us-west", "a"),
+		locality("us-east", "a"),
+		{},
+	}
+	makeSlice := func() ReplicaSlice {
+		rs := createReplicaSlice()
+		for i := range rs {
+			rs[i].NodeDesc = &roachpb.NodeDescriptor{Locality: localities[i]}
+		}
+		return rs
+	}
+
+	// Replicas in the same zone come first, then those in the same region.
+	// Replicas which are as diverse keep their relative order.
+	rs := makeSlice()
+	rs.SortByLocality(locality("us-east", "a"))
+	exp := []roachpb.StoreID{4, 2, 1, 3, 5}
+	if stores := getStores(rs); !reflect.DeepEqual(stores, exp) {
+		t.Errorf("expected order %s, got %s", exp, stores)
+	}
+
+	// Without a locality, the order is left untouched.
+	rs = makeSlice()
+	rs.SortByLocality(roachpb.Locality{})
+	exp = []roachpb.StoreID{1, 2, 3, 4, 5}
+	if stores := getStores(rs); !reflect.DeepEqual(stores, exp) {
+		t.Errorf("expected order %s, got %s", exp, stores)
+	}
+}
+
 // TestMoveLocalReplicaToFront verifies that OptimizeReplicaOrder correctly
 // move the local replica to the front.
 func TestMoveLocalReplicaToFront(t *testing.T) {
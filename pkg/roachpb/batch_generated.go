@@ -9,7 +9,7 @@ import (
 	"strconv"
 )
 
-type reqCounts [36]int32
+type reqCounts [38]int32
 
 // getReqCounts returns the number of times each
 // request type appears in the batch.
@@ -89,6 +89,10 @@ func (ba *BatchRequest) getReqCounts() reqCounts {
 			counts[34]++
 		case r.AddSstable != nil:
 			counts[35]++
+		case r.Subsume != nil:
+			counts[36]++
+		case r.RangeStats != nil:
+			counts[37]++
 		default:
 			panic(fmt.Sprintf("unsupported request: %+v", r))
 		}
@@ -133,6 +137,8 @@ var requestNames = []string{
 	"QueryTxn",
 	"AdmScatter",
 	"AddSstable",
+	"Subsume",
+	"RangeStats",
 }
 
 // Summary prints a short summary of the requests in a batch.
@@ -203,6 +209,8 @@ func (ba *BatchRequest) CreateReply() *BatchResponse {
 	var buf33 []QueryTxnResponse
 	var buf34 []AdminScatterResponse
 	var buf35 []AddSSTableResponse
+	var buf36 []SubsumeResponse
+	var buf37 []RangeStatsResponse
 
 	for i, r := range ba.Requests {
 		switch {
@@ -422,6 +430,18 @@ func (ba *BatchRequest) CreateReply() *BatchResponse {
 			}
 			br.Responses[i].AddSstable = &buf35[0]
 			buf35 = buf35[1:]
+		case r.Subsume != nil:
+			if buf36 == nil {
+				buf36 = make([]SubsumeResponse, counts[36])
+			}
+			br.Responses[i].Subsume = &buf36[0]
+			buf36 = buf36[1:]
+		case r.RangeStats != nil:
+			if buf37 == nil {
+				buf37 = make([]RangeStatsResponse, counts[37])
+			}
+			br.Responses[i].RangeStats = &buf37[0]
+			buf37 = buf37[1:]
 		default:
 			panic(fmt.Sprintf("unsupported request: %+v", r))
 		}
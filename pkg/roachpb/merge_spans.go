@@ -100,3 +100,29 @@ func MergeSpans(spans []Span) ([]Span, bool) {
 	}
 	return r, distinct
 }
+
+// IntersectSpans returns the spans covering the keys contained in both a and
+// b. The spans in a and b must have an EndKey, and be sorted and
+// non-overlapping, as returned by MergeSpans.
+func IntersectSpans(a, b []Span) []Span {
+	var r []Span
+	for len(a) > 0 && len(b) > 0 {
+		start, end := a[0].Key, a[0].EndKey
+		if b[0].Key.Compare(start) > 0 {
+			start = b[0].Key
+		}
+		if b[0].EndKey.Compare(end) < 0 {
+			end = b[0].EndKey
+		}
+		if start.Compare(end) < 0 {
+			r = append(r, Span{Key: start, EndKey: end})
+		}
+		// Advance past the span which ends first.
+		if a[0].EndKey.Compare(b[0].EndKey) < 0 {
+			a = a[1:]
+		} else {
+			b = b[1:]
+		}
+	}
+	return r
+}
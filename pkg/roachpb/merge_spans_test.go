@@ -20,24 +20,24 @@ import (
 	"testing"
 )
 
-func TestMergeSpans(t *testing.T) {
-	makeSpan := func(s string) Span {
-		parts := strings.Split(s, "-")
-		if len(parts) == 2 {
-			return Span{Key: Key(parts[0]), EndKey: Key(parts[1])}
-		}
-		return Span{Key: Key(s)}
-	}
-	makeSpans := func(s string) []Span {
-		var spans []Span
-		if len(s) > 0 {
-			for _, p := range strings.Split(s, ",") {
-				spans = append(spans, makeSpan(p))
+// makeTestSpans parses a comma-separated list of spans, each of which is
+// either a key or a start and end key separated by a dash.
+func makeTestSpans(s string) []Span {
+	var spans []Span
+	if len(s) > 0 {
+		for _, p := range strings.Split(s, ",") {
+			parts := strings.Split(p, "-")
+			if len(parts) == 2 {
+				spans = append(spans, Span{Key: Key(parts[0]), EndKey: Key(parts[1])})
+			} else {
+				spans = append(spans, Span{Key: Key(p)})
 			}
 		}
-		return spans
 	}
+	return spans
+}
 
+func TestMergeSpans(t *testing.T) {
 	testCases := []struct {
 		spans    string
 		expected string
@@ -59,8 +59,8 @@ func TestMergeSpans(t *testing.T) {
 		{"a-c,b-c", "a-c", false},
 	}
 	for i, c := range testCases {
-		spans, distinct := MergeSpans(makeSpans(c.spans))
-		expected := makeSpans(c.expected)
+		spans, distinct := MergeSpans(makeTestSpans(c.spans))
+		expected := makeTestSpans(c.expected)
 		if !reflect.DeepEqual(expected, spans) {
 			t.Fatalf("%d: expected\n%s\n, but found:\n%s", i, expected, spans)
 		}
@@ -69,3 +69,29 @@ func TestMergeSpans(t *testing.T) {
 		}
 	}
 }
+
+func TestIntersectSpans(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected string
+	}{
+		{"", "", ""},
+		{"a-c", "", ""},
+		{"", "a-c", ""},
+		{"a-c", "a-c", "a-c"},
+		{"a-c", "b-d", "b-c"},
+		{"b-d", "a-c", "b-c"},
+		{"a-b", "b-c", ""},
+		{"a-d", "b-c", "b-c"},
+		{"a-b,c-d,e-f", "a-z", "a-b,c-d,e-f"},
+		{"a-c,d-f", "b-e", "b-c,d-e"},
+		{"a-c,d-f", "aa-ab,b-dd,e-z", "aa-ab,b-c,d-dd,e-f"},
+	}
+	for i, c := range testCases {
+		spans := IntersectSpans(makeTestSpans(c.a), makeTestSpans(c.b))
+		expected := makeTestSpans(c.expected)
+		if !reflect.DeepEqual(expected, spans) {
+			t.Fatalf("%d: expected\n%s\n, but found:\n%s", i, expected, spans)
+		}
+	}
+}
@@ -133,4 +133,8 @@ const (
 	AdminScatter
 	// AddSSTable links a file into the RocksDB log-structured merge-tree.
 	AddSSTable
+	// Subsume freezes a range for merging with its left-hand neighbor.
+	Subsume
+	// RangeStats returns the MVCC statistics for a range.
+	RangeStats
 )
@@ -56,6 +56,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
 	"github.com/cockroachdb/cockroach/pkg/sql/mon"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire"
+	"github.com/cockroachdb/cockroach/pkg/sql/stats"
 	migrations "github.com/cockroachdb/cockroach/pkg/sqlmigrations"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
@@ -76,6 +77,10 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
 
+// tableStatisticsCacheSize is the number of tables whose statistics are kept
+// in the table statistics cache of each node.
+const tableStatisticsCacheSize = 256
+
 var (
 	// Allocation pool for gzip writers.
 	gzipWriterPool sync.Pool
@@ -403,6 +408,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	}
 
 	// Set up Executor
+	tableStatsCache := stats.NewTableStatisticsCache(tableStatisticsCacheSize, s.db, sqlExecutor)
 	execCfg := sql.ExecutorConfig{
 		Settings:                s.st,
 		NodeInfo:                nodeInfo,
@@ -417,6 +423,10 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		StatusServer:            s.status,
 		SessionRegistry:         s.sessionRegistry,
 		JobRegistry:             s.jobRegistry,
+		NodeLiveness:            s.nodeLiveness,
+		RoleMemberCache:         &sql.MembershipCache{},
+		TableStatsCache:         tableStatsCache,
+		TableStatsRefresher:     stats.MakeRefresher(s.st, tableStatsCache),
 		HistogramWindowInterval: s.cfg.HistogramWindowInterval(),
 		RangeDescriptorCache:    s.distSender.RangeDescriptorCache(),
 		LeaseHolderCache:        s.distSender.LeaseHolderCache(),
@@ -34,10 +34,11 @@ type alterTableNode struct {
 
 // AlterTable applies a schema change on a table.
 // Privileges: CREATE on table.
-//   notes: postgres requires CREATE on the table.
-//          mysql requires ALTER, CREATE, INSERT on the table.
+//
+//	notes: postgres requires CREATE on the table.
+//	       mysql requires ALTER, CREATE, INSERT on the table.
 func (p *planner) AlterTable(ctx context.Context, n *parser.AlterTable) (planNode, error) {
-	tn, err := n.Table.NormalizeWithDatabaseName(p.session.Database)
+	tn, err := p.normalizeTableName(ctx, &n.Table)
 	if err != nil {
 		return nil, err
 	}
@@ -53,7 +54,7 @@ func (p *planner) AlterTable(ctx context.Context, n *parser.AlterTable) (planNod
 		return nil, sqlbase.NewUndefinedRelationError(tn)
 	}
 
-	if err := p.CheckPrivilege(tableDesc, privilege.CREATE); err != nil {
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege.CREATE); err != nil {
 		return nil, err
 	}
 	return &alterTableNode{n: n, tableDesc: tableDesc}, nil
@@ -161,7 +162,7 @@ func (n *alterTableNode) Start(params runParams) error {
 				descriptorChanged = true
 
 			case *parser.ForeignKeyConstraintTableDef:
-				if _, err := d.Table.NormalizeWithDatabaseName(params.p.session.Database); err != nil {
+				if _, err := params.p.normalizeTableName(params.ctx, &d.Table); err != nil {
 					return err
 				}
 				affected := make(map[sqlbase.ID]*sqlbase.TableDescriptor)
@@ -196,6 +197,10 @@ func (n *alterTableNode) Start(params runParams) error {
 			if dropped {
 				continue
 			}
+			if n.tableDesc.IsIndexExprColumn(col.ID) {
+				return pgerror.NewErrorf(pgerror.CodeDependentObjectsStillExistError,
+					"column %q stores the values of an index expression, drop the index instead", col.Name)
+			}
 			// You can't drop a column depended on by a view unless CASCADE was
 			// specified.
 			for _, ref := range n.tableDesc.DependedOnBy {
@@ -252,12 +257,21 @@ func (n *alterTableNode) Start(params runParams) error {
 				// includes non-PK columns other than the one being dropped.
 				containsOnlyThisColumn := true
 
-				// Analyze the index.
-				for _, id := range idx.ColumnIDs {
-					if id == col.ID {
-						containsThisColumn = true
-					} else {
-						containsOnlyThisColumn = false
+				// Analyze the index. The expressions it indexes count as
+				// the columns they reference.
+				for i, id := range idx.ColumnIDs {
+					ids := []sqlbase.ColumnID{id}
+					if expr := idx.Expression(i); expr != "" {
+						if ids, err = n.tableDesc.ColumnExprSourceIDs(expr); err != nil {
+							return err
+						}
+					}
+					for _, id := range ids {
+						if id == col.ID {
+							containsThisColumn = true
+						} else {
+							containsOnlyThisColumn = false
+						}
 					}
 				}
 				for _, id := range idx.ExtraColumnIDs {
@@ -351,66 +365,36 @@ func (n *alterTableNode) Start(params runParams) error {
 			}
 
 		case *parser.AlterTableValidateConstraint:
-			info, err := n.tableDesc.GetConstraintInfo(params.ctx, nil)
+			changed, err := n.validateConstraint(params, t.Constraint)
 			if err != nil {
 				return err
 			}
-			name := string(t.Constraint)
-			constraint, ok := info[name]
-			if !ok {
-				return fmt.Errorf("constraint %q does not exist", t.Constraint)
+			descriptorChanged = descriptorChanged || changed
+
+		case *parser.AlterTableAlterConstraint:
+			var changed bool
+			var err error
+			if t.ValidationBehavior == parser.ValidationSkip {
+				changed, err = n.invalidateConstraint(params, t.Constraint)
+			} else {
+				changed, err = n.validateConstraint(params, t.Constraint)
 			}
-			if !constraint.Unvalidated {
-				continue
+			if err != nil {
+				return err
 			}
-			switch constraint.Kind {
-			case sqlbase.ConstraintTypeCheck:
-				found := false
-				var idx int
-				for idx = range n.tableDesc.Checks {
-					if n.tableDesc.Checks[idx].Name == name {
-						found = true
-						break
-					}
-				}
-				if !found {
-					panic("constraint returned by GetConstraintInfo not found")
-				}
-				ck := n.tableDesc.Checks[idx]
-				if err := params.p.validateCheckExpr(
-					params.ctx, ck.Expr, &n.n.Table, n.tableDesc,
-				); err != nil {
-					return err
-				}
-				n.tableDesc.Checks[idx].Validity = sqlbase.ConstraintValidity_Validated
-				descriptorChanged = true
+			descriptorChanged = descriptorChanged || changed
 
-			case sqlbase.ConstraintTypeFK:
-				found := false
-				var id sqlbase.IndexID
-				for _, idx := range n.tableDesc.AllNonDropIndexes() {
-					if idx.ForeignKey.IsSet() && idx.ForeignKey.Name == name {
-						found = true
-						id = idx.ID
-						break
-					}
-				}
-				if !found {
-					panic("constraint returned by GetConstraintInfo not found")
-				}
-				idx, err := n.tableDesc.FindIndexByID(id)
-				if err != nil {
-					panic(err)
-				}
-				if err := params.p.validateForeignKey(params.ctx, n.tableDesc, idx); err != nil {
-					return err
-				}
-				idx.ForeignKey.Validity = sqlbase.ConstraintValidity_Validated
-				descriptorChanged = true
+		case *parser.AlterTableSetNotNull:
+			if err := n.setNotNull(t); err != nil {
+				return err
+			}
 
-			default:
-				return errors.Errorf("validating %s constraint %q unsupported", constraint.Kind, t.Constraint)
+		case *parser.AlterTableAlterColumnType:
+			changed, err := n.alterColumnType(params, t)
+			if err != nil {
+				return err
 			}
+			descriptorChanged = descriptorChanged || changed
 
 		case parser.ColumnMutationCmd:
 			// Column mutations
@@ -494,6 +478,147 @@ func (n *alterTableNode) Next(runParams) (bool, error) { return false, nil }
 func (n *alterTableNode) Close(context.Context)        {}
 func (n *alterTableNode) Values() parser.Datums        { return parser.Datums{} }
 
+// findConstraint returns the details of the constraint with the given name.
+func (n *alterTableNode) findConstraint(
+	params runParams, name parser.Name,
+) (sqlbase.ConstraintDetail, error) {
+	info, err := n.tableDesc.GetConstraintInfo(params.ctx, nil)
+	if err != nil {
+		return sqlbase.ConstraintDetail{}, err
+	}
+	constraint, ok := info[string(name)]
+	if !ok {
+		return sqlbase.ConstraintDetail{}, fmt.Errorf("constraint %q does not exist", name)
+	}
+	return constraint, nil
+}
+
+// validateConstraint checks that the existing rows satisfy an unvalidated
+// constraint and marks it as validated, in which case true is returned.
+func (n *alterTableNode) validateConstraint(params runParams, name parser.Name) (bool, error) {
+	constraint, err := n.findConstraint(params, name)
+	if err != nil {
+		return false, err
+	}
+	if !constraint.Unvalidated {
+		return false, nil
+	}
+	switch constraint.Kind {
+	case sqlbase.ConstraintTypeCheck:
+		found := false
+		var idx int
+		for idx = range n.tableDesc.Checks {
+			if n.tableDesc.Checks[idx].Name == string(name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			panic("constraint returned by GetConstraintInfo not found")
+		}
+		ck := n.tableDesc.Checks[idx]
+		if err := params.p.validateCheckExpr(
+			params.ctx, ck.Expr, &n.n.Table, n.tableDesc,
+		); err != nil {
+			return false, err
+		}
+		n.tableDesc.Checks[idx].Validity = sqlbase.ConstraintValidity_Validated
+		return true, nil
+
+	case sqlbase.ConstraintTypeFK:
+		found := false
+		var id sqlbase.IndexID
+		for _, idx := range n.tableDesc.AllNonDropIndexes() {
+			if idx.ForeignKey.IsSet() && idx.ForeignKey.Name == string(name) {
+				found = true
+				id = idx.ID
+				break
+			}
+		}
+		if !found {
+			panic("constraint returned by GetConstraintInfo not found")
+		}
+		idx, err := n.tableDesc.FindIndexByID(id)
+		if err != nil {
+			panic(err)
+		}
+		if err := params.p.validateForeignKey(params.ctx, n.tableDesc, idx); err != nil {
+			return false, err
+		}
+		idx.ForeignKey.Validity = sqlbase.ConstraintValidity_Validated
+		return true, nil
+
+	default:
+		return false, errors.Errorf("validating %s constraint %q unsupported", constraint.Kind, name)
+	}
+}
+
+// invalidateConstraint marks a validated constraint as NOT VALID, in which
+// case true is returned. The constraint keeps being enforced on writes.
+func (n *alterTableNode) invalidateConstraint(params runParams, name parser.Name) (bool, error) {
+	constraint, err := n.findConstraint(params, name)
+	if err != nil {
+		return false, err
+	}
+	switch constraint.Kind {
+	case sqlbase.ConstraintTypeCheck:
+		for i := range n.tableDesc.Checks {
+			if n.tableDesc.Checks[i].Name == string(name) {
+				if n.tableDesc.Checks[i].Validity == sqlbase.ConstraintValidity_Unvalidated {
+					return false, nil
+				}
+				n.tableDesc.Checks[i].Validity = sqlbase.ConstraintValidity_Unvalidated
+				return true, nil
+			}
+		}
+
+	case sqlbase.ConstraintTypeFK:
+		for _, idx := range n.tableDesc.AllNonDropIndexes() {
+			if idx.ForeignKey.IsSet() && idx.ForeignKey.Name == string(name) {
+				if idx.ForeignKey.Validity == sqlbase.ConstraintValidity_Unvalidated {
+					return false, nil
+				}
+				fkIdx, err := n.tableDesc.FindIndexByID(idx.ID)
+				if err != nil {
+					panic(err)
+				}
+				fkIdx.ForeignKey.Validity = sqlbase.ConstraintValidity_Unvalidated
+				return true, nil
+			}
+		}
+
+	default:
+		return false, errors.Errorf("altering %s constraint %q unsupported", constraint.Kind, name)
+	}
+	panic("constraint returned by GetConstraintInfo not found")
+}
+
+// setNotNull adds a mutation that validates the existing values of a column
+// before making it non-nullable. Writes are required to honor the constraint
+// while the validation takes place.
+func (n *alterTableNode) setNotNull(t *parser.AlterTableSetNotNull) error {
+	col, dropped, err := n.tableDesc.FindColumnByName(t.Column)
+	if err != nil {
+		return err
+	}
+	if dropped {
+		return fmt.Errorf("column %q in the middle of being dropped", t.Column)
+	}
+	if _, err := n.tableDesc.FindActiveColumnByName(string(t.Column)); err != nil {
+		return fmt.Errorf("column %q in the middle of being added", t.Column)
+	}
+	if !col.Nullable {
+		return nil
+	}
+	for _, m := range n.tableDesc.Mutations {
+		if nn := m.GetNotNull(); nn != nil && nn.ColumnID == col.ID {
+			return fmt.Errorf("NOT NULL constraint on column %q in the middle of being changed", t.Column)
+		}
+	}
+	n.tableDesc.AddNotNullMutation(col.ID, sqlbase.DescriptorMutation_ADD)
+	return nil
+}
+
 func applyColumnMutation(
 	col *sqlbase.ColumnDescriptor, mut parser.ColumnMutationCmd, searchPath parser.SearchPath,
 ) error {
@@ -518,6 +643,436 @@ func applyColumnMutation(
 	return nil
 }
 
+// alterColumnType changes the type of a column. Changes that leave the
+// encoding of the existing values valid only update the column descriptor,
+// and true is returned. Otherwise a new column of the requested type is added
+// and backfilled from the converted values of the old one, which it replaces
+// once the backfill completes. The indexes referring to the column are
+// rebuilt over the new column as part of the same mutation, and the CHECK
+// constraints referring to it are validated against the new values. As in
+// Postgres, such a rewrite is refused for columns that a view refers to.
+func (n *alterTableNode) alterColumnType(
+	params runParams, t *parser.AlterTableAlterColumnType,
+) (bool, error) {
+	col, dropped, err := n.tableDesc.FindColumnByName(t.Column)
+	if err != nil {
+		return false, err
+	}
+	if dropped {
+		return false, fmt.Errorf("column %q in the middle of being dropped", t.Column)
+	}
+	if _, err := n.tableDesc.FindActiveColumnByID(col.ID); err != nil {
+		return false, fmt.Errorf("column %q in the middle of being added, try again later", t.Column)
+	}
+	for _, m := range n.tableDesc.Mutations {
+		if m.Conversion != nil && m.Conversion.SourceColumnID == col.ID {
+			return false, fmt.Errorf("column %q in the middle of being altered, try again later", t.Column)
+		}
+	}
+	if typ, ok := t.ToType.(*parser.IntColType); ok && typ.IsSerial() {
+		return false, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"cannot alter the type of column %q to %s", col.Name, t.ToType)
+	}
+
+	// Build the descriptor of the new column through the same path as
+	// ADD COLUMN, so that the type is validated in the same way.
+	newName := col.Name + "_new"
+	for i := 1; ; i++ {
+		if _, _, err := n.tableDesc.FindColumnByName(parser.Name(newName)); err != nil {
+			break
+		}
+		newName = fmt.Sprintf("%s_new%d", col.Name, i)
+	}
+	d := &parser.ColumnTableDef{Name: parser.Name(newName), Type: t.ToType}
+	if !col.Nullable {
+		d.Nullable.Nullability = parser.NotNull
+	}
+	if col.DefaultExpr != nil {
+		if d.DefaultExpr.Expr, err = parser.ParseExpr(*col.DefaultExpr); err != nil {
+			return false, err
+		}
+	}
+	newCol, _, err := sqlbase.MakeColumnDefDescs(d, params.p.session.SearchPath, &params.p.evalCtx)
+	if err != nil {
+		if col.DefaultExpr != nil {
+			return false, pgerror.NewErrorf(pgerror.CodeDatatypeMismatchError,
+				"default for column %q cannot be cast automatically to type %s", col.Name, t.ToType)
+		}
+		return false, err
+	}
+
+	if t.Using == nil && !columnTypeChangeNeedsRewrite(col.Type, newCol.Type) {
+		col.Type = newCol.Type
+		n.tableDesc.UpdateColumnDescriptor(col)
+		return true, nil
+	}
+
+	// The values of the column have to be rewritten. The views referring to
+	// the column would have to be recreated, which is left to the user.
+	for _, ref := range n.tableDesc.DependedOnBy {
+		for _, colID := range ref.ColumnIDs {
+			if colID == col.ID {
+				return false, pgerror.NewErrorf(pgerror.CodeDependentObjectsStillExistError,
+					"cannot alter the type of column %q because a view depends on it", col.Name)
+			}
+		}
+	}
+
+	conv, err := makeColumnConversion(n.tableDesc, col, t, params.p.session.SearchPath)
+	if err != nil {
+		return false, err
+	}
+	// Make sure the conversion type checks before queuing the mutation.
+	if _, err := sqlbase.MakeColumnConverter(n.tableDesc, *newCol, &conv); err != nil {
+		return false, err
+	}
+
+	var family string
+	for _, fam := range n.tableDesc.Families {
+		for _, id := range fam.ColumnIDs {
+			if id == col.ID {
+				family = fam.Name
+			}
+		}
+	}
+	convIdx := len(n.tableDesc.Mutations)
+	n.tableDesc.AddColumnConversionMutation(*newCol, conv)
+	// The new column is placed in the family of the old one, so that both
+	// are written together while the conversion is in progress. In
+	// particular, a column of the primary key stays in family 0.
+	if err := n.tableDesc.AddColumnToFamilyMaybeCreate(newCol.Name, family, false, false); err != nil {
+		return false, err
+	}
+	// The new column needs an ID for the indexes and constraints rebuilt
+	// over it to refer to it.
+	if err := n.tableDesc.AllocateIDs(); err != nil {
+		return false, err
+	}
+	*newCol = *n.tableDesc.Mutations[convIdx].GetColumn()
+
+	if _, err := sqlbase.MakeConversionChecks(
+		n.tableDesc, []sqlbase.ColumnDescriptor{*newCol},
+	); err != nil {
+		return false, errors.Wrapf(err,
+			"cannot alter the type of column %q referenced by a CHECK constraint", col.Name)
+	}
+
+	replaced, replacements, err := n.replaceIndexesForConversion(col, *newCol)
+	if err != nil {
+		return false, err
+	}
+	n.tableDesc.Mutations[convIdx].Conversion.ReplacedIndexIDs = replaced
+	n.tableDesc.Mutations[convIdx].Conversion.ReplacementIndexIDs = replacements
+	return false, nil
+}
+
+// replaceIndexesForConversion queues, along with the mutation rewriting col
+// into newCol, the mutations adding a copy of each index referring to col
+// that refers to newCol instead. The copies are backfilled with the new
+// column and take the place of the indexes they replace once the rewrite
+// completes. If col is part of the primary key, the primary index and all
+// the secondary indexes, which contain the primary key, are replaced. The IDs
+// of the replaced indexes and of their replacements are returned.
+func (n *alterTableNode) replaceIndexesForConversion(
+	col, newCol sqlbase.ColumnDescriptor,
+) ([]sqlbase.IndexID, []sqlbase.IndexID, error) {
+	desc := n.tableDesc
+	inPrimaryKey := desc.PrimaryIndex.ContainsColumnID(col.ID)
+	var indexes []sqlbase.IndexDescriptor
+	if inPrimaryKey {
+		if desc.IsInterleaved() {
+			return nil, nil, pgerror.Unimplemented("alter type interleaved primary key",
+				fmt.Sprintf("cannot alter the type of column %q, which is referenced by the primary key of an interleaved table",
+					col.Name))
+		}
+		indexes = append(indexes, desc.PrimaryIndex)
+	}
+	for _, idx := range desc.Indexes {
+		referenced, err := indexReferencesColumn(desc, &idx, col.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if inPrimaryKey || referenced {
+			indexes = append(indexes, idx)
+		}
+	}
+	for _, m := range desc.Mutations {
+		if idx := m.GetIndex(); idx != nil && m.Direction == sqlbase.DescriptorMutation_ADD {
+			referenced, err := indexReferencesColumn(desc, idx, col.ID)
+			if err != nil {
+				return nil, nil, err
+			}
+			if inPrimaryKey || referenced {
+				return nil, nil, fmt.Errorf(
+					"index %q referring to column %q in the middle of being added, try again later",
+					idx.Name, col.Name)
+			}
+		}
+	}
+
+	var replaced, replacements []sqlbase.IndexID
+	var mutationIdxs []int
+	for _, idx := range indexes {
+		if idx.ForeignKey.IsSet() || len(idx.ReferencedBy) > 0 {
+			return nil, nil, pgerror.Unimplemented("alter type foreign key",
+				fmt.Sprintf("cannot alter the type of column %q, which is referenced by index %q used by a foreign key",
+					col.Name, idx.Name))
+		}
+		if len(idx.Interleave.Ancestors) > 0 || len(idx.InterleavedBy) > 0 {
+			return nil, nil, pgerror.Unimplemented("alter type interleaved index",
+				fmt.Sprintf("cannot alter the type of column %q, which is referenced by interleaved index %q",
+					col.Name, idx.Name))
+		}
+		if idx.Partitioning.NumColumns > 0 {
+			return nil, nil, pgerror.Unimplemented("alter type partitioned index",
+				fmt.Sprintf("cannot alter the type of column %q, which is referenced by partitioned index %q",
+					col.Name, idx.Name))
+		}
+
+		replacement, exprCols, err := makeReplacementIndex(desc, idx, col, newCol)
+		if err != nil {
+			return nil, nil, err
+		}
+		if idx.ID == desc.PrimaryIndex.ID {
+			replacement.EncodingType = sqlbase.PrimaryIndexEncoding
+		}
+		// The columns storing the rebuilt index expressions are backfilled
+		// before the index, as part of the same schema change.
+		for _, exprCol := range exprCols {
+			desc.AddColumnMutation(exprCol, sqlbase.DescriptorMutation_ADD)
+		}
+		mutationIdxs = append(mutationIdxs, len(desc.Mutations))
+		if err := desc.AddIndexMutation(replacement, sqlbase.DescriptorMutation_ADD); err != nil {
+			return nil, nil, err
+		}
+		replaced = append(replaced, idx.ID)
+	}
+	if err := desc.AllocateIDs(); err != nil {
+		return nil, nil, err
+	}
+	for _, i := range mutationIdxs {
+		replacements = append(replacements, desc.Mutations[i].GetIndex().ID)
+	}
+	return replaced, replacements, nil
+}
+
+// indexReferencesColumn returns whether the index contains the column or
+// indexes an expression over it.
+func indexReferencesColumn(
+	desc *sqlbase.TableDescriptor, idx *sqlbase.IndexDescriptor, colID sqlbase.ColumnID,
+) (bool, error) {
+	if idx.ContainsColumnID(colID) {
+		return true, nil
+	}
+	for _, expr := range idx.Expressions {
+		if expr == "" {
+			continue
+		}
+		ids, err := desc.ColumnExprSourceIDs(expr)
+		if err != nil {
+			return false, err
+		}
+		for _, id := range ids {
+			if id == colID {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// makeReplacementIndex returns a copy of idx, an index of desc, referring to
+// newCol instead of col, with a new name that it exchanges for the name of
+// idx once it replaces it. The expressions of the index referring to col are
+// stored in new hidden columns, which are returned and must be added to desc
+// by the caller, along with the index.
+func makeReplacementIndex(
+	desc *sqlbase.TableDescriptor, idx sqlbase.IndexDescriptor, col, newCol sqlbase.ColumnDescriptor,
+) (sqlbase.IndexDescriptor, []sqlbase.ColumnDescriptor, error) {
+	replacement := idx
+	replacement.ID = 0
+	replacement.Name = idx.Name + "_new"
+	for i := 1; ; i++ {
+		if _, _, err := desc.FindIndexByName(replacement.Name); err != nil {
+			break
+		}
+		replacement.Name = fmt.Sprintf("%s_new%d", idx.Name, i)
+	}
+	renameCol := func(names []string) []string {
+		renamed := make([]string, len(names))
+		for i, name := range names {
+			if name == col.Name {
+				name = newCol.Name
+			}
+			renamed[i] = name
+		}
+		return renamed
+	}
+	replacement.ColumnNames = renameCol(idx.ColumnNames)
+	replacement.StoreColumnNames = renameCol(idx.StoreColumnNames)
+	replacement.ColumnDirections = append(
+		[]sqlbase.IndexDescriptor_Direction(nil), idx.ColumnDirections...)
+	// The IDs are filled from the names by AllocateIDs.
+	replacement.ColumnIDs = nil
+	replacement.ExtraColumnIDs = nil
+	replacement.StoreColumnIDs = nil
+	replacement.CompositeColumnIDs = nil
+
+	var exprCols []sqlbase.ColumnDescriptor
+	if idx.Expressions != nil {
+		replacement.Expressions = make([]string, len(idx.Expressions))
+	}
+	for i, expr := range idx.Expressions {
+		if expr == "" {
+			continue
+		}
+		remapped, err := remapColumnExpr(expr, col.ID, newCol.ID)
+		if err != nil {
+			return replacement, nil, err
+		}
+		replacement.Expressions[i] = remapped
+		if remapped == expr {
+			// The column storing the values of the expression is shared with
+			// the replaced index.
+			continue
+		}
+		typedExpr, err := sqlbase.TypeCheckIndexExpr(desc, remapped)
+		if err != nil {
+			return replacement, nil, errors.Wrapf(err,
+				"cannot alter the type of column %q referenced by index %q", col.Name, idx.Name)
+		}
+		colType, err := sqlbase.DatumTypeToColumnType(typedExpr.ResolvedType())
+		if err != nil {
+			return replacement, nil, err
+		}
+		exprCol := sqlbase.ColumnDescriptor{
+			Name:     makeIndexExprColumnName(desc, exprCols),
+			Type:     colType,
+			Nullable: true,
+			Hidden:   true,
+		}
+		exprCols = append(exprCols, exprCol)
+		replacement.ColumnNames[i] = exprCol.Name
+	}
+	return replacement, exprCols, nil
+}
+
+// remapColumnExpr returns the serialized expression, which references columns
+// by ID, with the references to the column from replaced by references to
+// the column to.
+func remapColumnExpr(exprStr string, from, to sqlbase.ColumnID) (string, error) {
+	expr, err := parser.ParseExpr(exprStr)
+	if err != nil {
+		return "", err
+	}
+	found := false
+	expr, err = parser.SimpleVisit(expr, func(expr parser.Expr) (error, bool, parser.Expr) {
+		if ivar, ok := expr.(*parser.IndexedVar); ok && ivar.Idx == int(from)-1 {
+			found = true
+			return nil, false, parser.NewOrdinalReference(int(to) - 1)
+		}
+		return nil, true, expr
+	})
+	if err != nil || !found {
+		return exprStr, err
+	}
+	return parser.Serialize(expr), nil
+}
+
+// columnTypeChangeNeedsRewrite returns whether the values stored for a column
+// of type oldType need to be rewritten for the column to be of type newType.
+// Types that only differ in their maximum width can be converted in place as
+// long as the width doesn't decrease.
+func columnTypeChangeNeedsRewrite(oldType, newType sqlbase.ColumnType) bool {
+	if oldType.Equal(newType) {
+		return false
+	}
+	if oldType.SemanticType != newType.SemanticType ||
+		oldType.Precision != newType.Precision ||
+		len(oldType.ArrayDimensions) != 0 || len(newType.ArrayDimensions) != 0 {
+		return true
+	}
+	widens := newType.Width == 0 || (oldType.Width != 0 && newType.Width >= oldType.Width)
+	switch oldType.SemanticType {
+	case sqlbase.ColumnType_INT:
+		// The width of BIT columns doesn't have the same meaning.
+		return !widens || oldType.VisibleType == sqlbase.ColumnType_BIT ||
+			newType.VisibleType == sqlbase.ColumnType_BIT
+	case sqlbase.ColumnType_STRING:
+		return !widens
+	case sqlbase.ColumnType_COLLATEDSTRING:
+		return !widens || *oldType.Locale != *newType.Locale
+	}
+	return true
+}
+
+// makeColumnConversion returns the conversion that computes the values of
+// col, a column of desc, with the type and USING expression of t. The USING
+// expression may refer to any column of the table; the columns are
+// referenced by ID in the serialized expression.
+func makeColumnConversion(
+	desc *sqlbase.TableDescriptor,
+	col sqlbase.ColumnDescriptor,
+	t *parser.AlterTableAlterColumnType,
+	searchPath parser.SearchPath,
+) (sqlbase.DescriptorMutation_ColumnConversion, error) {
+	conv := sqlbase.DescriptorMutation_ColumnConversion{SourceColumnID: col.ID}
+	colRef := parser.NewOrdinalReference(int(col.ID) - 1)
+	if t.Using == nil {
+		// Like an assignment, the conversion must fail rather than truncate
+		// the values that don't fit in the new width.
+		var target parser.CastTargetType = t.ToType
+		switch typ := t.ToType.(type) {
+		case *parser.StringColType:
+			unbounded := *typ
+			unbounded.N = 0
+			target = &unbounded
+		case *parser.CollatedStringColType:
+			unbounded := *typ
+			unbounded.N = 0
+			target = &unbounded
+		}
+		conv.Expr = parser.Serialize(&parser.CastExpr{Expr: colRef, Type: target})
+		return conv, nil
+	}
+	expr, err := parser.SimpleVisit(t.Using, func(expr parser.Expr) (error, bool, parser.Expr) {
+		switch e := expr.(type) {
+		case *parser.Subquery:
+			return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"subqueries are not allowed in USING"), false, nil
+		case parser.VarName:
+			v, err := e.NormalizeVarName()
+			if err != nil {
+				return err, false, nil
+			}
+			c, ok := v.(*parser.ColumnItem)
+			if !ok {
+				return nil, true, expr
+			}
+			if c.TableName.TableName != "" && string(c.TableName.TableName) != desc.Name {
+				return pgerror.NewErrorf(pgerror.CodeUndefinedColumnError,
+					"column %q does not exist", c), false, nil
+			}
+			ref, err := desc.FindActiveColumnByName(string(c.ColumnName))
+			if err != nil || desc.IsIndexExprColumn(ref.ID) {
+				return pgerror.NewErrorf(pgerror.CodeUndefinedColumnError,
+					"column %q does not exist", c.ColumnName), false, nil
+			}
+			return nil, false, parser.NewOrdinalReference(int(ref.ID) - 1)
+		}
+		return nil, true, expr
+	})
+	if err != nil {
+		return conv, err
+	}
+	var p parser.Parser
+	if err := p.AssertNoAggregationOrWindowing(expr, "USING", searchPath); err != nil {
+		return conv, err
+	}
+	conv.Expr = parser.Serialize(expr)
+	return conv, nil
+}
+
 func labeledRowValues(cols []sqlbase.ColumnDescriptor, values parser.Datums) string {
 	var s bytes.Buffer
 	for i := range cols {
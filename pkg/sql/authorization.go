@@ -17,20 +17,38 @@ package sql
 import (
 	"fmt"
 
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 )
 
+// MembershipCache is a per-node cache of the roles each user is a member
+// of. It is valid for a single version of the system.role_members table
+// descriptor: statements modifying role memberships bump that version, which
+// invalidates the cache on every node once they acquire a lease on the new
+// version.
+type MembershipCache struct {
+	syncutil.Mutex
+	tableVersion sqlbase.DescriptorVersion
+	// userCache maps users to the result of resolveMemberOf.
+	userCache map[string]map[string]bool
+}
+
 // AuthorizationAccessor for checking authorization (e.g. desc privileges).
 type AuthorizationAccessor interface {
 	// CheckPrivilege verifies that the user has `privilege` on `descriptor`.
 	CheckPrivilege(
-		descriptor sqlbase.DescriptorProto, privilege privilege.Kind,
+		ctx context.Context, descriptor sqlbase.DescriptorProto, privilege privilege.Kind,
 	) error
 
 	// anyPrivilege verifies that the user has any privilege on `descriptor`.
-	anyPrivilege(descriptor sqlbase.DescriptorProto) error
+	anyPrivilege(ctx context.Context, descriptor sqlbase.DescriptorProto) error
 
 	// RequiresSuperUser errors if the session user isn't a super-user (i.e. root
 	// or node). Includes the named action in the error message.
@@ -50,20 +68,164 @@ func CheckPrivilege(
 		user, privilege, descriptor.TypeName(), descriptor.GetName())
 }
 
-// CheckPrivilege implements the AuthorizationAccessor interface.
+// CheckPrivilege implements the AuthorizationAccessor interface. Privileges
+// granted to roles the session user is a member of, directly or indirectly,
+// are taken into account.
 func (p *planner) CheckPrivilege(
-	descriptor sqlbase.DescriptorProto, privilege privilege.Kind,
+	ctx context.Context, descriptor sqlbase.DescriptorProto, privilege privilege.Kind,
 ) error {
-	return CheckPrivilege(p.session.User, descriptor, privilege)
+	user := p.session.User
+	privs := descriptor.GetPrivileges()
+	if privs.CheckPrivilege(user, privilege) {
+		return nil
+	}
+
+	memberOf, err := p.memberOf(ctx, user)
+	if err != nil {
+		return err
+	}
+	for role := range memberOf {
+		if privs.CheckPrivilege(role, privilege) {
+			return nil
+		}
+	}
+	return fmt.Errorf("user %s does not have %s privilege on %s %s",
+		user, privilege, descriptor.TypeName(), descriptor.GetName())
 }
 
 // anyPrivilege implements the AuthorizationAccessor interface.
-func (p *planner) anyPrivilege(descriptor sqlbase.DescriptorProto) error {
-	if userCanSeeDescriptor(descriptor, p.session.User) {
+func (p *planner) anyPrivilege(ctx context.Context, descriptor sqlbase.DescriptorProto) error {
+	user := p.session.User
+	if userCanSeeDescriptor(descriptor, user) {
 		return nil
 	}
+
+	memberOf, err := p.memberOf(ctx, user)
+	if err != nil {
+		return err
+	}
+	for role := range memberOf {
+		if userCanSeeDescriptor(descriptor, role) {
+			return nil
+		}
+	}
 	return fmt.Errorf("user %s has no privileges on %s %s",
-		p.session.User, descriptor.TypeName(), descriptor.GetName())
+		user, descriptor.TypeName(), descriptor.GetName())
+}
+
+// memberOf returns the roles the given user is a member of, directly or
+// through other roles. Each role maps to whether the user holds the ADMIN
+// OPTION on it, which is the case if any membership path to the role ends
+// with a membership granted WITH ADMIN OPTION.
+// Superusers are never members of any role.
+// The returned map may be shared through the MembershipCache and must not be
+// modified.
+func (p *planner) memberOf(ctx context.Context, user string) (map[string]bool, error) {
+	if user == security.RootUser || user == security.NodeUser {
+		return map[string]bool{}, nil
+	}
+
+	var cache *MembershipCache
+	if execCfg := p.ExecCfg(); execCfg != nil {
+		cache = execCfg.RoleMemberCache
+	}
+	if cache == nil {
+		return resolveMemberOf(ctx, p.LeaseMgr(), p.txn, user)
+	}
+
+	// Lease the system.role_members descriptor to learn its current version.
+	tableDesc, err := p.session.tables.getTableVersionByID(ctx, p.txn, keys.RoleMembersTableID)
+	if err != nil {
+		return nil, err
+	}
+	if tableDesc.UpVersion {
+		// Role memberships are being modified by this transaction, whose
+		// changes are only visible from within it.
+		return resolveMemberOf(ctx, p.LeaseMgr(), p.txn, user)
+	}
+
+	tableVersion := tableDesc.Version
+	cache.Lock()
+	if cache.tableVersion != tableVersion {
+		// The memberships changed: drop everything cached for the previous
+		// version.
+		cache.tableVersion = tableVersion
+		cache.userCache = make(map[string]map[string]bool)
+	}
+	ret, ok := cache.userCache[user]
+	cache.Unlock()
+	if ok {
+		return ret, nil
+	}
+
+	// Resolve the memberships outside of the user transaction so that the
+	// result does not depend on its timestamp and can be shared.
+	if err := p.ExecCfg().DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		var err error
+		ret, err = resolveMemberOf(ctx, p.LeaseMgr(), txn, user)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	cache.Lock()
+	// Only cache the result if the version did not change in the meantime;
+	// otherwise it may predate the latest membership changes.
+	if cache.tableVersion == tableVersion {
+		cache.userCache[user] = ret
+	}
+	cache.Unlock()
+	return ret, nil
+}
+
+// resolveMemberOf computes memberOf from the contents of
+// system.role_members as of txn.
+func resolveMemberOf(
+	ctx context.Context, leaseMgr *LeaseManager, txn *client.Txn, user string,
+) (map[string]bool, error) {
+	internalExecutor := InternalExecutor{LeaseManager: leaseMgr}
+	rows, err := internalExecutor.QueryRowsInTransaction(
+		ctx,
+		"expand-roles",
+		txn,
+		`SELECT "role", "member", "isAdmin" FROM system.role_members`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	type membership struct {
+		role    string
+		isAdmin bool
+	}
+	// The membership graph is small, so it is loaded in full and traversed in
+	// memory.
+	roles := make(map[string][]membership)
+	for _, row := range rows {
+		member := string(parser.MustBeDString(row[1]))
+		roles[member] = append(roles[member], membership{
+			role:    string(parser.MustBeDString(row[0])),
+			isAdmin: row[2] == parser.DBoolTrue,
+		})
+	}
+
+	ret := make(map[string]bool)
+	// Breadth-first traversal of the membership graph starting at user.
+	// Cycles are rejected by GRANT, but visited roles are still skipped so
+	// that each role is expanded once.
+	visited := map[string]struct{}{user: {}}
+	toVisit := []string{user}
+	for len(toVisit) > 0 {
+		member := toVisit[0]
+		toVisit = toVisit[1:]
+		for _, m := range roles[member] {
+			ret[m.role] = ret[m.role] || m.isAdmin
+			if _, ok := visited[m.role]; !ok {
+				visited[m.role] = struct{}{}
+				toVisit = append(toVisit, m.role)
+			}
+		}
+	}
+	return ret, nil
 }
 
 // RequireSuperUser implements the AuthorizationAccessor interface.
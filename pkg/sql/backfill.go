@@ -142,11 +142,15 @@ func (sc *SchemaChanger) runBackfill(
 			switch t := m.Descriptor_.(type) {
 			case *sqlbase.DescriptorMutation_Column:
 				desc := m.GetColumn()
-				if desc.DefaultExpr != nil || !desc.Nullable {
+				if desc.DefaultExpr != nil || !desc.Nullable || m.Conversion != nil ||
+					tableDesc.IsIndexExprColumn(desc.ID) {
 					needColumnBackfill = true
 				}
 			case *sqlbase.DescriptorMutation_Index:
 				addedIndexDescs = append(addedIndexDescs, *t.Index)
+			case *sqlbase.DescriptorMutation_NotNull:
+				// The existing rows are validated by the column backfiller.
+				needColumnBackfill = true
 			default:
 				return errors.Errorf("unsupported mutation: %+v", m)
 			}
@@ -160,6 +164,8 @@ func (sc *SchemaChanger) runBackfill(
 				if droppedIndexMutationIdx == mutationSentinel {
 					droppedIndexMutationIdx = i
 				}
+			case *sqlbase.DescriptorMutation_NotNull:
+				// Dropping a NOT NULL constraint requires no backfill.
 			default:
 				return errors.Errorf("unsupported mutation: %+v", m)
 			}
@@ -302,7 +308,7 @@ func (sc *SchemaChanger) truncateIndexes(
 					return err
 				}
 
-				rd, err := sqlbase.MakeRowDeleter(txn, tableDesc, nil, nil, false, alloc)
+				rd, err := sqlbase.MakeRowDeleter(txn, tableDesc, nil, nil, false, nil, alloc)
 				if err != nil {
 					return err
 				}
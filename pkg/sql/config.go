@@ -29,10 +29,16 @@ func init() {
 // GetZoneConfig returns the zone config for the object with 'id'.
 func GetZoneConfig(cfg config.SystemConfig, id uint32) (config.ZoneConfig, bool, error) {
 	// Look in the zones table.
+	var placeholder *config.ZoneConfig
 	if zoneVal := cfg.GetValue(sqlbase.MakeZoneKey(sqlbase.ID(id))); zoneVal != nil {
-		// We're done.
 		zone, err := config.MigrateZoneConfig(zoneVal)
-		return zone, true, err
+		if err != nil || !zone.IsSubzonePlaceholder() {
+			// We're done.
+			return zone, true, err
+		}
+		// The zone config only stores the subzones of a table, which are
+		// combined with the config inherited from its database below.
+		placeholder = &zone
 	}
 
 	// No zone config for this ID. We need to figure out if it's a database
@@ -45,7 +51,11 @@ func GetZoneConfig(cfg config.SystemConfig, id uint32) (config.ZoneConfig, bool,
 		}
 		if tableDesc := desc.GetTable(); tableDesc != nil {
 			// This is a table descriptor. Lookup its parent database zone config.
-			return GetZoneConfig(cfg, uint32(tableDesc.ParentID))
+			zone, found, err := GetZoneConfig(cfg, uint32(tableDesc.ParentID))
+			if err == nil && found && placeholder != nil {
+				zone.Subzones, zone.SubzoneSpans = placeholder.Subzones, placeholder.SubzoneSpans
+			}
+			return zone, found, err
 		}
 	}
 
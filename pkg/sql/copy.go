@@ -65,7 +65,7 @@ func (p *planner) CopyFrom(ctx context.Context, n *parser.CopyFrom) (planNode, e
 		columns: n.Columns,
 	}
 
-	tn, err := n.Table.NormalizeWithDatabaseName(p.session.Database)
+	tn, err := p.normalizeTableName(ctx, &n.Table)
 	if err != nil {
 		return nil, err
 	}
@@ -188,7 +188,9 @@ func (n *copyNode) addRow(ctx context.Context, line []byte) error {
 			parser.TypeString,
 			parser.TypeTimestamp,
 			parser.TypeTimestampTZ,
-			parser.TypeUUID:
+			parser.TypeUUID,
+			parser.TypeINet,
+			parser.TypeJSON:
 			s, err = decodeCopy(s)
 			if err != nil {
 				return err
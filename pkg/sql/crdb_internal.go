@@ -266,6 +266,12 @@ CREATE TABLE crdb_internal.schema_changes (
 					mutType = "INDEX"
 					targetID = parser.NewDInt(parser.DInt(int64(d.Index.ID)))
 					targetName = parser.NewDString(d.Index.Name)
+				case *sqlbase.DescriptorMutation_NotNull:
+					mutType = "NOT NULL"
+					targetID = parser.NewDInt(parser.DInt(int64(d.NotNull.ColumnID)))
+					if col, err := table.FindColumnByID(d.NotNull.ColumnID); err == nil {
+						targetName = parser.NewDString(col.Name)
+					}
 				}
 				if err := addRow(
 					tableID,
@@ -852,8 +858,8 @@ CREATE TABLE crdb_internal.builtin_functions (
 	},
 }
 
-// crdbInternalCreateStmtsTable exposes the CREATE TABLE/CREATE VIEW
-// statements.
+// crdbInternalCreateStmtsTable exposes the CREATE TABLE/CREATE VIEW/CREATE
+// SEQUENCE statements.
 var crdbInternalCreateStmtsTable = virtualSchemaTable{
 	schema: `
 CREATE TABLE crdb_internal.create_statements (
@@ -874,9 +880,13 @@ CREATE TABLE crdb_internal.create_statements (
 				var err error
 				var typeView = parser.DString("view")
 				var typeTable = parser.DString("table")
+				var typeSequence = parser.DString("sequence")
 				if table.IsView() {
 					descType = &typeView
 					stmt, err = p.showCreateView(ctx, parser.Name(table.Name), table)
+				} else if table.IsSequence() {
+					descType = &typeSequence
+					stmt, err = p.showCreateSequence(ctx, parser.Name(table.Name), table)
 				} else {
 					descType = &typeTable
 					stmt, err = p.showCreateTable(ctx, parser.Name(table.Name), prefix, table)
@@ -48,6 +48,10 @@ func (p *planner) CreateDatabase(n *parser.CreateDatabase) (planNode, error) {
 		return nil, errEmptyDatabaseName
 	}
 
+	if err := checkNotTemporarySchemaName(string(n.Name)); err != nil {
+		return nil, err
+	}
+
 	if tmpl := n.Template; tmpl != "" {
 		// See https://www.postgresql.org/docs/current/static/manage-ag-templatedbs.html
 		if !strings.EqualFold(tmpl, "template0") {
@@ -129,7 +133,7 @@ type createIndexNode struct {
 //   notes: postgres requires CREATE on the table.
 //          mysql requires INDEX on the table.
 func (p *planner) CreateIndex(ctx context.Context, n *parser.CreateIndex) (planNode, error) {
-	tn, err := n.Table.NormalizeWithDatabaseName(p.session.Database)
+	tn, err := p.normalizeTableName(ctx, &n.Table)
 	if err != nil {
 		return nil, err
 	}
@@ -139,7 +143,7 @@ func (p *planner) CreateIndex(ctx context.Context, n *parser.CreateIndex) (planN
 		return nil, err
 	}
 
-	if err := p.CheckPrivilege(tableDesc, privilege.CREATE); err != nil {
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege.CREATE); err != nil {
 		return nil, err
 	}
 
@@ -162,9 +166,28 @@ func (n *createIndexNode) Start(params runParams) error {
 		Unique:           n.n.Unique,
 		StoreColumnNames: n.n.Storing.ToStrings(),
 	}
-	if err := indexDesc.FillColumns(n.n.Columns); err != nil {
+	if n.n.Inverted {
+		indexDesc.Type = sqlbase.IndexDescriptor_INVERTED
+	}
+	exprCols, err := fillIndexColumns(n.tableDesc, &indexDesc, n.n.Columns, params.p.session.SearchPath)
+	if err != nil {
 		return err
 	}
+	if n.n.PartitionBy != nil {
+		if n.n.Interleave != nil {
+			return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"cannot partition an interleaved index")
+		}
+		if indexDesc.Partitioning, err = createPartitioning(&params.p.evalCtx,
+			params.p.session.SearchPath, n.tableDesc, &indexDesc, n.n.PartitionBy); err != nil {
+			return err
+		}
+	}
+	// The columns storing the indexed expressions are backfilled before the
+	// index, as part of the same schema change.
+	for _, col := range exprCols {
+		n.tableDesc.AddColumnMutation(col, sqlbase.DescriptorMutation_ADD)
+	}
 
 	mutationIdx := len(n.tableDesc.Mutations)
 	if err := n.tableDesc.AddIndexMutation(indexDesc, sqlbase.DescriptorMutation_ADD); err != nil {
@@ -222,8 +245,10 @@ func (*createIndexNode) Close(context.Context)        {}
 func (*createIndexNode) Values() parser.Datums        { return parser.Datums{} }
 
 type createUserNode struct {
-	n        *parser.CreateUser
+	name     parser.Name
 	password string
+	// isRole is set when the node creates a role rather than a user.
+	isRole bool
 }
 
 // CreateUser creates a user.
@@ -231,7 +256,31 @@ type createUserNode struct {
 //   notes: postgres allows the creation of users with an empty password. We do
 //          as well, but disallow password authentication for these users.
 func (p *planner) CreateUser(ctx context.Context, n *parser.CreateUser) (planNode, error) {
-	if n.Name == "" {
+	var resolvedPassword string
+	if n.HasPassword() {
+		resolvedPassword = *n.Password
+		if resolvedPassword == "" {
+			return nil, security.ErrEmptyPassword
+		}
+	}
+
+	return p.newCreateUserNode(ctx, n.Name, resolvedPassword, false /* isRole */)
+}
+
+// CreateRole creates a role. Roles are stored in system.users alongside
+// users, but cannot log in.
+// Privileges: INSERT on system.users.
+func (p *planner) CreateRole(ctx context.Context, n *parser.CreateRole) (planNode, error) {
+	return p.newCreateUserNode(ctx, n.Name, "" /* password */, true /* isRole */)
+}
+
+func (p *planner) newCreateUserNode(
+	ctx context.Context, name parser.Name, password string, isRole bool,
+) (*createUserNode, error) {
+	if name == "" {
+		if isRole {
+			return nil, errors.New("no role name specified")
+		}
 		return nil, errors.New("no username specified")
 	}
 
@@ -240,19 +289,20 @@ func (p *planner) CreateUser(ctx context.Context, n *parser.CreateUser) (planNod
 		return nil, err
 	}
 
-	if err := p.CheckPrivilege(tDesc, privilege.INSERT); err != nil {
+	if err := p.CheckPrivilege(ctx, tDesc, privilege.INSERT); err != nil {
 		return nil, err
 	}
 
-	var resolvedPassword string
-	if n.HasPassword() {
-		resolvedPassword = *n.Password
-		if resolvedPassword == "" {
-			return nil, security.ErrEmptyPassword
-		}
-	}
+	return &createUserNode{name: name, password: password, isRole: isRole}, nil
+}
 
-	return &createUserNode{n: n, password: resolvedPassword}, nil
+// userOrRoleName returns the noun used in error messages about entries of
+// system.users.
+func userOrRoleName(isRole bool) string {
+	if isRole {
+		return "role"
+	}
+	return "user"
 }
 
 const usernameHelp = "usernames are case insensitive, must start with a letter " +
@@ -287,7 +337,7 @@ func (n *createUserNode) Start(params runParams) error {
 		}
 	}
 
-	normalizedUsername, err := NormalizeAndValidateUsername(string(n.n.Name))
+	normalizedUsername, err := NormalizeAndValidateUsername(string(n.name))
 	if err != nil {
 		return err
 	}
@@ -297,18 +347,20 @@ func (n *createUserNode) Start(params runParams) error {
 		params.ctx,
 		"create-user",
 		params.p.txn,
-		"INSERT INTO system.users VALUES ($1, $2);",
+		"INSERT INTO system.users VALUES ($1, $2, $3);",
 		normalizedUsername,
 		hashedPassword,
+		n.isRole,
 	)
 	if err != nil {
 		if sqlbase.IsUniquenessConstraintViolationError(err) {
-			err = errors.Errorf("user %s already exists", normalizedUsername)
+			err = errors.Errorf("%s %s already exists", userOrRoleName(n.isRole), normalizedUsername)
 		}
 		return err
 	} else if rowsAffected != 1 {
 		return errors.Errorf(
-			"%d rows affected by user creation; expected exactly one row affected", rowsAffected,
+			"%d rows affected by %s creation; expected exactly one row affected",
+			rowsAffected, userOrRoleName(n.isRole),
 		)
 	}
 
@@ -337,7 +389,7 @@ type createViewNode struct {
 //						selected columns.
 //          mysql requires CREATE VIEW plus SELECT on all the selected columns.
 func (p *planner) CreateView(ctx context.Context, n *parser.CreateView) (planNode, error) {
-	name, err := n.Name.NormalizeWithDatabaseName(p.session.Database)
+	name, err := p.normalizeNewTableName(&n.Name)
 	if err != nil {
 		return nil, err
 	}
@@ -347,10 +399,17 @@ func (p *planner) CreateView(ctx context.Context, n *parser.CreateView) (planNod
 		return nil, err
 	}
 
-	if err := p.CheckPrivilege(dbDesc, privilege.CREATE); err != nil {
+	if err := p.CheckPrivilege(ctx, dbDesc, privilege.CREATE); err != nil {
 		return nil, err
 	}
 
+	// The qualification of table names below does not know about the
+	// names defined by a WITH clause.
+	if n.AsSource.With != nil {
+		return nil, pgerror.Unimplemented("view cte",
+			"CREATE VIEW with a WITH clause is not supported")
+	}
+
 	// Ensure that all the table names are properly qualified.  The
 	// traversal will update the NormalizableTableNames in-place, so the
 	// changes are persisted in n.AsSource. We use parser.FormatNode
@@ -370,6 +429,12 @@ func (p *planner) CreateView(ctx context.Context, n *parser.CreateView) (planNod
 					fmtErr = err
 					return
 				}
+				// The view would outlive the temporary tables it depends on.
+				if isTemporarySchemaName(tn.Database()) {
+					fmtErr = pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+						"views cannot reference temporary table %q", tn.Table())
+					return
+				}
 				// Persist the database prefix expansion.
 				tn.DBNameOriginallyOmitted = false
 			},
@@ -475,6 +540,12 @@ func (n *createViewNode) Start(params runParams) error {
 		return err
 	}
 
+	if desc.IsMaterializedView() {
+		if _, err := n.p.populateMaterializedView(params.ctx, &desc); err != nil {
+			return err
+		}
+	}
+
 	// Log Create View event. This is an auditable log event and is
 	// recorded in the same transaction as the table descriptor update.
 	if err := MakeEventLogger(n.p.LeaseMgr()).InsertEventRecord(
@@ -500,38 +571,161 @@ func (n *createViewNode) Close(ctx context.Context) {}
 func (*createViewNode) Next(runParams) (bool, error) { return false, nil }
 func (*createViewNode) Values() parser.Datums        { return parser.Datums{} }
 
-type createTableNode struct {
-	n          *parser.CreateTable
-	dbDesc     *sqlbase.DatabaseDescriptor
-	sourcePlan planNode
-	count      int
+// createSequenceNode represents a CREATE SEQUENCE statement.
+type createSequenceNode struct {
+	n      *parser.CreateSequence
+	dbDesc *sqlbase.DatabaseDescriptor
 }
 
-// CreateTable creates a table.
+// CreateSequence creates a sequence.
 // Privileges: CREATE on database.
-//   Notes: postgres/mysql require CREATE on database.
-func (p *planner) CreateTable(ctx context.Context, n *parser.CreateTable) (planNode, error) {
-	tn, err := n.Table.NormalizeWithDatabaseName(p.session.Database)
+//   notes: postgres requires CREATE on the schema.
+func (p *planner) CreateSequence(ctx context.Context, n *parser.CreateSequence) (planNode, error) {
+	name, err := p.normalizeNewTableName(&n.Name)
 	if err != nil {
 		return nil, err
 	}
 
-	dbDesc, err := MustGetDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), tn.Database())
+	dbDesc, err := MustGetDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), name.Database())
 	if err != nil {
 		return nil, err
 	}
 
-	if err := p.CheckPrivilege(dbDesc, privilege.CREATE); err != nil {
+	if err := p.CheckPrivilege(ctx, dbDesc, privilege.CREATE); err != nil {
 		return nil, err
 	}
 
+	return &createSequenceNode{
+		n:      n,
+		dbDesc: dbDesc,
+	}, nil
+}
+
+func (n *createSequenceNode) Start(params runParams) error {
+	seqName := n.n.Name.TableName().Table()
+	tKey := tableKey{parentID: n.dbDesc.ID, name: seqName}
+	key := tKey.Key()
+	if exists, err := descExists(params.ctx, params.p.txn, key); err == nil && exists {
+		if n.n.IfNotExists {
+			// If the sequence exists and the user specified IF NOT EXISTS, do nothing.
+			return nil
+		}
+		return sqlbase.NewRelationAlreadyExistsError(tKey.Name())
+	} else if err != nil {
+		return err
+	}
+
+	id, err := GenerateUniqueDescID(params.ctx, params.p.session.execCfg.DB)
+	if err != nil {
+		return err
+	}
+
+	// Inherit permissions from the database descriptor.
+	privs := n.dbDesc.GetPrivileges()
+
+	desc, err := makeSequenceTableDesc(
+		seqName, n.n.Options, n.dbDesc.ID, id, params.p.txn.OrigTimestamp(), privs)
+	if err != nil {
+		return err
+	}
+
+	if err = desc.ValidateTable(); err != nil {
+		return err
+	}
+
+	if err = params.p.createDescriptorWithID(params.ctx, key, id, &desc); err != nil {
+		return err
+	}
+
+	// Initialize the sequence value so that the first call to nextval()
+	// returns the start value. Like all updates of sequence values, this
+	// happens outside of the SQL transaction.
+	seqValueKey := keys.MakeSequenceKey(uint32(id))
+	initialValue := desc.SequenceOpts.Start - desc.SequenceOpts.Increment
+	if err := params.p.session.execCfg.DB.Put(params.ctx, seqValueKey, initialValue); err != nil {
+		return err
+	}
+
+	if err := desc.Validate(params.ctx, params.p.txn); err != nil {
+		return err
+	}
+
+	// Log Create Sequence event. This is an auditable log event and is
+	// recorded in the same transaction as the table descriptor update.
+	if err := MakeEventLogger(params.p.LeaseMgr()).InsertEventRecord(
+		params.ctx,
+		params.p.txn,
+		EventLogCreateSequence,
+		int32(desc.ID),
+		int32(params.p.evalCtx.NodeID),
+		struct {
+			SequenceName string
+			Statement    string
+			User         string
+		}{n.n.Name.String(), n.n.String(), params.p.session.User},
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (*createSequenceNode) Next(runParams) (bool, error) { return false, nil }
+func (*createSequenceNode) Values() parser.Datums        { return parser.Datums{} }
+func (*createSequenceNode) Close(context.Context)        {}
+
+type createTableNode struct {
+	n          *parser.CreateTable
+	dbDesc     *sqlbase.DatabaseDescriptor
+	sourcePlan planNode
+	count      int
+}
+
+// CreateTable creates a table.
+// Privileges: CREATE on database. None for temporary tables.
+//   Notes: postgres/mysql require CREATE on database.
+//          postgres requires TEMPORARY on the database for temporary tables,
+//          which is granted to everyone by default.
+func (p *planner) CreateTable(ctx context.Context, n *parser.CreateTable) (planNode, error) {
+	var dbDesc *sqlbase.DatabaseDescriptor
+	if n.Temporary {
+		// The temporary schema is created along with the table if needed, see
+		// createTableNode.Start().
+		if n.Interleave != nil {
+			return nil, pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+				"temporary tables cannot be interleaved")
+		}
+		if err := p.qualifyTemporaryTableName(&n.Table); err != nil {
+			return nil, err
+		}
+	} else {
+		tn, err := p.normalizeNewTableName(&n.Table)
+		if err != nil {
+			return nil, err
+		}
+
+		dbDesc, err = MustGetDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), tn.Database())
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.CheckPrivilege(ctx, dbDesc, privilege.CREATE); err != nil {
+			return nil, err
+		}
+	}
+
 	hoistConstraints(n)
 	for _, def := range n.Defs {
 		switch t := def.(type) {
 		case *parser.ForeignKeyConstraintTableDef:
-			if _, err := t.Table.NormalizeWithDatabaseName(p.session.Database); err != nil {
+			tn, err := p.normalizeTableName(ctx, &t.Table)
+			if err != nil {
 				return nil, err
 			}
+			if !n.Temporary && isTemporarySchemaName(tn.Database()) {
+				return nil, pgerror.NewError(pgerror.CodeInvalidTableDefinitionError,
+					"constraints on permanent tables may reference only permanent tables")
+			}
 		}
 	}
 
@@ -541,6 +735,7 @@ func (p *planner) CreateTable(ctx context.Context, n *parser.CreateTable) (planN
 		// to populate the new table descriptor in Start() below. We
 		// instantiate the sourcePlan as early as here so that EXPLAIN has
 		// something useful to show about CREATE TABLE .. AS ...
+		var err error
 		sourcePlan, err = p.Select(ctx, n.AsSource, []parser.Type{})
 		if err != nil {
 			return nil, err
@@ -586,6 +781,7 @@ func hoistConstraints(n *parser.CreateTable) {
 					FromCols: parser.NameList{col.Name},
 					ToCols:   targetCol,
 					Name:     col.References.ConstraintName,
+					Actions:  col.References.Actions,
 				})
 				col.References.Table = parser.NormalizableTableName{}
 			}
@@ -594,6 +790,13 @@ func hoistConstraints(n *parser.CreateTable) {
 }
 
 func (n *createTableNode) Start(params runParams) error {
+	if n.n.Temporary {
+		var err error
+		if n.dbDesc, err = params.p.getOrCreateTemporarySchema(params.ctx); err != nil {
+			return err
+		}
+	}
+
 	tKey := tableKey{parentID: n.dbDesc.ID, name: n.n.Table.TableName().Table()}
 	key := tKey.Key()
 	if exists, err := descExists(params.ctx, params.p.txn, key); err == nil && exists {
@@ -775,6 +978,55 @@ func (p *planner) resolveFK(
 // "unvalidated", but when table is empty (e.g. during creation), no existing
 // data imples no existing violations, and thus the constraint can be created
 // without the unvalidated flag.
+// foreignKeyReferenceActionValue maps parser.ReferenceAction to the
+// corresponding value of the FK descriptor.
+var foreignKeyReferenceActionValue = [...]sqlbase.ForeignKeyReference_Action{
+	parser.NoAction:   sqlbase.ForeignKeyReference_NO_ACTION,
+	parser.Restrict:   sqlbase.ForeignKeyReference_RESTRICT,
+	parser.SetNull:    sqlbase.ForeignKeyReference_SET_NULL,
+	parser.SetDefault: sqlbase.ForeignKeyReference_SET_DEFAULT,
+	parser.Cascade:    sqlbase.ForeignKeyReference_CASCADE,
+}
+
+// referenceActionFromValue is the inverse of
+// foreignKeyReferenceActionValue.
+func referenceActionFromValue(v sqlbase.ForeignKeyReference_Action) parser.ReferenceAction {
+	for a, val := range foreignKeyReferenceActionValue {
+		if val == v {
+			return parser.ReferenceAction(a)
+		}
+	}
+	return parser.NoAction
+}
+
+// checkReferenceActions verifies that the referential actions of a FK
+// can be carried out on the referencing columns.
+func checkReferenceActions(
+	actions parser.ReferenceActions, srcCols []sqlbase.ColumnDescriptor,
+) error {
+	for _, action := range []parser.ReferenceAction{actions.Delete, actions.Update} {
+		switch action {
+		case parser.SetNull:
+			for _, col := range srcCols {
+				if !col.Nullable {
+					return pgerror.NewErrorf(pgerror.CodeInvalidForeignKeyError,
+						"cannot add a SET NULL action on column %q which has a NOT NULL constraint",
+						col.Name)
+				}
+			}
+		case parser.SetDefault:
+			for _, col := range srcCols {
+				if !col.Nullable && col.DefaultExpr == nil {
+					return pgerror.NewErrorf(pgerror.CodeInvalidForeignKeyError,
+						"cannot add a SET DEFAULT action on column %q which has a NOT NULL "+
+							"constraint and a NULL default expression", col.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
 func resolveFK(
 	ctx context.Context,
 	txn *client.Txn,
@@ -882,11 +1134,17 @@ func resolveFK(
 		}
 	}
 
+	if err := checkReferenceActions(d.Actions, srcCols); err != nil {
+		return err
+	}
+
 	ref := sqlbase.ForeignKeyReference{
 		Table:           target.ID,
 		Index:           targetIdx.ID,
 		Name:            constraintName,
 		SharedPrefixLen: int32(len(srcCols)),
+		OnDelete:        foreignKeyReferenceActionValue[d.Actions.Delete],
+		OnUpdate:        foreignKeyReferenceActionValue[d.Actions.Update],
 	}
 	if mode == sqlbase.ConstraintValidity_Unvalidated {
 		ref.Validity = sqlbase.ConstraintValidity_Unvalidated
@@ -1151,6 +1409,9 @@ func (n *createViewNode) makeViewTableDesc(
 ) (sqlbase.TableDescriptor, error) {
 	desc := initTableDescriptor(id, parentID, viewName, n.p.txn.OrigTimestamp(), privileges)
 	desc.ViewQuery = parser.AsStringWithFlags(n.n.AsSource, parser.FmtParsable)
+	// A materialized view must be marked as such before its IDs are allocated,
+	// so that it is given a primary key.
+	desc.MaterializedView = n.n.Materialized
 	for i, colRes := range resultColumns {
 		colType, err := parser.DatumTypeToColumnType(colRes.Typ)
 		if err != nil {
@@ -1171,6 +1432,30 @@ func (n *createViewNode) makeViewTableDesc(
 	return desc, desc.AllocateIDs()
 }
 
+// makeSequenceTableDesc returns the table descriptor for a new sequence.
+//
+// Like views, sequences are created directly in the PUBLIC state.
+func makeSequenceTableDesc(
+	sequenceName string,
+	sequenceOptions parser.SequenceOptions,
+	parentID sqlbase.ID,
+	id sqlbase.ID,
+	creationTime hlc.Timestamp,
+	privileges *sqlbase.PrivilegeDescriptor,
+) (sqlbase.TableDescriptor, error) {
+	desc := initTableDescriptor(id, parentID, sequenceName, creationTime, privileges)
+
+	opts := &sqlbase.TableDescriptor_SequenceOpts{
+		Increment: 1,
+	}
+	if err := assignSequenceOptions(opts, sequenceOptions, true /* setDefaults */); err != nil {
+		return desc, err
+	}
+	desc.SequenceOpts = opts
+
+	return desc, desc.AllocateIDs()
+}
+
 // makeTableDescIfAs is the MakeTableDesc method for when we have a table
 // that is created with the CREATE AS format.
 func makeTableDescIfAs(
@@ -1267,13 +1552,24 @@ func MakeTableDesc(
 			// pass, handled above.
 
 		case *parser.IndexTableDef:
+			if hasIndexExprs(d.Columns) {
+				// Handled after the IDs of the columns are allocated.
+				continue
+			}
 			idx := sqlbase.IndexDescriptor{
 				Name:             string(d.Name),
 				StoreColumnNames: d.Storing.ToStrings(),
 			}
+			if d.Inverted {
+				idx.Type = sqlbase.IndexDescriptor_INVERTED
+			}
 			if err := idx.FillColumns(d.Columns); err != nil {
 				return desc, err
 			}
+			if idx.Partitioning, err = createPartitioning(
+				evalCtx, searchPath, &desc, &idx, d.PartitionBy); err != nil {
+				return desc, err
+			}
 			if err := desc.AddIndex(idx, false); err != nil {
 				return desc, err
 			}
@@ -1281,6 +1577,10 @@ func MakeTableDesc(
 				return desc, pgerror.UnimplementedWithIssueError(9148, "use CREATE INDEX to make interleaved indexes")
 			}
 		case *parser.UniqueConstraintTableDef:
+			if !d.PrimaryKey && hasIndexExprs(d.Columns) {
+				// Handled after the IDs of the columns are allocated.
+				continue
+			}
 			idx := sqlbase.IndexDescriptor{
 				Name:             string(d.Name),
 				Unique:           true,
@@ -1289,6 +1589,10 @@ func MakeTableDesc(
 			if err := idx.FillColumns(d.Columns); err != nil {
 				return desc, err
 			}
+			if idx.Partitioning, err = createPartitioning(
+				evalCtx, searchPath, &desc, &idx, d.PartitionBy); err != nil {
+				return desc, err
+			}
 			if err := desc.AddIndex(idx, d.PrimaryKey); err != nil {
 				return desc, err
 			}
@@ -1336,6 +1640,70 @@ func MakeTableDesc(
 		return desc, err
 	}
 
+	// The primary index may only have been created by AllocateIDs.
+	if n.PartitionBy != nil {
+		if n.Interleave != nil {
+			return desc, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"cannot partition an interleaved index")
+		}
+		if desc.PrimaryIndex.Partitioning, err = createPartitioning(
+			evalCtx, searchPath, &desc, &desc.PrimaryIndex, n.PartitionBy); err != nil {
+			return desc, err
+		}
+	}
+
+	// Indexes on expressions reference the columns by ID, so they are added
+	// once the IDs are allocated.
+	for _, def := range n.Defs {
+		var idx sqlbase.IndexDescriptor
+		var elems parser.IndexElemList
+		var interleave *parser.InterleaveDef
+		var partitionBy *parser.PartitionBy
+		switch d := def.(type) {
+		case *parser.IndexTableDef:
+			idx = sqlbase.IndexDescriptor{Name: string(d.Name), StoreColumnNames: d.Storing.ToStrings()}
+			if d.Inverted {
+				idx.Type = sqlbase.IndexDescriptor_INVERTED
+			}
+			elems, interleave, partitionBy = d.Columns, d.Interleave, d.PartitionBy
+		case *parser.UniqueConstraintTableDef:
+			if d.PrimaryKey {
+				continue
+			}
+			idx = sqlbase.IndexDescriptor{
+				Name:             string(d.Name),
+				Unique:           true,
+				StoreColumnNames: d.Storing.ToStrings(),
+			}
+			elems, interleave, partitionBy = d.Columns, d.Interleave, d.PartitionBy
+		default:
+			continue
+		}
+		if !hasIndexExprs(elems) {
+			continue
+		}
+		if interleave != nil {
+			return desc, pgerror.UnimplementedWithIssueError(9148, "use CREATE INDEX to make interleaved indexes")
+		}
+		exprCols, err := fillIndexColumns(&desc, &idx, elems, searchPath)
+		if err != nil {
+			return desc, err
+		}
+		idx.Partitioning, err = createPartitioning(evalCtx, searchPath, &desc, &idx, partitionBy)
+		if err != nil {
+			return desc, err
+		}
+		for _, col := range exprCols {
+			desc.AddColumn(col)
+		}
+		if err := desc.AddIndex(idx, false); err != nil {
+			return desc, err
+		}
+		if err := desc.AllocateIDs(); err != nil {
+			return desc, err
+		}
+	}
+
 	if n.Interleave != nil {
 		if err := addInterleave(ctx, txn, vt, &desc, &desc.PrimaryIndex, n.Interleave, sessionDB); err != nil {
 			return desc, err
@@ -0,0 +1,329 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+)
+
+const (
+	// histogramSamples is the number of sampled values from which the
+	// histogram of a column is built.
+	histogramSamples = 10000
+	// histogramBuckets is the maximum number of buckets in a histogram.
+	histogramBuckets = 200
+)
+
+// createStatsNode represents a CREATE STATISTICS statement.
+type createStatsNode struct {
+	n         *parser.CreateStats
+	tableDesc *sqlbase.TableDescriptor
+	column    sqlbase.ColumnDescriptor
+}
+
+// CreateStatistics collects statistics on a column of a table and stores them
+// in system.table_statistics.
+// Privileges: SELECT on table.
+func (p *planner) CreateStatistics(ctx context.Context, n *parser.CreateStats) (planNode, error) {
+	tn, err := p.normalizeTableName(ctx, &n.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	tableDesc, err := MustGetTableDesc(ctx, p.txn, p.getVirtualTabler(), tn, false /* allowAdding */)
+	if err != nil {
+		return nil, err
+	}
+	if !tableDesc.IsTable() || tableDesc.IsVirtualTable() {
+		return nil, sqlbase.NewWrongObjectTypeError(tn, "table")
+	}
+
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege.SELECT); err != nil {
+		return nil, err
+	}
+
+	if len(n.ColumnNames) != 1 {
+		return nil, pgerror.Unimplemented("multi-column statistics",
+			"statistics on multiple columns are not supported")
+	}
+	column, err := tableDesc.FindActiveColumnByName(string(n.ColumnNames[0]))
+	if err != nil {
+		return nil, err
+	}
+
+	return &createStatsNode{
+		n:         n,
+		tableDesc: tableDesc,
+		column:    column,
+	}, nil
+}
+
+// Start creates a CREATE STATS job and waits for it to complete. The job is
+// created and run outside of the user transaction, so that the transaction is
+// not held open while the table is scanned; it holds a lease so that it is
+// resumed by another node if this one dies.
+func (n *createStatsNode) Start(params runParams) error {
+	p := params.p
+	execCfg := p.ExecCfg()
+	dsp := p.session.distSQLPlanner
+
+	// The job outlives the statement if the client goes away.
+	jobCtx, cancel := context.WithCancel(execCfg.AmbientCtx.AnnotateCtx(context.Background()))
+	job := execCfg.JobRegistry.NewJob(jobs.Record{
+		Description:   n.n.String(),
+		Username:      p.User(),
+		DescriptorIDs: sqlbase.IDs{n.tableDesc.ID},
+		Details: jobs.CreateStatsDetails{
+			Name:      string(n.n.Name),
+			TableID:   n.tableDesc.ID,
+			ColumnIDs: []sqlbase.ColumnID{n.column.ID},
+		},
+	})
+	if err := job.Created(params.ctx, cancel); err != nil {
+		cancel()
+		return err
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		defer cancel()
+		errCh <- runCreateStatsJob(jobCtx, execCfg, dsp, job)
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-params.ctx.Done():
+		return params.ctx.Err()
+	}
+}
+
+func (*createStatsNode) Next(runParams) (bool, error) { return false, nil }
+func (*createStatsNode) Values() parser.Datums        { return parser.Datums{} }
+func (*createStatsNode) Close(context.Context)        {}
+
+// runCreateStatsJob runs a CREATE STATS job that was created on this node and
+// records its outcome.
+func runCreateStatsJob(
+	ctx context.Context, execCfg *ExecutorConfig, dsp *distSQLPlanner, job *jobs.Job,
+) error {
+	if err := job.Started(ctx); err != nil {
+		return err
+	}
+	details := job.Record.Details.(jobs.CreateStatsDetails)
+	statsErr := createStatistic(ctx, execCfg, dsp, details)
+	if err := job.FinishedWith(ctx, statsErr); err != nil {
+		return err
+	}
+	return statsErr
+}
+
+// createStatsResumeHook resumes the CREATE STATS jobs abandoned by dead nodes.
+func (e *Executor) createStatsResumeHook(typ jobs.Type) func(context.Context, *jobs.Job) error {
+	if typ != jobs.TypeCreateStats {
+		return nil
+	}
+	return func(ctx context.Context, job *jobs.Job) error {
+		return createStatistic(ctx, &e.cfg, e.distSQLPlanner, job.Record.Details.(jobs.CreateStatsDetails))
+	}
+}
+
+// createStatistic collects the statistics described by a CREATE STATS job
+// and inserts them into system.table_statistics. The table is scanned by a
+// distributed plan of sampler processors, whose results are merged by a
+// sample aggregator; see createStatsPlan.
+func createStatistic(
+	ctx context.Context,
+	execCfg *ExecutorConfig,
+	dsp *distSQLPlanner,
+	details jobs.CreateStatsDetails,
+) error {
+	var rows collectRowsWriter
+	if err := execCfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		rows = rows[:0]
+		tableDesc, err := sqlbase.GetTableDescFromID(ctx, txn, details.TableID)
+		if err != nil {
+			return err
+		}
+		if tableDesc.Dropped() {
+			return errors.Errorf("table %q is being dropped", tableDesc.Name)
+		}
+
+		recv, err := makeDistSQLReceiver(
+			ctx, &rows,
+			execCfg.RangeDescriptorCache, execCfg.LeaseHolderCache,
+			txn,
+			func(ts hlc.Timestamp) {
+				_ = execCfg.Clock.Update(ts)
+			},
+		)
+		if err != nil {
+			return err
+		}
+		planCtx := dsp.NewPlanningCtx(ctx, txn)
+		plan, err := dsp.createStatsPlan(&planCtx, *tableDesc, details.ColumnIDs)
+		if err != nil {
+			return err
+		}
+		evalCtx := createSchemaChangeEvalCtx(txn.OrigTimestamp())
+		if err := dsp.Run(&planCtx, txn, &plan, &recv, evalCtx); err != nil {
+			return err
+		}
+		return recv.err
+	}); err != nil {
+		return err
+	}
+	if len(rows) != 1 {
+		return errors.Errorf("expected 1 row, got %d", len(rows))
+	}
+	row := rows[0]
+	rowCount := int64(parser.MustBeDInt(row[0]))
+	distinctCount := int64(parser.MustBeDInt(row[1]))
+	nullCount := int64(parser.MustBeDInt(row[2]))
+	var histogramData interface{}
+	if h, ok := row[3].(*parser.DBytes); ok {
+		histogramData = []byte(*h)
+	}
+
+	columnIDs := parser.NewDArray(parser.TypeInt)
+	for _, id := range details.ColumnIDs {
+		if err := columnIDs.Append(parser.NewDInt(parser.DInt(id))); err != nil {
+			return err
+		}
+	}
+	var nameArg interface{}
+	if details.Name != "" {
+		nameArg = details.Name
+	}
+
+	// The statistics are written as root, since system.table_statistics is not
+	// writable by regular users.
+	ie := InternalExecutor{LeaseManager: execCfg.LeaseManager}
+	if err := execCfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		_, err := ie.ExecuteStatementInTransaction(
+			ctx, "insert-statistic", txn,
+			`INSERT INTO system.table_statistics (
+					"tableID", name, "columnIDs", "rowCount", "distinctCount", "nullCount", histogram
+				) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			details.TableID, nameArg, columnIDs, rowCount, distinctCount, nullCount, histogramData,
+		)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	if cache := execCfg.TableStatsCache; cache != nil {
+		cache.InvalidateTableStats(ctx, details.TableID)
+	}
+	return nil
+}
+
+// collectRowsWriter is a rowResultWriter that accumulates the rows of a
+// query in memory.
+type collectRowsWriter []parser.Datums
+
+var _ rowResultWriter = &collectRowsWriter{}
+
+// AddRow implements the rowResultWriter interface.
+func (w *collectRowsWriter) AddRow(_ context.Context, row parser.Datums) error {
+	*w = append(*w, append(parser.Datums(nil), row...))
+	return nil
+}
+
+// IncrementRowsAffected implements the rowResultWriter interface.
+func (*collectRowsWriter) IncrementRowsAffected(int) {}
+
+// StatementType implements the rowResultWriter interface.
+func (*collectRowsWriter) StatementType() parser.StatementType { return parser.Rows }
+
+// refreshTableStats re-collects the most recent statistics of each column of
+// a table. It is used by the automatic statistics Refresher.
+func (e *Executor) refreshTableStats(ctx context.Context, tableID sqlbase.ID) error {
+	tableStats, err := e.cfg.TableStatsCache.GetTableStats(ctx, tableID)
+	if err != nil {
+		return err
+	}
+
+	var tn parser.TableName
+	var tableDesc *sqlbase.TableDescriptor
+	if err := e.cfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		var err error
+		tableDesc, err = sqlbase.GetTableDescFromID(ctx, txn, tableID)
+		if err != nil {
+			return err
+		}
+		dbDesc, err := getDatabaseDescByID(ctx, txn, tableDesc.ParentID)
+		if err != nil {
+			return err
+		}
+		if dbDesc == nil {
+			return errors.Errorf("database %d of table %d does not exist", tableDesc.ParentID, tableID)
+		}
+		tn = parser.TableName{
+			DatabaseName: parser.Name(dbDesc.Name),
+			TableName:    parser.Name(tableDesc.Name),
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if tableDesc.Dropped() {
+		return nil
+	}
+
+	session := NewSession(
+		ctx, SessionArgs{User: security.RootUser}, e, nil /* remote */, e.cfg.LeaseManager.memMetrics,
+	)
+	session.StartUnlimitedMonitor()
+	defer session.Finish(e)
+
+	// The statistics are sorted by creation time, most recent first; only the
+	// most recent statistic of each column is refreshed.
+	refreshed := make(map[sqlbase.ColumnID]struct{})
+	for _, s := range tableStats {
+		if len(s.ColumnIDs) != 1 {
+			continue
+		}
+		if _, ok := refreshed[s.ColumnIDs[0]]; ok {
+			continue
+		}
+		refreshed[s.ColumnIDs[0]] = struct{}{}
+		col, err := tableDesc.FindColumnByID(s.ColumnIDs[0])
+		if err != nil {
+			// The column was dropped.
+			continue
+		}
+		stmt := &parser.CreateStats{
+			Name:        parser.Name(s.Name),
+			ColumnNames: parser.NameList{parser.Name(col.Name)},
+			Table:       parser.NormalizableTableName{TableNameReference: &tn},
+		}
+		res, err := e.ExecuteStatementsBuffered(session, stmt.String(), nil, 1)
+		if err != nil {
+			return err
+		}
+		res.Close(ctx)
+	}
+	return nil
+}
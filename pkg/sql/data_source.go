@@ -253,6 +253,24 @@ func (p *planner) getSources(
 		return p.getDataSource(ctx, sources[0], nil, scanVisibility)
 
 	default:
+		if k := lastLateralSource(sources); k > 0 {
+			// The LATERAL data source can refer to the sources that
+			// precede it, so these must be joined first.
+			left, err := p.getSources(ctx, sources[:k], scanVisibility)
+			if err != nil {
+				return planDataSource{}, err
+			}
+			src, err := p.makeLateralJoin(ctx, "CROSS JOIN", left, sources[k], nil, scanVisibility)
+			if err != nil || k == len(sources)-1 {
+				return src, err
+			}
+			right, err := p.getSources(ctx, sources[k+1:], scanVisibility)
+			if err != nil {
+				return planDataSource{}, err
+			}
+			return p.makeJoin(ctx, "CROSS JOIN", src, right, nil)
+		}
+
 		left, err := p.getDataSource(ctx, sources[0], nil, scanVisibility)
 		if err != nil {
 			return planDataSource{}, err
@@ -334,6 +352,15 @@ func (p *planner) getDataSource(
 ) (planDataSource, error) {
 	switch t := src.(type) {
 	case *parser.NormalizableTableName:
+		// Is this perhaps a name for a common table expression?
+		ds, foundCTE, err := p.getCTEDataSource(ctx, t)
+		if err != nil {
+			return planDataSource{}, err
+		}
+		if foundCTE {
+			return ds, nil
+		}
+
 		// Usual case: a table.
 		tn, err := p.QualifyWithDatabase(ctx, t)
 		if err != nil {
@@ -362,6 +389,9 @@ func (p *planner) getDataSource(
 		if err != nil {
 			return left, err
 		}
+		if isLateralSource(t.Right) {
+			return p.makeLateralJoin(ctx, t.Join, left, t.Right, t.Cond, scanVisibility)
+		}
 		right, err := p.getDataSource(ctx, t.Right, nil, scanVisibility)
 		if err != nil {
 			return right, err
@@ -422,6 +452,8 @@ func (p *planner) QualifyWithDatabase(
 		if err := p.searchAndQualifyDatabase(ctx, tn); err != nil {
 			return nil, err
 		}
+	} else if err := p.resolveTemporarySchema(tn); err != nil {
+		return nil, err
 	}
 	return tn, nil
 }
@@ -556,20 +588,25 @@ func (p *planner) getPlanForDesc(
 	scanVisibility scanVisibility,
 	wantedColumns []parser.ColumnID,
 ) (planDataSource, error) {
-	if desc.IsView() {
+	if desc.IsView() && !desc.IsMaterializedView() {
 		if wantedColumns != nil {
 			return planDataSource{},
 				errors.Errorf("cannot specify an explicit column list when accessing a view by reference")
 		}
 		return p.getViewPlan(ctx, tn, desc)
-	} else if !desc.IsTable() {
+	} else if desc.IsSequence() {
+		return planDataSource{}, pgerror.NewErrorf(pgerror.CodeWrongObjectTypeError,
+			"cannot select from sequence %q; use nextval(), currval() or setval() instead",
+			parser.ErrString(tn))
+	} else if !desc.IsPhysicalTable() {
 		return planDataSource{}, errors.Errorf(
 			"unexpected table descriptor of type %s for %q", desc.TypeName(), parser.ErrString(tn))
 	}
 
-	// This name designates a real table.
+	// This name designates a real table or a materialized view, whose rows are
+	// stored like those of a table.
 	scan := p.Scan()
-	if err := scan.initTable(p, desc, hints, scanVisibility, wantedColumns); err != nil {
+	if err := scan.initTable(ctx, p, desc, hints, scanVisibility, wantedColumns); err != nil {
 		return planDataSource{}, err
 	}
 
@@ -600,7 +637,7 @@ func (p *planner) getViewPlan(
 	// SELECT privileges on the view, which is intended to allow for exposing
 	// some subset of a restricted table's data to less privileged users.
 	if !p.skipSelectPrivilegeChecks {
-		if err := p.CheckPrivilege(desc, privilege.SELECT); err != nil {
+		if err := p.CheckPrivilege(ctx, desc, privilege.SELECT); err != nil {
 			return planDataSource{}, err
 		}
 		p.skipSelectPrivilegeChecks = true
@@ -624,6 +661,14 @@ func (p *planner) getViewPlan(
 		p.planDeps = nil
 	}
 
+	// The view's query cannot refer to the common table expressions
+	// defined by the query that uses the view, nor to its lateral
+	// scopes.
+	defer func(prev *cteNameEnvironment) { p.cteNameEnvironment = prev }(p.cteNameEnvironment)
+	p.cteNameEnvironment = nil
+	defer func(prev []*lateralScope) { p.lateralScopes = prev }(p.lateralScopes)
+	p.lateralScopes = nil
+
 	// TODO(a-robinson): Support ORDER BY and LIMIT in views. Is it as simple as
 	// just passing the entire select here or will inserting an ORDER BY in the
 	// middle of a query plan break things?
@@ -53,12 +53,20 @@ type deleteNode struct {
 //          mysql requires DELETE. Also requires SELECT if a table is used in the "WHERE" clause.
 func (p *planner) Delete(
 	ctx context.Context, n *parser.Delete, desiredTypes []parser.Type,
-) (planNode, error) {
+) (result planNode, err error) {
 	if n.Where == nil && p.session.SafeUpdates {
 		return nil, pgerror.NewDangerousStatementErrorf("DELETE without WHERE clause")
 	}
 
-	tn, err := p.getAliasedTableName(n.Table)
+	finishWith, err := p.initWith(ctx, n.With)
+	if err != nil {
+		return nil, err
+	}
+	if finishWith != nil {
+		defer func() { result, err = finishWith(result, err) }()
+	}
+
+	tn, err := p.getAliasedTableName(ctx, n.Table)
 	if err != nil {
 		return nil, err
 	}
@@ -68,8 +76,13 @@ func (p *planner) Delete(
 		return nil, err
 	}
 
+	triggers, err := p.makeRowTriggers(ctx, en.tableDesc, parser.TriggerDelete)
+	if err != nil {
+		return nil, err
+	}
+
 	var requestedCols []sqlbase.ColumnDescriptor
-	if _, retExprs := n.Returning.(*parser.ReturningExprs); retExprs {
+	if _, retExprs := n.Returning.(*parser.ReturningExprs); retExprs || triggers != nil {
 		// TODO(dan): This could be made tighter, just the rows needed for RETURNING
 		// exprs.
 		requestedCols = en.tableDesc.Columns
@@ -80,11 +93,18 @@ func (p *planner) Delete(
 		return nil, err
 	}
 	rd, err := sqlbase.MakeRowDeleter(p.txn, en.tableDesc, fkTables, requestedCols,
-		sqlbase.CheckFKs, &p.alloc)
+		sqlbase.CheckFKs, &p.evalCtx, &p.alloc)
 	if err != nil {
 		return nil, err
 	}
-	tw := tableDeleter{rd: rd, autoCommit: p.autoCommit, alloc: &p.alloc}
+	// The AFTER triggers run once the rows are deleted, so the transaction
+	// cannot be committed along with them.
+	tw := tableDeleter{
+		rd:         rd,
+		autoCommit: p.autoCommit && triggers == nil,
+		alloc:      &p.alloc,
+		triggers:   triggers,
+	}
 
 	// TODO(knz): Until we split the creation of the node from Start()
 	// for the SelectClause too, we cannot cache this. This is because
@@ -109,7 +129,8 @@ func (p *planner) Delete(
 	}
 
 	if err := dn.run.initEditNode(
-		ctx, &dn.editNodeBase, rows, &dn.tw, tn, n.Returning, desiredTypes); err != nil {
+		ctx, &dn.editNodeBase, rows, &dn.tw, dn.tableSourceInfo(tn),
+		n.Returning, desiredTypes); err != nil {
 		return nil, err
 	}
 
@@ -155,32 +176,42 @@ func (d *deleteNode) FastPathResults() (int, bool) {
 func (d *deleteNode) Next(params runParams) (bool, error) {
 	traceKV := d.p.session.Tracing.KVTracingEnabled()
 
-	next, err := d.run.rows.Next(params)
-	if !next {
-		if err == nil {
-			if err := params.p.cancelChecker.Check(); err != nil {
-				return false, err
+	for {
+		next, err := d.run.rows.Next(params)
+		if !next {
+			if err == nil {
+				if err := params.p.cancelChecker.Check(); err != nil {
+					return false, err
+				}
+				// We're done. Finish the batch.
+				_, err = d.tw.finalize(params.ctx, traceKV)
+				if err == nil {
+					d.notifyMutation(d.run.numRows)
+				}
 			}
-			// We're done. Finish the batch.
-			_, err = d.tw.finalize(params.ctx, traceKV)
+			return false, err
 		}
-		return false, err
-	}
 
-	rowVals := d.run.rows.Values()
+		rowVals := d.run.rows.Values()
 
-	_, err = d.tw.row(params.ctx, rowVals, traceKV)
-	if err != nil {
-		return false, err
-	}
+		_, err = d.tw.row(params.ctx, rowVals, traceKV)
+		if err != nil {
+			return false, err
+		}
+		if d.tw.triggers.rowSkipped() {
+			// A BEFORE trigger skipped the row.
+			continue
+		}
+		d.run.numRows++
 
-	resultRow, err := d.rh.cookResultRow(rowVals)
-	if err != nil {
-		return false, err
-	}
-	d.run.resultRow = resultRow
+		resultRow, err := d.rh.cookResultRow(rowVals)
+		if err != nil {
+			return false, err
+		}
+		d.run.resultRow = resultRow
 
-	return true, nil
+		return true, nil
+	}
 }
 
 // Determine if the deletion of `rows` can be done without actually scanning them,
@@ -192,6 +223,12 @@ func canDeleteWithoutScan(
 	if !td.fastPathAvailable(ctx) {
 		return false
 	}
+	if td.triggers != nil {
+		if log.V(2) {
+			log.Infof(ctx, "delete forced to scan: values required for triggers")
+		}
+		return false
+	}
 	if _, ok := n.Returning.(*parser.ReturningExprs); ok {
 		if log.V(2) {
 			log.Infof(ctx, "delete forced to scan: values required for RETURNING")
@@ -226,6 +263,7 @@ func (d *deleteNode) fastDelete(ctx context.Context, scan *scanNode) error {
 		return err
 	}
 	d.rh.rowCount += rowCount
+	d.notifyMutation(rowCount)
 	return nil
 }
 
@@ -42,6 +42,11 @@ func (p *planner) Discard(ctx context.Context, s *parser.Discard) (planNode, err
 
 		// DEALLOCATE ALL
 		p.session.PreparedStatements.DeleteAll(ctx)
+
+		// DISCARD TEMP
+		return p.discardTemporarySchema(ctx)
+	case parser.DiscardModeTemp:
+		return p.discardTemporarySchema(ctx)
 	default:
 		return nil, pgerror.NewErrorf(pgerror.CodeInternalError,
 			"unknown mode for DISCARD: %d", s.Mode)
@@ -148,6 +148,10 @@ func (n *distinctNode) encodeValues(values parser.Datums) ([]byte, []byte, error
 	return prefix, suffix, err
 }
 
+func (n *distinctNode) memUsage() int64 {
+	return n.prefixMemAcc.CurrentlyAllocated() + n.suffixMemAcc.CurrentlyAllocated()
+}
+
 func (n *distinctNode) Close(ctx context.Context) {
 	n.plan.Close(ctx)
 	n.prefixSeen = nil
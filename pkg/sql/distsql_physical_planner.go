@@ -272,6 +272,9 @@ func (dsp *distSQLPlanner) checkSupportForNode(node planNode) (distRecommendatio
 		return rec, nil
 
 	case *joinNode:
+		if n.lateral != nil {
+			return 0, newQueryNotSupportedError("lateral joins not supported")
+		}
 		if err := dsp.checkExpr(n.pred.onCond); err != nil {
 			return 0, err
 		}
@@ -286,7 +289,7 @@ func (dsp *distSQLPlanner) checkSupportForNode(node planNode) (distRecommendatio
 		// If either the left or the right side can benefit from distribution, we
 		// should distribute.
 		rec := recLeft.compose(recRight)
-		// If we can do a hash join, we distribute if possible.
+		// If we can do a hash or lookup join, we distribute if possible.
 		if len(n.pred.leftEqualityIndices) > 0 {
 			rec = rec.compose(shouldDistribute)
 		}
@@ -315,6 +318,9 @@ func (dsp *distSQLPlanner) checkSupportForNode(node planNode) (distRecommendatio
 		return rec, nil
 
 	case *indexJoinNode:
+		if n.intersectSpans {
+			return 0, newQueryNotSupportedError("intersections of inverted index spans not supported")
+		}
 		// n.table doesn't have meaningful spans, but we need to check support (e.g.
 		// for any filtering expression).
 		if _, err := dsp.checkSupportForNode(n.table); err != nil {
@@ -852,6 +858,100 @@ func (dsp *distSQLPlanner) CreateBackfiller(
 	return p, nil
 }
 
+// createStatsPlan generates a plan that collects the statistics of a set of
+// columns of a table: table readers scan the primary index, a sampler on each
+// node computes a sample and a cardinality sketch of the columns, and a sample
+// aggregator on this node merges them. The plan is finalized; its single
+// output row is described by distsqlrun.SampleAggregatorOutputTypes.
+func (dsp *distSQLPlanner) createStatsPlan(
+	planCtx *planningCtx, desc sqlbase.TableDescriptor, columnIDs []sqlbase.ColumnID,
+) (physicalPlan, error) {
+	// The table readers output the public columns of the table; find the
+	// positions of the requested columns among them.
+	cols := make([]uint32, len(columnIDs))
+	for i, id := range columnIDs {
+		idx := -1
+		for j := range desc.Columns {
+			if desc.Columns[j].ID == id {
+				idx = j
+				break
+			}
+		}
+		if idx == -1 {
+			return physicalPlan{}, errors.Errorf("column %d of table %s does not exist", id, desc.Name)
+		}
+		cols[i] = uint32(idx)
+	}
+
+	spanPartitions, err := dsp.partitionSpans(planCtx, []roachpb.Span{desc.PrimaryIndexSpan()})
+	if err != nil {
+		return physicalPlan{}, err
+	}
+
+	var p physicalPlan
+	stageID := p.NewStageID()
+	for _, sp := range spanPartitions {
+		tr := &distsqlrun.TableReaderSpec{Table: desc}
+		tr.Spans = make([]distsqlrun.TableReaderSpan, len(sp.spans))
+		for i := range sp.spans {
+			tr.Spans[i].Span = sp.spans[i]
+		}
+
+		proc := distsqlplan.Processor{
+			Node: sp.node,
+			Spec: distsqlrun.ProcessorSpec{
+				Core:    distsqlrun.ProcessorCoreUnion{TableReader: tr},
+				Output:  []distsqlrun.OutputRouterSpec{{Type: distsqlrun.OutputRouterSpec_PASS_THROUGH}},
+				StageID: stageID,
+			},
+		}
+
+		pIdx := p.AddProcessor(proc)
+		p.ResultRouters = append(p.ResultRouters, pIdx)
+	}
+	types := make([]sqlbase.ColumnType, len(desc.Columns))
+	for i := range desc.Columns {
+		types[i] = desc.Columns[i].Type
+	}
+	p.SetLastStagePost(distsqlrun.PostProcessSpec{}, types)
+	p.AddProjection(cols)
+
+	// The sketch covers all the columns; the histogram is built on the first
+	// one.
+	sketch := distsqlrun.SketchSpec{
+		Columns:             make([]uint32, len(cols)),
+		GenerateHistogram:   true,
+		HistogramMaxBuckets: histogramBuckets,
+	}
+	for i := range sketch.Columns {
+		sketch.Columns[i] = uint32(i)
+	}
+	sketches := []distsqlrun.SketchSpec{sketch}
+
+	p.AddNoGroupingStage(
+		distsqlrun.ProcessorCoreUnion{Sampler: &distsqlrun.SamplerSpec{
+			Sketches:   sketches,
+			SampleSize: histogramSamples,
+		}},
+		distsqlrun.PostProcessSpec{},
+		distsqlrun.SamplerOutputTypes(p.ResultTypes),
+		orderingTerminated,
+	)
+	p.AddSingleGroupStage(
+		dsp.nodeDesc.NodeID,
+		distsqlrun.ProcessorCoreUnion{SampleAggregator: &distsqlrun.SampleAggregatorSpec{
+			Sketches:   sketches,
+			SampleSize: histogramSamples,
+		}},
+		distsqlrun.PostProcessSpec{},
+		distsqlrun.SampleAggregatorOutputTypes,
+	)
+
+	p.planToStreamColMap = identityMap(nil, len(distsqlrun.SampleAggregatorOutputTypes))
+	dsp.FinalizePlan(planCtx, &p)
+	return p, nil
+}
+
 // DistLoader uses DistSQL to convert external data formats (csv, etc) into
 // sstables of our mvcc-format key values.
 type DistLoader struct {
@@ -1261,6 +1361,13 @@ func (dsp *distSQLPlanner) addAggregators(
 		if fholder.argRenderIdx != noRenderIdx {
 			aggregations[i].ColIdx = []uint32{uint32(p.planToStreamColMap[fholder.argRenderIdx])}
 		}
+		if fholder.groupingCols != nil {
+			// The arguments of GROUPING are group columns.
+			aggregations[i].ColIdx = make([]uint32, len(fholder.groupingCols))
+			for j, c := range fholder.groupingCols {
+				aggregations[i].ColIdx[j] = uint32(p.planToStreamColMap[c])
+			}
+		}
 		if fholder.hasFilter {
 			col := uint32(p.planToStreamColMap[fholder.filterRenderIdx])
 			aggregations[i].FilterColIdx = &col
@@ -1274,6 +1381,16 @@ func (dsp *distSQLPlanner) addAggregators(
 		groupCols[i] = uint32(p.planToStreamColMap[i])
 	}
 
+	var groupingSets []distsqlrun.AggregatorSpec_GroupingSet
+	if n.groupingSets != nil {
+		groupingSets = make([]distsqlrun.AggregatorSpec_GroupingSet, len(n.groupingSets))
+		for i, s := range n.groupingSets {
+			for _, c := range s.Ordered() {
+				groupingSets[i].Cols = append(groupingSets[i].Cols, uint32(p.planToStreamColMap[c]))
+			}
+		}
+	}
+
 	// We either have a local stage on each stream followed by a final stage, or
 	// just a final stage. We only use a local stage if:
 	//  - the previous stage is distributed on multiple nodes, and
@@ -1283,6 +1400,9 @@ func (dsp *distSQLPlanner) addAggregators(
 	//  - we have a mix of aggregations that use distinct and aggregations that
 	//    don't use distinct. TODO(arjun): This would require doing the same as
 	//    the todo as above.
+	//  - with grouping sets, no grouping set appears more than once (the final
+	//    stage distinguishes the grouping sets by the result of GROUPING on all
+	//    the group columns).
 	multiStage := false
 	allDistinct := true
 	anyDistinct := false
@@ -1298,7 +1418,7 @@ func (dsp *distSQLPlanner) addAggregators(
 
 	if prevStageNode == 0 {
 		// Check that all aggregation functions support a local stage.
-		multiStage = true
+		multiStage = !hasDuplicateGroupingSets(n.groupingSets)
 		for _, e := range aggregations {
 			if e.Distinct {
 				// We can't do local aggregation for functions with distinct.
@@ -1364,6 +1484,7 @@ func (dsp *distSQLPlanner) addAggregators(
 		finalAggSpec = distsqlrun.AggregatorSpec{
 			Aggregations: aggregations,
 			GroupCols:    groupCols,
+			GroupingSets: groupingSets,
 		}
 	} else {
 		// Some aggregations might need multiple aggregation as part of their local
@@ -1460,9 +1581,25 @@ func (dsp *distSQLPlanner) addAggregators(
 			finalGroupCols[i] = uint32(idx)
 		}
 
+		if groupingSets != nil {
+			// The group columns that are not part of a grouping set are NULL in the
+			// output of the local stage. The final stage also groups by the result
+			// of GROUPING on all the group columns, which identifies the grouping
+			// set of each group.
+			finalGroupCols = append(finalGroupCols, uint32(len(localAgg)))
+			localAgg = append(localAgg, distsqlrun.AggregatorSpec_Aggregation{
+				Func:   distsqlrun.AggregatorSpec_GROUPING,
+				ColIdx: groupCols,
+			})
+			intermediateTypes = append(intermediateTypes, sqlbase.ColumnType{
+				SemanticType: sqlbase.ColumnType_INT,
+			})
+		}
+
 		localAggSpec := distsqlrun.AggregatorSpec{
 			Aggregations: localAgg,
 			GroupCols:    groupCols,
+			GroupingSets: groupingSets,
 		}
 
 		p.AddNoGroupingStage(
@@ -1516,8 +1653,10 @@ func (dsp *distSQLPlanner) addAggregators(
 		}
 	}
 
-	if len(finalAggSpec.GroupCols) == 0 || len(p.ResultRouters) == 1 {
-		// No GROUP BY, or we have a single stream. Use a single final aggregator.
+	if len(finalAggSpec.GroupCols) == 0 || len(finalAggSpec.GroupingSets) > 0 ||
+		len(p.ResultRouters) == 1 {
+		// No GROUP BY, grouping sets (which can't be distributed by the group
+		// columns), or we have a single stream. Use a single final aggregator.
 		// If the previous stage was all on a single node, put the final
 		// aggregator there. Otherwise, bring the results back on this node.
 		node := dsp.nodeDesc.NodeID
@@ -1587,6 +1726,19 @@ func (dsp *distSQLPlanner) addAggregators(
 	return nil
 }
 
+// hasDuplicateGroupingSets returns true if a grouping set appears more than
+// once.
+func hasDuplicateGroupingSets(sets []util.FastIntSet) bool {
+	for i := range sets {
+		for j := i + 1; j < len(sets); j++ {
+			if sets[i].Equals(sets[j]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (dsp *distSQLPlanner) createPlanForIndexJoin(
 	planCtx *planningCtx, n *indexJoinNode,
 ) (physicalPlan, error) {
@@ -1691,6 +1843,97 @@ func getTypesForPlanResult(node planNode, planToStreamColMap []int) []sqlbase.Co
 	return types
 }
 
+// createPlanForLookupJoin plans a join whose right side is a scan of an index
+// which is looked up with the values of the left side (see lookupJoinIndex).
+// A join reader is added to each stream of the left side; it looks up the
+// rows of the index matching its input rows, within the spans of the scan.
+func (dsp *distSQLPlanner) createPlanForLookupJoin(
+	planCtx *planningCtx, n *joinNode,
+) (physicalPlan, error) {
+	index := n.lookupIndex()
+	if index == nil {
+		return physicalPlan{}, errors.Errorf("join cannot be run as a lookup join")
+	}
+	scan := n.right.plan.(*scanNode)
+
+	plan, err := dsp.createPlanForNode(planCtx, n.left.plan)
+	if err != nil {
+		return physicalPlan{}, err
+	}
+	numLeftStreamCols := len(plan.ResultTypes)
+
+	trSpec, _, err := initTableReaderSpec(scan)
+	if err != nil {
+		return physicalPlan{}, err
+	}
+	joinReaderSpec := distsqlrun.JoinReaderSpec{
+		Table:         *scan.desc,
+		IndexIdx:      trSpec.IndexIdx,
+		LookupColumns: make([]uint32, len(n.pred.leftEqualityIndices)),
+		Spans:         make([]distsqlrun.TableReaderSpan, len(scan.spans)),
+	}
+	for i, colID := range index.ColumnIDs[:len(joinReaderSpec.LookupColumns)] {
+		eqIdx := lookupEqualityIdx(scan, n.pred.rightEqualityIndices, colID)
+		joinReaderSpec.LookupColumns[i] = uint32(
+			plan.planToStreamColMap[n.pred.leftEqualityIndices[eqIdx]],
+		)
+	}
+	for i := range scan.spans {
+		joinReaderSpec.Spans[i].Span = scan.spans[i]
+	}
+
+	// The columns of the scan are the columns of the table, which follow the
+	// columns of the left stream in the join reader. The values of the
+	// columns of the table which are not needed are not decoded.
+	joinColMap := make([]int, 0, n.pred.numLeftCols+n.pred.numRightCols)
+	for i := 0; i < n.pred.numLeftCols; i++ {
+		joinColMap = append(joinColMap, plan.planToStreamColMap[i])
+	}
+	scanColMap := make([]int, n.pred.numRightCols)
+	for i := range scanColMap {
+		scanColMap[i] = -1
+		if scan.valNeededForCol[i] {
+			scanColMap[i] = numLeftStreamCols + i
+		}
+		joinColMap = append(joinColMap, scanColMap[i])
+	}
+	joinReaderSpec.OnExpr = distsqlplan.MakeExpression(n.pred.onCond, joinColMap)
+
+	// The filter of the scan is applied to the joined rows.
+	post := distsqlrun.PostProcessSpec{
+		Filter:     distsqlplan.MakeExpression(scan.filter, scanColMap),
+		Projection: true,
+	}
+	joinToStreamColMap := makePlanToStreamColMap(len(n.columns))
+	joinCol := 0
+	// This is an inner join, so the merged columns are equivalent to the left
+	// equality columns.
+	for i := 0; i < n.pred.numMergedEqualityColumns; i++ {
+		if !n.columns[joinCol].Omitted {
+			joinToStreamColMap[joinCol] = len(post.OutputColumns)
+			post.OutputColumns = append(post.OutputColumns,
+				uint32(plan.planToStreamColMap[n.pred.leftEqualityIndices[i]]))
+		}
+		joinCol++
+	}
+	for i := 0; i < n.pred.numLeftCols+n.pred.numRightCols; i++ {
+		if !n.columns[joinCol].Omitted && joinColMap[i] != -1 {
+			joinToStreamColMap[joinCol] = len(post.OutputColumns)
+			post.OutputColumns = append(post.OutputColumns, uint32(joinColMap[i]))
+		}
+		joinCol++
+	}
+
+	plan.AddNoGroupingStage(
+		distsqlrun.ProcessorCoreUnion{JoinReader: &joinReaderSpec},
+		post,
+		getTypesForPlanResult(n, joinToStreamColMap),
+		distsqlrun.Ordering{},
+	)
+	plan.planToStreamColMap = joinToStreamColMap
+	return plan, nil
+}
+
 func (dsp *distSQLPlanner) createPlanForJoin(
 	planCtx *planningCtx, n *joinNode,
 ) (physicalPlan, error) {
@@ -1713,6 +1956,12 @@ func (dsp *distSQLPlanner) createPlanForJoin(
 	//    joiner.
 	//
 	//  - The routers of the joiner processors are the result routers of the plan.
+	//
+	// Lookup joins are planned differently, see createPlanForLookupJoin.
+
+	if n.algorithm == lookupJoin {
+		return dsp.createPlanForLookupJoin(planCtx, n)
+	}
 
 	leftPlan, err := dsp.createPlanForNode(planCtx, n.left.plan)
 	if err != nil {
@@ -1788,28 +2037,28 @@ func (dsp *distSQLPlanner) createPlanForJoin(
 		for i, rightPlanCol := range n.pred.rightEqualityIndices {
 			rightEqCols[i] = uint32(rightPlan.planToStreamColMap[rightPlanCol])
 		}
-		if planMergeJoins.Get(&dsp.st.SV) && len(n.mergeJoinOrdering) > 0 &&
+		// The algorithm of the join was chosen during planning.
+		//
+		// TODO(radu): we currently only use merge joins when we have an ordering on
+		// all equality columns. We should relax this by either:
+		//  - implementing a hybrid hash/merge processor which implements merge
+		//    logic on the columns we have an ordering on, and within each merge
+		//    group uses a hashmap on the remaining columns
+		//  - or: adding a sort processor to complete the order
+		if planMergeJoins.Get(&dsp.st.SV) && n.algorithm == mergeJoin &&
 			joinType == distsqlrun.JoinType_INNER {
-			// TODO(radu): we currently only use merge joins when we have an ordering on
-			// all equality columns. We should relax this by either:
-			//  - implementing a hybrid hash/merge processor which implements merge
-			//    logic on the columns we have an ordering on, and within each merge
-			//    group uses a hashmap on the remaining columns
-			//  - or: adding a sort processor to complete the order
-			if len(n.mergeJoinOrdering) == len(n.pred.leftEqualityIndices) {
-				// Excellent! We can use the merge joiner.
-				leftMergeOrd.Columns = make([]distsqlrun.Ordering_Column, len(n.mergeJoinOrdering))
-				rightMergeOrd.Columns = make([]distsqlrun.Ordering_Column, len(n.mergeJoinOrdering))
-				for i, c := range n.mergeJoinOrdering {
-					leftMergeOrd.Columns[i].ColIdx = leftEqCols[c.ColIdx]
-					rightMergeOrd.Columns[i].ColIdx = rightEqCols[c.ColIdx]
-					dir := distsqlrun.Ordering_Column_ASC
-					if c.Direction == encoding.Descending {
-						dir = distsqlrun.Ordering_Column_DESC
-					}
-					leftMergeOrd.Columns[i].Direction = dir
-					rightMergeOrd.Columns[i].Direction = dir
+			// Excellent! We can use the merge joiner.
+			leftMergeOrd.Columns = make([]distsqlrun.Ordering_Column, len(n.mergeJoinOrdering))
+			rightMergeOrd.Columns = make([]distsqlrun.Ordering_Column, len(n.mergeJoinOrdering))
+			for i, c := range n.mergeJoinOrdering {
+				leftMergeOrd.Columns[i].ColIdx = leftEqCols[c.ColIdx]
+				rightMergeOrd.Columns[i].ColIdx = rightEqCols[c.ColIdx]
+				dir := distsqlrun.Ordering_Column_ASC
+				if c.Direction == encoding.Descending {
+					dir = distsqlrun.Ordering_Column_DESC
 				}
+				leftMergeOrd.Columns[i].Direction = dir
+				rightMergeOrd.Columns[i].Direction = dir
 			}
 		}
 	} else {
@@ -121,7 +121,7 @@ func (dsp *distSQLPlanner) Run(
 
 	if logPlanDiagram {
 		log.VEvent(ctx, 1, "creating plan diagram")
-		json, url, err := distsqlrun.GeneratePlanDiagramWithURL(flows)
+		json, url, err := distsqlrun.GeneratePlanDiagramWithURL(flows, nil /* stats */)
 		if err != nil {
 			log.Infof(ctx, "Error generating diagram: %s", err)
 		} else {
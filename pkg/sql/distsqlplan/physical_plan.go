@@ -695,11 +695,14 @@ func (p *PhysicalPlan) GenerateFlowSpecs(
 	flowID := distsqlrun.FlowID{UUID: uuid.MakeV4()}
 	flows := make(map[roachpb.NodeID]distsqlrun.FlowSpec)
 
-	for _, proc := range p.Processors {
+	for i, proc := range p.Processors {
 		flowSpec, ok := flows[proc.Node]
 		if !ok {
 			flowSpec = distsqlrun.FlowSpec{FlowID: flowID, Gateway: gateway}
 		}
+		// The ProcessorID identifies the processor in the runtime statistics
+		// collected for EXPLAIN ANALYZE.
+		proc.Spec.ProcessorID = int32(i)
 		flowSpec.Processors = append(flowSpec.Processors, proc.Spec)
 		flows[proc.Node] = flowSpec
 	}
@@ -22,6 +22,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/mon"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/pkg/errors"
@@ -43,6 +45,14 @@ func GetAggregateInfo(
 		}
 		return parser.NewIdentAggregate, inputTypes[0], nil
 	}
+	if fn == AggregatorSpec_GROUPING {
+		// The result of GROUPING is computed by the aggregator for each grouping
+		// set; the aggregate just returns it.
+		if len(inputTypes) == 0 {
+			return nil, sqlbase.ColumnType{}, errors.Errorf("grouping aggregate needs at least 1 input")
+		}
+		return parser.NewIdentAggregate, sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_INT}, nil
+	}
 
 	datumTypes := make([]parser.Type, len(inputTypes))
 	for i := range inputTypes {
@@ -101,6 +111,12 @@ type aggregator struct {
 	groupCols    columns
 	aggregations []AggregatorSpec_Aggregation
 
+	// groupingSets is set if the rows are aggregated once for each of several
+	// grouping sets; each set contains input column indices. groupColSet
+	// contains all the group columns.
+	groupingSets []util.FastIntSet
+	groupColSet  util.FastIntSet
+
 	buckets map[string]struct{} // The set of bucket keys.
 }
 
@@ -123,6 +139,20 @@ func newAggregator(
 		outputTypes:  make([]sqlbase.ColumnType, len(spec.Aggregations)),
 		bucketsAcc:   flowCtx.EvalCtx.Mon.MakeBoundAccount(),
 	}
+	for _, c := range spec.GroupCols {
+		ag.groupColSet.Add(c)
+	}
+	if len(spec.GroupingSets) > 0 {
+		ag.groupingSets = make([]util.FastIntSet, len(spec.GroupingSets))
+		for i, s := range spec.GroupingSets {
+			for _, c := range s.Cols {
+				if !ag.groupColSet.Contains(c) {
+					return nil, errors.Errorf("grouping set column %d is not a group column", c)
+				}
+				ag.groupingSets[i].Add(c)
+			}
+		}
+	}
 
 	// Loop over the select expressions and extract any aggregate functions --
 	// non-aggregation functions are replaced with parser.NewIdentAggregate,
@@ -159,6 +189,14 @@ func newAggregator(
 		if aggInfo.Distinct {
 			ag.funcs[i].seen = make(map[string]struct{})
 		}
+		if aggInfo.Func == AggregatorSpec_GROUPING {
+			for _, c := range aggInfo.ColIdx {
+				if !ag.groupColSet.Contains(c) {
+					return nil, errors.Errorf("grouping argument %d is not a group column", c)
+				}
+			}
+			ag.funcs[i].groupingValues = ag.groupingValues(aggInfo.ColIdx)
+		}
 
 		ag.outputTypes[i] = retType
 	}
@@ -204,6 +242,13 @@ func (ag *aggregator) Run(ctx context.Context, wg *sync.WaitGroup) {
 	if len(ag.buckets) < 1 && len(ag.groupCols) == 0 {
 		ag.buckets[""] = struct{}{}
 	}
+	// Similarly, an empty grouping set produces a group even if there were no
+	// input rows.
+	for i, s := range ag.groupingSets {
+		if s.Empty() {
+			ag.buckets[string(encoding.EncodeUvarintAscending(nil, uint64(i)))] = struct{}{}
+		}
+	}
 
 	// Render the results.
 	var consumerDone bool
@@ -275,43 +320,101 @@ func (ag *aggregator) accumulateRows(ctx context.Context) (err error) {
 			return nil
 		}
 
-		// The encoding computed here determines which bucket the non-grouping
-		// datums are accumulated to.
-		encoded, err := ag.encode(scratch, row)
-		if err != nil {
-			return err
+		if ag.groupingSets == nil {
+			encoded, err := ag.addRow(ctx, row, -1 /* setIdx */, scratch)
+			if err != nil {
+				return err
+			}
+			scratch = encoded[:0]
+		} else {
+			for setIdx := range ag.groupingSets {
+				encoded, err := ag.addRow(ctx, row, setIdx, scratch)
+				if err != nil {
+					return err
+				}
+				scratch = encoded[:0]
+			}
 		}
+	}
+}
 
-		if err := ag.bucketsAcc.Grow(ctx, int64(len(encoded))); err != nil {
-			return err
-		}
+// addRow adds an input row to its bucket and feeds the func holders for that
+// bucket. If the aggregator has grouping sets, setIdx is the grouping set for
+// which the row is aggregated; otherwise it is -1. The bucket key is built in
+// scratch and returned.
+func (ag *aggregator) addRow(
+	ctx context.Context, row sqlbase.EncDatumRow, setIdx int, scratch []byte,
+) ([]byte, error) {
+	// The encoding computed here determines which bucket the non-grouping
+	// datums are accumulated to.
+	encoded, err := ag.encode(scratch, row, setIdx)
+	if err != nil {
+		return encoded, err
+	}
 
-		ag.buckets[string(encoded)] = struct{}{}
-		// Feed the func holders for this bucket the non-grouping datums.
-		for i, a := range ag.aggregations {
-			if a.FilterColIdx != nil {
-				if err := row[*a.FilterColIdx].EnsureDecoded(&ag.datumAlloc); err != nil {
-					return err
-				}
-				if row[*a.FilterColIdx].Datum != parser.DBoolTrue {
-					// This row doesn't contribute to this aggregation.
-					continue
-				}
+	if err := ag.bucketsAcc.Grow(ctx, int64(len(encoded))); err != nil {
+		return encoded, err
+	}
+
+	ag.buckets[string(encoded)] = struct{}{}
+	// Feed the func holders for this bucket the non-grouping datums.
+	for i, a := range ag.aggregations {
+		if a.FilterColIdx != nil {
+			if err := row[*a.FilterColIdx].EnsureDecoded(&ag.datumAlloc); err != nil {
+				return encoded, err
 			}
-			var value parser.Datum
-			if len(a.ColIdx) != 0 {
-				c := a.ColIdx[0]
-				if err := row[c].EnsureDecoded(&ag.datumAlloc); err != nil {
-					return err
-				}
-				value = row[c].Datum
+			if row[*a.FilterColIdx].Datum != parser.DBoolTrue {
+				// This row doesn't contribute to this aggregation.
+				continue
 			}
-			if err := ag.funcs[i].add(ctx, encoded, value); err != nil {
-				return err
+		}
+		var value parser.Datum
+		switch {
+		case a.Func == AggregatorSpec_GROUPING:
+			value = ag.funcs[i].groupingValues[0]
+			if setIdx >= 0 {
+				value = ag.funcs[i].groupingValues[setIdx]
+			}
+		case len(a.ColIdx) != 0:
+			c := a.ColIdx[0]
+			if a.Func == AggregatorSpec_IDENT && setIdx >= 0 &&
+				ag.groupColSet.Contains(c) && !ag.groupingSets[setIdx].Contains(c) {
+				// The group column is not part of the grouping set.
+				value = parser.DNull
+				break
+			}
+			if err := row[c].EnsureDecoded(&ag.datumAlloc); err != nil {
+				return encoded, err
+			}
+			value = row[c].Datum
+		}
+		if err := ag.funcs[i].add(ctx, encoded, value); err != nil {
+			return encoded, err
+		}
+	}
+	return encoded, nil
+}
+
+// groupingValues computes the results of a GROUPING aggregation with the
+// given arguments for each grouping set (or a single result if there are no
+// grouping sets).
+func (ag *aggregator) groupingValues(args []uint32) []parser.Datum {
+	numSets := len(ag.groupingSets)
+	if numSets == 0 {
+		numSets = 1
+	}
+	values := make([]parser.Datum, numSets)
+	for i := range values {
+		var mask parser.DInt
+		for _, c := range args {
+			mask <<= 1
+			if ag.groupingSets != nil && !ag.groupingSets[i].Contains(c) {
+				mask |= 1
 			}
 		}
-		scratch = encoded[:0]
+		values[i] = parser.NewDInt(mask)
 	}
+	return values
 }
 
 type aggregateFuncHolder struct {
@@ -320,6 +423,10 @@ type aggregateFuncHolder struct {
 	buckets       map[string]parser.AggregateFunc
 	seen          map[string]struct{}
 	bucketsMemAcc *mon.BoundAccount
+
+	// groupingValues is set for GROUPING aggregations; it contains the result
+	// for each grouping set.
+	groupingValues []parser.Datum
 }
 
 const sizeOfAggregateFunc = int64(unsafe.Sizeof(parser.AggregateFunc(nil)))
@@ -380,11 +487,18 @@ func (a *aggregateFuncHolder) get(bucket string) (parser.Datum, error) {
 }
 
 // encode returns the encoding for the grouping columns, this is then used as
-// our group key to determine which bucket to add to.
+// our group key to determine which bucket to add to. If setIdx is not -1, the
+// key is that of the given grouping set.
 func (ag *aggregator) encode(
-	appendTo []byte, row sqlbase.EncDatumRow,
-) (encoding []byte, err error) {
+	appendTo []byte, row sqlbase.EncDatumRow, setIdx int,
+) (_ []byte, err error) {
+	if setIdx >= 0 {
+		appendTo = encoding.EncodeUvarintAscending(appendTo, uint64(setIdx))
+	}
 	for _, colIdx := range ag.groupCols {
+		if setIdx >= 0 && !ag.groupingSets[setIdx].Contains(colIdx) {
+			continue
+		}
 		appendTo, err = row[colIdx].Encode(&ag.datumAlloc, sqlbase.DatumEncoding_VALUE, appendTo)
 		if err != nil {
 			return appendTo, err
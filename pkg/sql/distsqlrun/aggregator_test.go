@@ -304,6 +304,58 @@ func TestAggregator(t *testing.T) {
 			expected: sqlbase.EncDatumRows{
 				{v[2], v[3], v[3]},
 			},
+		}, {
+			// SELECT @2, COUNT(@1), GROUPING(@2) GROUP BY ROLLUP (@2)
+			spec: AggregatorSpec{
+				GroupCols: []uint32{1},
+				GroupingSets: []AggregatorSpec_GroupingSet{
+					{Cols: []uint32{1}},
+					{},
+				},
+				Aggregations: []AggregatorSpec_Aggregation{
+					{
+						Func:   AggregatorSpec_IDENT,
+						ColIdx: []uint32{1},
+					},
+					{
+						Func:   AggregatorSpec_COUNT,
+						ColIdx: []uint32{0},
+					},
+					{
+						Func:   AggregatorSpec_GROUPING,
+						ColIdx: []uint32{1},
+					},
+				},
+			},
+			input: sqlbase.EncDatumRows{
+				{v[1], v[2]},
+				{v[3], v[2]},
+				{v[6], v[4]},
+			},
+			expected: sqlbase.EncDatumRows{
+				{v[2], v[2], v[0]},
+				{v[4], v[1], v[0]},
+				{null, v[3], v[1]},
+			},
+		}, {
+			// SELECT COUNT(@1) GROUP BY GROUPING SETS ((@1), ()) (no rows).
+			spec: AggregatorSpec{
+				GroupCols: []uint32{0},
+				GroupingSets: []AggregatorSpec_GroupingSet{
+					{Cols: []uint32{0}},
+					{},
+				},
+				Aggregations: []AggregatorSpec_Aggregation{
+					{
+						Func:   AggregatorSpec_COUNT,
+						ColIdx: []uint32{0},
+					},
+				},
+			},
+			input: sqlbase.EncDatumRows{},
+			expected: sqlbase.EncDatumRows{
+				{v[0]},
+			},
 		},
 	}
 
@@ -187,6 +187,9 @@ func DrainAndForwardMetadata(ctx context.Context, src RowSource, dst RowReceiver
 
 func sendTraceData(ctx context.Context, dst RowReceiver) {
 	if sp := opentracing.SpanFromContext(ctx); sp != nil {
+		if stats := processorStatsFromContext(ctx); stats != nil {
+			stats.setTags(sp)
+		}
 		if rec := tracing.GetRecording(sp); rec != nil {
 			dst.Push(nil /* row */, ProducerMetadata{TraceData: rec})
 		}
@@ -34,14 +34,40 @@ type columnBackfiller struct {
 	// updateCols is a slice of all column descriptors that are being modified.
 	updateCols  []sqlbase.ColumnDescriptor
 	updateExprs []parser.TypedExpr
+	// converters computes the values of the added columns that rewrite an
+	// existing column to a new type or store an indexed expression, keyed by
+	// column ID, in the order of converterOrder.
+	converters     map[sqlbase.ColumnID]*sqlbase.ColumnConverter
+	converterOrder []sqlbase.ColumnID
+	// checks are the CHECK constraints referencing the columns being
+	// rewritten, validated against the new values.
+	checks []sqlbase.ConversionCheck
+	// colIdxMap maps ColumnIDs to indices into the fetched rows.
+	colIdxMap map[sqlbase.ColumnID]int
+	// convertedRow holds the fetched row followed by the values of the added
+	// columns, which the converters and checks are evaluated against, since
+	// they may reference other added columns. convertedColIdxMap maps
+	// ColumnIDs to indices into it.
+	convertedRow       parser.Datums
+	convertedColIdxMap map[sqlbase.ColumnID]int
+	// notNullCols are the columns whose NOT NULL constraint is being added and
+	// whose existing values are validated.
+	notNullCols []sqlbase.ColumnDescriptor
 }
 
+// maxNotNullViolations is the number of offending rows reported by a failed
+// NOT NULL validation.
+const maxNotNullViolations = 10
+
 var _ Processor = &columnBackfiller{}
 var _ chunkBackfiller = &columnBackfiller{}
 
 // ColumnMutationFilter is a filter that allows mutations that add or drop
-// columns.
+// columns, and mutations that add NOT NULL constraints.
 func ColumnMutationFilter(m sqlbase.DescriptorMutation) bool {
+	if m.GetNotNull() != nil {
+		return m.Direction == sqlbase.DescriptorMutation_ADD
+	}
 	return m.GetColumn() != nil && (m.Direction == sqlbase.DescriptorMutation_ADD || m.Direction == sqlbase.DescriptorMutation_DROP)
 }
 
@@ -69,12 +95,17 @@ func newColumnBackfiller(
 func (cb *columnBackfiller) init() error {
 	desc := cb.spec.Table
 
-	// colIdxMap maps ColumnIDs to indices into desc.Columns and desc.Mutations.
-	var colIdxMap map[sqlbase.ColumnID]int
-
 	if len(desc.Mutations) > 0 {
 		for _, m := range desc.Mutations {
 			if ColumnMutationFilter(m) {
+				if nn := m.GetNotNull(); nn != nil {
+					col, err := desc.FindActiveColumnByID(nn.ColumnID)
+					if err != nil {
+						return err
+					}
+					cb.notNullCols = append(cb.notNullCols, *col)
+					continue
+				}
 				switch m.Direction {
 				case sqlbase.DescriptorMutation_ADD:
 					desc := *m.GetColumn()
@@ -90,8 +121,18 @@ func (cb *columnBackfiller) init() error {
 		return err
 	}
 
+	cb.converters, err = sqlbase.MakeColumnConverters(&desc, cb.added)
+	if err != nil {
+		return err
+	}
+	cb.converterOrder = sqlbase.OrderColumnConverters(cb.converters)
+	cb.checks, err = sqlbase.MakeConversionChecks(&desc, cb.added)
+	if err != nil {
+		return err
+	}
+
 	cb.updateCols = append(cb.added, cb.dropped...)
-	if len(cb.dropped) > 0 || len(defaultExprs) > 0 {
+	if len(cb.dropped) > 0 || len(defaultExprs) > 0 || len(cb.converters) > 0 {
 		// Populate default values.
 		cb.updateExprs = make([]parser.TypedExpr, len(cb.updateCols))
 		for j := range cb.added {
@@ -112,12 +153,22 @@ func (cb *columnBackfiller) init() error {
 		valNeededForCol[i] = true
 	}
 
-	colIdxMap = make(map[sqlbase.ColumnID]int, len(desc.Columns))
+	cb.colIdxMap = make(map[sqlbase.ColumnID]int, len(desc.Columns))
 	for i, c := range desc.Columns {
-		colIdxMap[c.ID] = i
+		cb.colIdxMap[c.ID] = i
+	}
+	if len(cb.converters) > 0 {
+		cb.convertedRow = make(parser.Datums, len(desc.Columns)+len(cb.added))
+		cb.convertedColIdxMap = make(map[sqlbase.ColumnID]int, len(cb.convertedRow))
+		for id, i := range cb.colIdxMap {
+			cb.convertedColIdxMap[id] = i
+		}
+		for j, c := range cb.added {
+			cb.convertedColIdxMap[c.ID] = len(desc.Columns) + j
+		}
 	}
 	return cb.fetcher.Init(
-		&desc, colIdxMap, &desc.PrimaryIndex, false, false, desc.Columns,
+		&desc, cb.colIdxMap, &desc.PrimaryIndex, false, false, desc.Columns,
 		valNeededForCol, false, &cb.alloc,
 	)
 }
@@ -157,24 +208,30 @@ func (cb *columnBackfiller) runChunk(
 				return errors.Errorf("table %v not sent by coordinator", id)
 			}
 		}
-		// TODO(dan): Tighten up the bound on the requestedCols parameter to
-		// makeRowUpdater.
-		requestedCols := make([]sqlbase.ColumnDescriptor, 0, len(tableDesc.Columns)+len(cb.added))
-		requestedCols = append(requestedCols, tableDesc.Columns...)
-		requestedCols = append(requestedCols, cb.added...)
-		ru, err := sqlbase.MakeRowUpdater(
-			txn, &tableDesc, fkTables, cb.updateCols, requestedCols,
-			sqlbase.RowUpdaterOnlyColumns, &cb.alloc,
-		)
-		if err != nil {
-			return err
-		}
+		// The rows only need to be rewritten when columns are added or dropped;
+		// validating NOT NULL constraints only requires scanning them.
+		var ru sqlbase.RowUpdater
+		if len(cb.updateCols) > 0 {
+			// TODO(dan): Tighten up the bound on the requestedCols parameter to
+			// makeRowUpdater.
+			requestedCols := make([]sqlbase.ColumnDescriptor, 0, len(tableDesc.Columns)+len(cb.added))
+			requestedCols = append(requestedCols, tableDesc.Columns...)
+			requestedCols = append(requestedCols, cb.added...)
+			var err error
+			ru, err = sqlbase.MakeRowUpdater(
+				txn, &tableDesc, fkTables, cb.updateCols, requestedCols,
+				sqlbase.RowUpdaterOnlyColumns, &cb.flowCtx.EvalCtx, &cb.alloc,
+			)
+			if err != nil {
+				return err
+			}
 
-		// TODO(dan): This check is an unfortunate bleeding of the internals of
-		// rowUpdater. Extract the sql row to k/v mapping logic out into something
-		// usable here.
-		if !ru.IsColumnOnlyUpdate() {
-			panic("only column data should be modified, but the rowUpdater is configured otherwise")
+			// TODO(dan): This check is an unfortunate bleeding of the internals of
+			// rowUpdater. Extract the sql row to k/v mapping logic out into something
+			// usable here.
+			if !ru.IsColumnOnlyUpdate() {
+				panic("only column data should be modified, but the rowUpdater is configured otherwise")
+			}
 		}
 
 		// Get the next set of rows.
@@ -196,6 +253,11 @@ func (cb *columnBackfiller) runChunk(
 		updateValues := make(parser.Datums, len(cb.updateExprs))
 		b := txn.NewBatch()
 		rowLength := 0
+		// nullCol is the first column found to violate the NOT NULL constraint
+		// being added to it, and nullRows the primary keys of a sample of the
+		// offending rows.
+		var nullCol *sqlbase.ColumnDescriptor
+		var nullRows []parser.Datums
 		for i := int64(0); i < chunkSize; i++ {
 			row, err := cb.fetcher.NextRowDecoded(ctx, false /* traceKV */)
 			if err != nil {
@@ -204,9 +266,38 @@ func (cb *columnBackfiller) runChunk(
 			if row == nil {
 				break
 			}
+			for j := range cb.notNullCols {
+				col := &cb.notNullCols[j]
+				if row[cb.colIdxMap[col.ID]] != parser.DNull {
+					continue
+				}
+				if nullCol == nil {
+					nullCol = col
+				}
+				if nullCol.ID == col.ID {
+					pk := make(parser.Datums, len(tableDesc.PrimaryIndex.ColumnIDs))
+					for k, id := range tableDesc.PrimaryIndex.ColumnIDs {
+						pk[k] = row[cb.colIdxMap[id]]
+					}
+					nullRows = append(nullRows, pk)
+				}
+			}
+			if len(nullRows) >= maxNotNullViolations {
+				break
+			}
+			if nullCol != nil || len(cb.updateCols) == 0 {
+				// Nothing will be written.
+				continue
+			}
 			// Evaluate the new values. This must be done separately for
 			// each row so as to handle impure functions correctly.
 			for j, e := range cb.updateExprs {
+				if j < len(cb.added) {
+					if _, ok := cb.converters[cb.added[j].ID]; ok {
+						// Computed below.
+						continue
+					}
+				}
 				val, err := e.Eval(&cb.flowCtx.EvalCtx)
 				if err != nil {
 					return sqlbase.NewInvalidSchemaDefinitionError(err)
@@ -216,6 +307,14 @@ func (cb *columnBackfiller) runChunk(
 				}
 				updateValues[j] = val
 			}
+			if len(cb.converters) > 0 {
+				if err := cb.convert(row, updateValues); err != nil {
+					if sqlbase.IsPermanentSchemaChangeError(err) {
+						return err
+					}
+					return sqlbase.NewInvalidSchemaDefinitionError(err)
+				}
+			}
 			copy(oldValues, row)
 			// Update oldValues with NULL values where values weren't found;
 			// only update when necessary.
@@ -229,6 +328,12 @@ func (cb *columnBackfiller) runChunk(
 				return err
 			}
 		}
+		if nullCol != nil {
+			return sqlbase.NewNotNullValidationError(nullCol.Name, &tableDesc.PrimaryIndex, nullRows)
+		}
+		if len(cb.updateCols) == 0 {
+			return nil
+		}
 		// Write the new row values.
 		if err := txn.CommitInBatch(ctx, b); err != nil {
 			return ConvertBackfillError(ctx, &cb.spec.Table, b)
@@ -237,3 +342,31 @@ func (cb *columnBackfiller) runChunk(
 	})
 	return cb.fetcher.Key(), err
 }
+
+// convert computes the values of the added columns that have a converter, in
+// an order in which the columns they are computed from are computed first,
+// and validates the CHECK constraints referencing the columns being
+// rewritten. The values of the added columns are in the first part of
+// updateValues.
+func (cb *columnBackfiller) convert(row parser.Datums, updateValues parser.Datums) error {
+	n := len(cb.convertedRow) - len(cb.added)
+	copy(cb.convertedRow[:n], row)
+	copy(cb.convertedRow[n:], updateValues[:len(cb.added)])
+	for _, colID := range cb.converterOrder {
+		val, err := cb.converters[colID].Convert(
+			&cb.flowCtx.EvalCtx, cb.convertedColIdxMap, cb.convertedRow)
+		if err != nil {
+			return err
+		}
+		i := cb.convertedColIdxMap[colID]
+		cb.convertedRow[i] = val
+		updateValues[i-n] = val
+	}
+	for i := range cb.checks {
+		if err := cb.checks[i].Check(
+			&cb.flowCtx.EvalCtx, cb.convertedColIdxMap, cb.convertedRow); err != nil {
+			return err
+		}
+	}
+	return nil
+}
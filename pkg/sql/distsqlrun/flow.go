@@ -32,6 +32,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
 
@@ -135,6 +136,10 @@ type Flow struct {
 
 	// spec is the request that produced this flow. Only used for debugging.
 	spec *FlowSpec
+
+	// processorStats holds the stats collectors of the processors, if the flow
+	// is traced. Their memory monitors are stopped in Cleanup().
+	processorStats []*processorStatsCollector
 }
 
 func newFlow(flowCtx FlowCtx, flowReg *flowRegistry, syncFlowConsumer RowReceiver) *Flow {
@@ -243,7 +248,11 @@ func checkNumInOut(inputs []RowSource, outputs []RowReceiver, numIn, numOut int)
 	return nil
 }
 
-func (f *Flow) makeProcessor(ps *ProcessorSpec, inputs []RowSource) (Processor, error) {
+// makeProcessor creates the processor for the given spec. If stats is set, the
+// processor's stats are collected in it.
+func (f *Flow) makeProcessor(
+	ps *ProcessorSpec, inputs []RowSource, stats *processorStatsCollector,
+) (Processor, error) {
 	if len(ps.Output) != 1 {
 		return nil, errors.Errorf("only single-output processors supported")
 	}
@@ -271,16 +280,31 @@ func (f *Flow) makeProcessor(ps *ProcessorSpec, inputs []RowSource) (Processor,
 		outputs[i] = r
 		f.startables = append(f.startables, r)
 	}
-	proc, err := newProcessor(&f.FlowCtx, &ps.Core, &ps.Post, inputs, outputs)
+	// The routers are initialized below, once the output types are known.
+	routers := make([]router, 0, len(outputs))
+	for i, o := range outputs {
+		if r, ok := o.(router); ok {
+			routers = append(routers, r)
+		}
+		if stats != nil {
+			outputs[i] = &rowCountingReceiver{RowReceiver: o, stats: stats}
+		}
+	}
+	flowCtx := &f.FlowCtx
+	if stats != nil {
+		flowCtx = &stats.flowCtx
+	}
+	proc, err := newProcessor(flowCtx, &ps.Core, &ps.Post, inputs, outputs)
 	if err != nil {
 		return nil, err
 	}
 	// Initialize any routers (the setupRouter case above).
 	types := proc.OutputTypes()
-	for _, o := range outputs {
-		if r, ok := o.(router); ok {
-			r.init(&f.FlowCtx, types)
-		}
+	for _, r := range routers {
+		r.init(&f.FlowCtx, types)
+	}
+	if stats != nil {
+		return &processorWithStats{Processor: proc, stats: stats}, nil
 	}
 	return proc, nil
 }
@@ -341,9 +365,18 @@ func (f *Flow) setup(ctx context.Context, spec *FlowSpec) error {
 
 	f.processors = make([]Processor, len(spec.Processors))
 
+	// When the flow is traced (e.g. for EXPLAIN ANALYZE), we collect runtime
+	// statistics for each processor; they are sent to the gateway along with the
+	// trace data.
+	collectStats := tracing.IsRecording(opentracing.SpanFromContext(ctx))
 	for i := range spec.Processors {
+		var stats *processorStatsCollector
+		if collectStats {
+			stats = newProcessorStatsCollector(ctx, &f.FlowCtx, spec.Processors[i].ProcessorID)
+			f.processorStats = append(f.processorStats, stats)
+		}
 		var err error
-		f.processors[i], err = f.makeProcessor(&spec.Processors[i], inputSyncs[i])
+		f.processors[i], err = f.makeProcessor(&spec.Processors[i], inputSyncs[i], stats)
 		if err != nil {
 			return err
 		}
@@ -423,6 +456,9 @@ func (f *Flow) Cleanup(ctx context.Context) {
 	}
 	// This closes the account and monitor opened in ServerImpl.setupFlow.
 	f.EvalCtx.ActiveMemAcc.Close(ctx)
+	for _, stats := range f.processorStats {
+		stats.mon.Stop(ctx)
+	}
 	f.EvalCtx.Stop(ctx)
 	if log.V(1) {
 		log.Infof(ctx, "cleaning up")
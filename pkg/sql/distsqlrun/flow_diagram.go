@@ -81,6 +81,17 @@ func (a *AggregatorSpec) summary() (string, []string) {
 	if len(a.GroupCols) > 0 {
 		details = append(details, colListStr(a.GroupCols))
 	}
+	if len(a.GroupingSets) > 0 {
+		var buf bytes.Buffer
+		buf.WriteString("Grouping sets: ")
+		for i, s := range a.GroupingSets {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			fmt.Fprintf(&buf, "(%s)", colListStr(s.Cols))
+		}
+		details = append(details, buf.String())
+	}
 	for _, agg := range a.Aggregations {
 		var buf bytes.Buffer
 		buf.WriteString(agg.Func.String())
@@ -268,6 +279,9 @@ type diagramProcessor struct {
 	Core    diagramCell   `json:"core"`
 	Outputs []diagramCell `json:"outputs"`
 	StageID int32         `json:"stage"`
+	// Stats are the runtime statistics of the processor, if the plan was
+	// executed with statistics collection (EXPLAIN ANALYZE).
+	Stats *ProcessorStats `json:"stats,omitempty"`
 }
 
 type diagramEdge struct {
@@ -283,7 +297,9 @@ type diagramData struct {
 	Edges      []diagramEdge      `json:"edges"`
 }
 
-func generateDiagramData(flows []FlowSpec, nodeNames []string) (diagramData, error) {
+func generateDiagramData(
+	flows []FlowSpec, nodeNames []string, stats map[int32]ProcessorStats,
+) (diagramData, error) {
 	d := diagramData{NodeNames: nodeNames}
 
 	// inPorts maps streams to their "destination" attachment point. Only DestProc
@@ -297,6 +313,10 @@ func generateDiagramData(flows []FlowSpec, nodeNames []string) (diagramData, err
 			proc := diagramProcessor{NodeIdx: n}
 			proc.Core.Title, proc.Core.Details = p.Core.GetValue().(diagramCellType).summary()
 			proc.Core.Details = append(proc.Core.Details, p.Post.summary()...)
+			if s, ok := stats[p.ProcessorID]; ok {
+				proc.Stats = &s
+				proc.Core.Details = append(proc.Core.Details, s.Details()...)
+			}
 
 			// We need explicit synchronizers if we have multiple inputs, or if the
 			// one input has multiple input streams.
@@ -393,8 +413,11 @@ func generateDiagramData(flows []FlowSpec, nodeNames []string) (diagramData, err
 
 // GeneratePlanDiagram generates the json data for a flow diagram.  There should
 // be one FlowSpec per node. The function assumes that StreamIDs are unique
-// across all flows.
-func GeneratePlanDiagram(flows map[roachpb.NodeID]FlowSpec, w io.Writer) error {
+// across all flows. If stats is non-nil, the runtime statistics of the
+// processors (keyed by ProcessorID) are included in the diagram.
+func GeneratePlanDiagram(
+	flows map[roachpb.NodeID]FlowSpec, stats map[int32]ProcessorStats, w io.Writer,
+) error {
 	// We sort the flows by node because we want the diagram data to be
 	// deterministic.
 	nodeIDs := make([]int, 0, len(flows))
@@ -412,7 +435,7 @@ func GeneratePlanDiagram(flows map[roachpb.NodeID]FlowSpec, w io.Writer) error {
 		nodeNames[i] = n.String()
 	}
 
-	d, err := generateDiagramData(flowSlice, nodeNames)
+	d, err := generateDiagramData(flowSlice, nodeNames, stats)
 	if err != nil {
 		return err
 	}
@@ -422,9 +445,11 @@ func GeneratePlanDiagram(flows map[roachpb.NodeID]FlowSpec, w io.Writer) error {
 // GeneratePlanDiagramWithURL generates the json data for a flow diagram and a
 // URL which encodes the diagram. There should be one FlowSpec per node. The
 // function assumes that StreamIDs are unique across all flows.
-func GeneratePlanDiagramWithURL(flows map[roachpb.NodeID]FlowSpec) (string, url.URL, error) {
+func GeneratePlanDiagramWithURL(
+	flows map[roachpb.NodeID]FlowSpec, stats map[int32]ProcessorStats,
+) (string, url.URL, error) {
 	var json, compressed bytes.Buffer
-	if err := GeneratePlanDiagram(flows, &json); err != nil {
+	if err := GeneratePlanDiagram(flows, stats, &json); err != nil {
 		return "", url.URL{}, err
 	}
 	jsonStr := json.String()
@@ -135,7 +135,7 @@ func TestPlanDiagramIndexJoin(t *testing.T) {
 		},
 	}
 
-	json, url, err := GeneratePlanDiagramWithURL(flows)
+	json, url, err := GeneratePlanDiagramWithURL(flows, nil /* stats */)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -349,7 +349,7 @@ func TestPlanDiagramJoin(t *testing.T) {
 	}
 
 	var buf bytes.Buffer
-	if err := GeneratePlanDiagram(flows, &buf); err != nil {
+	if err := GeneratePlanDiagram(flows, nil /* stats */, &buf); err != nil {
 		t.Fatal(err)
 	}
 
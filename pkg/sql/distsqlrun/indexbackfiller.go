@@ -96,7 +96,9 @@ func (ib *indexBackfiller) init() error {
 		if IndexMutationFilter(m) {
 			idx := m.GetIndex()
 			for i, col := range cols {
-				valNeededForCol[i] = valNeededForCol[i] || idx.ContainsColumnID(col.ID)
+				// An index encoded like a primary index stores all the columns.
+				valNeededForCol[i] = valNeededForCol[i] || idx.ContainsColumnID(col.ID) ||
+					idx.EncodingType == sqlbase.PrimaryIndexEncoding
 			}
 		}
 	}
@@ -164,9 +166,10 @@ func (ib *indexBackfiller) runChunk(
 			if err := sqlbase.EncDatumRowToDatums(ib.rowVals, encRow, &ib.da); err != nil {
 				return nil, err
 			}
-			if err := sqlbase.EncodeSecondaryIndexes(
+			secondaryIndexEntries, err = sqlbase.EncodeSecondaryIndexes(
 				&ib.spec.Table, added, ib.colIdxMap,
-				ib.rowVals, secondaryIndexEntries); err != nil {
+				ib.rowVals, secondaryIndexEntries)
+			if err != nil {
 				return nil, err
 			}
 			entries = append(entries, secondaryIndexEntries...)
@@ -43,6 +43,21 @@ type joinReader struct {
 	alloc   sqlbase.DatumAlloc
 
 	input RowSource
+
+	// The following fields are only used by lookup joins, see
+	// JoinReaderSpec.LookupColumns.
+
+	// lookupCols are the columns of the input which are looked up in the
+	// leading columns of the index, and lookupTableCols are the positions of
+	// these index columns among the columns of the table.
+	lookupCols      columns
+	lookupTableCols []int
+	onCond          exprHelper
+	// spans restricts the rows of the index that are looked up; if empty, all
+	// the rows of the index can be looked up.
+	spans       roachpb.Spans
+	rowAlloc    sqlbase.EncDatumRowAlloc
+	combinedRow sqlbase.EncDatumRow
 }
 
 var _ Processor = &joinReader{}
@@ -54,15 +69,16 @@ func newJoinReader(
 	post *PostProcessSpec,
 	output RowReceiver,
 ) (*joinReader, error) {
-	if spec.IndexIdx != 0 {
-		// TODO(radu): for now we only support joining with the primary index
+	if spec.IndexIdx != 0 && len(spec.LookupColumns) == 0 {
+		// TODO(radu): for now we only support index joins with the primary index.
 		return nil, errors.Errorf("join with index not implemented")
 	}
 
 	jr := &joinReader{
-		flowCtx: flowCtx,
-		desc:    spec.Table,
-		input:   input,
+		flowCtx:    flowCtx,
+		desc:       spec.Table,
+		input:      input,
+		lookupCols: columns(spec.LookupColumns),
 	}
 
 	types := make([]sqlbase.ColumnType, len(spec.Table.Columns))
@@ -70,20 +86,95 @@ func newJoinReader(
 		types[i] = spec.Table.Columns[i].Type
 	}
 
-	if err := jr.out.Init(post, types, &flowCtx.EvalCtx, output); err != nil {
+	if len(jr.lookupCols) == 0 {
+		if err := jr.out.Init(post, types, &flowCtx.EvalCtx, output); err != nil {
+			return nil, err
+		}
+
+		var err error
+		jr.index, _, err = initRowFetcher(
+			&jr.fetcher, &jr.desc, int(spec.IndexIdx), false, /* reverse */
+			jr.out.neededColumns(), &jr.alloc,
+		)
+		if err != nil {
+			return nil, err
+		}
+		return jr, nil
+	}
+
+	if err := jr.initLookupJoin(spec, types, post, output); err != nil {
 		return nil, err
 	}
+	return jr, nil
+}
+
+// initLookupJoin initializes a join reader which performs a lookup join. The
+// internal columns are the columns of the input followed by the columns of
+// the table.
+func (jr *joinReader) initLookupJoin(
+	spec *JoinReaderSpec,
+	tableTypes []sqlbase.ColumnType,
+	post *PostProcessSpec,
+	output RowReceiver,
+) error {
+	inputTypes := jr.input.Types()
+	types := make([]sqlbase.ColumnType, 0, len(inputTypes)+len(tableTypes))
+	types = append(types, inputTypes...)
+	types = append(types, tableTypes...)
+	if err := jr.out.Init(post, types, &jr.flowCtx.EvalCtx, output); err != nil {
+		return err
+	}
+	if err := jr.onCond.init(spec.OnExpr, types, &jr.flowCtx.EvalCtx); err != nil {
+		return err
+	}
+	jr.combinedRow = make(sqlbase.EncDatumRow, len(types))
+	for _, sp := range spec.Spans {
+		jr.spans = append(jr.spans, sp.Span)
+	}
+	jr.spans, _ = roachpb.MergeSpans(jr.spans)
+
+	// The fetcher needs the columns of the table used by the output or the ON
+	// condition, as well as the looked up columns, which are used to match the
+	// rows of the table to the rows of the input.
+	needed := jr.out.neededColumns()
+	valNeededForCol := make([]bool, len(tableTypes))
+	for i := range valNeededForCol {
+		valNeededForCol[i] = needed[len(inputTypes)+i] ||
+			(jr.onCond.expr != nil && jr.onCond.vars.IndexedVarUsed(len(inputTypes)+i))
+	}
+	index := &jr.desc.PrimaryIndex
+	if spec.IndexIdx > 0 {
+		if int(spec.IndexIdx) > len(jr.desc.Indexes) {
+			return errors.Errorf("invalid indexIdx %d", spec.IndexIdx)
+		}
+		index = &jr.desc.Indexes[spec.IndexIdx-1]
+	}
+	if len(jr.lookupCols) > len(index.ColumnIDs) {
+		return errors.Errorf("%d lookup columns for index %q with %d columns",
+			len(jr.lookupCols), index.Name, len(index.ColumnIDs))
+	}
+	jr.lookupTableCols = make([]int, len(jr.lookupCols))
+	for i, colID := range index.ColumnIDs[:len(jr.lookupCols)] {
+		idx := -1
+		for j := range jr.desc.Columns {
+			if jr.desc.Columns[j].ID == colID {
+				idx = j
+				break
+			}
+		}
+		if idx == -1 {
+			return errors.Errorf("index column %d not found in table %s", colID, jr.desc.Name)
+		}
+		jr.lookupTableCols[i] = idx
+		valNeededForCol[idx] = true
+	}
 
 	var err error
 	jr.index, _, err = initRowFetcher(
 		&jr.fetcher, &jr.desc, int(spec.IndexIdx), false, /* reverse */
-		jr.out.neededColumns(), &jr.alloc,
+		valNeededForCol, &jr.alloc,
 	)
-	if err != nil {
-		return nil, err
-	}
-
-	return jr, nil
+	return err
 }
 
 func (jr *joinReader) generateKey(
@@ -99,6 +190,30 @@ func (jr *joinReader) generateKey(
 	return sqlbase.MakeKeyFromEncDatums(row, &jr.desc, index, primaryKeyPrefix, alloc)
 }
 
+// generateLookupKey encodes the key prefix of the rows of the index which
+// match the given values of the lookup columns. It returns false if one of the
+// values is NULL, in which case no row can match.
+func (jr *joinReader) generateLookupKey(
+	values sqlbase.EncDatumRow, alloc *sqlbase.DatumAlloc, keyPrefix []byte,
+) (roachpb.Key, bool, error) {
+	key := append(roachpb.Key(nil), keyPrefix...)
+	for i := range values {
+		if values[i].IsNull() {
+			return nil, false, nil
+		}
+		enc := sqlbase.DatumEncoding_ASCENDING_KEY
+		if jr.index.ColumnDirections[i] == sqlbase.IndexDescriptor_DESC {
+			enc = sqlbase.DatumEncoding_DESCENDING_KEY
+		}
+		var err error
+		key, err = values[i].Encode(alloc, enc, key)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	return key, true, nil
+}
+
 // mainLoop runs the mainLoop and returns any error.
 //
 // If no error is returned, the input has been drained and the output has been
@@ -106,6 +221,9 @@ func (jr *joinReader) generateKey(
 // should drain and close the output. The caller should also pass the returned
 // error to the consumer.
 func (jr *joinReader) mainLoop(ctx context.Context) error {
+	if len(jr.lookupCols) > 0 {
+		return jr.lookupLoop(ctx)
+	}
 	primaryKeyPrefix := sqlbase.MakeIndexKeyPrefix(&jr.desc, jr.index.ID)
 
 	var alloc sqlbase.DatumAlloc
@@ -192,6 +310,127 @@ func (jr *joinReader) mainLoop(ctx context.Context) error {
 	}
 }
 
+// lookupLoop is the mainLoop of a lookup join. It reads the input in batches,
+// and looks up the rows of the index which match each batch.
+//
+// The contract with the caller is the same as mainLoop's.
+func (jr *joinReader) lookupLoop(ctx context.Context) error {
+	keyPrefix := sqlbase.MakeIndexKeyPrefix(&jr.desc, jr.index.ID)
+
+	txn := jr.flowCtx.txn
+	if txn == nil {
+		log.Fatalf(ctx, "joinReader outside of txn")
+	}
+
+	log.VEventf(ctx, 1, "starting lookup join")
+	if log.V(1) {
+		defer log.Infof(ctx, "exiting lookup join")
+	}
+
+	var alloc sqlbase.DatumAlloc
+	// batch maps the key of each looked up prefix of the index to the input
+	// rows of the current batch which have these values.
+	batch := make(map[string][]sqlbase.EncDatumRow, joinReaderBatchSize)
+	spans := make(roachpb.Spans, 0, joinReaderBatchSize)
+	lookupValues := make(sqlbase.EncDatumRow, len(jr.lookupCols))
+	numInputRows := len(jr.input.Types())
+	for {
+		for k := range batch {
+			delete(batch, k)
+		}
+		spans = spans[:0]
+		inputDone := false
+		for numRows := 0; numRows < joinReaderBatchSize; {
+			row, meta := jr.input.Next()
+			if !meta.Empty() {
+				if meta.Err != nil {
+					return meta.Err
+				}
+				if !emitHelper(ctx, &jr.out, nil /* row */, meta, jr.input) {
+					return nil
+				}
+				continue
+			}
+			if row == nil {
+				inputDone = true
+				break
+			}
+			numRows++
+
+			for i, col := range jr.lookupCols {
+				lookupValues[i] = row[col]
+			}
+			key, ok, err := jr.generateLookupKey(lookupValues, &alloc, keyPrefix)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				// NULLs never match; this is an inner join.
+				continue
+			}
+			rows, found := batch[string(key)]
+			if !found {
+				spans = append(spans, roachpb.Span{Key: key, EndKey: key.PrefixEnd()})
+			}
+			batch[string(key)] = append(rows, jr.rowAlloc.CopyRow(row))
+		}
+
+		spans, _ = roachpb.MergeSpans(spans)
+		if len(jr.spans) > 0 {
+			spans = roachpb.IntersectSpans(spans, jr.spans)
+		}
+		if len(spans) > 0 {
+			if err := jr.fetcher.StartScan(ctx, txn, spans, false /* no batch limits */, 0); err != nil {
+				log.Errorf(ctx, "scan error: %s", err)
+				return err
+			}
+			for {
+				// TODO(radu,andrei,knz): set the traceKV flag when requested by the session.
+				fetcherRow, err := jr.fetcher.NextRow(ctx, false /* traceKV */)
+				if err != nil {
+					return err
+				}
+				if fetcherRow == nil {
+					// Done with this batch.
+					break
+				}
+				for i, col := range jr.lookupTableCols {
+					lookupValues[i] = fetcherRow[col]
+				}
+				key, ok, err := jr.generateLookupKey(lookupValues, &alloc, keyPrefix)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					continue
+				}
+				for _, inputRow := range batch[string(key)] {
+					copy(jr.combinedRow, inputRow)
+					copy(jr.combinedRow[numInputRows:], fetcherRow)
+					if jr.onCond.expr != nil {
+						passes, err := jr.onCond.evalFilter(jr.combinedRow)
+						if err != nil {
+							return err
+						}
+						if !passes {
+							continue
+						}
+					}
+					if !emitHelper(ctx, &jr.out, jr.combinedRow, ProducerMetadata{}, jr.input) {
+						return nil
+					}
+				}
+			}
+		}
+
+		if inputDone {
+			sendTraceData(ctx, jr.out.output)
+			jr.out.Close()
+			return nil
+		}
+	}
+}
+
 // Run is part of the processor interface.
 func (jr *joinReader) Run(ctx context.Context, wg *sync.WaitGroup) {
 	if wg != nil {
@@ -201,6 +440,7 @@ func (jr *joinReader) Run(ctx context.Context, wg *sync.WaitGroup) {
 	ctx = log.WithLogTagInt(ctx, "JoinReader", int(jr.desc.ID))
 	ctx, span := processorSpan(ctx, "join reader")
 	defer tracing.FinishSpan(span)
+	recordFetcherStats(ctx, &jr.fetcher)
 
 	err := jr.mainLoop(ctx)
 	if err != nil {
@@ -22,11 +22,13 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 )
 
@@ -152,6 +154,143 @@ func TestJoinReader(t *testing.T) {
 	}
 }
 
+// TestJoinReaderLookupJoin tests join readers which look up the rows of the
+// table matching the rows of their input.
+func TestJoinReaderLookupJoin(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, sqlDB, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	// Same table as in TestJoinReader.
+	aFn := func(row int) parser.Datum {
+		return parser.NewDInt(parser.DInt(row / 10))
+	}
+	bFn := func(row int) parser.Datum {
+		return parser.NewDInt(parser.DInt(row % 10))
+	}
+	sumFn := func(row int) parser.Datum {
+		return parser.NewDInt(parser.DInt(row/10 + row%10))
+	}
+
+	sqlutils.CreateTable(t, sqlDB, "t",
+		"a INT, b INT, sum INT, s STRING, PRIMARY KEY (a,b), INDEX bs (b,s)",
+		99,
+		sqlutils.ToRowFn(aFn, bFn, sumFn, sqlutils.RowEnglishFn))
+
+	td := sqlbase.GetTableDescriptor(kvDB, "test", "t")
+
+	// primarySpan returns the span of the primary index for the values of a in
+	// [start, end).
+	primarySpan := func(start, end int64) TableReaderSpan {
+		prefix := sqlbase.MakeIndexKeyPrefix(td, td.PrimaryIndex.ID)
+		return TableReaderSpan{Span: roachpb.Span{
+			Key:    encoding.EncodeVarintAscending(append([]byte(nil), prefix...), start),
+			EndKey: encoding.EncodeVarintAscending(append([]byte(nil), prefix...), end),
+		}}
+	}
+
+	// The internal columns of the join readers are the input column followed
+	// by a, b, sum and s.
+	testCases := []struct {
+		spec     JoinReaderSpec
+		post     PostProcessSpec
+		input    []parser.Datum
+		expected string
+	}{
+		{
+			spec: JoinReaderSpec{
+				LookupColumns: []uint32{0},
+				OnExpr:        Expression{Expr: "@3 < 2"}, // b < 2
+			},
+			post: PostProcessSpec{
+				Projection:    true,
+				OutputColumns: []uint32{0, 2},
+			},
+			input:    []parser.Datum{parser.NewDInt(3), parser.DNull, parser.NewDInt(1), parser.NewDInt(3)},
+			expected: "[[1 0] [1 1] [3 0] [3 0] [3 1] [3 1]]",
+		},
+		{
+			spec: JoinReaderSpec{
+				IndexIdx:      1,
+				LookupColumns: []uint32{0},
+			},
+			post: PostProcessSpec{
+				Filter:        Expression{Expr: "@2 < 3"}, // a < 3
+				Projection:    true,
+				OutputColumns: []uint32{1, 4},
+			},
+			input:    []parser.Datum{parser.NewDInt(5)},
+			expected: "[[0 'five'] [1 'one-five'] [2 'two-five']]",
+		},
+		{
+			spec: JoinReaderSpec{
+				LookupColumns: []uint32{0},
+				OnExpr:        Expression{Expr: "@3 = 0"}, // b = 0
+				Spans:         []TableReaderSpan{primarySpan(0, 3)},
+			},
+			post: PostProcessSpec{
+				Projection:    true,
+				OutputColumns: []uint32{1, 2},
+			},
+			input:    []parser.Datum{parser.NewDInt(4), parser.NewDInt(2)},
+			expected: "[[2 0]]",
+		},
+	}
+	for _, c := range testCases {
+		t.Run("", func(t *testing.T) {
+			evalCtx := parser.MakeTestingEvalContext()
+			defer evalCtx.Stop(context.Background())
+			flowCtx := FlowCtx{
+				EvalCtx:  evalCtx,
+				Settings: cluster.MakeTestingClusterSettings(),
+				// Pass a DB without a TxnCoordSender.
+				txn: client.NewTxn(client.NewDB(s.DistSender(), s.Clock())),
+			}
+
+			intType := sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_INT}
+			var rows sqlbase.EncDatumRows
+			for _, d := range c.input {
+				rows = append(rows, sqlbase.EncDatumRow{sqlbase.DatumToEncDatum(intType, d)})
+			}
+			in := NewRowBuffer([]sqlbase.ColumnType{intType}, rows, RowBufferArgs{})
+
+			out := &RowBuffer{}
+			spec := c.spec
+			spec.Table = *td
+			jr, err := newJoinReader(&flowCtx, &spec, in, &c.post, out)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			jr.Run(context.Background(), nil)
+
+			if !in.Done {
+				t.Fatal("joinReader didn't consume all the rows")
+			}
+			if !out.ProducerClosed {
+				t.Fatalf("output RowReceiver not closed")
+			}
+
+			var res sqlbase.EncDatumRows
+			for {
+				row, meta := out.Next()
+				if !meta.Empty() {
+					t.Fatalf("unexpected metadata: %v", meta)
+				}
+				if row == nil {
+					break
+				}
+				res = append(res, row)
+			}
+
+			if result := res.String(); result != c.expected {
+				t.Errorf("invalid results: %s, expected %s'", result, c.expected)
+			}
+		})
+	}
+}
+
 // TestJoinReaderDrain tests various scenarios in which a joinReader's consumer
 // is closed.
 func TestJoinReaderDrain(t *testing.T) {
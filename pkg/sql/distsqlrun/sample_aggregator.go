@@ -0,0 +1,264 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/stats"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+)
+
+// sampleAggregator is the processor that merges the samples and sketches
+// produced by sampler processors and computes the final statistics. See
+// SampleAggregatorSpec for the schemas of its input and output.
+type sampleAggregator struct {
+	processorBase
+
+	flowCtx    *FlowCtx
+	input      RowSource
+	sr         stats.SampleReservoir
+	sketches   []sketchInfo
+	datumAlloc sqlbase.DatumAlloc
+
+	// Input column indices for special columns.
+	rankCol      int
+	sketchIdxCol int
+	numRowsCol   int
+	numNullsCol  int
+	sketchCol    int
+}
+
+var _ Processor = &sampleAggregator{}
+
+// SampleAggregatorOutputTypes are the types of the statistics output by a
+// sample aggregator for each sketch.
+var SampleAggregatorOutputTypes = []sqlbase.ColumnType{
+	// row count
+	{SemanticType: sqlbase.ColumnType_INT},
+	// distinct count
+	{SemanticType: sqlbase.ColumnType_INT},
+	// NULL count
+	{SemanticType: sqlbase.ColumnType_INT},
+	// encoded histogram
+	{SemanticType: sqlbase.ColumnType_BYTES},
+}
+
+func newSampleAggregator(
+	flowCtx *FlowCtx,
+	spec *SampleAggregatorSpec,
+	input RowSource,
+	post *PostProcessSpec,
+	output RowReceiver,
+) (*sampleAggregator, error) {
+	inTypes := input.Types()
+	if len(inTypes) < len(samplerExtraTypes) {
+		return nil, errors.Errorf("invalid sample aggregator input with %d columns", len(inTypes))
+	}
+	numSampledCols := len(inTypes) - len(samplerExtraTypes)
+	for _, s := range spec.Sketches {
+		if len(s.Columns) == 0 {
+			return nil, errors.Errorf("sketch with no columns")
+		}
+		for _, c := range s.Columns {
+			if c >= uint32(numSampledCols) {
+				return nil, errors.Errorf("sketch column %d out of range", c)
+			}
+		}
+		if s.GenerateHistogram && s.HistogramMaxBuckets == 0 {
+			return nil, errors.Errorf("histogram max buckets not specified")
+		}
+	}
+
+	s := &sampleAggregator{
+		flowCtx:      flowCtx,
+		input:        input,
+		sketches:     make([]sketchInfo, len(spec.Sketches)),
+		rankCol:      numSampledCols,
+		sketchIdxCol: numSampledCols + 1,
+		numRowsCol:   numSampledCols + 2,
+		numNullsCol:  numSampledCols + 3,
+		sketchCol:    numSampledCols + 4,
+	}
+	s.sr.Init(int(spec.SampleSize))
+	for i := range spec.Sketches {
+		s.sketches[i] = sketchInfo{
+			spec:   spec.Sketches[i],
+			sketch: stats.NewSketch(),
+		}
+	}
+
+	if err := s.out.Init(post, SampleAggregatorOutputTypes, &flowCtx.EvalCtx, output); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Run is part of the Processor interface.
+func (s *sampleAggregator) Run(ctx context.Context, wg *sync.WaitGroup) {
+	if wg != nil {
+		defer wg.Done()
+	}
+
+	ctx = log.WithLogTag(ctx, "SampleAggregator", nil)
+	ctx, span := processorSpan(ctx, "sample aggregator")
+	defer tracing.FinishSpan(span)
+
+	if log.V(2) {
+		log.Infof(ctx, "starting sample aggregator")
+		defer log.Infof(ctx, "exiting sample aggregator")
+	}
+
+	earlyExit, err := s.mainLoop(ctx)
+	if err != nil {
+		DrainAndClose(ctx, s.out.output, err, s.input)
+	} else if !earlyExit {
+		sendTraceData(ctx, s.out.output)
+		s.input.ConsumerClosed()
+		s.out.Close()
+	}
+}
+
+func (s *sampleAggregator) mainLoop(ctx context.Context) (earlyExit bool, _ error) {
+	var tmpSketch stats.Sketch
+	for {
+		row, meta := s.input.Next()
+		if !meta.Empty() {
+			if meta.Err != nil {
+				return false, meta.Err
+			}
+			if !emitHelper(ctx, &s.out, nil /* row */, meta, s.input) {
+				// No cleanup required; emitHelper() took care of it.
+				return true, nil
+			}
+			continue
+		}
+		if row == nil {
+			break
+		}
+
+		if !row[s.rankCol].IsNull() {
+			// This is a sampled row.
+			rank, err := s.decodeInt(row, s.rankCol)
+			if err != nil {
+				return false, err
+			}
+			s.sr.SampleRow(row[:s.rankCol], uint64(rank))
+			continue
+		}
+
+		// This is a sketch row.
+		sketchIdx, err := s.decodeInt(row, s.sketchIdxCol)
+		if err != nil {
+			return false, err
+		}
+		if sketchIdx < 0 || sketchIdx >= int64(len(s.sketches)) {
+			return false, errors.Errorf("invalid sketch index %d", sketchIdx)
+		}
+		info := &s.sketches[sketchIdx]
+		numRows, err := s.decodeInt(row, s.numRowsCol)
+		if err != nil {
+			return false, err
+		}
+		info.numRows += numRows
+		numNulls, err := s.decodeInt(row, s.numNullsCol)
+		if err != nil {
+			return false, err
+		}
+		info.numNulls += numNulls
+
+		if err := row[s.sketchCol].EnsureDecoded(&s.datumAlloc); err != nil {
+			return false, err
+		}
+		data, ok := row[s.sketchCol].Datum.(*parser.DBytes)
+		if !ok {
+			return false, errors.Errorf("invalid sketch data %s", row[s.sketchCol].Datum)
+		}
+		if err := tmpSketch.UnmarshalBinary([]byte(*data)); err != nil {
+			return false, err
+		}
+		info.sketch.Merge(&tmpSketch)
+	}
+
+	outRow := make(sqlbase.EncDatumRow, len(SampleAggregatorOutputTypes))
+	for _, info := range s.sketches {
+		histogram := parser.Datum(parser.DNull)
+		if info.spec.GenerateHistogram {
+			h, err := s.generateHistogram(info)
+			if err != nil {
+				return false, err
+			}
+			histogram = h
+		}
+		outRow[0] = sqlbase.DatumToEncDatum(
+			SampleAggregatorOutputTypes[0], parser.NewDInt(parser.DInt(info.numRows)),
+		)
+		outRow[1] = sqlbase.DatumToEncDatum(
+			SampleAggregatorOutputTypes[1], parser.NewDInt(parser.DInt(info.sketch.Estimate())),
+		)
+		outRow[2] = sqlbase.DatumToEncDatum(
+			SampleAggregatorOutputTypes[2], parser.NewDInt(parser.DInt(info.numNulls)),
+		)
+		outRow[3] = sqlbase.DatumToEncDatum(SampleAggregatorOutputTypes[3], histogram)
+		if !emitHelper(ctx, &s.out, outRow, ProducerMetadata{}, s.input) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *sampleAggregator) decodeInt(row sqlbase.EncDatumRow, col int) (int64, error) {
+	if err := row[col].EnsureDecoded(&s.datumAlloc); err != nil {
+		return 0, err
+	}
+	d, ok := row[col].Datum.(*parser.DInt)
+	if !ok {
+		return 0, errors.Errorf("invalid value %s in column %d", row[col].Datum, col)
+	}
+	return int64(*d), nil
+}
+
+// generateHistogram builds an equi-depth histogram of the first column of a
+// sketch from the non-NULL sampled values, and returns it encoded.
+func (s *sampleAggregator) generateHistogram(info sketchInfo) (parser.Datum, error) {
+	col := info.spec.Columns[0]
+	var values parser.Datums
+	for _, sample := range s.sr.Get() {
+		ed := &sample.Row[col]
+		if err := ed.EnsureDecoded(&s.datumAlloc); err != nil {
+			return nil, err
+		}
+		if ed.Datum != parser.DNull {
+			values = append(values, ed.Datum)
+		}
+	}
+	h, err := stats.EquiDepthHistogram(
+		&s.flowCtx.EvalCtx, values, info.numRows-info.numNulls, int(info.spec.HistogramMaxBuckets),
+	)
+	if err != nil {
+		return nil, err
+	}
+	data, err := h.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return parser.NewDBytes(parser.DBytes(data)), nil
+}
@@ -0,0 +1,235 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"math/rand"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/stats"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+)
+
+// sketchInfo tracks the statistics collected for a SketchSpec.
+type sketchInfo struct {
+	spec     SketchSpec
+	sketch   *stats.Sketch
+	numNulls int64
+	numRows  int64
+}
+
+// sampler is the processor that computes the statistics of its input: it
+// retains a random sample of the rows and computes a cardinality sketch on
+// each set of columns. See SamplerSpec for the schema of its output.
+type sampler struct {
+	processorBase
+
+	flowCtx    *FlowCtx
+	input      RowSource
+	sr         stats.SampleReservoir
+	sketches   []sketchInfo
+	outTypes   []sqlbase.ColumnType
+	datumAlloc sqlbase.DatumAlloc
+
+	// Output column indices for special columns.
+	rankCol      int
+	sketchIdxCol int
+	numRowsCol   int
+	numNullsCol  int
+	sketchCol    int
+}
+
+var _ Processor = &sampler{}
+
+// samplerExtraTypes are the types of the columns the sampler appends to the
+// columns of its input.
+var samplerExtraTypes = []sqlbase.ColumnType{
+	// rank
+	{SemanticType: sqlbase.ColumnType_INT},
+	// sketch index
+	{SemanticType: sqlbase.ColumnType_INT},
+	// number of rows
+	{SemanticType: sqlbase.ColumnType_INT},
+	// number of NULLs
+	{SemanticType: sqlbase.ColumnType_INT},
+	// sketch data
+	{SemanticType: sqlbase.ColumnType_BYTES},
+}
+
+// SamplerOutputTypes returns the types of the columns output by a sampler
+// whose input has the given types.
+func SamplerOutputTypes(inTypes []sqlbase.ColumnType) []sqlbase.ColumnType {
+	return append(append([]sqlbase.ColumnType(nil), inTypes...), samplerExtraTypes...)
+}
+
+func newSampler(
+	flowCtx *FlowCtx, spec *SamplerSpec, input RowSource, post *PostProcessSpec, output RowReceiver,
+) (*sampler, error) {
+	inTypes := input.Types()
+	for _, s := range spec.Sketches {
+		if len(s.Columns) == 0 {
+			return nil, errors.Errorf("sketch with no columns")
+		}
+		for _, c := range s.Columns {
+			if c >= uint32(len(inTypes)) {
+				return nil, errors.Errorf("sketch column %d out of range", c)
+			}
+		}
+	}
+
+	s := &sampler{
+		flowCtx:  flowCtx,
+		input:    input,
+		sketches: make([]sketchInfo, len(spec.Sketches)),
+	}
+	s.sr.Init(int(spec.SampleSize))
+	for i := range spec.Sketches {
+		s.sketches[i] = sketchInfo{
+			spec:   spec.Sketches[i],
+			sketch: stats.NewSketch(),
+		}
+	}
+
+	s.outTypes = SamplerOutputTypes(inTypes)
+	s.rankCol = len(inTypes)
+	s.sketchIdxCol = len(inTypes) + 1
+	s.numRowsCol = len(inTypes) + 2
+	s.numNullsCol = len(inTypes) + 3
+	s.sketchCol = len(inTypes) + 4
+
+	if err := s.out.Init(post, s.outTypes, &flowCtx.EvalCtx, output); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Run is part of the Processor interface.
+func (s *sampler) Run(ctx context.Context, wg *sync.WaitGroup) {
+	if wg != nil {
+		defer wg.Done()
+	}
+
+	ctx = log.WithLogTag(ctx, "Sampler", nil)
+	ctx, span := processorSpan(ctx, "sampler")
+	defer tracing.FinishSpan(span)
+
+	if log.V(2) {
+		log.Infof(ctx, "starting sampler")
+		defer log.Infof(ctx, "exiting sampler")
+	}
+
+	earlyExit, err := s.mainLoop(ctx)
+	if err != nil {
+		DrainAndClose(ctx, s.out.output, err, s.input)
+	} else if !earlyExit {
+		sendTraceData(ctx, s.out.output)
+		s.input.ConsumerClosed()
+		s.out.Close()
+	}
+}
+
+func (s *sampler) mainLoop(ctx context.Context) (earlyExit bool, _ error) {
+	rng := rand.New(rand.NewSource(timeutil.Now().UnixNano()))
+	var buf []byte
+	for {
+		row, meta := s.input.Next()
+		if !meta.Empty() {
+			if meta.Err != nil {
+				return false, meta.Err
+			}
+			if !emitHelper(ctx, &s.out, nil /* row */, meta, s.input) {
+				// No cleanup required; emitHelper() took care of it.
+				return true, nil
+			}
+			continue
+		}
+		if row == nil {
+			break
+		}
+
+		for i := range s.sketches {
+			info := &s.sketches[i]
+			info.numRows++
+			if row[info.spec.Columns[0]].IsNull() {
+				info.numNulls++
+				continue
+			}
+			// The sketch is built on the key encodings of the values, which are
+			// the same for equal values regardless of the encoding in which the
+			// rows were produced.
+			buf = buf[:0]
+			for _, col := range info.spec.Columns {
+				var err error
+				buf, err = row[col].Encode(&s.datumAlloc, sqlbase.DatumEncoding_ASCENDING_KEY, buf)
+				if err != nil {
+					return false, err
+				}
+			}
+			info.sketch.Insert(buf)
+		}
+
+		// Use Int63 so the rank can be output in an INT column.
+		s.sr.SampleRow(row, uint64(rng.Int63()))
+	}
+
+	outRow := make(sqlbase.EncDatumRow, len(s.outTypes))
+	for i := range outRow {
+		outRow[i] = sqlbase.DatumToEncDatum(s.outTypes[i], parser.DNull)
+	}
+	// Emit the sampled rows.
+	for _, sample := range s.sr.Get() {
+		copy(outRow, sample.Row)
+		outRow[s.rankCol] = sqlbase.DatumToEncDatum(
+			s.outTypes[s.rankCol], parser.NewDInt(parser.DInt(sample.Rank)),
+		)
+		if !emitHelper(ctx, &s.out, outRow, ProducerMetadata{}, s.input) {
+			return true, nil
+		}
+	}
+
+	// Emit the sketch rows.
+	for i := range outRow {
+		outRow[i] = sqlbase.DatumToEncDatum(s.outTypes[i], parser.DNull)
+	}
+	for i, info := range s.sketches {
+		data, err := info.sketch.MarshalBinary()
+		if err != nil {
+			return false, err
+		}
+		outRow[s.sketchIdxCol] = sqlbase.DatumToEncDatum(
+			s.outTypes[s.sketchIdxCol], parser.NewDInt(parser.DInt(i)),
+		)
+		outRow[s.numRowsCol] = sqlbase.DatumToEncDatum(
+			s.outTypes[s.numRowsCol], parser.NewDInt(parser.DInt(info.numRows)),
+		)
+		outRow[s.numNullsCol] = sqlbase.DatumToEncDatum(
+			s.outTypes[s.numNullsCol], parser.NewDInt(parser.DInt(info.numNulls)),
+		)
+		outRow[s.sketchCol] = sqlbase.DatumToEncDatum(
+			s.outTypes[s.sketchCol], parser.NewDBytes(parser.DBytes(data)),
+		)
+		if !emitHelper(ctx, &s.out, outRow, ProducerMetadata{}, s.input) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,168 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/stats"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// TestSamplerAndAggregator runs two samplers on halves of a set of rows and
+// merges their results with a sample aggregator.
+func TestSamplerAndAggregator(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	evalCtx := parser.MakeTestingEvalContext()
+	defer evalCtx.Stop(context.Background())
+	flowCtx := FlowCtx{
+		Settings: cluster.MakeTestingClusterSettings(),
+		EvalCtx:  evalCtx,
+	}
+
+	intType := sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_INT}
+	types := []sqlbase.ColumnType{intType, intType}
+	const numRows = 1000
+	// Column 0 has 1000 distinct values; column 1 has 10 distinct values and
+	// a NULL in every fourth row.
+	var halves [2]sqlbase.EncDatumRows
+	for i := 0; i < numRows; i++ {
+		var b parser.Datum = parser.NewDInt(parser.DInt(i % 10))
+		if i%4 == 0 {
+			b = parser.DNull
+		}
+		row := sqlbase.EncDatumRow{
+			sqlbase.DatumToEncDatum(intType, parser.NewDInt(parser.DInt(i))),
+			sqlbase.DatumToEncDatum(intType, b),
+		}
+		halves[i%2] = append(halves[i%2], row)
+	}
+
+	sketches := []SketchSpec{
+		{Columns: []uint32{0}, GenerateHistogram: true, HistogramMaxBuckets: 4},
+		{Columns: []uint32{1}},
+	}
+	const sampleSize = 100
+
+	var samplerOutRows sqlbase.EncDatumRows
+	var samplerOutTypes []sqlbase.ColumnType
+	for _, half := range halves {
+		in := NewRowBuffer(types, half, RowBufferArgs{})
+		out := &RowBuffer{}
+		s, err := newSampler(
+			&flowCtx, &SamplerSpec{Sketches: sketches, SampleSize: sampleSize}, in, &PostProcessSpec{}, out,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.Run(context.Background(), nil)
+		if !out.ProducerClosed {
+			t.Fatalf("output RowReceiver not closed")
+		}
+		samplerOutTypes = s.outTypes
+		for {
+			row, meta := out.Next()
+			if !meta.Empty() {
+				t.Fatalf("unexpected metadata: %v", meta)
+			}
+			if row == nil {
+				break
+			}
+			samplerOutRows = append(samplerOutRows, row)
+		}
+	}
+	// Each sampler outputs a full sample plus one row per sketch.
+	if expected := 2 * (sampleSize + len(sketches)); len(samplerOutRows) != expected {
+		t.Fatalf("expected %d rows from the samplers, got %d", expected, len(samplerOutRows))
+	}
+
+	in := NewRowBuffer(samplerOutTypes, samplerOutRows, RowBufferArgs{})
+	out := &RowBuffer{}
+	agg, err := newSampleAggregator(
+		&flowCtx, &SampleAggregatorSpec{Sketches: sketches, SampleSize: sampleSize},
+		in, &PostProcessSpec{}, out,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agg.Run(context.Background(), nil)
+	if !out.ProducerClosed {
+		t.Fatalf("output RowReceiver not closed")
+	}
+	if n := len(agg.sr.Get()); n != sampleSize {
+		t.Fatalf("expected %d sampled rows, got %d", sampleSize, n)
+	}
+
+	expected := []struct {
+		rowCount, distinctCount, nullCount int64
+		histogram                          bool
+	}{
+		{rowCount: numRows, distinctCount: numRows, nullCount: 0, histogram: true},
+		{rowCount: numRows, distinctCount: 10, nullCount: numRows / 4, histogram: false},
+	}
+	var a sqlbase.DatumAlloc
+	for i, exp := range expected {
+		row, meta := out.Next()
+		if !meta.Empty() {
+			t.Fatalf("unexpected metadata: %v", meta)
+		}
+		if row == nil {
+			t.Fatalf("expected %d rows, got %d", len(expected), i)
+		}
+		var vals [3]int64
+		for j := range vals {
+			if err := row[j].EnsureDecoded(&a); err != nil {
+				t.Fatal(err)
+			}
+			vals[j] = int64(*row[j].Datum.(*parser.DInt))
+		}
+		if vals[0] != exp.rowCount || vals[1] != exp.distinctCount || vals[2] != exp.nullCount {
+			t.Errorf("%d: expected counts %d/%d/%d, got %d/%d/%d", i,
+				exp.rowCount, exp.distinctCount, exp.nullCount, vals[0], vals[1], vals[2])
+		}
+		if err := row[3].EnsureDecoded(&a); err != nil {
+			t.Fatal(err)
+		}
+		if !exp.histogram {
+			if row[3].Datum != parser.DNull {
+				t.Errorf("%d: unexpected histogram", i)
+			}
+			continue
+		}
+		var h stats.HistogramData
+		if err := h.Unmarshal([]byte(*row[3].Datum.(*parser.DBytes))); err != nil {
+			t.Fatal(err)
+		}
+		if len(h.Buckets) != 4 {
+			t.Fatalf("%d: expected 4 buckets, got %d", i, len(h.Buckets))
+		}
+		var total int64
+		for _, b := range h.Buckets {
+			total += b.NumEq + b.NumRange
+		}
+		if total != numRows {
+			t.Errorf("%d: expected the histogram to count %d rows, got %d", i, numRows, total)
+		}
+	}
+	if row, _ := out.Next(); row != nil {
+		t.Fatalf("unexpected row %s", row)
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/mon"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+)
+
+// ProcessorStats contains runtime statistics collected for a processor while
+// a flow runs with tracing enabled (e.g. for EXPLAIN ANALYZE).
+type ProcessorStats struct {
+	// Rows is the number of rows the processor produced.
+	Rows int64 `json:"rows"`
+	// KVBatches is the number of batches of KV requests sent by the processor.
+	KVBatches int64 `json:"kvBatches"`
+	// KVBytes is the number of bytes of key/values read by the processor.
+	KVBytes int64 `json:"kvBytes"`
+	// Time is the time spent in the processor's main loop.
+	Time time.Duration `json:"timeNanos"`
+	// MaxMemory is the peak amount of memory allocated through the processor's
+	// memory monitor.
+	MaxMemory int64 `json:"maxMemory"`
+}
+
+// The stats are transported to the gateway as tags on the processor spans,
+// which are part of the trace data sent with the flow's metadata.
+const (
+	processorIDTag        = "cockroach.processor.id"
+	processorRowsTag      = "cockroach.processor.rows"
+	processorKVBatchesTag = "cockroach.processor.kv_batches"
+	processorKVBytesTag   = "cockroach.processor.kv_bytes"
+	processorTimeTag      = "cockroach.processor.time_ns"
+	processorMaxMemoryTag = "cockroach.processor.max_memory"
+)
+
+// Details returns a human-readable description of the stats, one line per
+// statistic.
+func (s ProcessorStats) Details() []string {
+	details := []string{fmt.Sprintf("rows: %d", s.Rows)}
+	if s.KVBatches > 0 {
+		details = append(details,
+			fmt.Sprintf("KV batches: %d", s.KVBatches),
+			fmt.Sprintf("KV bytes: %s", humanizeutil.IBytes(s.KVBytes)),
+		)
+	}
+	details = append(details, fmt.Sprintf("time: %s", s.Time))
+	if s.MaxMemory > 0 {
+		details = append(details, fmt.Sprintf("max memory: %s", humanizeutil.IBytes(s.MaxMemory)))
+	}
+	return details
+}
+
+// processorStatsCollector accumulates the stats of a processor. It is only
+// created when the flow is being traced.
+type processorStatsCollector struct {
+	id int32
+
+	// flowCtx is the FlowCtx passed to the processor; it is a copy of the flow's
+	// FlowCtx that uses a separate memory monitor.
+	flowCtx FlowCtx
+	mon     mon.BytesMonitor
+
+	start time.Time
+	rows  int64
+
+	// fetcher, if set, is the RowFetcher used by the processor to read from
+	// the KV layer.
+	fetcher *sqlbase.RowFetcher
+}
+
+func newProcessorStatsCollector(
+	ctx context.Context, flowCtx *FlowCtx, id int32,
+) *processorStatsCollector {
+	c := &processorStatsCollector{id: id, flowCtx: *flowCtx}
+	// The monitor is closed in Flow.Cleanup().
+	c.mon = mon.MakeMonitorInheritWithLimit("processor", math.MaxInt64, flowCtx.EvalCtx.Mon)
+	c.mon.Start(ctx, flowCtx.EvalCtx.Mon, mon.BoundAccount{})
+	c.flowCtx.EvalCtx.Mon = &c.mon
+	return c
+}
+
+func (c *processorStatsCollector) stats() ProcessorStats {
+	s := ProcessorStats{
+		Rows:      atomic.LoadInt64(&c.rows),
+		Time:      timeutil.Since(c.start),
+		MaxMemory: c.mon.MaximumBytes(),
+	}
+	if c.fetcher != nil {
+		kvStats := c.fetcher.KVStats()
+		s.KVBatches = kvStats.Batches
+		s.KVBytes = kvStats.Bytes
+	}
+	return s
+}
+
+// setTags records the current stats on the given span.
+func (c *processorStatsCollector) setTags(sp opentracing.Span) {
+	s := c.stats()
+	sp.SetTag(processorIDTag, c.id)
+	sp.SetTag(processorRowsTag, s.Rows)
+	sp.SetTag(processorKVBatchesTag, s.KVBatches)
+	sp.SetTag(processorKVBytesTag, s.KVBytes)
+	sp.SetTag(processorTimeTag, s.Time.Nanoseconds())
+	sp.SetTag(processorMaxMemoryTag, s.MaxMemory)
+}
+
+type processorStatsKey struct{}
+
+func withProcessorStats(ctx context.Context, c *processorStatsCollector) context.Context {
+	return context.WithValue(ctx, processorStatsKey{}, c)
+}
+
+func processorStatsFromContext(ctx context.Context) *processorStatsCollector {
+	c, _ := ctx.Value(processorStatsKey{}).(*processorStatsCollector)
+	return c
+}
+
+// recordFetcherStats registers the RowFetcher of a processor so that its KV
+// stats are included in the processor's stats, if any are being collected.
+func recordFetcherStats(ctx context.Context, fetcher *sqlbase.RowFetcher) {
+	if c := processorStatsFromContext(ctx); c != nil {
+		c.fetcher = fetcher
+	}
+}
+
+// processorWithStats wraps a processor whose stats are being collected.
+type processorWithStats struct {
+	Processor
+	stats *processorStatsCollector
+}
+
+var _ Processor = &processorWithStats{}
+
+// Run is part of the Processor interface.
+func (p *processorWithStats) Run(ctx context.Context, wg *sync.WaitGroup) {
+	p.stats.start = timeutil.Now()
+	p.Processor.Run(withProcessorStats(ctx, p.stats), wg)
+}
+
+// rowCountingReceiver is a RowReceiver that counts the rows pushed by a
+// processor whose stats are being collected.
+type rowCountingReceiver struct {
+	RowReceiver
+	stats *processorStatsCollector
+}
+
+var _ RowReceiver = &rowCountingReceiver{}
+
+// Push is part of the RowReceiver interface.
+func (r *rowCountingReceiver) Push(row sqlbase.EncDatumRow, meta ProducerMetadata) ConsumerStatus {
+	if row != nil {
+		atomic.AddInt64(&r.stats.rows, 1)
+	}
+	return r.RowReceiver.Push(row, meta)
+}
+
+// ExtractProcessorStats retrieves the processor stats recorded in the spans of
+// a trace, keyed by ProcessorSpec.ProcessorID.
+func ExtractProcessorStats(spans []tracing.RecordedSpan) map[int32]ProcessorStats {
+	var res map[int32]ProcessorStats
+	for _, sp := range spans {
+		idStr, ok := sp.Tags[processorIDTag]
+		if !ok {
+			continue
+		}
+		id, err := strconv.ParseInt(idStr, 10, 32)
+		if err != nil {
+			continue
+		}
+		parse := func(tag string) int64 {
+			v, _ := strconv.ParseInt(sp.Tags[tag], 10, 64)
+			return v
+		}
+		if res == nil {
+			res = make(map[int32]ProcessorStats)
+		}
+		res[int32(id)] = ProcessorStats{
+			Rows:      parse(processorRowsTag),
+			KVBatches: parse(processorKVBatchesTag),
+			KVBytes:   parse(processorKVBytesTag),
+			Time:      time.Duration(parse(processorTimeTag)),
+			MaxMemory: parse(processorMaxMemoryTag),
+		}
+	}
+	return res
+}
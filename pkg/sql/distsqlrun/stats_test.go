@@ -0,0 +1,56 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+)
+
+// TestProcessorStatsTracing verifies that the stats recorded as tags on a
+// processor span can be extracted from the recording.
+func TestProcessorStatsTracing(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	tr := tracing.NewTracer()
+	sp := tr.StartSpan("processor", tracing.Recordable)
+	tracing.StartRecording(sp, tracing.SnowballRecording)
+
+	c := &processorStatsCollector{id: 7, start: timeutil.Now(), rows: 3}
+	c.setTags(sp)
+	sp.Finish()
+
+	stats := ExtractProcessorStats(tracing.GetRecording(sp))
+	if len(stats) != 1 {
+		t.Fatalf("expected stats for one processor, got %v", stats)
+	}
+	s, ok := stats[7]
+	if !ok {
+		t.Fatalf("no stats for processor 7: %v", stats)
+	}
+	if s.Rows != 3 || s.KVBatches != 0 || s.KVBytes != 0 || s.MaxMemory != 0 || s.Time < 0 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+
+	s.Time = 0
+	expected := []string{"rows: 3", "time: 0s"}
+	if details := s.Details(); !reflect.DeepEqual(details, expected) {
+		t.Errorf("expected details %v, got %v", expected, details)
+	}
+}
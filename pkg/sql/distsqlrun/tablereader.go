@@ -181,6 +181,7 @@ func (tr *tableReader) Run(ctx context.Context, wg *sync.WaitGroup) {
 	ctx = log.WithLogTagInt(ctx, "TableReader", int(tr.tableID))
 	ctx, span := processorSpan(ctx, "table reader")
 	defer tracing.FinishSpan(span)
+	recordFetcherStats(ctx, &tr.fetcher)
 
 	txn := tr.flowCtx.txn
 	if txn == nil {
@@ -62,7 +62,7 @@ func (p *planner) DropDatabase(ctx context.Context, n *parser.DropDatabase) (pla
 		return nil, sqlbase.NewUndefinedDatabaseError(string(n.Name))
 	}
 
-	if err := p.CheckPrivilege(dbDesc, privilege.DROP); err != nil {
+	if err := p.CheckPrivilege(ctx, dbDesc, privilege.DROP); err != nil {
 		return nil, err
 	}
 
@@ -157,6 +157,10 @@ func (n *dropDatabaseNode) Start(params runParams) error {
 				return err
 			}
 			tbNameStrings = append(tbNameStrings, cascadedViews...)
+		} else if tbDesc.IsSequence() {
+			if err := p.dropSequenceImpl(ctx, tbDesc); err != nil {
+				return err
+			}
 		} else {
 			cascadedViews, err := p.dropTableImpl(ctx, tbDesc)
 			if err != nil {
@@ -195,6 +199,14 @@ func (n *dropDatabaseNode) Start(params runParams) error {
 		return err
 	}
 
+	// The functions of the database are dropped along with it.
+	if _, err := (InternalExecutor{LeaseManager: p.LeaseMgr()}).ExecuteStatementInTransaction(
+		ctx, "drop-database-functions", p.txn,
+		`DELETE FROM system.functions WHERE "parentID" = $1`, n.dbDesc.ID,
+	); err != nil {
+		return err
+	}
+
 	// Log Drop Database event. This is an auditable log event and is recorded
 	// in the same transaction as the table descriptor update.
 	if err := MakeEventLogger(p.LeaseMgr()).InsertEventRecord(
@@ -246,7 +258,7 @@ func (p *planner) DropIndex(ctx context.Context, n *parser.DropIndex) (planNode,
 			return nil, err
 		}
 
-		if err := p.CheckPrivilege(tableDesc, privilege.CREATE); err != nil {
+		if err := p.CheckPrivilege(ctx, tableDesc, privilege.CREATE); err != nil {
 			return nil, err
 		}
 
@@ -369,10 +381,27 @@ func (p *planner) dropIndexByName(
 	if !found {
 		return fmt.Errorf("index %q in the middle of being added, try again later", idxName)
 	}
+	// The columns storing the expressions indexed by the index are dropped
+	// along with it.
+	for i, colID := range idx.ColumnIDs {
+		if idx.Expression(i) == "" {
+			continue
+		}
+		for j := range tableDesc.Columns {
+			if tableDesc.Columns[j].ID == colID {
+				tableDesc.AddColumnMutation(tableDesc.Columns[j], sqlbase.DescriptorMutation_DROP)
+				tableDesc.Columns = append(tableDesc.Columns[:j], tableDesc.Columns[j+1:]...)
+				break
+			}
+		}
+	}
 
 	if err := tableDesc.Validate(ctx, p.txn); err != nil {
 		return err
 	}
+	if err := pruneSubzones(ctx, p.txn, tableDesc); err != nil {
+		return err
+	}
 	mutationID, err := p.createSchemaChangeJob(ctx, tableDesc, jobDesc)
 	if err != nil {
 		return err
@@ -426,7 +455,7 @@ func (p *planner) DropView(ctx context.Context, n *parser.DropView) (planNode, e
 		if err != nil {
 			return nil, err
 		}
-		if err := tn.QualifyWithDatabase(p.session.Database); err != nil {
+		if err := p.qualifyTableName(ctx, tn); err != nil {
 			return nil, err
 		}
 
@@ -515,6 +544,83 @@ func (*dropViewNode) Next(runParams) (bool, error) { return false, nil }
 func (*dropViewNode) Close(context.Context)        {}
 func (*dropViewNode) Values() parser.Datums        { return parser.Datums{} }
 
+type dropSequenceNode struct {
+	n  *parser.DropSequence
+	td []*sqlbase.TableDescriptor
+}
+
+// DropSequence drops a sequence.
+// Privileges: DROP on sequence.
+//   Notes: postgres allows only the sequence owner to DROP a sequence.
+func (p *planner) DropSequence(ctx context.Context, n *parser.DropSequence) (planNode, error) {
+	td := make([]*sqlbase.TableDescriptor, 0, len(n.Names))
+	for _, name := range n.Names {
+		tn, err := name.NormalizeTableName()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.qualifyTableName(ctx, tn); err != nil {
+			return nil, err
+		}
+
+		droppedDesc, err := p.dropTableOrViewPrepare(ctx, tn)
+		if err != nil {
+			return nil, err
+		}
+		if droppedDesc == nil {
+			if n.IfExists {
+				continue
+			}
+			// Sequence does not exist, but we want it to: error out.
+			return nil, sqlbase.NewUndefinedRelationError(tn)
+		}
+		if !droppedDesc.IsSequence() {
+			return nil, sqlbase.NewWrongObjectTypeError(tn, "sequence")
+		}
+
+		td = append(td, droppedDesc)
+	}
+
+	if len(td) == 0 {
+		return &zeroNode{}, nil
+	}
+	return &dropSequenceNode{n: n, td: td}, nil
+}
+
+func (n *dropSequenceNode) Start(params runParams) error {
+	ctx := params.ctx
+	for _, droppedDesc := range n.td {
+		if droppedDesc == nil {
+			continue
+		}
+		if err := params.p.dropSequenceImpl(ctx, droppedDesc); err != nil {
+			return err
+		}
+		// Log a Drop Sequence event for this sequence. This is an auditable log
+		// event and is recorded in the same transaction as the table descriptor
+		// update.
+		if err := MakeEventLogger(params.p.LeaseMgr()).InsertEventRecord(
+			ctx,
+			params.p.txn,
+			EventLogDropSequence,
+			int32(droppedDesc.ID),
+			int32(params.p.evalCtx.NodeID),
+			struct {
+				SequenceName string
+				Statement    string
+				User         string
+			}{droppedDesc.Name, n.n.String(), params.p.session.User},
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (*dropSequenceNode) Next(runParams) (bool, error) { return false, nil }
+func (*dropSequenceNode) Close(context.Context)        {}
+func (*dropSequenceNode) Values() parser.Datums        { return parser.Datums{} }
+
 type dropTableNode struct {
 	n  *parser.DropTable
 	td []*sqlbase.TableDescriptor
@@ -531,7 +637,7 @@ func (p *planner) DropTable(ctx context.Context, n *parser.DropTable) (planNode,
 		if err != nil {
 			return nil, err
 		}
-		if err := tn.QualifyWithDatabase(p.session.Database); err != nil {
+		if err := p.qualifyTableName(ctx, tn); err != nil {
 			return nil, err
 		}
 
@@ -599,7 +705,7 @@ func (p *planner) canRemoveFK(
 	if behavior != parser.DropCascade {
 		return nil, fmt.Errorf("%q is referenced by foreign key from table %q", from, table.Name)
 	}
-	if err := p.CheckPrivilege(table, privilege.CREATE); err != nil {
+	if err := p.CheckPrivilege(ctx, table, privilege.CREATE); err != nil {
 		return nil, err
 	}
 	return table, nil
@@ -622,7 +728,7 @@ func (p *planner) canRemoveInterleave(
 		return pgerror.UnimplementedWithIssueErrorf(
 			8036, "%q is interleaved by table %q", from, table.Name)
 	}
-	if err := p.CheckPrivilege(table, privilege.CREATE); err != nil {
+	if err := p.CheckPrivilege(ctx, table, privilege.CREATE); err != nil {
 		return err
 	}
 	return nil
@@ -649,7 +755,7 @@ func (p *planner) canRemoveDependentViewGeneric(
 	if err != nil {
 		return err
 	}
-	if err := p.CheckPrivilege(viewDesc, privilege.DROP); err != nil {
+	if err := p.CheckPrivilege(ctx, viewDesc, privilege.DROP); err != nil {
 		return err
 	}
 	// If this view is depended on by other views, we have to check them as well.
@@ -772,7 +878,7 @@ func (p *planner) dropTableOrViewPrepare(
 		return nil, err
 	}
 
-	if err := p.CheckPrivilege(tableDesc, privilege.DROP); err != nil {
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege.DROP); err != nil {
 		return nil, err
 	}
 	return tableDesc, nil
@@ -990,6 +1096,20 @@ func (p *planner) dropViewImpl(
 	return cascadeDroppedViews, nil
 }
 
+// dropSequenceImpl does the work of dropping a sequence. The value of the
+// sequence is deleted along with the rest of the table's span by the schema
+// changer.
+func (p *planner) dropSequenceImpl(ctx context.Context, seqDesc *sqlbase.TableDescriptor) error {
+	if err := p.initiateDropTable(ctx, seqDesc); err != nil {
+		return err
+	}
+
+	p.session.setTestingVerifyMetadata(func(systemConfig config.SystemConfig) error {
+		return verifyDropTableMetadata(systemConfig, seqDesc.ID, "sequence")
+	})
+	return nil
+}
+
 // removeMatchingReferences removes all refs from the provided slice that
 // match the provided ID, returning the modified slice.
 func removeMatchingReferences(
@@ -1036,14 +1156,17 @@ func (p *planner) getViewDescForCascade(
 }
 
 type dropUserNode struct {
-	n *parser.DropUser
+	names    parser.NameList
+	ifExists bool
+	// isRole is set when the node drops roles rather than users.
+	isRole bool
 	// The number of users deleted.
 	numDeleted int
 }
 
 func (n *dropUserNode) Start(params runParams) error {
 	numDeleted := 0
-	for _, name := range n.n.Names {
+	for _, name := range n.names {
 		normalizedUsername, err := NormalizeAndValidateUsername(string(name))
 		if err != nil {
 			return err
@@ -1060,15 +1183,33 @@ func (n *dropUserNode) Start(params runParams) error {
 			params.ctx,
 			"drop-user",
 			params.p.txn,
-			"DELETE FROM system.users WHERE username=$1",
+			`DELETE FROM system.users WHERE username=$1 AND "isRole" = $2`,
 			normalizedUsername,
+			n.isRole,
 		)
 		if err != nil {
 			return err
 		}
 
-		if rowsAffected == 0 && !n.n.IfExists {
-			return errors.Errorf("user %s does not exist", normalizedUsername)
+		if rowsAffected == 0 {
+			if !n.ifExists {
+				return errors.Errorf("%s %s does not exist", userOrRoleName(n.isRole), normalizedUsername)
+			}
+			continue
+		}
+
+		// Remove all memberships the user or role participates in.
+		if err := params.p.bumpRoleMembershipTableVersion(params.ctx); err != nil {
+			return err
+		}
+		if _, err := internalExecutor.ExecuteStatementInTransaction(
+			params.ctx,
+			"drop-user-memberships",
+			params.p.txn,
+			`DELETE FROM system.role_members WHERE "role" = $1 OR "member" = $1`,
+			normalizedUsername,
+		); err != nil {
+			return err
 		}
 
 		numDeleted += rowsAffected
@@ -1087,14 +1228,26 @@ func (n *dropUserNode) FastPathResults() (int, bool) { return n.numDeleted, true
 // DropUser drops a list of users.
 // Privileges: DELETE on system.users.
 func (p *planner) DropUser(ctx context.Context, n *parser.DropUser) (planNode, error) {
+	return p.newDropUserNode(ctx, n.Names, n.IfExists, false /* isRole */)
+}
+
+// DropRole drops a list of roles, along with their memberships.
+// Privileges: DELETE on system.users.
+func (p *planner) DropRole(ctx context.Context, n *parser.DropRole) (planNode, error) {
+	return p.newDropUserNode(ctx, n.Names, n.IfExists, true /* isRole */)
+}
+
+func (p *planner) newDropUserNode(
+	ctx context.Context, names parser.NameList, ifExists bool, isRole bool,
+) (*dropUserNode, error) {
 	tDesc, err := getTableDesc(ctx, p.txn, p.getVirtualTabler(), &parser.TableName{DatabaseName: "system", TableName: "users"})
 	if err != nil {
 		return nil, err
 	}
 
-	if err := p.CheckPrivilege(tDesc, privilege.DELETE); err != nil {
+	if err := p.CheckPrivilege(ctx, tDesc, privilege.DELETE); err != nil {
 		return nil, err
 	}
 
-	return &dropUserNode{n: n}, nil
+	return &dropUserNode{names: names, ifExists: ifExists, isRole: isRole}, nil
 }
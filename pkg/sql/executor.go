@@ -44,6 +44,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/stats"
+	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -276,6 +278,16 @@ type ExecutorConfig struct {
 	StatusServer    serverpb.StatusServer
 	SessionRegistry *SessionRegistry
 	JobRegistry     *jobs.Registry
+	// NodeLiveness is used to find the temporary schemas of dead nodes.
+	NodeLiveness *storage.NodeLiveness
+
+	// RoleMemberCache caches the role memberships of users.
+	RoleMemberCache *MembershipCache
+	// TableStatsCache caches the statistics in system.table_statistics.
+	TableStatsCache *stats.TableStatisticsCache
+	// TableStatsRefresher refreshes table statistics once enough rows have been
+	// modified.
+	TableStatsRefresher *stats.Refresher
 
 	TestingKnobs              *ExecutorTestingKnobs
 	SchemaChangerTestingKnobs *SchemaChangerTestingKnobs
@@ -428,6 +440,19 @@ func (e *Executor) Start(
 		}
 	})
 
+	if e.cfg.JobRegistry != nil {
+		e.cfg.JobRegistry.AddResumeHook(e.createStatsResumeHook)
+		e.cfg.JobRegistry.AddResumeHook(e.refreshMaterializedViewResumeHook)
+	}
+
+	if e.cfg.TableStatsRefresher != nil {
+		e.cfg.TableStatsRefresher.Start(
+			ctx, e.stopper, e.refreshTableStats, stats.DefaultRefreshInterval,
+		)
+	}
+
+	e.startTemporarySchemaSweeper(ctx, TemporarySchemaSweepInterval)
+
 	ctx = log.WithLogTag(ctx, "startup", nil)
 	startupSession := NewSession(ctx, SessionArgs{}, e, nil, startupMemMetrics)
 	startupSession.StartUnlimitedMonitor()
@@ -2107,6 +2132,8 @@ func checkResultType(typ parser.Type) error {
 	case parser.TypeTimestampTZ:
 	case parser.TypeInterval:
 	case parser.TypeUUID:
+	case parser.TypeINet:
+	case parser.TypeJSON:
 	case parser.TypeNameArray:
 	case parser.TypeOid:
 	case parser.TypeRegClass:
@@ -108,26 +108,21 @@ func doExpandPlan(
 		}
 		n.left, err = doExpandPlan(ctx, p, params, n.left)
 
-	case *filterNode:
-		n.source.plan, err = doExpandPlan(ctx, p, params, n.source.plan)
-
-	case *joinNode:
-		n.left.plan, err = doExpandPlan(ctx, p, noParams, n.left.plan)
-		if err != nil {
-			return plan, err
-		}
-		n.right.plan, err = doExpandPlan(ctx, p, noParams, n.right.plan)
+	case *recursiveCTENode:
+		n.initial, err = doExpandPlan(ctx, p, noParams, n.initial)
 		if err != nil {
 			return plan, err
 		}
+		n.recursive, err = doExpandPlan(ctx, p, noParams, n.recursive)
+
+	case *spoolNode:
+		n.source, err = doExpandPlan(ctx, p, noParams, n.source)
+
+	case *filterNode:
+		n.source.plan, err = doExpandPlan(ctx, p, params, n.source.plan)
 
-		n.mergeJoinOrdering = computeMergeJoinOrdering(
-			planOrdering(n.left.plan),
-			planOrdering(n.right.plan),
-			n.pred.leftEqualityIndices,
-			n.pred.rightEqualityIndices,
-		)
-		n.ordering = n.joinOrdering()
+	case *joinNode:
+		plan, err = p.expandJoinNode(ctx, n)
 
 	case *ordinalityNode:
 		// There may be too many columns in the required ordering. Filter them.
@@ -236,6 +231,7 @@ func doExpandPlan(
 
 	case *valuesNode:
 	case *alterTableNode:
+	case *alterSequenceNode:
 	case *cancelQueryNode:
 	case *controlJobNode:
 	case *copyNode:
@@ -243,11 +239,21 @@ func doExpandPlan(
 	case *createIndexNode:
 	case *createUserNode:
 	case *createViewNode:
+	case *createSequenceNode:
+	case *createStatsNode:
+	case *refreshMaterializedViewNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
 	case *dropTableNode:
 	case *dropViewNode:
+	case *dropSequenceNode:
 	case *dropUserNode:
+	case *grantRoleNode:
+	case *revokeRoleNode:
+	case *createFunctionNode:
+	case *dropFunctionNode:
+	case *createTriggerNode:
+	case *dropTriggerNode:
 	case *zeroNode:
 	case *unaryNode:
 	case *hookFnNode:
@@ -276,6 +282,52 @@ func elideDoubleSort(parent, source *sortNode) {
 	}
 }
 
+// expandJoinNode expands the sources of a join. Trees of inner joins are
+// flattened so that all their sources are expanded before a join order is
+// chosen; the resulting plan may thus be different from the joinNode.
+func (p *planner) expandJoinNode(ctx context.Context, n *joinNode) (planNode, error) {
+	if !isReorderableJoin(n) {
+		var err error
+		n.left.plan, err = doExpandPlan(ctx, p, noParams, n.left.plan)
+		if err != nil {
+			return n, err
+		}
+		n.right.plan, err = doExpandPlan(ctx, p, noParams, n.right.plan)
+		if err != nil {
+			return n, err
+		}
+		p.completeJoinExpansion(ctx, n)
+		return n, nil
+	}
+
+	var t joinTree
+	t.addSource(&p.evalCtx, &planDataSource{plan: n})
+	for _, src := range t.sources {
+		var err error
+		src.plan, err = doExpandPlan(ctx, p, noParams, src.plan)
+		if err != nil {
+			return n, err
+		}
+	}
+	for _, j := range t.joins {
+		p.completeJoinExpansion(ctx, j)
+	}
+	return p.reorderJoins(ctx, n, &t)
+}
+
+// completeJoinExpansion computes the ordering of a join whose sources are
+// expanded and chooses the algorithm used to run it.
+func (p *planner) completeJoinExpansion(ctx context.Context, n *joinNode) {
+	n.mergeJoinOrdering = computeMergeJoinOrdering(
+		planOrdering(n.left.plan),
+		planOrdering(n.right.plan),
+		n.pred.leftEqualityIndices,
+		n.pred.rightEqualityIndices,
+	)
+	n.ordering = n.joinOrdering()
+	p.chooseJoinAlgorithm(ctx, n)
+}
+
 func expandScanNode(
 	ctx context.Context, p *planner, params expandParameters, s *scanNode,
 ) (planNode, error) {
@@ -444,6 +496,13 @@ func simplifyOrderings(plan planNode, usefulOrdering sqlbase.ColumnOrdering) pla
 		n.right = simplifyOrderings(n.right, nil)
 		n.left = simplifyOrderings(n.left, nil)
 
+	case *recursiveCTENode:
+		n.initial = simplifyOrderings(n.initial, nil)
+		n.recursive = simplifyOrderings(n.recursive, nil)
+
+	case *spoolNode:
+		n.source = simplifyOrderings(n.source, nil)
+
 	case *filterNode:
 		n.source.plan = simplifyOrderings(n.source.plan, usefulOrdering)
 
@@ -556,6 +615,7 @@ func simplifyOrderings(plan planNode, usefulOrdering sqlbase.ColumnOrdering) pla
 
 	case *valuesNode:
 	case *alterTableNode:
+	case *alterSequenceNode:
 	case *cancelQueryNode:
 	case *controlJobNode:
 	case *copyNode:
@@ -563,11 +623,21 @@ func simplifyOrderings(plan planNode, usefulOrdering sqlbase.ColumnOrdering) pla
 	case *createIndexNode:
 	case *createUserNode:
 	case *createViewNode:
+	case *createSequenceNode:
+	case *createStatsNode:
+	case *refreshMaterializedViewNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
 	case *dropTableNode:
 	case *dropViewNode:
+	case *dropSequenceNode:
 	case *dropUserNode:
+	case *grantRoleNode:
+	case *revokeRoleNode:
+	case *createFunctionNode:
+	case *dropFunctionNode:
+	case *createTriggerNode:
+	case *dropTriggerNode:
 	case *zeroNode:
 	case *unaryNode:
 	case *hookFnNode:
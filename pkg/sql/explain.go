@@ -24,6 +24,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlrun"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 )
 
 type explainMode int
@@ -52,6 +54,7 @@ func (p *planner) Explain(ctx context.Context, n *parser.Explain) (planNode, err
 	optimized := true
 	expanded := true
 	normalizeExprs := true
+	analyze := false
 	explainer := explainer{
 		showMetadata: false,
 		showExprs:    false,
@@ -110,6 +113,12 @@ func (p *planner) Explain(ctx context.Context, n *parser.Explain) (planNode, err
 			case "nooptimize":
 				optimized = false
 
+			case "analyze":
+				analyze = true
+
+			case "costs":
+				explainer.showCosts = true
+
 			default:
 				return nil, fmt.Errorf("unsupported EXPLAIN option: %s", opt)
 			}
@@ -124,6 +133,12 @@ func (p *planner) Explain(ctx context.Context, n *parser.Explain) (planNode, err
 	if mode == explainNone {
 		mode = explainPlan
 	}
+	if analyze && (!expanded || !optimized) {
+		return nil, fmt.Errorf("cannot use EXPLAIN option ANALYZE with NOEXPAND or NOOPTIMIZE")
+	}
+	if explainer.showCosts && (!expanded || !optimized) {
+		return nil, fmt.Errorf("cannot use EXPLAIN option COSTS with NOEXPAND or NOOPTIMIZE")
+	}
 
 	p.evalCtx.SkipNormalize = !normalizeExprs
 
@@ -137,12 +152,16 @@ func (p *planner) Explain(ctx context.Context, n *parser.Explain) (planNode, err
 			plan:           plan,
 			distSQLPlanner: p.session.distSQLPlanner,
 			txn:            p.txn,
+			analyze:        analyze,
 		}, nil
 
 	case explainPlan:
 		// We may want to show placeholder types, so ensure no values
 		// are missing.
 		p.semaCtx.Placeholders.FillUnassigned()
+		if analyze {
+			explainer.stats = make(map[planNode]*planNodeStats)
+		}
 		return p.makeExplainPlanNode(explainer, expanded, optimized, plan), nil
 
 	default:
@@ -161,6 +180,10 @@ type explainDistSQLNode struct {
 	// txn is the current transaction (used for the fake span resolver).
 	txn *client.Txn
 
+	// analyze indicates that the plan is run and that the runtime statistics
+	// of the processors are included in the diagram.
+	analyze bool
+
 	// The single row returned by the node.
 	values parser.Datums
 
@@ -194,7 +217,15 @@ func (n *explainDistSQLNode) Start(params runParams) error {
 	}
 	n.distSQLPlanner.FinalizePlan(&planCtx, &plan)
 	flows := plan.GenerateFlowSpecs(params.p.evalCtx.NodeID)
-	planJSON, planURL, err := distsqlrun.GeneratePlanDiagramWithURL(flows)
+
+	var stats map[int32]distsqlrun.ProcessorStats
+	if n.analyze {
+		stats, err = n.runAnalyze(params, &planCtx, &plan)
+		if err != nil {
+			return err
+		}
+	}
+	planJSON, planURL, err := distsqlrun.GeneratePlanDiagramWithURL(flows, stats)
 	if err != nil {
 		return err
 	}
@@ -218,3 +249,39 @@ func (n *explainDistSQLNode) Next(runParams) (bool, error) {
 func (n *explainDistSQLNode) Values() parser.Datums {
 	return n.values
 }
+
+// runAnalyze runs the physical plan with tracing enabled and returns the
+// runtime statistics collected for its processors. The results of the query are
+// discarded.
+func (n *explainDistSQLNode) runAnalyze(
+	params runParams, planCtx *planningCtx, plan *physicalPlan,
+) (map[int32]distsqlrun.ProcessorStats, error) {
+	ctx, sp, err := tracing.StartSnowballTrace(
+		params.ctx, params.p.ExecCfg().AmbientCtx.Tracer, "explain analyze",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer sp.Finish()
+
+	execCfg := params.p.ExecCfg()
+	recv, err := makeDistSQLReceiver(
+		ctx, discardRowsWriter{},
+		execCfg.RangeDescriptorCache, execCfg.LeaseHolderCache,
+		n.txn,
+		func(ts hlc.Timestamp) {
+			_ = execCfg.Clock.Update(ts)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	planCtx.ctx = ctx
+	if err := n.distSQLPlanner.Run(planCtx, n.txn, plan, &recv, params.p.evalCtx); err != nil {
+		return nil, err
+	}
+	if recv.err != nil {
+		return nil, recv.err
+	}
+	return distsqlrun.ExtractProcessorStats(tracing.GetRecording(sp)), nil
+}
@@ -0,0 +1,200 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// planNodeStats contains the runtime statistics collected for a planNode by
+// EXPLAIN ANALYZE.
+type planNodeStats struct {
+	// rows is the number of rows produced by the node.
+	rows int64
+	// time is the time spent in the node's Start() and Next() methods,
+	// including the time spent in its sources.
+	time time.Duration
+	// maxMemory is the peak amount of memory registered with the node's own
+	// memory accounts, sampled after each call to Start() and Next().
+	maxMemory int64
+}
+
+// memoryAccountingNode is implemented by the planNodes that register the
+// memory they allocate with memory accounts.
+type memoryAccountingNode interface {
+	// memUsage returns the number of bytes currently registered with the
+	// node's memory accounts, excluding those of its sources.
+	memUsage() int64
+}
+
+// analyzeNode wraps a planNode and collects its runtime statistics. It is
+// transparent to walkPlan(), planColumns() and planOrdering().
+type analyzeNode struct {
+	plan  planNode
+	stats planNodeStats
+}
+
+func (n *analyzeNode) Start(params runParams) error {
+	start := timeutil.Now()
+	err := n.plan.Start(params)
+	n.stats.time += timeutil.Since(start)
+	n.recordMemUsage()
+	return err
+}
+
+func (n *analyzeNode) Next(params runParams) (bool, error) {
+	start := timeutil.Now()
+	next, err := n.plan.Next(params)
+	n.stats.time += timeutil.Since(start)
+	if next {
+		n.stats.rows++
+	}
+	n.recordMemUsage()
+	return next, err
+}
+
+// recordMemUsage updates the peak memory usage of the node.
+func (n *analyzeNode) recordMemUsage() {
+	if m, ok := n.plan.(memoryAccountingNode); ok {
+		if usage := m.memUsage(); usage > n.stats.maxMemory {
+			n.stats.maxMemory = usage
+		}
+	}
+}
+
+func (n *analyzeNode) Values() parser.Datums     { return n.plan.Values() }
+func (n *analyzeNode) Close(ctx context.Context) { n.plan.Close(ctx) }
+
+// FastPathResults implements the planNodeFastPath interface.
+func (n *analyzeNode) FastPathResults() (int, bool) {
+	if f, ok := n.plan.(planNodeFastPath); ok {
+		return f.FastPathResults()
+	}
+	return 0, false
+}
+
+// instrumentPlan wraps the nodes of the plan in analyzeNodes, so that their
+// statistics are collected when the plan runs. The statistics are registered
+// in the stats map, keyed by the wrapped node.
+func instrumentPlan(plan planNode, stats map[planNode]*planNodeStats) planNode {
+	switch n := plan.(type) {
+	case *filterNode:
+		n.source.plan = instrumentPlan(n.source.plan, stats)
+	case *renderNode:
+		n.source.plan = instrumentPlan(n.source.plan, stats)
+	case *joinNode:
+		n.left.plan = instrumentPlan(n.left.plan, stats)
+		n.right.plan = instrumentPlan(n.right.plan, stats)
+	case *limitNode:
+		n.plan = instrumentPlan(n.plan, stats)
+	case *distinctNode:
+		n.plan = instrumentPlan(n.plan, stats)
+	case *sortNode:
+		n.plan = instrumentPlan(n.plan, stats)
+	case *groupNode:
+		n.plan = instrumentPlan(n.plan, stats)
+	case *windowNode:
+		n.plan = instrumentPlan(n.plan, stats)
+	case *unionNode:
+		n.left = instrumentPlan(n.left, stats)
+		n.right = instrumentPlan(n.right, stats)
+	case *ordinalityNode:
+		n.source = instrumentPlan(n.source, stats)
+	case *spoolNode:
+		n.source = instrumentPlan(n.source, stats)
+	case *insertNode:
+		n.run.rows = instrumentPlan(n.run.rows, stats)
+	case *updateNode:
+		n.run.rows = instrumentPlan(n.run.rows, stats)
+
+	case *deleteNode:
+		// The source of a DELETE is not instrumented, so that the fast path
+		// (which requires the source to be a scanNode) is still detected.
+
+	case *indexJoinNode:
+		// The index and table scanNodes are used directly by the indexJoinNode;
+		// only their KV statistics are reported.
+	}
+
+	a := &analyzeNode{plan: plan}
+	stats[plan] = &a.stats
+	return a
+}
+
+// makeStatsRow produces the columns of EXPLAIN ANALYZE for a node: the number
+// of rows it produced, the KV batches and bytes read by the node itself, the
+// time spent in the node (including its sources) and the peak memory used by
+// the node itself. The values are NULL when the statistic is not available for
+// the node.
+func makeStatsRow(plan planNode, stats map[planNode]*planNodeStats) parser.Datums {
+	row := parser.Datums{parser.DNull, parser.DNull, parser.DNull, parser.DNull, parser.DNull}
+	if plan == nil {
+		return row
+	}
+	if s, ok := plan.(*scanNode); ok {
+		kvStats := s.fetcher.KVStats()
+		row[1] = parser.NewDInt(parser.DInt(kvStats.Batches))
+		row[2] = parser.NewDInt(parser.DInt(kvStats.Bytes))
+	}
+	if s, ok := stats[plan]; ok {
+		row[0] = parser.NewDInt(parser.DInt(s.rows))
+		row[3] = &parser.DInterval{Duration: duration.Duration{Nanos: s.time.Nanoseconds()}}
+		if _, ok := plan.(memoryAccountingNode); ok {
+			row[4] = parser.NewDInt(parser.DInt(s.maxMemory))
+		}
+	}
+	return row
+}
+
+// runAnalyze runs the plan wrapped by an EXPLAIN ANALYZE to completion,
+// collecting the runtime statistics of its nodes. The results are discarded.
+func (e *explainPlanNode) runAnalyze(params runParams) error {
+	e.plan = instrumentPlan(e.plan, e.explainer.stats)
+	if err := params.p.startPlan(params.ctx, e.plan); err != nil {
+		return err
+	}
+	if f, ok := e.plan.(planNodeFastPath); ok {
+		if _, done := f.FastPathResults(); done {
+			return nil
+		}
+	}
+	for {
+		next, err := e.plan.Next(params)
+		if err != nil || !next {
+			return err
+		}
+	}
+}
+
+// discardRowsWriter is a rowResultWriter that discards the results of a query
+// run by EXPLAIN (DISTSQL, ANALYZE).
+type discardRowsWriter struct{}
+
+var _ rowResultWriter = discardRowsWriter{}
+
+// AddRow implements the rowResultWriter interface.
+func (discardRowsWriter) AddRow(context.Context, parser.Datums) error { return nil }
+
+// IncrementRowsAffected implements the rowResultWriter interface.
+func (discardRowsWriter) IncrementRowsAffected(int) {}
+
+// StatementType implements the rowResultWriter interface.
+func (discardRowsWriter) StatementType() parser.StatementType { return parser.Rows }
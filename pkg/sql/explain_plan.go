@@ -17,6 +17,7 @@ package sql
 import (
 	"bytes"
 	"fmt"
+	"math"
 
 	"golang.org/x/net/context"
 
@@ -57,6 +58,14 @@ type explainer struct {
 	// with leading white spaces.
 	doIndent bool
 
+	// showCosts indicates whether the output has columns for the estimated
+	// cardinality and cost of the nodes.
+	showCosts bool
+
+	// stats, if non-nil, indicates that the plan is run (EXPLAIN ANALYZE); it
+	// holds the runtime statistics of the plan's nodes.
+	stats map[planNode]*planNodeStats
+
 	// makeRow produces one row of EXPLAIN output.
 	makeRow func(level int, typ, field, desc string, plan planNode)
 
@@ -84,6 +93,30 @@ func (p *planner) makeExplainPlanNode(
 		// Ordering indicates the known ordering of the data from this source.
 		columns = append(columns, sqlbase.ResultColumn{Name: "Ordering", Typ: parser.TypeString})
 	}
+	if explainer.showCosts {
+		columns = append(columns,
+			// Estimated Rows is the estimated number of rows produced by the node.
+			sqlbase.ResultColumn{Name: "Estimated Rows", Typ: parser.TypeFloat},
+			// Estimated Cost is the estimated cost of the node, including its
+			// sources.
+			sqlbase.ResultColumn{Name: "Estimated Cost", Typ: parser.TypeFloat},
+		)
+	}
+	if explainer.stats != nil {
+		columns = append(columns,
+			// Rows is the number of rows produced by the node.
+			sqlbase.ResultColumn{Name: "Rows", Typ: parser.TypeInt},
+			// KV Batches is the number of KV batches sent by the node.
+			sqlbase.ResultColumn{Name: "KV Batches", Typ: parser.TypeInt},
+			// KV Bytes is the number of bytes read from KV by the node.
+			sqlbase.ResultColumn{Name: "KV Bytes", Typ: parser.TypeInt},
+			// Time is the time spent in the node, including its sources.
+			sqlbase.ResultColumn{Name: "Time", Typ: parser.TypeInterval},
+			// Max Memory is the peak number of bytes of memory used by the node,
+			// excluding its sources.
+			sqlbase.ResultColumn{Name: "Max Memory", Typ: parser.TypeInt},
+		)
+	}
 
 	explainer.fmtFlags = parser.FmtExpr(
 		parser.FmtSimple, explainer.showTypes, explainer.symbolicVars, explainer.qualifyNames,
@@ -126,6 +159,17 @@ func (p *planner) populateExplain(
 				row = append(row, emptyString, emptyString)
 			}
 		}
+		if e.showCosts {
+			if plan != nil {
+				est := p.estimatePlan(ctx, plan)
+				row = append(row, roundEstimate(est.rowCount), roundEstimate(est.cost))
+			} else {
+				row = append(row, parser.DNull, parser.DNull)
+			}
+		}
+		if e.stats != nil {
+			row = append(row, makeStatsRow(plan, e.stats)...)
+		}
 		if _, err := v.rows.AddRow(ctx, row); err != nil {
 			e.err = err
 		}
@@ -136,6 +180,11 @@ func (p *planner) populateExplain(
 	return e.err
 }
 
+// roundEstimate rounds an estimate to two decimals for display.
+func roundEstimate(f float64) parser.Datum {
+	return parser.NewDFloat(parser.DFloat(math.Floor(f*100+0.5) / 100))
+}
+
 // planToString uses explain() to build a string representation of the planNode.
 func planToString(ctx context.Context, plan planNode) string {
 	var buf bytes.Buffer
@@ -275,9 +324,14 @@ func (e *explainPlanNode) Next(params runParams) (bool, error) { return e.result
 func (e *explainPlanNode) Values() parser.Datums               { return e.results.Values() }
 
 func (e *explainPlanNode) Start(params runParams) error {
-	// Note that we don't call start on e.plan. That's on purpose, Start() can
-	// have side effects. And it's supposed to not be needed for the way in which
-	// we're going to use e.plan.
+	// Note that we don't call start on e.plan, unless EXPLAIN ANALYZE was
+	// requested. That's on purpose, Start() can have side effects. And it's
+	// supposed to not be needed for the way in which we're going to use e.plan.
+	if e.explainer.stats != nil {
+		if err := e.runAnalyze(params); err != nil {
+			return err
+		}
+	}
 	return params.p.populateExplain(params.ctx, &e.explainer, e.results, e.plan)
 }
 
@@ -84,6 +84,8 @@ import (
 //
 //     f( [limit FROM A], P )       = P, [limit FROM t(A)]
 //     f( [ordinality FROM A], P )  = P, [ordinality FROM t(A)]
+//     f( [recursive cte FROM A, B], P )  = P, [recursive cte FROM t(A), t(B)]
+//     f( [spool FROM A], P )       = P, [spool FROM t(A)]
 //
 // Some nodes currently block filtering entirely, but could be
 // optimized later to let some filters through (see inline comments
@@ -265,6 +267,21 @@ func (p *planner) propagateFilters(
 			return plan, extraFilter, err
 		}
 
+	case *recursiveCTENode:
+		// The rows of both terms are fed back into the recursive term, so
+		// filtering them would change the result.
+		if n.initial, err = p.triggerFilterPropagation(ctx, n.initial); err != nil {
+			return plan, extraFilter, err
+		}
+		if n.recursive, err = p.triggerFilterPropagation(ctx, n.recursive); err != nil {
+			return plan, extraFilter, err
+		}
+
+	case *spoolNode:
+		if n.source, err = p.triggerFilterPropagation(ctx, n.source); err != nil {
+			return plan, extraFilter, err
+		}
+
 	case *createTableNode:
 		if n.n.As() {
 			if n.sourcePlan, err = p.triggerFilterPropagation(ctx, n.sourcePlan); err != nil {
@@ -322,6 +339,7 @@ func (p *planner) propagateFilters(
 		}
 
 	case *alterTableNode:
+	case *alterSequenceNode:
 	case *cancelQueryNode:
 	case *controlJobNode:
 	case *copyNode:
@@ -329,11 +347,21 @@ func (p *planner) propagateFilters(
 	case *createIndexNode:
 	case *createUserNode:
 	case *createViewNode:
+	case *createSequenceNode:
+	case *createStatsNode:
+	case *refreshMaterializedViewNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
 	case *dropTableNode:
 	case *dropViewNode:
+	case *dropSequenceNode:
 	case *dropUserNode:
+	case *grantRoleNode:
+	case *revokeRoleNode:
+	case *createFunctionNode:
+	case *dropFunctionNode:
+	case *createTriggerNode:
+	case *dropTriggerNode:
 	case *hookFnNode:
 	case *valueGenerator:
 	case *valuesNode:
@@ -407,11 +435,14 @@ func (p *planner) addGroupFilter(
 		// The filter that's being added refers to the result expressions,
 		// not the groupNode's source node. We need to detect which parts
 		// of the filter refer to passed-through source columns ("IDENT
-		// aggregations"), and renumber the indexed vars accordingly.
+		// aggregations"), and renumber the indexed vars accordingly. With
+		// grouping sets, a column can only be filtered before the aggregation
+		// if it is part of all the grouping sets (otherwise it is NULL in some
+		// of the groups).
 		convFunc := func(v parser.VariableExpr) (bool, parser.Expr) {
 			if iv, ok := v.(*parser.IndexedVar); ok {
 				f := g.funcs[iv.Idx]
-				if f.identAggregate {
+				if f.identAggregate && g.inAllGroupingSets(f.argRenderIdx) {
 					return true, &parser.IndexedVar{Idx: f.argRenderIdx}
 				}
 			}
@@ -705,6 +736,14 @@ func (p *planner) addJoinFilter(
 		//   n.pred.onCond have been processed by the code above.
 		initialPred := mergeConj(extraFilter, n.pred.onCond)
 
+		if n.lateral != nil {
+			// The right side of a lateral join is planned anew for
+			// every row of the left side, so nothing can be propagated
+			// to it.
+			propagateLeft, onRemainder = splitJoinFilterLeft(n, leftBegin, rightBegin, initialPred)
+			break
+		}
+
 		// Now split the combined predicate.
 		propagateLeft, propagateRight, onRemainder = splitJoinFilter(
 			n, leftBegin, rightBegin, initialPred,
@@ -727,6 +766,11 @@ func (p *planner) addJoinFilter(
 		// Extract filterLeft towards propagation on the left.
 		// filterRemainder = filterRight AND filterCombined.
 		propagateLeft, filterRemainder = splitJoinFilterLeft(n, leftBegin, rightBegin, extraFilter)
+		if n.lateral != nil {
+			// See the inner join case above.
+			onRemainder = n.pred.onCond
+			break
+		}
 		// Extract onRight towards propagation on the right.
 		// onRemainder = onLeft AND onCombined.
 		propagateRight, onRemainder = splitJoinFilterRight(n, leftBegin, rightBegin, n.pred.onCond)
@@ -0,0 +1,532 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// maxFunctionCallDepth limits the nesting of calls to user-defined
+// functions, including the calls made by triggers.
+const maxFunctionCallDepth = 32
+
+// createFunctionNode implements CREATE FUNCTION.
+type createFunctionNode struct {
+	n      *parser.CreateFunction
+	dbDesc *sqlbase.DatabaseDescriptor
+	name   string
+}
+
+// CreateFunction creates a user-defined function.
+// Privileges: CREATE on database.
+//   Notes: postgres requires USAGE on the language and on the argument
+//          and return types.
+func (p *planner) CreateFunction(
+	ctx context.Context, n *parser.CreateFunction,
+) (planNode, error) {
+	if lang := n.Language.Normalize(); lang != "sql" {
+		return nil, pgerror.NewErrorf(pgerror.CodeUndefinedObjectError,
+			"language %q does not exist", lang)
+	}
+	dbDesc, name, err := p.resolveFunctionName(ctx, n.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.CheckPrivilege(ctx, dbDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+	if _, err := (parser.UnresolvedName{parser.Name(name)}).ResolveFunction(
+		p.session.SearchPath,
+	); err == nil {
+		return nil, pgerror.NewErrorf(pgerror.CodeDuplicateFunctionError,
+			"cannot create function %s(): a built-in function with the same name exists", name)
+	}
+	if err := validateFunctionBody(n); err != nil {
+		return nil, err
+	}
+	return &createFunctionNode{n: n, dbDesc: dbDesc, name: name}, nil
+}
+
+func (n *createFunctionNode) Start(params runParams) error {
+	// The definition is stored without OR REPLACE and with an unqualified
+	// name, since the function is identified by the columns of its row.
+	def := *n.n
+	def.Replace = false
+	def.Name = parser.UnresolvedName{parser.Name(n.name)}
+	args := functionArgTypesKey(def.Args)
+
+	stmt := `INSERT INTO system.functions ("parentID", name, "argTypes", definition) ` +
+		`VALUES ($1, $2, $3, $4) ON CONFLICT ("parentID", name, "argTypes") DO NOTHING`
+	if n.n.Replace {
+		stmt = `UPSERT INTO system.functions ("parentID", name, "argTypes", definition) ` +
+			`VALUES ($1, $2, $3, $4)`
+	}
+	internalExecutor := InternalExecutor{LeaseManager: params.p.LeaseMgr()}
+	rowsAffected, err := internalExecutor.ExecuteStatementInTransaction(
+		params.ctx, "create-function", params.p.txn, stmt,
+		n.dbDesc.ID, n.name, args, parser.AsString(&def),
+	)
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return pgerror.NewErrorf(pgerror.CodeDuplicateFunctionError,
+			"function %s(%s) already exists", n.name, args)
+	}
+	return nil
+}
+
+func (*createFunctionNode) Next(runParams) (bool, error) { return false, nil }
+func (*createFunctionNode) Close(context.Context)        {}
+func (*createFunctionNode) Values() parser.Datums        { return parser.Datums{} }
+
+// dropFunctionNode implements DROP FUNCTION.
+type dropFunctionNode struct {
+	n      *parser.DropFunction
+	dbDesc *sqlbase.DatabaseDescriptor
+	name   string
+}
+
+// DropFunction drops a user-defined function.
+// Privileges: DROP on database.
+//   Notes: postgres requires ownership of the function.
+func (p *planner) DropFunction(ctx context.Context, n *parser.DropFunction) (planNode, error) {
+	dbDesc, name, err := p.resolveFunctionName(ctx, n.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.CheckPrivilege(ctx, dbDesc, privilege.DROP); err != nil {
+		return nil, err
+	}
+	return &dropFunctionNode{n: n, dbDesc: dbDesc, name: name}, nil
+}
+
+func (n *dropFunctionNode) Start(params runParams) error {
+	ctx := params.ctx
+	p := params.p
+	internalExecutor := InternalExecutor{LeaseManager: p.LeaseMgr()}
+	rows, err := internalExecutor.QueryRowsInTransaction(
+		ctx, "drop-function", p.txn,
+		`SELECT "argTypes" FROM system.functions WHERE "parentID" = $1 AND name = $2`,
+		n.dbDesc.ID, n.name,
+	)
+	if err != nil {
+		return err
+	}
+
+	var args string
+	if n.n.ArgTypes == nil {
+		switch len(rows) {
+		case 0:
+		case 1:
+			args = string(parser.MustBeDString(rows[0][0]))
+		default:
+			return pgerror.NewErrorf(pgerror.CodeAmbiguousFunctionError,
+				"function name %q is not unique", n.name).SetHintf(
+				"Specify the argument list to select the function unambiguously.")
+		}
+	} else {
+		args = columnTypesKey(n.n.ArgTypes)
+	}
+	found := false
+	for _, row := range rows {
+		if string(parser.MustBeDString(row[0])) == args {
+			found = true
+			break
+		}
+	}
+	if !found {
+		if n.n.IfExists {
+			return nil
+		}
+		return pgerror.NewErrorf(pgerror.CodeUndefinedFunctionError,
+			"function %s(%s) does not exist", n.name, args)
+	}
+
+	if args == "" {
+		// Only functions without arguments can be used by triggers.
+		if err := p.checkNoTriggerUsesFunction(ctx, n.dbDesc, n.name); err != nil {
+			return err
+		}
+	}
+
+	_, err = internalExecutor.ExecuteStatementInTransaction(
+		ctx, "drop-function", p.txn,
+		`DELETE FROM system.functions WHERE "parentID" = $1 AND name = $2 AND "argTypes" = $3`,
+		n.dbDesc.ID, n.name, args,
+	)
+	return err
+}
+
+func (*dropFunctionNode) Next(runParams) (bool, error) { return false, nil }
+func (*dropFunctionNode) Close(context.Context)        {}
+func (*dropFunctionNode) Values() parser.Datums        { return parser.Datums{} }
+
+// resolveFunctionName splits the name of a user-defined function into the
+// descriptor of its database and its normalized unqualified name.
+func (p *planner) resolveFunctionName(
+	ctx context.Context, n parser.UnresolvedName,
+) (*sqlbase.DatabaseDescriptor, string, error) {
+	var dbName string
+	var name parser.Name
+	switch len(n) {
+	case 1:
+		name, _ = n[0].(parser.Name)
+		dbName = p.session.Database
+	case 2:
+		var db parser.Name
+		db, _ = n[0].(parser.Name)
+		name, _ = n[1].(parser.Name)
+		dbName = db.Normalize()
+	}
+	if name == "" {
+		return nil, "", pgerror.NewErrorf(pgerror.CodeSyntaxError, "invalid function name: %s", n)
+	}
+	if dbName == "" {
+		return nil, "", errNoDatabase
+	}
+	dbDesc, err := MustGetDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), dbName)
+	if err != nil {
+		return nil, "", err
+	}
+	return dbDesc, name.Normalize(), nil
+}
+
+// validateFunctionBody checks that the body of a function consists of
+// statements that can be run by the function, and that the last statement
+// returns the result of the function if there is one.
+func validateFunctionBody(n *parser.CreateFunction) error {
+	stmts, err := parser.Parse(n.Body)
+	if err != nil {
+		return err
+	}
+	for _, stmt := range stmts {
+		switch stmt.(type) {
+		case *parser.Select, *parser.ParenSelect, *parser.Insert, *parser.Update, *parser.Delete:
+		default:
+			return pgerror.NewErrorf(pgerror.CodeInvalidFunctionDefinitionError,
+				"%s statements are not allowed in SQL functions", stmt.StatementTag())
+		}
+	}
+	if n.ReturnKind == parser.FunctionReturnsType {
+		if len(stmts) == 0 || stmts[len(stmts)-1].StatementType() != parser.Rows {
+			return pgerror.NewErrorf(pgerror.CodeInvalidFunctionDefinitionError,
+				"return type mismatch in function declared to return %s", n.ReturnType).SetDetailf(
+				"Function's final statement must be SELECT or INSERT/UPDATE/DELETE RETURNING.")
+		}
+	}
+	return nil
+}
+
+// functionArgTypesKey returns the value of the argTypes column of
+// system.functions for a function with the given arguments.
+func functionArgTypesKey(args parser.FunctionArgs) string {
+	types := make([]parser.ColumnType, len(args))
+	for i, arg := range args {
+		types[i] = arg.Type
+	}
+	return columnTypesKey(types)
+}
+
+// columnTypesKey returns the comma-separated names of the given types, in
+// the format of the argTypes column of system.functions.
+func columnTypesKey(types []parser.ColumnType) string {
+	names := make([]string, len(types))
+	for i, typ := range types {
+		names[i] = parser.CastTargetToDatumType(typ).String()
+	}
+	return strings.Join(names, ",")
+}
+
+// makeUserDefinedFunction creates the overload described by a CREATE
+// FUNCTION statement stored in system.functions.
+func makeUserDefinedFunction(database, definition string) (*parser.UserDefinedFunction, error) {
+	stmt, err := parser.ParseOne(definition)
+	if err != nil {
+		return nil, err
+	}
+	def, ok := stmt.(*parser.CreateFunction)
+	if !ok {
+		return nil, pgerror.NewErrorf(pgerror.CodeInternalError,
+			"invalid function definition: %s", definition)
+	}
+	fn := &parser.UserDefinedFunction{
+		Database:   database,
+		Def:        def,
+		ArgTypes:   make(parser.ArgTypes, len(def.Args)),
+		ReturnType: parser.TypeNull,
+	}
+	for i, arg := range def.Args {
+		fn.ArgTypes[i].Name = arg.Name.Normalize()
+		fn.ArgTypes[i].Typ = parser.CastTargetToDatumType(arg.Type)
+	}
+	if def.ReturnKind == parser.FunctionReturnsType {
+		fn.ReturnType = parser.CastTargetToDatumType(def.ReturnType)
+	}
+	return fn, nil
+}
+
+// lookupUserDefinedFunctions returns the overloads of the user-defined
+// function with the given name in the given database.
+func (p *planner) lookupUserDefinedFunctions(
+	ctx context.Context, dbDesc *sqlbase.DatabaseDescriptor, name string,
+) ([]*parser.UserDefinedFunction, error) {
+	internalExecutor := InternalExecutor{LeaseManager: p.LeaseMgr()}
+	rows, err := internalExecutor.QueryRowsInTransaction(
+		ctx, "lookup-function", p.txn,
+		`SELECT definition FROM system.functions WHERE "parentID" = $1 AND name = $2`,
+		dbDesc.ID, name,
+	)
+	if err != nil {
+		return nil, err
+	}
+	fns := make([]*parser.UserDefinedFunction, len(rows))
+	for i, row := range rows {
+		if fns[i], err = makeUserDefinedFunction(
+			dbDesc.Name, string(parser.MustBeDString(row[0])),
+		); err != nil {
+			return nil, err
+		}
+	}
+	return fns, nil
+}
+
+// LookupFunction implements the parser.FunctionResolver interface.
+func (p *planner) LookupFunction(prefix, name string) (*parser.FunctionDefinition, error) {
+	if p.txn == nil || p.LeaseMgr() == nil {
+		// Functions are stored in a table, which cannot be read without a
+		// transaction.
+		return nil, nil
+	}
+	dbName := prefix
+	if dbName == "" {
+		dbName = p.session.Database
+	}
+	if dbName == "" {
+		return nil, nil
+	}
+	ctx := p.session.Ctx()
+	dbDesc, err := getDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), dbName)
+	if err != nil || dbDesc == nil {
+		return nil, err
+	}
+	fns, err := p.lookupUserDefinedFunctions(ctx, dbDesc, name)
+	if err != nil || len(fns) == 0 {
+		return nil, err
+	}
+	return parser.NewUserDefinedFunctionDefinition(dbName+"."+name, fns), nil
+}
+
+// EvalUserDefinedFunction implements the parser.EvalPlanner interface.
+func (p *planner) EvalUserDefinedFunction(
+	evalCtx *parser.EvalContext, fn *parser.UserDefinedFunction, args parser.Datums,
+) (parser.Datum, error) {
+	if fn.Def.ReturnKind == parser.FunctionReturnsTrigger {
+		return nil, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"trigger functions can only be called as triggers")
+	}
+	// Arguments can be referred to both by name and by position.
+	argIdx := make(map[string]int, len(fn.ArgTypes))
+	for i, arg := range fn.ArgTypes {
+		if arg.Name != "" {
+			argIdx[arg.Name] = i
+		}
+	}
+	bind := func(n parser.UnresolvedName) (int, bool, error) {
+		if len(n) != 1 {
+			return 0, false, nil
+		}
+		name, ok := n[0].(parser.Name)
+		if !ok {
+			return 0, false, nil
+		}
+		idx, ok := argIdx[name.Normalize()]
+		return idx, ok, nil
+	}
+	types := make([]parser.Type, len(fn.ArgTypes))
+	for i, arg := range fn.ArgTypes {
+		types[i] = arg.Typ
+	}
+	row, err := p.runFunctionBody(evalCtx.Ctx(), fn, bind, types, args)
+	if err != nil {
+		return nil, err
+	}
+	result := parser.Datum(parser.DNull)
+	if len(row) > 0 {
+		result = row[0]
+	}
+	if fn.Def.ReturnKind != parser.FunctionReturnsType {
+		return parser.DNull, nil
+	}
+	if result == parser.DNull || result.ResolvedType().Equivalent(fn.ReturnType) {
+		return result, nil
+	}
+	cast := &parser.CastExpr{Expr: result, Type: fn.Def.ReturnType}
+	typedCast, err := cast.TypeCheck(&p.semaCtx, fn.ReturnType)
+	if err != nil {
+		return nil, pgerror.NewErrorf(pgerror.CodeDatatypeMismatchError,
+			"return type mismatch in function declared to return %s", fn.Def.ReturnType)
+	}
+	return typedCast.Eval(&p.evalCtx)
+}
+
+// functionBinder maps a name used in an expression in the body of a
+// function to the index of the value it refers to, if any.
+type functionBinder func(parser.UnresolvedName) (idx int, ok bool, err error)
+
+// functionBody is the body of a user-defined function prepared for
+// execution: its statements, with the names bound to the values the function
+// is called with replaced by placeholders.
+type functionBody struct {
+	stmts []parser.Statement
+}
+
+// prepareFunctionBody returns the body of fn with the names bound by bind
+// replaced by placeholders. Bodies are cached for the duration of the
+// statement, so that a function called for every row of a statement is only
+// parsed and rewritten once. They are keyed by the definition of the function
+// rather than by fn, which is resolved again each time a statement calling
+// it is planned; the binder only depends on the definition.
+func (p *planner) prepareFunctionBody(
+	fn *parser.UserDefinedFunction, bind functionBinder,
+) (*functionBody, error) {
+	key := fn.Database + "." + parser.AsString(fn.Def)
+	if body, ok := p.functionBodies[key]; ok {
+		return body, nil
+	}
+	stmts, err := parser.Parse(fn.Def.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var bindErr error
+	fmtFlags := parser.FmtReformatUnresolvedNames(parser.FmtSimple,
+		func(n parser.UnresolvedName, buf *bytes.Buffer, _ parser.FmtFlags) bool {
+			idx, ok, err := bind(n)
+			if err != nil && bindErr == nil {
+				bindErr = err
+			}
+			if !ok {
+				return false
+			}
+			fmt.Fprintf(buf, "$%d", idx+1)
+			return true
+		})
+
+	body := &functionBody{stmts: make([]parser.Statement, len(stmts))}
+	for i, stmt := range stmts {
+		// The statements are rewritten so that the bound names become
+		// placeholders, and parsed again.
+		sql := parser.AsStringWithFlags(stmt, fmtFlags)
+		if bindErr != nil {
+			return nil, bindErr
+		}
+		if body.stmts[i], err = parser.ParseOne(sql); err != nil {
+			return nil, err
+		}
+	}
+	if p.functionBodies == nil {
+		p.functionBodies = make(map[string]*functionBody)
+	}
+	p.functionBodies[key] = body
+	return body, nil
+}
+
+// runFunctionBody runs the statements in the body of a user-defined function
+// in the transaction of the planner. The names bound by bind and the
+// placeholders $1, $2, ... refer to the given values. The result is the
+// first row returned by the last statement, or nil if it returns no rows.
+func (p *planner) runFunctionBody(
+	ctx context.Context,
+	fn *parser.UserDefinedFunction,
+	bind functionBinder,
+	types []parser.Type,
+	values parser.Datums,
+) (parser.Datums, error) {
+	if p.functionCallDepth >= maxFunctionCallDepth {
+		return nil, pgerror.NewErrorf(pgerror.CodeStatementTooComplexError,
+			"stack depth limit exceeded").SetHintf(
+			"Functions may be called recursively at most %d levels deep.", maxFunctionCallDepth)
+	}
+	body, err := p.prepareFunctionBody(fn, bind)
+	if err != nil {
+		return nil, err
+	}
+
+	var result parser.Datums
+	for _, stmt := range body.stmts {
+		np := p.session.newPlanner(nil, p.txn)
+		np.evalCtx = p.evalCtx
+		np.evalCtx.Planner = np
+		np.avoidCachedDescriptors = p.avoidCachedDescriptors
+		np.functionCallDepth = p.functionCallDepth + 1
+		// Functions called by the body share the cache of the statement.
+		np.functionBodies = p.functionBodies
+		for i, typ := range types {
+			name := fmt.Sprint(i + 1)
+			if err := np.semaCtx.Placeholders.SetType(name, typ); err != nil {
+				return nil, err
+			}
+			np.semaCtx.Placeholders.SetValue(name, values[i])
+		}
+
+		rows, err := np.queryStatement(ctx, stmt)
+		if err != nil {
+			return nil, err
+		}
+		result = nil
+		if len(rows) > 0 {
+			result = rows[0]
+		}
+	}
+	return result, nil
+}
+
+// queryStatement runs the given statement and returns the rows it produces.
+// Unlike queryRows, it uses the placeholder values already set up in the
+// planner.
+func (p *planner) queryStatement(ctx context.Context, stmt parser.Statement) ([]parser.Datums, error) {
+	plan, err := p.makePlan(ctx, Statement{AST: stmt})
+	if err != nil {
+		return nil, err
+	}
+	defer plan.Close(ctx)
+	if err := p.startPlan(ctx, plan); err != nil {
+		return nil, err
+	}
+	params := runParams{
+		ctx: ctx,
+		p:   p,
+	}
+	var rows []parser.Datums
+	if err := forEachRow(params, plan, func(values parser.Datums) error {
+		if values != nil {
+			rows = append(rows, append(parser.Datums(nil), values...))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
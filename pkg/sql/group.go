@@ -23,6 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/pkg/errors"
@@ -82,7 +83,13 @@ func (p *planner) groupBy(
 		return nil, nil, nil
 	}
 
-	groupByExprs := make([]parser.Expr, len(n.GroupBy))
+	// GROUPING SETS, ROLLUP and CUBE are expanded into a flat list of grouping
+	// expressions; each grouping set refers to a subset of that list.
+	groupByList, exprSets, err := expandGroupingSets(n.GroupBy)
+	if err != nil {
+		return nil, nil, err
+	}
+	groupByExprs := make([]parser.Expr, len(groupByList))
 
 	// In the construction of the renderNode, when renders are processed (via
 	// computeRender()), the expressions are normalized. In order to compare these
@@ -90,7 +97,7 @@ func (p *planner) groupBy(
 	// the GROUP BY expressions as well. This is done before determining if
 	// aggregation is being performed, because that determination is made during
 	// validation, which will require matching expressions.
-	for i, expr := range n.GroupBy {
+	for i, expr := range groupByList {
 		expr = parser.StripParens(expr)
 
 		// Check whether the GROUP BY clause refers to a rendered column
@@ -182,7 +189,9 @@ func (p *planner) groupBy(
 	// the aggregate function directly; there is no need to add a render. See
 	// extractAggregatesVisitor below.
 	groupStrs := make(groupByStrMap, len(groupByExprs))
-	for _, g := range groupByExprs {
+	// exprCols[i] contains the render columns of groupByExprs[i].
+	exprCols := make([][]int, len(groupByExprs))
+	for i, g := range groupByExprs {
 		cols, exprs, hasStar, err := p.computeRenderAllowingStars(
 			ctx, parser.SelectExpr{Expr: g}, parser.TypeAny, r.sourceInfo, r.ivarHelper,
 			autoGenerateRenderOutputName)
@@ -195,6 +204,7 @@ func (p *planner) groupBy(
 		cols, exprs = flattenTuples(cols, exprs)
 
 		colIdxs := r.addOrReuseRenders(cols, exprs, true /* reuseExistingRender */)
+		exprCols[i] = colIdxs
 		if len(colIdxs) == 1 {
 			// We only remember the render if there is a 1:1 correspondence with
 			// the expression written after GROUP BY and the computed renders.
@@ -209,6 +219,7 @@ func (p *planner) groupBy(
 		}
 	}
 	group.numGroupCols = len(r.render)
+	group.setGroupingSets(exprSets, exprCols)
 
 	var havingNode *filterNode
 	plan := planNode(group)
@@ -287,6 +298,7 @@ func (p *planner) groupBy(
 	group.addNullBucketIfEmpty = len(groupByExprs) == 0
 
 	group.buckets = make(map[string]struct{})
+	group.initGroupingFuncs()
 
 	if log.V(2) {
 		strs := make([]string, 0, len(group.funcs))
@@ -301,6 +313,132 @@ func (p *planner) groupBy(
 	return plan, group, nil
 }
 
+// maxGroupingSets is the maximum number of grouping sets a GROUP BY clause can
+// expand to.
+const maxGroupingSets = 4096
+
+// maxCubeElems is the maximum number of elements of a CUBE.
+const maxCubeElems = 12
+
+// expandGroupingSets flattens a GROUP BY clause containing GROUPING SETS,
+// ROLLUP or CUBE elements. It returns the grouping expressions and the
+// grouping sets, each of which is a list of indices into the expressions. If
+// there are no such elements, the expressions are those of the clause and the
+// returned grouping sets are nil.
+func expandGroupingSets(groupBy parser.GroupBy) ([]parser.Expr, [][]int, error) {
+	hasSets := false
+	for _, e := range groupBy {
+		if _, ok := e.(*parser.GroupingSet); ok || isEmptyGroupingSet(e) {
+			hasSets = true
+			break
+		}
+	}
+	if !hasSets {
+		return groupBy, nil, nil
+	}
+
+	var exprs []parser.Expr
+	// The grouping sets of the clause are the cross product of the grouping
+	// sets of its elements.
+	sets := [][]int{nil}
+	for _, e := range groupBy {
+		elemSets, err := expandGroupingSetElem(e, &exprs)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(sets)*len(elemSets) > maxGroupingSets {
+			return nil, nil, errTooManyGroupingSets
+		}
+		product := make([][]int, 0, len(sets)*len(elemSets))
+		for _, s := range sets {
+			for _, elemSet := range elemSets {
+				product = append(product, append(append([]int(nil), s...), elemSet...))
+			}
+		}
+		sets = product
+	}
+	return exprs, sets, nil
+}
+
+var errTooManyGroupingSets = pgerror.NewErrorf(pgerror.CodeProgramLimitExceededError,
+	"too many grouping sets present (maximum %d)", maxGroupingSets)
+
+// isEmptyGroupingSet returns true if the expression is the empty grouping set
+// "()".
+func isEmptyGroupingSet(e parser.Expr) bool {
+	t, ok := e.(*parser.Tuple)
+	return ok && len(t.Exprs) == 0
+}
+
+// expandGroupingSetElem returns the grouping sets denoted by an element of a
+// GROUP BY clause or of GROUPING SETS. The expressions of the element are
+// appended to exprs and the grouping sets refer to them by index.
+func expandGroupingSetElem(e parser.Expr, exprs *[]parser.Expr) ([][]int, error) {
+	addExpr := func(e parser.Expr) int {
+		*exprs = append(*exprs, e)
+		return len(*exprs) - 1
+	}
+
+	gs, ok := e.(*parser.GroupingSet)
+	if !ok {
+		if isEmptyGroupingSet(e) {
+			return [][]int{nil}, nil
+		}
+		// A plain expression (possibly a parenthesized list of expressions) is
+		// a single grouping set.
+		return [][]int{{addExpr(e)}}, nil
+	}
+
+	var sets [][]int
+	switch gs.Type {
+	case parser.GroupingSets:
+		for _, elem := range gs.Exprs {
+			elemSets, err := expandGroupingSetElem(elem, exprs)
+			if err != nil {
+				return nil, err
+			}
+			sets = append(sets, elemSets...)
+			if len(sets) > maxGroupingSets {
+				return nil, errTooManyGroupingSets
+			}
+		}
+
+	case parser.Rollup:
+		// ROLLUP (a, b, c) is GROUPING SETS ((a, b, c), (a, b), (a), ()).
+		elems := make([]int, len(gs.Exprs))
+		for i, elem := range gs.Exprs {
+			elems[i] = addExpr(elem)
+		}
+		for i := len(elems); i >= 0; i-- {
+			sets = append(sets, elems[:i:i])
+		}
+
+	case parser.Cube:
+		// CUBE (a, b) is GROUPING SETS ((a, b), (a), (b), ()).
+		if len(gs.Exprs) > maxCubeElems {
+			return nil, pgerror.NewErrorf(pgerror.CodeProgramLimitExceededError,
+				"CUBE is limited to %d elements", maxCubeElems)
+		}
+		elems := make([]int, len(gs.Exprs))
+		for i, elem := range gs.Exprs {
+			elems[i] = addExpr(elem)
+		}
+		for mask := 1<<uint(len(elems)) - 1; mask >= 0; mask-- {
+			var set []int
+			for i, elem := range elems {
+				if mask&(1<<uint(len(elems)-1-i)) != 0 {
+					set = append(set, elem)
+				}
+			}
+			sets = append(sets, set)
+		}
+
+	default:
+		panic(fmt.Sprintf("unknown grouping set type %d", gs.Type))
+	}
+	return sets, nil
+}
+
 // A groupNode implements the planNode interface and handles the grouping logic.
 // It "wraps" a planNode which is used to retrieve the ungrouped results.
 type groupNode struct {
@@ -313,6 +451,12 @@ type groupNode struct {
 	// the source plan.
 	numGroupCols int
 
+	// groupingSets is set if the GROUP BY uses GROUPING SETS, ROLLUP or CUBE.
+	// Each set contains a subset of the group-by columns. Every input row is
+	// aggregated once for each grouping set; the group-by columns that are not
+	// in a set are NULL in the groups of that set.
+	groupingSets []util.FastIntSet
+
 	// funcs are the aggregation functions that the renders use.
 	funcs []*aggregateFuncHolder
 	// The set of bucket keys. We add buckets as we are processing input rows, and
@@ -370,33 +514,21 @@ func (n *groupNode) Next(params runParams) (bool, error) {
 
 		// TODO(dt): optimization: skip buckets when underlying plan is ordered by grouped values.
 
-		bucket := scratch
-		for idx := 0; idx < n.numGroupCols; idx++ {
-			var err error
-			bucket, err = sqlbase.EncodeDatum(bucket, values[idx])
+		if n.groupingSets == nil {
+			bucket, err := n.addRow(params.ctx, values, -1 /* setIdx */, scratch)
 			if err != nil {
 				return false, err
 			}
-		}
-
-		n.buckets[string(bucket)] = struct{}{}
-
-		// Feed the aggregateFuncHolders for this bucket the non-grouped values.
-		for _, f := range n.funcs {
-			if f.hasFilter && values[f.filterRenderIdx] != parser.DBoolTrue {
-				continue
-			}
-
-			var value parser.Datum
-			if f.argRenderIdx != noRenderIdx {
-				value = values[f.argRenderIdx]
-			}
-
-			if err := f.add(params.ctx, n.planner.session, bucket, value); err != nil {
-				return false, err
+			scratch = bucket[:0]
+		} else {
+			for setIdx := range n.groupingSets {
+				bucket, err := n.addRow(params.ctx, values, setIdx, scratch)
+				if err != nil {
+					return false, err
+				}
+				scratch = bucket[:0]
 			}
 		}
-		scratch = bucket[:0]
 
 		n.gotOneRow = true
 	}
@@ -427,15 +559,140 @@ func (n *groupNode) Next(params runParams) (bool, error) {
 	return true, nil
 }
 
+// addRow adds a row of the source to its bucket and feeds the
+// aggregateFuncHolders for that bucket. If the GROUP BY has grouping sets,
+// setIdx is the grouping set for which the row is aggregated; otherwise it is
+// -1. The bucket key is built in scratch and returned.
+func (n *groupNode) addRow(
+	ctx context.Context, values parser.Datums, setIdx int, scratch []byte,
+) ([]byte, error) {
+	bucket := scratch
+	var set util.FastIntSet
+	if setIdx >= 0 {
+		set = n.groupingSets[setIdx]
+		bucket = encoding.EncodeUvarintAscending(bucket, uint64(setIdx))
+	}
+	for idx := 0; idx < n.numGroupCols; idx++ {
+		if setIdx >= 0 && !set.Contains(uint32(idx)) {
+			continue
+		}
+		var err error
+		bucket, err = sqlbase.EncodeDatum(bucket, values[idx])
+		if err != nil {
+			return bucket, err
+		}
+	}
+
+	n.buckets[string(bucket)] = struct{}{}
+
+	// Feed the aggregateFuncHolders for this bucket the non-grouped values.
+	for _, f := range n.funcs {
+		if f.hasFilter && values[f.filterRenderIdx] != parser.DBoolTrue {
+			continue
+		}
+
+		var value parser.Datum
+		switch {
+		case f.groupingValues != nil:
+			value = f.groupingValues[0]
+			if setIdx >= 0 {
+				value = f.groupingValues[setIdx]
+			}
+		case f.argRenderIdx != noRenderIdx:
+			value = values[f.argRenderIdx]
+			if f.identAggregate && setIdx >= 0 && !set.Contains(uint32(f.argRenderIdx)) {
+				// The column is not part of the grouping set.
+				value = parser.DNull
+			}
+		}
+
+		if err := f.add(ctx, n.planner.session, bucket, value); err != nil {
+			return bucket, err
+		}
+	}
+	return bucket, nil
+}
+
+// setGroupingSets initializes the grouping sets of the groupNode from the
+// grouping sets returned by expandGroupingSets; exprCols contains the group-by
+// columns of each grouping expression.
+func (n *groupNode) setGroupingSets(exprSets [][]int, exprCols [][]int) {
+	if len(exprSets) <= 1 {
+		// A single grouping set contains all the grouping expressions.
+		return
+	}
+	n.groupingSets = make([]util.FastIntSet, len(exprSets))
+	for i, s := range exprSets {
+		for _, e := range s {
+			for _, c := range exprCols[e] {
+				n.groupingSets[i].Add(uint32(c))
+			}
+		}
+	}
+}
+
+// initGroupingFuncs computes the results of the GROUPING() functions for each
+// grouping set. The result has a bit for each argument, which is set if the
+// argument is not part of the grouping set; the last argument corresponds to
+// the least significant bit.
+func (n *groupNode) initGroupingFuncs() {
+	for _, f := range n.funcs {
+		if f.groupingCols == nil {
+			continue
+		}
+		numSets := len(n.groupingSets)
+		if numSets == 0 {
+			numSets = 1
+		}
+		f.groupingValues = make([]parser.Datum, numSets)
+		for i := range f.groupingValues {
+			var mask parser.DInt
+			for _, c := range f.groupingCols {
+				mask <<= 1
+				if n.groupingSets != nil && !n.groupingSets[i].Contains(uint32(c)) {
+					mask |= 1
+				}
+			}
+			f.groupingValues[i] = parser.NewDInt(mask)
+		}
+	}
+}
+
+// inAllGroupingSets returns true if the given group-by column is part of all
+// the grouping sets.
+func (n *groupNode) inAllGroupingSets(colIdx int) bool {
+	for _, s := range n.groupingSets {
+		if !s.Contains(uint32(colIdx)) {
+			return false
+		}
+	}
+	return true
+}
+
 // setupOutput runs once after all the input rows have been processed. It sets
 // up the necessary state to start iterating through the buckets in Next().
 func (n *groupNode) setupOutput() {
 	if len(n.buckets) < 1 && n.addNullBucketIfEmpty {
 		n.buckets[""] = struct{}{}
 	}
+	// Like a GROUP BY without grouping columns, an empty grouping set
+	// produces a group even if there were no input rows.
+	for i, s := range n.groupingSets {
+		if s.Empty() {
+			n.buckets[string(encoding.EncodeUvarintAscending(nil, uint64(i)))] = struct{}{}
+		}
+	}
 	n.values = make(parser.Datums, len(n.funcs))
 }
 
+func (n *groupNode) memUsage() int64 {
+	var usage int64
+	for _, f := range n.funcs {
+		usage += f.bucketsMemAcc.CurrentlyAllocated()
+	}
+	return usage
+}
+
 func (n *groupNode) Close(ctx context.Context) {
 	n.plan.Close(ctx)
 	for _, f := range n.funcs {
@@ -535,7 +792,9 @@ func (v *extractAggregatesVisitor) addAggregation(f *aggregateFuncHolder) *parse
 	// We care about the name of the groupNode columns as an optimization: we want
 	// them to match the post-render node's columns if the post-render expressions
 	// are trivial (so the renderNode can be elided).
-	colName, err := getRenderColName(v.planner.session.SearchPath, parser.SelectExpr{Expr: f.expr})
+	colName, err := getRenderColName(
+		v.planner.session.SearchPath, v.planner, parser.SelectExpr{Expr: f.expr},
+	)
 	if err != nil {
 		colName = fmt.Sprintf("agg%d", renderIdx)
 	} else if strings.ToLower(colName) == "count_rows()" {
@@ -552,6 +811,31 @@ func (v *extractAggregatesVisitor) addAggregation(f *aggregateFuncHolder) *parse
 	return v.ivarHelper.IndexedVar(renderIdx)
 }
 
+// addGroupingFunc adds an aggregateFuncHolder for a GROUPING() function to the
+// groupNode funcs and returns an IndexedVar that refers to it.
+func (v *extractAggregatesVisitor) addGroupingFunc(t *parser.FuncExpr) parser.Expr {
+	if len(t.Exprs) > 31 {
+		v.err = pgerror.NewErrorf(pgerror.CodeTooManyArgumentsError,
+			"GROUPING must have fewer than 32 arguments")
+		return t
+	}
+	cols := make([]int, len(t.Exprs))
+	for i, e := range t.Exprs {
+		groupIdx, ok := v.groupStrs[symbolicExprStr(e)]
+		if !ok || groupIdx < 0 {
+			v.err = pgerror.NewErrorf(pgerror.CodeGroupingError,
+				"arguments to GROUPING must be grouping expressions of the associated query level")
+			return t
+		}
+		cols[i] = groupIdx
+	}
+	f := v.groupNode.newAggregateFuncHolder(
+		t, noRenderIdx, false /* not ident */, t.GetAggregateConstructor(),
+	)
+	f.groupingCols = cols
+	return v.addAggregation(f)
+}
+
 func (v *extractAggregatesVisitor) VisitPre(expr parser.Expr) (recurse bool, newExpr parser.Expr) {
 	if v.err != nil {
 		return false, expr
@@ -569,6 +853,9 @@ func (v *extractAggregatesVisitor) VisitPre(expr parser.Expr) (recurse bool, new
 
 	switch t := expr.(type) {
 	case *parser.FuncExpr:
+		if t.IsGroupingFunc() {
+			return false, v.addGroupingFunc(t)
+		}
 		if agg := t.GetAggregateConstructor(); agg != nil {
 			var f *aggregateFuncHolder
 			switch len(t.Exprs) {
@@ -673,6 +960,13 @@ type aggregateFuncHolder struct {
 
 	identAggregate bool
 
+	// groupingCols is set for GROUPING() functions; it contains the group-by
+	// columns that are the arguments of the function. groupingValues contains
+	// the result of the function for each grouping set (or a single result if
+	// there are no grouping sets).
+	groupingCols   []int
+	groupingValues []parser.Datum
+
 	create        func(*parser.EvalContext) parser.AggregateFunc
 	group         *groupNode
 	buckets       map[string]parser.AggregateFunc
@@ -78,3 +78,45 @@ func TestDesiredAggregateOrder(t *testing.T) {
 		}
 	}
 }
+
+func TestExpandGroupingSets(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testData := []struct {
+		groupBy string
+		exprs   []string
+		sets    [][]int
+	}{
+		{`a, b`, []string{`a`, `b`}, nil},
+		{`()`, nil, [][]int{nil}},
+		{`ROLLUP (a, (b, c))`, []string{`a`, `(b, c)`}, [][]int{{0, 1}, {0}, nil}},
+		{`CUBE (a, b)`, []string{`a`, `b`}, [][]int{{0, 1}, {0}, {1}, nil}},
+		{`GROUPING SETS (a, (b, c), ())`, []string{`a`, `(b, c)`}, [][]int{{0}, {1}, nil}},
+		{`a, ROLLUP (b)`, []string{`a`, `b`}, [][]int{{0, 1}, {0}}},
+		{`GROUPING SETS (a, ROLLUP (b)), c`, []string{`a`, `b`, `c`},
+			[][]int{{0, 2}, {1, 2}, {2}}},
+	}
+	for _, d := range testData {
+		t.Run(d.groupBy, func(t *testing.T) {
+			stmt, err := parser.ParseOne("SELECT 1 FROM t GROUP BY " + d.groupBy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			groupBy := stmt.(*parser.Select).Select.(*parser.SelectClause).GroupBy
+			exprs, sets, err := expandGroupingSets(groupBy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var exprStrs []string
+			for _, e := range exprs {
+				exprStrs = append(exprStrs, e.String())
+			}
+			if !reflect.DeepEqual(exprStrs, d.exprs) {
+				t.Errorf("expected expressions %v, got %v", d.exprs, exprStrs)
+			}
+			if !reflect.DeepEqual(sets, d.sets) {
+				t.Errorf("expected grouping sets %v, got %v", d.sets, sets)
+			}
+		})
+	}
+}
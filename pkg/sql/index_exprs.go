@@ -0,0 +1,237 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// indexExprColumnName is the name of the hidden columns storing the values of
+// indexed expressions. A number is appended to it when it is already in use.
+const indexExprColumnName = "crdb_internal_idx_expr"
+
+// hasIndexExprs returns whether some of the elements of an index are
+// expressions.
+func hasIndexExprs(elems parser.IndexElemList) bool {
+	for _, elem := range elems {
+		if elem.Expr != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// fillIndexColumns sets the columns and directions of idx, an index of desc,
+// from elems. Each expression among elems is indexed through a new hidden
+// column storing its values; these columns are returned and must be added to
+// desc by the caller, along with the index.
+func fillIndexColumns(
+	desc *sqlbase.TableDescriptor,
+	idx *sqlbase.IndexDescriptor,
+	elems parser.IndexElemList,
+	searchPath parser.SearchPath,
+) ([]sqlbase.ColumnDescriptor, error) {
+	var exprCols []sqlbase.ColumnDescriptor
+	var exprs []string
+	resolved := make(parser.IndexElemList, len(elems))
+	for i, elem := range elems {
+		resolved[i] = elem
+		if elem.Expr == nil {
+			continue
+		}
+		if idx.Type == sqlbase.IndexDescriptor_INVERTED {
+			return nil, pgerror.Unimplemented("inverted index expression",
+				"inverted indexes on expressions are not supported")
+		}
+		expr, err := serializeIndexExpr(desc, elem.Expr, searchPath)
+		if err != nil {
+			return nil, err
+		}
+		typedExpr, err := sqlbase.TypeCheckIndexExpr(desc, expr)
+		if err != nil {
+			return nil, err
+		}
+		colType, err := sqlbase.DatumTypeToColumnType(typedExpr.ResolvedType())
+		if err != nil {
+			return nil, err
+		}
+		col := sqlbase.ColumnDescriptor{
+			Name:     makeIndexExprColumnName(desc, exprCols),
+			Type:     colType,
+			Nullable: true,
+			Hidden:   true,
+		}
+		exprCols = append(exprCols, col)
+		if exprs == nil {
+			exprs = make([]string, len(elems))
+		}
+		exprs[i] = expr
+		resolved[i] = parser.IndexElem{Column: parser.Name(col.Name), Direction: elem.Direction}
+	}
+	if err := idx.FillColumns(resolved); err != nil {
+		return nil, err
+	}
+	idx.Expressions = exprs
+	return exprCols, nil
+}
+
+// makeIndexExprColumnName returns a name for the column storing an indexed
+// expression that is used neither by the columns of desc nor by the ones in
+// pending.
+func makeIndexExprColumnName(
+	desc *sqlbase.TableDescriptor, pending []sqlbase.ColumnDescriptor,
+) string {
+	inUse := func(name string) bool {
+		if _, _, err := desc.FindColumnByName(parser.Name(name)); err == nil {
+			return true
+		}
+		for _, col := range pending {
+			if col.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+	name := indexExprColumnName
+	for i := 1; inUse(name); i++ {
+		name = fmt.Sprintf("%s_%d", indexExprColumnName, i)
+	}
+	return name
+}
+
+// serializeIndexExpr returns the serialized form of an indexed expression,
+// where the columns of desc are referenced by ID (@1 being the column with ID
+// 1) so that the expression is not affected by renames or by the removal of
+// other columns.
+func serializeIndexExpr(
+	desc *sqlbase.TableDescriptor, expr parser.Expr, searchPath parser.SearchPath,
+) (string, error) {
+	expr, err := parser.SimpleVisit(expr, func(expr parser.Expr) (error, bool, parser.Expr) {
+		switch e := expr.(type) {
+		case *parser.Subquery:
+			return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"subqueries are not allowed in index expressions"), false, nil
+		case parser.VarName:
+			v, err := e.NormalizeVarName()
+			if err != nil {
+				return err, false, nil
+			}
+			c, ok := v.(*parser.ColumnItem)
+			if !ok {
+				return pgerror.NewErrorf(pgerror.CodeSyntaxError,
+					"%s is not allowed in index expressions", v), false, nil
+			}
+			if c.TableName.TableName != "" && string(c.TableName.TableName) != desc.Name {
+				return pgerror.NewErrorf(pgerror.CodeUndefinedColumnError,
+					"column %q does not exist", c), false, nil
+			}
+			col, err := desc.FindActiveColumnByName(string(c.ColumnName))
+			if err != nil || desc.IsIndexExprColumn(col.ID) {
+				return pgerror.NewErrorf(pgerror.CodeUndefinedColumnError,
+					"column %q does not exist", c.ColumnName), false, nil
+			}
+			return nil, false, parser.NewOrdinalReference(int(col.ID) - 1)
+		}
+		return nil, true, expr
+	})
+	if err != nil {
+		return "", err
+	}
+	var p parser.Parser
+	if err := p.AssertNoAggregationOrWindowing(expr, "index expressions", searchPath); err != nil {
+		return "", err
+	}
+	return parser.Serialize(expr), nil
+}
+
+// replaceIndexedExprs replaces the sub-expressions of the filter of the scan
+// that are indexed by an index of the table with the hidden columns storing
+// their values. This lets index selection constrain these indexes using the
+// conditions on the indexed expressions.
+func (p *planner) replaceIndexedExprs(s *scanNode) error {
+	var exprCols map[string]int
+	for _, idx := range s.desc.Indexes {
+		for i, expr := range idx.Expressions {
+			if expr == "" {
+				continue
+			}
+			colIdx, ok := s.colIdxMap[idx.ColumnIDs[i]]
+			if !ok {
+				continue
+			}
+			scanExpr, err := p.makeScanIndexExpr(s, expr)
+			if err != nil {
+				return err
+			}
+			if exprCols == nil {
+				exprCols = make(map[string]int)
+			}
+			exprCols[parser.AsStringWithFlags(scanExpr, parser.FmtCheckEquivalence)] = colIdx
+		}
+	}
+	if exprCols == nil {
+		return nil
+	}
+	filter, err := parser.SimpleVisit(s.filter, func(expr parser.Expr) (error, bool, parser.Expr) {
+		typedExpr, ok := expr.(parser.TypedExpr)
+		if !ok {
+			return nil, true, expr
+		}
+		if colIdx, ok := exprCols[parser.AsStringWithFlags(typedExpr, parser.FmtCheckEquivalence)]; ok {
+			return nil, false, s.filterVars.IndexedVar(colIdx)
+		}
+		return nil, true, expr
+	})
+	if err != nil {
+		return err
+	}
+	s.filter = filter.(parser.TypedExpr)
+	return nil
+}
+
+// makeScanIndexExpr returns the serialized index expression expr in terms of
+// the columns of the scan, normalized like the filter of the scan is. The
+// expression is only used to be compared to the filter, so its variables are
+// not bound to the filter's helper.
+func (p *planner) makeScanIndexExpr(s *scanNode, exprStr string) (parser.TypedExpr, error) {
+	expr, err := parser.ParseExpr(exprStr)
+	if err != nil {
+		return nil, err
+	}
+	ivarHelper := parser.MakeIndexedVarHelper(s, len(s.cols))
+	expr, err = parser.SimpleVisit(expr, func(expr parser.Expr) (error, bool, parser.Expr) {
+		if ivar, ok := expr.(*parser.IndexedVar); ok {
+			colIdx, ok := s.colIdxMap[sqlbase.ColumnID(ivar.Idx+1)]
+			if !ok {
+				return pgerror.NewErrorf(pgerror.CodeInternalError,
+					"column %d referenced by an index expression is not scanned", ivar.Idx+1), false, expr
+			}
+			return nil, false, ivarHelper.IndexedVar(colIdx)
+		}
+		return nil, true, expr
+	})
+	if err != nil {
+		return nil, err
+	}
+	typedExpr, err := parser.TypeCheck(expr, nil, parser.TypeAny)
+	if err != nil {
+		return nil, err
+	}
+	return p.evalCtx.NormalizeExpr(typedExpr)
+}
@@ -16,6 +16,7 @@ package sql
 
 import (
 	"fmt"
+	"sort"
 
 	"golang.org/x/net/context"
 
@@ -104,6 +105,18 @@ type indexJoinNode struct {
 	// may produce more values than this, e.g. when its filter expression
 	// uses more columns than the PK.
 	primaryKeyColumns []bool
+
+	// intersectSpans is set when the index scanNode scans several spans of
+	// an inverted index, each of which contains an entry for every row
+	// matching the filter. Only the primary keys found in all the spans are
+	// then looked up in the table.
+	intersectSpans bool
+	// intersection holds the primary keys found in all the spans which
+	// remain to be looked up, in order. It is populated on the first call
+	// to Next and accounted for by intersectAcc.
+	intersection      []roachpb.Key
+	intersectionReady bool
+	intersectAcc      WrappableMemoryAccount
 }
 
 // makeIndexJoin build an index join node.
@@ -157,6 +170,11 @@ func (p *planner) makeIndexJoin(
 	// refers to any additional column, we also need to prepare the
 	// mapping for these columns in colIDtoRowIndex.
 	for _, colID := range indexScan.index.ColumnIDs {
+		if indexScan.index.Type == sqlbase.IndexDescriptor_INVERTED {
+			// The indexed column of an inverted index can't be decoded from
+			// its keys, so it is never provided by the index scanNode.
+			break
+		}
 		idx, ok := indexScan.colIdxMap[colID]
 		if !ok {
 			panic(fmt.Sprintf("Unknown column %d in index!", colID))
@@ -217,6 +235,12 @@ func (n *indexJoinNode) Start(params runParams) error {
 }
 
 func (n *indexJoinNode) Next(params runParams) (bool, error) {
+	if n.intersectSpans && !n.intersectionReady {
+		if err := n.intersectIndexSpans(params); err != nil {
+			return false, err
+		}
+	}
+
 	// Loop looking up the next row. We either are going to pull a row from the
 	// table or a batch of rows from the index. If we pull a batch of rows from
 	// the index we perform another iteration of the loop looking for rows in the
@@ -239,7 +263,8 @@ func (n *indexJoinNode) Next(params runParams) (bool, error) {
 		n.table.spans = n.table.spans[:0]
 
 		for len(n.table.spans) < indexJoinBatchSize {
-			if next, err := n.index.Next(params); !next {
+			key, next, err := n.nextPrimaryKey(params)
+			if !next {
 				// The index is out of rows or an error occurred.
 				if err != nil {
 					return false, err
@@ -250,14 +275,6 @@ func (n *indexJoinNode) Next(params runParams) (bool, error) {
 				}
 				break
 			}
-
-			vals := n.index.Values()
-			primaryIndexKey, _, err := sqlbase.EncodeIndexKey(
-				n.table.desc, n.table.index, n.colIDtoRowIndex, vals, n.primaryKeyPrefix)
-			if err != nil {
-				return false, err
-			}
-			key := roachpb.Key(primaryIndexKey)
 			n.table.spans = append(n.table.spans, roachpb.Span{
 				Key:    key,
 				EndKey: key.PrefixEnd(),
@@ -271,7 +288,99 @@ func (n *indexJoinNode) Next(params runParams) (bool, error) {
 	return false, nil
 }
 
+// nextPrimaryKey returns the primary key of the next row to look up in the
+// table, or false if there is none left.
+func (n *indexJoinNode) nextPrimaryKey(params runParams) (roachpb.Key, bool, error) {
+	if n.intersectSpans {
+		if len(n.intersection) == 0 {
+			return nil, false, nil
+		}
+		key := n.intersection[0]
+		n.intersection = n.intersection[1:]
+		return key, true, nil
+	}
+	if next, err := n.index.Next(params); !next {
+		return nil, false, err
+	}
+	key, err := n.encodePrimaryKey()
+	return key, err == nil, err
+}
+
+// encodePrimaryKey returns the primary key of the current row of the index
+// scanNode.
+func (n *indexJoinNode) encodePrimaryKey() (roachpb.Key, error) {
+	primaryIndexKey, _, err := sqlbase.EncodeIndexKey(
+		n.table.desc, n.table.index, n.colIDtoRowIndex, n.index.Values(), n.primaryKeyPrefix)
+	return roachpb.Key(primaryIndexKey), err
+}
+
+// intersectIndexSpans scans the spans of the index scanNode one at a time,
+// and collects the primary keys of the rows found in all of them. Only the
+// keys found in the previous spans are retained while scanning a span, so
+// the memory used is bounded by the number of rows in the first one.
+func (n *indexJoinNode) intersectIndexSpans(params runParams) error {
+	acc := n.intersectAcc.Wtxn(params.p.session)
+	spans := n.index.spans
+	var keys map[string]struct{}
+	var keysSize int64
+	for i := range spans {
+		n.index.spans = spans[i : i+1]
+		n.index.scanInitialized = false
+		found := make(map[string]struct{}, len(keys))
+		var foundSize int64
+		for {
+			next, err := n.index.Next(params)
+			if err != nil {
+				return err
+			}
+			if !next {
+				break
+			}
+			key, err := n.encodePrimaryKey()
+			if err != nil {
+				return err
+			}
+			sKey := string(key)
+			if _, ok := keys[sKey]; i > 0 && !ok {
+				continue
+			}
+			if _, ok := found[sKey]; ok {
+				continue
+			}
+			if err := acc.Grow(params.ctx, int64(len(sKey))); err != nil {
+				return err
+			}
+			found[sKey] = struct{}{}
+			foundSize += int64(len(sKey))
+		}
+		if err := acc.ResizeItem(params.ctx, keysSize+foundSize, foundSize); err != nil {
+			return err
+		}
+		keys, keysSize = found, foundSize
+		if len(keys) == 0 {
+			break
+		}
+	}
+
+	n.intersection = make([]roachpb.Key, 0, len(keys))
+	for key := range keys {
+		n.intersection = append(n.intersection, roachpb.Key(key))
+	}
+	sort.Slice(n.intersection, func(i, j int) bool {
+		return n.intersection[i].Compare(n.intersection[j]) < 0
+	})
+	n.intersectionReady = true
+	return nil
+}
+
+func (n *indexJoinNode) memUsage() int64 {
+	return n.intersectAcc.CurrentlyAllocated()
+}
+
 func (n *indexJoinNode) Close(ctx context.Context) {
+	if n.intersectSpans {
+		n.intersectAcc.Wtxn(n.index.p.session).Close(ctx)
+	}
 	n.index.Close(ctx)
 	n.table.Close(ctx)
 }
@@ -17,6 +17,7 @@ package sql
 import (
 	"bytes"
 	"fmt"
+	"math"
 	"sort"
 
 	"golang.org/x/net/context"
@@ -24,7 +25,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/stats"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/pkg/errors"
 )
@@ -96,9 +99,25 @@ func (p *planner) selectIndex(
 		if err != nil {
 			return nil, errors.Wrapf(err, "table ID = %d, index ID = %d", s.desc.ID, s.index.ID)
 		}
+		s.estimate = p.estimateIndexScan(s, s.index, nil, p.getTableStatistics(ctx, s.desc))
 		return s, nil
 	}
 
+	if s.specifiedIndex != nil && s.specifiedIndex.Type == sqlbase.IndexDescriptor_INVERTED {
+		// An explicit inverted index was requested. It can only be used to
+		// scan the entries of the documents matching a containment filter.
+		if s.noIndexJoin {
+			return nil, fmt.Errorf("index \"%s\" is not covering and NO_INDEX_JOIN was specified",
+				s.specifiedIndex.Name)
+		}
+		spans, ok := invertedIndexSpans(s, s.specifiedIndex)
+		if !ok {
+			return nil, fmt.Errorf("index \"%s\" is inverted and cannot be used for this query",
+				s.specifiedIndex.Name)
+		}
+		return p.useInvertedIndex(ctx, s, s.specifiedIndex, spans), nil
+	}
+
 	candidates := make([]*indexInfo, 0, len(s.desc.Indexes)+1)
 	if s.specifiedIndex != nil {
 		// An explicit secondary index was requested. Only add it to the candidate
@@ -113,6 +132,11 @@ func (p *planner) selectIndex(
 			index: &s.desc.PrimaryIndex,
 		})
 		for i := range s.desc.Indexes {
+			if s.desc.Indexes[i].Type == sqlbase.IndexDescriptor_INVERTED {
+				// Inverted indexes are only considered below, when no other
+				// index can constrain the scan.
+				continue
+			}
 			candidates = append(candidates, &indexInfo{
 				desc:  s.desc,
 				index: &s.desc.Indexes[i],
@@ -123,8 +147,15 @@ func (p *planner) selectIndex(
 	for _, c := range candidates {
 		c.init(s)
 	}
+	tableStats := p.getTableStatistics(ctx, s.desc)
 
 	if s.filter != nil {
+		// Conditions on indexed expressions are conditions on the columns
+		// storing their values.
+		if err := p.replaceIndexedExprs(s); err != nil {
+			return nil, err
+		}
+
 		// Analyze the filter expression, simplifying it and splitting it up into
 		// possibly overlapping ranges.
 		exprs, equivalent := analyzeExpr(&p.evalCtx, s.filter)
@@ -165,6 +196,12 @@ func (p *planner) selectIndex(
 		for _, c := range candidates {
 			c.analyzeExprs(exprs)
 		}
+
+		if len(tableStats) > 0 {
+			for _, c := range candidates {
+				c.applyStatistics(&p.evalCtx, tableStats)
+			}
+		}
 	}
 
 	if s.noIndexJoin {
@@ -204,9 +241,23 @@ func (p *planner) selectIndex(
 		}
 	}
 
-	// After sorting, candidates[0] contains the best index. Copy its info into
-	// the scanNode.
+	// After sorting, candidates[0] contains the best index. If it would
+	// require a full scan, an inverted index which can restrict the scan to
+	// the documents matching a containment filter is used instead.
 	c := candidates[0]
+	if c.constraints == nil && s.specifiedIndex == nil && !s.noIndexJoin {
+		for i := range s.desc.Indexes {
+			index := &s.desc.Indexes[i]
+			if index.Type != sqlbase.IndexDescriptor_INVERTED {
+				continue
+			}
+			if spans, ok := invertedIndexSpans(s, index); ok {
+				return p.useInvertedIndex(ctx, s, index, spans), nil
+			}
+		}
+	}
+
+	// Copy the info of the best index into the scanNode.
 	s.index = c.index
 	s.specifiedIndex = nil
 	s.isSecondaryIndex = (c.index != &s.desc.PrimaryIndex)
@@ -239,6 +290,7 @@ func (p *planner) selectIndex(
 	}
 
 	s.reverse = c.reverse
+	s.estimate = p.estimateIndexScan(s, c.index, c.constraints, tableStats)
 
 	var plan planNode
 	if c.covering {
@@ -261,6 +313,83 @@ func (p *planner) selectIndex(
 	return plan, nil
 }
 
+// invertedIndexSpans looks for the conjuncts of the scanNode's filter of the
+// form `col @> <json>`, where col is the column indexed by the given inverted
+// index, and returns the spans of index entries which each contain an entry
+// for every row satisfying them: there is one span for every path of the
+// documents on the right-hand side. The filter still needs to be applied to
+// the rows found in all of these spans.
+func invertedIndexSpans(s *scanNode, index *sqlbase.IndexDescriptor) (roachpb.Spans, bool) {
+	if s.filter == nil {
+		return nil, false
+	}
+	prefix := sqlbase.MakeIndexKeyPrefix(s.desc, index.ID)
+	var keys [][]byte
+	var visit func(expr parser.TypedExpr)
+	visit = func(expr parser.TypedExpr) {
+		switch t := expr.(type) {
+		case *parser.AndExpr:
+			visit(t.TypedLeft())
+			visit(t.TypedRight())
+		case *parser.ComparisonExpr:
+			if t.Operator != parser.Contains {
+				return
+			}
+			ok, colIdx := getColVarIdx(t.Left)
+			if !ok || s.cols[colIdx].ID != index.ColumnIDs[0] {
+				return
+			}
+			d, ok := t.Right.(*parser.DJSON)
+			if !ok {
+				return
+			}
+			keys = append(keys, json.InvertedIndexSpanKeys(prefix, d.JSON)...)
+		}
+	}
+	visit(s.filter)
+	if len(keys) == 0 {
+		return nil, false
+	}
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+	spans := make(roachpb.Spans, 0, len(keys))
+	for i, key := range keys {
+		if i > 0 && bytes.Equal(key, keys[i-1]) {
+			continue
+		}
+		spans = append(spans, roachpb.Span{Key: key, EndKey: roachpb.Key(key).PrefixEnd()})
+	}
+	return spans, true
+}
+
+// useInvertedIndex configures the scanNode to scan the given spans of an
+// inverted index, and returns the index join which looks up the rows of the
+// entries found in all of them.
+func (p *planner) useInvertedIndex(
+	ctx context.Context, s *scanNode, index *sqlbase.IndexDescriptor, spans roachpb.Spans,
+) planNode {
+	s.index = index
+	s.specifiedIndex = nil
+	s.isSecondaryIndex = true
+	s.spans = spans
+	s.reverse = false
+	// Note: makeIndexJoin destroys s and returns a new index scan node.
+	plan, s := p.makeIndexJoin(s, 0 /* exactPrefix */)
+	if len(spans) > 1 {
+		// A row must have an entry in every span: the index join only looks
+		// up the primary keys found in all of them.
+		plan.intersectSpans = true
+		plan.intersectAcc = p.session.TxnState.OpenAccount()
+	}
+
+	if log.V(3) {
+		log.Infof(ctx, "%s: filter=%v", index.Name, s.filter)
+		for i, span := range s.spans {
+			log.Infof(ctx, "%s/%d: %s", index.Name, i, sqlbase.PrettySpan(span, 2))
+		}
+	}
+	return plan
+}
+
 type indexConstraint struct {
 	start *parser.ComparisonExpr
 	end   *parser.ComparisonExpr
@@ -340,25 +469,32 @@ type indexInfo struct {
 	covering    bool // Does the index cover the required IndexedVars?
 	reverse     bool
 	exactPrefix int
+	// rowCount is the estimated number of rows scanned in the index; it is
+	// only set when the table has statistics.
+	rowCount float64
 }
 
 func (v *indexInfo) init(s *scanNode) {
 	v.covering = v.isCoveringIndex(s)
+	v.cost = v.baseCost()
+}
 
-	// The base cost is the number of keys per row.
+// baseCost returns the cost of scanning one row of the index, which is the
+// number of keys per row.
+func (v *indexInfo) baseCost() float64 {
 	if v.index == &v.desc.PrimaryIndex {
 		// The primary index contains 1 key per column plus the sentinel key per
 		// row.
-		v.cost = float64(1 + len(v.desc.Columns) - len(v.desc.PrimaryIndex.ColumnIDs))
-	} else {
-		v.cost = 1
-		if !v.covering {
-			v.cost += float64(1 + len(v.desc.Columns) - len(v.desc.PrimaryIndex.ColumnIDs))
-			// Non-covering indexes are significantly more expensive than covering
-			// indexes.
-			v.cost *= nonCoveringIndexPenalty
-		}
+		return float64(1 + len(v.desc.Columns) - len(v.desc.PrimaryIndex.ColumnIDs))
+	}
+	cost := 1.0
+	if !v.covering {
+		cost += float64(1 + len(v.desc.Columns) - len(v.desc.PrimaryIndex.ColumnIDs))
+		// Non-covering indexes are significantly more expensive than covering
+		// indexes.
+		cost *= nonCoveringIndexPenalty
 	}
+	return cost
 }
 
 // analyzeExprs examines the range map to determine the cost of using the
@@ -398,6 +534,35 @@ func (v *indexInfo) analyzeExprs(exprs []parser.TypedExprs) {
 	}
 }
 
+// applyStatistics replaces the heuristic cost of the index computed by
+// analyzeExprs, which only considers how many of the index columns are
+// constrained, with a cost proportional to the number of rows scanned, as
+// estimated from the table statistics.
+func (v *indexInfo) applyStatistics(
+	evalCtx *parser.EvalContext, tableStats []*stats.TableStatistic,
+) {
+	v.rowCount = float64(tableStats[0].RowCount) *
+		constraintsSelectivity(evalCtx, v.desc, v.index, v.constraints, tableStats)
+	v.cost = v.baseCost() * math.Max(v.rowCount, 1)
+}
+
+// getTableStatistics returns the statistics of the given table, or nil if
+// there are none or they cannot be retrieved.
+func (p *planner) getTableStatistics(
+	ctx context.Context, desc *sqlbase.TableDescriptor,
+) []*stats.TableStatistic {
+	execCfg := p.ExecCfg()
+	if execCfg == nil || execCfg.TableStatsCache == nil || desc.IsVirtualTable() {
+		return nil
+	}
+	tableStats, err := execCfg.TableStatsCache.GetTableStats(ctx, desc.ID)
+	if err != nil {
+		log.VEventf(ctx, 1, "could not retrieve statistics for table %d: %v", desc.ID, err)
+		return nil
+	}
+	return tableStats
+}
+
 // analyzeOrdering analyzes the ordering provided by the index and determines
 // if it matches the ordering requested by the query. Non-matching orderings
 // increase the cost of using the index.
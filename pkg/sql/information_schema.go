@@ -346,8 +346,8 @@ CREATE TABLE information_schema.statistics (
 					parser.DNull,                                  // collation
 					parser.DNull,                                  // cardinality
 					direction,                                     // direction
-					parser.MakeDBool(parser.DBool(isStored)),   // storing
-					parser.MakeDBool(parser.DBool(isImplicit)), // implicit
+					parser.MakeDBool(parser.DBool(isStored)),      // storing
+					parser.MakeDBool(parser.DBool(isImplicit)),    // implicit
 				)
 			}
 
@@ -373,9 +373,15 @@ CREATE TABLE information_schema.statistics (
 
 				sequence := 1
 				for i, col := range index.ColumnNames {
-					// We add a row for each column of index.
+					// We add a row for each column of index. Indexed
+					// expressions are shown in place of the hidden columns
+					// storing their values.
 					dir := dStringForIndexDirection(index.ColumnDirections[i])
-					if err := appendRow(index, col, sequence, dir, false, false); err != nil {
+					colName := col
+					if expr := index.Expression(i); expr != "" {
+						colName = table.FormatColumnExpr(expr)
+					}
+					if err := appendRow(index, colName, sequence, dir, false, false); err != nil {
 						return err
 					}
 					sequence++
@@ -506,6 +512,7 @@ var (
 	tableTypeSystemView = parser.NewDString("SYSTEM VIEW")
 	tableTypeBaseTable  = parser.NewDString("BASE TABLE")
 	tableTypeView       = parser.NewDString("VIEW")
+	tableTypeSequence   = parser.NewDString("SEQUENCE")
 )
 
 var informationSchemaTablesTable = virtualSchemaTable{
@@ -524,6 +531,8 @@ CREATE TABLE information_schema.tables (
 				tableType = tableTypeSystemView
 			} else if table.IsView() {
 				tableType = tableTypeView
+			} else if table.IsSequence() {
+				tableType = tableTypeSequence
 			}
 			return addRow(
 				defString,                     // table_catalog
@@ -606,6 +615,9 @@ func forEachDatabaseDesc(
 
 	sort.Sort(sortedDBDescs(dbDescs))
 	for _, db := range dbDescs {
+		if p.isOtherSessionTemporarySchema(db.Name) {
+			continue
+		}
 		if userCanSeeDatabase(db, p.session.User) {
 			if err := fn(db); err != nil {
 				return err
@@ -784,7 +796,13 @@ func forEachTableDescWithTableLookupInternal(
 	}
 	sort.Strings(dbNames)
 	for _, dbName := range dbNames {
-		if !isDatabaseVisible(dbName, prefix, p.session.User) {
+		// The temporary tables of the session are visible in every database
+		// context, those of other sessions never are.
+		if isTemporarySchemaName(dbName) {
+			if dbName != p.session.temporarySchemaName() {
+				continue
+			}
+		} else if !isDatabaseVisible(dbName, prefix, p.session.User) {
 			continue
 		}
 		db := databases[dbName]
@@ -854,8 +872,11 @@ func forEachColumnInIndex(
 	return nil
 }
 
-func forEachUser(ctx context.Context, p *planner, fn func(username string) error) error {
-	query := `SELECT username FROM system.users`
+// forEachRole calls fn for every user and role, including the root user.
+func forEachRole(
+	ctx context.Context, p *planner, fn func(username string, isRole bool) error,
+) error {
+	query := `SELECT username, "isRole" FROM system.users`
 	plan, err := p.query(ctx, query)
 	if err != nil {
 		return nil
@@ -867,7 +888,7 @@ func forEachUser(ctx context.Context, p *planner, fn func(username string) error
 
 	// TODO(cuongdo/asubiotto): Get rid of root user special-casing if/when a row
 	// for "root" exists in system.user.
-	if err := fn(security.RootUser); err != nil {
+	if err := fn(security.RootUser, false /* isRole */); err != nil {
 		return err
 	}
 	params := runParams{
@@ -885,7 +906,8 @@ func forEachUser(ctx context.Context, p *planner, fn func(username string) error
 		}
 		row := plan.Values()
 		username := parser.MustBeDString(row[0])
-		if err := fn(string(username)); err != nil {
+		isRole := row[1] == parser.DBoolTrue
+		if err := fn(string(username), isRole); err != nil {
 			return err
 		}
 	}
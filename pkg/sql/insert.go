@@ -76,8 +76,16 @@ type insertNode struct {
 //          mysql requires INSERT. Also requires UPDATE on "ON DUPLICATE KEY UPDATE".
 func (p *planner) Insert(
 	ctx context.Context, n *parser.Insert, desiredTypes []parser.Type,
-) (planNode, error) {
-	tn, err := p.getAliasedTableName(n.Table)
+) (result planNode, err error) {
+	finishWith, err := p.initWith(ctx, n.With)
+	if err != nil {
+		return nil, err
+	}
+	if finishWith != nil {
+		defer func() { result, err = finishWith(result, err) }()
+	}
+
+	tn, err := p.getAliasedTableName(ctx, n.Table)
 	if err != nil {
 		return nil, err
 	}
@@ -89,7 +97,7 @@ func (p *planner) Insert(
 	isUpsertReturning := false
 	if n.OnConflict != nil {
 		if !n.OnConflict.DoNothing {
-			if err := p.CheckPrivilege(en.tableDesc, privilege.UPDATE); err != nil {
+			if err := p.CheckPrivilege(ctx, en.tableDesc, privilege.UPDATE); err != nil {
 				return nil, err
 			}
 		}
@@ -171,15 +179,27 @@ func (p *planner) Insert(
 		return nil, err
 	}
 	ri, err := sqlbase.MakeRowInserter(p.txn, en.tableDesc, fkTables, cols,
-		sqlbase.CheckFKs, &p.alloc)
+		sqlbase.CheckFKs, &p.evalCtx, &p.alloc)
 	if err != nil {
 		return nil, err
 	}
 
+	events := []parser.TriggerEvent{parser.TriggerInsert}
+	if n.OnConflict != nil && !n.OnConflict.DoNothing {
+		events = append(events, parser.TriggerUpdate)
+	}
+	triggers, err := p.makeRowTriggers(ctx, en.tableDesc, events...)
+	if err != nil {
+		return nil, err
+	}
+	// The AFTER triggers run once the rows are written, so the transaction
+	// cannot be committed along with them.
+	autoCommit := p.autoCommit && triggers == nil
+
 	var tw tableWriter
 	if n.OnConflict == nil {
 		ti := tableInserterPool.Get().(*tableInserter)
-		*ti = tableInserter{ri: ri, autoCommit: p.autoCommit}
+		*ti = tableInserter{ri: ri, autoCommit: autoCommit, triggers: triggers}
 		tw = ti
 	} else {
 		updateExprs, conflictIndex, err := upsertExprsAndIndex(en.tableDesc, *n.OnConflict, ri.InsertCols)
@@ -194,11 +214,12 @@ func (p *planner) Insert(
 			tu := tableUpserterPool.Get().(*tableUpserter)
 			*tu = tableUpserter{
 				ri:            ri,
-				autoCommit:    p.autoCommit,
+				autoCommit:    autoCommit,
 				conflictIndex: *conflictIndex,
 				alloc:         &p.alloc,
 				mon:           &p.session.TxnState.mon,
 				collectRows:   isUpsertReturning,
+				triggers:      triggers,
 			}
 			tw = tu
 		} else {
@@ -236,15 +257,17 @@ func (p *planner) Insert(
 			tu := tableUpserterPool.Get().(*tableUpserter)
 			*tu = tableUpserter{
 				ri:            ri,
-				autoCommit:    p.autoCommit,
+				autoCommit:    autoCommit,
 				alloc:         &p.alloc,
 				mon:           &p.session.TxnState.mon,
 				collectRows:   isUpsertReturning,
 				fkTables:      fkTables,
+				evalCtx:       &p.evalCtx,
 				updateCols:    updateCols,
 				conflictIndex: *conflictIndex,
 				evaler:        helper,
 				isUpsertAlias: n.OnConflict.IsUpsertAlias(),
+				triggers:      triggers,
 			}
 			tw = tu
 		}
@@ -266,7 +289,8 @@ func (p *planner) Insert(
 	}
 
 	if err := in.run.initEditNode(
-		ctx, &in.editNodeBase, rows, in.tw, tn, n.Returning, desiredTypes); err != nil {
+		ctx, &in.editNodeBase, rows, in.tw, in.tableSourceInfo(tn),
+		n.Returning, desiredTypes); err != nil {
 		return nil, err
 	}
 
@@ -348,72 +372,80 @@ func (n *insertNode) drain(params runParams) (bool, error) {
 }
 
 func (n *insertNode) internalNext(params runParams) (bool, error) {
-	if next, err := n.run.rows.Next(params); !next {
-		if err == nil {
-			if err := params.p.cancelChecker.Check(); err != nil {
-				return false, err
-			}
-			// We're done. Finish the batch.
-			rows, err := n.tw.finalize(params.ctx, params.p.session.Tracing.KVTracingEnabled())
-			if err != nil {
-				return false, err
-			}
-
-			if n.isUpsertReturning {
-				n.run.rowsUpserted = sqlbase.NewRowContainer(
-					params.p.session.TxnState.makeBoundAccount(),
-					sqlbase.ColTypeInfoFromResCols(n.rh.columns),
-					rows.Len(),
-				)
-				for i := 0; i < rows.Len(); i++ {
-					cooked, err := n.rh.cookResultRow(rows.At(i))
-					if err != nil {
-						return false, err
-					}
-					_, err = n.run.rowsUpserted.AddRow(params.ctx, cooked)
-					if err != nil {
-						return false, err
+	for {
+		if next, err := n.run.rows.Next(params); !next {
+			if err == nil {
+				if err := params.p.cancelChecker.Check(); err != nil {
+					return false, err
+				}
+				// We're done. Finish the batch.
+				rows, err := n.tw.finalize(params.ctx, params.p.session.Tracing.KVTracingEnabled())
+				if err != nil {
+					return false, err
+				}
+				n.notifyMutation(n.run.numRows)
+
+				if n.isUpsertReturning {
+					n.run.rowsUpserted = sqlbase.NewRowContainer(
+						params.p.session.TxnState.makeBoundAccount(),
+						sqlbase.ColTypeInfoFromResCols(n.rh.columns),
+						rows.Len(),
+					)
+					for i := 0; i < rows.Len(); i++ {
+						cooked, err := n.rh.cookResultRow(rows.At(i))
+						if err != nil {
+							return false, err
+						}
+						_, err = n.run.rowsUpserted.AddRow(params.ctx, cooked)
+						if err != nil {
+							return false, err
+						}
 					}
+					n.run.doneUpserting = true
 				}
-				n.run.doneUpserting = true
 			}
+			return false, err
 		}
-		return false, err
-	}
-
-	rowVals, err := GenerateInsertRow(n.defaultExprs, n.insertColIDtoRowIndex, n.insertCols, params.p.evalCtx, n.tableDesc, n.run.rows.Values())
-	if err != nil {
-		return false, err
-	}
 
-	if err := n.checkHelper.loadRow(n.insertColIDtoRowIndex, rowVals, false); err != nil {
-		return false, err
-	}
-	if err := n.checkHelper.check(&params.p.evalCtx); err != nil {
-		return false, err
-	}
-
-	_, err = n.tw.row(params.ctx, rowVals, params.p.session.Tracing.KVTracingEnabled())
-	if err != nil {
-		return false, err
-	}
+		rowVals, err := GenerateInsertRow(n.defaultExprs, n.insertColIDtoRowIndex, n.insertCols, params.p.evalCtx, n.tableDesc, n.run.rows.Values())
+		if err != nil {
+			return false, err
+		}
 
-	// Handle regular INSERT ... RETURNING without ON CONFLICT clause
-	if !n.isUpsertReturning {
-		for i, val := range rowVals {
-			if n.run.rowTemplate != nil {
-				n.run.rowTemplate[n.run.rowIdxToRetIdx[i]] = val
-			}
+		if err := n.checkHelper.loadRow(n.insertColIDtoRowIndex, rowVals, false); err != nil {
+			return false, err
+		}
+		if err := n.checkHelper.check(&params.p.evalCtx); err != nil {
+			return false, err
 		}
 
-		resultRow, err := n.rh.cookResultRow(n.run.rowTemplate)
+		_, err = n.tw.row(params.ctx, rowVals, params.p.session.Tracing.KVTracingEnabled())
 		if err != nil {
 			return false, err
 		}
-		n.run.resultRow = resultRow
-	}
+		if ti, ok := n.tw.(*tableInserter); ok && ti.triggers.rowSkipped() {
+			// A BEFORE trigger skipped the row.
+			continue
+		}
+		n.run.numRows++
+
+		// Handle regular INSERT ... RETURNING without ON CONFLICT clause
+		if !n.isUpsertReturning {
+			for i, val := range rowVals {
+				if n.run.rowTemplate != nil {
+					n.run.rowTemplate[n.run.rowIdxToRetIdx[i]] = val
+				}
+			}
+
+			resultRow, err := n.rh.cookResultRow(n.run.rowTemplate)
+			if err != nil {
+				return false, err
+			}
+			n.run.resultRow = resultRow
+		}
 
-	return true, nil
+		return true, nil
+	}
 }
 
 // GenerateInsertRow prepares a row tuple for insertion. It fills in default
@@ -452,7 +484,7 @@ func GenerateInsertRow(
 
 	// Check to see if NULL is being inserted into any non-nullable column.
 	for _, col := range tableDesc.Columns {
-		if !col.Nullable {
+		if !tableDesc.ColumnAcceptsNulls(&col) {
 			if i, ok := insertColIDtoRowIndex[col.ID]; !ok || rowVals[i] == parser.DNull {
 				return nil, sqlbase.NewNonNullViolationError(col.Name)
 			}
@@ -495,6 +527,10 @@ func (p *planner) processColumns(
 		if err != nil {
 			return nil, err
 		}
+		if tableDesc.IsIndexExprColumn(col.ID) {
+			return nil, pgerror.NewErrorf(pgerror.CodeInvalidColumnReferenceError,
+				"cannot write to column %q, which stores the values of an index expression", col.Name)
+		}
 
 		if _, ok := colIDSet[col.ID]; ok {
 			return nil, fmt.Errorf("multiple assignments to the same column %q", n)
@@ -67,6 +67,9 @@ type Details interface{}
 var _ Details = BackupDetails{}
 var _ Details = RestoreDetails{}
 var _ Details = SchemaChangeDetails{}
+var _ Details = ImportDetails{}
+var _ Details = CreateStatsDetails{}
+var _ Details = RefreshMaterializedViewDetails{}
 
 // Record stores the job fields that are not automatically managed by Job.
 type Record struct {
@@ -589,6 +592,10 @@ func (p *Payload) Type() Type {
 		return TypeSchemaChange
 	case *Payload_Import:
 		return TypeImport
+	case *Payload_CreateStats:
+		return TypeCreateStats
+	case *Payload_RefreshMaterializedView:
+		return TypeRefreshMaterializedView
 	default:
 		panic("Payload.Type called on a payload with an unknown details type")
 	}
@@ -611,6 +618,10 @@ func WrapPayloadDetails(details Details) interface {
 		return &Payload_SchemaChange{SchemaChange: &d}
 	case ImportDetails:
 		return &Payload_Import{Import: &d}
+	case CreateStatsDetails:
+		return &Payload_CreateStats{CreateStats: &d}
+	case RefreshMaterializedViewDetails:
+		return &Payload_RefreshMaterializedView{RefreshMaterializedView: &d}
 	default:
 		panic(fmt.Sprintf("jobs.WrapPayloadDetails: unknown details type %T", d))
 	}
@@ -632,6 +643,10 @@ func (p *Payload) UnwrapDetails() (Details, error) {
 		return *d.SchemaChange, nil
 	case *Payload_Import:
 		return *d.Import, nil
+	case *Payload_CreateStats:
+		return *d.CreateStats, nil
+	case *Payload_RefreshMaterializedView:
+		return *d.RefreshMaterializedView, nil
 	default:
 		return nil, errors.Errorf("jobs.Payload: unsupported details type %T", d)
 	}
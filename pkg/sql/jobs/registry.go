@@ -55,6 +55,9 @@ type Registry struct {
 		syncutil.Mutex
 		epoch int64
 		jobs  map[int64]*Job
+		// resumeHooks are consulted before the global resume hooks. They are
+		// used by jobs whose resumption requires per-node state.
+		resumeHooks []resumeHookFn
 	}
 }
 
@@ -189,6 +192,13 @@ func AddResumeHook(fn resumeHookFn) {
 	resumeHooks = append(resumeHooks, fn)
 }
 
+// AddResumeHook adds a resume hook that is only used by this registry.
+func (r *Registry) AddResumeHook(fn resumeHookFn) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mu.resumeHooks = append(r.mu.resumeHooks, fn)
+}
+
 func (r *Registry) maybeAdoptJob(ctx context.Context, nl nodeLiveness) error {
 	var rows []parser.Datums
 	if err := r.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
@@ -268,8 +278,11 @@ func (r *Registry) maybeAdoptJob(ctx context.Context, nl nodeLiveness) error {
 			continue
 		}
 
+		r.mu.Lock()
+		hooks := append(r.mu.resumeHooks[:len(r.mu.resumeHooks):len(r.mu.resumeHooks)], resumeHooks...)
+		r.mu.Unlock()
 		var resumeFn func(context.Context, *Job) error
-		for _, hook := range resumeHooks {
+		for _, hook := range hooks {
 			if resumeFn = hook(payload.Type()); resumeFn != nil {
 				break
 			}
@@ -21,6 +21,7 @@ import (
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 )
@@ -113,6 +114,13 @@ func (b *buckets) Close(ctx context.Context) {
 	b.buckets = nil
 }
 
+// Clear removes all the rows from the buckets, so that they can be
+// loaded again.
+func (b *buckets) Clear(ctx context.Context) {
+	b.rowContainer.Clear(ctx)
+	b.buckets = make(map[string]*bucket)
+}
+
 func (b *buckets) Fetch(encoding []byte) (*bucket, bool) {
 	bk, ok := b.buckets[string(encoding)]
 	return bk, ok
@@ -142,6 +150,12 @@ type joinNode struct {
 	// trimmed.
 	ordering orderingInfo
 
+	// algorithm is the join algorithm chosen during expandPlan, and estimate
+	// is the corresponding estimated cardinality and cost of the join. See
+	// chooseJoinAlgorithm.
+	algorithm joinAlgorithm
+	estimate  planEstimate
+
 	// columns contains the metadata for the results of this node.
 	columns sqlbase.ResultColumns
 
@@ -156,6 +170,24 @@ type joinNode struct {
 	buckets       buckets
 	bucketsMemAcc WrappableMemoryAccount
 
+	// The following fields are used when the join is run as a lookup join,
+	// see lookupJoinStart. lookupRows holds the current batch of rows of the
+	// left side, and lookupRowIdx is the number of them processed so far.
+	// lookupSpans are the spans of the scan of the right side, before it is
+	// restricted to the rows matching each batch. lookupDone is set once the
+	// left side is exhausted.
+	lookupIdx    *sqlbase.IndexDescriptor
+	lookupRows   *sqlbase.RowContainer
+	lookupRowIdx int
+	lookupSpans  roachpb.Spans
+	lookupDone   bool
+
+	// lateral is set when the right side of the join refers to the
+	// columns of the left side and could not be decorrelated. The
+	// buckets then contain the rows of the right side for the current
+	// row of the left side. See lateral.go.
+	lateral *lateralSource
+
 	// emptyRight contain tuples of NULL values to use on the right for left and
 	// full outer joins when the on condition fails.
 	emptyRight parser.Datums
@@ -291,12 +323,20 @@ func (n *joinNode) Start(params runParams) error {
 	if err := n.left.plan.Start(params); err != nil {
 		return err
 	}
-	if err := n.right.plan.Start(params); err != nil {
-		return err
-	}
+	// The right side of a lateral join is run for every row of the
+	// left side instead, see runLateral.
+	if n.lateral == nil {
+		if err := n.right.plan.Start(params); err != nil {
+			return err
+		}
 
-	if err := n.hashJoinStart(params); err != nil {
-		return err
+		if n.algorithm == lookupJoin {
+			if err := n.lookupJoinStart(params); err != nil {
+				return err
+			}
+		} else if err := n.hashJoinStart(params); err != nil {
+			return err
+		}
 	}
 
 	// Pre-allocate the space for output rows.
@@ -320,20 +360,143 @@ func (n *joinNode) Start(params runParams) error {
 	return nil
 }
 
+// lookupJoinBatchSize is the number of rows of the left side of a lookup join
+// whose matching rows on the right side are looked up at once.
+const lookupJoinBatchSize = 100
+
+// lookupJoinStart prepares the join to be run as a lookup join: the rows of
+// the left side are read in batches, and for each batch the scan of the right
+// side is restricted to the spans of its index which contain the rows
+// matching them (see lookupNextBatch).
+func (n *joinNode) lookupJoinStart(params runParams) error {
+	n.lookupIdx = n.lookupIndex()
+	if n.lookupIdx == nil {
+		return errors.Errorf("join cannot be run as a lookup join")
+	}
+	scan := n.right.plan.(*scanNode)
+	n.lookupSpans, _ = roachpb.MergeSpans(append(roachpb.Spans(nil), scan.spans...))
+	n.lookupRows = sqlbase.NewRowContainer(
+		n.planner.session.TxnState.makeBoundAccount(),
+		sqlbase.ColTypeInfoFromResCols(planColumns(n.left.plan)),
+		lookupJoinBatchSize,
+	)
+	return nil
+}
+
+// lookupNextBatch reads the next batch of rows of the left side of a lookup
+// join into n.lookupRows, and loads the rows of the right side which match
+// them into the buckets.
+func (n *joinNode) lookupNextBatch(params runParams) error {
+	ctx := params.ctx
+	scan := n.right.plan.(*scanNode)
+	n.lookupRows.Clear(ctx)
+	n.lookupRowIdx = 0
+	n.buckets.Clear(ctx)
+	n.bucketsMemAcc.Wtxn(n.planner.session).Clear(ctx)
+
+	prefix := sqlbase.MakeIndexKeyPrefix(scan.desc, n.lookupIdx.ID)
+	numEq := len(n.pred.leftEqualityIndices)
+	var spans roachpb.Spans
+	for n.lookupRows.Len() < lookupJoinBatchSize {
+		hasRow, err := n.left.plan.Next(params)
+		if err != nil {
+			return err
+		}
+		if !hasRow {
+			n.lookupDone = true
+			break
+		}
+		row := n.left.plan.Values()
+
+		key := append(roachpb.Key(nil), prefix...)
+		containsNull := false
+		for i, colID := range n.lookupIdx.ColumnIDs[:numEq] {
+			d := row[n.pred.leftEqualityIndices[lookupEqualityIdx(scan, n.pred.rightEqualityIndices, colID)]]
+			if d == parser.DNull {
+				containsNull = true
+				break
+			}
+			dir, err := n.lookupIdx.ColumnDirections[i].ToEncodingDirection()
+			if err != nil {
+				return err
+			}
+			if key, err = sqlbase.EncodeTableKey(key, d, dir); err != nil {
+				return err
+			}
+		}
+		if containsNull {
+			// NULLs never match, there is nothing to look up.
+			continue
+		}
+		if _, err := n.lookupRows.AddRow(ctx, row); err != nil {
+			return err
+		}
+		spans = append(spans, roachpb.Span{Key: key, EndKey: key.PrefixEnd()})
+	}
+
+	// Only the rows of the original scan can match.
+	spans, _ = roachpb.MergeSpans(spans)
+	scan.spans = roachpb.IntersectSpans(spans, n.lookupSpans)
+	if len(scan.spans) == 0 {
+		// None of the rows of the batch can have a match; the right side does
+		// not need to be scanned.
+		return nil
+	}
+	scan.scanInitialized = false
+	return n.addRightRows(params, scan)
+}
+
+// nextLeftRow advances to the next row of the left side.
+func (n *joinNode) nextLeftRow(params runParams) (bool, error) {
+	if n.lookupRows == nil {
+		return n.left.plan.Next(params)
+	}
+	for n.lookupRowIdx >= n.lookupRows.Len() {
+		if n.lookupDone {
+			return false, nil
+		}
+		if err := n.lookupNextBatch(params); err != nil {
+			return false, err
+		}
+	}
+	n.lookupRowIdx++
+	return true, nil
+}
+
+// leftValues returns the current row of the left side.
+func (n *joinNode) leftValues() parser.Datums {
+	if n.lookupRows == nil {
+		return n.left.plan.Values()
+	}
+	return n.lookupRows.At(n.lookupRowIdx - 1)
+}
+
 func (n *joinNode) hashJoinStart(params runParams) error {
-	var scratch []byte
 	// Load all the rows from the right side and build our hashmap.
+	if err := n.addRightRows(params, n.right.plan); err != nil {
+		return err
+	}
+	if n.joinType == joinTypeFullOuter || n.joinType == joinTypeRightOuter {
+		return n.buckets.InitSeen(params.ctx, n.bucketsMemAcc.Wtxn(n.planner.session))
+	}
+	return nil
+}
+
+// addRightRows loads the rows of the given plan for the right side into
+// the buckets.
+func (n *joinNode) addRightRows(params runParams, plan planNode) error {
+	var scratch []byte
 	acc := n.bucketsMemAcc.Wtxn(n.planner.session)
 	ctx := params.ctx
 	for {
-		hasRow, err := n.right.plan.Next(params)
+		hasRow, err := plan.Next(params)
 		if err != nil {
 			return err
 		}
 		if !hasRow {
 			break
 		}
-		row := n.right.plan.Values()
+		row := plan.Values()
 		encoding, _, err := n.pred.encode(scratch, row, n.pred.rightEqualityIndices)
 		if err != nil {
 			return err
@@ -345,9 +508,6 @@ func (n *joinNode) hashJoinStart(params runParams) error {
 
 		scratch = encoding[:0]
 	}
-	if n.joinType == joinTypeFullOuter || n.joinType == joinTypeRightOuter {
-		return n.buckets.InitSeen(ctx, acc)
-	}
 	return nil
 }
 
@@ -367,7 +527,7 @@ func (n *joinNode) Next(params runParams) (res bool, err error) {
 	wantUnmatchedLeft := n.joinType == joinTypeLeftOuter || n.joinType == joinTypeFullOuter
 	wantUnmatchedRight := n.joinType == joinTypeRightOuter || n.joinType == joinTypeFullOuter
 
-	if len(n.buckets.Buckets()) == 0 {
+	if len(n.buckets.Buckets()) == 0 && n.lateral == nil && n.lookupRows == nil {
 		if !wantUnmatchedLeft {
 			// No rows on right; don't even try.
 			return false, nil
@@ -381,7 +541,7 @@ func (n *joinNode) Next(params runParams) (res bool, err error) {
 			return false, err
 		}
 
-		leftHasRow, err := n.left.plan.Next(params)
+		leftHasRow, err := n.nextLeftRow(params)
 		if err != nil {
 			return false, nil
 		}
@@ -389,7 +549,12 @@ func (n *joinNode) Next(params runParams) (res bool, err error) {
 			break
 		}
 
-		lrow := n.left.plan.Values()
+		lrow := n.leftValues()
+		if n.lateral != nil {
+			if err := n.runLateral(params, lrow); err != nil {
+				return false, err
+			}
+		}
 		encoding, containsNull, err := n.pred.encode(scratch, lrow, n.pred.leftEqualityIndices)
 		if err != nil {
 			return false, err
@@ -539,13 +704,36 @@ func (n *joinNode) Values() parser.Datums {
 
 // Close implements the planNode interface.
 func (n *joinNode) Close(ctx context.Context) {
+	n.releaseResources(ctx)
+	n.right.plan.Close(ctx)
+	n.left.plan.Close(ctx)
+}
+
+func (n *joinNode) memUsage() int64 {
+	usage := n.bucketsMemAcc.CurrentlyAllocated()
+	if n.buffer != nil {
+		usage += n.buffer.MemUsage()
+	}
+	if n.buckets.rowContainer != nil {
+		usage += n.buckets.rowContainer.MemUsage()
+	}
+	if n.lookupRows != nil {
+		usage += n.lookupRows.MemUsage()
+	}
+	return usage
+}
+
+// releaseResources releases the resources of the joinNode itself, but not
+// those of its sources.
+func (n *joinNode) releaseResources(ctx context.Context) {
 	n.buffer.Close(ctx)
 	n.buffer = nil
 	n.buckets.Close(ctx)
 	n.bucketsMemAcc.Wtxn(n.planner.session).Close(ctx)
-
-	n.right.plan.Close(ctx)
-	n.left.plan.Close(ctx)
+	if n.lookupRows != nil {
+		n.lookupRows.Close(ctx)
+		n.lookupRows = nil
+	}
 }
 
 // equalityColIdxInSchema takes a column index from joinPred.leftEqualityIndices
@@ -0,0 +1,347 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"math"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/util"
+)
+
+// This file implements the reordering of trees of inner joins. A tree of
+// inner joins is flattened into its data sources and the predicates which
+// connect them; if the estimates of all the sources are derived from table
+// statistics, a greedy search looks for a cheaper left-deep join order than
+// the one written in the query. The reordered tree is wrapped in a renderNode
+// which restores the columns in their original order.
+
+// isReorderableJoin returns true if the given plan is a join which can be
+// flattened into a joinTree: an inner join without merged columns (i.e.
+// without USING or NATURAL), whose right side does not depend on its left
+// side.
+func isReorderableJoin(plan planNode) bool {
+	n, ok := plan.(*joinNode)
+	return ok && n.joinType == joinTypeInner && n.pred.numMergedEqualityColumns == 0 &&
+		n.lateral == nil
+}
+
+// joinTree is a tree of inner joins, flattened into its data sources and its
+// predicates. The predicates refer to the columns of the concatenation of the
+// columns of all the sources, which is the layout of the results of the tree.
+type joinTree struct {
+	// sources are the data sources of the tree, in the order of their columns
+	// in the results of the tree. They point into the joinNodes of the tree,
+	// so that the sources can be expanded in place.
+	sources []*planDataSource
+	// offsets[i] is the position of the first column of sources[i].
+	offsets []int
+	// numCols is the total number of columns of the sources.
+	numCols int
+
+	// joins are the joinNodes of the tree, children first.
+	joins []*joinNode
+
+	// equalities are the pairs of columns constrained to be equal.
+	equalities [][2]int
+	// filters are the conjuncts of the ON conditions which are not
+	// equalities, using ordinal references to the columns.
+	filters []parser.TypedExpr
+}
+
+// addSource adds a data source to the tree. Reorderable joins are flattened
+// recursively.
+func (t *joinTree) addSource(evalCtx *parser.EvalContext, src *planDataSource) {
+	if !isReorderableJoin(src.plan) {
+		t.sources = append(t.sources, src)
+		t.offsets = append(t.offsets, t.numCols)
+		t.numCols += len(src.info.sourceColumns)
+		return
+	}
+	n := src.plan.(*joinNode)
+
+	leftOffset := t.numCols
+	t.addSource(evalCtx, &n.left)
+	rightOffset := t.numCols
+	t.addSource(evalCtx, &n.right)
+	t.joins = append(t.joins, n)
+
+	for i := range n.pred.leftEqualityIndices {
+		t.equalities = append(t.equalities, [2]int{
+			leftOffset + n.pred.leftEqualityIndices[i],
+			rightOffset + n.pred.rightEqualityIndices[i],
+		})
+	}
+	if !isFilterTrue(n.pred.onCond) {
+		for _, e := range splitAndExpr(evalCtx, n.pred.onCond, nil) {
+			t.filters = append(t.filters, exprConvertVars(e,
+				func(expr parser.VariableExpr) (bool, parser.Expr) {
+					if iv, ok := expr.(*parser.IndexedVar); ok {
+						return true, parser.NewOrdinalReference(leftOffset + iv.Idx)
+					}
+					return true, expr
+				}))
+		}
+	}
+}
+
+// sourceOf returns the index of the source which provides the given column.
+func (t *joinTree) sourceOf(col int) int {
+	i := len(t.offsets) - 1
+	for t.offsets[i] > col {
+		i--
+	}
+	return i
+}
+
+// filterSources returns the set of sources referenced by each filter.
+func (t *joinTree) filterSources() []util.FastIntSet {
+	res := make([]util.FastIntSet, len(t.filters))
+	for i, f := range t.filters {
+		exprCheckVars(f, func(expr parser.VariableExpr) (bool, parser.Expr) {
+			if iv, ok := expr.(*parser.IndexedVar); ok {
+				res[i].Add(uint32(t.sourceOf(iv.Idx)))
+			}
+			return true, expr
+		})
+	}
+	return res
+}
+
+// isSubset returns true if all the elements of a are elements of b.
+func isSubset(a, b util.FastIntSet) bool {
+	subset := true
+	a.ForEach(func(i uint32) {
+		if !b.Contains(i) {
+			subset = false
+		}
+	})
+	return subset
+}
+
+// joinOrderer implements the greedy search for a join order.
+type joinOrderer struct {
+	tree *joinTree
+
+	// estimates of the sources.
+	estimates []planEstimate
+	// distinct[i] is the estimated number of distinct values of the columns
+	// of equalities[i], on both sides.
+	distinct [][2]float64
+	// filterSources are the sets of sources referenced by the filters.
+	filterSources []util.FastIntSet
+}
+
+// joinStep estimates the result of joining the sources in the given set,
+// whose join has the given estimate, with another source. The cheapest of a
+// hash join and, if possible, a lookup join is assumed.
+func (o *joinOrderer) joinStep(cur planEstimate, set util.FastIntSet, src int) planEstimate {
+	t := o.tree
+	sel := 1.0
+	var rightEqCols []int
+	var leftEqTypes []parser.Type
+	for i, eq := range t.equalities {
+		a, b := eq[0], eq[1]
+		distinctA, distinctB := o.distinct[i][0], o.distinct[i][1]
+		if t.sourceOf(a) == src {
+			a, b = b, a
+			distinctA, distinctB = distinctB, distinctA
+		}
+		if t.sourceOf(b) != src || !set.Contains(uint32(t.sourceOf(a))) {
+			continue
+		}
+		sel /= math.Max(distinctA, distinctB)
+		rightEqCols = append(rightEqCols, b-t.offsets[src])
+		leftEqTypes = append(leftEqTypes, o.columnType(a))
+	}
+
+	withSrc := set.Copy()
+	withSrc.Add(uint32(src))
+	for _, fs := range o.filterSources {
+		if fs.Contains(uint32(src)) && isSubset(fs, withSrc) {
+			sel *= defaultSelectivity
+		}
+	}
+
+	right := o.estimates[src]
+	est := joinCost(cur, right, sel, hashJoin)
+	if lookupJoinIndex(t.sources[src].plan, rightEqCols, leftEqTypes) != nil {
+		if lookup := joinCost(cur, right, sel, lookupJoin); lookup.cost < est.cost {
+			est = lookup
+		}
+	}
+	return est
+}
+
+// columnType returns the type of a column of the tree.
+func (o *joinOrderer) columnType(col int) parser.Type {
+	src := o.tree.sourceOf(col)
+	return o.tree.sources[src].info.sourceColumns[col-o.tree.offsets[src]].Typ
+}
+
+// chooseOrder returns the left-deep join order found by the greedy search,
+// along with its estimate. Each source is tried as the first one; the
+// following ones are chosen as the cheapest to join with the previous ones.
+func (o *joinOrderer) chooseOrder() ([]int, planEstimate) {
+	numSources := len(o.tree.sources)
+	var bestOrder []int
+	var best planEstimate
+	for first := 0; first < numSources; first++ {
+		order := []int{first}
+		var set util.FastIntSet
+		set.Add(uint32(first))
+		cur := o.estimates[first]
+		for len(order) < numSources {
+			next := -1
+			var nextEst planEstimate
+			for src := 0; src < numSources; src++ {
+				if set.Contains(uint32(src)) {
+					continue
+				}
+				if est := o.joinStep(cur, set, src); next == -1 || est.cost < nextEst.cost {
+					next, nextEst = src, est
+				}
+			}
+			order = append(order, next)
+			set.Add(uint32(next))
+			cur = nextEst
+		}
+		if bestOrder == nil || cur.cost < best.cost {
+			bestOrder, best = order, cur
+		}
+	}
+	return bestOrder, best
+}
+
+// reorderJoins looks for a cheaper order for a tree of inner joins whose
+// sources are expanded and whose joins have been estimated. It returns either
+// the original root of the tree or a new plan with the same columns.
+func (p *planner) reorderJoins(ctx context.Context, root *joinNode, t *joinTree) (planNode, error) {
+	o := joinOrderer{
+		tree:          t,
+		estimates:     make([]planEstimate, len(t.sources)),
+		distinct:      make([][2]float64, len(t.equalities)),
+		filterSources: t.filterSources(),
+	}
+	for i, src := range t.sources {
+		o.estimates[i] = p.estimatePlan(ctx, src.plan)
+		if !o.estimates[i].fromStats {
+			// Without statistics, keep the order written in the query.
+			return root, nil
+		}
+	}
+	for i, eq := range t.equalities {
+		for j, col := range eq {
+			src := t.sourceOf(col)
+			o.distinct[i][j] = p.estimateColumnDistinct(
+				ctx, t.sources[src].plan, col-t.offsets[src], o.estimates[src].rowCount,
+			)
+		}
+	}
+
+	order, est := o.chooseOrder()
+	if est.cost >= root.estimate.cost {
+		return root, nil
+	}
+	reordered := false
+	for i := range order {
+		if order[i] != i {
+			reordered = true
+		}
+	}
+	if !reordered {
+		return root, nil
+	}
+
+	// newPos maps the columns of the original tree to the columns of the
+	// reordered one.
+	newPos := make([]int, t.numCols)
+	numCols := 0
+	var included util.FastIntSet
+	addSource := func(src int) {
+		for i := range t.sources[src].info.sourceColumns {
+			newPos[t.offsets[src]+i] = numCols + i
+		}
+		numCols += len(t.sources[src].info.sourceColumns)
+		included.Add(uint32(src))
+	}
+
+	placedFilters := make([]bool, len(t.filters))
+	cur := *t.sources[order[0]]
+	addSource(order[0])
+	for _, src := range order[1:] {
+		prev := included.Copy()
+		addSource(src)
+
+		ds, err := p.makeJoin(ctx, "JOIN", cur, *t.sources[src], nil /* cond */)
+		if err != nil {
+			return nil, err
+		}
+		n := ds.plan.(*joinNode)
+
+		var onCond parser.TypedExpr
+		for _, eq := range t.equalities {
+			a, b := t.sourceOf(eq[0]), t.sourceOf(eq[1])
+			if !(a == src && prev.Contains(uint32(b))) && !(b == src && prev.Contains(uint32(a))) {
+				continue
+			}
+			filter := parser.NewTypedComparisonExpr(parser.EQ,
+				n.pred.iVarHelper.IndexedVar(newPos[eq[0]]),
+				n.pred.iVarHelper.IndexedVar(newPos[eq[1]]),
+			)
+			if !n.pred.tryAddEqualityFilter(filter, n.left.info, n.right.info) {
+				onCond = mergeConj(onCond, filter)
+			}
+		}
+		for i, f := range t.filters {
+			if placedFilters[i] || !isSubset(o.filterSources[i], included) {
+				continue
+			}
+			placedFilters[i] = true
+			onCond = mergeConj(onCond, exprConvertVars(f,
+				func(expr parser.VariableExpr) (bool, parser.Expr) {
+					if iv, ok := expr.(*parser.IndexedVar); ok {
+						return true, n.pred.iVarHelper.IndexedVar(newPos[iv.Idx])
+					}
+					return true, expr
+				}))
+		}
+		n.pred.onCond = n.pred.iVarHelper.Rebind(onCond, true /* alsoReset */, false /* normalizeToNonNil */)
+
+		p.completeJoinExpansion(ctx, n)
+		cur = ds
+	}
+
+	// Restore the original order of the columns.
+	r := &renderNode{
+		planner: p,
+		source:  cur,
+	}
+	r.sourceInfo = multiSourceInfo{cur.info}
+	r.ivarHelper = parser.MakeIndexedVarHelper(r, numCols)
+	for i, col := range root.columns {
+		expr := r.ivarHelper.IndexedVar(newPos[i])
+		r.addRenderColumn(expr, symbolicExprStr(expr), col)
+	}
+	r.numOriginalCols = len(r.columns)
+	r.computeOrdering(planOrdering(cur.plan))
+
+	for _, j := range t.joins {
+		j.releaseResources(ctx)
+	}
+	return r, nil
+}
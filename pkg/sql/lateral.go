@@ -0,0 +1,377 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+)
+
+// This file implements LATERAL data sources, that is, data sources in
+// a FROM clause that can refer to the columns of the FROM items that
+// precede them:
+//
+//   SELECT * FROM t, LATERAL (SELECT * FROM u WHERE u.b = t.a) AS s
+//   SELECT * FROM t, generate_series(1, t.a)
+//
+// As in PostgreSQL, functions in FROM are implicitly lateral.
+//
+// While the right side of a lateral join is being planned, the data
+// source on its left is registered as a lateral scope of the
+// planner. Column names which cannot be resolved against the sources
+// of an expression are then looked up in the lateral scopes, innermost
+// first, and replaced by a lateralColumn.
+//
+// If the right side does not refer to the left side, the join is
+// planned as a regular join. Otherwise, the planner first attempts to
+// decorrelate it (see decorrelateLateralJoin). When this is not
+// possible, the joinNode plans and runs its right side anew for every
+// row of its left side (see runLateral). The right side planned along
+// with the rest of the query is only used to describe the join.
+
+// lateralScope is a data source whose columns can be referred to by
+// the right side of a lateral join.
+type lateralScope struct {
+	info *dataSourceInfo
+	// row is the current row of the data source, while the right side
+	// of the join is planned and run for it.
+	row parser.Datums
+	// numRefs is the number of references to the scope found during
+	// name resolution.
+	numRefs int
+}
+
+// lateralColumn is a reference to a column of a lateralScope in an
+// expression tree.
+type lateralColumn struct {
+	scope  *lateralScope
+	colIdx int
+}
+
+var _ parser.TypedExpr = &lateralColumn{}
+var _ parser.VariableExpr = &lateralColumn{}
+
+func (c *lateralColumn) Format(buf *bytes.Buffer, f parser.FmtFlags) {
+	c.scope.info.FormatVar(buf, f, c.colIdx)
+}
+
+func (c *lateralColumn) String() string { return parser.AsString(c) }
+
+func (c *lateralColumn) Walk(v parser.Visitor) parser.Expr {
+	return c
+}
+
+func (c *lateralColumn) Variable() {}
+
+func (c *lateralColumn) TypeCheck(_ *parser.SemaContext, desired parser.Type) (parser.TypedExpr, error) {
+	return c, nil
+}
+
+func (c *lateralColumn) ResolvedType() parser.Type {
+	return c.scope.info.sourceColumns[c.colIdx].Typ
+}
+
+func (c *lateralColumn) Eval(_ *parser.EvalContext) (parser.Datum, error) {
+	if c.scope.row == nil {
+		panic("lateral reference evaluated outside of its join")
+	}
+	return c.scope.row[c.colIdx], nil
+}
+
+// resolveLateralColumn looks up a column reference which could not be
+// found in the sources of an expression in the given lateral scopes,
+// innermost first. The second return value is false if the column is
+// not found there either.
+//
+// When the right side of a join is planned for a specific row of its
+// left side, the reference is replaced by the value of the column
+// itself, so that it can be used e.g. to constrain index scans. NULL
+// values are not substituted, to preserve the type of the reference.
+func resolveLateralColumn(
+	scopes []*lateralScope, c *parser.ColumnItem,
+) (parser.TypedExpr, bool, error) {
+	for i := len(scopes) - 1; i >= 0; i-- {
+		s := scopes[i]
+		_, colIdx, err := multiSourceInfo{s.info}.findColumn(c)
+		if err != nil {
+			if isUnknownColumnError(err) {
+				continue
+			}
+			return nil, false, err
+		}
+		s.numRefs++
+		if s.row != nil && s.row[colIdx] != parser.DNull {
+			return s.row[colIdx], true, nil
+		}
+		return &lateralColumn{scope: s, colIdx: colIdx}, true, nil
+	}
+	return nil, false, nil
+}
+
+// isUnknownColumnError returns true if the error was returned by
+// findColumn because the column or its table is not part of the
+// sources.
+func isUnknownColumnError(err error) bool {
+	pgErr, ok := pgerror.GetPGCause(err)
+	return ok && (pgErr.Code == pgerror.CodeUndefinedColumnError ||
+		pgErr.Code == pgerror.CodeUndefinedTableError)
+}
+
+// isLateralSource returns true if the given data source can refer to
+// the FROM items that precede it.
+func isLateralSource(src parser.TableExpr) bool {
+	switch t := src.(type) {
+	case *parser.AliasedTableExpr:
+		return t.Lateral || isLateralSource(t.Expr)
+	case *parser.FuncExpr:
+		return true
+	}
+	return false
+}
+
+// lastLateralSource returns the index of the last source in a FROM
+// list, other than the first one, which can refer to the sources that
+// precede it, or 0 if there is none.
+func lastLateralSource(sources []parser.TableExpr) int {
+	for i := len(sources) - 1; i > 0; i-- {
+		if isLateralSource(sources[i]) {
+			return i
+		}
+	}
+	return 0
+}
+
+// lateralSource is the right side of a lateral join which cannot be
+// decorrelated.
+type lateralSource struct {
+	scope *lateralScope
+	// plan plans and optimizes the right side of the join for the
+	// current row of the scope.
+	plan func(ctx context.Context) (planNode, error)
+}
+
+// makeLateralJoin constructs a planDataSource for a JOIN node whose
+// right side is a lateral data source. The right side is planned with
+// the left side as innermost lateral scope.
+func (p *planner) makeLateralJoin(
+	ctx context.Context,
+	astJoinType string,
+	left planDataSource,
+	rightExpr parser.TableExpr,
+	cond parser.JoinCond,
+	scanVisibility scanVisibility,
+) (planDataSource, error) {
+	scope := &lateralScope{info: left.info}
+	scopes := append(append([]*lateralScope(nil), p.lateralScopes...), scope)
+	planRight := func(ctx context.Context) (planDataSource, error) {
+		defer func(prev []*lateralScope) { p.lateralScopes = prev }(p.lateralScopes)
+		p.lateralScopes = scopes
+		return p.getDataSource(ctx, rightExpr, nil, scanVisibility)
+	}
+
+	right, err := planRight(ctx)
+	if err != nil {
+		return planDataSource{}, err
+	}
+	if scope.numRefs == 0 {
+		// The right side is not correlated; a regular join will do.
+		return p.makeJoin(ctx, astJoinType, left, right, cond)
+	}
+
+	switch astJoinType {
+	case "RIGHT JOIN", "FULL JOIN":
+		return planDataSource{}, pgerror.NewErrorf(pgerror.CodeInvalidColumnReferenceError,
+			"the combining JOIN type must be INNER or LEFT for a LATERAL reference")
+	}
+
+	if src, ok, err := p.decorrelateLateralJoin(ctx, astJoinType, left, right, cond, scope); ok || err != nil {
+		return src, err
+	}
+
+	src, err := p.makeJoin(ctx, astJoinType, left, right, cond)
+	if err != nil {
+		return planDataSource{}, err
+	}
+	env := p.cteNameEnvironment
+	src.plan.(*joinNode).lateral = &lateralSource{
+		scope: scope,
+		plan: func(ctx context.Context) (planNode, error) {
+			defer func(prev *cteNameEnvironment) { p.cteNameEnvironment = prev }(p.cteNameEnvironment)
+			p.cteNameEnvironment = env
+
+			right, err := planRight(ctx)
+			if err != nil {
+				return nil, err
+			}
+			plan, err := p.optimizePlan(ctx, right.plan, allColumns(right.plan))
+			if err != nil {
+				right.plan.Close(ctx)
+				return nil, err
+			}
+			return plan, nil
+		},
+	}
+	return src, nil
+}
+
+// decorrelateLateralJoin attempts to plan a lateral join as a regular
+// join. This is possible when the right side is a simple SELECT which
+// only refers to the left side in conjuncts of its WHERE clause, and
+// which renders the columns used by these conjuncts as-is. The
+// conjuncts are then moved to the join predicate, for example:
+//
+//   SELECT * FROM t, LATERAL (SELECT * FROM u WHERE u.b = t.a) AS s
+//   -> SELECT * FROM t JOIN (SELECT * FROM u) AS s ON s.b = t.a
+//
+// The second return value is false if the join cannot be decorrelated.
+func (p *planner) decorrelateLateralJoin(
+	ctx context.Context,
+	astJoinType string,
+	left, right planDataSource,
+	cond parser.JoinCond,
+	scope *lateralScope,
+) (planDataSource, bool, error) {
+	switch astJoinType {
+	case "JOIN", "INNER JOIN", "CROSS JOIN", "LEFT JOIN":
+	default:
+		return planDataSource{}, false, nil
+	}
+	if _, ok := cond.(*parser.OnJoinCond); cond != nil && !ok {
+		// The merged columns of USING and NATURAL joins are only
+		// supported by regular join predicates.
+		return planDataSource{}, false, nil
+	}
+	r, ok := right.plan.(*renderNode)
+	if !ok {
+		return planDataSource{}, false, nil
+	}
+	f, ok := r.source.plan.(*filterNode)
+	if !ok || f.filter == nil {
+		return planDataSource{}, false, nil
+	}
+
+	// renderOf maps the source columns of the SELECT to the first
+	// render which passes them through unchanged.
+	renderOf := make(map[int]int)
+	for i, e := range r.render {
+		if countLateralRefs(e, scope) > 0 {
+			return planDataSource{}, false, nil
+		}
+		if iv, ok := e.(*parser.IndexedVar); ok {
+			if _, found := renderOf[iv.Idx]; !found {
+				renderOf[iv.Idx] = i
+			}
+		}
+	}
+
+	var correlated, uncorrelated parser.TypedExprs
+	numRefs := 0
+	for _, e := range splitAndExpr(&p.evalCtx, f.filter, nil) {
+		n := countLateralRefs(e, scope)
+		if n == 0 {
+			uncorrelated = append(uncorrelated, e)
+			continue
+		}
+		rendered := exprCheckVars(e, func(expr parser.VariableExpr) (bool, parser.Expr) {
+			if iv, ok := expr.(*parser.IndexedVar); ok {
+				_, found := renderOf[iv.Idx]
+				return found, expr
+			}
+			return true, expr
+		})
+		if !rendered {
+			return planDataSource{}, false, nil
+		}
+		numRefs += n
+		correlated = append(correlated, e)
+	}
+	if numRefs != scope.numRefs {
+		// Some references are elsewhere, for example in the FROM clause
+		// or in a sub-query of the SELECT.
+		return planDataSource{}, false, nil
+	}
+
+	f.filter = f.ivarHelper.Rebind(joinAndExprs(uncorrelated), true, false)
+
+	src, err := p.makeJoin(ctx, astJoinType, left, right, cond)
+	if err != nil {
+		return planDataSource{}, false, err
+	}
+	n := src.plan.(*joinNode)
+	numLeft := len(left.info.sourceColumns)
+	pred := exprConvertVars(joinAndExprs(correlated),
+		func(expr parser.VariableExpr) (bool, parser.Expr) {
+			switch t := expr.(type) {
+			case *parser.IndexedVar:
+				return true, n.pred.iVarHelper.IndexedVar(numLeft + renderOf[t.Idx])
+			case *lateralColumn:
+				if t.scope == scope {
+					return true, n.pred.iVarHelper.IndexedVar(t.colIdx)
+				}
+			}
+			return true, expr
+		})
+	n.pred.onCond = mergeConj(n.pred.onCond, pred)
+	return src, true, nil
+}
+
+// lateralRefCounter counts the references to a lateral scope in an
+// expression, not including those in sub-queries.
+type lateralRefCounter struct {
+	scope   *lateralScope
+	numRefs int
+}
+
+var _ parser.Visitor = &lateralRefCounter{}
+
+func (v *lateralRefCounter) VisitPre(expr parser.Expr) (recurse bool, newExpr parser.Expr) {
+	if c, ok := expr.(*lateralColumn); ok && c.scope == v.scope {
+		v.numRefs++
+	}
+	return true, expr
+}
+
+func (*lateralRefCounter) VisitPost(expr parser.Expr) parser.Expr { return expr }
+
+func countLateralRefs(expr parser.Expr, scope *lateralScope) int {
+	v := lateralRefCounter{scope: scope}
+	parser.WalkExprConst(&v, expr)
+	return v.numRefs
+}
+
+// runLateral plans and runs the right side of a lateral join for the
+// given row of the left side, and loads its rows into the buckets.
+func (n *joinNode) runLateral(params runParams, lrow parser.Datums) error {
+	ctx := params.ctx
+	n.buckets.Clear(ctx)
+	n.bucketsMemAcc.Wtxn(n.planner.session).Clear(ctx)
+
+	n.lateral.scope.row = lrow
+	defer func() { n.lateral.scope.row = nil }()
+
+	plan, err := n.lateral.plan(ctx)
+	if err != nil {
+		return err
+	}
+	defer plan.Close(ctx)
+	if err := params.p.startPlan(ctx, plan); err != nil {
+		return err
+	}
+	return n.addRightRows(params, plan)
+}
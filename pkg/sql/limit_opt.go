@@ -129,6 +129,13 @@ func applyLimit(plan planNode, numRows int64, soft bool) {
 		setUnlimited(n.left.plan)
 		setUnlimited(n.right.plan)
 
+	case *recursiveCTENode:
+		setUnlimited(n.initial)
+		setUnlimited(n.recursive)
+
+	case *spoolNode:
+		setUnlimited(n.source)
+
 	case *ordinalityNode:
 		applyLimit(n.source, numRows, soft)
 
@@ -155,6 +162,8 @@ func applyLimit(plan planNode, numRows int64, soft bool) {
 		if n.expanded {
 			setUnlimited(n.plan)
 		}
+	case *analyzeNode:
+		applyLimit(n.plan, numRows, soft)
 
 	case *splitNode:
 		setUnlimited(n.rows)
@@ -164,6 +173,7 @@ func applyLimit(plan planNode, numRows int64, soft bool) {
 
 	case *valuesNode:
 	case *alterTableNode:
+	case *alterSequenceNode:
 	case *cancelQueryNode:
 	case *controlJobNode:
 	case *copyNode:
@@ -171,11 +181,21 @@ func applyLimit(plan planNode, numRows int64, soft bool) {
 	case *createIndexNode:
 	case *createUserNode:
 	case *createViewNode:
+	case *createSequenceNode:
+	case *createStatsNode:
+	case *refreshMaterializedViewNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
 	case *dropTableNode:
 	case *dropViewNode:
+	case *dropSequenceNode:
 	case *dropUserNode:
+	case *grantRoleNode:
+	case *revokeRoleNode:
+	case *createFunctionNode:
+	case *dropFunctionNode:
+	case *createTriggerNode:
+	case *dropTriggerNode:
 	case *zeroNode:
 	case *unaryNode:
 	case *hookFnNode:
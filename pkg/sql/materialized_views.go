@@ -0,0 +1,380 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// A materialized view is a view whose results are stored like the rows of a
+// table: its descriptor has both the query of a view and the columns and
+// indexes of a table (with a hidden rowid primary key). Reads scan the stored
+// rows rather than expanding the view's query, and the rows are only
+// recomputed by REFRESH MATERIALIZED VIEW, which runs as a job.
+
+// refreshMaterializedViewNode represents a REFRESH MATERIALIZED VIEW
+// statement.
+type refreshMaterializedViewNode struct {
+	n    *parser.RefreshMaterializedView
+	desc *sqlbase.TableDescriptor
+}
+
+// RefreshMaterializedView recomputes the rows of a materialized view.
+// Privileges: DROP on view.
+//   Notes: postgres requires ownership of the view.
+func (p *planner) RefreshMaterializedView(
+	ctx context.Context, n *parser.RefreshMaterializedView,
+) (planNode, error) {
+	tn, err := p.normalizeTableName(ctx, &n.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	desc, err := MustGetTableOrViewDesc(
+		ctx, p.txn, p.getVirtualTabler(), tn, false /* allowAdding */)
+	if err != nil {
+		return nil, err
+	}
+	if !desc.IsMaterializedView() {
+		return nil, sqlbase.NewWrongObjectTypeError(tn, "materialized view")
+	}
+
+	// Like TRUNCATE, refreshing the view replaces its rows.
+	if err := p.CheckPrivilege(ctx, desc, privilege.DROP); err != nil {
+		return nil, err
+	}
+
+	return &refreshMaterializedViewNode{n: n, desc: desc}, nil
+}
+
+// Start creates a REFRESH MATERIALIZED VIEW job and waits for it to complete.
+// The job runs outside of the user transaction, so that the transaction is not
+// held open while the rows of the view are recomputed; it holds a lease so
+// that it is resumed by another node if this one dies.
+func (n *refreshMaterializedViewNode) Start(params runParams) error {
+	p := params.p
+	execCfg := p.ExecCfg()
+
+	// The job outlives the statement if the client goes away.
+	jobCtx, cancel := context.WithCancel(execCfg.AmbientCtx.AnnotateCtx(context.Background()))
+	job := execCfg.JobRegistry.NewJob(jobs.Record{
+		Description:   n.n.String(),
+		Username:      p.User(),
+		DescriptorIDs: sqlbase.IDs{n.desc.ID},
+		Details:       jobs.RefreshMaterializedViewDetails{TableID: n.desc.ID},
+	})
+	if err := job.Created(params.ctx, cancel); err != nil {
+		cancel()
+		return err
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		defer cancel()
+		errCh <- runRefreshMaterializedViewJob(jobCtx, execCfg, job)
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-params.ctx.Done():
+		return params.ctx.Err()
+	}
+}
+
+func (*refreshMaterializedViewNode) Next(runParams) (bool, error) { return false, nil }
+func (*refreshMaterializedViewNode) Values() parser.Datums        { return parser.Datums{} }
+func (*refreshMaterializedViewNode) Close(context.Context)        {}
+
+// runRefreshMaterializedViewJob runs a REFRESH MATERIALIZED VIEW job that was
+// created on this node and records its outcome.
+func runRefreshMaterializedViewJob(
+	ctx context.Context, execCfg *ExecutorConfig, job *jobs.Job,
+) error {
+	if err := job.Started(ctx); err != nil {
+		return err
+	}
+	refreshErr := refreshMaterializedView(ctx, execCfg, job)
+	if err := job.FinishedWith(ctx, refreshErr); err != nil {
+		return err
+	}
+	return refreshErr
+}
+
+// refreshMaterializedViewResumeHook resumes the REFRESH MATERIALIZED VIEW jobs
+// abandoned by dead nodes.
+func (e *Executor) refreshMaterializedViewResumeHook(
+	typ jobs.Type,
+) func(context.Context, *jobs.Job) error {
+	if typ != jobs.TypeRefreshMaterializedView {
+		return nil
+	}
+	return func(ctx context.Context, job *jobs.Job) error {
+		return refreshMaterializedView(ctx, &e.cfg, job)
+	}
+}
+
+// refreshMaterializedViewChunkSize is the number of rows of a materialized
+// view written per transaction when it is refreshed.
+const refreshMaterializedViewChunkSize = 1000
+
+// refreshMaterializedView replaces the rows of a materialized view with the
+// current results of its query. The new rows are written under a new table ID
+// in a series of transactions; the view is then swapped, in a single
+// transaction, for a descriptor with the new ID, and the old descriptor is
+// dropped. Readers thus see either all the old rows or all the new ones. The
+// old rows are GC-ed later through an asynchronous schema change.
+func refreshMaterializedView(ctx context.Context, execCfg *ExecutorConfig, job *jobs.Job) error {
+	details := job.Record.Details.(jobs.RefreshMaterializedViewDetails)
+	db := execCfg.DB
+
+	var viewDesc *sqlbase.TableDescriptor
+	var swapped bool
+	if err := db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		var err error
+		viewDesc, err = sqlbase.GetTableDescFromID(ctx, txn, details.TableID)
+		if err != nil {
+			return err
+		}
+		swapped = false
+		if details.NewTableID != 0 {
+			// The job was resumed; the interrupted attempt may have swapped the
+			// view already.
+			_, err := sqlbase.GetTableDescFromID(ctx, txn, details.NewTableID)
+			if err == nil {
+				swapped = true
+			} else if err != sqlbase.ErrDescriptorNotFound {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if swapped {
+		return nil
+	}
+	if viewDesc.Dropped() {
+		return errors.Errorf("materialized view %q is being dropped", viewDesc.Name)
+	}
+
+	if details.NewTableID == 0 {
+		newID, err := GenerateUniqueDescID(ctx, db)
+		if err != nil {
+			return err
+		}
+		details.NewTableID = newID
+		if err := job.SetDetails(ctx, details); err != nil {
+			return err
+		}
+	}
+	newDesc := *viewDesc
+	newDesc.ID = details.NewTableID
+
+	if err := backfillMaterializedView(ctx, execCfg, viewDesc, &newDesc); err != nil {
+		return cleanupMaterializedViewRefresh(ctx, db, &newDesc, err)
+	}
+
+	if err := db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		p := makeInternalPlanner(
+			"refresh-materialized-view", txn, security.RootUser, execCfg.LeaseManager.memMetrics,
+		)
+		defer finishInternalPlanner(p)
+		p.session.execCfg = execCfg
+		p.session.tables.leaseMgr = execCfg.LeaseManager
+		if err := txn.SetSystemConfigTrigger(); err != nil {
+			return err
+		}
+		desc, err := sqlbase.GetTableDescFromID(ctx, txn, details.TableID)
+		if err != nil {
+			return err
+		}
+		if desc.Dropped() {
+			return errors.Errorf("materialized view %q is being dropped", desc.Name)
+		}
+		_, err = p.replaceTable(
+			ctx, details.TableID, details.NewTableID, sqlbase.TableDescriptor_PUBLIC, false, /* traceKV */
+		)
+		return err
+	}); err != nil {
+		return cleanupMaterializedViewRefresh(ctx, db, &newDesc, err)
+	}
+
+	// Wait until no one uses the old descriptor, so that the new rows are
+	// visible once the refresh completes.
+	_, err := execCfg.LeaseManager.WaitForOneVersion(ctx, details.TableID, base.DefaultRetryOptions())
+	return err
+}
+
+// cleanupMaterializedViewRefresh deletes the rows written by a refresh that
+// failed with the given error, which is returned.
+func cleanupMaterializedViewRefresh(
+	ctx context.Context, db *client.DB, newDesc *sqlbase.TableDescriptor, refreshErr error,
+) error {
+	if err := truncateTableInChunks(ctx, newDesc, db, false /* traceKV */); err != nil {
+		log.Warningf(ctx, "failed to delete the rows of refreshed materialized view %d: %v",
+			newDesc.ID, err)
+	}
+	return refreshErr
+}
+
+// backfillMaterializedView writes the results of the query of a materialized
+// view under the ID of newDesc. The query is run at a fixed timestamp; the
+// rows are written in chunks of refreshMaterializedViewChunkSize rows.
+func backfillMaterializedView(
+	ctx context.Context, execCfg *ExecutorConfig, viewDesc, newDesc *sqlbase.TableDescriptor,
+) error {
+	db := execCfg.DB
+	readAsOf := execCfg.Clock.Now()
+	return db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		txn.SetFixedTimestamp(readAsOf)
+		// The rows written by a previous attempt, or by an interrupted job, are
+		// cleared.
+		if err := truncateTableInChunks(ctx, newDesc, db, false /* traceKV */); err != nil {
+			return err
+		}
+
+		p := makeInternalPlanner(
+			"refresh-materialized-view", txn, security.RootUser, execCfg.LeaseManager.memMetrics,
+		)
+		defer finishInternalPlanner(p)
+		p.session.execCfg = execCfg
+		p.session.tables.leaseMgr = execCfg.LeaseManager
+		p.evalCtx.NodeID = execCfg.NodeID.Get()
+		// The descriptors used by the query are read at the timestamp of the
+		// transaction.
+		p.avoidCachedDescriptors = true
+
+		var alloc sqlbase.DatumAlloc
+		rows := make([]parser.Datums, 0, refreshMaterializedViewChunkSize)
+		flush := func() error {
+			err := db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+				ri, err := sqlbase.MakeRowInserter(
+					txn, newDesc, nil /* fkTables */, newDesc.Columns, sqlbase.SkipFKs, &p.evalCtx, &alloc,
+				)
+				if err != nil {
+					return err
+				}
+				ti := tableInserter{ri: ri}
+				if err := ti.init(txn); err != nil {
+					return err
+				}
+				defer ti.close(ctx)
+				for _, row := range rows {
+					if _, err := ti.row(ctx, row, false /* traceKV */); err != nil {
+						return err
+					}
+				}
+				_, err = ti.finalize(ctx, false /* traceKV */)
+				return err
+			})
+			rows = rows[:0]
+			return err
+		}
+		if err := p.runMaterializedViewQuery(ctx, viewDesc, func(row parser.Datums) error {
+			rows = append(rows, append(parser.Datums(nil), row...))
+			if len(rows) < refreshMaterializedViewChunkSize {
+				return nil
+			}
+			return flush()
+		}); err != nil {
+			return err
+		}
+		return flush()
+	})
+}
+
+// populateMaterializedView runs the query of a materialized view and stores
+// its results as the rows of the view. It returns the number of rows written.
+func (p *planner) populateMaterializedView(
+	ctx context.Context, desc *sqlbase.TableDescriptor,
+) (int, error) {
+	ri, err := sqlbase.MakeRowInserter(
+		p.txn, desc, nil /* fkTables */, desc.Columns, sqlbase.SkipFKs, &p.evalCtx, &p.alloc,
+	)
+	if err != nil {
+		return 0, err
+	}
+	ti := tableInserter{ri: ri}
+	if err := ti.init(p.txn); err != nil {
+		return 0, err
+	}
+	defer ti.close(ctx)
+
+	traceKV := p.session.Tracing.KVTracingEnabled()
+	count := 0
+	if err := p.runMaterializedViewQuery(ctx, desc, func(row parser.Datums) error {
+		if _, err := ti.row(ctx, row, traceKV); err != nil {
+			return err
+		}
+		count++
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+	if _, err := ti.finalize(ctx, traceKV); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// runMaterializedViewQuery runs the query of a materialized view and calls fn
+// with each of the rows to store in the view. The row passed to fn is only
+// valid until it returns.
+func (p *planner) runMaterializedViewQuery(
+	ctx context.Context, desc *sqlbase.TableDescriptor, fn func(row parser.Datums) error,
+) error {
+	// As when a view is expanded, the SELECT privilege is only required on the
+	// view itself and not on the tables it depends on.
+	defer func(prev bool) { p.skipSelectPrivilegeChecks = prev }(p.skipSelectPrivilegeChecks)
+	p.skipSelectPrivilegeChecks = true
+
+	plan, err := p.query(ctx, desc.ViewQuery)
+	if err != nil {
+		return err
+	}
+	defer plan.Close(ctx)
+	if err := p.startPlan(ctx, plan); err != nil {
+		return err
+	}
+
+	// The columns of the view are followed by the hidden primary key, which
+	// is filled in with its default value.
+	defaultExprs, err := sqlbase.MakeDefaultExprs(desc.Columns, &p.parser, &p.evalCtx)
+	if err != nil {
+		return err
+	}
+	row := make(parser.Datums, len(desc.Columns))
+	return forEachRow(runParams{ctx: ctx, p: p}, plan, func(values parser.Datums) error {
+		copy(row, values)
+		for i := len(values); i < len(row); i++ {
+			d, err := defaultExprs[i].Eval(&p.evalCtx)
+			if err != nil {
+				return err
+			}
+			row[i] = d
+		}
+		return fn(row)
+	})
+}
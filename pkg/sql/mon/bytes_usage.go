@@ -668,3 +668,11 @@ func (mm *BytesMonitor) adjustBudget(ctx context.Context) {
 func (mm *BytesMonitor) GetCurrentAllocationForTesting() int64 {
 	return mm.mu.curAllocated
 }
+
+// MaximumBytes returns the maximum number of bytes that were allocated by this
+// monitor at one time since it was started.
+func (mm *BytesMonitor) MaximumBytes() int64 {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+	return mm.mu.maxAllocated
+}
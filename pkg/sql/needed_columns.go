@@ -64,7 +64,24 @@ func setNeededColumns(plan planNode, needed []bool) {
 		setNeededColumns(n.left, needed)
 		setNeededColumns(n.right, needed)
 
+	case *recursiveCTENode:
+		// The rows produced by each term are fed back into the
+		// recursive term, so all the columns are needed.
+		setNeededColumns(n.initial, allColumns(n.initial))
+		setNeededColumns(n.recursive, allColumns(n.recursive))
+
+	case *spoolNode:
+		setNeededColumns(n.source, allColumns(n.source))
+
 	case *joinNode:
+		if n.lateral != nil {
+			// The left row is needed in full to plan the right side,
+			// which is always planned with all its columns.
+			setNeededColumns(n.left.plan, allColumns(n.left.plan))
+			setNeededColumns(n.right.plan, allColumns(n.right.plan))
+			markOmitted(n.columns, needed)
+			break
+		}
 		// Note: getNeededColumns takes into account both the columns
 		// tested for equality and the join predicate expression.
 		leftNeeded, rightNeeded := n.pred.getNeededColumns(needed)
@@ -184,6 +201,7 @@ func setNeededColumns(plan planNode, needed []bool) {
 		setNeededColumns(n.rows, allColumns(n.rows))
 
 	case *alterTableNode:
+	case *alterSequenceNode:
 	case *cancelQueryNode:
 	case *controlJobNode:
 	case *copyNode:
@@ -191,11 +209,21 @@ func setNeededColumns(plan planNode, needed []bool) {
 	case *createIndexNode:
 	case *createUserNode:
 	case *createViewNode:
+	case *createSequenceNode:
+	case *createStatsNode:
+	case *refreshMaterializedViewNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
 	case *dropTableNode:
 	case *dropViewNode:
+	case *dropSequenceNode:
 	case *dropUserNode:
+	case *grantRoleNode:
+	case *revokeRoleNode:
+	case *createFunctionNode:
+	case *dropFunctionNode:
+	case *createTriggerNode:
+	case *dropTriggerNode:
 	case *zeroNode:
 	case *unaryNode:
 	case *hookFnNode:
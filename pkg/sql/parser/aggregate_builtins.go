@@ -75,6 +75,18 @@ type AggregateFunc interface {
 	Close(context.Context)
 }
 
+// mergeableAggregateFunc is an AggregateFunc whose partial aggregations can
+// be combined. This lets window functions compute the aggregation over a
+// sliding window frame without accumulating every row of every frame.
+type mergeableAggregateFunc interface {
+	AggregateFunc
+
+	// merge accumulates the state of other, which must have been created by the
+	// same constructor as the receiver, as if all the datums passed to other's
+	// Add had been passed to the receiver's Add. other is left unmodified.
+	merge(ctx context.Context, other AggregateFunc) error
+}
+
 // Aggregates are a special class of builtin functions that are wrapped
 // at execution in a bucketing layer to combine (aggregate) the result
 // of the function being run over many rows.
@@ -145,12 +157,38 @@ var Aggregates = map[string][]Builtin{
 			ReturnType:    fixedReturnType(TypeInt),
 			AggregateFunc: newCountRowsAggregate,
 			WindowFunc: func(params []Type, evalCtx *EvalContext) WindowFunc {
-				return newAggregateWindow(newCountRowsAggregate(params, evalCtx))
+				return newAggregateWindow(func() AggregateFunc {
+					return newCountRowsAggregate(params, evalCtx)
+				})
 			},
 			Info: "Calculates the number of rows.",
 		},
 	},
 
+	"grouping": {
+		{
+			impure:       true,
+			class:        AggregateClass,
+			Types:        VariadicType{Typ: TypeAny},
+			ReturnType:   fixedReturnType(TypeInt),
+			nullableArgs: true,
+			// The result of GROUPING() is computed by the GROUP BY machinery for
+			// each grouping set and passed to the aggregate, which returns it
+			// unchanged.
+			AggregateFunc: func([]Type, *EvalContext) AggregateFunc {
+				return NewIdentAggregate(nil)
+			},
+			WindowFunc: func([]Type, *EvalContext) WindowFunc {
+				return newAggregateWindow(func() AggregateFunc {
+					return NewIdentAggregate(nil)
+				})
+			},
+			Info: "Returns a bit mask indicating which of the arguments are not part of " +
+				"the current grouping set. The last argument corresponds to the least " +
+				"significant bit.",
+		},
+	},
+
 	"max": collectBuiltins(func(t Type) Builtin {
 		return makeAggBuiltin(t, t, newMaxAggregate,
 			"Identifies the maximum selected value.")
@@ -218,7 +256,9 @@ func makeAggBuiltinWithReturnType(
 		ReturnType:    retType,
 		AggregateFunc: f,
 		WindowFunc: func(params []Type, evalCtx *EvalContext) WindowFunc {
-			return newAggregateWindow(f(params, evalCtx))
+			return newAggregateWindow(func() AggregateFunc {
+				return f(params, evalCtx)
+			})
 		},
 		Info: info,
 	}
@@ -241,6 +281,27 @@ var _ AggregateFunc = &concatAggregate{}
 var _ AggregateFunc = &bytesXorAggregate{}
 var _ AggregateFunc = &intXorAggregate{}
 
+var _ mergeableAggregateFunc = &arrayAggregate{}
+var _ mergeableAggregateFunc = &avgAggregate{}
+var _ mergeableAggregateFunc = &concatAggregate{}
+var _ mergeableAggregateFunc = &boolAndAggregate{}
+var _ mergeableAggregateFunc = &boolOrAggregate{}
+var _ mergeableAggregateFunc = &countAggregate{}
+var _ mergeableAggregateFunc = &countRowsAggregate{}
+var _ mergeableAggregateFunc = &MaxAggregate{}
+var _ mergeableAggregateFunc = &MinAggregate{}
+var _ mergeableAggregateFunc = &smallIntSumAggregate{}
+var _ mergeableAggregateFunc = &intSumAggregate{}
+var _ mergeableAggregateFunc = &decimalSumAggregate{}
+var _ mergeableAggregateFunc = &floatSumAggregate{}
+var _ mergeableAggregateFunc = &intervalSumAggregate{}
+var _ mergeableAggregateFunc = &intVarianceAggregate{}
+var _ mergeableAggregateFunc = &floatVarianceAggregate{}
+var _ mergeableAggregateFunc = &decimalVarianceAggregate{}
+var _ mergeableAggregateFunc = &stdDevAggregate{}
+var _ mergeableAggregateFunc = &bytesXorAggregate{}
+var _ mergeableAggregateFunc = &intXorAggregate{}
+
 // In order to render the unaggregated (i.e. grouped) fields, during aggregation,
 // the values for those fields have to be stored for each bucket.
 // The `identAggregate` provides an "aggregate" function that actually
@@ -308,10 +369,22 @@ func (a *arrayAggregate) Add(ctx context.Context, datum Datum) error {
 	return nil
 }
 
+func (a *arrayAggregate) merge(ctx context.Context, other AggregateFunc) error {
+	for _, datum := range other.(*arrayAggregate).arr.Array {
+		if err := a.Add(ctx, datum); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Result returns an array of all datums passed to Add.
 func (a *arrayAggregate) Result() (Datum, error) {
 	if len(a.arr.Array) > 0 {
-		return a.arr, nil
+		// Copy the array so that it is not modified by further calls to Add.
+		res := *a.arr
+		res.Array = append(Datums(nil), a.arr.Array...)
+		return &res, nil
 	}
 	return DNull, nil
 }
@@ -349,6 +422,15 @@ func (a *avgAggregate) Add(ctx context.Context, datum Datum) error {
 	return nil
 }
 
+func (a *avgAggregate) merge(ctx context.Context, other AggregateFunc) error {
+	o := other.(*avgAggregate)
+	if err := a.agg.(mergeableAggregateFunc).merge(ctx, o.agg); err != nil {
+		return err
+	}
+	a.count += o.count
+	return nil
+}
+
 // Result returns the average of all datums passed to Add.
 func (a *avgAggregate) Result() (Datum, error) {
 	sum, err := a.agg.Result()
@@ -408,6 +490,19 @@ func (a *concatAggregate) Add(ctx context.Context, datum Datum) error {
 	return nil
 }
 
+func (a *concatAggregate) merge(ctx context.Context, other AggregateFunc) error {
+	o := other.(*concatAggregate)
+	if !o.sawNonNull {
+		return nil
+	}
+	if err := a.acc.Grow(ctx, int64(o.result.Len())); err != nil {
+		return err
+	}
+	a.sawNonNull = true
+	a.result.Write(o.result.Bytes())
+	return nil
+}
+
 func (a *concatAggregate) Result() (Datum, error) {
 	if !a.sawNonNull {
 		return DNull, nil
@@ -447,6 +542,13 @@ func (a *boolAndAggregate) Add(_ context.Context, datum Datum) error {
 	return nil
 }
 
+func (a *boolAndAggregate) merge(ctx context.Context, other AggregateFunc) error {
+	if o := other.(*boolAndAggregate); o.sawNonNull {
+		return a.Add(ctx, MakeDBool(DBool(o.result)))
+	}
+	return nil
+}
+
 func (a *boolAndAggregate) Result() (Datum, error) {
 	if !a.sawNonNull {
 		return DNull, nil
@@ -475,6 +577,13 @@ func (a *boolOrAggregate) Add(_ context.Context, datum Datum) error {
 	return nil
 }
 
+func (a *boolOrAggregate) merge(ctx context.Context, other AggregateFunc) error {
+	if o := other.(*boolOrAggregate); o.sawNonNull {
+		return a.Add(ctx, MakeDBool(DBool(o.result)))
+	}
+	return nil
+}
+
 func (a *boolOrAggregate) Result() (Datum, error) {
 	if !a.sawNonNull {
 		return DNull, nil
@@ -501,6 +610,11 @@ func (a *countAggregate) Add(_ context.Context, datum Datum) error {
 	return nil
 }
 
+func (a *countAggregate) merge(_ context.Context, other AggregateFunc) error {
+	a.count += other.(*countAggregate).count
+	return nil
+}
+
 func (a *countAggregate) Result() (Datum, error) {
 	return NewDInt(DInt(a.count)), nil
 }
@@ -521,6 +635,11 @@ func (a *countRowsAggregate) Add(_ context.Context, _ Datum) error {
 	return nil
 }
 
+func (a *countRowsAggregate) merge(_ context.Context, other AggregateFunc) error {
+	a.count += other.(*countRowsAggregate).count
+	return nil
+}
+
 func (a *countRowsAggregate) Result() (Datum, error) {
 	return NewDInt(DInt(a.count)), nil
 }
@@ -554,6 +673,13 @@ func (a *MaxAggregate) Add(_ context.Context, datum Datum) error {
 	return nil
 }
 
+func (a *MaxAggregate) merge(ctx context.Context, other AggregateFunc) error {
+	if o := other.(*MaxAggregate); o.max != nil {
+		return a.Add(ctx, o.max)
+	}
+	return nil
+}
+
 // Result returns the largest value passed to Add.
 func (a *MaxAggregate) Result() (Datum, error) {
 	if a.max == nil {
@@ -591,6 +717,13 @@ func (a *MinAggregate) Add(_ context.Context, datum Datum) error {
 	return nil
 }
 
+func (a *MinAggregate) merge(ctx context.Context, other AggregateFunc) error {
+	if o := other.(*MinAggregate); o.min != nil {
+		return a.Add(ctx, o.min)
+	}
+	return nil
+}
+
 // Result returns the smallest value passed to Add.
 func (a *MinAggregate) Result() (Datum, error) {
 	if a.min == nil {
@@ -622,6 +755,14 @@ func (a *smallIntSumAggregate) Add(_ context.Context, datum Datum) error {
 	return nil
 }
 
+func (a *smallIntSumAggregate) merge(_ context.Context, other AggregateFunc) error {
+	if o := other.(*smallIntSumAggregate); o.seenNonNull {
+		a.sum += o.sum
+		a.seenNonNull = true
+	}
+	return nil
+}
+
 // Result returns the sum.
 func (a *smallIntSumAggregate) Result() (Datum, error) {
 	if !a.seenNonNull {
@@ -684,6 +825,31 @@ func (a *intSumAggregate) Add(_ context.Context, datum Datum) error {
 	return nil
 }
 
+func (a *intSumAggregate) merge(_ context.Context, other AggregateFunc) error {
+	o := other.(*intSumAggregate)
+	if !o.seenNonNull {
+		return nil
+	}
+	a.seenNonNull = true
+	if !a.large && !o.large {
+		if r, ok := addWithOverflow(a.intSum, o.intSum); ok {
+			a.intSum = r
+			return nil
+		}
+	}
+	if !a.large {
+		a.large = true
+		a.decSum.SetCoefficient(a.intSum)
+	}
+	if o.large {
+		a.tmpDec.Set(&o.decSum.Decimal)
+	} else {
+		a.tmpDec.SetCoefficient(o.intSum)
+	}
+	_, err := ExactCtx.Add(&a.decSum.Decimal, &a.decSum.Decimal, &a.tmpDec)
+	return err
+}
+
 // Result returns the sum.
 func (a *intSumAggregate) Result() (Datum, error) {
 	if !a.seenNonNull {
@@ -724,6 +890,18 @@ func (a *decimalSumAggregate) Add(_ context.Context, datum Datum) error {
 	return nil
 }
 
+func (a *decimalSumAggregate) merge(_ context.Context, other AggregateFunc) error {
+	o := other.(*decimalSumAggregate)
+	if !o.sawNonNull {
+		return nil
+	}
+	if _, err := ExactCtx.Add(&a.sum, &a.sum, &o.sum); err != nil {
+		return err
+	}
+	a.sawNonNull = true
+	return nil
+}
+
 // Result returns the sum.
 func (a *decimalSumAggregate) Result() (Datum, error) {
 	if !a.sawNonNull {
@@ -757,6 +935,14 @@ func (a *floatSumAggregate) Add(_ context.Context, datum Datum) error {
 	return nil
 }
 
+func (a *floatSumAggregate) merge(_ context.Context, other AggregateFunc) error {
+	if o := other.(*floatSumAggregate); o.sawNonNull {
+		a.sum += o.sum
+		a.sawNonNull = true
+	}
+	return nil
+}
+
 // Result returns the sum.
 func (a *floatSumAggregate) Result() (Datum, error) {
 	if !a.sawNonNull {
@@ -788,6 +974,14 @@ func (a *intervalSumAggregate) Add(_ context.Context, datum Datum) error {
 	return nil
 }
 
+func (a *intervalSumAggregate) merge(_ context.Context, other AggregateFunc) error {
+	if o := other.(*intervalSumAggregate); o.sawNonNull {
+		a.sum = a.sum.Add(o.sum)
+		a.sawNonNull = true
+	}
+	return nil
+}
+
 // Result returns the sum.
 func (a *intervalSumAggregate) Result() (Datum, error) {
 	if !a.sawNonNull {
@@ -820,6 +1014,10 @@ func (a *intVarianceAggregate) Add(ctx context.Context, datum Datum) error {
 	return a.agg.Add(ctx, &a.tmpDec)
 }
 
+func (a *intVarianceAggregate) merge(ctx context.Context, other AggregateFunc) error {
+	return a.agg.merge(ctx, other.(*intVarianceAggregate).agg)
+}
+
 func (a *intVarianceAggregate) Result() (Datum, error) {
 	return a.agg.Result()
 }
@@ -853,6 +1051,23 @@ func (a *floatVarianceAggregate) Add(_ context.Context, datum Datum) error {
 	return nil
 }
 
+func (a *floatVarianceAggregate) merge(_ context.Context, other AggregateFunc) error {
+	o := other.(*floatVarianceAggregate)
+	if o.count == 0 {
+		return nil
+	}
+
+	// Uses the parallel algorithm of Chan et al. to combine the two partial
+	// results. See
+	// https://en.wikipedia.org/wiki/Algorithms_for_calculating_variance#Parallel_algorithm.
+	count := a.count + o.count
+	delta := o.mean - a.mean
+	a.sqrDiff += o.sqrDiff + delta*delta*float64(a.count)*float64(o.count)/float64(count)
+	a.mean += delta * float64(o.count) / float64(count)
+	a.count = count
+	return nil
+}
+
 func (a *floatVarianceAggregate) Result() (Datum, error) {
 	if a.count < 2 {
 		return DNull, nil
@@ -916,6 +1131,32 @@ func (a *decimalVarianceAggregate) Add(_ context.Context, datum Datum) error {
 	return a.ed.Err()
 }
 
+func (a *decimalVarianceAggregate) merge(_ context.Context, other AggregateFunc) error {
+	o := other.(*decimalVarianceAggregate)
+	if o.count.Sign() == 0 {
+		return nil
+	}
+
+	// Uses the parallel algorithm of Chan et al. to combine the two partial
+	// results. See
+	// https://en.wikipedia.org/wiki/Algorithms_for_calculating_variance#Parallel_algorithm.
+	var count apd.Decimal
+	a.ed.Add(&count, &a.count, &o.count)
+	a.ed.Sub(&a.delta, &o.mean, &a.mean)
+	a.ed.Mul(&a.tmp, &a.delta, &a.delta)
+	a.ed.Mul(&a.tmp, &a.tmp, &a.count)
+	a.ed.Mul(&a.tmp, &a.tmp, &o.count)
+	a.ed.Quo(&a.tmp, &a.tmp, &count)
+	a.ed.Add(&a.sqrDiff, &a.sqrDiff, &a.tmp)
+	a.ed.Add(&a.sqrDiff, &a.sqrDiff, &o.sqrDiff)
+	a.ed.Mul(&a.tmp, &a.delta, &o.count)
+	a.ed.Quo(&a.tmp, &a.tmp, &count)
+	a.ed.Add(&a.mean, &a.mean, &a.tmp)
+	a.count.Set(&count)
+
+	return a.ed.Err()
+}
+
 func (a *decimalVarianceAggregate) Result() (Datum, error) {
 	if a.count.Cmp(decimalTwo) < 0 {
 		return DNull, nil
@@ -956,6 +1197,10 @@ func (a *stdDevAggregate) Add(ctx context.Context, datum Datum) error {
 	return a.agg.Add(ctx, datum)
 }
 
+func (a *stdDevAggregate) merge(ctx context.Context, other AggregateFunc) error {
+	return a.agg.(mergeableAggregateFunc).merge(ctx, other.(*stdDevAggregate).agg)
+}
+
 // Result computes the square root of the variance.
 func (a *stdDevAggregate) Result() (Datum, error) {
 	variance, err := a.agg.Result()
@@ -1008,6 +1253,13 @@ func (a *bytesXorAggregate) Add(_ context.Context, datum Datum) error {
 	return nil
 }
 
+func (a *bytesXorAggregate) merge(ctx context.Context, other AggregateFunc) error {
+	if o := other.(*bytesXorAggregate); o.sawNonNull {
+		return a.Add(ctx, NewDBytes(DBytes(o.sum)))
+	}
+	return nil
+}
+
 // Result returns the xor.
 func (a *bytesXorAggregate) Result() (Datum, error) {
 	if !a.sawNonNull {
@@ -1039,6 +1291,14 @@ func (a *intXorAggregate) Add(_ context.Context, datum Datum) error {
 	return nil
 }
 
+func (a *intXorAggregate) merge(_ context.Context, other AggregateFunc) error {
+	if o := other.(*intXorAggregate); o.sawNonNull {
+		a.sum = a.sum ^ o.sum
+		a.sawNonNull = true
+	}
+	return nil
+}
+
 // Result returns the xor.
 func (a *intXorAggregate) Result() (Datum, error) {
 	if !a.sawNonNull {
@@ -16,6 +16,7 @@ package parser
 
 import (
 	"fmt"
+	"math"
 	"reflect"
 	"testing"
 
@@ -37,6 +38,7 @@ func testAggregateResultDeepCopy(
 	evalCtx := NewTestingEvalContext()
 	defer evalCtx.Stop(context.Background())
 	aggImpl := aggFunc([]Type{vals[0].ResolvedType()}, evalCtx)
+	defer aggImpl.Close(context.Background())
 	runningDatums := make([]Datum, len(vals))
 	runningStrings := make([]string, len(vals))
 	for i := range vals {
@@ -160,6 +162,163 @@ func TestStdDevDecimalResultDeepCopy(t *testing.T) {
 	testAggregateResultDeepCopy(t, newDecimalStdDevAggregate, makeDecimalTestDatum(10))
 }
 
+func TestArrayAggResultDeepCopy(t *testing.T) {
+	testAggregateResultDeepCopy(t, newArrayAggregate, makeIntTestDatum(10))
+}
+
+// aggregateResultsEqual returns whether two results of an aggregation are
+// equal, allowing for the rounding errors of floating point computations
+// performed in a different order.
+func aggregateResultsEqual(evalCtx *EvalContext, a, b Datum) bool {
+	if fa, ok := a.(*DFloat); ok {
+		if fb, ok := b.(*DFloat); ok {
+			return math.Abs(float64(*fa-*fb)) <= 1e-9*math.Max(1, math.Abs(float64(*fb)))
+		}
+	}
+	if da, ok := a.(*DDecimal); ok {
+		if db, ok := b.(*DDecimal); ok {
+			fa, _ := da.Float64()
+			fb, _ := db.Float64()
+			return math.Abs(fa-fb) <= 1e-9*math.Max(1, math.Abs(fb))
+		}
+	}
+	return a.Compare(evalCtx, b) == 0
+}
+
+// testAggregateMerge verifies that merging the aggregations of two
+// consecutive parts of a set of values gives the same result as
+// accumulating all the values into a single aggregation.
+func testAggregateMerge(
+	t *testing.T, aggFunc func([]Type, *EvalContext) AggregateFunc, vals []Datum,
+) {
+	ctx := context.Background()
+	evalCtx := NewTestingEvalContext()
+	defer evalCtx.Stop(ctx)
+	params := []Type{vals[0].ResolvedType()}
+
+	accumulate := func(vals []Datum) AggregateFunc {
+		agg := aggFunc(params, evalCtx)
+		for _, val := range vals {
+			if err := agg.Add(ctx, val); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return agg
+	}
+	all := accumulate(vals)
+	defer all.Close(ctx)
+	expected, err := all.Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, split := range []int{0, 1, len(vals) / 2, len(vals)} {
+		left, right := accumulate(vals[:split]), accumulate(vals[split:])
+		if err := left.(mergeableAggregateFunc).merge(ctx, right); err != nil {
+			t.Fatal(err)
+		}
+		res, err := left.Result()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !aggregateResultsEqual(evalCtx, res, expected) {
+			t.Errorf("split at %d: expected %s, but found %s", split, expected, res)
+		}
+		left.Close(ctx)
+		right.Close(ctx)
+	}
+}
+
+func TestAggregateMerge(t *testing.T) {
+	nullVals := []Datum{DNull, NewDInt(1), DNull}
+	testData := []struct {
+		name    string
+		aggFunc func([]Type, *EvalContext) AggregateFunc
+		vals    []Datum
+	}{
+		{"array_agg", newArrayAggregate, makeIntTestDatum(10)},
+		{"avg int", newIntAvgAggregate, makeIntTestDatum(10)},
+		{"avg float", newFloatAvgAggregate, makeFloatTestDatum(10)},
+		{"avg decimal", newDecimalAvgAggregate, makeDecimalTestDatum(10)},
+		{"bool_and", newBoolAndAggregate, makeBoolTestDatum(10)},
+		{"bool_or", newBoolOrAggregate, makeBoolTestDatum(10)},
+		{"concat_agg", newStringConcatAggregate,
+			[]Datum{NewDString("a"), DNull, NewDString("b"), NewDString("c")}},
+		{"count", newCountAggregate, nullVals},
+		{"count_rows", newCountRowsAggregate, nullVals},
+		{"max", newMaxAggregate, makeDecimalTestDatum(10)},
+		{"min", newMinAggregate, makeFloatTestDatum(10)},
+		{"sum_int", newSmallIntSumAggregate, makeSmallIntTestDatum(10)},
+		{"sum int", newIntSumAggregate, makeIntTestDatum(10)},
+		{"sum int nulls", newIntSumAggregate, nullVals},
+		{"sum float", newFloatSumAggregate, makeFloatTestDatum(10)},
+		{"sum decimal", newDecimalSumAggregate, makeDecimalTestDatum(10)},
+		{"sum interval", newIntervalSumAggregate, makeIntervalTestDatum(10)},
+		{"variance int", newIntVarianceAggregate, makeIntTestDatum(10)},
+		{"variance float", newFloatVarianceAggregate, makeFloatTestDatum(10)},
+		{"variance decimal", newDecimalVarianceAggregate, makeDecimalTestDatum(10)},
+		{"stddev float", newFloatStdDevAggregate, makeFloatTestDatum(10)},
+		{"xor_agg", newIntXorAggregate, makeIntTestDatum(10)},
+		{"xor_agg bytes", newBytesXorAggregate,
+			[]Datum{NewDBytes("ab"), NewDBytes("cd"), DNull, NewDBytes("ef")}},
+	}
+	for _, d := range testData {
+		t.Run(d.name, func(t *testing.T) {
+			testAggregateMerge(t, d.aggFunc, d.vals)
+		})
+	}
+}
+
+// TestAggregateSegmentTree verifies that aggregating ranges of rows with an
+// aggregateSegmentTree gives the same result as accumulating the rows of the
+// range in order.
+func TestAggregateSegmentTree(t *testing.T) {
+	ctx := context.Background()
+	evalCtx := NewTestingEvalContext()
+	defer evalCtx.Stop(ctx)
+
+	rng, _ := randutil.NewPseudoRand()
+	for _, rowCount := range []int{0, 1, 15, 16, 17, 300} {
+		wf := WindowFrameRun{ArgCount: 1, Rows: make([]IndexedRow, rowCount)}
+		for i := range wf.Rows {
+			wf.Rows[i] = IndexedRow{Idx: i, Row: Datums{NewDInt(DInt(rng.Int63n(1000)))}}
+		}
+		for _, aggFunc := range []func([]Type, *EvalContext) AggregateFunc{
+			newIntSumAggregate, newArrayAggregate,
+		} {
+			newAgg := func() AggregateFunc { return aggFunc([]Type{TypeInt}, evalCtx) }
+			tree, err := makeAggregateSegmentTree(ctx, newAgg, wf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 100; i++ {
+				start := rng.Intn(rowCount + 1)
+				end := start + rng.Intn(rowCount-start+1)
+				res, err := tree.aggregate(ctx, start, end)
+				if err != nil {
+					t.Fatal(err)
+				}
+				agg := newAgg()
+				for j := start; j < end; j++ {
+					if err := agg.Add(ctx, wf.Rows[j].Row[0]); err != nil {
+						t.Fatal(err)
+					}
+				}
+				expected, err := agg.Result()
+				if err != nil {
+					t.Fatal(err)
+				}
+				agg.Close(ctx)
+				if res.Compare(evalCtx, expected) != 0 {
+					t.Errorf("%d rows: expected %s for [%d, %d), but found %s",
+						rowCount, expected, start, end, res)
+				}
+			}
+			tree.close(ctx)
+		}
+	}
+}
+
 func makeIntTestDatum(count int) []Datum {
 	rng, _ := randutil.NewPseudoRand()
 
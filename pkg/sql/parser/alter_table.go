@@ -60,18 +60,24 @@ type AlterTableCmd interface {
 
 func (*AlterTableAddColumn) alterTableCmd()          {}
 func (*AlterTableAddConstraint) alterTableCmd()      {}
+func (*AlterTableAlterColumnType) alterTableCmd()    {}
+func (*AlterTableAlterConstraint) alterTableCmd()    {}
 func (*AlterTableDropColumn) alterTableCmd()         {}
 func (*AlterTableDropConstraint) alterTableCmd()     {}
 func (*AlterTableDropNotNull) alterTableCmd()        {}
 func (*AlterTableSetDefault) alterTableCmd()         {}
+func (*AlterTableSetNotNull) alterTableCmd()         {}
 func (*AlterTableValidateConstraint) alterTableCmd() {}
 
 var _ AlterTableCmd = &AlterTableAddColumn{}
 var _ AlterTableCmd = &AlterTableAddConstraint{}
+var _ AlterTableCmd = &AlterTableAlterColumnType{}
+var _ AlterTableCmd = &AlterTableAlterConstraint{}
 var _ AlterTableCmd = &AlterTableDropColumn{}
 var _ AlterTableCmd = &AlterTableDropConstraint{}
 var _ AlterTableCmd = &AlterTableDropNotNull{}
 var _ AlterTableCmd = &AlterTableSetDefault{}
+var _ AlterTableCmd = &AlterTableSetNotNull{}
 var _ AlterTableCmd = &AlterTableValidateConstraint{}
 
 // ColumnMutationCmd is the subset of AlterTableCmds that modify an
@@ -178,6 +184,24 @@ func (node *AlterTableValidateConstraint) Format(buf *bytes.Buffer, f FmtFlags)
 	FormatNode(buf, f, node.Constraint)
 }
 
+// AlterTableAlterConstraint represents an ALTER CONSTRAINT command, which
+// marks a constraint as validated or NOT VALID.
+type AlterTableAlterConstraint struct {
+	Constraint         Name
+	ValidationBehavior ValidationBehavior
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTableAlterConstraint) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("ALTER CONSTRAINT ")
+	FormatNode(buf, f, node.Constraint)
+	if node.ValidationBehavior == ValidationSkip {
+		buf.WriteString(" NOT VALID")
+	} else {
+		buf.WriteString(" VALID")
+	}
+}
+
 // AlterTableSetDefault represents an ALTER COLUMN SET DEFAULT
 // or DROP DEFAULT command.
 type AlterTableSetDefault struct {
@@ -227,3 +251,53 @@ func (node *AlterTableDropNotNull) Format(buf *bytes.Buffer, f FmtFlags) {
 	FormatNode(buf, f, node.Column)
 	buf.WriteString(" DROP NOT NULL")
 }
+
+// AlterTableSetNotNull represents an ALTER COLUMN SET NOT NULL
+// command.
+type AlterTableSetNotNull struct {
+	columnKeyword bool
+	Column        Name
+}
+
+// GetColumn implements the ColumnMutationCmd interface.
+func (node *AlterTableSetNotNull) GetColumn() Name {
+	return node.Column
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTableSetNotNull) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("ALTER ")
+	if node.columnKeyword {
+		buf.WriteString("COLUMN ")
+	}
+	FormatNode(buf, f, node.Column)
+	buf.WriteString(" SET NOT NULL")
+}
+
+// AlterTableAlterColumnType represents an ALTER COLUMN TYPE command.
+type AlterTableAlterColumnType struct {
+	columnKeyword bool
+	Column        Name
+	ToType        ColumnType
+	Using         Expr
+}
+
+// GetColumn implements the ColumnMutationCmd interface.
+func (node *AlterTableAlterColumnType) GetColumn() Name {
+	return node.Column
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTableAlterColumnType) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("ALTER ")
+	if node.columnKeyword {
+		buf.WriteString("COLUMN ")
+	}
+	FormatNode(buf, f, node.Column)
+	buf.WriteString(" TYPE ")
+	FormatNode(buf, f, node.ToType)
+	if node.Using != nil {
+		buf.WriteString(" USING ")
+		FormatNode(buf, f, node.Using)
+	}
+}
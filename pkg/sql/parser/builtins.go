@@ -43,6 +43,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
@@ -82,10 +83,14 @@ const (
 	categoryCompatibility = "Compatibility"
 	categoryDateAndTime   = "Date and Time"
 	categoryIDGeneration  = "ID Generation"
+	categoryIPAddress     = "IP address"
 	categoryMath          = "Math and Numeric"
 	categoryString        = "String and Byte"
 	categoryArray         = "Array"
+	categoryJSON          = "JSONB"
+	categorySequences     = "Sequence"
 	categorySystemInfo    = "System Info"
+	categoryUserDefined   = "User-defined"
 )
 
 // Builtin is a built-in function.
@@ -306,7 +311,7 @@ var Builtins = map[string][]Builtin{
 	// NULL arguments are ignored.
 	"concat": {
 		Builtin{
-			Types:        VariadicType{TypeString},
+			Types:        VariadicType{Typ: TypeString},
 			ReturnType:   fixedReturnType(TypeString),
 			nullableArgs: true,
 			fn: func(evalCtx *EvalContext, args Datums) (Datum, error) {
@@ -329,7 +334,7 @@ var Builtins = map[string][]Builtin{
 
 	"concat_ws": {
 		Builtin{
-			Types:        VariadicType{TypeString},
+			Types:        VariadicType{Typ: TypeString},
 			ReturnType:   fixedReturnType(TypeString),
 			nullableArgs: true,
 			fn: func(evalCtx *EvalContext, args Datums) (Datum, error) {
@@ -910,6 +915,101 @@ CockroachDB supports the following flags:
 	"experimental_uuid_v4": {uuidV4Impl},
 	"uuid_v4":              {uuidV4Impl},
 
+	// Sequence functions.
+
+	"nextval": {
+		Builtin{
+			Types:                   ArgTypes{{"sequence_name", TypeString}},
+			ReturnType:              fixedReturnType(TypeInt),
+			category:                categorySequences,
+			impure:                  true,
+			needsRepeatedEvaluation: true,
+			distsqlBlacklist:        true,
+			fn: func(ctx *EvalContext, args Datums) (Datum, error) {
+				qualifiedName, err := evalSequenceName(ctx, args[0])
+				if err != nil {
+					return nil, err
+				}
+				res, err := ctx.Planner.IncrementSequence(ctx.Ctx(), qualifiedName)
+				if err != nil {
+					return nil, err
+				}
+				return NewDInt(DInt(res)), nil
+			},
+			Info: "Advances the given sequence and returns its new value.",
+		},
+	},
+
+	"currval": {
+		Builtin{
+			Types:            ArgTypes{{"sequence_name", TypeString}},
+			ReturnType:       fixedReturnType(TypeInt),
+			category:         categorySequences,
+			impure:           true,
+			distsqlBlacklist: true,
+			fn: func(ctx *EvalContext, args Datums) (Datum, error) {
+				qualifiedName, err := evalSequenceName(ctx, args[0])
+				if err != nil {
+					return nil, err
+				}
+				res, err := ctx.Planner.GetLatestValueInSessionForSequence(ctx.Ctx(), qualifiedName)
+				if err != nil {
+					return nil, err
+				}
+				return NewDInt(DInt(res)), nil
+			},
+			Info: "Returns the latest value obtained with nextval for this sequence in this session.",
+		},
+	},
+
+	"setval": {
+		Builtin{
+			Types:            ArgTypes{{"sequence_name", TypeString}, {"value", TypeInt}},
+			ReturnType:       fixedReturnType(TypeInt),
+			category:         categorySequences,
+			impure:           true,
+			distsqlBlacklist: true,
+			fn: func(ctx *EvalContext, args Datums) (Datum, error) {
+				qualifiedName, err := evalSequenceName(ctx, args[0])
+				if err != nil {
+					return nil, err
+				}
+				newVal := MustBeDInt(args[1])
+				if err := ctx.Planner.SetSequenceValue(
+					ctx.Ctx(), qualifiedName, int64(newVal), true /* isCalled */); err != nil {
+					return nil, err
+				}
+				return args[1], nil
+			},
+			Info: "Set the given sequence's current value. The next call to nextval will return " +
+				"`value + Increment`.",
+		},
+		Builtin{
+			Types: ArgTypes{
+				{"sequence_name", TypeString}, {"value", TypeInt}, {"is_called", TypeBool},
+			},
+			ReturnType:       fixedReturnType(TypeInt),
+			category:         categorySequences,
+			impure:           true,
+			distsqlBlacklist: true,
+			fn: func(ctx *EvalContext, args Datums) (Datum, error) {
+				qualifiedName, err := evalSequenceName(ctx, args[0])
+				if err != nil {
+					return nil, err
+				}
+				isCalled := bool(*args[2].(*DBool))
+				newVal := MustBeDInt(args[1])
+				if err := ctx.Planner.SetSequenceValue(
+					ctx.Ctx(), qualifiedName, int64(newVal), isCalled); err != nil {
+					return nil, err
+				}
+				return args[1], nil
+			},
+			Info: "Set the given sequence's current value. If is_called is false, the next call to " +
+				"nextval will return `value`; otherwise `value + Increment`.",
+		},
+	},
+
 	"greatest": {
 		Builtin{
 			Types:        HomogeneousType{},
@@ -1128,6 +1228,21 @@ CockroachDB supports the following flags:
 				"Compatible elements: year, quarter, month, week, dayofweek, dayofyear,\n" +
 				"hour, minute, second, millisecond, microsecond, epoch",
 		},
+		Builtin{
+			Types:      ArgTypes{{"element", TypeString}, {"input", TypeTimestampTZ}},
+			ReturnType: fixedReturnType(TypeInt),
+			category:   categoryDateAndTime,
+			fn: func(ctx *EvalContext, args Datums) (Datum, error) {
+				// The fields are extracted from the wall clock time in the session
+				// time zone.
+				fromTime := args[1].(*DTimestampTZ).Time.In(ctx.GetLocation())
+				timeSpan := strings.ToLower(string(MustBeDString(args[0])))
+				return extractStringFromTimestamp(ctx, fromTime, timeSpan)
+			},
+			Info: "Extracts `element` from `input` in the session time zone.\n\n" +
+				"Compatible elements: year, quarter, month, week, dayofweek, dayofyear,\n" +
+				"hour, minute, second, millisecond, microsecond, epoch",
+		},
 	},
 
 	"extract_duration": {
@@ -1166,6 +1281,124 @@ CockroachDB supports the following flags:
 		},
 	},
 
+	"date_trunc": {
+		Builtin{
+			Types:      ArgTypes{{"element", TypeString}, {"input", TypeTimestamp}},
+			ReturnType: fixedReturnType(TypeTimestamp),
+			category:   categoryDateAndTime,
+			fn: func(ctx *EvalContext, args Datums) (Datum, error) {
+				fromTime := args[1].(*DTimestamp).Time
+				timeSpan := strings.ToLower(string(MustBeDString(args[0])))
+				t, err := truncateTimestamp(ctx, fromTime, timeSpan)
+				if err != nil {
+					return nil, err
+				}
+				return MakeDTimestamp(t, time.Microsecond), nil
+			},
+			Info: "Truncates `input` to precision `element`, setting all less significant\n" +
+				"fields to their lowest value.\n\n" +
+				"Compatible elements: millennium, century, decade, year, quarter, month,\n" +
+				"week, day, hour, minute, second, millisecond, microsecond",
+		},
+		Builtin{
+			Types:      ArgTypes{{"element", TypeString}, {"input", TypeDate}},
+			ReturnType: fixedReturnType(TypeTimestampTZ),
+			category:   categoryDateAndTime,
+			fn: func(ctx *EvalContext, args Datums) (Datum, error) {
+				date := args[1].(*DDate)
+				fromTSTZ := MakeDTimestampTZFromDate(ctx.GetLocation(), date)
+				timeSpan := strings.ToLower(string(MustBeDString(args[0])))
+				t, err := truncateTimestamp(ctx, fromTSTZ.Time, timeSpan)
+				if err != nil {
+					return nil, err
+				}
+				return MakeDTimestampTZ(t, time.Microsecond), nil
+			},
+			Info: "Truncates `input` to precision `element` in the session time zone, setting\n" +
+				"all less significant fields to their lowest value.\n\n" +
+				"Compatible elements: millennium, century, decade, year, quarter, month,\n" +
+				"week, day, hour, minute, second, millisecond, microsecond",
+		},
+		Builtin{
+			Types:      ArgTypes{{"element", TypeString}, {"input", TypeTimestampTZ}},
+			ReturnType: fixedReturnType(TypeTimestampTZ),
+			category:   categoryDateAndTime,
+			fn: func(ctx *EvalContext, args Datums) (Datum, error) {
+				fromTime := args[1].(*DTimestampTZ).Time.In(ctx.GetLocation())
+				timeSpan := strings.ToLower(string(MustBeDString(args[0])))
+				t, err := truncateTimestamp(ctx, fromTime, timeSpan)
+				if err != nil {
+					return nil, err
+				}
+				return MakeDTimestampTZ(t, time.Microsecond), nil
+			},
+			Info: "Truncates `input` to precision `element` in the session time zone, setting\n" +
+				"all less significant fields to their lowest value.\n\n" +
+				"Compatible elements: millennium, century, decade, year, quarter, month,\n" +
+				"week, day, hour, minute, second, millisecond, microsecond",
+		},
+	},
+
+	// timezone is the function form of `input AT TIME ZONE zone`.
+	"timezone": {
+		Builtin{
+			Types:      ArgTypes{{"zone", TypeString}, {"input", TypeTimestamp}},
+			ReturnType: fixedReturnType(TypeTimestampTZ),
+			category:   categoryDateAndTime,
+			fn: func(_ *EvalContext, args Datums) (Datum, error) {
+				loc, err := LoadLocation(string(MustBeDString(args[0])))
+				if err != nil {
+					return nil, err
+				}
+				t := reinterpretInLocation(args[1].(*DTimestamp).Time, loc)
+				return MakeDTimestampTZ(t, time.Microsecond), nil
+			},
+			Info: "Treats `input` as a local time in time zone `zone` and converts it to a\n" +
+				"timestamp with time zone.",
+		},
+		Builtin{
+			Types:      ArgTypes{{"zone", TypeString}, {"input", TypeTimestampTZ}},
+			ReturnType: fixedReturnType(TypeTimestamp),
+			category:   categoryDateAndTime,
+			fn: func(_ *EvalContext, args Datums) (Datum, error) {
+				loc, err := LoadLocation(string(MustBeDString(args[0])))
+				if err != nil {
+					return nil, err
+				}
+				return MakeDTimestamp(wallTime(args[1].(*DTimestampTZ).Time, loc), time.Microsecond), nil
+			},
+			Info: "Converts `input` to the local time in time zone `zone`.",
+		},
+		Builtin{
+			Types:      ArgTypes{{"zone", TypeInterval}, {"input", TypeTimestamp}},
+			ReturnType: fixedReturnType(TypeTimestampTZ),
+			category:   categoryDateAndTime,
+			fn: func(_ *EvalContext, args Datums) (Datum, error) {
+				loc, err := intervalToLocation(args[0].(*DInterval))
+				if err != nil {
+					return nil, err
+				}
+				t := reinterpretInLocation(args[1].(*DTimestamp).Time, loc)
+				return MakeDTimestampTZ(t, time.Microsecond), nil
+			},
+			Info: "Treats `input` as a local time at UTC offset `zone` and converts it to a\n" +
+				"timestamp with time zone.",
+		},
+		Builtin{
+			Types:      ArgTypes{{"zone", TypeInterval}, {"input", TypeTimestampTZ}},
+			ReturnType: fixedReturnType(TypeTimestamp),
+			category:   categoryDateAndTime,
+			fn: func(_ *EvalContext, args Datums) (Datum, error) {
+				loc, err := intervalToLocation(args[0].(*DInterval))
+				if err != nil {
+					return nil, err
+				}
+				return MakeDTimestamp(wallTime(args[1].(*DTimestampTZ).Time, loc), time.Microsecond), nil
+			},
+			Info: "Converts `input` to the local time at UTC offset `zone`.",
+		},
+	},
+
 	// Math functions
 	"abs": {
 		floatBuiltin1(func(x float64) (Datum, error) {
@@ -1725,6 +1958,225 @@ CockroachDB supports the following flags:
 		}
 	}),
 
+	// JSON functions.
+
+	"to_jsonb": {
+		Builtin{
+			Types:      ArgTypes{{"val", TypeAny}},
+			ReturnType: fixedReturnType(TypeJSON),
+			category:   categoryJSON,
+			fn: func(_ *EvalContext, args Datums) (Datum, error) {
+				j, err := asJSON(args[0])
+				if err != nil {
+					return nil, err
+				}
+				return NewDJSON(j), nil
+			},
+			Info: "Returns the value as JSONB. Arrays are converted to JSON arrays, and the " +
+				"values of the types without a JSON equivalent to JSON strings.",
+		},
+	},
+
+	"jsonb_typeof": {
+		Builtin{
+			Types:      ArgTypes{{"val", TypeJSON}},
+			ReturnType: fixedReturnType(TypeString),
+			category:   categoryJSON,
+			fn: func(_ *EvalContext, args Datums) (Datum, error) {
+				return NewDString(MustBeDJSON(args[0]).Type().String()), nil
+			},
+			Info: "Returns the type of the outermost JSON value as a text string: `object`, " +
+				"`array`, `string`, `number`, `boolean` or `null`.",
+		},
+	},
+
+	"jsonb_array_length": {
+		Builtin{
+			Types:      ArgTypes{{"json", TypeJSON}},
+			ReturnType: fixedReturnType(TypeInt),
+			category:   categoryJSON,
+			fn: func(_ *EvalContext, args Datums) (Datum, error) {
+				elems, ok := MustBeDJSON(args[0]).AsArray()
+				if !ok {
+					return nil, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+						"cannot get array length of a non-array")
+				}
+				return NewDInt(DInt(len(elems))), nil
+			},
+			Info: "Returns the number of elements in the outermost JSON array.",
+		},
+	},
+
+	"jsonb_pretty": {
+		Builtin{
+			Types:      ArgTypes{{"val", TypeJSON}},
+			ReturnType: fixedReturnType(TypeString),
+			category:   categoryJSON,
+			fn: func(_ *EvalContext, args Datums) (Datum, error) {
+				return NewDString(json.Pretty(MustBeDJSON(args[0]).JSON)), nil
+			},
+			Info: "Returns the given JSON value as an indented STRING.",
+		},
+	},
+
+	"jsonb_extract_path": {
+		Builtin{
+			Types:      VariadicType{FixedTypes: []Type{TypeJSON}, Typ: TypeString},
+			ReturnType: fixedReturnType(TypeJSON),
+			category:   categoryJSON,
+			fn: func(_ *EvalContext, args Datums) (Datum, error) {
+				return jsonOrNull(json.FetchPath(MustBeDJSON(args[0]).JSON, jsonPath(args[1:]))), nil
+			},
+			Info: "Returns the JSON value pointed to by the variadic arguments, or NULL if " +
+				"there is none. This is equivalent to chaining the `->` operator.",
+		},
+	},
+
+	"jsonb_extract_path_text": {
+		Builtin{
+			Types:      VariadicType{FixedTypes: []Type{TypeJSON}, Typ: TypeString},
+			ReturnType: fixedReturnType(TypeString),
+			category:   categoryJSON,
+			fn: func(_ *EvalContext, args Datums) (Datum, error) {
+				return jsonTextOrNull(json.FetchPath(MustBeDJSON(args[0]).JSON, jsonPath(args[1:]))), nil
+			},
+			Info: "Returns the JSON value pointed to by the variadic arguments as text, or " +
+				"NULL if there is none.",
+		},
+	},
+
+	"jsonb_build_array": {
+		Builtin{
+			Types:        VariadicType{Typ: TypeAny},
+			ReturnType:   fixedReturnType(TypeJSON),
+			category:     categoryJSON,
+			nullableArgs: true,
+			fn: func(_ *EvalContext, args Datums) (Datum, error) {
+				elems := make([]json.JSON, len(args))
+				for i, arg := range args {
+					var err error
+					if elems[i], err = asJSON(arg); err != nil {
+						return nil, err
+					}
+				}
+				return NewDJSON(json.FromArray(elems)), nil
+			},
+			Info: "Builds a JSON array out of the variadic arguments.",
+		},
+	},
+
+	"jsonb_build_object": {
+		Builtin{
+			Types:        VariadicType{Typ: TypeAny},
+			ReturnType:   fixedReturnType(TypeJSON),
+			category:     categoryJSON,
+			nullableArgs: true,
+			fn: func(_ *EvalContext, args Datums) (Datum, error) {
+				if len(args)%2 != 0 {
+					return nil, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+						"argument list must have even number of elements")
+				}
+				obj := make(map[string]interface{}, len(args)/2)
+				for i := 0; i < len(args); i += 2 {
+					if args[i] == DNull {
+						return nil, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+							"argument %d cannot be null", i+1)
+					}
+					v, err := asJSON(args[i+1])
+					if err != nil {
+						return nil, err
+					}
+					obj[asJSONObjectKey(args[i])] = v
+				}
+				j, err := json.MakeJSON(obj)
+				if err != nil {
+					return nil, err
+				}
+				return NewDJSON(j), nil
+			},
+			Info: "Builds a JSON object out of the variadic arguments, which alternate " +
+				"between keys and values.",
+		},
+	},
+
+	// Network address functions.
+
+	"host": {
+		Builtin{
+			Types:      ArgTypes{{"val", TypeINet}},
+			ReturnType: fixedReturnType(TypeString),
+			category:   categoryIPAddress,
+			fn: func(_ *EvalContext, args Datums) (Datum, error) {
+				return NewDString(MustBeDIPAddr(args[0]).Host()), nil
+			},
+			Info: "Extracts the address part of the combined address/prefixlen value as text.",
+		},
+	},
+
+	"masklen": {
+		Builtin{
+			Types:      ArgTypes{{"val", TypeINet}},
+			ReturnType: fixedReturnType(TypeInt),
+			category:   categoryIPAddress,
+			fn: func(_ *EvalContext, args Datums) (Datum, error) {
+				return NewDInt(DInt(MustBeDIPAddr(args[0]).Mask)), nil
+			},
+			Info: "Retrieves the prefix length stored in `val`.",
+		},
+	},
+
+	"family": {
+		Builtin{
+			Types:      ArgTypes{{"val", TypeINet}},
+			ReturnType: fixedReturnType(TypeInt),
+			category:   categoryIPAddress,
+			fn: func(_ *EvalContext, args Datums) (Datum, error) {
+				return NewDInt(DInt(MustBeDIPAddr(args[0]).Family)), nil
+			},
+			Info: "Extracts the IP family of the value; 4 for IPv4, 6 for IPv6.",
+		},
+	},
+
+	"broadcast": {
+		Builtin{
+			Types:      ArgTypes{{"val", TypeINet}},
+			ReturnType: fixedReturnType(TypeINet),
+			category:   categoryIPAddress,
+			fn: func(_ *EvalContext, args Datums) (Datum, error) {
+				return NewDIPAddr(DIPAddr{MustBeDIPAddr(args[0]).Broadcast()}), nil
+			},
+			Info: "Gets the broadcast address for the network address represented by `val`.",
+		},
+	},
+
+	"inet_contains_or_equals": {
+		Builtin{
+			Types:      ArgTypes{{"container", TypeINet}, {"containee", TypeINet}},
+			ReturnType: fixedReturnType(TypeBool),
+			category:   categoryIPAddress,
+			fn: func(_ *EvalContext, args Datums) (Datum, error) {
+				ipAddr := MustBeDIPAddr(args[0]).IPAddr
+				return MakeDBool(DBool(ipAddr.ContainsOrEquals(MustBeDIPAddr(args[1]).IPAddr))), nil
+			},
+			Info: "Test for subnet inclusion or equality, using only the network parts of " +
+				"the addresses. The host part of the addresses is ignored.",
+		},
+	},
+
+	"inet_contained_by_or_equals": {
+		Builtin{
+			Types:      ArgTypes{{"val", TypeINet}, {"container", TypeINet}},
+			ReturnType: fixedReturnType(TypeBool),
+			category:   categoryIPAddress,
+			fn: func(_ *EvalContext, args Datums) (Datum, error) {
+				ipAddr := MustBeDIPAddr(args[0]).IPAddr
+				return MakeDBool(DBool(ipAddr.ContainedByOrEquals(MustBeDIPAddr(args[1]).IPAddr))), nil
+			},
+			Info: "Test for subnet inclusion or equality, using only the network parts of " +
+				"the addresses. The host part of the addresses is ignored.",
+		},
+	},
+
 	// Metadata functions.
 
 	"version": {
@@ -2065,6 +2517,22 @@ var uuidV4Impl = Builtin{
 	Info: "Returns a UUID.",
 }
 
+// evalSequenceName parses the given sequence name argument of a sequence
+// builtin and qualifies it with the current database if needed. The sequence
+// builtins need a planner, which is not available in every EvalContext.
+func evalSequenceName(ctx *EvalContext, arg Datum) (*TableName, error) {
+	if ctx.Planner == nil {
+		// This happens e.g. when evaluating column defaults during a backfill.
+		return nil, pgerror.Unimplemented("seq_no_planner",
+			"sequence functions cannot be used in this context")
+	}
+	tn, err := ParseTableName(string(MustBeDString(arg)))
+	if err != nil {
+		return nil, err
+	}
+	return ctx.Planner.QualifyWithDatabase(ctx.Ctx(), &NormalizableTableName{tn})
+}
+
 var ceilImpl = []Builtin{
 	floatBuiltin1(func(x float64) (Datum, error) {
 		return NewDFloat(DFloat(math.Ceil(x))), nil
@@ -2271,7 +2739,7 @@ func feedHash(h hash.Hash, args Datums) {
 func hashBuiltin(newHash func() hash.Hash, info string) []Builtin {
 	return []Builtin{
 		{
-			Types:        VariadicType{TypeString},
+			Types:        VariadicType{Typ: TypeString},
 			ReturnType:   fixedReturnType(TypeString),
 			nullableArgs: true,
 			fn: func(_ *EvalContext, args Datums) (Datum, error) {
@@ -2282,7 +2750,7 @@ func hashBuiltin(newHash func() hash.Hash, info string) []Builtin {
 			Info: info,
 		},
 		{
-			Types:        VariadicType{TypeBytes},
+			Types:        VariadicType{Typ: TypeBytes},
 			ReturnType:   fixedReturnType(TypeString),
 			nullableArgs: true,
 			fn: func(_ *EvalContext, args Datums) (Datum, error) {
@@ -2298,7 +2766,7 @@ func hashBuiltin(newHash func() hash.Hash, info string) []Builtin {
 func hash32Builtin(newHash func() hash.Hash32, info string) []Builtin {
 	return []Builtin{
 		{
-			Types:        VariadicType{TypeString},
+			Types:        VariadicType{Typ: TypeString},
 			ReturnType:   fixedReturnType(TypeInt),
 			nullableArgs: true,
 			fn: func(_ *EvalContext, args Datums) (Datum, error) {
@@ -2309,7 +2777,7 @@ func hash32Builtin(newHash func() hash.Hash32, info string) []Builtin {
 			Info: info,
 		},
 		{
-			Types:        VariadicType{TypeBytes},
+			Types:        VariadicType{Typ: TypeBytes},
 			ReturnType:   fixedReturnType(TypeInt),
 			nullableArgs: true,
 			fn: func(_ *EvalContext, args Datums) (Datum, error) {
@@ -2324,7 +2792,7 @@ func hash32Builtin(newHash func() hash.Hash32, info string) []Builtin {
 func hash64Builtin(newHash func() hash.Hash64, info string) []Builtin {
 	return []Builtin{
 		{
-			Types:        VariadicType{TypeString},
+			Types:        VariadicType{Typ: TypeString},
 			ReturnType:   fixedReturnType(TypeInt),
 			nullableArgs: true,
 			fn: func(_ *EvalContext, args Datums) (Datum, error) {
@@ -2335,7 +2803,7 @@ func hash64Builtin(newHash func() hash.Hash64, info string) []Builtin {
 			Info: info,
 		},
 		{
-			Types:        VariadicType{TypeBytes},
+			Types:        VariadicType{Typ: TypeBytes},
 			ReturnType:   fixedReturnType(TypeInt),
 			nullableArgs: true,
 			fn: func(_ *EvalContext, args Datums) (Datum, error) {
@@ -2693,6 +3161,69 @@ func GenerateUniqueInt(nodeID roachpb.NodeID) DInt {
 	return DInt(id)
 }
 
+// asJSON converts a datum to JSON, as done by to_jsonb().
+func asJSON(d Datum) (json.JSON, error) {
+	switch t := d.(type) {
+	case *DBool:
+		return json.FromBool(bool(*t)), nil
+	case *DInt:
+		return json.FromInt64(int64(*t)), nil
+	case *DFloat:
+		return json.FromFloat64(float64(*t))
+	case *DDecimal:
+		return json.FromDecimal(t.Decimal), nil
+	case *DString:
+		return json.FromString(string(*t)), nil
+	case *DCollatedString:
+		return json.FromString(t.Contents), nil
+	case *DJSON:
+		return t.JSON, nil
+	case *DArray:
+		elems := make([]json.JSON, t.Len())
+		for i, e := range t.Array {
+			var err error
+			if elems[i], err = asJSON(e); err != nil {
+				return nil, err
+			}
+		}
+		return json.FromArray(elems), nil
+	case *DTuple, *DTable:
+		return nil, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"cannot convert %s to JSON", d.ResolvedType())
+	case dNull:
+		return json.NullJSONValue, nil
+	case *DOidWrapper:
+		return asJSON(t.Wrapped)
+	default:
+		return json.FromString(AsStringWithFlags(d, FmtBareStrings)), nil
+	}
+}
+
+// asJSONObjectKey returns the text of d used as an object key by
+// jsonb_build_object().
+func asJSONObjectKey(d Datum) string {
+	switch t := d.(type) {
+	case *DString:
+		return string(*t)
+	case *DCollatedString:
+		return t.Contents
+	case *DOidWrapper:
+		return asJSONObjectKey(t.Wrapped)
+	default:
+		return AsStringWithFlags(d, FmtBareStrings)
+	}
+}
+
+// jsonPath returns the path of keys and array indexes given as the variadic
+// arguments of jsonb_extract_path().
+func jsonPath(args Datums) []string {
+	path := make([]string, len(args))
+	for i, arg := range args {
+		path[i] = string(MustBeDString(arg))
+	}
+	return path
+}
+
 func arrayLength(arr *DArray, dim int64) Datum {
 	if arr.Len() == 0 || dim < 1 {
 		return DNull
@@ -2731,7 +3262,7 @@ func extractStringFromTimestamp(
 		return NewDInt(DInt(fromTime.Year())), nil
 
 	case "quarter":
-		return NewDInt(DInt(fromTime.Month()/4 + 1)), nil
+		return NewDInt(DInt((fromTime.Month()-1)/3 + 1)), nil
 
 	case "month", "months":
 		return NewDInt(DInt(fromTime.Month())), nil
@@ -2772,3 +3303,131 @@ func extractStringFromTimestamp(
 		return nil, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError, "unsupported timespan: %s", timeSpan)
 	}
 }
+
+// truncateTimestamp truncates fromTime to the precision given by timeSpan.
+// Truncation operates on the wall clock time of fromTime in its location.
+// When truncating to a day or coarser, the resulting local time is converted
+// back using the UTC offset in effect at that time, which can differ from the
+// offset of fromTime across a daylight savings transition. Finer truncations
+// keep the UTC offset of fromTime, as in Postgres.
+func truncateTimestamp(_ *EvalContext, fromTime time.Time, timeSpan string) (time.Time, error) {
+	year, month, day := fromTime.Date()
+	_, min, sec := fromTime.Clock()
+	nsec := fromTime.Nanosecond()
+
+	switch timeSpan {
+	case "millennium", "millennia":
+		year = ((year-1)/1000)*1000 + 1
+		month, day = time.January, 1
+
+	case "century", "centuries":
+		year = ((year-1)/100)*100 + 1
+		month, day = time.January, 1
+
+	case "decade", "decades":
+		year = (year / 10) * 10
+		month, day = time.January, 1
+
+	case "year", "years":
+		month, day = time.January, 1
+
+	case "quarter":
+		month, day = (month-1)/3*3+1, 1
+
+	case "month", "months":
+		day = 1
+
+	case "week", "weeks":
+		// Weeks start on Monday, as in ISO 8601.
+		day -= (int(fromTime.Weekday()) + 6) % 7
+
+	case "day", "days":
+
+	case "hour", "hours":
+		return fromTime.Add(-time.Duration(min)*time.Minute -
+			time.Duration(sec)*time.Second - time.Duration(nsec)), nil
+
+	case "minute", "minutes":
+		return fromTime.Add(-time.Duration(sec)*time.Second - time.Duration(nsec)), nil
+
+	case "second", "seconds":
+		return fromTime.Add(-time.Duration(nsec)), nil
+
+	case "millisecond", "milliseconds":
+		return fromTime.Add(-time.Duration(nsec % int(time.Millisecond))), nil
+
+	case "microsecond", "microseconds":
+		return fromTime.Add(-time.Duration(nsec % int(time.Microsecond))), nil
+
+	default:
+		return time.Time{}, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError, "unsupported timespan: %s", timeSpan)
+	}
+
+	return reinterpretInLocation(
+		time.Date(year, month, day, 0, 0, 0, 0, time.UTC), fromTime.Location()), nil
+}
+
+// reinterpretInLocation returns the time at which the wall clock in loc reads
+// the same date and time as t does in its own location.
+//
+// Like Postgres, a wall clock time skipped by a daylight savings transition is
+// interpreted using the UTC offset in effect before the transition, and a wall
+// clock time that occurs twice is interpreted using the UTC offset in effect
+// after the transition.
+func reinterpretInLocation(t time.Time, loc *time.Location) time.Time {
+	year, month, day := t.Date()
+	hour, min, sec := t.Clock()
+	wall := time.Date(year, month, day, hour, min, sec, t.Nanosecond(), time.UTC)
+
+	// The UTC offset of the wall clock time differs from the offset of the
+	// actual time by less than a day, so the offsets in effect a day before
+	// and a day after are the only candidates.
+	_, before := wall.Add(-secondsInDay * time.Second).In(loc).Zone()
+	_, after := wall.Add(secondsInDay * time.Second).In(loc).Zone()
+	if res := wall.Add(-time.Duration(after) * time.Second).In(loc); offsetOf(res) == after {
+		return res
+	}
+	return wall.Add(-time.Duration(before) * time.Second).In(loc)
+}
+
+// wallTime returns the wall clock time of t in loc as a time in UTC.
+func wallTime(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	year, month, day := t.Date()
+	hour, min, sec := t.Clock()
+	return time.Date(year, month, day, hour, min, sec, t.Nanosecond(), time.UTC)
+}
+
+func offsetOf(t time.Time) int {
+	_, offset := t.Zone()
+	return offset
+}
+
+// intervalToLocation returns a location with the fixed UTC offset given by
+// an interval, as used by `AT TIME ZONE INTERVAL '-08:00'`.
+func intervalToLocation(d *DInterval) (*time.Location, error) {
+	if d.Months != 0 || d.Days != 0 {
+		return nil, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+			"interval time zone %s must not include months or days", d)
+	}
+	offset := d.Nanos / int64(time.Second)
+	return time.FixedZone(fmt.Sprintf("%+d", offset), int(offset)), nil
+}
+
+// LoadLocation returns the time zone with the given name. Names are matched
+// case insensitively where the time zone database allows it, so that 'utc'
+// and 'EUROPE/ROME' are accepted.
+func LoadLocation(name string) (*time.Location, error) {
+	loc, err := timeutil.LoadLocation(name)
+	if err == nil {
+		return loc, nil
+	}
+	if loc, err1 := timeutil.LoadLocation(strings.ToUpper(name)); err1 == nil {
+		return loc, nil
+	}
+	if loc, err1 := timeutil.LoadLocation(strings.ToTitle(name)); err1 == nil {
+		return loc, nil
+	}
+	return nil, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+		"cannot find time zone %q: %v", name, err)
+}
@@ -14,7 +14,12 @@
 
 package parser
 
-import "testing"
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
 
 func TestCategory(t *testing.T) {
 	if expected, actual := categoryString, Builtins["lower"][0].Category(); expected != actual {
@@ -30,3 +35,36 @@ func TestCategory(t *testing.T) {
 		t.Fatalf("bad category: expected %q got %q", expected, actual)
 	}
 }
+
+func TestReinterpretInLocation(t *testing.T) {
+	testCases := []struct {
+		location string
+		wall     string
+		expected string
+	}{
+		{"America/New_York", "2017-07-01 12:00:00", "2017-07-01 16:00:00"},
+		// Skipped wall clock times use the offset from before the transition.
+		{"America/New_York", "2017-03-12 02:30:00", "2017-03-12 07:30:00"},
+		{"Australia/Sydney", "2017-10-01 02:30:00", "2017-09-30 16:30:00"},
+		{"Australia/Lord_Howe", "2017-10-01 02:15:00", "2017-09-30 15:45:00"},
+		// Repeated wall clock times use the offset from after the transition.
+		{"America/New_York", "2017-11-05 01:30:00", "2017-11-05 06:30:00"},
+		{"Australia/Sydney", "2017-04-02 02:30:00", "2017-04-01 16:30:00"},
+		{"Australia/Lord_Howe", "2017-04-02 01:45:00", "2017-04-01 15:15:00"},
+	}
+	const layout = "2006-01-02 15:04:05"
+	for _, tc := range testCases {
+		loc, err := timeutil.LoadLocation(tc.location)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wall, err := time.Parse(layout, tc.wall)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res := reinterpretInLocation(wall, loc)
+		if actual := res.UTC().Format(layout); actual != tc.expected {
+			t.Errorf("%s in %s: expected %s, got %s", tc.wall, tc.location, tc.expected, actual)
+		}
+	}
+}
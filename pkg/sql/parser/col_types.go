@@ -48,6 +48,8 @@ func (*TimestampColType) columnType()      {}
 func (*TimestampTZColType) columnType()    {}
 func (*IntervalColType) columnType()       {}
 func (*UUIDColType) columnType()           {}
+func (*IPAddrColType) columnType()         {}
+func (*JSONColType) columnType()           {}
 func (*StringColType) columnType()         {}
 func (*NameColType) columnType()           {}
 func (*BytesColType) columnType()          {}
@@ -66,6 +68,8 @@ func (*TimestampColType) castTargetType()      {}
 func (*TimestampTZColType) castTargetType()    {}
 func (*IntervalColType) castTargetType()       {}
 func (*UUIDColType) castTargetType()           {}
+func (*IPAddrColType) castTargetType()         {}
+func (*JSONColType) castTargetType()           {}
 func (*StringColType) castTargetType()         {}
 func (*NameColType) castTargetType()           {}
 func (*BytesColType) castTargetType()          {}
@@ -295,6 +299,37 @@ func (node *UUIDColType) Format(buf *bytes.Buffer, f FmtFlags) {
 	buf.WriteString("UUID")
 }
 
+// Pre-allocated immutable IPAddr column types.
+var (
+	ipnetColTypeINet = &IPAddrColType{Name: "INET"}
+)
+
+// IPAddrColType represents an INET column type.
+type IPAddrColType struct {
+	Name string
+}
+
+// Format implements the NodeFormatter interface.
+func (node *IPAddrColType) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString(node.Name)
+}
+
+// Pre-allocated immutable JSON column types.
+var (
+	jsonColTypeJSON  = &JSONColType{Name: "JSON"}
+	jsonColTypeJSONB = &JSONColType{Name: "JSONB"}
+)
+
+// JSONColType represents the JSON column type.
+type JSONColType struct {
+	Name string
+}
+
+// Format implements the NodeFormatter interface.
+func (node *JSONColType) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString(node.Name)
+}
+
 // Pre-allocated immutable string column types.
 var (
 	stringColTypeChar    = &StringColType{Name: "CHAR"}
@@ -476,6 +511,8 @@ func (node *TimestampColType) String() string      { return AsString(node) }
 func (node *TimestampTZColType) String() string    { return AsString(node) }
 func (node *IntervalColType) String() string       { return AsString(node) }
 func (node *UUIDColType) String() string           { return AsString(node) }
+func (node *IPAddrColType) String() string         { return AsString(node) }
+func (node *JSONColType) String() string           { return AsString(node) }
 func (node *StringColType) String() string         { return AsString(node) }
 func (node *NameColType) String() string           { return AsString(node) }
 func (node *BytesColType) String() string          { return AsString(node) }
@@ -505,6 +542,10 @@ func DatumTypeToColumnType(t Type) (ColumnType, error) {
 		return intervalColTypeInterval, nil
 	case TypeUUID:
 		return uuidColTypeUUID, nil
+	case TypeINet:
+		return ipnetColTypeINet, nil
+	case TypeJSON:
+		return jsonColTypeJSONB, nil
 	case TypeDate:
 		return dateColTypeDate, nil
 	case TypeString:
@@ -567,6 +608,10 @@ func CastTargetToDatumType(t CastTargetType) Type {
 		return TypeInterval
 	case *UUIDColType:
 		return TypeUUID
+	case *IPAddrColType:
+		return TypeINet
+	case *JSONColType:
+		return TypeJSON
 	case *CollatedStringColType:
 		return TCollatedString{Locale: ct.Locale}
 	case *ArrayColType:
@@ -350,6 +350,8 @@ var (
 		TypeTimestampTZ,
 		TypeInterval,
 		TypeUUID,
+		TypeINet,
+		TypeJSON,
 	}
 	strValAvailBytesString = []Type{TypeBytes, TypeString, TypeUUID}
 	strValAvailBytes       = []Type{TypeBytes, TypeUUID}
@@ -435,6 +437,10 @@ func (expr *StrVal) ResolveAsType(ctx *SemaContext, typ Type) (Datum, error) {
 			return ParseDUuidFromBytes([]byte(expr.s))
 		}
 		return ParseDUuidFromString(expr.s)
+	case TypeINet:
+		return ParseDIPAddrFromINetString(expr.s)
+	case TypeJSON:
+		return ParseDJSON(expr.s)
 	default:
 		return nil, pgerror.NewErrorf(pgerror.CodeInternalError,
 			"could not resolve %T %v into a %T", expr, expr, typ)
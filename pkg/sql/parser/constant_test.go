@@ -216,6 +216,20 @@ func mustParseDInterval(t *testing.T, s string) Datum {
 	}
 	return d
 }
+func mustParseDIPAddr(t *testing.T, s string) Datum {
+	d, err := ParseDIPAddrFromINetString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+func mustParseDJSON(t *testing.T, s string) Datum {
+	d, err := ParseDJSON(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
 
 var parseFuncs = map[Type]func(*testing.T, string) Datum{
 	TypeString:      func(t *testing.T, s string) Datum { return NewDString(s) },
@@ -225,6 +239,8 @@ var parseFuncs = map[Type]func(*testing.T, string) Datum{
 	TypeTimestamp:   mustParseDTimestamp,
 	TypeTimestampTZ: mustParseDTimestampTZ,
 	TypeInterval:    mustParseDInterval,
+	TypeINet:        mustParseDIPAddr,
+	TypeJSON:        mustParseDJSON,
 }
 
 func typeSet(types ...Type) map[Type]struct{} {
@@ -251,7 +267,7 @@ func TestStringConstantResolveAvailableTypes(t *testing.T) {
 		},
 		{
 			c:            &StrVal{s: "true", bytesEsc: false},
-			parseOptions: typeSet(TypeString, TypeBytes, TypeBool),
+			parseOptions: typeSet(TypeString, TypeBytes, TypeBool, TypeJSON),
 		},
 		{
 			c:            &StrVal{s: "2010-09-28", bytesEsc: false},
@@ -269,6 +285,18 @@ func TestStringConstantResolveAvailableTypes(t *testing.T) {
 			c:            &StrVal{s: "PT12H2M", bytesEsc: false},
 			parseOptions: typeSet(TypeString, TypeBytes, TypeInterval),
 		},
+		{
+			c:            &StrVal{s: "2001:4f8:3:ba::/64", bytesEsc: false},
+			parseOptions: typeSet(TypeString, TypeBytes, TypeINet),
+		},
+		{
+			c:            &StrVal{s: "::ffff:1.2.3.1/120", bytesEsc: false},
+			parseOptions: typeSet(TypeString, TypeBytes, TypeINet),
+		},
+		{
+			c:            &StrVal{s: `{"a": [1, true]}`, bytesEsc: false},
+			parseOptions: typeSet(TypeString, TypeBytes, TypeJSON),
+		},
 		{
 			c:            &StrVal{s: "abc 世界", bytesEsc: true},
 			parseOptions: typeSet(TypeString, TypeBytes),
@@ -66,15 +66,34 @@ func (node *CreateDatabase) Format(buf *bytes.Buffer, f FmtFlags) {
 	}
 }
 
-// IndexElem represents a column with a direction in a CREATE INDEX statement.
+// IndexElem represents a column or an expression with a direction in a
+// CREATE INDEX statement. Exactly one of Column and Expr is set.
 type IndexElem struct {
 	Column    Name
+	Expr      Expr
 	Direction Direction
 }
 
+// makeIndexElemFromExpr is used by the grammar for parenthesized index
+// elements; a parenthesized column name still indexes the column itself.
+func makeIndexElemFromExpr(expr Expr, dir Direction) IndexElem {
+	if n, ok := expr.(UnresolvedName); ok && len(n) == 1 {
+		if name, ok := n[0].(Name); ok {
+			return IndexElem{Column: name, Direction: dir}
+		}
+	}
+	return IndexElem{Expr: expr, Direction: dir}
+}
+
 // Format implements the NodeFormatter interface.
 func (node IndexElem) Format(buf *bytes.Buffer, f FmtFlags) {
-	FormatNode(buf, f, node.Column)
+	if node.Expr != nil {
+		buf.WriteByte('(')
+		FormatNode(buf, f, node.Expr)
+		buf.WriteByte(')')
+	} else {
+		FormatNode(buf, f, node.Column)
+	}
 	if node.Direction != DefaultDirection {
 		buf.WriteByte(' ')
 		buf.WriteString(node.Direction.String())
@@ -104,8 +123,12 @@ type CreateIndex struct {
 	Columns     IndexElemList
 	// Extra columns to be stored together with the indexed ones as an optimization
 	// for improved reading performance.
-	Storing    NameList
-	Interleave *InterleaveDef
+	Storing     NameList
+	Interleave  *InterleaveDef
+	PartitionBy *PartitionBy
+	// Inverted is true for inverted indexes, which index all the paths of
+	// JSON documents.
+	Inverted bool
 }
 
 // Format implements the NodeFormatter interface.
@@ -114,6 +137,9 @@ func (node *CreateIndex) Format(buf *bytes.Buffer, f FmtFlags) {
 	if node.Unique {
 		buf.WriteString("UNIQUE ")
 	}
+	if node.Inverted {
+		buf.WriteString("INVERTED ")
+	}
 	buf.WriteString("INDEX ")
 	if node.IfNotExists {
 		buf.WriteString("IF NOT EXISTS ")
@@ -135,6 +161,9 @@ func (node *CreateIndex) Format(buf *bytes.Buffer, f FmtFlags) {
 	if node.Interleave != nil {
 		FormatNode(buf, f, node.Interleave)
 	}
+	if node.PartitionBy != nil {
+		FormatNode(buf, f, node.PartitionBy)
+	}
 }
 
 // TableDef represents a column, index or constraint definition within a CREATE
@@ -196,6 +225,7 @@ type ColumnTableDef struct {
 		Table          NormalizableTableName
 		Col            Name
 		ConstraintName Name
+		Actions        ReferenceActions
 	}
 	Family struct {
 		Name        Name
@@ -292,6 +322,7 @@ func newColumnTableDef(
 			d.References.Table = t.Table
 			d.References.Col = t.Col
 			d.References.ConstraintName = c.Name
+			d.References.Actions = t.Actions
 		case *ColumnFamilyConstraint:
 			if d.HasColumnFamily() {
 				return nil, pgerror.NewErrorf(pgerror.CodeInvalidTableDefinitionError,
@@ -375,6 +406,7 @@ func (node *ColumnTableDef) Format(buf *bytes.Buffer, f FmtFlags) {
 			FormatNode(buf, f, node.References.Col)
 			buf.WriteByte(')')
 		}
+		FormatNode(buf, f, &node.References.Actions)
 	}
 	if node.HasColumnFamily() {
 		if node.Family.Create {
@@ -439,8 +471,9 @@ type ColumnCheckConstraint struct {
 
 // ColumnFKConstraint represents a FK-constaint on a column.
 type ColumnFKConstraint struct {
-	Table NormalizableTableName
-	Col   Name // empty-string means use PK
+	Table   NormalizableTableName
+	Col     Name // empty-string means use PK
+	Actions ReferenceActions
 }
 
 // ColumnFamilyConstraint represents FAMILY on a column.
@@ -453,10 +486,12 @@ type ColumnFamilyConstraint struct {
 // IndexTableDef represents an index definition within a CREATE TABLE
 // statement.
 type IndexTableDef struct {
-	Name       Name
-	Columns    IndexElemList
-	Storing    NameList
-	Interleave *InterleaveDef
+	Name        Name
+	Columns     IndexElemList
+	Storing     NameList
+	Interleave  *InterleaveDef
+	PartitionBy *PartitionBy
+	Inverted    bool
 }
 
 func (node *IndexTableDef) setName(name Name) {
@@ -465,6 +500,9 @@ func (node *IndexTableDef) setName(name Name) {
 
 // Format implements the NodeFormatter interface.
 func (node *IndexTableDef) Format(buf *bytes.Buffer, f FmtFlags) {
+	if node.Inverted {
+		buf.WriteString("INVERTED ")
+	}
 	buf.WriteString("INDEX ")
 	if node.Name != "" {
 		FormatNode(buf, f, node.Name)
@@ -481,6 +519,9 @@ func (node *IndexTableDef) Format(buf *bytes.Buffer, f FmtFlags) {
 	if node.Interleave != nil {
 		FormatNode(buf, f, node.Interleave)
 	}
+	if node.PartitionBy != nil {
+		FormatNode(buf, f, node.PartitionBy)
+	}
 }
 
 // ConstraintTableDef represents a constraint definition within a CREATE TABLE
@@ -524,6 +565,53 @@ func (node *UniqueConstraintTableDef) Format(buf *bytes.Buffer, f FmtFlags) {
 	if node.Interleave != nil {
 		FormatNode(buf, f, node.Interleave)
 	}
+	if node.PartitionBy != nil {
+		FormatNode(buf, f, node.PartitionBy)
+	}
+}
+
+// ReferenceAction is the method used to maintain referential integrity
+// through foreign keys.
+type ReferenceAction int
+
+// ReferenceAction values.
+const (
+	NoAction ReferenceAction = iota
+	Restrict
+	SetNull
+	SetDefault
+	Cascade
+)
+
+var referenceActionName = [...]string{
+	NoAction:   "NO ACTION",
+	Restrict:   "RESTRICT",
+	SetNull:    "SET NULL",
+	SetDefault: "SET DEFAULT",
+	Cascade:    "CASCADE",
+}
+
+func (ra ReferenceAction) String() string {
+	return referenceActionName[ra]
+}
+
+// ReferenceActions contains the actions specified to maintain referential
+// integrity through foreign keys for different operations.
+type ReferenceActions struct {
+	Delete ReferenceAction
+	Update ReferenceAction
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ReferenceActions) Format(buf *bytes.Buffer, f FmtFlags) {
+	if node.Delete != NoAction {
+		buf.WriteString(" ON DELETE ")
+		buf.WriteString(node.Delete.String())
+	}
+	if node.Update != NoAction {
+		buf.WriteString(" ON UPDATE ")
+		buf.WriteString(node.Update.String())
+	}
 }
 
 // ForeignKeyConstraintTableDef represents a FOREIGN KEY constraint in the AST.
@@ -532,6 +620,7 @@ type ForeignKeyConstraintTableDef struct {
 	Table    NormalizableTableName
 	FromCols NameList
 	ToCols   NameList
+	Actions  ReferenceActions
 }
 
 // Format implements the NodeFormatter interface.
@@ -552,6 +641,7 @@ func (node *ForeignKeyConstraintTableDef) Format(buf *bytes.Buffer, f FmtFlags)
 		FormatNode(buf, f, node.ToCols)
 		buf.WriteByte(')')
 	}
+	FormatNode(buf, f, &node.Actions)
 }
 
 func (node *ForeignKeyConstraintTableDef) setName(name Name) {
@@ -636,11 +726,102 @@ func (node *InterleaveDef) Format(buf *bytes.Buffer, f FmtFlags) {
 	}
 }
 
+// PartitionBy represents a PARTITION BY definition within a CREATE TABLE or
+// CREATE INDEX statement.
+type PartitionBy struct {
+	Fields NameList
+	// Exactly one of List or Range is non-empty.
+	List  []ListPartition
+	Range []RangePartition
+}
+
+// Format implements the NodeFormatter interface.
+func (node *PartitionBy) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString(" PARTITION BY ")
+	if len(node.List) > 0 {
+		buf.WriteString("LIST")
+	} else {
+		buf.WriteString("RANGE")
+	}
+	buf.WriteString(" (")
+	FormatNode(buf, f, node.Fields)
+	buf.WriteString(") (")
+	for i := range node.List {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		FormatNode(buf, f, &node.List[i])
+	}
+	for i := range node.Range {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		FormatNode(buf, f, &node.Range[i])
+	}
+	buf.WriteByte(')')
+}
+
+// ListPartition represents a PARTITION definition within a PARTITION BY LIST.
+type ListPartition struct {
+	Name Name
+	// Exprs holds one value (or tuple of values, when partitioning by more
+	// than one column) per row of the partition. DefaultVal matches every
+	// row not matched by the other partitions.
+	Exprs Exprs
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ListPartition) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("PARTITION ")
+	FormatNode(buf, f, node.Name)
+	buf.WriteString(" VALUES IN (")
+	FormatNode(buf, f, node.Exprs)
+	buf.WriteByte(')')
+}
+
+// RangePartition represents a PARTITION definition within a PARTITION BY
+// RANGE.
+type RangePartition struct {
+	Name Name
+	// From and To hold one value per partitioning column. From is inclusive
+	// and To is exclusive; either may contain PartitionMinVal or
+	// PartitionMaxVal.
+	From Exprs
+	To   Exprs
+}
+
+// Format implements the NodeFormatter interface.
+func (node *RangePartition) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("PARTITION ")
+	FormatNode(buf, f, node.Name)
+	buf.WriteString(" VALUES FROM (")
+	FormatNode(buf, f, node.From)
+	buf.WriteString(") TO (")
+	FormatNode(buf, f, node.To)
+	buf.WriteByte(')')
+}
+
+// partitionValue returns the special value for MINVALUE and MAXVALUE, which
+// the grammar can only parse as column names, and expr otherwise.
+func partitionValue(expr Expr) Expr {
+	if n, ok := expr.(UnresolvedName); ok && len(n) == 1 {
+		switch n[0] {
+		case Name("minvalue"):
+			return PartitionMinVal{}
+		case Name("maxvalue"):
+			return PartitionMaxVal{}
+		}
+	}
+	return expr
+}
+
 // CreateTable represents a CREATE TABLE statement.
 type CreateTable struct {
 	IfNotExists   bool
+	Temporary     bool
 	Table         NormalizableTableName
 	Interleave    *InterleaveDef
+	PartitionBy   *PartitionBy
 	Defs          TableDefs
 	AsSource      *Select
 	AsColumnNames NameList // Only to be used in conjunction with AsSource
@@ -654,7 +835,11 @@ func (node *CreateTable) As() bool {
 
 // Format implements the NodeFormatter interface.
 func (node *CreateTable) Format(buf *bytes.Buffer, f FmtFlags) {
-	buf.WriteString("CREATE TABLE ")
+	buf.WriteString("CREATE ")
+	if node.Temporary {
+		buf.WriteString("TEMP ")
+	}
+	buf.WriteString("TABLE ")
 	if node.IfNotExists {
 		buf.WriteString("IF NOT EXISTS ")
 	}
@@ -674,6 +859,9 @@ func (node *CreateTable) Format(buf *bytes.Buffer, f FmtFlags) {
 		if node.Interleave != nil {
 			FormatNode(buf, f, node.Interleave)
 		}
+		if node.PartitionBy != nil {
+			FormatNode(buf, f, node.PartitionBy)
+		}
 	}
 }
 
@@ -702,16 +890,33 @@ func (node *CreateUser) Format(buf *bytes.Buffer, f FmtFlags) {
 	}
 }
 
+// CreateRole represents a CREATE ROLE statement.
+type CreateRole struct {
+	Name Name
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateRole) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("CREATE ROLE ")
+	FormatNode(buf, f, node.Name)
+}
+
 // CreateView represents a CREATE VIEW statement.
 type CreateView struct {
 	Name        NormalizableTableName
 	ColumnNames NameList
 	AsSource    *Select
+	// Materialized is set for CREATE MATERIALIZED VIEW.
+	Materialized bool
 }
 
 // Format implements the NodeFormatter interface.
 func (node *CreateView) Format(buf *bytes.Buffer, f FmtFlags) {
-	buf.WriteString("CREATE VIEW ")
+	buf.WriteString("CREATE ")
+	if node.Materialized {
+		buf.WriteString("MATERIALIZED ")
+	}
+	buf.WriteString("VIEW ")
 	FormatNode(buf, f, &node.Name)
 
 	if len(node.ColumnNames) > 0 {
@@ -724,3 +929,254 @@ func (node *CreateView) Format(buf *bytes.Buffer, f FmtFlags) {
 	buf.WriteString(" AS ")
 	FormatNode(buf, f, node.AsSource)
 }
+
+// RefreshMaterializedView represents a REFRESH MATERIALIZED VIEW statement.
+type RefreshMaterializedView struct {
+	Name NormalizableTableName
+}
+
+// Format implements the NodeFormatter interface.
+func (node *RefreshMaterializedView) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("REFRESH MATERIALIZED VIEW ")
+	FormatNode(buf, f, &node.Name)
+}
+
+// CreateSequence represents a CREATE SEQUENCE statement.
+type CreateSequence struct {
+	IfNotExists bool
+	Name        NormalizableTableName
+	Options     SequenceOptions
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateSequence) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("CREATE SEQUENCE ")
+	if node.IfNotExists {
+		buf.WriteString("IF NOT EXISTS ")
+	}
+	FormatNode(buf, f, &node.Name)
+	FormatNode(buf, f, node.Options)
+}
+
+// CreateStats represents a CREATE STATISTICS statement.
+type CreateStats struct {
+	Name        Name
+	ColumnNames NameList
+	Table       NormalizableTableName
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateStats) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("CREATE STATISTICS ")
+	FormatNode(buf, f, node.Name)
+	buf.WriteString(" ON ")
+	FormatNode(buf, f, node.ColumnNames)
+	buf.WriteString(" FROM ")
+	FormatNode(buf, f, &node.Table)
+}
+
+// FunctionArg represents an argument in a CREATE FUNCTION statement. The
+// name is empty for arguments that are only referred to by position.
+type FunctionArg struct {
+	Name Name
+	Type ColumnType
+}
+
+// FunctionArgs represents a list of function arguments.
+type FunctionArgs []FunctionArg
+
+// Format implements the NodeFormatter interface.
+func (node FunctionArgs) Format(buf *bytes.Buffer, f FmtFlags) {
+	for i, arg := range node {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		if arg.Name != "" {
+			FormatNode(buf, f, arg.Name)
+			buf.WriteByte(' ')
+		}
+		FormatNode(buf, f, arg.Type)
+	}
+}
+
+// FunctionReturnKind describes what a user-defined function returns.
+type FunctionReturnKind int
+
+// FunctionReturnKind values.
+const (
+	// FunctionReturnsType is used for functions returning a value of the
+	// type in CreateFunction.ReturnType.
+	FunctionReturnsType FunctionReturnKind = iota
+	// FunctionReturnsTrigger is used for functions executed by triggers.
+	FunctionReturnsTrigger
+	// FunctionReturnsVoid is used for functions that do not return a value.
+	FunctionReturnsVoid
+)
+
+// CreateFunction represents a CREATE FUNCTION statement.
+type CreateFunction struct {
+	Replace    bool
+	Name       UnresolvedName
+	Args       FunctionArgs
+	ReturnKind FunctionReturnKind
+	ReturnType ColumnType
+	Language   Name
+	Body       string
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateFunction) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("CREATE ")
+	if node.Replace {
+		buf.WriteString("OR REPLACE ")
+	}
+	buf.WriteString("FUNCTION ")
+	FormatNode(buf, f, node.Name)
+	buf.WriteByte('(')
+	FormatNode(buf, f, node.Args)
+	buf.WriteString(") RETURNS ")
+	switch node.ReturnKind {
+	case FunctionReturnsTrigger:
+		buf.WriteString("TRIGGER")
+	case FunctionReturnsVoid:
+		buf.WriteString("VOID")
+	default:
+		FormatNode(buf, f, node.ReturnType)
+	}
+	buf.WriteString(" LANGUAGE ")
+	FormatNode(buf, f, node.Language)
+	buf.WriteString(" AS ")
+	encodeSQLString(buf, node.Body)
+}
+
+// TriggerTiming specifies when a trigger fires relative to the row
+// modification.
+type TriggerTiming int
+
+// TriggerTiming values.
+const (
+	TriggerBefore TriggerTiming = iota
+	TriggerAfter
+)
+
+var triggerTimingName = [...]string{
+	TriggerBefore: "BEFORE",
+	TriggerAfter:  "AFTER",
+}
+
+func (t TriggerTiming) String() string {
+	return triggerTimingName[t]
+}
+
+// TriggerEvent is a kind of statement that fires a trigger.
+type TriggerEvent int
+
+// TriggerEvent values.
+const (
+	TriggerInsert TriggerEvent = iota
+	TriggerUpdate
+	TriggerDelete
+)
+
+var triggerEventName = [...]string{
+	TriggerInsert: "INSERT",
+	TriggerUpdate: "UPDATE",
+	TriggerDelete: "DELETE",
+}
+
+func (e TriggerEvent) String() string {
+	return triggerEventName[e]
+}
+
+// CreateTrigger represents a CREATE TRIGGER statement.
+type CreateTrigger struct {
+	Name   Name
+	Timing TriggerTiming
+	Events []TriggerEvent
+	Table  NormalizableTableName
+	Func   UnresolvedName
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateTrigger) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("CREATE TRIGGER ")
+	FormatNode(buf, f, node.Name)
+	buf.WriteByte(' ')
+	buf.WriteString(node.Timing.String())
+	for i, event := range node.Events {
+		if i > 0 {
+			buf.WriteString(" OR")
+		}
+		buf.WriteByte(' ')
+		buf.WriteString(event.String())
+	}
+	buf.WriteString(" ON ")
+	FormatNode(buf, f, &node.Table)
+	buf.WriteString(" FOR EACH ROW EXECUTE FUNCTION ")
+	FormatNode(buf, f, node.Func)
+	buf.WriteString("()")
+}
+
+// SequenceOptions represents a list of sequence options.
+type SequenceOptions []SequenceOption
+
+// Format implements the NodeFormatter interface.
+func (node SequenceOptions) Format(buf *bytes.Buffer, f FmtFlags) {
+	for _, option := range node {
+		buf.WriteByte(' ')
+		switch option.Name {
+		case SeqOptCycle, SeqOptNoCycle:
+			buf.WriteString(option.Name)
+		case SeqOptCache:
+			buf.WriteString(option.Name)
+			buf.WriteByte(' ')
+			fmt.Fprintf(buf, "%d", *option.IntVal)
+		case SeqOptMaxValue, SeqOptMinValue:
+			if option.IntVal == nil {
+				buf.WriteString("NO ")
+				buf.WriteString(option.Name)
+			} else {
+				buf.WriteString(option.Name)
+				buf.WriteByte(' ')
+				fmt.Fprintf(buf, "%d", *option.IntVal)
+			}
+		case SeqOptStart:
+			buf.WriteString(option.Name)
+			buf.WriteByte(' ')
+			if option.OptionalWord {
+				buf.WriteString("WITH ")
+			}
+			fmt.Fprintf(buf, "%d", *option.IntVal)
+		case SeqOptIncrement:
+			buf.WriteString(option.Name)
+			buf.WriteByte(' ')
+			if option.OptionalWord {
+				buf.WriteString("BY ")
+			}
+			fmt.Fprintf(buf, "%d", *option.IntVal)
+		default:
+			panic(fmt.Sprintf("unexpected SequenceOption: %v", option))
+		}
+	}
+}
+
+// SequenceOption represents an option on a CREATE SEQUENCE or ALTER SEQUENCE
+// statement.
+type SequenceOption struct {
+	Name string
+	// IntVal is nil for the NO MINVALUE and NO MAXVALUE options.
+	IntVal *int64
+	// OptionalWord is set if the optional BY or WITH noise word was specified.
+	OptionalWord bool
+}
+
+// Names of the options on CREATE SEQUENCE and ALTER SEQUENCE.
+const (
+	SeqOptCycle     = "CYCLE"
+	SeqOptNoCycle   = "NO CYCLE"
+	SeqOptCache     = "CACHE"
+	SeqOptIncrement = "INCREMENT"
+	SeqOptMinValue  = "MINVALUE"
+	SeqOptMaxValue  = "MAXVALUE"
+	SeqOptStart     = "START"
+)
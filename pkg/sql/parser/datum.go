@@ -37,6 +37,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
+	"github.com/cockroachdb/cockroach/pkg/util/uint128"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
 
@@ -234,6 +237,26 @@ func ParseDUuidFromBytes(b []byte) (*DUuid, error) {
 	return NewDUuid(DUuid{uv}), nil
 }
 
+// ParseDIPAddrFromINetString parses and returns the *DIPAddr Datum value
+// represented by the provided input INET string, or an error.
+func ParseDIPAddrFromINetString(s string) (*DIPAddr, error) {
+	ipAddr, err := ipaddr.ParseINet(s)
+	if err != nil {
+		return nil, makeParseError(s, TypeINet, err)
+	}
+	return NewDIPAddr(DIPAddr{ipAddr}), nil
+}
+
+// ParseDJSON parses and returns the *DJSON Datum value represented by the
+// provided input string, or an error.
+func ParseDJSON(s string) (*DJSON, error) {
+	j, err := json.ParseJSON(s)
+	if err != nil {
+		return nil, makeParseError(s, TypeJSON, err)
+	}
+	return NewDJSON(j), nil
+}
+
 // GetBool gets DBool or an error (also treats NULL as false, not an error).
 func GetBool(d Datum) (DBool, error) {
 	if v, ok := d.(*DBool); ok {
@@ -1093,6 +1116,192 @@ func (d *DUuid) Size() uintptr {
 	return unsafe.Sizeof(*d)
 }
 
+// DIPAddr is the IPAddr Datum.
+type DIPAddr struct {
+	ipaddr.IPAddr
+}
+
+// NewDIPAddr is a helper routine to create a *DIPAddr initialized from its
+// argument.
+func NewDIPAddr(d DIPAddr) *DIPAddr {
+	return &d
+}
+
+// MustBeDIPAddr attempts to retrieve a DIPAddr from an Expr, panicking if the
+// assertion fails.
+func MustBeDIPAddr(e Expr) DIPAddr {
+	i, ok := e.(*DIPAddr)
+	if !ok {
+		panic(pgerror.NewErrorf(pgerror.CodeInternalError, "expected *DIPAddr, found %T", e))
+	}
+	return *i
+}
+
+// ResolvedType implements the TypedExpr interface.
+func (*DIPAddr) ResolvedType() Type {
+	return TypeINet
+}
+
+// Compare implements the Datum interface.
+func (d *DIPAddr) Compare(ctx *EvalContext, other Datum) int {
+	if other == DNull {
+		// NULL is less than any non-NULL value.
+		return 1
+	}
+	v, ok := other.(*DIPAddr)
+	if !ok {
+		panic(makeUnsupportedComparisonMessage(d, other))
+	}
+	return d.IPAddr.Compare(v.IPAddr)
+}
+
+// Prev implements the Datum interface.
+func (d *DIPAddr) Prev() (Datum, bool) {
+	return nil, false
+}
+
+// Next implements the Datum interface.
+func (d *DIPAddr) Next() (Datum, bool) {
+	return nil, false
+}
+
+// IsMax implements the Datum interface.
+func (d *DIPAddr) IsMax() bool {
+	return d.IPAddr == dMaxIPAddr.IPAddr
+}
+
+// IsMin implements the Datum interface.
+func (d *DIPAddr) IsMin() bool {
+	return d.IPAddr == dMinIPAddr.IPAddr
+}
+
+// dMinIPAddr is 0.0.0.0/0 and dMaxIPAddr is the IPv6 address with all bits
+// set: IPv4 addresses sort before IPv6 ones, and shorter netmasks before
+// longer ones.
+var dMinIPAddr = NewDIPAddr(DIPAddr{ipaddr.IPAddr{Family: ipaddr.IPv4family}})
+var dMaxIPAddr = NewDIPAddr(DIPAddr{ipaddr.IPAddr{
+	Family: ipaddr.IPv6family,
+	Addr:   uint128.FromInts(math.MaxUint64, math.MaxUint64),
+	Mask:   128,
+}})
+
+// min implements the Datum interface.
+func (*DIPAddr) min() (Datum, bool) {
+	return dMinIPAddr, true
+}
+
+// max implements the Datum interface.
+func (*DIPAddr) max() (Datum, bool) {
+	return dMaxIPAddr, true
+}
+
+// AmbiguousFormat implements the Datum interface.
+func (*DIPAddr) AmbiguousFormat() bool { return true }
+
+// Format implements the NodeFormatter interface.
+func (d *DIPAddr) Format(buf *bytes.Buffer, f FmtFlags) {
+	s := d.IPAddr.String()
+	if f.withinArray {
+		encodeSQLStringInsideArray(buf, s)
+	} else {
+		encodeSQLStringWithFlags(buf, s, f)
+	}
+}
+
+// Size implements the Datum interface.
+func (d *DIPAddr) Size() uintptr {
+	return unsafe.Sizeof(*d)
+}
+
+// DJSON is the JSON Datum.
+type DJSON struct {
+	json.JSON
+}
+
+// NewDJSON is a helper routine to create a *DJSON initialized from its
+// argument.
+func NewDJSON(j json.JSON) *DJSON {
+	return &DJSON{j}
+}
+
+// MustBeDJSON attempts to retrieve a DJSON from an Expr, panicking if the
+// assertion fails.
+func MustBeDJSON(e Expr) DJSON {
+	i, ok := e.(*DJSON)
+	if !ok {
+		panic(pgerror.NewErrorf(pgerror.CodeInternalError, "expected *DJSON, found %T", e))
+	}
+	return *i
+}
+
+// ResolvedType implements the TypedExpr interface.
+func (*DJSON) ResolvedType() Type {
+	return TypeJSON
+}
+
+// Compare implements the Datum interface.
+func (d *DJSON) Compare(ctx *EvalContext, other Datum) int {
+	if other == DNull {
+		// NULL is less than any non-NULL value.
+		return 1
+	}
+	v, ok := other.(*DJSON)
+	if !ok {
+		panic(makeUnsupportedComparisonMessage(d, other))
+	}
+	return d.JSON.Compare(v.JSON)
+}
+
+// Prev implements the Datum interface.
+func (d *DJSON) Prev() (Datum, bool) {
+	return nil, false
+}
+
+// Next implements the Datum interface.
+func (d *DJSON) Next() (Datum, bool) {
+	return nil, false
+}
+
+// IsMax implements the Datum interface.
+func (d *DJSON) IsMax() bool {
+	return false
+}
+
+// IsMin implements the Datum interface.
+func (d *DJSON) IsMin() bool {
+	return d.JSON == json.NullJSONValue
+}
+
+var dNullJSON = NewDJSON(json.NullJSONValue)
+
+// min implements the Datum interface.
+func (*DJSON) min() (Datum, bool) {
+	return dNullJSON, true
+}
+
+// max implements the Datum interface.
+func (*DJSON) max() (Datum, bool) {
+	return nil, false
+}
+
+// AmbiguousFormat implements the Datum interface.
+func (*DJSON) AmbiguousFormat() bool { return true }
+
+// Format implements the NodeFormatter interface.
+func (d *DJSON) Format(buf *bytes.Buffer, f FmtFlags) {
+	s := d.JSON.String()
+	if f.withinArray {
+		encodeSQLStringInsideArray(buf, s)
+	} else {
+		encodeSQLStringWithFlags(buf, s, f)
+	}
+}
+
+// Size implements the Datum interface.
+func (d *DJSON) Size() uintptr {
+	return unsafe.Sizeof(*d) + d.JSON.Size()
+}
+
 // DDate is the date Datum represented as the number of days after
 // the Unix epoch.
 type DDate int64
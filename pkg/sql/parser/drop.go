@@ -122,6 +122,41 @@ func (node *DropView) Format(buf *bytes.Buffer, f FmtFlags) {
 	}
 }
 
+// DropSequence represents a DROP SEQUENCE statement.
+type DropSequence struct {
+	Names        TableNameReferences
+	IfExists     bool
+	DropBehavior DropBehavior
+}
+
+// Format implements the NodeFormatter interface.
+func (node *DropSequence) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("DROP SEQUENCE ")
+	if node.IfExists {
+		buf.WriteString("IF EXISTS ")
+	}
+	FormatNode(buf, f, node.Names)
+	if node.DropBehavior != DropDefault {
+		buf.WriteByte(' ')
+		buf.WriteString(node.DropBehavior.String())
+	}
+}
+
+// DropRole represents a DROP ROLE statement
+type DropRole struct {
+	Names    NameList
+	IfExists bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *DropRole) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("DROP ROLE ")
+	if node.IfExists {
+		buf.WriteString("IF EXISTS ")
+	}
+	FormatNode(buf, f, node.Names)
+}
+
 // DropUser represents a DROP USER statement
 type DropUser struct {
 	Names    NameList
@@ -136,3 +171,49 @@ func (node *DropUser) Format(buf *bytes.Buffer, f FmtFlags) {
 	}
 	FormatNode(buf, f, node.Names)
 }
+
+// DropFunction represents a DROP FUNCTION statement. ArgTypes is nil when
+// the statement does not list the argument types, in which case the name
+// must refer to a single function.
+type DropFunction struct {
+	Name     UnresolvedName
+	ArgTypes []ColumnType
+	IfExists bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *DropFunction) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("DROP FUNCTION ")
+	if node.IfExists {
+		buf.WriteString("IF EXISTS ")
+	}
+	FormatNode(buf, f, node.Name)
+	if node.ArgTypes != nil {
+		buf.WriteByte('(')
+		for i, t := range node.ArgTypes {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			FormatNode(buf, f, t)
+		}
+		buf.WriteByte(')')
+	}
+}
+
+// DropTrigger represents a DROP TRIGGER statement.
+type DropTrigger struct {
+	Name     Name
+	Table    NormalizableTableName
+	IfExists bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *DropTrigger) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("DROP TRIGGER ")
+	if node.IfExists {
+		buf.WriteString("IF EXISTS ")
+	}
+	FormatNode(buf, f, node.Name)
+	buf.WriteString(" ON ")
+	FormatNode(buf, f, &node.Table)
+}
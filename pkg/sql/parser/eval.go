@@ -34,6 +34,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
@@ -998,6 +999,15 @@ var BinOps = map[BinaryOperator]binOpOverload{
 				return NewDInt(MustBeDInt(left) << uint(MustBeDInt(right))), nil
 			},
 		},
+		BinOp{
+			LeftType:   TypeINet,
+			RightType:  TypeINet,
+			ReturnType: TypeBool,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				ipAddr := MustBeDIPAddr(left).IPAddr
+				return MakeDBool(DBool(ipAddr.ContainedBy(MustBeDIPAddr(right).IPAddr))), nil
+			},
+		},
 	},
 
 	RShift: {
@@ -1009,6 +1019,15 @@ var BinOps = map[BinaryOperator]binOpOverload{
 				return NewDInt(MustBeDInt(left) >> uint(MustBeDInt(right))), nil
 			},
 		},
+		BinOp{
+			LeftType:   TypeINet,
+			RightType:  TypeINet,
+			ReturnType: TypeBool,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				ipAddr := MustBeDIPAddr(left).IPAddr
+				return MakeDBool(DBool(ipAddr.Contains(MustBeDIPAddr(right).IPAddr))), nil
+			},
+		},
 	},
 
 	Pow: {
@@ -1068,6 +1087,65 @@ var BinOps = map[BinaryOperator]binOpOverload{
 			},
 		},
 	},
+
+	JSONFetchVal: {
+		BinOp{
+			LeftType:   TypeJSON,
+			RightType:  TypeString,
+			ReturnType: TypeJSON,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				return jsonOrNull(MustBeDJSON(left).FetchValKey(string(MustBeDString(right)))), nil
+			},
+		},
+		BinOp{
+			LeftType:   TypeJSON,
+			RightType:  TypeInt,
+			ReturnType: TypeJSON,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				return jsonOrNull(MustBeDJSON(left).FetchValIdx(int(MustBeDInt(right)))), nil
+			},
+		},
+	},
+
+	JSONFetchText: {
+		BinOp{
+			LeftType:   TypeJSON,
+			RightType:  TypeString,
+			ReturnType: TypeString,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				return jsonTextOrNull(MustBeDJSON(left).FetchValKey(string(MustBeDString(right)))), nil
+			},
+		},
+		BinOp{
+			LeftType:   TypeJSON,
+			RightType:  TypeInt,
+			ReturnType: TypeString,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				return jsonTextOrNull(MustBeDJSON(left).FetchValIdx(int(MustBeDInt(right)))), nil
+			},
+		},
+	},
+}
+
+// jsonOrNull returns the DJSON for j, or NULL if j is nil.
+func jsonOrNull(j json.JSON) Datum {
+	if j == nil {
+		return DNull
+	}
+	return NewDJSON(j)
+}
+
+// jsonTextOrNull returns the text representation of j as a DString, or NULL
+// if j is nil or a JSON null.
+func jsonTextOrNull(j json.JSON) Datum {
+	if j == nil {
+		return DNull
+	}
+	text := j.AsText()
+	if text == nil {
+		return DNull
+	}
+	return NewDString(*text)
 }
 
 var timestampMinusBinOp BinOp
@@ -1261,6 +1339,16 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 			RightType: TypeUUID,
 			fn:        cmpOpScalarEQFn,
 		},
+		CmpOp{
+			LeftType:  TypeINet,
+			RightType: TypeINet,
+			fn:        cmpOpScalarEQFn,
+		},
+		CmpOp{
+			LeftType:  TypeJSON,
+			RightType: TypeJSON,
+			fn:        cmpOpScalarEQFn,
+		},
 		CmpOp{
 			LeftType:  TypeOid,
 			RightType: TypeOid,
@@ -1396,6 +1484,16 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 			RightType: TypeUUID,
 			fn:        cmpOpScalarLTFn,
 		},
+		CmpOp{
+			LeftType:  TypeINet,
+			RightType: TypeINet,
+			fn:        cmpOpScalarLTFn,
+		},
+		CmpOp{
+			LeftType:  TypeJSON,
+			RightType: TypeJSON,
+			fn:        cmpOpScalarLTFn,
+		},
 		CmpOp{
 			LeftType:  TypeTuple,
 			RightType: TypeTuple,
@@ -1526,6 +1624,16 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 			RightType: TypeUUID,
 			fn:        cmpOpScalarLEFn,
 		},
+		CmpOp{
+			LeftType:  TypeINet,
+			RightType: TypeINet,
+			fn:        cmpOpScalarLEFn,
+		},
+		CmpOp{
+			LeftType:  TypeJSON,
+			RightType: TypeJSON,
+			fn:        cmpOpScalarLEFn,
+		},
 		CmpOp{
 			LeftType:  TypeTuple,
 			RightType: TypeTuple,
@@ -1548,6 +1656,8 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 		makeEvalTupleIn(TypeTimestampTZ),
 		makeEvalTupleIn(TypeInterval),
 		makeEvalTupleIn(TypeUUID),
+		makeEvalTupleIn(TypeINet),
+		makeEvalTupleIn(TypeJSON),
 		makeEvalTupleIn(TypeTuple),
 		makeEvalTupleIn(TypeOid),
 	},
@@ -1604,6 +1714,26 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 			},
 		},
 	},
+
+	Contains: {
+		CmpOp{
+			LeftType:  TypeJSON,
+			RightType: TypeJSON,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				return MakeDBool(DBool(json.Contains(MustBeDJSON(left).JSON, MustBeDJSON(right).JSON))), nil
+			},
+		},
+	},
+
+	JSONExists: {
+		CmpOp{
+			LeftType:  TypeJSON,
+			RightType: TypeString,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				return MakeDBool(DBool(MustBeDJSON(left).Exists(string(MustBeDString(right))))), nil
+			},
+		},
+	},
 }
 
 func isNaN(d Datum) bool {
@@ -1814,8 +1944,29 @@ func (e *MultipleResultsError) Error() string {
 	return fmt.Sprintf("%s: unexpected multiple results", e.SQL)
 }
 
+// SequenceOperators is used for various sql related functions that can
+// be used from EvalContext.
+type SequenceOperators interface {
+	// IncrementSequence increments the given sequence and returns the result.
+	// It returns an error if the given name is not a sequence.
+	// The caller must ensure that seqName is fully qualified already.
+	IncrementSequence(ctx context.Context, seqName *TableName) (int64, error)
+
+	// GetLatestValueInSessionForSequence returns the value most recently obtained by
+	// nextval() for the given sequence in this session.
+	GetLatestValueInSessionForSequence(ctx context.Context, seqName *TableName) (int64, error)
+
+	// SetSequenceValue sets the sequence's value.
+	// If isCalled is false, the sequence is set such that the next time nextval() is called,
+	// `newVal` is returned. Otherwise, the next call to nextval will return
+	// `newVal + seqOpts.Increment`.
+	SetSequenceValue(ctx context.Context, seqName *TableName, newVal int64, isCalled bool) error
+}
+
 // EvalPlanner is a limited planner that can be used from EvalContext.
 type EvalPlanner interface {
+	SequenceOperators
+
 	// QueryRow executes a SQL query string where exactly 1 result row is
 	// expected and returns that row.
 	QueryRow(ctx context.Context, sql string, args ...interface{}) (Datums, error)
@@ -1823,6 +1974,10 @@ type EvalPlanner interface {
 	// QualifyWithDatabase resolves a possibly unqualified table name into a
 	// normalized table name that is qualified by database.
 	QualifyWithDatabase(ctx context.Context, t *NormalizableTableName) (*TableName, error)
+
+	// EvalUserDefinedFunction runs the body of a function created with
+	// CREATE FUNCTION and returns its result.
+	EvalUserDefinedFunction(ctx *EvalContext, fn *UserDefinedFunction, args Datums) (Datum, error)
 }
 
 // contextHolder is a wrapper that returns a Context.
@@ -2395,6 +2550,10 @@ func (expr *CastExpr) Eval(ctx *EvalContext) (Datum, error) {
 			s = t.ValueAsString()
 		case *DUuid:
 			s = t.UUID.String()
+		case *DIPAddr:
+			s = t.IPAddr.String()
+		case *DJSON:
+			s = t.JSON.String()
 		case *DString:
 			s = string(*t)
 		case *DCollatedString:
@@ -2451,6 +2610,26 @@ func (expr *CastExpr) Eval(ctx *EvalContext) (Datum, error) {
 			return d, nil
 		}
 
+	case *IPAddrColType:
+		switch t := d.(type) {
+		case *DString:
+			return ParseDIPAddrFromINetString(string(*t))
+		case *DCollatedString:
+			return ParseDIPAddrFromINetString(t.Contents)
+		case *DIPAddr:
+			return d, nil
+		}
+
+	case *JSONColType:
+		switch t := d.(type) {
+		case *DString:
+			return ParseDJSON(string(*t))
+		case *DCollatedString:
+			return ParseDJSON(t.Contents)
+		case *DJSON:
+			return d, nil
+		}
+
 	case *DateColType:
 		switch d := d.(type) {
 		case *DString:
@@ -2909,6 +3088,16 @@ func (expr DefaultVal) Eval(ctx *EvalContext) (Datum, error) {
 	return nil, pgerror.NewErrorf(pgerror.CodeInternalError, "unhandled type %T", expr)
 }
 
+// Eval implements the TypedExpr interface.
+func (expr PartitionMinVal) Eval(ctx *EvalContext) (Datum, error) {
+	return nil, pgerror.NewErrorf(pgerror.CodeInternalError, "unhandled type %T", expr)
+}
+
+// Eval implements the TypedExpr interface.
+func (expr PartitionMaxVal) Eval(ctx *EvalContext) (Datum, error) {
+	return nil, pgerror.NewErrorf(pgerror.CodeInternalError, "unhandled type %T", expr)
+}
+
 // Eval implements the TypedExpr interface.
 func (expr UnqualifiedStar) Eval(ctx *EvalContext) (Datum, error) {
 	return nil, pgerror.NewErrorf(pgerror.CodeInternalError, "unhandled type %T", expr)
@@ -3007,6 +3196,16 @@ func (t *DUuid) Eval(_ *EvalContext) (Datum, error) {
 	return t, nil
 }
 
+// Eval implements the TypedExpr interface.
+func (t *DIPAddr) Eval(_ *EvalContext) (Datum, error) {
+	return t, nil
+}
+
+// Eval implements the TypedExpr interface.
+func (t *DJSON) Eval(_ *EvalContext) (Datum, error) {
+	return t, nil
+}
+
 // Eval implements the TypedExpr interface.
 func (t *DDate) Eval(_ *EvalContext) (Datum, error) {
 	return t, nil
@@ -3160,6 +3359,9 @@ func foldComparisonExpr(
 		// Note the special handling of NULLs and IS NOT is needed before this
 		// expression fold.
 		return EQ, left, right, false, true
+	case ContainedBy:
+		// ContainedBy(left, right) is implemented as Contains(right, left)
+		return Contains, right, left, true, false
 	}
 	return op, left, right, false, false
 }
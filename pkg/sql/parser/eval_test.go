@@ -505,6 +505,52 @@ func TestEval(t *testing.T) {
 		{`length('hel'||'lo')`, `5`},
 		{`lower('HELLO')`, `'hello'`},
 		{`UPPER('hello')`, `'HELLO'`},
+		// JSON operators and functions.
+		{`'{"b": 1, "a": [1, "x"]}'::JSONB`, `'{"a": [1, "x"], "b": 1}'`},
+		{`'{"a": [1, "x"]}'::JSONB -> 'a'`, `'[1, "x"]'`},
+		{`'{"a": [1, "x"]}'::JSONB -> 'b'`, `NULL`},
+		{`'{"a": [1, "x"]}'::JSONB -> 'a' -> 1`, `'"x"'`},
+		{`'{"a": [1, "x"]}'::JSONB -> 'a' -> -2`, `'1'`},
+		{`'{"a": [1, "x"]}'::JSONB -> 'a' ->> 1`, `'x'`},
+		{`'{"a": null}'::JSONB ->> 'a'`, `NULL`},
+		{`'{"a": [1, "x"], "b": 2}'::JSONB @> '{"a": ["x"]}'`, `true`},
+		{`'{"a": [1, "x"], "b": 2}'::JSONB @> '{"a": "x"}'`, `false`},
+		{`'{"a": ["x"]}'::JSONB <@ '{"a": [1, "x"], "b": 2}'`, `true`},
+		{`'{"a": 1}'::JSONB ? 'a'`, `true`},
+		{`'["a", "b"]'::JSONB ? 'c'`, `false`},
+		{`'[1, 2]'::JSONB = '[1, 2.0]'`, `true`},
+		{`'[1, 2]'::JSONB < '{}'`, `true`},
+		{`'{"a": 1}'::JSONB::STRING`, `'{"a": 1}'`},
+		{`to_jsonb(ARRAY[1, 2])`, `'[1, 2]'`},
+		{`to_jsonb('a')`, `'"a"'`},
+		{`to_jsonb(1.50)`, `'1.50'`},
+		{`jsonb_typeof('{"a": 1}')`, `'object'`},
+		{`jsonb_typeof('true')`, `'boolean'`},
+		{`jsonb_array_length('[1, [2, 3]]')`, `2`},
+		{`jsonb_extract_path('{"a": [1, {"b": 2}]}', 'a', '1', 'b')`, `'2'`},
+		{`jsonb_extract_path('{"a": [1, {"b": 2}]}', 'a', 'c')`, `NULL`},
+		{`jsonb_extract_path_text('{"a": "b"}', 'a')`, `'b'`},
+		{`jsonb_build_array(1, 'a', NULL, true)`, `'[1, "a", null, true]'`},
+		{`jsonb_build_object('a', 1, 'bb', ARRAY['c'], 'a', 2)`, `'{"a": 2, "bb": ["c"]}'`},
+		// INET operators and functions.
+		{`'192.168.1.2/24'::INET`, `'192.168.1.2/24'`},
+		{`'2001:4f8:3:ba:2e0:81ff:fe22:d1f1/128'::INET`, `'2001:4f8:3:ba:2e0:81ff:fe22:d1f1'`},
+		{`'192.168.1.5'::INET < '192.168.1.6'`, `true`},
+		{`'192.168.1.5'::INET = '192.168.1.5/32'`, `true`},
+		{`'10.2.0.0/8'::INET < '10.1.0.0/16'`, `true`},
+		{`'::1'::INET > '255.255.255.255'`, `true`},
+		{`'192.168.1.5'::INET << '192.168.1/24'`, `true`},
+		{`'192.168.1/24'::INET << '192.168.1/24'`, `false`},
+		{`'192.168.1/24'::INET <<= '192.168.1/24'`, `true`},
+		{`'192.168.1/24'::INET >> '192.168.1.5'`, `true`},
+		{`'192.168.1/24'::INET >>= '192.168.1/24'`, `true`},
+		{`'192.168.1/24'::INET >> '::ffff:192.168.1.5'`, `false`},
+		{`host('192.168.1.5/24')`, `'192.168.1.5'`},
+		{`masklen('192.168.1.5/24')`, `24`},
+		{`family('::1')`, `6`},
+		{`family('127.0.0.1')`, `4`},
+		{`broadcast('192.168.1.5/24')`, `'192.168.1.255/24'`},
+		{`'10.1.2.3/8'::INET::STRING`, `'10.1.2.3/8'`},
 		// Array constructors.
 		{`ARRAY[]:::int[]`, `ARRAY[]`},
 		{`ARRAY[NULL]`, `ARRAY[NULL]`},
@@ -1065,6 +1111,11 @@ func TestEvalError(t *testing.T) {
 			`could not parse "1- 2:3:4 9" as type interval: invalid input syntax for type interval 1- 2:3:4 9`},
 		{`b'\xff\xfe\xfd'::string`, `invalid UTF-8: "\xff\xfe\xfd"`},
 		{`e'\\x6sdfsd36174'::BYTES`, `could not parse "\\x6sdfsd36174" as type bytes: encoding/hex: odd length hex string`},
+		{`'192.168.1.5/33'::INET`, `could not parse "192.168.1.5/33" as type inet: invalid netmask: "33"`},
+		{`'{"a": }'::JSONB`, `could not parse "{\"a\": }" as type jsonb`},
+		{`jsonb_array_length('{}')`, `cannot get array length of a non-array`},
+		{`jsonb_build_object('a')`, `argument list must have even number of elements`},
+		{`jsonb_build_object(NULL, 1)`, `argument 1 cannot be null`},
 		{`ARRAY[NULL, ARRAY[1, 2]]`, `multidimensional arrays must have array expressions with matching dimensions`},
 		{`ARRAY[ARRAY[1, 2], NULL]`, `multidimensional arrays must have array expressions with matching dimensions`},
 		{`ARRAY[ARRAY[1, 2], ARRAY[1]]`, `multidimensional arrays must have array expressions with matching dimensions`},
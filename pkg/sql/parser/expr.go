@@ -293,6 +293,9 @@ const (
 	IsNotDistinctFrom
 	Is
 	IsNot
+	Contains
+	ContainedBy
+	JSONExists
 
 	// The following operators will always be used with an associated SubOperator.
 	// If Go had algebraic data types they would be defined in a self-contained
@@ -335,6 +338,9 @@ var comparisonOpName = [...]string{
 	IsNotDistinctFrom: "IS NOT DISTINCT FROM",
 	Is:                "IS",
 	IsNot:             "IS NOT",
+	Contains:          "@>",
+	ContainedBy:       "<@",
+	JSONExists:        "?",
 	Any:               "ANY",
 	Some:              "SOME",
 	All:               "ALL",
@@ -606,6 +612,22 @@ func (node DefaultVal) Format(buf *bytes.Buffer, f FmtFlags) {
 // ResolvedType implements the TypedExpr interface.
 func (DefaultVal) ResolvedType() Type { return nil }
 
+// PartitionMinVal represents the MINVALUE bound of a range partition.
+type PartitionMinVal struct{}
+
+// Format implements the NodeFormatter interface.
+func (node PartitionMinVal) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("MINVALUE")
+}
+
+// PartitionMaxVal represents the MAXVALUE bound of a range partition.
+type PartitionMaxVal struct{}
+
+// Format implements the NodeFormatter interface.
+func (node PartitionMaxVal) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("MAXVALUE")
+}
+
 var _ VariableExpr = &Placeholder{}
 
 // Placeholder represents a named placeholder.
@@ -748,6 +770,8 @@ const (
 	Concat
 	LShift
 	RShift
+	JSONFetchVal
+	JSONFetchText
 )
 
 var binaryOpName = [...]string{
@@ -764,6 +788,9 @@ var binaryOpName = [...]string{
 	Concat:   "||",
 	LShift:   "<<",
 	RShift:   ">>",
+
+	JSONFetchVal:  "->",
+	JSONFetchText: "->>",
 }
 
 func (i BinaryOperator) String() string {
@@ -782,6 +809,14 @@ type BinaryExpr struct {
 	fn BinOp
 }
 
+// NewTypedBinaryExpr returns a new BinaryExpr that is well-typed.
+func NewTypedBinaryExpr(op BinaryOperator, left, right TypedExpr, typ Type) *BinaryExpr {
+	node := &BinaryExpr{Operator: op, Left: left, Right: right}
+	node.typ = typ
+	node.memoizeFn()
+	return node
+}
+
 // TypedLeft returns the BinaryExpr's left expression as a TypedExpr.
 func (node *BinaryExpr) TypedLeft() TypedExpr {
 	return node.Left.(TypedExpr)
@@ -900,6 +935,14 @@ func (node *FuncExpr) GetAggregateConstructor() func(*EvalContext) AggregateFunc
 	}
 }
 
+// IsGroupingFunc returns true if the FuncExpr is a call to GROUPING(). The
+// result of GROUPING() is not computed from its arguments but from the
+// grouping set that a group belongs to.
+func (node *FuncExpr) IsGroupingFunc() bool {
+	fd, ok := node.Func.FunctionReference.(*FunctionDefinition)
+	return ok && fd.Name == "grouping"
+}
+
 // GetWindowConstructor returns a window function constructor if the
 // FuncExpr is a built-in window function.
 func (node *FuncExpr) GetWindowConstructor() func(*EvalContext) WindowFunc {
@@ -1078,13 +1121,15 @@ var (
 	decimalCastTypes = []Type{TypeNull, TypeBool, TypeInt, TypeFloat, TypeDecimal, TypeString, TypeCollatedString,
 		TypeTimestamp, TypeTimestampTZ, TypeDate, TypeInterval}
 	stringCastTypes = []Type{TypeNull, TypeBool, TypeInt, TypeFloat, TypeDecimal, TypeString, TypeCollatedString,
-		TypeBytes, TypeTimestamp, TypeTimestampTZ, TypeInterval, TypeUUID, TypeDate, TypeOid}
+		TypeBytes, TypeTimestamp, TypeTimestampTZ, TypeInterval, TypeUUID, TypeDate, TypeOid, TypeINet, TypeJSON}
 	bytesCastTypes     = []Type{TypeNull, TypeString, TypeCollatedString, TypeBytes, TypeUUID}
 	dateCastTypes      = []Type{TypeNull, TypeString, TypeCollatedString, TypeDate, TypeTimestamp, TypeTimestampTZ, TypeInt}
 	timestampCastTypes = []Type{TypeNull, TypeString, TypeCollatedString, TypeDate, TypeTimestamp, TypeTimestampTZ, TypeInt}
 	intervalCastTypes  = []Type{TypeNull, TypeString, TypeCollatedString, TypeInt, TypeInterval}
 	oidCastTypes       = []Type{TypeNull, TypeString, TypeCollatedString, TypeInt, TypeOid}
 	uuidCastTypes      = []Type{TypeNull, TypeString, TypeCollatedString, TypeBytes, TypeUUID}
+	inetCastTypes      = []Type{TypeNull, TypeString, TypeCollatedString, TypeINet}
+	jsonCastTypes      = []Type{TypeNull, TypeString, TypeCollatedString, TypeJSON}
 )
 
 // validCastTypes returns a set of types that can be cast into the provided type.
@@ -1110,6 +1155,10 @@ func validCastTypes(t Type) []Type {
 		return intervalCastTypes
 	case TypeUUID:
 		return uuidCastTypes
+	case TypeINet:
+		return inetCastTypes
+	case TypeJSON:
+		return jsonCastTypes
 	case TypeOid, TypeRegClass, TypeRegNamespace, TypeRegProc, TypeRegProcedure, TypeRegType:
 		return oidCastTypes
 	default:
@@ -1224,6 +1273,8 @@ func (node *DFloat) String() string           { return AsString(node) }
 func (node *DInt) String() string             { return AsString(node) }
 func (node *DInterval) String() string        { return AsString(node) }
 func (node *DUuid) String() string            { return AsString(node) }
+func (node *DIPAddr) String() string          { return AsString(node) }
+func (node *DJSON) String() string            { return AsString(node) }
 func (node *DString) String() string          { return AsString(node) }
 func (node *DCollatedString) String() string  { return AsString(node) }
 func (node *DTimestamp) String() string       { return AsString(node) }
@@ -1240,6 +1291,7 @@ func (node *FuncExpr) String() string         { return AsString(node) }
 func (node *IfExpr) String() string           { return AsString(node) }
 func (node *IndexedVar) String() string       { return AsString(node) }
 func (node *IndirectionExpr) String() string  { return AsString(node) }
+func (node *GroupingSet) String() string      { return AsString(node) }
 func (node *IsOfTypeExpr) String() string     { return AsString(node) }
 func (node Name) String() string              { return AsString(node) }
 func (node *NotExpr) String() string          { return AsString(node) }
@@ -1254,6 +1306,8 @@ func (node *Tuple) String() string            { return AsString(node) }
 func (node *AnnotateTypeExpr) String() string { return AsString(node) }
 func (node *UnaryExpr) String() string        { return AsString(node) }
 func (node DefaultVal) String() string        { return AsString(node) }
+func (node PartitionMinVal) String() string   { return AsString(node) }
+func (node PartitionMaxVal) String() string   { return AsString(node) }
 func (node *Placeholder) String() string      { return AsString(node) }
 func (node dNull) String() string             { return AsString(node) }
 func (list NameList) String() string          { return AsString(list) }
@@ -27,6 +27,10 @@ type fmtFlags struct {
 	// tableNameFormatter will be called on all NormalizableTableNames if it is
 	// non-nil.
 	tableNameFormatter func(*NormalizableTableName, *bytes.Buffer, FmtFlags)
+	// unresolvedNameFormatter, if non-nil, will be called on the
+	// UnresolvedNames that appear in expressions; the name is formatted as
+	// usual if it returns false.
+	unresolvedNameFormatter func(UnresolvedName, *bytes.Buffer, FmtFlags) bool
 	// indexedVarFormat is an optional interceptor for
 	// IndexedVarContainer.IndexedVarFormat calls; it can be used to
 	// customize the formatting of IndexedVars.
@@ -118,6 +122,27 @@ func FmtReformatTableNames(
 	return &f
 }
 
+// FmtReformatUnresolvedNames returns FmtFlags that instructs the pretty-printer
+// to call fn on every UnresolvedName used in an expression, such as a column
+// reference. Names for which fn returns false are formatted as usual.
+func FmtReformatUnresolvedNames(
+	base FmtFlags, fn func(UnresolvedName, *bytes.Buffer, FmtFlags) bool,
+) FmtFlags {
+	f := *base
+	f.unresolvedNameFormatter = fn
+	return &f
+}
+
+// withoutUnresolvedNameFormatter returns f without its unresolvedNameFormatter.
+func withoutUnresolvedNameFormatter(f FmtFlags) FmtFlags {
+	if f.unresolvedNameFormatter == nil {
+		return f
+	}
+	nf := *f
+	nf.unresolvedNameFormatter = nil
+	return &nf
+}
+
 // StripTypeFormatting removes the flag that extracts types from the format flags,
 // so as to enable rendering expressions for which types have not been computed yet.
 func StripTypeFormatting(f FmtFlags) FmtFlags {
@@ -25,6 +25,14 @@ func TestFormatStatement(t *testing.T) {
 		func(_ *NormalizableTableName, buf *bytes.Buffer, _ FmtFlags) {
 			buf.WriteString("xoxoxo")
 		})
+	nameFormatter := FmtReformatUnresolvedNames(FmtSimple,
+		func(n UnresolvedName, buf *bytes.Buffer, _ FmtFlags) bool {
+			if n.String() != "x" {
+				return false
+			}
+			buf.WriteString("$1")
+			return true
+		})
 
 	testData := []struct {
 		stmt     string
@@ -58,6 +66,13 @@ func TestFormatStatement(t *testing.T) {
 		// {`GRANT SELECT ON bar TO foo`, tableFormatter,
 		// `GRANT SELECT ON xoxoxo TO foo`},
 
+		{`SELECT x, y, x(x) FROM x WHERE x > 1`, nameFormatter,
+			`SELECT $1, y, x($1) FROM x WHERE $1 > 1`},
+		{`INSERT INTO foo(x) VALUES (x)`, nameFormatter,
+			`INSERT INTO foo(x) VALUES ($1)`},
+		{`UPDATE foo SET x = x + 1`, nameFormatter,
+			`UPDATE foo SET x = $1 + 1`},
+
 		{`CREATE TABLE foo (x INT)`, FmtAnonymize,
 			`CREATE TABLE _ (_ INT)`},
 		{`INSERT INTO foo(x) TABLE bar`, FmtAnonymize,
@@ -50,6 +50,62 @@ func newFunctionDefinition(name string, def []Builtin) *FunctionDefinition {
 	}
 }
 
+// UserDefinedFunction is an overload of a function created with CREATE
+// FUNCTION. Its body is evaluated by the EvalPlanner of the EvalContext in
+// which the function is applied.
+type UserDefinedFunction struct {
+	// Database is the database in which the function was created.
+	Database string
+	// Def is the statement that created the function.
+	Def *CreateFunction
+	// ArgTypes and ReturnType are the resolved types of the arguments and
+	// the result declared by Def. Functions returning VOID or TRIGGER have
+	// the return type TypeNull.
+	ArgTypes   ArgTypes
+	ReturnType Type
+}
+
+// NewUserDefinedFunctionDefinition creates the FunctionDefinition for the
+// given overloads of a user-defined function.
+func NewUserDefinedFunctionDefinition(
+	name string, overloads []*UserDefinedFunction,
+) *FunctionDefinition {
+	def := make([]Builtin, len(overloads))
+	for i, udf := range overloads {
+		udf := udf
+		def[i] = Builtin{
+			Types:      udf.ArgTypes,
+			ReturnType: fixedReturnType(udf.ReturnType),
+			// The body may read and write tables and is run by the planner, so
+			// the function must be evaluated for every row and cannot be
+			// evaluated by DistSQL.
+			impure:                  true,
+			needsRepeatedEvaluation: true,
+			distsqlBlacklist:        true,
+			nullableArgs:            true,
+			category:                categoryUserDefined,
+			Info:                    "Calls a function created with CREATE FUNCTION.",
+			fn: func(ctx *EvalContext, args Datums) (Datum, error) {
+				if ctx.Planner == nil {
+					return nil, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+						"cannot evaluate user-defined function %s() in this context", name)
+				}
+				return ctx.Planner.EvalUserDefinedFunction(ctx, udf, args)
+			},
+		}
+	}
+	return newFunctionDefinition(name, def)
+}
+
+// FunctionResolver looks up functions that are not builtins, such as the
+// functions created with CREATE FUNCTION.
+type FunctionResolver interface {
+	// LookupFunction returns the definition of the function with the given
+	// name, or nil if there is no such function. The prefix is the database
+	// named in the function reference, or empty if the name is unqualified.
+	LookupFunction(prefix, name string) (*FunctionDefinition, error)
+}
+
 // funDefs holds pre-allocated FunctionDefinition instances
 // for every builtin function. Initialized by setupBuiltins().
 var funDefs map[string]*FunctionDefinition
@@ -63,6 +119,14 @@ func (fd *FunctionDefinition) String() string { return AsString(fd) }
 
 // ResolveFunction transforms an UnresolvedName to a FunctionDefinition.
 func (n UnresolvedName) ResolveFunction(searchPath SearchPath) (*FunctionDefinition, error) {
+	return n.ResolveFunctionWith(searchPath, nil /* functions */)
+}
+
+// ResolveFunctionWith is like ResolveFunction, but names that do not refer to
+// a builtin function are also looked up in functions, if non-nil.
+func (n UnresolvedName) ResolveFunctionWith(
+	searchPath SearchPath, functions FunctionResolver,
+) (*FunctionDefinition, error) {
 	fn, err := n.normalizeFunctionName()
 	if err != nil {
 		return nil, err
@@ -113,6 +177,12 @@ func (n UnresolvedName) ResolveFunction(searchPath SearchPath) (*FunctionDefinit
 				}
 			}
 		}
+		if !found && functions != nil {
+			if def, err = functions.LookupFunction(prefix, smallName); err != nil {
+				return nil, err
+			}
+			found = def != nil
+		}
 		if !found {
 			return nil, pgerror.NewErrorf(
 				pgerror.CodeUndefinedFunctionError, "unknown function: %s()", n)
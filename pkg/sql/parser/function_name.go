@@ -39,18 +39,27 @@ type ResolvableFunctionReference struct {
 
 // Format implements the NodeFormatter interface.
 func (fn ResolvableFunctionReference) Format(buf *bytes.Buffer, f FmtFlags) {
-	FormatNode(buf, f, fn.FunctionReference)
+	// Function names are not expressions; leave them alone.
+	FormatNode(buf, withoutUnresolvedNameFormatter(f), fn.FunctionReference)
 }
 func (fn ResolvableFunctionReference) String() string { return AsString(fn) }
 
 // Resolve checks if the function name is already resolved and
 // resolves it as necessary.
 func (fn *ResolvableFunctionReference) Resolve(searchPath SearchPath) (*FunctionDefinition, error) {
+	return fn.ResolveWith(searchPath, nil /* functions */)
+}
+
+// ResolveWith is like Resolve, but names that do not refer to a builtin
+// function are also looked up in functions, if non-nil.
+func (fn *ResolvableFunctionReference) ResolveWith(
+	searchPath SearchPath, functions FunctionResolver,
+) (*FunctionDefinition, error) {
 	switch t := fn.FunctionReference.(type) {
 	case *FunctionDefinition:
 		return t, nil
 	case UnresolvedName:
-		fd, err := t.ResolveFunction(searchPath)
+		fd, err := t.ResolveFunctionWith(searchPath, functions)
 		if err != nil {
 			return nil, err
 		}
@@ -77,6 +77,11 @@ func TestContextualHelp(t *testing.T) {
 		{`ALTER VIEW blah RENAME ?`, `ALTER VIEW`},
 		{`ALTER VIEW blah RENAME TO blih ?`, `ALTER VIEW`},
 
+		{`ALTER SEQUENCE IF ?`, `ALTER SEQUENCE`},
+		{`ALTER SEQUENCE blah ?`, `ALTER SEQUENCE`},
+		{`ALTER SEQUENCE blah RENAME ?`, `ALTER SEQUENCE`},
+		{`ALTER SEQUENCE blah RENAME TO blih ?`, `ALTER SEQUENCE`},
+
 		{`CANCEL ?`, `CANCEL`},
 		{`CANCEL JOB ?`, `CANCEL JOB`},
 		{`CANCEL QUERY ?`, `CANCEL QUERY`},
@@ -96,10 +101,29 @@ func TestContextualHelp(t *testing.T) {
 		{`CREATE USER blih ?`, `CREATE USER`},
 		{`CREATE USER blih WITH ?`, `CREATE USER`},
 
+		{`CREATE ROLE ?`, `CREATE ROLE`},
+		{`CREATE ROLE blih ?`, `CREATE ROLE`},
+
+		{`CREATE FUNCTION ?`, `CREATE FUNCTION`},
+		{`CREATE OR REPLACE FUNCTION f(?`, `CREATE FUNCTION`},
+		{`CREATE FUNCTION f() RETURNS ?`, `CREATE FUNCTION`},
+
+		{`CREATE SEQUENCE ?`, `CREATE SEQUENCE`},
+		{`CREATE SEQUENCE IF ?`, `CREATE SEQUENCE`},
+		{`CREATE SEQUENCE blah INCREMENT ?`, `CREATE SEQUENCE`},
+
+		{`CREATE STATISTICS ?`, `CREATE STATISTICS`},
+		{`CREATE STATISTICS blah ON a FROM ?`, `CREATE STATISTICS`},
+
 		{`CREATE VIEW blah (?`, `CREATE VIEW`},
 		{`CREATE VIEW blah AS (SELECT c FROM x) ?`, `CREATE VIEW`},
 		{`CREATE VIEW blah AS SELECT c FROM x ?`, `SELECT`},
 		{`CREATE VIEW blah AS (?`, `<SELECTCLAUSE>`},
+		{`CREATE MATERIALIZED VIEW blah (?`, `CREATE VIEW`},
+		{`CREATE MATERIALIZED VIEW ?`, `CREATE VIEW`},
+
+		{`CREATE TRIGGER ?`, `CREATE TRIGGER`},
+		{`CREATE TRIGGER t BEFORE INSERT ?`, `CREATE TRIGGER`},
 
 		{`CREATE TABLE blah (?`, `CREATE TABLE`},
 		{`CREATE TABLE IF NOT ?`, `CREATE TABLE`},
@@ -108,6 +132,7 @@ func TestContextualHelp(t *testing.T) {
 		{`CREATE TABLE blah AS ?`, `CREATE TABLE`},
 		{`CREATE TABLE blah AS (SELECT 1) ?`, `CREATE TABLE`},
 		{`CREATE TABLE blah AS SELECT 1 ?`, `SELECT`},
+		{`CREATE TEMP TABLE blah (?`, `CREATE TABLE`},
 
 		{`DELETE FROM ?`, `DELETE`},
 		{`DELETE FROM blah ?`, `DELETE`},
@@ -116,6 +141,7 @@ func TestContextualHelp(t *testing.T) {
 
 		{`DISCARD ALL ?`, `DISCARD`},
 		{`DISCARD ?`, `DISCARD`},
+		{`DISCARD TEMP ?`, `DISCARD`},
 
 		{`DROP ?`, `DROP`},
 
@@ -133,9 +159,22 @@ func TestContextualHelp(t *testing.T) {
 		{`DROP VIEW IF ?`, `DROP VIEW`},
 		{`DROP VIEW IF EXISTS blih, bloh ?`, `DROP VIEW`},
 
+		{`DROP SEQUENCE blah ?`, `DROP SEQUENCE`},
+		{`DROP SEQUENCE IF ?`, `DROP SEQUENCE`},
+		{`DROP SEQUENCE IF EXISTS blih, bloh ?`, `DROP SEQUENCE`},
+
+		{`DROP FUNCTION ?`, `DROP FUNCTION`},
+		{`DROP FUNCTION IF EXISTS f(?`, `DROP FUNCTION`},
+
+		{`DROP TRIGGER ?`, `DROP TRIGGER`},
+		{`DROP TRIGGER t ON ?`, `DROP TRIGGER`},
+
 		{`DROP USER IF ?`, `DROP USER`},
 		{`DROP USER IF EXISTS bloh ?`, `DROP USER`},
 
+		{`DROP ROLE IF ?`, `DROP ROLE`},
+		{`DROP ROLE IF EXISTS bloh ?`, `DROP ROLE`},
+
 		{`EXPLAIN (?`, `EXPLAIN`},
 		{`EXPLAIN SELECT 1 ?`, `SELECT`},
 		{`EXPLAIN INSERT INTO xx (SELECT 1) ?`, `INSERT`},
@@ -219,6 +258,9 @@ func TestContextualHelp(t *testing.T) {
 
 		{`SHOW JOBS ?`, `SHOW JOBS`},
 
+		{`SHOW STATISTICS ?`, `SHOW STATISTICS`},
+		{`SHOW STATISTICS FOR TABLE ?`, `SHOW STATISTICS`},
+
 		{`SHOW BACKUP 'foo' ?`, `SHOW BACKUP`},
 
 		{`SHOW CLUSTER SETTING all ?`, `SHOW CLUSTER SETTING`},
@@ -234,6 +276,8 @@ func TestContextualHelp(t *testing.T) {
 
 		{`SHOW CREATE VIEW blah ?`, `SHOW CREATE VIEW`},
 
+		{`SHOW CREATE SEQUENCE blah ?`, `SHOW CREATE SEQUENCE`},
+
 		{`SHOW DATABASES ?`, `SHOW DATABASES`},
 
 		{`SHOW GRANTS ON ?`, `SHOW GRANTS`},
@@ -254,6 +298,10 @@ func TestContextualHelp(t *testing.T) {
 		{`SHOW TRANSACTION ISOLATION LEVEL ?`, `SHOW TRANSACTION`},
 
 		{`SHOW USERS ?`, `SHOW USERS`},
+		{`SHOW ROLES ?`, `SHOW ROLES`},
+
+		{`REFRESH ?`, `REFRESH MATERIALIZED VIEW`},
+		{`REFRESH MATERIALIZED VIEW blah ?`, `REFRESH MATERIALIZED VIEW`},
 
 		{`TRUNCATE foo ?`, `TRUNCATE`},
 		{`TRUNCATE foo, ?`, `TRUNCATE`},
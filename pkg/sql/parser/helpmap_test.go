@@ -8,6 +8,7 @@ var expectedHelpStrings = []string{
 	"<SOURCE>",
 	"ALTER DATABASE",
 	"ALTER INDEX",
+	"ALTER SEQUENCE",
 	"ALTER TABLE",
 	"ALTER VIEW",
 	"ALTER",
@@ -18,8 +19,13 @@ var expectedHelpStrings = []string{
 	"CANCEL",
 	"COMMIT",
 	"CREATE DATABASE",
+	"CREATE FUNCTION",
 	"CREATE INDEX",
+	"CREATE ROLE",
+	"CREATE SEQUENCE",
+	"CREATE STATISTICS",
 	"CREATE TABLE",
+	"CREATE TRIGGER",
 	"CREATE USER",
 	"CREATE VIEW",
 	"CREATE",
@@ -27,8 +33,12 @@ var expectedHelpStrings = []string{
 	"DELETE",
 	"DISCARD",
 	"DROP DATABASE",
+	"DROP FUNCTION",
 	"DROP INDEX",
+	"DROP ROLE",
+	"DROP SEQUENCE",
 	"DROP TABLE",
+	"DROP TRIGGER",
 	"DROP USER",
 	"DROP VIEW",
 	"DROP",
@@ -39,6 +49,7 @@ var expectedHelpStrings = []string{
 	"INSERT",
 	"PAUSE JOB",
 	"PREPARE",
+	"REFRESH MATERIALIZED VIEW",
 	"RELEASE",
 	"RESET CLUSTER SETTING",
 	"RESET",
@@ -55,6 +66,7 @@ var expectedHelpStrings = []string{
 	"SHOW CLUSTER SETTING",
 	"SHOW COLUMNS",
 	"SHOW CONSTRAINTS",
+	"SHOW CREATE SEQUENCE",
 	"SHOW CREATE TABLE",
 	"SHOW CREATE VIEW",
 	"SHOW DATABASES",
@@ -62,8 +74,10 @@ var expectedHelpStrings = []string{
 	"SHOW INDEXES",
 	"SHOW JOBS",
 	"SHOW QUERIES",
+	"SHOW ROLES",
 	"SHOW SESSION",
 	"SHOW SESSIONS",
+	"SHOW STATISTICS",
 	"SHOW TABLES",
 	"SHOW TRACE",
 	"SHOW TRANSACTION",
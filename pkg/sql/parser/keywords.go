@@ -6,6 +6,8 @@ package parser
 var keywords = map[string]int{
 	"ACTION":                    ACTION,
 	"ADD":                       ADD,
+	"ADMIN":                     ADMIN,
+	"AFTER":                     AFTER,
 	"ALL":                       ALL,
 	"ALTER":                     ALTER,
 	"ANALYSE":                   ANALYSE,
@@ -19,6 +21,7 @@ var keywords = map[string]int{
 	"ASYMMETRIC":                ASYMMETRIC,
 	"AT":                        AT,
 	"BACKUP":                    BACKUP,
+	"BEFORE":                    BEFORE,
 	"BEGIN":                     BEGIN,
 	"BETWEEN":                   BETWEEN,
 	"BIGINT":                    BIGINT,
@@ -31,6 +34,7 @@ var keywords = map[string]int{
 	"BY":                        BY,
 	"BYTEA":                     BYTEA,
 	"BYTES":                     BYTES,
+	"CACHE":                     CACHE,
 	"CANCEL":                    CANCEL,
 	"CASCADE":                   CASCADE,
 	"CASE":                      CASE,
@@ -82,6 +86,7 @@ var keywords = map[string]int{
 	"DO":                        DO,
 	"DOUBLE":                    DOUBLE,
 	"DROP":                      DROP,
+	"EACH":                      EACH,
 	"ELSE":                      ELSE,
 	"ENCODING":                  ENCODING,
 	"END":                       END,
@@ -106,6 +111,7 @@ var keywords = map[string]int{
 	"FOREIGN":                   FOREIGN,
 	"FROM":                      FROM,
 	"FULL":                      FULL,
+	"FUNCTION":                  FUNCTION,
 	"GRANT":                     GRANT,
 	"GRANTS":                    GRANTS,
 	"GREATEST":                  GREATEST,
@@ -119,9 +125,11 @@ var keywords = map[string]int{
 	"ILIKE":                     ILIKE,
 	"IMPORT":                    IMPORT,
 	"IN":                        IN,
+	"INCREMENT":                 INCREMENT,
 	"INCREMENTAL":               INCREMENTAL,
 	"INDEX":                     INDEX,
 	"INDEXES":                   INDEXES,
+	"INET":                      INET,
 	"INITIALLY":                 INITIALLY,
 	"INNER":                     INNER,
 	"INSERT":                    INSERT,
@@ -136,14 +144,18 @@ var keywords = map[string]int{
 	"INTERSECT":                 INTERSECT,
 	"INTERVAL":                  INTERVAL,
 	"INTO":                      INTO,
+	"INVERTED":                  INVERTED,
 	"IS":                        IS,
 	"ISOLATION":                 ISOLATION,
 	"JOB":                       JOB,
 	"JOBS":                      JOBS,
 	"JOIN":                      JOIN,
+	"JSON":                      JSON,
+	"JSONB":                     JSONB,
 	"KEY":                       KEY,
 	"KEYS":                      KEYS,
 	"KV":                        KV,
+	"LANGUAGE":                  LANGUAGE,
 	"LATERAL":                   LATERAL,
 	"LC_COLLATE":                LC_COLLATE,
 	"LC_CTYPE":                  LC_CTYPE,
@@ -153,12 +165,16 @@ var keywords = map[string]int{
 	"LEVEL":                     LEVEL,
 	"LIKE":                      LIKE,
 	"LIMIT":                     LIMIT,
+	"LIST":                      LIST,
 	"LOCAL":                     LOCAL,
 	"LOCALTIME":                 LOCALTIME,
 	"LOCALTIMESTAMP":            LOCALTIMESTAMP,
 	"LOW":                       LOW,
 	"MATCH":                     MATCH,
+	"MATERIALIZED":              MATERIALIZED,
+	"MAXVALUE":                  MAXVALUE,
 	"MINUTE":                    MINUTE,
+	"MINVALUE":                  MINVALUE,
 	"MONTH":                     MONTH,
 	"NAME":                      NAME,
 	"NAMES":                     NAMES,
@@ -180,6 +196,7 @@ var keywords = map[string]int{
 	"OID":                       OID,
 	"ON":                        ON,
 	"ONLY":                      ONLY,
+	"OPTION":                    OPTION,
 	"OPTIONS":                   OPTIONS,
 	"OR":                        OR,
 	"ORDER":                     ORDER,
@@ -202,6 +219,7 @@ var keywords = map[string]int{
 	"PREPARE":                   PREPARE,
 	"PRIMARY":                   PRIMARY,
 	"PRIORITY":                  PRIORITY,
+	"PROCEDURE":                 PROCEDURE,
 	"QUERIES":                   QUERIES,
 	"QUERY":                     QUERY,
 	"RANGE":                     RANGE,
@@ -210,6 +228,7 @@ var keywords = map[string]int{
 	"RECURSIVE":                 RECURSIVE,
 	"REF":                       REF,
 	"REFERENCES":                REFERENCES,
+	"REFRESH":                   REFRESH,
 	"REGCLASS":                  REGCLASS,
 	"REGNAMESPACE":              REGNAMESPACE,
 	"REGPROC":                   REGPROC,
@@ -218,13 +237,17 @@ var keywords = map[string]int{
 	"RELEASE":                   RELEASE,
 	"RENAME":                    RENAME,
 	"REPEATABLE":                REPEATABLE,
+	"REPLACE":                   REPLACE,
 	"RESET":                     RESET,
 	"RESTORE":                   RESTORE,
 	"RESTRICT":                  RESTRICT,
 	"RESUME":                    RESUME,
 	"RETURNING":                 RETURNING,
+	"RETURNS":                   RETURNS,
 	"REVOKE":                    REVOKE,
 	"RIGHT":                     RIGHT,
+	"ROLE":                      ROLE,
+	"ROLES":                     ROLES,
 	"ROLLBACK":                  ROLLBACK,
 	"ROLLUP":                    ROLLUP,
 	"ROW":                       ROW,
@@ -234,6 +257,7 @@ var keywords = map[string]int{
 	"SEARCH":                    SEARCH,
 	"SECOND":                    SECOND,
 	"SELECT":                    SELECT,
+	"SEQUENCE":                  SEQUENCE,
 	"SEQUENCES":                 SEQUENCES,
 	"SERIAL":                    SERIAL,
 	"SERIALIZABLE":              SERIALIZABLE,
@@ -241,6 +265,7 @@ var keywords = map[string]int{
 	"SESSIONS":                  SESSIONS,
 	"SESSION_USER":              SESSION_USER,
 	"SET":                       SET,
+	"SETS":                      SETS,
 	"SETTING":                   SETTING,
 	"SETTINGS":                  SETTINGS,
 	"SHOW":                      SHOW,
@@ -253,6 +278,7 @@ var keywords = map[string]int{
 	"SPLIT":                     SPLIT,
 	"SQL":                       SQL,
 	"START":                     START,
+	"STATISTICS":                STATISTICS,
 	"STATUS":                    STATUS,
 	"STDIN":                     STDIN,
 	"STORE":                     STORE,
@@ -279,6 +305,7 @@ var keywords = map[string]int{
 	"TRAILING":                  TRAILING,
 	"TRANSACTION":               TRANSACTION,
 	"TREAT":                     TREAT,
+	"TRIGGER":                   TRIGGER,
 	"TRIM":                      TRIM,
 	"TRUE":                      TRUE,
 	"TRUNCATE":                  TRUNCATE,
@@ -303,6 +330,7 @@ var keywords = map[string]int{
 	"VARIADIC":                  VARIADIC,
 	"VARYING":                   VARYING,
 	"VIEW":                      VIEW,
+	"VOID":                      VOID,
 	"WHEN":                      WHEN,
 	"WHERE":                     WHERE,
 	"WINDOW":                    WINDOW,
@@ -312,4 +340,6 @@ var keywords = map[string]int{
 	"WRITE":                     WRITE,
 	"YEAR":                      YEAR,
 	"ZONE":                      ZONE,
+	"{}":                        {},
+	"{}":                        {},
 }
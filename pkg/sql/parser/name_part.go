@@ -170,8 +170,14 @@ func (l NameParts) Format(buf *bytes.Buffer, f FmtFlags) {
 type UnresolvedName NameParts
 
 // Format implements the NodeFormatter interface.
-func (u UnresolvedName) Format(buf *bytes.Buffer, f FmtFlags) { FormatNode(buf, f, NameParts(u)) }
-func (u UnresolvedName) String() string                       { return AsString(u) }
+func (u UnresolvedName) Format(buf *bytes.Buffer, f FmtFlags) {
+	if f.unresolvedNameFormatter != nil && f.unresolvedNameFormatter(u, buf, f) {
+		return
+	}
+	FormatNode(buf, f, NameParts(u))
+}
+
+func (u UnresolvedName) String() string { return AsString(u) }
 
 // UnresolvedNames corresponds to a comma-separate list of unresolved
 // names.  Note: this should be treated as immutable when embedded in
@@ -181,6 +187,9 @@ type UnresolvedNames []UnresolvedName
 
 // Format implements the NodeFormatter interface.
 func (u UnresolvedNames) Format(buf *bytes.Buffer, f FmtFlags) {
+	// Lists of names are used for the target columns of INSERT and UPDATE,
+	// which are not expressions; leave them alone.
+	f = withoutUnresolvedNameFormatter(f)
 	for i, n := range u {
 		if i > 0 {
 			buf.WriteString(", ")
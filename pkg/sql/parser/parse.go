@@ -236,6 +236,10 @@ func ParseStringAs(t Type, s string, location *time.Location) (Datum, error) {
 		d, err = ParseDTimestampTZ(s, location, time.Microsecond)
 	case TypeUUID:
 		d, err = ParseDUuidFromString(s)
+	case TypeINet:
+		d, err = ParseDIPAddrFromINetString(s)
+	case TypeJSON:
+		d, err = ParseDJSON(s)
 	default:
 		return nil, pgerror.NewErrorf(pgerror.CodeInternalError, "unknown type %s", t)
 	}
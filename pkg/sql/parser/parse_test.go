@@ -83,10 +83,19 @@ func TestParse(t *testing.T) {
 		{`CREATE UNIQUE INDEX a ON b (c) STORING (d)`},
 		{`CREATE UNIQUE INDEX a ON b (c) INTERLEAVE IN PARENT d (e, f)`},
 		{`CREATE UNIQUE INDEX a ON b (c) INTERLEAVE IN PARENT d.e (f, g)`},
+		{`CREATE INDEX a ON b (c) STORING (d) PARTITION BY LIST (c) (PARTITION p1 VALUES IN (1))`},
+		{`CREATE INDEX a ON b (c) PARTITION BY RANGE (c) (PARTITION p1 VALUES FROM (1) TO (2))`},
 		{`CREATE UNIQUE INDEX a ON b.c (d)`},
+		{`CREATE INVERTED INDEX a ON b (c)`},
+		{`CREATE INVERTED INDEX IF NOT EXISTS a ON b (c)`},
+		{`CREATE INDEX a ON b ((lower(c)))`},
+		{`CREATE INDEX a ON b ((c + d) DESC, e)`},
+		{`CREATE UNIQUE INDEX a ON b ((lower(c)), (d::STRING)) STORING (e)`},
 
 		{`CREATE TABLE a ()`},
 		{`CREATE TABLE a (b INT)`},
+		{`CREATE TEMP TABLE a (b INT)`},
+		{`CREATE TEMP TABLE IF NOT EXISTS a (b INT)`},
 		{`CREATE TABLE a (b INT, c INT)`},
 		{`CREATE TABLE a (b CHAR)`},
 		{`CREATE TABLE a (b CHAR(3))`},
@@ -99,6 +108,10 @@ func TestParse(t *testing.T) {
 		{`CREATE TABLE a (b SMALLSERIAL)`},
 		{`CREATE TABLE a (b BIGSERIAL)`},
 		{`CREATE TABLE a (b UUID)`},
+		{`CREATE TABLE a (b JSON)`},
+		{`CREATE TABLE a (b JSONB)`},
+		{`CREATE TABLE a (b INET)`},
+		{`CREATE TABLE a (b INET[])`},
 		{`CREATE TABLE a (b INT NULL)`},
 		{`CREATE TABLE a (b INT CONSTRAINT maybe NULL)`},
 		{`CREATE TABLE a (b INT NOT NULL)`},
@@ -122,6 +135,9 @@ func TestParse(t *testing.T) {
 		{`CREATE TABLE a (b INT, c TEXT, FOREIGN KEY (b, c) REFERENCES other)`},
 		{`CREATE TABLE a (b INT, c TEXT, FOREIGN KEY (b, c) REFERENCES other (x, y))`},
 		{`CREATE TABLE a (b INT, c TEXT, CONSTRAINT s FOREIGN KEY (b, c) REFERENCES other (x, y))`},
+		{`CREATE TABLE a (b INT, FOREIGN KEY (b) REFERENCES other ON DELETE CASCADE)`},
+		{`CREATE TABLE a (b INT, FOREIGN KEY (b) REFERENCES other ON UPDATE SET NULL)`},
+		{`CREATE TABLE a (b INT, FOREIGN KEY (b) REFERENCES other (x) ON DELETE SET DEFAULT ON UPDATE RESTRICT)`},
 		{`CREATE TABLE a (b INT, c TEXT, INDEX (b, c))`},
 		{`CREATE TABLE a (b INT, c TEXT, INDEX d (b, c))`},
 		{`CREATE TABLE a (b INT, c TEXT, CONSTRAINT d UNIQUE (b, c))`},
@@ -129,9 +145,12 @@ func TestParse(t *testing.T) {
 		{`CREATE TABLE a (b INT, UNIQUE (b))`},
 		{`CREATE TABLE a (b INT, UNIQUE (b) STORING (c))`},
 		{`CREATE TABLE a (b INT, INDEX (b))`},
+		{`CREATE TABLE a (b JSONB, INVERTED INDEX (b))`},
+		{`CREATE TABLE a (b JSONB, INVERTED INDEX c (b))`},
 		{`CREATE TABLE a (b INT, c INT REFERENCES foo)`},
 		{`CREATE TABLE a (b INT, c INT CONSTRAINT ref REFERENCES foo)`},
 		{`CREATE TABLE a (b INT, c INT REFERENCES foo (bar))`},
+		{`CREATE TABLE a (b INT, c INT REFERENCES foo (bar) ON DELETE CASCADE ON UPDATE CASCADE)`},
 		{`CREATE TABLE a (b INT, INDEX (b) STORING (c))`},
 		{`CREATE TABLE a (b INT, c TEXT, INDEX (b ASC, c DESC) STORING (c))`},
 		{`CREATE TABLE a (b INT, INDEX (b) INTERLEAVE IN PARENT c (d, e))`},
@@ -139,6 +158,12 @@ func TestParse(t *testing.T) {
 		{`CREATE TABLE a (b INT, c STRING, FAMILY foo (b), FAMILY (c))`},
 		{`CREATE TABLE a (b INT) INTERLEAVE IN PARENT foo (c, d)`},
 		{`CREATE TABLE a (b INT) INTERLEAVE IN PARENT foo (c) CASCADE`},
+		{`CREATE TABLE a (b INT) PARTITION BY LIST (b) (PARTITION p1 VALUES IN (1, 2), PARTITION p2 VALUES IN (DEFAULT))`},
+		{`CREATE TABLE a (b INT, c STRING) PARTITION BY LIST (b, c) (PARTITION p1 VALUES IN ((1, 'x'), (2, 'y')))`},
+		{`CREATE TABLE a (b INT) PARTITION BY RANGE (b) (PARTITION p1 VALUES FROM (MINVALUE) TO (10), PARTITION p2 VALUES FROM (10) TO (MAXVALUE))`},
+		{`CREATE TABLE a (b INT, c INT) PARTITION BY RANGE (b, c) (PARTITION p1 VALUES FROM (1, MINVALUE) TO (1, 10))`},
+		{`CREATE TABLE a (b INT, INDEX (b) PARTITION BY LIST (b) (PARTITION p1 VALUES IN (1)))`},
+		{`CREATE TABLE a (b INT) INTERLEAVE IN PARENT foo (b) PARTITION BY LIST (b) (PARTITION p1 VALUES IN (1))`},
 		{`CREATE TABLE a.b (b INT)`},
 		{`CREATE TABLE IF NOT EXISTS a (b INT)`},
 
@@ -156,6 +181,8 @@ func TestParse(t *testing.T) {
 		{`CREATE TABLE IF NOT EXISTS a AS SELECT * FROM b UNION SELECT * FROM c`},
 		{`CREATE TABLE a AS SELECT * FROM b UNION VALUES ('one', 1) ORDER BY c LIMIT 5`},
 		{`CREATE TABLE IF NOT EXISTS a AS SELECT * FROM b UNION VALUES ('one', 1) ORDER BY c LIMIT 5`},
+		{`CREATE TEMP TABLE a AS SELECT * FROM b`},
+		{`CREATE TEMP TABLE IF NOT EXISTS a (x, y) AS SELECT * FROM b`},
 		{`CREATE TABLE a (b STRING COLLATE "DE")`},
 		{`CREATE TABLE a (b STRING[] COLLATE "DE")`},
 
@@ -166,6 +193,27 @@ func TestParse(t *testing.T) {
 		{`CREATE VIEW a AS VALUES (1, 'one'), (2, 'two')`},
 		{`CREATE VIEW a (x, y) AS VALUES (1, 'one'), (2, 'two')`},
 		{`CREATE VIEW a AS TABLE b`},
+		{`CREATE MATERIALIZED VIEW a AS SELECT c, d FROM b`},
+		{`CREATE MATERIALIZED VIEW a (x, y) AS SELECT c, d FROM b`},
+
+		{`CREATE SEQUENCE a`},
+		{`CREATE SEQUENCE IF NOT EXISTS a`},
+		{`CREATE SEQUENCE a CYCLE`},
+		{`CREATE SEQUENCE a NO CYCLE`},
+		{`CREATE SEQUENCE a CACHE 0`},
+		{`CREATE SEQUENCE a INCREMENT 5`},
+		{`CREATE SEQUENCE a INCREMENT BY 5`},
+		{`CREATE SEQUENCE a NO MAXVALUE`},
+		{`CREATE SEQUENCE a MAXVALUE 1000`},
+		{`CREATE SEQUENCE a NO MINVALUE`},
+		{`CREATE SEQUENCE a MINVALUE 1000`},
+		{`CREATE SEQUENCE a START 1000`},
+		{`CREATE SEQUENCE a START WITH 1000`},
+		{`CREATE SEQUENCE a INCREMENT BY -1 MINVALUE -100 MAXVALUE -1 START WITH -1`},
+		{`CREATE SEQUENCE a INCREMENT BY 5 NO MAXVALUE MINVALUE 1 START 3`},
+
+		{`CREATE STATISTICS a ON col1 FROM t`},
+		{`CREATE STATISTICS a ON col1, col2 FROM d.t`},
 
 		{`DELETE FROM a`},
 		{`DELETE FROM a.b`},
@@ -177,6 +225,7 @@ func TestParse(t *testing.T) {
 		{`DELETE FROM a WHERE a = b RETURNING NOTHING`},
 
 		{`DISCARD ALL`},
+		{`DISCARD TEMP`},
 
 		{`DROP DATABASE a`},
 		{`DROP DATABASE IF EXISTS a`},
@@ -211,9 +260,35 @@ func TestParse(t *testing.T) {
 		{`DROP VIEW a.b CASCADE`},
 		{`DROP VIEW a, b CASCADE`},
 
+		{`DROP SEQUENCE a`},
+		{`DROP SEQUENCE a.b`},
+		{`DROP SEQUENCE a, b`},
+		{`DROP SEQUENCE IF EXISTS a`},
+		{`DROP SEQUENCE a RESTRICT`},
+		{`DROP SEQUENCE IF EXISTS a, b RESTRICT`},
+		{`DROP SEQUENCE a.b CASCADE`},
+
 		{`DROP USER a`},
 		{`DROP USER a, b`},
 
+		{`CREATE ROLE a`},
+		{`DROP ROLE a`},
+		{`DROP ROLE a, b`},
+		{`DROP ROLE IF EXISTS a, b`},
+
+		{`CREATE FUNCTION f() RETURNS INT LANGUAGE sql AS 'SELECT 1'`},
+		{`CREATE FUNCTION a.f(INT, s STRING) RETURNS STRING LANGUAGE sql AS 'SELECT s || $1::STRING'`},
+		{`CREATE OR REPLACE FUNCTION f(x INT) RETURNS VOID LANGUAGE sql AS 'INSERT INTO t VALUES (x); DELETE FROM u'`},
+		{`CREATE FUNCTION f() RETURNS TRIGGER LANGUAGE sql AS 'INSERT INTO log VALUES (new.a)'`},
+		{`DROP FUNCTION f`},
+		{`DROP FUNCTION a.f()`},
+		{`DROP FUNCTION IF EXISTS f(INT, STRING)`},
+
+		{`CREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW EXECUTE FUNCTION f()`},
+		{`CREATE TRIGGER t AFTER INSERT OR UPDATE OR DELETE ON a.b FOR EACH ROW EXECUTE FUNCTION c.f()`},
+		{`DROP TRIGGER t ON a`},
+		{`DROP TRIGGER IF EXISTS t ON a.b`},
+
 		{`CANCEL JOB a`},
 		{`CANCEL QUERY a`},
 		{`RESUME JOB a`},
@@ -222,6 +297,8 @@ func TestParse(t *testing.T) {
 		{`EXPLAIN SELECT 1`},
 		{`EXPLAIN EXPLAIN SELECT 1`},
 		{`EXPLAIN (A, B, C) SELECT 1`},
+		{`EXPLAIN (ANALYZE) SELECT 1`},
+		{`EXPLAIN (DISTSQL, ANALYZE) SELECT 1`},
 		{`SELECT * FROM [EXPLAIN SELECT 1]`},
 		{`SELECT * FROM [SHOW TRANSACTION STATUS]`},
 
@@ -243,7 +320,10 @@ func TestParse(t *testing.T) {
 		{`SHOW CONSTRAINTS FROM a.b.c`},
 		{`SHOW TABLES FROM a; SHOW COLUMNS FROM b`},
 		{`SHOW USERS`},
+		{`SHOW ROLES`},
 		{`SHOW JOBS`},
+		{`SHOW STATISTICS FOR TABLE t`},
+		{`SHOW STATISTICS FOR TABLE d.t`},
 		{`SHOW CLUSTER QUERIES`},
 		{`SHOW LOCAL QUERIES`},
 		{`SHOW CLUSTER SESSIONS`},
@@ -317,6 +397,10 @@ func TestParse(t *testing.T) {
 		{`GRANT SELECT, INSERT ON DATABASE db1, db2 TO foo, bar, baz`},
 		{`GRANT SELECT, INSERT ON DATABASE db1, db2 TO "test-user"`},
 
+		{`GRANT foo TO bar`},
+		{`GRANT foo, bar TO baz, qux`},
+		{`GRANT foo TO bar WITH ADMIN OPTION`},
+
 		// Tables are the default, but can also be specified with
 		// REVOKE x ON TABLE y. However, the stringer does not output TABLE.
 		{`REVOKE SELECT ON foo FROM root`},
@@ -326,6 +410,10 @@ func TestParse(t *testing.T) {
 		{`REVOKE SELECT, INSERT ON DATABASE bar FROM foo, bar, baz`},
 		{`REVOKE SELECT, INSERT ON DATABASE db1, db2 FROM foo, bar, baz`},
 
+		{`REVOKE foo FROM bar`},
+		{`REVOKE foo, bar FROM baz, qux`},
+		{`REVOKE ADMIN OPTION FOR foo FROM bar`},
+
 		{`INSERT INTO a VALUES (1)`},
 		{`INSERT INTO a.b VALUES (1)`},
 		{`INSERT INTO a VALUES (1, 2)`},
@@ -470,6 +558,10 @@ func TestParse(t *testing.T) {
 		{`SELECT a FROM generate_series(1, 32)`},
 		{`SELECT a FROM generate_series(1, 32) AS s (x)`},
 		{`SELECT a FROM generate_series(1, 32) WITH ORDINALITY AS s (x)`},
+		{`SELECT a FROM t, LATERAL (SELECT * FROM u WHERE u.b = t.a) AS s`},
+		{`SELECT a FROM t, LATERAL generate_series(1, t.a) WITH ORDINALITY AS s (x)`},
+		{`SELECT a FROM t CROSS JOIN LATERAL (SELECT t.a) AS s`},
+		{`SELECT a FROM t LEFT JOIN LATERAL (SELECT * FROM u WHERE u.b = t.a) AS s ON true`},
 		{`SELECT a FROM t1, t2`},
 		{`SELECT a FROM t AS t1`},
 		{`SELECT a FROM t AS t1 (c1)`},
@@ -501,6 +593,15 @@ func TestParse(t *testing.T) {
 		{`SELECT a FROM t WHERE a !~ b`},
 		{`SELECT a FROM t WHERE a ~* c`},
 		{`SELECT a FROM t WHERE a !~* c`},
+		{`SELECT a FROM t WHERE a @> b`},
+		{`SELECT a FROM t WHERE a <@ b`},
+		{`SELECT a FROM t WHERE a ? b`},
+		{`SELECT a -> b, a ->> b FROM t`},
+		{`SELECT (a -> b) -> c FROM t`},
+		{`SELECT (a ->> 'b') = c FROM t`},
+		{`SELECT a FROM t WHERE a << b`},
+		{`SELECT a FROM t WHERE a >> b`},
+		{`SELECT family(a), host(a) FROM t`},
 		{`SELECT a FROM t WHERE a BETWEEN b AND c`},
 		{`SELECT a FROM t WHERE a NOT BETWEEN b AND c`},
 		{`SELECT a FROM t WHERE a IS NULL`},
@@ -545,6 +646,12 @@ func TestParse(t *testing.T) {
 
 		{`SELECT 1 FROM t GROUP BY a`},
 		{`SELECT 1 FROM t GROUP BY a, b`},
+		{`SELECT 1 FROM t GROUP BY ()`},
+		{`SELECT a, b FROM t GROUP BY ROLLUP(a, b)`},
+		{`SELECT a, b FROM t GROUP BY CUBE(a, (b, c))`},
+		{`SELECT a, b FROM t GROUP BY GROUPING SETS ((a, b), (a), ())`},
+		{`SELECT a, b FROM t GROUP BY a, ROLLUP(b), GROUPING SETS (c, CUBE(d, e))`},
+		{`SELECT a, grouping(a, b) FROM t GROUP BY ROLLUP(a, b)`},
 
 		{`SELECT a FROM t HAVING a = b`},
 
@@ -563,6 +670,16 @@ func TestParse(t *testing.T) {
 		{`SELECT avg(1) OVER (ORDER BY c) FROM t`},
 		{`SELECT avg(1) OVER (PARTITION BY b ORDER BY c) FROM t`},
 		{`SELECT avg(1) OVER (w PARTITION BY b ORDER BY c) FROM t`},
+		{`SELECT avg(1) OVER (ROWS UNBOUNDED PRECEDING) FROM t`},
+		{`SELECT avg(1) OVER (ROWS 1 PRECEDING) FROM t`},
+		{`SELECT avg(1) OVER (ROWS CURRENT ROW) FROM t`},
+		{`SELECT avg(1) OVER (w ROWS BETWEEN 1 PRECEDING AND 1 FOLLOWING) FROM t`},
+		{`SELECT avg(1) OVER (ORDER BY c ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) FROM t`},
+		{`SELECT avg(1) OVER (PARTITION BY b ORDER BY c ROWS BETWEEN CURRENT ROW AND UNBOUNDED FOLLOWING) FROM t`},
+		{`SELECT avg(1) OVER (RANGE UNBOUNDED PRECEDING) FROM t`},
+		{`SELECT avg(1) OVER (ORDER BY c RANGE BETWEEN 2 PRECEDING AND 1 + 2 FOLLOWING) FROM t`},
+		{`SELECT avg(1) OVER (ORDER BY c RANGE BETWEEN 1 FOLLOWING AND UNBOUNDED FOLLOWING) FROM t`},
+		{`SELECT a FROM t WINDOW w AS (ORDER BY c ROWS BETWEEN $1 PRECEDING AND $2 FOLLOWING)`},
 
 		{`SELECT a FROM t UNION SELECT 1 FROM t`},
 		{`SELECT a FROM t UNION SELECT 1 FROM t UNION SELECT 1 FROM t`},
@@ -583,6 +700,16 @@ func TestParse(t *testing.T) {
 		{`SELECT a FROM t1 FULL JOIN t2 USING (a)`},
 		{`SELECT * FROM (t1 WITH ORDINALITY AS o1 CROSS JOIN t2 WITH ORDINALITY AS o2) WITH ORDINALITY AS o3`},
 
+		{`WITH a AS (SELECT 1) SELECT * FROM a`},
+		{`WITH a (x, y) AS (SELECT 1, 2), b AS (SELECT x FROM a) SELECT * FROM a, b`},
+		{`WITH a AS (INSERT INTO t VALUES (1) RETURNING k) SELECT * FROM a`},
+		{`WITH RECURSIVE a (n) AS (SELECT 1 UNION ALL SELECT n + 1 FROM a WHERE n < 10) SELECT n FROM a`},
+		{`SELECT * FROM (WITH a AS (SELECT 1) SELECT * FROM a)`},
+		{`WITH a AS (SELECT 1) INSERT INTO t SELECT * FROM a`},
+		{`WITH a AS (SELECT 1) UPSERT INTO t SELECT * FROM a`},
+		{`WITH a AS (SELECT 1) UPDATE t SET v = 1 WHERE k IN (SELECT * FROM a)`},
+		{`WITH a AS (SELECT 1) DELETE FROM t WHERE k IN (SELECT * FROM a)`},
+
 		{`SELECT a FROM t1 AS OF SYSTEM TIME '2016-01-01'`},
 		{`SELECT a FROM t1, t2 AS OF SYSTEM TIME '2016-01-01'`},
 
@@ -628,6 +755,9 @@ func TestParse(t *testing.T) {
 		{`TABLE a`}, // Shorthand for: SELECT * FROM a; used e.g. in CREATE VIEW v AS TABLE t
 		{`TABLE [123 AS a]`},
 
+		{`REFRESH MATERIALIZED VIEW a`},
+		{`REFRESH MATERIALIZED VIEW a.b`},
+
 		{`TRUNCATE TABLE a`},
 		{`TRUNCATE TABLE a, b.c`},
 		{`TRUNCATE TABLE a CASCADE`},
@@ -644,6 +774,8 @@ func TestParse(t *testing.T) {
 		{`UPDATE a SET b = 3 WHERE a = b RETURNING 1, 2`},
 		{`UPDATE a SET b = 3 WHERE a = b RETURNING a, a + b`},
 		{`UPDATE a SET b = 3 WHERE a = b RETURNING NOTHING`},
+		{`UPDATE a SET b = c.d FROM c WHERE a.e = c.e`},
+		{`UPDATE a AS x SET b = y.c FROM c AS y, d WHERE (x.e = y.e) AND (y.f = d.f) RETURNING x.b, y.c`},
 
 		{`UPDATE t AS "0" SET k = ''`},                 // "0" lost its quotes
 		{`SELECT * FROM "0" JOIN "0" USING (id, "0")`}, // last "0" lost its quotes.
@@ -651,6 +783,11 @@ func TestParse(t *testing.T) {
 		{`ALTER DATABASE a RENAME TO b`},
 		{`ALTER TABLE a RENAME TO b`},
 		{`ALTER TABLE IF EXISTS a RENAME TO b`},
+		{`ALTER SEQUENCE a RENAME TO b`},
+		{`ALTER SEQUENCE IF EXISTS a RENAME TO b`},
+		{`ALTER SEQUENCE a INCREMENT BY 5 START WITH 1000`},
+		{`ALTER SEQUENCE IF EXISTS a INCREMENT BY 5 START WITH 1000`},
+		{`ALTER SEQUENCE a NO CYCLE NO MINVALUE MAXVALUE 100`},
 		{`ALTER INDEX a@b RENAME TO b`},
 		{`ALTER INDEX b RENAME TO b`},
 		{`ALTER INDEX IF EXISTS a@b RENAME TO b`},
@@ -683,12 +820,19 @@ func TestParse(t *testing.T) {
 		{`ALTER TABLE a DROP CONSTRAINT b CASCADE`},
 		{`ALTER TABLE a DROP CONSTRAINT IF EXISTS b RESTRICT`},
 		{`ALTER TABLE a VALIDATE CONSTRAINT a`},
+		{`ALTER TABLE a ALTER CONSTRAINT a VALID`},
+		{`ALTER TABLE a ALTER CONSTRAINT a NOT VALID`},
 
 		{`ALTER TABLE a ALTER COLUMN b SET DEFAULT 42`},
 		{`ALTER TABLE a ALTER COLUMN b SET DEFAULT NULL`},
 		{`ALTER TABLE a ALTER COLUMN b DROP DEFAULT`},
 		{`ALTER TABLE a ALTER COLUMN b DROP NOT NULL`},
 		{`ALTER TABLE a ALTER b DROP NOT NULL`},
+		{`ALTER TABLE a ALTER COLUMN b SET NOT NULL`},
+		{`ALTER TABLE a ALTER b SET NOT NULL`},
+		{`ALTER TABLE a ALTER COLUMN b TYPE INT`},
+		{`ALTER TABLE a ALTER b TYPE STRING(10)`},
+		{`ALTER TABLE a ALTER COLUMN b TYPE DECIMAL USING b::DECIMAL * 100`},
 
 		{`COPY t FROM STDIN`},
 		{`COPY t (a, b, c) FROM STDIN`},
@@ -759,15 +903,40 @@ func TestParse2(t *testing.T) {
 	}{
 		{`CREATE DATABASE a WITH ENCODING = 'foo'`,
 			`CREATE DATABASE a ENCODING = 'foo'`},
+		{`EXPLAIN ANALYZE SELECT 1`, `EXPLAIN (ANALYZE) SELECT 1`},
+		{`CREATE FUNCTION f(INT) RETURNS INT AS 'SELECT $1 + 1' LANGUAGE SQL`,
+			`CREATE FUNCTION f(INT) RETURNS INT LANGUAGE sql AS 'SELECT $1 + 1'`},
+		{`CREATE TRIGGER t AFTER DELETE ON a FOR EACH ROW EXECUTE PROCEDURE f()`,
+			`CREATE TRIGGER t AFTER DELETE ON a FOR EACH ROW EXECUTE FUNCTION f()`},
+		{`SELECT GROUPING(a), rollup(a) FROM t GROUP BY rollup(a)`,
+			`SELECT grouping(a), rollup(a) FROM t GROUP BY ROLLUP(a)`},
+		{`SELECT a FROM t GROUP BY grouping sets (a, cube (a))`,
+			`SELECT a FROM t GROUP BY GROUPING SETS (a, CUBE(a))`},
+		{`SELECT a->'b'->>'c', a@>b, a<@b, a?'b' FROM t`,
+			`SELECT (a -> 'b') ->> 'c', a @> b, a <@ b, a ? 'b' FROM t`},
+		{`SELECT a->'b' = c FROM t`, `SELECT (a -> 'b') = c FROM t`},
 		{`CREATE DATABASE a TEMPLATE = template0`,
 			`CREATE DATABASE a TEMPLATE = 'template0'`},
 		{`CREATE DATABASE a TEMPLATE = invalid`,
 			`CREATE DATABASE a TEMPLATE = 'invalid'`},
+		{`CREATE TEMPORARY TABLE a (b INT)`, `CREATE TEMP TABLE a (b INT)`},
+		{`DISCARD TEMPORARY`, `DISCARD TEMP`},
 		{`CREATE TABLE a (b INT, UNIQUE INDEX foo (b))`,
 			`CREATE TABLE a (b INT, CONSTRAINT foo UNIQUE (b))`},
 		{`CREATE TABLE a (b INT, UNIQUE INDEX foo (b) INTERLEAVE IN PARENT c (d))`,
 			`CREATE TABLE a (b INT, CONSTRAINT foo UNIQUE (b) INTERLEAVE IN PARENT c (d))`},
+		{`CREATE TABLE a (b INT) PARTITION BY RANGE (b) (PARTITION p1 VALUES FROM (minvalue) TO (maxvalue))`,
+			`CREATE TABLE a (b INT) PARTITION BY RANGE (b) (PARTITION p1 VALUES FROM (MINVALUE) TO (MAXVALUE))`},
 		{`CREATE INDEX ON a (b) COVERING (c)`, `CREATE INDEX ON a (b) STORING (c)`},
+		{`CREATE INDEX a ON b (lower(c))`, `CREATE INDEX a ON b ((lower(c)))`},
+		{`CREATE INDEX a ON b ((c) DESC)`, `CREATE INDEX a ON b (c DESC)`},
+		{`CREATE TABLE a (b TEXT, INDEX (lower(b)))`, `CREATE TABLE a (b TEXT, INDEX ((lower(b))))`},
+		{`ALTER TABLE a ALTER COLUMN b SET DATA TYPE INT8`,
+			`ALTER TABLE a ALTER COLUMN b TYPE INT8`},
+		{`CREATE TABLE a (b INT REFERENCES other ON DELETE NO ACTION)`,
+			`CREATE TABLE a (b INT REFERENCES other)`},
+		{`CREATE TABLE a (b INT, FOREIGN KEY (b) REFERENCES other ON UPDATE CASCADE ON DELETE SET NULL)`,
+			`CREATE TABLE a (b INT, FOREIGN KEY (b) REFERENCES other ON DELETE SET NULL ON UPDATE CASCADE)`},
 
 		{`SELECT TIMESTAMP WITHOUT TIME ZONE 'foo'`, `SELECT TIMESTAMP 'foo'`},
 		{`SELECT CAST('foo' AS TIMESTAMP WITHOUT TIME ZONE)`, `SELECT CAST('foo' AS TIMESTAMP)`},
@@ -867,6 +1036,8 @@ func TestParse2(t *testing.T) {
 			`SELECT current_user()`},
 		{`SELECT SESSION_USER`,
 			`SELECT current_user()`},
+		{`SELECT CURRENT_ROLE`,
+			`SELECT current_user()`},
 		{`SELECT USER`,
 			`SELECT current_user()`},
 		// Offset has an optional ROW/ROWS keyword.
@@ -962,6 +1133,11 @@ func TestParse2(t *testing.T) {
 		// Special extract syntax
 		{`SELECT EXTRACT(second from now())`,
 			`SELECT extract('second', now())`},
+		// Special AT TIME ZONE syntax
+		{`SELECT a AT TIME ZONE 'America/New_York'`,
+			`SELECT timezone('America/New_York', a)`},
+		{`SELECT a::TIMESTAMP AT TIME ZONE 'UTC' AT TIME ZONE INTERVAL '-08:00'`,
+			`SELECT timezone('-8h', timezone('UTC', a::TIMESTAMP))`},
 		// Special trim syntax
 		{`SELECT TRIM('xy' from 'xyxtrimyyx')`,
 			`SELECT btrim('xyxtrimyyx', 'xy')`},
@@ -987,6 +1163,8 @@ func TestParse2(t *testing.T) {
 			`SELECT rtrim('xyxtrimyyx')`},
 		{`SELECT a IS NAN`, `SELECT isnan(a)`},
 		{`SELECT a IS NOT NAN`, `SELECT NOT isnan(a)`},
+		{`SELECT a <<= b, a>>=b FROM t`,
+			`SELECT inet_contained_by_or_equals(a, b), inet_contains_or_equals(a, b) FROM t`},
 		{`SHOW INDEX FROM t`,
 			`SHOW INDEXES FROM t`},
 		{`SHOW CONSTRAINT FROM t`,
@@ -1107,6 +1285,10 @@ SET TIME ZONE INTERVAL 'foobar'
 		{`SELECT INTERVAL 'foo'`, `could not parse "foo" as type interval: interval: missing unit at position 0: "foo" at or near "EOF"
 SELECT INTERVAL 'foo'
                      ^
+`},
+		{`GRANT SELECT, FOO ON t TO bar`, `not a valid privilege: "foo" at or near "on"
+GRANT SELECT, FOO ON t TO bar
+                  ^
 `},
 		{`SELECT 1 /* hello`, `unterminated comment
 SELECT 1 /* hello
@@ -1175,6 +1357,36 @@ CREATE TABLE test (
   foo INT NULL NOT NULL
 )
 ^
+`},
+		{`SELECT avg(1) OVER (ROWS UNBOUNDED FOLLOWING) FROM t`,
+			`frame start cannot be UNBOUNDED FOLLOWING at or near "following"
+SELECT avg(1) OVER (ROWS UNBOUNDED FOLLOWING) FROM t
+                                   ^
+`},
+		{`SELECT avg(1) OVER (ROWS 1 FOLLOWING) FROM t`,
+			`frame starting from following row cannot end with current row at or near "following"
+SELECT avg(1) OVER (ROWS 1 FOLLOWING) FROM t
+                           ^
+`},
+		{`SELECT avg(1) OVER (ROWS BETWEEN UNBOUNDED FOLLOWING AND UNBOUNDED FOLLOWING) FROM t`,
+			`frame start cannot be UNBOUNDED FOLLOWING at or near "following"
+SELECT avg(1) OVER (ROWS BETWEEN UNBOUNDED FOLLOWING AND UNBOUNDED FOLLOWING) FROM t
+                                                                   ^
+`},
+		{`SELECT avg(1) OVER (ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED PRECEDING) FROM t`,
+			`frame end cannot be UNBOUNDED PRECEDING at or near "preceding"
+SELECT avg(1) OVER (ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED PRECEDING) FROM t
+                                                                   ^
+`},
+		{`SELECT avg(1) OVER (ROWS BETWEEN CURRENT ROW AND 1 PRECEDING) FROM t`,
+			`frame starting from current row cannot have preceding rows at or near "preceding"
+SELECT avg(1) OVER (ROWS BETWEEN CURRENT ROW AND 1 PRECEDING) FROM t
+                                                   ^
+`},
+		{`SELECT avg(1) OVER (ROWS BETWEEN 1 FOLLOWING AND CURRENT ROW) FROM t`,
+			`frame starting from following row cannot have preceding rows at or near "row"
+SELECT avg(1) OVER (ROWS BETWEEN 1 FOLLOWING AND CURRENT ROW) FROM t
+                                                         ^
 `},
 		{`CREATE DATABASE a b`,
 			`syntax error at or near "b"
@@ -37,7 +37,9 @@ var typeBuiltinsHaveUnderscore = map[oid.Oid]struct{}{
 	TypeAny.Oid():         {},
 	TypeDate.Oid():        {},
 	TypeDecimal.Oid():     {},
+	TypeINet.Oid():        {},
 	TypeInterval.Oid():    {},
+	TypeJSON.Oid():        {},
 	TypeUUID.Oid():        {},
 	TypeTimestamp.Oid():   {},
 	TypeTimestampTZ.Oid(): {},
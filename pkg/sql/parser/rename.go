@@ -36,20 +36,23 @@ func (node *RenameDatabase) Format(buf *bytes.Buffer, f FmtFlags) {
 	FormatNode(buf, f, node.NewName)
 }
 
-// RenameTable represents a RENAME TABLE or RENAME VIEW statement.
-// Whether the user has asked to rename a table or view is indicated
-// by the IsView field.
+// RenameTable represents a RENAME TABLE, RENAME VIEW or RENAME SEQUENCE
+// statement. Whether the user has asked to rename a table, view or sequence
+// is indicated by the IsView and IsSequence fields.
 type RenameTable struct {
-	Name     NormalizableTableName
-	NewName  NormalizableTableName
-	IfExists bool
-	IsView   bool
+	Name       NormalizableTableName
+	NewName    NormalizableTableName
+	IfExists   bool
+	IsView     bool
+	IsSequence bool
 }
 
 // Format implements the NodeFormatter interface.
 func (node *RenameTable) Format(buf *bytes.Buffer, f FmtFlags) {
 	if node.IsView {
 		buf.WriteString("ALTER VIEW ")
+	} else if node.IsSequence {
+		buf.WriteString("ALTER SEQUENCE ")
 	} else {
 		buf.WriteString("ALTER TABLE ")
 	}
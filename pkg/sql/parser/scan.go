@@ -321,15 +321,29 @@ func (s *Scanner) scan(lval *sqlSymType) {
 		return
 
 	case '?':
-		lval.id = HELPTOKEN
+		// A '?' at the end of the input requests contextual help; anywhere
+		// else it is the JSON key existence operator.
+		if s.onlyWhitespaceRemains() {
+			lval.id = HELPTOKEN
+		}
 		return
 
 	case '<':
 		switch s.peek() {
 		case '<': // <<
 			s.pos++
+			if s.peek() == '=' {
+				// <<=
+				s.pos++
+				lval.id = INET_CONTAINED_BY_OR_EQUALS
+				return
+			}
 			lval.id = LSHIFT
 			return
+		case '@': // <@
+			s.pos++
+			lval.id = CONTAINED_BY
+			return
 		case '>': // <>
 			s.pos++
 			lval.id = NOT_EQUALS
@@ -345,6 +359,12 @@ func (s *Scanner) scan(lval *sqlSymType) {
 		switch s.peek() {
 		case '>': // >>
 			s.pos++
+			if s.peek() == '=' {
+				// >>=
+				s.pos++
+				lval.id = INET_CONTAINS_OR_EQUALS
+				return
+			}
 			lval.id = RSHIFT
 			return
 		case '=': // >=
@@ -354,6 +374,30 @@ func (s *Scanner) scan(lval *sqlSymType) {
 		}
 		return
 
+	case '-':
+		switch s.peek() {
+		case '>': // ->
+			if s.peekN(1) == '>' {
+				// ->>
+				s.pos += 2
+				lval.id = FETCHTEXT
+				return
+			}
+			s.pos++
+			lval.id = FETCHVAL
+			return
+		}
+		return
+
+	case '@':
+		switch s.peek() {
+		case '>': // @>
+			s.pos++
+			lval.id = CONTAINS
+			return
+		}
+		return
+
 	case ':':
 		switch s.peek() {
 		case ':': // ::
@@ -411,6 +455,12 @@ func (s *Scanner) scan(lval *sqlSymType) {
 	// lval for above.
 }
 
+// onlyWhitespaceRemains returns true if there is nothing but whitespace
+// left to scan in the input.
+func (s *Scanner) onlyWhitespaceRemains() bool {
+	return strings.TrimSpace(s.in[s.pos:]) == ""
+}
+
 func (s *Scanner) peek() int {
 	if s.pos >= len(s.in) {
 		return eof
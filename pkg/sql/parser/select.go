@@ -38,6 +38,7 @@ func (*ValuesClause) selectStatement() {}
 
 // Select represents a SelectStatement with an ORDER and/or LIMIT.
 type Select struct {
+	With    *With
 	Select  SelectStatement
 	OrderBy OrderBy
 	Limit   *Limit
@@ -45,11 +46,50 @@ type Select struct {
 
 // Format implements the NodeFormatter interface.
 func (node *Select) Format(buf *bytes.Buffer, f FmtFlags) {
+	FormatNode(buf, f, node.With)
 	FormatNode(buf, f, node.Select)
 	FormatNode(buf, f, node.OrderBy)
 	FormatNode(buf, f, node.Limit)
 }
 
+// With represents a WITH statement.
+type With struct {
+	Recursive bool
+	CTEList   []*CTE
+}
+
+// Format implements the NodeFormatter interface.
+func (node *With) Format(buf *bytes.Buffer, f FmtFlags) {
+	if node == nil {
+		return
+	}
+	buf.WriteString("WITH ")
+	if node.Recursive {
+		buf.WriteString("RECURSIVE ")
+	}
+	for i, cte := range node.CTEList {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		FormatNode(buf, f, cte)
+	}
+	buf.WriteByte(' ')
+}
+
+// CTE represents a common table expression inside of a WITH clause.
+type CTE struct {
+	Name AliasClause
+	Stmt Statement
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CTE) Format(buf *bytes.Buffer, f FmtFlags) {
+	FormatNode(buf, f, node.Name)
+	buf.WriteString(" AS (")
+	FormatNode(buf, f, node.Stmt)
+	buf.WriteByte(')')
+}
+
 // ParenSelect represents a parenthesized SELECT/UNION/VALUES statement.
 type ParenSelect struct {
 	Select *Select
@@ -271,11 +311,15 @@ type AliasedTableExpr struct {
 	Expr       TableExpr
 	Hints      *IndexHints
 	Ordinality bool
+	Lateral    bool
 	As         AliasClause
 }
 
 // Format implements the NodeFormatter interface.
 func (node *AliasedTableExpr) Format(buf *bytes.Buffer, f FmtFlags) {
+	if node.Lateral {
+		buf.WriteString("LATERAL ")
+	}
 	FormatNode(buf, f, node.Expr)
 	if node.Hints != nil {
 		FormatNode(buf, f, node.Hints)
@@ -428,6 +472,50 @@ func (node GroupBy) Format(buf *bytes.Buffer, f FmtFlags) {
 	}
 }
 
+// GroupingSetType is the type of a GroupingSet.
+type GroupingSetType int
+
+// GroupingSetType values.
+const (
+	// GroupingSets represents GROUPING SETS (...).
+	GroupingSets GroupingSetType = iota
+	// Rollup represents ROLLUP (...).
+	Rollup
+	// Cube represents CUBE (...).
+	Cube
+)
+
+var groupingSetTypeName = [...]string{
+	GroupingSets: "GROUPING SETS",
+	Rollup:       "ROLLUP",
+	Cube:         "CUBE",
+}
+
+func (t GroupingSetType) String() string {
+	return groupingSetTypeName[t]
+}
+
+// GroupingSet represents a GROUPING SETS, ROLLUP or CUBE element of a GROUP
+// BY clause. The elements of ROLLUP and CUBE are expressions; a Tuple groups
+// several expressions into a single element. The elements of GROUPING SETS
+// can also be nested GroupingSets; an empty Tuple stands for the empty
+// grouping set.
+type GroupingSet struct {
+	Type  GroupingSetType
+	Exprs Exprs
+}
+
+// Format implements the NodeFormatter interface.
+func (node *GroupingSet) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString(node.Type.String())
+	if node.Type == GroupingSets {
+		buf.WriteByte(' ')
+	}
+	buf.WriteByte('(')
+	FormatNode(buf, f, node.Exprs)
+	buf.WriteByte(')')
+}
+
 // OrderBy represents an ORDER By clause.
 type OrderBy []*Order
 
@@ -546,6 +634,7 @@ type WindowDef struct {
 	RefName    Name
 	Partitions Exprs
 	OrderBy    OrderBy
+	Frame      *WindowFrame
 }
 
 // Format implements the NodeFormatter interface.
@@ -574,9 +663,103 @@ func (node *WindowDef) Format(buf *bytes.Buffer, f FmtFlags) {
 			buf.WriteString(tmpBuf.String()[1:])
 		}
 		needSpaceSeparator = true
-		_ = needSpaceSeparator // avoid compiler warning until TODO below is addressed.
 	}
-	// TODO(nvanbenschoten): Support Window Frames.
-	// if node.Frame != nil {}
+	if node.Frame != nil {
+		if needSpaceSeparator {
+			buf.WriteRune(' ')
+		}
+		FormatNode(buf, f, node.Frame)
+	}
 	buf.WriteRune(')')
 }
+
+// WindowFrameMode indicates which mode of framing is used.
+type WindowFrameMode int
+
+const (
+	// RangeMode is the mode of specifying frame in terms of logical range
+	// (e.g. 100 units cheaper).
+	RangeMode WindowFrameMode = iota
+	// RowsMode is the mode of specifying frame in terms of physical offsets
+	// (e.g. 1 row before etc).
+	RowsMode
+)
+
+var windowFrameModeName = [...]string{
+	RangeMode: "RANGE",
+	RowsMode:  "ROWS",
+}
+
+func (m WindowFrameMode) String() string {
+	return windowFrameModeName[m]
+}
+
+// WindowFrameBoundType indicates which type of boundary is used.
+type WindowFrameBoundType int
+
+const (
+	// UnboundedPreceding represents UNBOUNDED PRECEDING type of boundary.
+	UnboundedPreceding WindowFrameBoundType = iota
+	// ValuePreceding represents 'value' PRECEDING type of boundary.
+	ValuePreceding
+	// CurrentRow represents CURRENT ROW type of boundary.
+	CurrentRow
+	// ValueFollowing represents 'value' FOLLOWING type of boundary.
+	ValueFollowing
+	// UnboundedFollowing represents UNBOUNDED FOLLOWING type of boundary.
+	UnboundedFollowing
+)
+
+// WindowFrameBound specifies the offset and the type of boundary.
+type WindowFrameBound struct {
+	BoundType  WindowFrameBoundType
+	OffsetExpr Expr
+}
+
+// Format implements the NodeFormatter interface.
+func (node *WindowFrameBound) Format(buf *bytes.Buffer, f FmtFlags) {
+	switch node.BoundType {
+	case UnboundedPreceding:
+		buf.WriteString("UNBOUNDED PRECEDING")
+	case ValuePreceding:
+		FormatNode(buf, f, node.OffsetExpr)
+		buf.WriteString(" PRECEDING")
+	case CurrentRow:
+		buf.WriteString("CURRENT ROW")
+	case ValueFollowing:
+		FormatNode(buf, f, node.OffsetExpr)
+		buf.WriteString(" FOLLOWING")
+	case UnboundedFollowing:
+		buf.WriteString("UNBOUNDED FOLLOWING")
+	default:
+		panic(fmt.Sprintf("unhandled case: %d", node.BoundType))
+	}
+}
+
+// WindowFrameBounds specifies boundaries of the window frame.
+type WindowFrameBounds struct {
+	StartBound *WindowFrameBound
+	// EndBound is nil when the frame is specified with a single bound, in
+	// which case the frame ends at the current row.
+	EndBound *WindowFrameBound
+}
+
+// WindowFrame represents static state of window frame over which calculations are made.
+type WindowFrame struct {
+	Mode   WindowFrameMode
+	Bounds WindowFrameBounds
+}
+
+// Format implements the NodeFormatter interface.
+func (node *WindowFrame) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString(node.Mode.String())
+	buf.WriteRune(' ')
+	if node.Bounds.EndBound != nil {
+		buf.WriteString("BETWEEN ")
+		FormatNode(buf, f, node.Bounds.StartBound)
+		buf.WriteString(" AND ")
+		FormatNode(buf, f, node.Bounds.EndBound)
+	} else {
+		FormatNode(buf, f, node.Bounds.StartBound)
+	}
+}
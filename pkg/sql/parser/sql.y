@@ -169,6 +169,18 @@ func (u *sqlSymUnion) slct() *Select {
 func (u *sqlSymUnion) selectStmt() SelectStatement {
     return u.val.(SelectStatement)
 }
+func (u *sqlSymUnion) with() *With {
+    if with, ok := u.val.(*With); ok {
+        return with
+    }
+    return nil
+}
+func (u *sqlSymUnion) cte() *CTE {
+    return u.val.(*CTE)
+}
+func (u *sqlSymUnion) ctes() []*CTE {
+    return u.val.([]*CTE)
+}
 func (u *sqlSymUnion) colDef() *ColumnTableDef {
     return u.val.(*ColumnTableDef)
 }
@@ -265,9 +277,6 @@ func (u *sqlSymUnion) targetList() TargetList {
 func (u *sqlSymUnion) targetListPtr() *TargetList {
     return u.val.(*TargetList)
 }
-func (u *sqlSymUnion) privilegeType() privilege.Kind {
-    return u.val.(privilege.Kind)
-}
 func (u *sqlSymUnion) privilegeList() privilege.List {
     return u.val.(privilege.List)
 }
@@ -316,15 +325,45 @@ func (u *sqlSymUnion) dropBehavior() DropBehavior {
 func (u *sqlSymUnion) validationBehavior() ValidationBehavior {
     return u.val.(ValidationBehavior)
 }
+func (u *sqlSymUnion) referenceAction() ReferenceAction {
+    return u.val.(ReferenceAction)
+}
+func (u *sqlSymUnion) referenceActions() ReferenceActions {
+    return u.val.(ReferenceActions)
+}
 func (u *sqlSymUnion) interleave() *InterleaveDef {
     return u.val.(*InterleaveDef)
 }
+func (u *sqlSymUnion) partitionBy() *PartitionBy {
+    return u.val.(*PartitionBy)
+}
+func (u *sqlSymUnion) listPartition() ListPartition {
+    return u.val.(ListPartition)
+}
+func (u *sqlSymUnion) listPartitions() []ListPartition {
+    return u.val.([]ListPartition)
+}
+func (u *sqlSymUnion) rangePartition() RangePartition {
+    return u.val.(RangePartition)
+}
+func (u *sqlSymUnion) rangePartitions() []RangePartition {
+    return u.val.([]RangePartition)
+}
 func (u *sqlSymUnion) windowDef() *WindowDef {
     return u.val.(*WindowDef)
 }
 func (u *sqlSymUnion) window() Window {
     return u.val.(Window)
 }
+func (u *sqlSymUnion) windowFrame() *WindowFrame {
+    return u.val.(*WindowFrame)
+}
+func (u *sqlSymUnion) windowFrameBounds() WindowFrameBounds {
+    return u.val.(WindowFrameBounds)
+}
+func (u *sqlSymUnion) windowFrameBound() *WindowFrameBound {
+    return u.val.(*WindowFrameBound)
+}
 func (u *sqlSymUnion) op() operator {
     return u.val.(operator)
 }
@@ -346,6 +385,36 @@ func (u *sqlSymUnion) kvOptions() []KVOption {
 func (u *sqlSymUnion) transactionModes() TransactionModes {
     return u.val.(TransactionModes)
 }
+func (u *sqlSymUnion) int64() int64 {
+    return u.val.(int64)
+}
+func (u *sqlSymUnion) seqOpt() SequenceOption {
+    return u.val.(SequenceOption)
+}
+func (u *sqlSymUnion) seqOpts() []SequenceOption {
+    return u.val.([]SequenceOption)
+}
+func (u *sqlSymUnion) funcArg() FunctionArg {
+    return u.val.(FunctionArg)
+}
+func (u *sqlSymUnion) funcArgs() FunctionArgs {
+    if funcArgs, ok := u.val.(FunctionArgs); ok {
+        return funcArgs
+    }
+    return nil
+}
+func (u *sqlSymUnion) createFunction() *CreateFunction {
+    return u.val.(*CreateFunction)
+}
+func (u *sqlSymUnion) triggerTiming() TriggerTiming {
+    return u.val.(TriggerTiming)
+}
+func (u *sqlSymUnion) triggerEvent() TriggerEvent {
+    return u.val.(TriggerEvent)
+}
+func (u *sqlSymUnion) triggerEvents() []TriggerEvent {
+    return u.val.([]TriggerEvent)
+}
 
 %}
 
@@ -359,6 +428,8 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 %token <str>   TYPECAST TYPEANNOTATE DOT_DOT
 %token <str>   LESS_EQUALS GREATER_EQUALS NOT_EQUALS
 %token <str>   NOT_REGMATCH REGIMATCH NOT_REGIMATCH
+%token <str>   FETCHVAL FETCHTEXT CONTAINS CONTAINED_BY
+%token <str>   INET_CONTAINED_BY_OR_EQUALS INET_CONTAINS_OR_EQUALS
 %token <str>   ERROR
 
 // If you want to make any keyword changes, add the new keyword here as well as
@@ -366,14 +437,14 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 // below; search this file for "Keyword category lists".
 
 // Ordinary key words in alphabetical order.
-%token <str>   ACTION ADD
+%token <str>   ACTION ADD ADMIN AFTER
 %token <str>   ALL ALTER ANALYSE ANALYZE AND ANY ANNOTATE_TYPE ARRAY AS ASC
 %token <str>   ASYMMETRIC AT
 
-%token <str>   BACKUP BEGIN BETWEEN BIGINT BIGSERIAL BIT
+%token <str>   BACKUP BEFORE BEGIN BETWEEN BIGINT BIGSERIAL BIT
 %token <str>   BLOB BOOL BOOLEAN BOTH BY BYTEA BYTES
 
-%token <str>   CANCEL CASCADE CASE CAST CHAR
+%token <str>   CACHE CANCEL CASCADE CASE CAST CHAR
 %token <str>   CHARACTER CHARACTERISTICS CHECK
 %token <str>   CLUSTER COALESCE COLLATE COLLATION COLUMN COLUMNS COMMIT
 %token <str>   COMMITTED CONCAT CONFLICT CONSTRAINT CONSTRAINTS
@@ -386,63 +457,63 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 %token <str>   DEALLOCATE DEFERRABLE DELETE DESC
 %token <str>   DISCARD DISTINCT DO DOUBLE DROP
 
-%token <str>   ELSE ENCODING END ESCAPE EXCEPT
+%token <str>   EACH ELSE ENCODING END ESCAPE EXCEPT
 %token <str>   EXISTS EXECUTE EXPERIMENTAL_FINGERPRINTS EXPLAIN EXTRACT EXTRACT_DURATION
 
 %token <str>   FALSE FAMILY FETCH FILTER FIRST FLOAT FLOAT4 FLOAT8 FLOORDIV FOLLOWING FOR
-%token <str>   FORCE_INDEX FOREIGN FROM FULL
+%token <str>   FORCE_INDEX FOREIGN FROM FULL FUNCTION
 
 %token <str>   GRANT GRANTS GREATEST GROUP GROUPING
 
 %token <str>   HAVING HELP HIGH HOUR
 
-%token <str>   IMPORT INCREMENTAL IF IFNULL ILIKE IN INTERLEAVE
+%token <str>   IMPORT INCREMENT INCREMENTAL IF IFNULL ILIKE IN INTERLEAVE
 %token <str>   INDEX INDEXES INITIALLY
-%token <str>   INNER INSERT INT INT2VECTOR INT2 INT4 INT8 INT64 INTEGER
-%token <str>   INTERSECT INTERVAL INTO IS ISOLATION
+%token <str>   INET INNER INSERT INT INT2VECTOR INT2 INT4 INT8 INT64 INTEGER
+%token <str>   INTERSECT INTERVAL INTO INVERTED IS ISOLATION
 
-%token <str>   JOB JOBS JOIN
+%token <str>   JOB JOBS JOIN JSON JSONB
 
 %token <str>   KEY KEYS KV
 
-%token <str>   LATERAL LC_CTYPE LC_COLLATE
-%token <str>   LEADING LEAST LEFT LEVEL LIKE LIMIT LOCAL
+%token <str>   LANGUAGE LATERAL LC_CTYPE LC_COLLATE
+%token <str>   LEADING LEAST LEFT LEVEL LIKE LIMIT LIST LOCAL
 %token <str>   LOCALTIME LOCALTIMESTAMP LOW LSHIFT
 
-%token <str>   MATCH MINUTE MONTH
+%token <str>   MATCH MATERIALIZED MAXVALUE MINUTE MINVALUE MONTH
 
 %token <str>   NAN NAME NAMES NATURAL NEXT NO NO_INDEX_JOIN NORMAL
 %token <str>   NOT NOTHING NULL NULLIF
 %token <str>   NULLS NUMERIC
 
-%token <str>   OF OFF OFFSET OID ON ONLY OPTIONS OR
+%token <str>   OF OFF OFFSET OID ON ONLY OPTION OPTIONS OR
 %token <str>   ORDER ORDINALITY OUT OUTER OVER OVERLAPS OVERLAY
 
 %token <str>   PARENT PARTIAL PARTITION PASSWORD PAUSE PLACING PLANS POSITION
-%token <str>   PRECEDING PRECISION PREPARE PRIMARY PRIORITY
+%token <str>   PRECEDING PRECISION PREPARE PRIMARY PRIORITY PROCEDURE
 
 %token <str>   QUERIES QUERY
 
-%token <str>   RANGE READ REAL RECURSIVE REF REFERENCES
+%token <str>   RANGE READ REAL RECURSIVE REF REFERENCES REFRESH
 %token <str>   REGCLASS REGPROC REGPROCEDURE REGNAMESPACE REGTYPE
-%token <str>   RENAME REPEATABLE
-%token <str>   RELEASE RESET RESTORE RESTRICT RESUME RETURNING REVOKE RIGHT
-%token <str>   ROLLBACK ROLLUP ROW ROWS RSHIFT
+%token <str>   RENAME REPEATABLE REPLACE
+%token <str>   RELEASE RESET RESTORE RESTRICT RESUME RETURNING RETURNS REVOKE RIGHT
+%token <str>   ROLE ROLES ROLLBACK ROLLUP ROW ROWS RSHIFT
 
-%token <str>   SAVEPOINT SCATTER SEARCH SECOND SELECT SEQUENCES
-%token <str>   SERIAL SERIALIZABLE SESSION SESSIONS SESSION_USER SET SETTING SETTINGS
+%token <str>   SAVEPOINT SCATTER SEARCH SECOND SELECT SEQUENCE SEQUENCES
+%token <str>   SERIAL SERIALIZABLE SESSION SESSIONS SESSION_USER SET SETS SETTING SETTINGS
 %token <str>   SHOW SIMILAR SIMPLE SMALLINT SMALLSERIAL SNAPSHOT SOME SPLIT SQL
-%token <str>   START STATUS STDIN STRICT STRING STORE STORING SUBSTRING
+%token <str>   START STATISTICS STATUS STDIN STRICT STRING STORE STORING SUBSTRING
 %token <str>   SYMMETRIC SYSTEM
 
 %token <str>   TABLE TABLES TEMP TEMPLATE TEMPORARY TESTING_RANGES TESTING_RELOCATE TEXT THEN
 %token <str>   TIME TIMESTAMP TIMESTAMPTZ TO TRAILING TRACE TRANSACTION TREAT TRIM TRUE
-%token <str>   TRUNCATE TYPE
+%token <str>   TRIGGER TRUNCATE TYPE
 
 %token <str>   UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN
 %token <str>   UPDATE UPSERT USE USER USERS USING UUID
 
-%token <str>   VALID VALIDATE VALUE VALUES VARCHAR VARIADIC VIEW VARYING
+%token <str>   VALID VALIDATE VALUE VALUES VARCHAR VARIADIC VIEW VARYING VOID
 
 %token <str>   WHEN WHERE WINDOW WITH WITHIN WITHOUT WRITE
 
@@ -476,6 +547,7 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 %type <Statement> alter_table_stmt
 %type <Statement> alter_index_stmt
 %type <Statement> alter_view_stmt
+%type <Statement> alter_sequence_stmt
 %type <Statement> alter_database_stmt
 
 // ALTER TABLE
@@ -497,6 +569,10 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 // ALTER VIEW
 %type <Statement> alter_rename_view_stmt
 
+// ALTER SEQUENCE
+%type <Statement> alter_rename_sequence_stmt
+%type <Statement> alter_sequence_options_stmt
+
 %type <Statement> backup_stmt
 %type <Statement> begin_stmt
 
@@ -509,9 +585,14 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 
 %type <Statement> create_stmt
 %type <Statement> create_database_stmt
+%type <Statement> create_function_stmt
 %type <Statement> create_index_stmt
+%type <Statement> create_role_stmt
+%type <Statement> create_sequence_stmt
+%type <Statement> create_stats_stmt
 %type <Statement> create_table_stmt
 %type <Statement> create_table_as_stmt
+%type <Statement> create_trigger_stmt
 %type <Statement> create_user_stmt
 %type <Statement> create_view_stmt
 %type <Statement> delete_stmt
@@ -519,8 +600,12 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 
 %type <Statement> drop_stmt
 %type <Statement> drop_database_stmt
+%type <Statement> drop_function_stmt
 %type <Statement> drop_index_stmt
+%type <Statement> drop_role_stmt
+%type <Statement> drop_sequence_stmt
 %type <Statement> drop_table_stmt
+%type <Statement> drop_trigger_stmt
 %type <Statement> drop_user_stmt
 %type <Statement> drop_view_stmt
 
@@ -534,6 +619,7 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 %type <Statement> insert_stmt
 %type <Statement> import_stmt
 %type <Statement> pause_stmt
+%type <Statement> refresh_stmt
 %type <Statement> release_stmt
 %type <Statement> reset_stmt reset_session_stmt reset_csetting_stmt
 %type <Statement> resume_stmt
@@ -558,12 +644,15 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 %type <Statement> show_constraints_stmt
 %type <Statement> show_create_table_stmt
 %type <Statement> show_create_view_stmt
+%type <Statement> show_create_sequence_stmt
 %type <Statement> show_csettings_stmt
 %type <Statement> show_databases_stmt
 %type <Statement> show_grants_stmt
 %type <Statement> show_indexes_stmt
 %type <Statement> show_jobs_stmt
 %type <Statement> show_queries_stmt
+%type <Statement> show_roles_stmt
+%type <Statement> show_stats_stmt
 %type <Statement> show_session_stmt
 %type <Statement> show_sessions_stmt
 %type <Statement> show_tables_stmt
@@ -589,7 +678,7 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 %type <SelectStatement> select_clause select_with_parens simple_select values_clause table_clause simple_select_clause
 %type <SelectStatement> set_operation
 
-%type <empty> alter_using
+%type <Expr> alter_using
 %type <Expr> alter_column_default
 %type <Direction> opt_asc_desc
 
@@ -631,6 +720,13 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 
 %type <TableDefs> opt_table_elem_list table_elem_list
 %type <*InterleaveDef> opt_interleave
+%type <*PartitionBy> opt_partition_by partition_by
+%type <ListPartition> list_partition
+%type <[]ListPartition> list_partitions
+%type <RangePartition> range_partition
+%type <[]RangePartition> range_partitions
+%type <Expr> partition_value
+%type <Exprs> partition_value_list partition_values
 %type <empty> opt_all_clause
 %type <bool> distinct_clause
 %type <NameList> opt_column_list
@@ -639,8 +735,8 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 %type <IndexElemList> index_params
 %type <NameList> name_list opt_name_list
 %type <Exprs> opt_array_bounds
-%type <*From> from_clause update_from_clause
-%type <TableExprs> from_list
+%type <*From> from_clause
+%type <TableExprs> from_list update_from_clause
 %type <UnresolvedNames> qualified_name_list
 %type <TablePatterns> table_pattern_list
 %type <UnresolvedName> any_name
@@ -655,6 +751,8 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 %type <NamePart> name_indirection_elem
 %type <Exprs> ctext_expr_list ctext_row
 %type <GroupBy> group_clause
+%type <Exprs> group_by_list
+%type <Expr> group_by_item
 %type <*Limit> select_limit
 %type <TableNameReferences> relation_expr_list
 %type <ReturningClause> returning_clause
@@ -673,7 +771,13 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 %type <durationField> opt_interval interval_second
 %type <Expr> overlay_placing
 
-%type <bool> opt_unique opt_column
+%type <bool> opt_unique opt_column opt_temp opt_or_replace
+%type <FunctionArgs> opt_func_arg_list func_arg_list
+%type <FunctionArg> func_arg
+%type <*CreateFunction> func_return func_language_and_body
+%type <TriggerTiming> trigger_action_time
+%type <[]TriggerEvent> trigger_event_list
+%type <TriggerEvent> trigger_event
 
 %type <empty> opt_set_data
 
@@ -745,6 +849,9 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 %type <empty> opt_varying
 
 %type <*NumVal>  signed_iconst
+%type <int64> signed_iconst64
+%type <[]SequenceOption> sequence_option_list opt_sequence_option_list
+%type <SequenceOption> sequence_option_elem
 %type <Expr>  var_value
 %type <Exprs> var_list
 %type <UnresolvedName> var_name
@@ -764,13 +871,17 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 %type <[]NamedColumnQualification> col_qual_list
 %type <NamedColumnQualification> col_qualification
 %type <ColumnQualification> col_qualification_elem
-%type <empty> key_actions key_delete key_match key_update key_action
+%type <empty> key_match
+%type <ReferenceActions> key_actions
+%type <ReferenceAction> key_action key_update key_delete
 
 %type <Expr>  func_application func_expr_common_subexpr
 %type <Expr>  func_expr func_expr_windowless
-%type <empty> common_table_expr
-%type <empty> with_clause opt_with opt_with_clause
-%type <empty> cte_list
+%type <*CTE> common_table_expr
+%type <*With> with_clause opt_with_clause
+%type <[]*CTE> cte_list
+%type <empty> opt_with
+%type <empty> trigger_function_keyword
 
 %type <empty> within_group_clause
 %type <Expr> filter_clause
@@ -778,15 +889,18 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 %type <Window> window_clause window_definition_list
 %type <*WindowDef> window_definition over_clause window_specification
 %type <str> opt_existing_window_name
-%type <empty> opt_frame_clause frame_extent frame_bound
+%type <*WindowFrame> opt_frame_clause
+%type <WindowFrameBounds> frame_extent
+%type <*WindowFrameBound> frame_bound
 
 %type <[]ColumnID> opt_tableref_col_list tableref_col_list
 
 %type <TargetList>    targets
 %type <*TargetList> on_privilege_target_clause
 %type <NameList>       grantee_list for_grantee_clause
-%type <privilege.List> privileges privilege_list
-%type <privilege.Kind> privilege
+%type <privilege.List> privileges
+%type <NameList> privilege_list
+%type <str> privilege
 
 // Precedence: lowest to highest
 %nonassoc  VALUES              // see value_clause
@@ -827,11 +941,11 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 // funny behavior of UNBOUNDED on the SQL standard, though.
 %nonassoc  UNBOUNDED         // ideally should have same precedence as IDENT
 %nonassoc  IDENT NULL PARTITION RANGE ROWS PRECEDING FOLLOWING CUBE ROLLUP
-%left      CONCAT       // multi-character ops
+%left      CONCAT FETCHVAL FETCHTEXT CONTAINS CONTAINED_BY '?' // multi-character ops
 %left      '|'
 %left      '#'
 %left      '&'
-%left      LSHIFT RSHIFT
+%left      LSHIFT RSHIFT INET_CONTAINED_BY_OR_EQUALS INET_CONTAINS_OR_EQUALS
 %left      '+' '-'
 %left      '*' '/' FLOORDIV '%'
 %left      '^'
@@ -894,6 +1008,7 @@ stmt:
 | import_stmt     // EXTEND WITH HELP: IMPORT
 | pause_stmt      // EXTEND WITH HELP: PAUSE JOB
 | prepare_stmt    // EXTEND WITH HELP: PREPARE
+| refresh_stmt    // EXTEND WITH HELP: REFRESH MATERIALIZED VIEW
 | restore_stmt    // EXTEND WITH HELP: RESTORE
 | resume_stmt     // EXTEND WITH HELP: RESUME JOB
 | revoke_stmt     // EXTEND WITH HELP: REVOKE
@@ -917,11 +1032,12 @@ stmt:
 
 // %Help: ALTER
 // %Category: Group
-// %Text: ALTER TABLE, ALTER INDEX, ALTER VIEW, ALTER DATABASE
+// %Text: ALTER TABLE, ALTER INDEX, ALTER VIEW, ALTER SEQUENCE, ALTER DATABASE
 alter_stmt:
   alter_table_stmt    // EXTEND WITH HELP: ALTER TABLE
 | alter_index_stmt    // EXTEND WITH HELP: ALTER INDEX
 | alter_view_stmt     // EXTEND WITH HELP: ALTER VIEW
+| alter_sequence_stmt // EXTEND WITH HELP: ALTER SEQUENCE
 | alter_database_stmt // EXTEND WITH HELP: ALTER DATABASE
 | ALTER error         // SHOW HELP: ALTER
 
@@ -936,10 +1052,12 @@ alter_stmt:
 //   ALTER TABLE ... DROP [COLUMN] [IF EXISTS] <colname> [RESTRICT | CASCADE]
 //   ALTER TABLE ... DROP CONSTRAINT [IF EXISTS] <constraintname> [RESTRICT | CASCADE]
 //   ALTER TABLE ... ALTER [COLUMN] <colname> {SET DEFAULT <expr> | DROP DEFAULT}
-//   ALTER TABLE ... ALTER [COLUMN] <colname> DROP NOT NULL
+//   ALTER TABLE ... ALTER [COLUMN] <colname> {SET NOT NULL | DROP NOT NULL}
+//   ALTER TABLE ... ALTER [COLUMN] <colname> [SET DATA] TYPE <type> [USING <expr>]
 //   ALTER TABLE ... RENAME TO <newname>
 //   ALTER TABLE ... RENAME [COLUMN] <colname> TO <newname>
 //   ALTER TABLE ... VALIDATE CONSTRAINT <constraintname>
+//   ALTER TABLE ... ALTER CONSTRAINT <constraintname> [NOT] VALID
 //   ALTER TABLE ... SPLIT AT <selectclause>
 //   ALTER TABLE ... SCATTER [ FROM ( <exprs...> ) TO ( <exprs...> ) ]
 //
@@ -971,6 +1089,34 @@ alter_view_stmt:
 // prefix is spread over multiple non-terminals.
 | ALTER VIEW error // SHOW HELP: ALTER VIEW
 
+// %Help: ALTER SEQUENCE - change the definition of a sequence
+// %Category: DDL
+// %Text:
+// ALTER SEQUENCE [IF EXISTS] <name>
+//   [INCREMENT <increment>]
+//   [MINVALUE <minvalue> | NO MINVALUE]
+//   [MAXVALUE <maxvalue> | NO MAXVALUE]
+//   [START [WITH] <start>]
+//   [NO CYCLE]
+// ALTER SEQUENCE [IF EXISTS] <name> RENAME TO <newname>
+// %SeeAlso: WEBDOCS/alter-sequence.html
+alter_sequence_stmt:
+  alter_rename_sequence_stmt
+| alter_sequence_options_stmt
+// ALTER SEQUENCE has its error help token here because the ALTER SEQUENCE
+// prefix is spread over multiple non-terminals.
+| ALTER SEQUENCE error // SHOW HELP: ALTER SEQUENCE
+
+alter_sequence_options_stmt:
+  ALTER SEQUENCE relation_expr sequence_option_list
+  {
+    $$.val = &AlterSequence{Name: $3.normalizableTableName(), Options: $4.seqOpts(), IfExists: false}
+  }
+| ALTER SEQUENCE IF EXISTS relation_expr sequence_option_list
+  {
+    $$.val = &AlterSequence{Name: $5.normalizableTableName(), Options: $6.seqOpts(), IfExists: true}
+  }
+
 // %Help: ALTER DATABASE - change the definition of a database
 // %Category: DDL
 // %Text:
@@ -1100,7 +1246,10 @@ alter_table_cmd:
     $$.val = &AlterTableDropNotNull{columnKeyword: $2.bool(), Column: Name($3)}
   }
   // ALTER TABLE <name> ALTER [COLUMN] <colname> SET NOT NULL
-| ALTER opt_column name SET NOT NULL { return unimplemented(sqllex, "alter set non null") }
+| ALTER opt_column name SET NOT NULL
+  {
+    $$.val = &AlterTableSetNotNull{columnKeyword: $2.bool(), Column: Name($3)}
+  }
   // ALTER TABLE <name> DROP [COLUMN] IF EXISTS <colname> [RESTRICT|CASCADE]
 | DROP opt_column IF EXISTS name opt_drop_behavior
   {
@@ -1123,7 +1272,15 @@ alter_table_cmd:
   }
   // ALTER TABLE <name> ALTER [COLUMN] <colname> [SET DATA] TYPE <typename>
   //     [ USING <expression> ]
-| ALTER opt_column name opt_set_data TYPE typename opt_collate_clause alter_using { return unimplemented(sqllex, "alter set type") }
+| ALTER opt_column name opt_set_data TYPE typename opt_collate_clause alter_using
+  {
+    $$.val = &AlterTableAlterColumnType{
+      columnKeyword: $2.bool(),
+      Column: Name($3),
+      ToType: $6.colType(),
+      Using: $8.expr(),
+    }
+  }
   // ALTER TABLE <name> ADD CONSTRAINT ...
 | ADD table_constraint opt_validate_behavior
   {
@@ -1132,8 +1289,21 @@ alter_table_cmd:
       ValidationBehavior: $3.validationBehavior(),
     }
   }
-  // ALTER TABLE <name> ALTER CONSTRAINT ...
-| ALTER CONSTRAINT name { return unimplemented(sqllex, "alter constraint") }
+  // ALTER TABLE <name> ALTER CONSTRAINT <name> [NOT] VALID
+| ALTER CONSTRAINT name VALID
+  {
+    $$.val = &AlterTableAlterConstraint{
+      Constraint: Name($3),
+      ValidationBehavior: ValidationDefault,
+    }
+  }
+| ALTER CONSTRAINT name NOT VALID
+  {
+    $$.val = &AlterTableAlterConstraint{
+      Constraint: Name($3),
+      ValidationBehavior: ValidationSkip,
+    }
+  }
   // ALTER TABLE <name> VALIDATE CONSTRAINT ...
 | VALIDATE CONSTRAINT name
   {
@@ -1199,8 +1369,14 @@ opt_collate_clause:
 | /* EMPTY */ {}
 
 alter_using:
-  USING a_expr { return unimplemented(sqllex, "alter using") }
-| /* EMPTY */ {}
+  USING a_expr
+  {
+    $$.val = $2.expr()
+  }
+| /* EMPTY */
+  {
+    $$.val = nil
+  }
 
 // %Help: BACKUP - back up data to external storage
 // %Category: CCL
@@ -1410,14 +1586,20 @@ cancel_query_stmt:
 // %Category: Group
 // %Text:
 // CREATE DATABASE, CREATE TABLE, CREATE INDEX, CREATE TABLE AS,
-// CREATE USER, CREATE VIEW
+// CREATE USER, CREATE ROLE, CREATE VIEW, CREATE MATERIALIZED VIEW,
+// CREATE SEQUENCE, CREATE STATISTICS, CREATE FUNCTION, CREATE TRIGGER
 create_stmt:
   create_database_stmt // EXTEND WITH HELP: CREATE DATABASE
+| create_function_stmt // EXTEND WITH HELP: CREATE FUNCTION
 | create_index_stmt    // EXTEND WITH HELP: CREATE INDEX
+| create_role_stmt     // EXTEND WITH HELP: CREATE ROLE
+| create_sequence_stmt // EXTEND WITH HELP: CREATE SEQUENCE
+| create_stats_stmt    // EXTEND WITH HELP: CREATE STATISTICS
 | create_table_stmt    // EXTEND WITH HELP: CREATE TABLE
 | create_table_as_stmt // EXTEND WITH HELP: CREATE TABLE
 // Error case for both CREATE TABLE and CREATE TABLE ... AS in one
-| CREATE TABLE error   // SHOW HELP: CREATE TABLE
+| CREATE opt_temp TABLE error   // SHOW HELP: CREATE TABLE
+| create_trigger_stmt  // EXTEND WITH HELP: CREATE TRIGGER
 | create_user_stmt     // EXTEND WITH HELP: CREATE USER
 | create_view_stmt     // EXTEND WITH HELP: CREATE VIEW
 | CREATE error         // SHOW HELP: CREATE
@@ -1432,6 +1614,7 @@ delete_stmt:
   opt_with_clause DELETE FROM relation_expr_opt_alias where_clause opt_limit_clause returning_clause
   {
     $$.val = &Delete{
+      With: $1.with(),
       Table: $4.tblExpr(),
       Where: newWhere(astWhere, $5.expr()),
       Limit: $6.limit(),
@@ -1442,7 +1625,7 @@ delete_stmt:
 
 // %Help: DISCARD - reset the session to its initial state
 // %Category: Cfg
-// %Text: DISCARD ALL
+// %Text: DISCARD { ALL | TEMP }
 discard_stmt:
   DISCARD ALL
   {
@@ -1450,18 +1633,30 @@ discard_stmt:
   }
 | DISCARD PLANS { return unimplemented(sqllex, "discard plans") }
 | DISCARD SEQUENCES { return unimplemented(sqllex, "discard sequences") }
-| DISCARD TEMP { return unimplemented(sqllex, "discard temp") }
-| DISCARD TEMPORARY { return unimplemented(sqllex, "discard temporary") }
+| DISCARD TEMP
+  {
+    $$.val = &Discard{Mode: DiscardModeTemp}
+  }
+| DISCARD TEMPORARY
+  {
+    $$.val = &Discard{Mode: DiscardModeTemp}
+  }
 | DISCARD error // SHOW HELP: DISCARD
 
 // %Help: DROP
 // %Category: Group
-// %Text: DROP DATABASE, DROP INDEX, DROP TABLE, DROP VIEW, DROP USER
+// %Text:
+// DROP DATABASE, DROP INDEX, DROP TABLE, DROP VIEW, DROP SEQUENCE,
+// DROP USER, DROP ROLE, DROP FUNCTION, DROP TRIGGER
 drop_stmt:
   drop_database_stmt // EXTEND WITH HELP: DROP DATABASE
+| drop_function_stmt // EXTEND WITH HELP: DROP FUNCTION
 | drop_index_stmt    // EXTEND WITH HELP: DROP INDEX
+| drop_role_stmt     // EXTEND WITH HELP: DROP ROLE
 | drop_table_stmt    // EXTEND WITH HELP: DROP TABLE
+| drop_trigger_stmt  // EXTEND WITH HELP: DROP TRIGGER
 | drop_view_stmt     // EXTEND WITH HELP: DROP VIEW
+| drop_sequence_stmt // EXTEND WITH HELP: DROP SEQUENCE
 | drop_user_stmt     // EXTEND WITH HELP: DROP USER
 | DROP error         // SHOW HELP: DROP
 
@@ -1480,6 +1675,21 @@ drop_view_stmt:
   }
 | DROP VIEW error // SHOW HELP: DROP VIEW
 
+// %Help: DROP SEQUENCE - remove a sequence
+// %Category: DDL
+// %Text: DROP SEQUENCE [IF EXISTS] <sequenceName> [, ...] [CASCADE | RESTRICT]
+// %SeeAlso: WEBDOCS/drop-sequence.html
+drop_sequence_stmt:
+  DROP SEQUENCE table_name_list opt_drop_behavior
+  {
+    $$.val = &DropSequence{Names: $3.tableNameReferences(), IfExists: false, DropBehavior: $4.dropBehavior()}
+  }
+| DROP SEQUENCE IF EXISTS table_name_list opt_drop_behavior
+  {
+    $$.val = &DropSequence{Names: $5.tableNameReferences(), IfExists: true, DropBehavior: $6.dropBehavior()}
+  }
+| DROP SEQUENCE error // SHOW HELP: DROP SEQUENCE
+
 // %Help: DROP TABLE - remove a table
 // %Category: DDL
 // %Text: DROP TABLE [IF EXISTS] <tablename> [, ...] [CASCADE | RESTRICT]
@@ -1541,6 +1751,52 @@ drop_database_stmt:
   }
 | DROP DATABASE error // SHOW HELP: DROP DATABASE
 
+// %Help: DROP FUNCTION - remove a user-defined function
+// %Category: DDL
+// %Text: DROP FUNCTION [IF EXISTS] <name> [( <argtypes...> )]
+// %SeeAlso: CREATE FUNCTION
+drop_function_stmt:
+  DROP FUNCTION any_name
+  {
+    $$.val = &DropFunction{Name: $3.unresolvedName()}
+  }
+| DROP FUNCTION any_name '(' ')'
+  {
+    $$.val = &DropFunction{Name: $3.unresolvedName(), ArgTypes: []ColumnType{}}
+  }
+| DROP FUNCTION any_name '(' type_list ')'
+  {
+    $$.val = &DropFunction{Name: $3.unresolvedName(), ArgTypes: $5.colTypes()}
+  }
+| DROP FUNCTION IF EXISTS any_name
+  {
+    $$.val = &DropFunction{Name: $5.unresolvedName(), IfExists: true}
+  }
+| DROP FUNCTION IF EXISTS any_name '(' ')'
+  {
+    $$.val = &DropFunction{Name: $5.unresolvedName(), ArgTypes: []ColumnType{}, IfExists: true}
+  }
+| DROP FUNCTION IF EXISTS any_name '(' type_list ')'
+  {
+    $$.val = &DropFunction{Name: $5.unresolvedName(), ArgTypes: $7.colTypes(), IfExists: true}
+  }
+| DROP FUNCTION error // SHOW HELP: DROP FUNCTION
+
+// %Help: DROP TRIGGER - remove a trigger
+// %Category: DDL
+// %Text: DROP TRIGGER [IF EXISTS] <name> ON <tablename>
+// %SeeAlso: CREATE TRIGGER
+drop_trigger_stmt:
+  DROP TRIGGER name ON qualified_name
+  {
+    $$.val = &DropTrigger{Name: Name($3), Table: $5.normalizableTableName()}
+  }
+| DROP TRIGGER IF EXISTS name ON qualified_name
+  {
+    $$.val = &DropTrigger{Name: Name($5), Table: $7.normalizableTableName(), IfExists: true}
+  }
+| DROP TRIGGER error // SHOW HELP: DROP TRIGGER
+
 // %Help: DROP USER - remove a user
 // %Category: Priv
 // %Text: DROP USER [IF EXISTS] <user> [, ...]
@@ -1556,6 +1812,21 @@ drop_user_stmt:
   }
 | DROP USER error // SHOW HELP: DROP USER
 
+// %Help: DROP ROLE - remove a role
+// %Category: Priv
+// %Text: DROP ROLE [IF EXISTS] <role> [, ...]
+// %SeeAlso: CREATE ROLE, SHOW ROLES
+drop_role_stmt:
+  DROP ROLE name_list
+  {
+    $$.val = &DropRole{Names: $3.nameList(), IfExists: false}
+  }
+| DROP ROLE IF EXISTS name_list
+  {
+    $$.val = &DropRole{Names: $5.nameList(), IfExists: true}
+  }
+| DROP ROLE error // SHOW HELP: DROP ROLE
+
 table_name_list:
   any_name
   {
@@ -1590,6 +1861,7 @@ attrs:
 // %Category: Misc
 // %Text:
 // EXPLAIN <statement>
+// EXPLAIN ANALYZE <statement>
 // EXPLAIN [( [PLAN ,] <planoptions...> )] <statement>
 //
 // Explainable statements:
@@ -1597,7 +1869,8 @@ attrs:
 //     SHOW, EXPLAIN, EXECUTE
 //
 // Plan options:
-//     TYPES, EXPRS, METADATA, QUALIFY, INDENT, VERBOSE, DIST_SQL
+//     TYPES, EXPRS, METADATA, QUALIFY, INDENT, VERBOSE, DIST_SQL, ANALYZE,
+//     COSTS
 //
 // %SeeAlso: WEBDOCS/explain.html
 explain_stmt:
@@ -1605,6 +1878,10 @@ explain_stmt:
   {
     $$.val = &Explain{Statement: $2.stmt()}
   }
+| EXPLAIN ANALYZE explainable_stmt
+  {
+    $$.val = &Explain{Options: []string{"analyze"}, Statement: $3.stmt()}
+  }
 | EXPLAIN error // SHOW HELP: EXPLAIN
 | EXPLAIN '(' explain_option_list ')' explainable_stmt
   {
@@ -1656,6 +1933,10 @@ explain_option_list:
 
 explain_option_name:
   non_reserved_word
+| ANALYZE
+  {
+    $$ = "analyze"
+  }
 
 // %Help: PREPARE - prepare a statement for later execution
 // %Category: Misc
@@ -1734,7 +2015,10 @@ deallocate_stmt:
 // %Help: GRANT - define access privileges
 // %Category: Priv
 // %Text:
-// GRANT {ALL | <privileges...> } ON <targets...> TO <grantees...>
+// Grant privileges:
+//   GRANT {ALL | <privileges...> } ON <targets...> TO <grantees...>
+// Grant role membership:
+//   GRANT <roles...> TO <grantees...> [WITH ADMIN OPTION]
 //
 // Privileges:
 //   CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE
@@ -1749,12 +2033,23 @@ grant_stmt:
   {
     $$.val = &Grant{Privileges: $2.privilegeList(), Grantees: $6.nameList(), Targets: $4.targetList()}
   }
+| GRANT privilege_list TO grantee_list
+  {
+    $$.val = &GrantRole{Roles: $2.nameList(), Members: $4.nameList(), AdminOption: false}
+  }
+| GRANT privilege_list TO grantee_list WITH ADMIN OPTION
+  {
+    $$.val = &GrantRole{Roles: $2.nameList(), Members: $4.nameList(), AdminOption: true}
+  }
 | GRANT error // SHOW HELP: GRANT
 
 // %Help: REVOKE - remove access privileges
 // %Category: Priv
 // %Text:
-// REVOKE {ALL | <privileges...> } ON <targets...> FROM <grantees...>
+// Revoke privileges:
+//   REVOKE {ALL | <privileges...> } ON <targets...> FROM <grantees...>
+// Revoke role membership:
+//   REVOKE [ADMIN OPTION FOR] <roles...> FROM <grantees...>
 //
 // Privileges:
 //   CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE
@@ -1769,6 +2064,14 @@ revoke_stmt:
   {
     $$.val = &Revoke{Privileges: $2.privilegeList(), Grantees: $6.nameList(), Targets: $4.targetList()}
   }
+| REVOKE privilege_list FROM grantee_list
+  {
+    $$.val = &RevokeRole{Roles: $2.nameList(), Members: $4.nameList(), AdminOption: false}
+  }
+| REVOKE ADMIN OPTION FOR privilege_list FROM grantee_list
+  {
+    $$.val = &RevokeRole{Roles: $5.nameList(), Members: $7.nameList(), AdminOption: true}
+  }
 | REVOKE error // SHOW HELP: REVOKE
 
 targets:
@@ -1791,48 +2094,34 @@ privileges:
   {
     $$.val = privilege.List{privilege.ALL}
   }
-  | privilege_list { }
+| privilege_list
+  {
+    privList, err := privilege.ListFromStrings($1.nameList().ToStrings())
+    if err != nil {
+      sqllex.Error(err.Error())
+      return 1
+    }
+    $$.val = privList
+  }
 
+// Privileges and role names are both parsed as privilege_list; whether the
+// names refer to privileges or roles is decided by the enclosing statement.
 privilege_list:
   privilege
   {
-    $$.val = privilege.List{$1.privilegeType()}
+    $$.val = NameList{Name($1)}
   }
-  | privilege_list ',' privilege
+| privilege_list ',' privilege
   {
-    $$.val = append($1.privilegeList(), $3.privilegeType())
+    $$.val = append($1.nameList(), Name($3))
   }
 
-// This list must match the list of privileges in sql/privilege/privilege.go.
+// Some privileges are reserved keywords and cannot be parsed as names.
 privilege:
-  CREATE
-  {
-    $$.val = privilege.CREATE
-  }
-| DROP
-  {
-    $$.val = privilege.DROP
-  }
+  name
+| CREATE
 | GRANT
-  {
-    $$.val = privilege.GRANT
-  }
 | SELECT
-  {
-    $$.val = privilege.SELECT
-  }
-| INSERT
-  {
-    $$.val = privilege.INSERT
-  }
-| DELETE
-  {
-    $$.val = privilege.DELETE
-  }
-| UPDATE
-  {
-    $$.val = privilege.UPDATE
-  }
 
 // TODO(marc): this should not be 'name', but should instead be a
 // type just for usernames.
@@ -2094,22 +2383,26 @@ non_reserved_word_or_sconst:
 // %Category: Group
 // %Text:
 // SHOW SESSION, SHOW CLUSTER SETTING, SHOW DATABASES, SHOW TABLES, SHOW COLUMNS, SHOW INDEXES,
-// SHOW CONSTRAINTS, SHOW CREATE TABLE, SHOW CREATE VIEW, SHOW USERS, SHOW TRANSACTION, SHOW BACKUP,
-// SHOW JOBS, SHOW QUERIES, SHOW SESSIONS, SHOW TRACE
+// SHOW CONSTRAINTS, SHOW CREATE TABLE, SHOW CREATE VIEW, SHOW CREATE SEQUENCE, SHOW USERS,
+// SHOW TRANSACTION, SHOW BACKUP, SHOW JOBS, SHOW QUERIES, SHOW SESSIONS, SHOW TRACE,
+// SHOW STATISTICS
 show_stmt:
   show_backup_stmt       // EXTEND WITH HELP: SHOW BACKUP
 | show_columns_stmt      // EXTEND WITH HELP: SHOW COLUMNS
 | show_constraints_stmt  // EXTEND WITH HELP: SHOW CONSTRAINTS
 | show_create_table_stmt // EXTEND WITH HELP: SHOW CREATE TABLE
 | show_create_view_stmt  // EXTEND WITH HELP: SHOW CREATE VIEW
+| show_create_sequence_stmt // EXTEND WITH HELP: SHOW CREATE SEQUENCE
 | show_csettings_stmt    // EXTEND WITH HELP: SHOW CLUSTER SETTING
 | show_databases_stmt    // EXTEND WITH HELP: SHOW DATABASES
 | show_grants_stmt       // EXTEND WITH HELP: SHOW GRANTS
 | show_indexes_stmt      // EXTEND WITH HELP: SHOW INDEXES
 | show_jobs_stmt         // EXTEND WITH HELP: SHOW JOBS
 | show_queries_stmt      // EXTEND WITH HELP: SHOW QUERIES
+| show_roles_stmt        // EXTEND WITH HELP: SHOW ROLES
 | show_session_stmt      // EXTEND WITH HELP: SHOW SESSION
 | show_sessions_stmt     // EXTEND WITH HELP: SHOW SESSIONS
+| show_stats_stmt        // EXTEND WITH HELP: SHOW STATISTICS
 | show_tables_stmt       // EXTEND WITH HELP: SHOW TABLES
 | show_testing_stmt
 | show_trace_stmt        // EXTEND WITH HELP: SHOW TRACE
@@ -2274,6 +2567,17 @@ show_jobs_stmt:
   }
 | SHOW JOBS error // SHOW HELP: SHOW JOBS
 
+// %Help: SHOW STATISTICS - display table statistics
+// %Category: Misc
+// %Text: SHOW STATISTICS FOR TABLE <table_name>
+// %SeeAlso: CREATE STATISTICS
+show_stats_stmt:
+  SHOW STATISTICS FOR TABLE var_name
+  {
+    $$.val = &ShowTableStats{Table: $5.normalizableTableName()}
+  }
+| SHOW STATISTICS error // SHOW HELP: SHOW STATISTICS
+
 // %Help: SHOW TRACE - display an execution trace
 // %Category: Misc
 // %Text:
@@ -2377,6 +2681,17 @@ show_create_view_stmt:
   }
 | SHOW CREATE VIEW error // SHOW HELP: SHOW CREATE VIEW
 
+// %Help: SHOW CREATE SEQUENCE - display the CREATE SEQUENCE statement for a sequence
+// %Category: DDL
+// %Text: SHOW CREATE SEQUENCE <seqname>
+// %SeeAlso: WEBDOCS/show-create-sequence.html
+show_create_sequence_stmt:
+  SHOW CREATE SEQUENCE var_name
+  {
+    $$.val = &ShowCreateSequence{Sequence: $4.normalizableTableName()}
+  }
+| SHOW CREATE SEQUENCE error // SHOW HELP: SHOW CREATE SEQUENCE
+
 // %Help: SHOW USERS - list defined users
 // %Category: Priv
 // %Text: SHOW USERS
@@ -2388,6 +2703,17 @@ show_users_stmt:
   }
 | SHOW USERS error // SHOW HELP: SHOW USERS
 
+// %Help: SHOW ROLES - list defined roles
+// %Category: Priv
+// %Text: SHOW ROLES
+// %SeeAlso: CREATE ROLE, DROP ROLE, SHOW USERS
+show_roles_stmt:
+  SHOW ROLES
+  {
+    $$.val = &ShowRoles{}
+  }
+| SHOW ROLES error // SHOW HELP: SHOW ROLES
+
 show_testing_stmt:
   SHOW TESTING_RANGES FROM TABLE qualified_name
   {
@@ -2440,13 +2766,13 @@ pause_stmt:
 // %Help: CREATE TABLE - create a new table
 // %Category: DDL
 // %Text:
-// CREATE TABLE [IF NOT EXISTS] <tablename> ( <elements...> ) [<interleave>]
-// CREATE TABLE [IF NOT EXISTS] <tablename> [( <colnames...> )] AS <source>
+// CREATE [TEMP] TABLE [IF NOT EXISTS] <tablename> ( <elements...> ) [<interleave>] [<partitioning>]
+// CREATE [TEMP] TABLE [IF NOT EXISTS] <tablename> [( <colnames...> )] AS <source>
 //
 // Table elements:
 //    <name> <type> [<qualifiers...>]
 //    [UNIQUE] INDEX [<name>] ( <colname> [ASC | DESC] [, ...] )
-//                            [STORING ( <colnames...> )] [<interleave>]
+//                            [STORING ( <colnames...> )] [<interleave>] [<partitioning>]
 //    FAMILY [<name>] ( <colnames...> )
 //    [CONSTRAINT <name>] <constraint>
 //
@@ -2465,27 +2791,50 @@ pause_stmt:
 // Interleave clause:
 //    INTERLEAVE IN PARENT <tablename> ( <colnames...> ) [CASCADE | RESTRICT]
 //
+// Partitioning clause:
+//    PARTITION BY LIST ( <colnames...> ) (
+//      PARTITION <name> VALUES IN ( <values...> | DEFAULT ) [, ...]
+//    )
+//    PARTITION BY RANGE ( <colnames...> ) (
+//      PARTITION <name> VALUES FROM ( <values...> ) TO ( <values...> ) [, ...]
+//    )
+//    Range bounds may use MINVALUE and MAXVALUE.
+//
 // %SeeAlso: SHOW TABLES, CREATE VIEW, SHOW CREATE TABLE,
 // WEBDOCS/create-table.html
 // WEBDOCS/create-table-as.html
 create_table_stmt:
-  CREATE TABLE any_name '(' opt_table_elem_list ')' opt_interleave
+  CREATE opt_temp TABLE any_name '(' opt_table_elem_list ')' opt_interleave opt_partition_by
   {
-    $$.val = &CreateTable{Table: $3.normalizableTableName(), IfNotExists: false, Interleave: $7.interleave(), Defs: $5.tblDefs(), AsSource: nil, AsColumnNames: nil}
+    $$.val = &CreateTable{Table: $4.normalizableTableName(), IfNotExists: false, Temporary: $2.bool(), Interleave: $8.interleave(), PartitionBy: $9.partitionBy(), Defs: $6.tblDefs(), AsSource: nil, AsColumnNames: nil}
   }
-| CREATE TABLE IF NOT EXISTS any_name '(' opt_table_elem_list ')' opt_interleave
+| CREATE opt_temp TABLE IF NOT EXISTS any_name '(' opt_table_elem_list ')' opt_interleave opt_partition_by
   {
-    $$.val = &CreateTable{Table: $6.normalizableTableName(), IfNotExists: true, Interleave: $10.interleave(), Defs: $8.tblDefs(), AsSource: nil, AsColumnNames: nil}
+    $$.val = &CreateTable{Table: $7.normalizableTableName(), IfNotExists: true, Temporary: $2.bool(), Interleave: $11.interleave(), PartitionBy: $12.partitionBy(), Defs: $9.tblDefs(), AsSource: nil, AsColumnNames: nil}
   }
 
 create_table_as_stmt:
-  CREATE TABLE any_name opt_column_list AS select_stmt
+  CREATE opt_temp TABLE any_name opt_column_list AS select_stmt
   {
-    $$.val = &CreateTable{Table: $3.normalizableTableName(), IfNotExists: false, Interleave: nil, Defs: nil, AsSource: $6.slct(), AsColumnNames: $4.nameList()}
+    $$.val = &CreateTable{Table: $4.normalizableTableName(), IfNotExists: false, Temporary: $2.bool(), Interleave: nil, Defs: nil, AsSource: $7.slct(), AsColumnNames: $5.nameList()}
   }
-| CREATE TABLE IF NOT EXISTS any_name opt_column_list AS select_stmt
+| CREATE opt_temp TABLE IF NOT EXISTS any_name opt_column_list AS select_stmt
   {
-    $$.val = &CreateTable{Table: $6.normalizableTableName(), IfNotExists: true, Interleave: nil, Defs: nil, AsSource: $9.slct(), AsColumnNames: $7.nameList()}
+    $$.val = &CreateTable{Table: $7.normalizableTableName(), IfNotExists: true, Temporary: $2.bool(), Interleave: nil, Defs: nil, AsSource: $10.slct(), AsColumnNames: $8.nameList()}
+  }
+
+opt_temp:
+  TEMPORARY
+  {
+    $$.val = true
+  }
+| TEMP
+  {
+    $$.val = true
+  }
+| /* EMPTY */
+  {
+    $$.val = false
   }
 
 opt_table_elem_list:
@@ -2531,6 +2880,98 @@ opt_interleave:
     $$.val = (*InterleaveDef)(nil)
   }
 
+opt_partition_by:
+  partition_by
+| /* EMPTY */
+  {
+    $$.val = (*PartitionBy)(nil)
+  }
+
+partition_by:
+  PARTITION BY LIST '(' name_list ')' '(' list_partitions ')'
+  {
+    $$.val = &PartitionBy{
+      Fields: $5.nameList(),
+      List: $8.listPartitions(),
+    }
+  }
+| PARTITION BY RANGE '(' name_list ')' '(' range_partitions ')'
+  {
+    $$.val = &PartitionBy{
+      Fields: $5.nameList(),
+      Range: $8.rangePartitions(),
+    }
+  }
+
+list_partitions:
+  list_partition
+  {
+    $$.val = []ListPartition{$1.listPartition()}
+  }
+| list_partitions ',' list_partition
+  {
+    $$.val = append($1.listPartitions(), $3.listPartition())
+  }
+
+list_partition:
+  PARTITION name VALUES IN '(' partition_value_list ')'
+  {
+    $$.val = ListPartition{
+      Name: Name($2),
+      Exprs: $6.exprs(),
+    }
+  }
+
+range_partitions:
+  range_partition
+  {
+    $$.val = []RangePartition{$1.rangePartition()}
+  }
+| range_partitions ',' range_partition
+  {
+    $$.val = append($1.rangePartitions(), $3.rangePartition())
+  }
+
+range_partition:
+  PARTITION name VALUES FROM partition_values TO partition_values
+  {
+    $$.val = RangePartition{
+      Name: Name($2),
+      From: $5.exprs(),
+      To: $7.exprs(),
+    }
+  }
+
+// partition_values is a parenthesized list of the values of the partitioning
+// columns making up a range partition bound.
+partition_values:
+  '(' partition_value_list ')'
+  {
+    $$.val = $2.exprs()
+  }
+
+partition_value_list:
+  partition_value
+  {
+    $$.val = Exprs{$1.expr()}
+  }
+| partition_value_list ',' partition_value
+  {
+    $$.val = append($1.exprs(), $3.expr())
+  }
+
+// MINVALUE and MAXVALUE are unreserved keywords, so a_expr parses them as
+// column names; partitionValue turns them into the special bounds.
+partition_value:
+  a_expr
+  {
+    $$.val = partitionValue($1.expr())
+  }
+| DEFAULT
+  {
+    $$.val = DefaultVal{}
+  }
+
 // TODO(dan): This can be removed in favor of opt_drop_behavior when #7854 is fixed.
 opt_interleave_drop_behavior:
   CASCADE
@@ -2637,20 +3078,30 @@ col_qualification_elem:
     $$.val = &ColumnFKConstraint{
       Table: $2.normalizableTableName(),
       Col: Name($3),
+      Actions: $5.referenceActions(),
     }
  }
 
 index_def:
-  INDEX opt_name '(' index_params ')' opt_storing opt_interleave
+  INDEX opt_name '(' index_params ')' opt_storing opt_interleave opt_partition_by
   {
     $$.val = &IndexTableDef{
       Name:    Name($2),
       Columns: $4.idxElems(),
       Storing: $6.nameList(),
       Interleave: $7.interleave(),
+      PartitionBy: $8.partitionBy(),
     }
   }
-| UNIQUE INDEX opt_name '(' index_params ')' opt_storing opt_interleave
+| INVERTED INDEX opt_name '(' index_params ')'
+  {
+    $$.val = &IndexTableDef{
+      Name:     Name($3),
+      Columns:  $5.idxElems(),
+      Inverted: true,
+    }
+  }
+| UNIQUE INDEX opt_name '(' index_params ')' opt_storing opt_interleave opt_partition_by
   {
     $$.val = &UniqueConstraintTableDef{
       IndexTableDef: IndexTableDef {
@@ -2658,6 +3109,7 @@ index_def:
         Columns: $5.idxElems(),
         Storing: $7.nameList(),
         Interleave: $8.interleave(),
+        PartitionBy: $9.partitionBy(),
       },
     }
   }
@@ -2718,6 +3170,7 @@ constraint_elem:
       Table: $7.normalizableTableName(),
       FromCols: $4.nameList(),
       ToCols: $8.nameList(),
+      Actions: $10.referenceActions(),
     }
   }
 
@@ -2764,77 +3217,274 @@ key_match:
 // simplicity of parsing, and then break them down again in the calling
 // production.
 key_actions:
-  key_update {}
-| key_delete {}
-| key_update key_delete {}
-| key_delete key_update {}
-| /* EMPTY */ {}
+  key_update
+  {
+    $$.val = ReferenceActions{Update: $1.referenceAction()}
+  }
+| key_delete
+  {
+    $$.val = ReferenceActions{Delete: $1.referenceAction()}
+  }
+| key_update key_delete
+  {
+    $$.val = ReferenceActions{Update: $1.referenceAction(), Delete: $2.referenceAction()}
+  }
+| key_delete key_update
+  {
+    $$.val = ReferenceActions{Delete: $1.referenceAction(), Update: $2.referenceAction()}
+  }
+| /* EMPTY */
+  {
+    $$.val = ReferenceActions{}
+  }
 
 key_update:
-  ON UPDATE key_action {}
+  ON UPDATE key_action
+  {
+    $$.val = $3.referenceAction()
+  }
 
 key_delete:
-  ON DELETE key_action {}
+  ON DELETE key_action
+  {
+    $$.val = $3.referenceAction()
+  }
 
 key_action:
-  NO ACTION { return unimplemented(sqllex, "no action") }
-// RESTRICT is currently the default and only supported ON DELETE/UPDATE
-// behavior and thus needs no special handling.
-| RESTRICT {}
-| CASCADE { return unimplemented(sqllex, "action cascade") }
-| SET NULL { return unimplemented(sqllex, "action set null") }
-| SET DEFAULT { return unimplemented(sqllex, "action set default") }
+  NO ACTION
+  {
+    $$.val = NoAction
+  }
+| RESTRICT
+  {
+    $$.val = Restrict
+  }
+| CASCADE
+  {
+    $$.val = Cascade
+  }
+| SET NULL
+  {
+    $$.val = SetNull
+  }
+| SET DEFAULT
+  {
+    $$.val = SetDefault
+  }
+
+numeric_only:
+  FCONST
+  {
+    $$.val = $1.numVal()
+  }
+| '-' FCONST
+  {
+    $$.val = &NumVal{Value: constant.UnaryOp(token.SUB, $2.numVal().Value, 0)}
+  }
+| signed_iconst
+  {
+    $$.val = $1.numVal()
+  }
+
+// %Help: TRUNCATE - empty one or more tables
+// %Category: DML
+// %Text: TRUNCATE [TABLE] <tablename> [, ...] [CASCADE | RESTRICT]
+// %SeeAlso: WEBDOCS/truncate.html
+truncate_stmt:
+  TRUNCATE opt_table relation_expr_list opt_drop_behavior
+  {
+    $$.val = &Truncate{Tables: $3.tableNameReferences(), DropBehavior: $4.dropBehavior()}
+  }
+| TRUNCATE error // SHOW HELP: TRUNCATE
+
+// %Help: CREATE USER - define a new user
+// %Category: Priv
+// %Text: CREATE USER <name> [ [WITH] PASSWORD <passwd> ]
+// %SeeAlso: DROP USER, SHOW USERS, CREATE ROLE, WEBDOCS/create-user.html
+create_user_stmt:
+  CREATE USER name opt_password
+  {
+    $$.val = &CreateUser{Name: Name($3), Password: $4.strPtr()}
+  }
+| CREATE USER error // SHOW HELP: CREATE USER
+
+// %Help: CREATE ROLE - define a new role
+// %Category: Priv
+// %Text: CREATE ROLE <name>
+// %SeeAlso: DROP ROLE, SHOW ROLES, GRANT
+create_role_stmt:
+  CREATE ROLE name
+  {
+    $$.val = &CreateRole{Name: Name($3)}
+  }
+| CREATE ROLE error // SHOW HELP: CREATE ROLE
+
+opt_password:
+  opt_with PASSWORD SCONST
+  {
+    pwd := $3
+    $$.val = &pwd
+  }
+| /* EMPTY */ {
+    $$.val = (*string)(nil)
+  }
+
+// %Help: CREATE FUNCTION - define a new function
+// %Category: DDL
+// %Text:
+// CREATE [OR REPLACE] FUNCTION <name> ( [ [<argname>] <argtype> [, ...] ] )
+//   RETURNS { <type> | TRIGGER | VOID }
+//   LANGUAGE SQL AS '<statements>'
+//
+// The body is a list of SQL statements separated by semicolons. Arguments
+// are referred to by name or as $1, $2, etc. The result of the function is
+// the first column of the first row returned by the last statement.
+// %SeeAlso: DROP FUNCTION, CREATE TRIGGER
+create_function_stmt:
+  CREATE opt_or_replace FUNCTION any_name '(' opt_func_arg_list ')' func_return func_language_and_body
+  {
+    n := $9.createFunction()
+    ret := $8.createFunction()
+    n.Replace = $2.bool()
+    n.Name = $4.unresolvedName()
+    n.Args = $6.funcArgs()
+    n.ReturnKind = ret.ReturnKind
+    n.ReturnType = ret.ReturnType
+    $$.val = n
+  }
+| CREATE opt_or_replace FUNCTION error // SHOW HELP: CREATE FUNCTION
+
+opt_or_replace:
+  OR REPLACE
+  {
+    $$.val = true
+  }
+| /* EMPTY */
+  {
+    $$.val = false
+  }
+
+opt_func_arg_list:
+  func_arg_list
+| /* EMPTY */
+  {
+    $$.val = FunctionArgs(nil)
+  }
+
+func_arg_list:
+  func_arg
+  {
+    $$.val = FunctionArgs{$1.funcArg()}
+  }
+| func_arg_list ',' func_arg
+  {
+    $$.val = append($1.funcArgs(), $3.funcArg())
+  }
+
+func_arg:
+  typename
+  {
+    $$.val = FunctionArg{Type: $1.colType()}
+  }
+| IDENT typename
+  {
+    $$.val = FunctionArg{Name: Name($1), Type: $2.colType()}
+  }
+
+func_return:
+  RETURNS typename
+  {
+    $$.val = &CreateFunction{ReturnKind: FunctionReturnsType, ReturnType: $2.colType()}
+  }
+| RETURNS TRIGGER
+  {
+    $$.val = &CreateFunction{ReturnKind: FunctionReturnsTrigger}
+  }
+| RETURNS VOID
+  {
+    $$.val = &CreateFunction{ReturnKind: FunctionReturnsVoid}
+  }
+
+func_language_and_body:
+  LANGUAGE name AS SCONST
+  {
+    $$.val = &CreateFunction{Language: Name($2), Body: $4}
+  }
+| AS SCONST LANGUAGE name
+  {
+    $$.val = &CreateFunction{Language: Name($4), Body: $2}
+  }
 
-numeric_only:
-  FCONST
+// %Help: CREATE TRIGGER - define a new trigger
+// %Category: DDL
+// %Text:
+// CREATE TRIGGER <name> { BEFORE | AFTER } <event> [OR ...]
+//   ON <tablename> FOR EACH ROW EXECUTE FUNCTION <funcname> ()
+//
+// Events:
+//   INSERT
+//   UPDATE
+//   DELETE
+//
+// The function must return TRIGGER. Its body can refer to the columns of
+// the row being modified as NEW.<column> and OLD.<column>.
+// %SeeAlso: DROP TRIGGER, CREATE FUNCTION
+create_trigger_stmt:
+  CREATE TRIGGER name trigger_action_time trigger_event_list ON qualified_name FOR EACH ROW EXECUTE trigger_function_keyword any_name '(' ')'
   {
-    $$.val = $1.numVal()
+    $$.val = &CreateTrigger{
+      Name: Name($3),
+      Timing: $4.triggerTiming(),
+      Events: $5.triggerEvents(),
+      Table: $7.normalizableTableName(),
+      Func: $13.unresolvedName(),
+    }
   }
-| '-' FCONST
+| CREATE TRIGGER error // SHOW HELP: CREATE TRIGGER
+
+trigger_action_time:
+  BEFORE
   {
-    $$.val = &NumVal{Value: constant.UnaryOp(token.SUB, $2.numVal().Value, 0)}
+    $$.val = TriggerBefore
   }
-| signed_iconst
+| AFTER
   {
-    $$.val = $1.numVal()
+    $$.val = TriggerAfter
   }
 
-// %Help: TRUNCATE - empty one or more tables
-// %Category: DML
-// %Text: TRUNCATE [TABLE] <tablename> [, ...] [CASCADE | RESTRICT]
-// %SeeAlso: WEBDOCS/truncate.html
-truncate_stmt:
-  TRUNCATE opt_table relation_expr_list opt_drop_behavior
+trigger_event_list:
+  trigger_event
   {
-    $$.val = &Truncate{Tables: $3.tableNameReferences(), DropBehavior: $4.dropBehavior()}
+    $$.val = []TriggerEvent{$1.triggerEvent()}
   }
-| TRUNCATE error // SHOW HELP: TRUNCATE
-
-// %Help: CREATE USER - define a new user
-// %Category: Priv
-// %Text: CREATE USER <name> [ [WITH] PASSWORD <passwd> ]
-// %SeeAlso: DROP USER, SHOW USERS, WEBDOCS/create-user.html
-create_user_stmt:
-  CREATE USER name opt_password
+| trigger_event_list OR trigger_event
   {
-    $$.val = &CreateUser{Name: Name($3), Password: $4.strPtr()}
+    $$.val = append($1.triggerEvents(), $3.triggerEvent())
   }
-| CREATE USER error // SHOW HELP: CREATE USER
 
-opt_password:
-  opt_with PASSWORD SCONST
+trigger_event:
+  INSERT
   {
-    pwd := $3
-    $$.val = &pwd
+    $$.val = TriggerInsert
   }
-| /* EMPTY */ {
-    $$.val = (*string)(nil)
+| UPDATE
+  {
+    $$.val = TriggerUpdate
+  }
+| DELETE
+  {
+    $$.val = TriggerDelete
   }
 
+trigger_function_keyword:
+  FUNCTION {}
+| PROCEDURE {}
+
 // %Help: CREATE VIEW - create a new view
 // %Category: DDL
-// %Text: CREATE VIEW <viewname> [( <colnames...> )] AS <source>
-// %SeeAlso: CREATE TABLE, SHOW CREATE VIEW, WEBDOCS/create-view.html
+// %Text: CREATE [MATERIALIZED] VIEW <viewname> [( <colnames...> )] AS <source>
+// %SeeAlso: CREATE TABLE, SHOW CREATE VIEW, REFRESH MATERIALIZED VIEW,
+// WEBDOCS/create-view.html
 create_view_stmt:
   CREATE VIEW any_name opt_column_list AS select_stmt
   {
@@ -2844,24 +3494,155 @@ create_view_stmt:
       AsSource: $6.slct(),
     }
   }
+| CREATE MATERIALIZED VIEW any_name opt_column_list AS select_stmt
+  {
+    $$.val = &CreateView{
+      Name: $4.normalizableTableName(),
+      ColumnNames: $5.nameList(),
+      AsSource: $7.slct(),
+      Materialized: true,
+    }
+  }
 | CREATE VIEW error // SHOW HELP: CREATE VIEW
+| CREATE MATERIALIZED VIEW error // SHOW HELP: CREATE VIEW
 
 // TODO(a-robinson): CREATE OR REPLACE VIEW support (#2971).
 
+// %Help: CREATE SEQUENCE - create a new sequence
+// %Category: DDL
+// %Text:
+// CREATE SEQUENCE [IF NOT EXISTS] <seqname>
+//   [INCREMENT <increment>]
+//   [MINVALUE <minvalue> | NO MINVALUE]
+//   [MAXVALUE <maxvalue> | NO MAXVALUE]
+//   [START [WITH] <start>]
+//   [CACHE <cache>]
+//   [NO CYCLE]
+// %SeeAlso: CREATE TABLE, SHOW CREATE SEQUENCE, WEBDOCS/create-sequence.html
+create_sequence_stmt:
+  CREATE SEQUENCE any_name opt_sequence_option_list
+  {
+    $$.val = &CreateSequence{Name: $3.normalizableTableName(), Options: $4.seqOpts()}
+  }
+| CREATE SEQUENCE IF NOT EXISTS any_name opt_sequence_option_list
+  {
+    $$.val = &CreateSequence{Name: $6.normalizableTableName(), Options: $7.seqOpts(), IfNotExists: true}
+  }
+| CREATE SEQUENCE error // SHOW HELP: CREATE SEQUENCE
+
+// %Help: CREATE STATISTICS - create a new table statistic
+// %Category: Misc
+// %Text:
+// CREATE STATISTICS <statisticname>
+//   ON <colname> [, ...]
+//   FROM <tablename>
+// %SeeAlso: SHOW STATISTICS
+create_stats_stmt:
+  CREATE STATISTICS name ON name_list FROM qualified_name
+  {
+    $$.val = &CreateStats{
+      Name: Name($3),
+      ColumnNames: $5.nameList(),
+      Table: $7.normalizableTableName(),
+    }
+  }
+| CREATE STATISTICS error // SHOW HELP: CREATE STATISTICS
+
+opt_sequence_option_list:
+  sequence_option_list
+| /* EMPTY */
+  {
+    $$.val = []SequenceOption(nil)
+  }
+
+sequence_option_list:
+  sequence_option_elem
+  {
+    $$.val = []SequenceOption{$1.seqOpt()}
+  }
+| sequence_option_list sequence_option_elem
+  {
+    $$.val = append($1.seqOpts(), $2.seqOpt())
+  }
+
+sequence_option_elem:
+  NO CYCLE
+  {
+    $$.val = SequenceOption{Name: SeqOptNoCycle}
+  }
+| CYCLE
+  {
+    /* SKIP DOC */
+    $$.val = SequenceOption{Name: SeqOptCycle}
+  }
+| CACHE signed_iconst64
+  {
+    x := $2.int64()
+    $$.val = SequenceOption{Name: SeqOptCache, IntVal: &x}
+  }
+| INCREMENT signed_iconst64
+  {
+    x := $2.int64()
+    $$.val = SequenceOption{Name: SeqOptIncrement, IntVal: &x}
+  }
+| INCREMENT BY signed_iconst64
+  {
+    x := $3.int64()
+    $$.val = SequenceOption{Name: SeqOptIncrement, IntVal: &x, OptionalWord: true}
+  }
+| MINVALUE signed_iconst64
+  {
+    x := $2.int64()
+    $$.val = SequenceOption{Name: SeqOptMinValue, IntVal: &x}
+  }
+| NO MINVALUE
+  {
+    $$.val = SequenceOption{Name: SeqOptMinValue}
+  }
+| MAXVALUE signed_iconst64
+  {
+    x := $2.int64()
+    $$.val = SequenceOption{Name: SeqOptMaxValue, IntVal: &x}
+  }
+| NO MAXVALUE
+  {
+    $$.val = SequenceOption{Name: SeqOptMaxValue}
+  }
+| START signed_iconst64
+  {
+    x := $2.int64()
+    $$.val = SequenceOption{Name: SeqOptStart, IntVal: &x}
+  }
+| START WITH signed_iconst64
+  {
+    x := $3.int64()
+    $$.val = SequenceOption{Name: SeqOptStart, IntVal: &x, OptionalWord: true}
+  }
+
 // %Help: CREATE INDEX - create a new index
 // %Category: DDL
 // %Text:
 // CREATE [UNIQUE] INDEX [IF NOT EXISTS] [<idxname>]
 //        ON <tablename> ( <colname> [ASC | DESC] [, ...] )
-//        [STORING ( <colnames...> )] [<interleave>]
+//        [STORING ( <colnames...> )] [<interleave>] [<partitioning>]
+//
+// Each <colname> can also be a function call or a parenthesized
+// expression over the columns of the table, whose values are indexed.
+//
+// CREATE INVERTED INDEX [IF NOT EXISTS] [<idxname>]
+//        ON <tablename> ( <colname> )
 //
 // Interleave clause:
 //    INTERLEAVE IN PARENT <tablename> ( <colnames...> ) [CASCADE | RESTRICT]
 //
+// Partitioning clause:
+//    PARTITION BY { LIST | RANGE } ( <colnames...> ) ( <partitions...> )
+//    (see CREATE TABLE)
+//
 // %SeeAlso: CREATE TABLE, SHOW INDEXES, SHOW CREATE INDEX,
 // WEBDOCS/create-index.html
 create_index_stmt:
-  CREATE opt_unique INDEX opt_name ON qualified_name '(' index_params ')' opt_storing opt_interleave
+  CREATE opt_unique INDEX opt_name ON qualified_name '(' index_params ')' opt_storing opt_interleave opt_partition_by
   {
     $$.val = &CreateIndex{
       Name:    Name($4),
@@ -2870,9 +3651,10 @@ create_index_stmt:
       Columns: $8.idxElems(),
       Storing: $10.nameList(),
       Interleave: $11.interleave(),
+      PartitionBy: $12.partitionBy(),
     }
   }
-| CREATE opt_unique INDEX IF NOT EXISTS name ON qualified_name '(' index_params ')' opt_storing opt_interleave
+| CREATE opt_unique INDEX IF NOT EXISTS name ON qualified_name '(' index_params ')' opt_storing opt_interleave opt_partition_by
   {
     $$.val = &CreateIndex{
       Name:        Name($7),
@@ -2882,6 +3664,26 @@ create_index_stmt:
       Columns:     $11.idxElems(),
       Storing:     $13.nameList(),
       Interleave: $14.interleave(),
+      PartitionBy: $15.partitionBy(),
+    }
+  }
+| CREATE INVERTED INDEX opt_name ON qualified_name '(' index_params ')'
+  {
+    $$.val = &CreateIndex{
+      Name:     Name($4),
+      Table:    $6.normalizableTableName(),
+      Inverted: true,
+      Columns:  $8.idxElems(),
+    }
+  }
+| CREATE INVERTED INDEX IF NOT EXISTS name ON qualified_name '(' index_params ')'
+  {
+    $$.val = &CreateIndex{
+      Name:        Name($7),
+      Table:       $9.normalizableTableName(),
+      Inverted:    true,
+      IfNotExists: true,
+      Columns:     $11.idxElems(),
     }
   }
 | CREATE opt_unique INDEX error // SHOW HELP: CREATE INDEX
@@ -2914,8 +3716,14 @@ index_elem:
   {
     $$.val = IndexElem{Column: Name($1), Direction: $3.dir()}
   }
-| func_expr_windowless opt_collate opt_asc_desc { return unimplemented(sqllex, "index_elem func expr") }
-| '(' a_expr ')' opt_collate opt_asc_desc { return unimplemented(sqllex, "index_elem a_expr") }
+| func_expr_windowless opt_collate opt_asc_desc
+  {
+    $$.val = IndexElem{Expr: $1.expr(), Direction: $3.dir()}
+  }
+| '(' a_expr ')' opt_collate opt_asc_desc
+  {
+    $$.val = makeIndexElemFromExpr($2.expr(), $5.dir())
+  }
 
 opt_collate:
   COLLATE unrestricted_name { return unimplementedWithIssue(sqllex, 16619) }
@@ -2973,6 +3781,16 @@ alter_rename_view_stmt:
     $$.val = &RenameTable{Name: $5.normalizableTableName(), NewName: $8.normalizableTableName(), IfExists: true, IsView: true}
   }
 
+alter_rename_sequence_stmt:
+  ALTER SEQUENCE relation_expr RENAME TO qualified_name
+  {
+    $$.val = &RenameTable{Name: $3.normalizableTableName(), NewName: $6.normalizableTableName(), IfExists: false, IsSequence: true}
+  }
+| ALTER SEQUENCE IF EXISTS relation_expr RENAME TO qualified_name
+  {
+    $$.val = &RenameTable{Name: $5.normalizableTableName(), NewName: $8.normalizableTableName(), IfExists: true, IsSequence: true}
+  }
+
 alter_rename_index_stmt:
   ALTER INDEX table_name_with_index RENAME TO name
   {
@@ -2997,6 +3815,17 @@ opt_set_data:
   SET DATA {}
 | /* EMPTY */ {}
 
+// %Help: REFRESH MATERIALIZED VIEW - recompute the rows of a materialized view
+// %Category: DDL
+// %Text: REFRESH MATERIALIZED VIEW <viewname>
+// %SeeAlso: CREATE VIEW, SHOW JOBS
+refresh_stmt:
+  REFRESH MATERIALIZED VIEW qualified_name
+  {
+    $$.val = &RefreshMaterializedView{Name: $4.normalizableTableName()}
+  }
+| REFRESH error // SHOW HELP: REFRESH MATERIALIZED VIEW
+
 // %Help: RELEASE - complete a retryable block
 // %Category: Txn
 // %Text: RELEASE [SAVEPOINT] cockroach_restart
@@ -3277,12 +4106,14 @@ insert_stmt:
   opt_with_clause INSERT INTO insert_target insert_rest returning_clause
   {
     $$.val = $5.stmt()
+    $$.val.(*Insert).With = $1.with()
     $$.val.(*Insert).Table = $4.tblExpr()
     $$.val.(*Insert).Returning = $6.retClause()
   }
 | opt_with_clause INSERT INTO insert_target insert_rest on_conflict returning_clause
   {
     $$.val = $5.stmt()
+    $$.val.(*Insert).With = $1.with()
     $$.val.(*Insert).Table = $4.tblExpr()
     $$.val.(*Insert).OnConflict = $6.onConflict()
     $$.val.(*Insert).Returning = $7.retClause()
@@ -3300,6 +4131,7 @@ upsert_stmt:
   opt_with_clause UPSERT INTO insert_target insert_rest returning_clause
   {
     $$.val = $5.stmt()
+    $$.val.(*Insert).With = $1.with()
     $$.val.(*Insert).Table = $4.tblExpr()
     $$.val.(*Insert).OnConflict = &OnConflict{}
     $$.val.(*Insert).Returning = $6.retClause()
@@ -3373,20 +4205,25 @@ returning_clause:
 
 // %Help: UPDATE - update rows of a table
 // %Category: DML
-// %Text: UPDATE <tablename> [[AS] <name>] SET ... [WHERE <expr>] [RETURNING <exprs...>]
+// %Text: UPDATE <tablename> [[AS] <name>] SET ... [FROM <source> [, ...]] [WHERE <expr>] [RETURNING <exprs...>]
 // %SeeAlso: INSERT, UPSERT, DELETE, WEBDOCS/update.html
 update_stmt:
   opt_with_clause UPDATE relation_expr_opt_alias
     SET set_clause_list update_from_clause where_clause returning_clause
   {
-    $$.val = &Update{Table: $3.tblExpr(), Exprs: $5.updateExprs(), Where: newWhere(astWhere, $7.expr()), Returning: $8.retClause()}
+    $$.val = &Update{With: $1.with(), Table: $3.tblExpr(), Exprs: $5.updateExprs(), From: $6.tblExprs(), Where: newWhere(astWhere, $7.expr()), Returning: $8.retClause()}
   }
 | opt_with_clause UPDATE error // SHOW HELP: UPDATE
 
-// Mark this as unimplemented until the normal from_clause is supported here.
 update_from_clause:
-  FROM from_list { return unimplementedWithIssue(sqllex, 7841) }
-| /* EMPTY */ {}
+  FROM from_list
+  {
+    $$.val = $2.tblExprs()
+  }
+| /* EMPTY */
+  {
+    $$.val = TableExprs(nil)
+  }
 
 set_clause_list:
   set_clause
@@ -3501,15 +4338,15 @@ select_no_parens:
   }
 | with_clause select_clause
   {
-    $$.val = &Select{Select: $2.selectStmt()}
+    $$.val = &Select{With: $1.with(), Select: $2.selectStmt()}
   }
 | with_clause select_clause sort_clause
   {
-    $$.val = &Select{Select: $2.selectStmt(), OrderBy: $3.orderBy()}
+    $$.val = &Select{With: $1.with(), Select: $2.selectStmt(), OrderBy: $3.orderBy()}
   }
 | with_clause select_clause opt_sort_clause select_limit
   {
-    $$.val = &Select{Select: $2.selectStmt(), OrderBy: $3.orderBy(), Limit: $4.limit()}
+    $$.val = &Select{With: $1.with(), Select: $2.selectStmt(), OrderBy: $3.orderBy(), Limit: $4.limit()}
   }
 
 select_clause:
@@ -3562,7 +4399,7 @@ simple_select:
 //        { <expr> [[AS] <name>] | [ [<dbname>.] <tablename>. ] * } [, ...]
 //        [ FROM <source> ]
 //        [ WHERE <expr> ]
-//        [ GROUP BY <expr> [ , ... ] ]
+//        [ GROUP BY { <expr> | ROLLUP ( ... ) | CUBE ( ... ) | GROUPING SETS ( ... ) } [ , ... ] ]
 //        [ HAVING <expr> ]
 //        [ WINDOW <name> AS ( <definition> ) ]
 //        [ { UNION | INTERSECT | EXCEPT } [ ALL | DISTINCT ] <selectclause> ]
@@ -3653,24 +4490,51 @@ table_clause:
 //
 // Recognizing WITH_LA here allows a CTE to be named TIME or ORDINALITY.
 with_clause:
-  WITH cte_list { return unimplemented(sqllex, "with cte_list") }
-| WITH_LA cte_list { return unimplemented(sqllex, "with cte_list") }
-| WITH RECURSIVE cte_list { return unimplemented(sqllex, "with cte_list") }
+  WITH cte_list
+  {
+    $$.val = &With{CTEList: $2.ctes()}
+  }
+| WITH_LA cte_list
+  {
+    $$.val = &With{CTEList: $2.ctes()}
+  }
+| WITH RECURSIVE cte_list
+  {
+    $$.val = &With{Recursive: true, CTEList: $3.ctes()}
+  }
 
 cte_list:
-  common_table_expr { return unimplemented(sqllex, "cte_list") }
-| cte_list ',' common_table_expr { return unimplemented(sqllex, "cte_list") }
+  common_table_expr
+  {
+    $$.val = []*CTE{$1.cte()}
+  }
+| cte_list ',' common_table_expr
+  {
+    $$.val = append($1.ctes(), $3.cte())
+  }
 
 common_table_expr:
-  name opt_name_list AS '(' preparable_stmt ')' { return unimplemented(sqllex, "cte") }
+  name opt_column_list AS '(' preparable_stmt ')'
+  {
+    $$.val = &CTE{
+      Name: AliasClause{Alias: Name($1), Cols: $2.nameList()},
+      Stmt: $5.stmt(),
+    }
+  }
 
 opt_with:
   WITH {}
 | /* EMPTY */ {}
 
 opt_with_clause:
-  with_clause { return unimplemented(sqllex, "with_clause") }
-| /* EMPTY */ {}
+  with_clause
+  {
+    $$.val = $1.with()
+  }
+| /* EMPTY */
+  {
+    $$.val = nil
+  }
 
 opt_table:
   TABLE {}
@@ -3847,7 +4711,7 @@ first_or_next:
 // Each item in the group_clause list is either an expression tree or a
 // GroupingSet node of some type.
 group_clause:
-  GROUP BY expr_list
+  GROUP BY group_by_list
   {
     $$.val = GroupBy($3.exprs())
   }
@@ -3856,6 +4720,36 @@ group_clause:
     $$.val = GroupBy(nil)
   }
 
+group_by_list:
+  group_by_item
+  {
+    $$.val = Exprs{$1.expr()}
+  }
+| group_by_list ',' group_by_item
+  {
+    $$.val = append($1.exprs(), $3.expr())
+  }
+
+group_by_item:
+  a_expr
+| '(' ')'
+  {
+    // The empty grouping set.
+    $$.val = &Tuple{}
+  }
+| ROLLUP '(' expr_list ')'
+  {
+    $$.val = &GroupingSet{Type: Rollup, Exprs: $3.exprs()}
+  }
+| CUBE '(' expr_list ')'
+  {
+    $$.val = &GroupingSet{Type: Cube, Exprs: $3.exprs()}
+  }
+| GROUPING SETS '(' group_by_list ')'
+  {
+    $$.val = &GroupingSet{Type: GroupingSets, Exprs: $4.exprs()}
+  }
+
 having_clause:
   HAVING a_expr
   {
@@ -3990,6 +4884,7 @@ opt_index_hints:
 //   <tablename> [ @ { <idxname> | <indexhint> } ]
 //   <tablefunc> ( <exprs...> )
 //   ( { <selectclause> | <source> } )
+//   LATERAL { ( <selectclause> ) | <tablefunc> ( <exprs...> ) }
 //   <source> [AS] <alias> [( <colnames...> )]
 //   <source> { [INNER] | { LEFT | RIGHT | FULL } [OUTER] } JOIN <source> ON <expr>
 //   <source> { [INNER] | { LEFT | RIGHT | FULL } [OUTER] } JOIN <source> USING ( <colnames...> )
@@ -4037,6 +4932,14 @@ table_ref:
   {
     $$.val = &AliasedTableExpr{Expr: &Subquery{Select: $1.selectStmt()}, Ordinality: $2.bool(), As: $3.aliasClause() }
   }
+| LATERAL select_with_parens opt_ordinality opt_alias_clause
+  {
+    $$.val = &AliasedTableExpr{Expr: &Subquery{Select: $2.selectStmt()}, Ordinality: $3.bool(), Lateral: true, As: $4.aliasClause() }
+  }
+| LATERAL qualified_name '(' expr_list ')' opt_ordinality opt_alias_clause
+  {
+    $$.val = &AliasedTableExpr{Expr: &FuncExpr{Func: $2.resolvableFunctionReference(), Exprs: $4.exprs()}, Ordinality: $6.bool(), Lateral: true, As: $7.aliasClause() }
+  }
 | joined_table
   {
     $$.val = $1.tblExpr()
@@ -4381,6 +5284,18 @@ simple_typename:
   {
     $$.val = int2vectorColType
   }
+| INET
+  {
+    $$.val = ipnetColTypeINet
+  }
+| JSON
+  {
+    $$.val = jsonColTypeJSON
+  }
+| JSONB
+  {
+    $$.val = jsonColTypeJSONB
+  }
 
 // We have a separate const_typename to allow defaulting fixed-length types
 // such as CHAR() and BIT() to an unspecified length. SQL9x requires that these
@@ -4771,7 +5686,10 @@ a_expr:
   {
     $$.val = &CollateExpr{Expr: $1.expr(), Locale: $3}
   }
-| a_expr AT TIME ZONE a_expr %prec AT { return unimplemented(sqllex, "at tz") }
+| a_expr AT TIME ZONE a_expr %prec AT
+  {
+    $$.val = &FuncExpr{Func: wrapFunction("timezone"), Exprs: Exprs{$5.expr(), $1.expr()}}
+  }
   // These operators must be called out explicitly in order to make use of
   // bison's automatic operator-precedence handling. All other operator names
   // are handled by the generic productions using "OP", below; and all those
@@ -4847,6 +5765,26 @@ a_expr:
   {
     $$.val = &BinaryExpr{Operator: Concat, Left: $1.expr(), Right: $3.expr()}
   }
+| a_expr FETCHVAL a_expr
+  {
+    $$.val = &BinaryExpr{Operator: JSONFetchVal, Left: $1.expr(), Right: $3.expr()}
+  }
+| a_expr FETCHTEXT a_expr
+  {
+    $$.val = &BinaryExpr{Operator: JSONFetchText, Left: $1.expr(), Right: $3.expr()}
+  }
+| a_expr CONTAINS a_expr
+  {
+    $$.val = &ComparisonExpr{Operator: Contains, Left: $1.expr(), Right: $3.expr()}
+  }
+| a_expr CONTAINED_BY a_expr
+  {
+    $$.val = &ComparisonExpr{Operator: ContainedBy, Left: $1.expr(), Right: $3.expr()}
+  }
+| a_expr '?' a_expr
+  {
+    $$.val = &ComparisonExpr{Operator: JSONExists, Left: $1.expr(), Right: $3.expr()}
+  }
 | a_expr LSHIFT a_expr
   {
     $$.val = &BinaryExpr{Operator: LShift, Left: $1.expr(), Right: $3.expr()}
@@ -4855,6 +5793,14 @@ a_expr:
   {
     $$.val = &BinaryExpr{Operator: RShift, Left: $1.expr(), Right: $3.expr()}
   }
+| a_expr INET_CONTAINED_BY_OR_EQUALS a_expr
+  {
+    $$.val = &FuncExpr{Func: wrapFunction("inet_contained_by_or_equals"), Exprs: Exprs{$1.expr(), $3.expr()}}
+  }
+| a_expr INET_CONTAINS_OR_EQUALS a_expr
+  {
+    $$.val = &FuncExpr{Func: wrapFunction("inet_contains_or_equals"), Exprs: Exprs{$1.expr(), $3.expr()}}
+  }
 | a_expr LESS_EQUALS a_expr
   {
     $$.val = &ComparisonExpr{Operator: LE, Left: $1.expr(), Right: $3.expr()}
@@ -5108,6 +6054,26 @@ b_expr:
   {
     $$.val = &BinaryExpr{Operator: Concat, Left: $1.expr(), Right: $3.expr()}
   }
+| b_expr FETCHVAL b_expr
+  {
+    $$.val = &BinaryExpr{Operator: JSONFetchVal, Left: $1.expr(), Right: $3.expr()}
+  }
+| b_expr FETCHTEXT b_expr
+  {
+    $$.val = &BinaryExpr{Operator: JSONFetchText, Left: $1.expr(), Right: $3.expr()}
+  }
+| b_expr CONTAINS b_expr
+  {
+    $$.val = &ComparisonExpr{Operator: Contains, Left: $1.expr(), Right: $3.expr()}
+  }
+| b_expr CONTAINED_BY b_expr
+  {
+    $$.val = &ComparisonExpr{Operator: ContainedBy, Left: $1.expr(), Right: $3.expr()}
+  }
+| b_expr '?' b_expr
+  {
+    $$.val = &ComparisonExpr{Operator: JSONExists, Left: $1.expr(), Right: $3.expr()}
+  }
 | b_expr LSHIFT b_expr
   {
     $$.val = &BinaryExpr{Operator: LShift, Left: $1.expr(), Right: $3.expr()}
@@ -5116,6 +6082,14 @@ b_expr:
   {
     $$.val = &BinaryExpr{Operator: RShift, Left: $1.expr(), Right: $3.expr()}
   }
+| b_expr INET_CONTAINED_BY_OR_EQUALS b_expr
+  {
+    $$.val = &FuncExpr{Func: wrapFunction("inet_contained_by_or_equals"), Exprs: Exprs{$1.expr(), $3.expr()}}
+  }
+| b_expr INET_CONTAINS_OR_EQUALS b_expr
+  {
+    $$.val = &FuncExpr{Func: wrapFunction("inet_contains_or_equals"), Exprs: Exprs{$1.expr(), $3.expr()}}
+  }
 | b_expr LESS_EQUALS b_expr
   {
     $$.val = &ComparisonExpr{Operator: LE, Left: $1.expr(), Right: $3.expr()}
@@ -5219,8 +6193,11 @@ d_expr:
   {
     $$.val = $1.expr()
   }
-// TODO(pmattis): Support this notation?
-// | GROUPING '(' expr_list ')' { return unimplemented(sqllex) }
+| GROUPING '(' expr_list ')'
+  {
+    $$.val = &FuncExpr{Func: wrapFunction($1), Exprs: $3.exprs()}
+  }
+| GROUPING '(' error { return helpWithFunction(sqllex, ResolvableFunctionReference{UnresolvedName{Name($1)}}) }
 
 func_application:
   func_name '(' ')'
@@ -5272,8 +6249,8 @@ func_expr:
 // expressions are not allowed, where needed to disambiguate the grammar
 // (e.g. in CREATE INDEX).
 func_expr_windowless:
-  func_application { return unimplemented(sqllex, "func_application") }
-| func_expr_common_subexpr { return unimplemented(sqllex, "func_expr_common_subexpr") }
+  func_application
+| func_expr_common_subexpr
 
 // Special expressions that are considered to be functions.
 func_expr_common_subexpr:
@@ -5305,7 +6282,10 @@ func_expr_common_subexpr:
     $$.val = &FuncExpr{Func: wrapFunction($1)}
   }
 | CURRENT_TIMESTAMP '(' error { return helpWithFunction(sqllex, ResolvableFunctionReference{UnresolvedName{Name($1)}}) }
-| CURRENT_ROLE { return unimplemented(sqllex, "current role") }
+| CURRENT_ROLE
+  {
+    $$.val = &FuncExpr{Func: wrapFunction("current_user")}
+  }
 | CURRENT_USER
   {
     $$.val = &FuncExpr{Func: wrapFunction($1)}
@@ -5340,6 +6320,11 @@ func_expr_common_subexpr:
   {
     $$.val = &FuncExpr{Func: wrapFunction($1), Exprs: $3.exprs()}
   }
+| FAMILY '(' a_expr ')'
+  {
+    $$.val = &FuncExpr{Func: wrapFunction($1), Exprs: Exprs{$3.expr()}}
+  }
+| FAMILY '(' error { return helpWithFunction(sqllex, ResolvableFunctionReference{UnresolvedName{Name($1)}}) }
 | EXTRACT_DURATION '(' error { return helpWithFunction(sqllex, ResolvableFunctionReference{UnresolvedName{Name($1)}}) }
 | OVERLAY '(' overlay_list ')'
   {
@@ -5465,6 +6450,7 @@ window_specification:
       RefName: Name($2),
       Partitions: $3.exprs(),
       OrderBy: $4.orderBy(),
+      Frame: $5.windowFrame(),
     }
   }
 
@@ -5493,29 +6479,94 @@ opt_partition_clause:
     $$.val = Exprs(nil)
   }
 
-// For frame clauses, we return a WindowDef, but only some fields are used:
-// frameOptions, startOffset, and endOffset.
-//
 // This is only a subset of the full SQL:2008 frame_clause grammar. We don't
 // support <window frame exclusion> yet.
 opt_frame_clause:
-  RANGE frame_extent { return unimplemented(sqllex, "frame range") }
-| ROWS frame_extent { return unimplemented(sqllex, "frame rows") }
-| /* EMPTY */ {}
+  RANGE frame_extent
+  {
+    $$.val = &WindowFrame{
+      Mode: RangeMode,
+      Bounds: $2.windowFrameBounds(),
+    }
+  }
+| ROWS frame_extent
+  {
+    $$.val = &WindowFrame{
+      Mode: RowsMode,
+      Bounds: $2.windowFrameBounds(),
+    }
+  }
+| /* EMPTY */
+  {
+    $$.val = (*WindowFrame)(nil)
+  }
 
 frame_extent:
-  frame_bound { return unimplemented(sqllex, "frame_extent") }
-| BETWEEN frame_bound AND frame_bound { return unimplemented(sqllex, "frame_extent") }
+  frame_bound
+  {
+    startBound := $1.windowFrameBound()
+    switch {
+    case startBound.BoundType == UnboundedFollowing:
+      sqllex.Error("frame start cannot be UNBOUNDED FOLLOWING")
+      return 1
+    case startBound.BoundType == ValueFollowing:
+      sqllex.Error("frame starting from following row cannot end with current row")
+      return 1
+    }
+    $$.val = WindowFrameBounds{StartBound: startBound}
+  }
+| BETWEEN frame_bound AND frame_bound
+  {
+    startBound := $2.windowFrameBound()
+    endBound := $4.windowFrameBound()
+    switch {
+    case startBound.BoundType == UnboundedFollowing:
+      sqllex.Error("frame start cannot be UNBOUNDED FOLLOWING")
+      return 1
+    case endBound.BoundType == UnboundedPreceding:
+      sqllex.Error("frame end cannot be UNBOUNDED PRECEDING")
+      return 1
+    case startBound.BoundType == CurrentRow && endBound.BoundType == ValuePreceding:
+      sqllex.Error("frame starting from current row cannot have preceding rows")
+      return 1
+    case startBound.BoundType == ValueFollowing &&
+      (endBound.BoundType == ValuePreceding || endBound.BoundType == CurrentRow):
+      sqllex.Error("frame starting from following row cannot have preceding rows")
+      return 1
+    }
+    $$.val = WindowFrameBounds{StartBound: startBound, EndBound: endBound}
+  }
 
 // This is used for both frame start and frame end, with output set up on the
 // assumption it's frame start; the frame_extent productions must reject
 // invalid cases.
 frame_bound:
-  UNBOUNDED PRECEDING { return unimplemented(sqllex, "frame_bound") }
-| UNBOUNDED FOLLOWING { return unimplemented(sqllex, "frame_bound") }
-| CURRENT ROW { return unimplemented(sqllex, "frame_bound") }
-| a_expr PRECEDING { return unimplemented(sqllex, "frame_bound") }
-| a_expr FOLLOWING { return unimplemented(sqllex, "frame_bound") }
+  UNBOUNDED PRECEDING
+  {
+    $$.val = &WindowFrameBound{BoundType: UnboundedPreceding}
+  }
+| UNBOUNDED FOLLOWING
+  {
+    $$.val = &WindowFrameBound{BoundType: UnboundedFollowing}
+  }
+| CURRENT ROW
+  {
+    $$.val = &WindowFrameBound{BoundType: CurrentRow}
+  }
+| a_expr PRECEDING
+  {
+    $$.val = &WindowFrameBound{
+      OffsetExpr: $1.expr(),
+      BoundType: ValuePreceding,
+    }
+  }
+| a_expr FOLLOWING
+  {
+    $$.val = &WindowFrameBound{
+      OffsetExpr: $1.expr(),
+      BoundType: ValueFollowing,
+    }
+  }
 
 // Supporting nonterminals for expressions.
 
@@ -6112,6 +7163,18 @@ signed_iconst:
     $$.val = &NumVal{Value: constant.UnaryOp(token.SUB, $2.numVal().Value, 0)}
   }
 
+// signed_iconst64 is a variant of signed_iconst which only accepts integer
+// literals that fit in an int64, reporting a syntax error otherwise.
+signed_iconst64:
+  signed_iconst
+  {
+    val, err := $1.numVal().AsInt64()
+    if err != nil {
+      sqllex.Error(err.Error()); return 1
+    }
+    $$.val = val
+  }
+
 interval:
   const_interval SCONST opt_interval
   {
@@ -6196,12 +7259,16 @@ unrestricted_name:
 unreserved_keyword:
   ACTION
 | ADD
+| ADMIN
+| AFTER
 | ALTER
 | AT
 | BACKUP
+| BEFORE
 | BEGIN
 | BLOB
 | BY
+| CACHE
 | CANCEL
 | CASCADE
 | CLUSTER
@@ -6225,6 +7292,7 @@ unreserved_keyword:
 | DISCARD
 | DOUBLE
 | DROP
+| EACH
 | ENCODING
 | EXECUTE
 | EXPERIMENTAL_FINGERPRINTS
@@ -6233,28 +7301,39 @@ unreserved_keyword:
 | FIRST
 | FOLLOWING
 | FORCE_INDEX
+| FUNCTION
 | GRANTS
 | HIGH
 | HOUR
 | IMPORT
+| INCREMENT
 | INCREMENTAL
 | INDEXES
+| INET
 | INSERT
 | INT2VECTOR
 | INTERLEAVE
+| INVERTED
 | ISOLATION
 | JOB
 | JOBS
+| JSON
+| JSONB
 | KEY
 | KEYS
 | KV
+| LANGUAGE
 | LC_COLLATE
 | LC_CTYPE
 | LEVEL
+| LIST
 | LOCAL
 | LOW
 | MATCH
+| MATERIALIZED
+| MAXVALUE
 | MINUTE
+| MINVALUE
 | MONTH
 | NAMES
 | NAN
@@ -6266,6 +7345,7 @@ unreserved_keyword:
 | OF
 | OFF
 | OID
+| OPTION
 | OPTIONS
 | ORDINALITY
 | OVER
@@ -6278,12 +7358,14 @@ unreserved_keyword:
 | PRECEDING
 | PREPARE
 | PRIORITY
+| PROCEDURE
 | QUERIES
 | QUERY
 | RANGE
 | READ
 | RECURSIVE
 | REF
+| REFRESH
 | REGCLASS
 | REGPROC
 | REGPROCEDURE
@@ -6292,11 +7374,15 @@ unreserved_keyword:
 | RELEASE
 | RENAME
 | REPEATABLE
+| REPLACE
 | RESET
 | RESTORE
 | RESTRICT
 | RESUME
+| RETURNS
 | REVOKE
+| ROLE
+| ROLES
 | ROLLBACK
 | ROLLUP
 | ROWS
@@ -6308,15 +7394,18 @@ unreserved_keyword:
 | SEARCH
 | SECOND
 | SERIALIZABLE
+| SEQUENCE
 | SEQUENCES
 | SESSION
 | SESSIONS
 | SET
+| SETS
 | SHOW
 | SIMPLE
 | SNAPSHOT
 | SQL
 | START
+| STATISTICS
 | STDIN
 | STORE
 | STORING
@@ -6332,6 +7421,7 @@ unreserved_keyword:
 | TEXT
 | TRACE
 | TRANSACTION
+| TRIGGER
 | TRUNCATE
 | TYPE
 | UNBOUNDED
@@ -6345,6 +7435,7 @@ unreserved_keyword:
 | VALIDATE
 | VALUE
 | VARYING
+| VOID
 | WITHIN
 | WITHOUT
 | WRITE
@@ -91,6 +91,10 @@ var (
 	TypeInterval Type = tInterval{}
 	// TypeUUID is the type of a DUuid. Can be compared with ==.
 	TypeUUID Type = tUUID{}
+	// TypeINet is the type of a DIPAddr. Can be compared with ==.
+	TypeINet Type = tINet{}
+	// TypeJSON is the type of a DJSON. Can be compared with ==.
+	TypeJSON Type = tJSON{}
 	// TypeTuple is the type family of a DTuple. CANNOT be compared with ==.
 	TypeTuple Type = TTuple(nil)
 	// TypeArray is the type family of a DArray. CANNOT be compared with ==.
@@ -142,6 +146,7 @@ var (
 		TypeTimestampTZ,
 		TypeInterval,
 		TypeUUID,
+		TypeINet,
 		TypeOid,
 	}
 )
@@ -167,8 +172,10 @@ var OidToType = map[oid.Oid]Type{
 	oid.T_int2:         typeInt2,
 	oid.T_int4:         typeInt4,
 	oid.T_int8:         TypeInt,
+	oid.T_inet:         TypeINet,
 	oid.T_int2vector:   TypeIntVector,
 	oid.T_interval:     TypeInterval,
+	oid.T_jsonb:        TypeJSON,
 	oid.T_name:         TypeName,
 	oid.T_numeric:      TypeDecimal,
 	oid.T_oid:          TypeOid,
@@ -393,6 +400,26 @@ func (tUUID) Oid() oid.Oid                { return oid.T_uuid }
 func (tUUID) SQLName() string             { return "uuid" }
 func (tUUID) IsAmbiguous() bool           { return false }
 
+type tINet struct{}
+
+func (tINet) String() string              { return "inet" }
+func (tINet) Equivalent(other Type) bool  { return UnwrapType(other) == TypeINet || other == TypeAny }
+func (tINet) FamilyEqual(other Type) bool { return UnwrapType(other) == TypeINet }
+func (tINet) Size() (uintptr, bool)       { return unsafe.Sizeof(DIPAddr{}), fixedSize }
+func (tINet) Oid() oid.Oid                { return oid.T_inet }
+func (tINet) SQLName() string             { return "inet" }
+func (tINet) IsAmbiguous() bool           { return false }
+
+type tJSON struct{}
+
+func (tJSON) String() string              { return "jsonb" }
+func (tJSON) Equivalent(other Type) bool  { return UnwrapType(other) == TypeJSON || other == TypeAny }
+func (tJSON) FamilyEqual(other Type) bool { return UnwrapType(other) == TypeJSON }
+func (tJSON) Size() (uintptr, bool)       { return unsafe.Sizeof(DJSON{}), variableSize }
+func (tJSON) Oid() oid.Oid                { return oid.T_jsonb }
+func (tJSON) SQLName() string             { return "jsonb" }
+func (tJSON) IsAmbiguous() bool           { return false }
+
 // TTuple is the type of a DTuple.
 type TTuple []Type
 
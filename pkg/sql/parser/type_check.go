@@ -39,6 +39,10 @@ type SemaContext struct {
 	// already.
 	SearchPath SearchPath
 
+	// Functions, if set, is used to look up the functions that are not
+	// builtins.
+	Functions FunctionResolver
+
 	// privileged, if true, enables "unsafe" builtins, e.g. those
 	// from the crdb_internal namespace. Must be set only for
 	// the root user.
@@ -402,10 +406,12 @@ func (expr *ExistsExpr) TypeCheck(ctx *SemaContext, desired Type) (TypedExpr, er
 // TypeCheck implements the Expr interface.
 func (expr *FuncExpr) TypeCheck(ctx *SemaContext, desired Type) (TypedExpr, error) {
 	var searchPath SearchPath
+	var functions FunctionResolver
 	if ctx != nil {
 		searchPath = ctx.SearchPath
+		functions = ctx.Functions
 	}
-	def, err := expr.Func.Resolve(searchPath)
+	def, err := expr.Func.ResolveWith(searchPath, functions)
 	if err != nil {
 		return nil, err
 	}
@@ -507,6 +513,13 @@ func (expr *FuncExpr) TypeCheck(ctx *SemaContext, desired Type) (TypedExpr, erro
 		}
 	}
 
+	if def.Name == "grouping" {
+		if expr.IsWindowFunctionApplication() || expr.Filter != nil || expr.Type == DistinctFuncType {
+			return nil, pgerror.NewErrorf(pgerror.CodeSyntaxError,
+				"OVER, FILTER and DISTINCT cannot be used with GROUPING()")
+		}
+	}
+
 	if expr.Filter != nil {
 		if builtin.class != AggregateClass {
 			// Same error message as Postgres.
@@ -721,6 +734,24 @@ func (expr DefaultVal) TypeCheck(_ *SemaContext, desired Type) (TypedExpr, error
 	return nil, errInvalidDefaultUsage
 }
 
+var errInvalidPartitionBoundUsage = errors.New("MINVALUE and MAXVALUE can only appear in a range partition bound")
+
+// TypeCheck implements the Expr interface.
+func (expr PartitionMinVal) TypeCheck(_ *SemaContext, desired Type) (TypedExpr, error) {
+	return nil, errInvalidPartitionBoundUsage
+}
+
+// TypeCheck implements the Expr interface.
+func (expr PartitionMaxVal) TypeCheck(_ *SemaContext, desired Type) (TypedExpr, error) {
+	return nil, errInvalidPartitionBoundUsage
+}
+
+// TypeCheck implements the Expr interface.
+func (expr *GroupingSet) TypeCheck(_ *SemaContext, desired Type) (TypedExpr, error) {
+	return nil, pgerror.NewErrorf(pgerror.CodeSyntaxError,
+		"%s can only appear in a GROUP BY clause", expr.Type)
+}
+
 // TypeCheck implements the Expr interface.
 func (expr *NumVal) TypeCheck(ctx *SemaContext, desired Type) (TypedExpr, error) {
 	return typeCheckConstant(expr, ctx, desired)
@@ -865,6 +896,14 @@ func (d *DBytes) TypeCheck(_ *SemaContext, _ Type) (TypedExpr, error) { return d
 // identity function for Datum.
 func (d *DUuid) TypeCheck(_ *SemaContext, _ Type) (TypedExpr, error) { return d, nil }
 
+// TypeCheck implements the Expr interface. It is implemented as an idempotent
+// identity function for Datum.
+func (d *DIPAddr) TypeCheck(_ *SemaContext, _ Type) (TypedExpr, error) { return d, nil }
+
+// TypeCheck implements the Expr interface. It is implemented as an idempotent
+// identity function for Datum.
+func (d *DJSON) TypeCheck(_ *SemaContext, _ Type) (TypedExpr, error) { return d, nil }
+
 // TypeCheck implements the Expr interface. It is implemented as an idempotent
 // identity function for Datum.
 func (d *DDate) TypeCheck(_ *SemaContext, _ Type) (TypedExpr, error) { return d, nil }
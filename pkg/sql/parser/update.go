@@ -24,18 +24,22 @@ import "bytes"
 
 // Update represents an UPDATE statement.
 type Update struct {
+	With      *With
 	Table     TableExpr
 	Exprs     UpdateExprs
+	From      TableExprs
 	Where     *Where
 	Returning ReturningClause
 }
 
 // Format implements the NodeFormatter interface.
 func (node *Update) Format(buf *bytes.Buffer, f FmtFlags) {
+	FormatNode(buf, f, node.With)
 	buf.WriteString("UPDATE ")
 	FormatNode(buf, f, node.Table)
 	buf.WriteString(" SET ")
 	FormatNode(buf, f, node.Exprs)
+	FormatNode(buf, f, node.From)
 	FormatNode(buf, f, node.Where)
 	FormatNode(buf, f, node.Returning)
 }
@@ -462,6 +462,16 @@ func (expr *Tuple) Walk(v Visitor) Expr {
 	return expr
 }
 
+// Walk implements the Expr interface.
+func (expr *GroupingSet) Walk(v Visitor) Expr {
+	if exprs, changed := walkExprSlice(v, expr.Exprs); changed {
+		exprCopy := *expr
+		exprCopy.Exprs = exprs
+		return &exprCopy
+	}
+	return expr
+}
+
 // Walk implements the Expr interface.
 func (expr *Array) Walk(v Visitor) Expr {
 	if exprs, changed := walkExprSlice(v, expr.Exprs); changed {
@@ -501,6 +511,12 @@ func (expr *ColumnItem) Walk(_ Visitor) Expr {
 // Walk implements the Expr interface.
 func (expr DefaultVal) Walk(_ Visitor) Expr { return expr }
 
+// Walk implements the Expr interface.
+func (expr PartitionMinVal) Walk(_ Visitor) Expr { return expr }
+
+// Walk implements the Expr interface.
+func (expr PartitionMaxVal) Walk(_ Visitor) Expr { return expr }
+
 // Walk implements the Expr interface.
 func (expr *NumVal) Walk(_ Visitor) Expr { return expr }
 
@@ -534,6 +550,12 @@ func (expr *DInterval) Walk(_ Visitor) Expr { return expr }
 // Walk implements the Expr interface.
 func (expr *DUuid) Walk(_ Visitor) Expr { return expr }
 
+// Walk implements the Expr interface.
+func (expr *DIPAddr) Walk(_ Visitor) Expr { return expr }
+
+// Walk implements the Expr interface.
+func (expr *DJSON) Walk(_ Visitor) Expr { return expr }
+
 // Walk implements the Expr interface.
 func (expr dNull) Walk(_ Visitor) Expr { return expr }
 
@@ -682,10 +704,16 @@ func (stmt *Delete) CopyNode() *Delete {
 // WalkStmt is part of the WalkableStmt interface.
 func (stmt *Delete) WalkStmt(v Visitor) Statement {
 	ret := stmt
+	if with, changed := walkWith(v, stmt.With); changed {
+		ret = stmt.CopyNode()
+		ret.With = with
+	}
 	if stmt.Where != nil {
 		e, changed := WalkExpr(v, stmt.Where.Expr)
 		if changed {
-			ret = stmt.CopyNode()
+			if ret == stmt {
+				ret = stmt.CopyNode()
+			}
 			ret.Where.Expr = e
 		}
 	}
@@ -725,10 +753,16 @@ func (stmt *Insert) CopyNode() *Insert {
 // WalkStmt is part of the WalkableStmt interface.
 func (stmt *Insert) WalkStmt(v Visitor) Statement {
 	ret := stmt
+	if with, changed := walkWith(v, stmt.With); changed {
+		ret = stmt.CopyNode()
+		ret.With = with
+	}
 	if stmt.Rows != nil {
 		rows, changed := WalkStmt(v, stmt.Rows)
 		if changed {
-			ret = stmt.CopyNode()
+			if ret == stmt {
+				ret = stmt.CopyNode()
+			}
 			ret.Rows = rows.(*Select)
 		}
 	}
@@ -859,6 +893,26 @@ func walkOrderBy(v Visitor, order OrderBy) (OrderBy, bool) {
 	return order, copied
 }
 
+func walkWith(v Visitor, with *With) (*With, bool) {
+	if with == nil {
+		return nil, false
+	}
+	var ret *With
+	for i, cte := range with.CTEList {
+		s, changed := WalkStmt(v, cte.Stmt)
+		if changed {
+			if ret == nil {
+				ret = &With{Recursive: with.Recursive, CTEList: append([]*CTE(nil), with.CTEList...)}
+			}
+			ret.CTEList[i] = &CTE{Name: cte.Name, Stmt: s}
+		}
+	}
+	if ret == nil {
+		return with, false
+	}
+	return ret, true
+}
+
 // CopyNode makes a copy of this Statement without recursing in any child Statements.
 func (stmt *Select) CopyNode() *Select {
 	stmtCopy := *stmt
@@ -872,9 +926,15 @@ func (stmt *Select) CopyNode() *Select {
 // WalkStmt is part of the WalkableStmt interface.
 func (stmt *Select) WalkStmt(v Visitor) Statement {
 	ret := stmt
+	if with, changed := walkWith(v, stmt.With); changed {
+		ret = stmt.CopyNode()
+		ret.With = with
+	}
 	sel, changed := WalkStmt(v, stmt.Select)
 	if changed {
-		ret = stmt.CopyNode()
+		if ret == stmt {
+			ret = stmt.CopyNode()
+		}
 		ret.Select = sel.(SelectStatement)
 	}
 	order, changed := walkOrderBy(v, stmt.OrderBy)
@@ -1063,6 +1123,10 @@ func (stmt *Update) CopyNode() *Update {
 // WalkStmt is part of the WalkableStmt interface.
 func (stmt *Update) WalkStmt(v Visitor) Statement {
 	ret := stmt
+	if with, changed := walkWith(v, stmt.With); changed {
+		ret = stmt.CopyNode()
+		ret.With = with
+	}
 	for i, expr := range stmt.Exprs {
 		e, changed := WalkExpr(v, expr.Expr)
 		if changed {
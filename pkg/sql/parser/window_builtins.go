@@ -48,8 +48,8 @@ type IndexedRow struct {
 	Row Datums
 }
 
-// WindowFrame is a view into a subset of data over which calculations are made.
-type WindowFrame struct {
+// WindowFrameRun contains the runtime state of window frame during calculations.
+type WindowFrameRun struct {
 	// constant for all calls to WindowFunc.Add
 	Rows        []IndexedRow
 	ArgIdxStart int // the index which arguments to the window function begin
@@ -61,36 +61,51 @@ type WindowFrame struct {
 	// changes for each peer group
 	FirstPeerIdx int // the first index in the current peer group
 	PeerRowCount int // the number of rows in the current peer group
+
+	// changes for each row, according to the frame clause of the window
+	// definition. The frame of the current row consists of the rows in the
+	// half-open interval [FrameStartIdx, FrameEndIdx). Without a frame clause,
+	// this goes from the start of the partition through the last peer of the
+	// current row. Neither the start nor the end of the frame ever decrease
+	// from one row to the next.
+	FrameStartIdx int
+	FrameEndIdx   int
 }
 
-func (wf WindowFrame) rank() int {
+func (wf WindowFrameRun) rank() int {
 	return wf.RowIdx + 1
 }
 
-func (wf WindowFrame) rowCount() int {
+func (wf WindowFrameRun) rowCount() int {
 	return len(wf.Rows)
 }
 
-// TODO(nvanbenschoten): This definition only holds while we don't support
-// frame specification (RANGE or ROWS) in the OVER clause.
-func (wf WindowFrame) frameSize() int {
-	return wf.FirstPeerIdx + wf.PeerRowCount
+// frameSize returns the number of rows in the frame of the current row.
+func (wf WindowFrameRun) frameSize() int {
+	if wf.FrameEndIdx <= wf.FrameStartIdx {
+		return 0
+	}
+	return wf.FrameEndIdx - wf.FrameStartIdx
 }
 
 // firstInPeerGroup returns if the current row is the first in its peer group.
-func (wf WindowFrame) firstInPeerGroup() bool {
+func (wf WindowFrameRun) firstInPeerGroup() bool {
 	return wf.RowIdx == wf.FirstPeerIdx
 }
 
-func (wf WindowFrame) args() Datums {
+func (wf WindowFrameRun) args() Datums {
 	return wf.argsWithRowOffset(0)
 }
 
-func (wf WindowFrame) argsWithRowOffset(offset int) Datums {
-	return wf.Rows[wf.RowIdx+offset].Row[wf.ArgIdxStart : wf.ArgIdxStart+wf.ArgCount]
+func (wf WindowFrameRun) argsWithRowOffset(offset int) Datums {
+	return wf.argsAtRow(wf.RowIdx + offset)
+}
+
+func (wf WindowFrameRun) argsAtRow(rowIdx int) Datums {
+	return wf.Rows[rowIdx].Row[wf.ArgIdxStart : wf.ArgIdxStart+wf.ArgCount]
 }
 
-// WindowFunc performs a computation on each row using data from a provided WindowFrame.
+// WindowFunc performs a computation on each row using data from a provided WindowFrameRun.
 type WindowFunc interface {
 	// Compute computes the window function for the provided window frame, given the
 	// current state of WindowFunc. The method should be called sequentially for every
@@ -98,7 +113,7 @@ type WindowFunc interface {
 	// because there is an implicit carried dependency between each row and all those
 	// that have come before it (like in an AggregateFunc). As such, this approach does
 	// not present any exploitable associativity/commutativity for optimization.
-	Compute(context.Context, *EvalContext, WindowFrame) (Datum, error)
+	Compute(context.Context, *EvalContext, WindowFrameRun) (Datum, error)
 
 	// Close allows the window function to free any memory it requested during execution,
 	// such as during the execution of an aggregation like CONCAT_AGG or ARRAY_AGG.
@@ -204,48 +219,194 @@ var _ WindowFunc = &nthValueWindow{}
 
 // aggregateWindowFunc aggregates over the the current row's window frame, using
 // the internal AggregateFunc to perform the aggregation.
+//
+// As long as the start of the window frame does not move, which is always the
+// case without a frame clause, the rows entering the frame are accumulated into
+// a single running aggregation. Once the start of the frame moves, the results
+// are computed using an aggregateSegmentTree built over the partition instead.
 type aggregateWindowFunc struct {
-	agg     AggregateFunc
-	peerRes Datum
+	newAgg func() AggregateFunc
+
+	// agg accumulates the rows [aggStart, aggEnd) of the partition.
+	agg              AggregateFunc
+	aggStart, aggEnd int
+
+	tree *aggregateSegmentTree
+
+	// res is the result for the frame [resStart, resEnd), which is shared by
+	// all the rows with the same frame, e.g. the rows in a peer group.
+	res              Datum
+	resStart, resEnd int
 }
 
-func newAggregateWindow(agg AggregateFunc) WindowFunc {
-	return &aggregateWindowFunc{agg: agg}
+func newAggregateWindow(newAgg func() AggregateFunc) WindowFunc {
+	return &aggregateWindowFunc{newAgg: newAgg}
 }
 
 func (w *aggregateWindowFunc) Compute(
-	ctx context.Context, evalCtx *EvalContext, wf WindowFrame,
+	ctx context.Context, evalCtx *EvalContext, wf WindowFrameRun,
 ) (Datum, error) {
-	if !wf.firstInPeerGroup() {
-		return w.peerRes, nil
-	}
-
-	// Accumulate all values in the peer group at the same time, as these
-	// must return the same value.
-	for i := 0; i < wf.PeerRowCount; i++ {
-		args := wf.argsWithRowOffset(i)
-		var value Datum
-		// COUNT_ROWS takes no arguments.
-		if len(args) > 0 {
-			value = args[0]
+	start, end := wf.FrameStartIdx, wf.FrameStartIdx+wf.frameSize()
+	if w.res != nil && start == w.resStart && end == w.resEnd {
+		return w.res, nil
+	}
+
+	var res Datum
+	var err error
+	if w.tree == nil && (w.agg == nil || (start == w.aggStart && end >= w.aggEnd)) {
+		if w.agg == nil {
+			w.agg = w.newAgg()
+			w.aggStart, w.aggEnd = start, start
 		}
-		if err := w.agg.Add(ctx, value); err != nil {
-			return nil, err
+		for ; w.aggEnd < end; w.aggEnd++ {
+			if err := w.agg.Add(ctx, aggregateArg(wf.argsAtRow(w.aggEnd))); err != nil {
+				return nil, err
+			}
 		}
+		res, err = w.agg.Result()
+	} else {
+		if w.tree == nil {
+			w.agg.Close(ctx)
+			w.agg = nil
+			if w.tree, err = makeAggregateSegmentTree(ctx, w.newAgg, wf); err != nil {
+				return nil, err
+			}
+		}
+		res, err = w.tree.aggregate(ctx, start, end)
 	}
-
-	// Retrieve the value for the entire peer group, save it, and return it.
-
-	peerRes, err := w.agg.Result()
 	if err != nil {
 		return nil, err
 	}
-	w.peerRes = peerRes
-	return w.peerRes, nil
+
+	// Save the result for the rows that share the same frame.
+	w.res, w.resStart, w.resEnd = res, start, end
+	return w.res, nil
 }
 
 func (w *aggregateWindowFunc) Close(ctx context.Context, evalCtx *EvalContext) {
-	w.agg.Close(ctx)
+	if w.agg != nil {
+		w.agg.Close(ctx)
+	}
+	if w.tree != nil {
+		w.tree.close(ctx)
+	}
+}
+
+// aggregateArg returns the argument to pass to AggregateFunc.Add for the
+// given window function arguments.
+func aggregateArg(args Datums) Datum {
+	// COUNT_ROWS takes no arguments.
+	if len(args) == 0 {
+		return nil
+	}
+	return args[0]
+}
+
+// aggregateSegmentTreeFanout is the number of children of each inner node of
+// an aggregateSegmentTree.
+const aggregateSegmentTreeFanout = 16
+
+// aggregateSegmentTree computes an aggregation over any range of rows of a
+// partition by combining the partial aggregations of a logarithmic number of
+// aligned groups of rows, instead of accumulating every row of the range.
+// See Leis et al. [http://www.vldb.org/pvldb/vol8/p1058-leis.pdf]
+type aggregateSegmentTree struct {
+	wf     WindowFrameRun
+	newAgg func() AggregateFunc
+
+	// levels[i][j] holds the aggregation of the rows
+	// [j*fanout^(i+1), (j+1)*fanout^(i+1)) of the partition. The tree
+	// has no levels if the aggregate function cannot be merged.
+	levels [][]mergeableAggregateFunc
+}
+
+func makeAggregateSegmentTree(
+	ctx context.Context, newAgg func() AggregateFunc, wf WindowFrameRun,
+) (*aggregateSegmentTree, error) {
+	t := &aggregateSegmentTree{wf: wf, newAgg: newAgg}
+	agg := newAgg()
+	_, mergeable := agg.(mergeableAggregateFunc)
+	agg.Close(ctx)
+	if !mergeable {
+		return t, nil
+	}
+	for n := len(wf.Rows); n > 1; n = len(t.levels[len(t.levels)-1]) {
+		level := make([]mergeableAggregateFunc, (n+aggregateSegmentTreeFanout-1)/aggregateSegmentTreeFanout)
+		for i := range level {
+			level[i] = newAgg().(mergeableAggregateFunc)
+			start := i * aggregateSegmentTreeFanout
+			end := start + aggregateSegmentTreeFanout
+			if end > n {
+				end = n
+			}
+			if err := t.accumulate(ctx, level[i], len(t.levels)-1, start, end); err != nil {
+				return nil, err
+			}
+		}
+		t.levels = append(t.levels, level)
+	}
+	return t, nil
+}
+
+// accumulate adds the items [start, end) of the given level of the tree to
+// agg. The level -1 refers to the rows of the partition.
+func (t *aggregateSegmentTree) accumulate(
+	ctx context.Context, agg AggregateFunc, level, start, end int,
+) error {
+	for i := start; i < end; i++ {
+		var err error
+		if level < 0 {
+			err = agg.Add(ctx, aggregateArg(t.wf.argsAtRow(i)))
+		} else {
+			err = agg.(mergeableAggregateFunc).merge(ctx, t.levels[level][i])
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// aggregate computes the aggregation of the rows [start, end) of the
+// partition.
+func (t *aggregateSegmentTree) aggregate(ctx context.Context, start, end int) (Datum, error) {
+	agg := t.newAgg()
+	defer agg.Close(ctx)
+
+	// The items are accumulated in the order of the rows they cover, so the
+	// trailing items found on the way up the tree are kept aside and
+	// accumulated last, in reverse order.
+	type span struct{ level, start, end int }
+	var trailing []span
+	for level := -1; start < end; level++ {
+		parentStart := (start + aggregateSegmentTreeFanout - 1) / aggregateSegmentTreeFanout
+		parentEnd := end / aggregateSegmentTreeFanout
+		if level+1 >= len(t.levels) || parentStart >= parentEnd {
+			if err := t.accumulate(ctx, agg, level, start, end); err != nil {
+				return nil, err
+			}
+			break
+		}
+		if err := t.accumulate(ctx, agg, level, start, parentStart*aggregateSegmentTreeFanout); err != nil {
+			return nil, err
+		}
+		trailing = append(trailing, span{level, parentEnd * aggregateSegmentTreeFanout, end})
+		start, end = parentStart, parentEnd
+	}
+	for i := len(trailing) - 1; i >= 0; i-- {
+		if err := t.accumulate(ctx, agg, trailing[i].level, trailing[i].start, trailing[i].end); err != nil {
+			return nil, err
+		}
+	}
+	return agg.Result()
+}
+
+func (t *aggregateSegmentTree) close(ctx context.Context) {
+	for _, level := range t.levels {
+		for _, agg := range level {
+			agg.Close(ctx)
+		}
+	}
 }
 
 // rowNumberWindow computes the number of the current row within its partition,
@@ -256,7 +417,7 @@ func newRowNumberWindow([]Type, *EvalContext) WindowFunc {
 	return &rowNumberWindow{}
 }
 
-func (rowNumberWindow) Compute(_ context.Context, _ *EvalContext, wf WindowFrame) (Datum, error) {
+func (rowNumberWindow) Compute(_ context.Context, _ *EvalContext, wf WindowFrameRun) (Datum, error) {
 	return NewDInt(DInt(wf.RowIdx + 1 /* one-indexed */)), nil
 }
 
@@ -271,7 +432,7 @@ func newRankWindow([]Type, *EvalContext) WindowFunc {
 	return &rankWindow{}
 }
 
-func (w *rankWindow) Compute(_ context.Context, _ *EvalContext, wf WindowFrame) (Datum, error) {
+func (w *rankWindow) Compute(_ context.Context, _ *EvalContext, wf WindowFrameRun) (Datum, error) {
 	if wf.firstInPeerGroup() {
 		w.peerRes = NewDInt(DInt(wf.rank()))
 	}
@@ -291,7 +452,7 @@ func newDenseRankWindow([]Type, *EvalContext) WindowFunc {
 }
 
 func (w *denseRankWindow) Compute(
-	_ context.Context, _ *EvalContext, wf WindowFrame,
+	_ context.Context, _ *EvalContext, wf WindowFrameRun,
 ) (Datum, error) {
 	if wf.firstInPeerGroup() {
 		w.denseRank++
@@ -315,7 +476,7 @@ func newPercentRankWindow([]Type, *EvalContext) WindowFunc {
 var dfloatZero = NewDFloat(0)
 
 func (w *percentRankWindow) Compute(
-	_ context.Context, _ *EvalContext, wf WindowFrame,
+	_ context.Context, _ *EvalContext, wf WindowFrameRun,
 ) (Datum, error) {
 	// Return zero if there's only one row, per spec.
 	if wf.rowCount() <= 1 {
@@ -342,11 +503,11 @@ func newCumulativeDistWindow([]Type, *EvalContext) WindowFunc {
 }
 
 func (w *cumulativeDistWindow) Compute(
-	_ context.Context, _ *EvalContext, wf WindowFrame,
+	_ context.Context, _ *EvalContext, wf WindowFrameRun,
 ) (Datum, error) {
 	if wf.firstInPeerGroup() {
 		// (number of rows preceding or peer with current row) / (total rows)
-		w.peerRes = NewDFloat(DFloat(wf.frameSize()) / DFloat(wf.rowCount()))
+		w.peerRes = NewDFloat(DFloat(wf.FirstPeerIdx+wf.PeerRowCount) / DFloat(wf.rowCount()))
 	}
 	return w.peerRes, nil
 }
@@ -368,7 +529,7 @@ func newNtileWindow([]Type, *EvalContext) WindowFunc {
 
 var errInvalidArgumentForNtile = pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError, "argument of ntile() must be greater than zero")
 
-func (w *ntileWindow) Compute(_ context.Context, _ *EvalContext, wf WindowFrame) (Datum, error) {
+func (w *ntileWindow) Compute(_ context.Context, _ *EvalContext, wf WindowFrameRun) (Datum, error) {
 	if w.ntile == nil {
 		// If this is the first call to ntileWindow.Compute, set up the buckets.
 		total := wf.rowCount()
@@ -436,7 +597,7 @@ func makeLeadLagWindowConstructor(
 	}
 }
 
-func (w *leadLagWindow) Compute(_ context.Context, _ *EvalContext, wf WindowFrame) (Datum, error) {
+func (w *leadLagWindow) Compute(_ context.Context, _ *EvalContext, wf WindowFrameRun) (Datum, error) {
 	offset := 1
 	if w.withOffset {
 		offsetArg := wf.args()[1]
@@ -470,8 +631,11 @@ func newFirstValueWindow([]Type, *EvalContext) WindowFunc {
 	return &firstValueWindow{}
 }
 
-func (firstValueWindow) Compute(_ context.Context, _ *EvalContext, wf WindowFrame) (Datum, error) {
-	return wf.Rows[0].Row[wf.ArgIdxStart], nil
+func (firstValueWindow) Compute(_ context.Context, _ *EvalContext, wf WindowFrameRun) (Datum, error) {
+	if wf.frameSize() == 0 {
+		return DNull, nil
+	}
+	return wf.Rows[wf.FrameStartIdx].Row[wf.ArgIdxStart], nil
 }
 
 func (firstValueWindow) Close(context.Context, *EvalContext) {}
@@ -483,8 +647,11 @@ func newLastValueWindow([]Type, *EvalContext) WindowFunc {
 	return &lastValueWindow{}
 }
 
-func (lastValueWindow) Compute(_ context.Context, _ *EvalContext, wf WindowFrame) (Datum, error) {
-	return wf.Rows[wf.frameSize()-1].Row[wf.ArgIdxStart], nil
+func (lastValueWindow) Compute(_ context.Context, _ *EvalContext, wf WindowFrameRun) (Datum, error) {
+	if wf.frameSize() == 0 {
+		return DNull, nil
+	}
+	return wf.Rows[wf.FrameEndIdx-1].Row[wf.ArgIdxStart], nil
 }
 
 func (lastValueWindow) Close(context.Context, *EvalContext) {}
@@ -499,7 +666,7 @@ func newNthValueWindow([]Type, *EvalContext) WindowFunc {
 
 var errInvalidArgumentForNthValue = pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError, "argument of nth_value() must be greater than zero")
 
-func (nthValueWindow) Compute(_ context.Context, _ *EvalContext, wf WindowFrame) (Datum, error) {
+func (nthValueWindow) Compute(_ context.Context, _ *EvalContext, wf WindowFrameRun) (Datum, error) {
 	arg := wf.args()[1]
 	if arg == DNull {
 		return DNull, nil
@@ -515,7 +682,7 @@ func (nthValueWindow) Compute(_ context.Context, _ *EvalContext, wf WindowFrame)
 	if nth > wf.frameSize() {
 		return DNull, nil
 	}
-	return wf.Rows[nth-1].Row[wf.ArgIdxStart], nil
+	return wf.Rows[wf.FrameStartIdx+nth-1].Row[wf.ArgIdxStart], nil
 }
 
 func (nthValueWindow) Close(context.Context, *EvalContext) {}
@@ -0,0 +1,333 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// createPartitioning constructs the partitioning descriptor of an index from
+// its PARTITION BY clause. The index's columns must already be filled in. The
+// partitions themselves (overlaps, name uniqueness) are validated along with
+// the rest of the table descriptor.
+func createPartitioning(
+	evalCtx *parser.EvalContext,
+	searchPath parser.SearchPath,
+	tableDesc *sqlbase.TableDescriptor,
+	indexDesc *sqlbase.IndexDescriptor,
+	partBy *parser.PartitionBy,
+) (sqlbase.PartitioningDescriptor, error) {
+	var partDesc sqlbase.PartitioningDescriptor
+	if partBy == nil {
+		return partDesc, nil
+	}
+	if indexDesc.Type == sqlbase.IndexDescriptor_INVERTED {
+		return partDesc, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"cannot partition an inverted index")
+	}
+	if len(indexDesc.Expressions) > 0 {
+		return partDesc, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"cannot partition an index on expressions")
+	}
+
+	// The partitioning columns must be a prefix of the index columns.
+	for i, field := range partBy.Fields {
+		if i >= len(indexDesc.ColumnNames) || string(field) != indexDesc.ColumnNames[i] {
+			n := len(partBy.Fields)
+			if n > len(indexDesc.ColumnNames) {
+				n = len(indexDesc.ColumnNames)
+			}
+			return partDesc, pgerror.NewErrorf(pgerror.CodeSyntaxError,
+				"declared partition columns (%s) do not match first %d columns in index being partitioned (%s)",
+				parser.AsString(partBy.Fields), n, strings.Join(indexDesc.ColumnNames[:n], ", "))
+		}
+	}
+	cols := make([]sqlbase.ColumnDescriptor, len(partBy.Fields))
+	for i, field := range partBy.Fields {
+		col, _, err := tableDesc.FindColumnByName(field)
+		if err != nil {
+			return partDesc, err
+		}
+		cols[i] = col
+	}
+
+	partDesc.NumColumns = uint32(len(partBy.Fields))
+	for _, l := range partBy.List {
+		p := sqlbase.PartitioningDescriptor_List{Name: string(l.Name)}
+		for _, expr := range l.Exprs {
+			encoded, err := valueEncodePartitionTuple(
+				evalCtx, searchPath, l.Name, expr, cols, false /* isRange */)
+			if err != nil {
+				return partDesc, err
+			}
+			p.Values = append(p.Values, encoded)
+		}
+		partDesc.List = append(partDesc.List, p)
+	}
+	for _, r := range partBy.Range {
+		p := sqlbase.PartitioningDescriptor_Range{Name: string(r.Name)}
+		var err error
+		p.FromInclusive, err = valueEncodePartitionTuple(
+			evalCtx, searchPath, r.Name, &parser.Tuple{Exprs: r.From}, cols, true /* isRange */)
+		if err != nil {
+			return partDesc, err
+		}
+		p.ToExclusive, err = valueEncodePartitionTuple(
+			evalCtx, searchPath, r.Name, &parser.Tuple{Exprs: r.To}, cols, true /* isRange */)
+		if err != nil {
+			return partDesc, err
+		}
+		partDesc.Range = append(partDesc.Range, p)
+	}
+	return partDesc, nil
+}
+
+// valueEncodePartitionTuple evaluates the values of a partition tuple and
+// returns their encoding, as stored in a PartitioningDescriptor. For a list
+// partition over a single column, maybeTuple is a single value; a lone
+// DEFAULT stands for DEFAULT in every column.
+func valueEncodePartitionTuple(
+	evalCtx *parser.EvalContext,
+	searchPath parser.SearchPath,
+	partName parser.Name,
+	maybeTuple parser.Expr,
+	cols []sqlbase.ColumnDescriptor,
+	isRange bool,
+) ([]byte, error) {
+	var tuple *parser.Tuple
+	if _, ok := maybeTuple.(parser.DefaultVal); ok {
+		tuple = &parser.Tuple{Exprs: make(parser.Exprs, len(cols))}
+		for i := range tuple.Exprs {
+			tuple.Exprs[i] = parser.DefaultVal{}
+		}
+	} else if t, ok := maybeTuple.(*parser.Tuple); ok && (isRange || len(cols) > 1) {
+		tuple = t
+	} else {
+		tuple = &parser.Tuple{Exprs: parser.Exprs{maybeTuple}}
+	}
+	if len(tuple.Exprs) != len(cols) {
+		return nil, pgerror.NewErrorf(pgerror.CodeSyntaxError,
+			"PARTITION %s: partition has %d columns but %d values were supplied",
+			partName, len(cols), len(tuple.Exprs))
+	}
+
+	var t sqlbase.PartitionTuple
+	for i, expr := range tuple.Exprs {
+		var special sqlbase.PartitionSpecialValCode
+		switch expr.(type) {
+		case parser.DefaultVal:
+			if isRange {
+				return nil, pgerror.NewErrorf(pgerror.CodeSyntaxError,
+					"PARTITION %s: DEFAULT cannot be used with PARTITION BY RANGE", partName)
+			}
+			special = sqlbase.PartitionDefaultVal
+		case parser.PartitionMinVal:
+			if !isRange {
+				return nil, pgerror.NewErrorf(pgerror.CodeSyntaxError,
+					"PARTITION %s: MINVALUE cannot be used with PARTITION BY LIST", partName)
+			}
+			special = sqlbase.PartitionMinVal
+		case parser.PartitionMaxVal:
+			if !isRange {
+				return nil, pgerror.NewErrorf(pgerror.CodeSyntaxError,
+					"PARTITION %s: MAXVALUE cannot be used with PARTITION BY LIST", partName)
+			}
+			special = sqlbase.PartitionMaxVal
+		default:
+			if t.SpecialCount > 0 {
+				return nil, pgerror.NewErrorf(pgerror.CodeSyntaxError,
+					"PARTITION %s: %s cannot be followed by a value", partName, t.Special)
+			}
+			typedExpr, err := sqlbase.SanitizeVarFreeExpr(
+				expr, cols[i].Type.ToDatumType(), "partition", searchPath)
+			if err != nil {
+				return nil, errors.Wrapf(err, "PARTITION %s", partName)
+			}
+			datum, err := typedExpr.Eval(evalCtx)
+			if err != nil {
+				return nil, errors.Wrapf(err, "evaluating %s", parser.AsString(typedExpr))
+			}
+			t.Datums = append(t.Datums, datum)
+			continue
+		}
+		if t.SpecialCount > 0 && special != t.Special {
+			return nil, pgerror.NewErrorf(pgerror.CodeSyntaxError,
+				"PARTITION %s: %s cannot be followed by %s", partName, t.Special, special)
+		}
+		t.Special = special
+		t.SpecialCount++
+	}
+	return sqlbase.EncodePartitionTuple(&t, nil /* scratch */)
+}
+
+// pruneSubzones removes the subzones of the table's zone config which refer
+// to indexes or partitions that no longer exist, and regenerates its subzone
+// spans. It is called whenever an index of the table is dropped.
+func pruneSubzones(ctx context.Context, txn *client.Txn, tableDesc *sqlbase.TableDescriptor) error {
+	zoneKey := sqlbase.MakeZoneKey(tableDesc.ID)
+	kv, err := txn.Get(ctx, zoneKey)
+	if err != nil || kv.Value == nil {
+		return err
+	}
+	zone, err := config.MigrateZoneConfig(kv.Value)
+	if err != nil {
+		return err
+	}
+	if len(zone.Subzones) == 0 {
+		return nil
+	}
+	if err := sqlbase.PruneSubzones(tableDesc, &zone); err != nil {
+		return err
+	}
+	if zone.NumReplicas == 0 && len(zone.Subzones) == 0 {
+		// The zone config only stored the subzones.
+		return txn.Del(ctx, zoneKey)
+	}
+	return txn.Put(ctx, zoneKey, &zone)
+}
+
+// remapSubzones moves the subzones of the indexes of the table that were
+// replaced, keyed by ID in replaced, to the indexes replacing them, and
+// regenerates the subzone spans. It is called when ALTER COLUMN ... TYPE
+// completes the rebuild of the indexes referring to a column.
+func remapSubzones(
+	ctx context.Context,
+	txn *client.Txn,
+	tableDesc *sqlbase.TableDescriptor,
+	replaced map[sqlbase.IndexID]sqlbase.IndexID,
+) error {
+	zoneKey := sqlbase.MakeZoneKey(tableDesc.ID)
+	kv, err := txn.Get(ctx, zoneKey)
+	if err != nil || kv.Value == nil {
+		return err
+	}
+	zone, err := config.MigrateZoneConfig(kv.Value)
+	if err != nil {
+		return err
+	}
+	if len(zone.Subzones) == 0 {
+		return nil
+	}
+	for i := range zone.Subzones {
+		if id, ok := replaced[sqlbase.IndexID(zone.Subzones[i].IndexID)]; ok {
+			zone.Subzones[i].IndexID = uint32(id)
+		}
+	}
+	if err := sqlbase.PruneSubzones(tableDesc, &zone); err != nil {
+		return err
+	}
+	return txn.Put(ctx, zoneKey, &zone)
+}
+
+// formatPartitioning writes the PARTITION BY clause of an index to buf, for
+// SHOW CREATE TABLE.
+func formatPartitioning(
+	buf *bytes.Buffer,
+	a *sqlbase.DatumAlloc,
+	tableDesc *sqlbase.TableDescriptor,
+	indexDesc *sqlbase.IndexDescriptor,
+) error {
+	part := &indexDesc.Partitioning
+	if part.NumColumns == 0 {
+		return nil
+	}
+	if len(part.List) > 0 {
+		buf.WriteString(" PARTITION BY LIST (")
+	} else {
+		buf.WriteString(" PARTITION BY RANGE (")
+	}
+	for i := 0; i < int(part.NumColumns); i++ {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		parser.Name(indexDesc.ColumnNames[i]).Format(buf, parser.FmtSimple)
+	}
+	buf.WriteString(") (")
+	for i, p := range part.List {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString("PARTITION ")
+		parser.Name(p.Name).Format(buf, parser.FmtSimple)
+		buf.WriteString(" VALUES IN (")
+		for j, values := range p.Values {
+			if j > 0 {
+				buf.WriteString(", ")
+			}
+			t, _, err := sqlbase.DecodePartitionTuple(a, tableDesc, indexDesc, values)
+			if err != nil {
+				return err
+			}
+			if part.NumColumns == 1 || t.SpecialCount == int(part.NumColumns) {
+				// Single values and DEFAULT are not parenthesized.
+				if t.SpecialCount > 0 {
+					buf.WriteString(t.Special.String())
+				} else {
+					t.Datums[0].Format(buf, parser.FmtSimple)
+				}
+				continue
+			}
+			formatPartitionTuple(buf, t)
+		}
+		buf.WriteByte(')')
+	}
+	for i, p := range part.Range {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString("PARTITION ")
+		parser.Name(p.Name).Format(buf, parser.FmtSimple)
+		buf.WriteString(" VALUES FROM ")
+		from, _, err := sqlbase.DecodePartitionTuple(a, tableDesc, indexDesc, p.FromInclusive)
+		if err != nil {
+			return err
+		}
+		formatPartitionTuple(buf, from)
+		buf.WriteString(" TO ")
+		to, _, err := sqlbase.DecodePartitionTuple(a, tableDesc, indexDesc, p.ToExclusive)
+		if err != nil {
+			return err
+		}
+		formatPartitionTuple(buf, to)
+	}
+	buf.WriteByte(')')
+	return nil
+}
+
+// formatPartitionTuple writes a parenthesized partition tuple to buf.
+func formatPartitionTuple(buf *bytes.Buffer, t *sqlbase.PartitionTuple) {
+	buf.WriteByte('(')
+	for i := 0; i < len(t.Datums)+t.SpecialCount; i++ {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		if i < len(t.Datums) {
+			t.Datums[i].Format(buf, parser.FmtSimple)
+		} else {
+			buf.WriteString(t.Special.String())
+		}
+	}
+	buf.WriteByte(')')
+}
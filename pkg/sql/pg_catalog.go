@@ -54,6 +54,7 @@ var pgCatalog = virtualSchema{
 		pgCatalogAmTable,
 		pgCatalogAttrDefTable,
 		pgCatalogAttributeTable,
+		pgCatalogAuthMembersTable,
 		pgCatalogClassTable,
 		pgCatalogCollationTable,
 		pgCatalogConstraintTable,
@@ -126,8 +127,8 @@ CREATE TABLE pg_catalog.pg_attrdef (
 					h.ColumnOid(db, table, column),      // oid
 					h.TableOid(db, table),               // adrelid
 					parser.NewDInt(parser.DInt(colNum)), // adnum
-					defSrc, // adbin
-					defSrc, // adsrc
+					defSrc,                              // adbin
+					defSrc,                              // adsrc
 				)
 			})
 		})
@@ -174,12 +175,12 @@ CREATE TABLE pg_catalog.pg_attribute (
 					zeroVal,                             // attstattarget
 					typLen(colTyp),                      // attlen
 					parser.NewDInt(parser.DInt(colNum)), // attnum
-					zeroVal,      // attndims
-					negOneVal,    // attcacheoff
-					negOneVal,    // atttypmod
-					parser.DNull, // attbyval (see pg_type.typbyval)
-					parser.DNull, // attstorage
-					parser.DNull, // attalign
+					zeroVal,                             // attndims
+					negOneVal,                           // attcacheoff
+					negOneVal,                           // atttypmod
+					parser.DNull,                        // attbyval (see pg_type.typbyval)
+					parser.DNull,                        // attstorage
+					parser.DNull,                        // attalign
 					parser.MakeDBool(parser.DBool(!column.Nullable)),          // attnotnull
 					parser.MakeDBool(parser.DBool(column.DefaultExpr != nil)), // atthasdef
 					parser.MakeDBool(false),                                   // attisdropped
@@ -218,11 +219,51 @@ CREATE TABLE pg_catalog.pg_attribute (
 }
 
 var (
-	relKindTable = parser.NewDString("r")
-	relKindIndex = parser.NewDString("i")
-	relKindView  = parser.NewDString("v")
+	relKindTable    = parser.NewDString("r")
+	relKindIndex    = parser.NewDString("i")
+	relKindView     = parser.NewDString("v")
+	relKindMatView  = parser.NewDString("m")
+	relKindSequence = parser.NewDString("S")
 )
 
+// See: https://www.postgresql.org/docs/9.6/static/catalog-pg-auth-members.html.
+var pgCatalogAuthMembersTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE pg_catalog.pg_auth_members (
+	roleid OID,
+	member OID,
+	grantor OID,
+	admin_option BOOL
+);
+`,
+	populate: func(ctx context.Context, p *planner, _ string, addRow func(...parser.Datum) error) error {
+		// Like pg_roles, pg_auth_members is readable by non-privileged users, so
+		// system.role_members is read as root.
+		h := makeOidHasher()
+		internalExecutor := InternalExecutor{LeaseManager: p.LeaseMgr()}
+		rows, err := internalExecutor.QueryRowsInTransaction(
+			ctx, "read-role-members", p.txn,
+			`SELECT "role", "member", "isAdmin" FROM system.role_members`,
+		)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			role := parser.MustBeDString(row[0])
+			member := parser.MustBeDString(row[1])
+			if err := addRow(
+				h.UserOid(string(role)),   // roleid
+				h.UserOid(string(member)), // member
+				parser.DNull,              // grantor
+				row[2],                    // admin_option
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
 // See: https://www.postgresql.org/docs/9.6/static/catalog-pg-class.html.
 var pgCatalogClassTable = virtualSchemaTable{
 	schema: `
@@ -260,9 +301,13 @@ CREATE TABLE pg_catalog.pg_class (
 		return forEachTableDesc(ctx, p, prefix, func(db *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor) error {
 			// Table.
 			relKind := relKindTable
-			if table.IsView() {
+			if table.IsMaterializedView() {
+				relKind = relKindMatView
+			} else if table.IsView() {
 				// The only difference between tables and views is the relkind column.
 				relKind = relKindView
+			} else if table.IsSequence() {
+				relKind = relKindSequence
 			}
 			if err := addRow(
 				h.TableOid(db, table),       // oid
@@ -383,11 +428,13 @@ var (
 	fkActionSetNull    = parser.NewDString("n")
 	fkActionSetDefault = parser.NewDString("d")
 
-	// Avoid unused warning for constants.
-	_ = fkActionRestrict
-	_ = fkActionCascade
-	_ = fkActionSetNull
-	_ = fkActionSetDefault
+	fkActionMap = map[sqlbase.ForeignKeyReference_Action]parser.Datum{
+		sqlbase.ForeignKeyReference_NO_ACTION:   fkActionNone,
+		sqlbase.ForeignKeyReference_RESTRICT:    fkActionRestrict,
+		sqlbase.ForeignKeyReference_SET_NULL:    fkActionSetNull,
+		sqlbase.ForeignKeyReference_SET_DEFAULT: fkActionSetDefault,
+		sqlbase.ForeignKeyReference_CASCADE:     fkActionCascade,
+	}
 
 	fkMatchTypeFull    = parser.NewDString("f")
 	fkMatchTypePartial = parser.NewDString("p")
@@ -476,8 +523,8 @@ CREATE TABLE pg_catalog.pg_constraint (
 					contype = conTypeFK
 					conindid = h.IndexOid(referencedDB, c.ReferencedTable, c.ReferencedIndex)
 					confrelid = h.TableOid(referencedDB, c.ReferencedTable)
-					confupdtype = fkActionNone
-					confdeltype = fkActionNone
+					confupdtype = fkActionMap[c.FK.OnUpdate]
+					confdeltype = fkActionMap[c.FK.OnDelete]
 					confmatchtype = fkMatchTypeSimple
 					var err error
 					conkey, err = colIDArrayToDatum(c.Index.ColumnIDs)
@@ -509,12 +556,12 @@ CREATE TABLE pg_catalog.pg_constraint (
 				}
 
 				if err := addRow(
-					oid,                                            // oid
-					dNameOrNull(name),                              // conname
-					pgNamespaceForDB(db, h).Oid,                    // connamespace
-					contype,                                        // contype
-					parser.MakeDBool(false),                        // condeferrable
-					parser.MakeDBool(false),                        // condeferred
+					oid,                         // oid
+					dNameOrNull(name),           // conname
+					pgNamespaceForDB(db, h).Oid, // connamespace
+					contype,                     // contype
+					parser.MakeDBool(false),     // condeferrable
+					parser.MakeDBool(false),     // condeferred
 					parser.MakeDBool(parser.DBool(!c.Unvalidated)), // convalidated
 					h.TableOid(db, table),                          // conrelid
 					oidZero,                                        // contypid
@@ -939,6 +986,12 @@ func indexDefFromDescriptor(
 			Column:    parser.Name(name),
 			Direction: parser.Ascending,
 		}
+		if expr := index.Expression(i); expr != "" {
+			var err error
+			if elem.Expr, err = parser.ParseExpr(table.FormatColumnExpr(expr)); err != nil {
+				return "", err
+			}
+		}
 		if index.ColumnDirections[i] == sqlbase.IndexDescriptor_DESC {
 			elem.Direction = parser.Descending
 		}
@@ -1126,7 +1179,7 @@ CREATE TABLE pg_catalog.pg_proc (
 				switch argTypes.(type) {
 				case parser.VariadicType:
 					argmodes = proArgModeVariadic
-					argType := argTypes.Types()[0]
+					argType := argTypes.(parser.VariadicType).Typ
 					oid := argType.Oid()
 					variadicType = parser.NewDOid(parser.DInt(oid))
 				case parser.HomogeneousType:
@@ -1140,35 +1193,35 @@ CREATE TABLE pg_catalog.pg_proc (
 				}
 				err := addRow(
 					h.BuiltinOid(name, &builtin), // oid
-					dName,                                             // proname
-					nspOid,                                            // pronamespace
-					parser.DNull,                                      // proowner
-					oidZero,                                           // prolang
-					parser.DNull,                                      // procost
-					parser.DNull,                                      // prorows
-					variadicType,                                      // provariadic
-					parser.DNull,                                      // protransform
-					parser.MakeDBool(parser.DBool(isAggregate)),       // proisagg
-					parser.MakeDBool(parser.DBool(isWindow)),          // proiswindow
-					parser.MakeDBool(false),                           // prosecdef
-					parser.MakeDBool(parser.DBool(!builtin.Impure())), // proleakproof
-					parser.MakeDBool(false),                           // proisstrict
-					parser.MakeDBool(parser.DBool(isRetSet)),          // proretset
-					parser.DNull,                                      // provolatile
-					parser.DNull,                                      // proparallel
+					dName,                        // proname
+					nspOid,                       // pronamespace
+					parser.DNull,                 // proowner
+					oidZero,                      // prolang
+					parser.DNull,                 // procost
+					parser.DNull,                 // prorows
+					variadicType,                 // provariadic
+					parser.DNull,                 // protransform
+					parser.MakeDBool(parser.DBool(isAggregate)),         // proisagg
+					parser.MakeDBool(parser.DBool(isWindow)),            // proiswindow
+					parser.MakeDBool(false),                             // prosecdef
+					parser.MakeDBool(parser.DBool(!builtin.Impure())),   // proleakproof
+					parser.MakeDBool(false),                             // proisstrict
+					parser.MakeDBool(parser.DBool(isRetSet)),            // proretset
+					parser.DNull,                                        // provolatile
+					parser.DNull,                                        // proparallel
 					parser.NewDInt(parser.DInt(builtin.Types.Length())), // pronargs
 					parser.NewDInt(parser.DInt(0)),                      // pronargdefaults
-					retType, // prorettype
-					parser.NewDString(dArgTypeString), // proargtypes
-					parser.DNull,                      // proallargtypes
-					argmodes,                          // proargmodes
-					parser.DNull,                      // proargnames
-					parser.DNull,                      // proargdefaults
-					parser.DNull,                      // protrftypes
-					dSrc,                              // prosrc
-					parser.DNull,                      // probin
-					parser.DNull,                      // proconfig
-					parser.DNull,                      // proacl
+					retType,                                             // prorettype
+					parser.NewDString(dArgTypeString),                   // proargtypes
+					parser.DNull,                                        // proallargtypes
+					argmodes,                                            // proargmodes
+					parser.DNull,                                        // proargnames
+					parser.DNull,                                        // proargdefaults
+					parser.DNull,                                        // protrftypes
+					dSrc,                                                // prosrc
+					parser.DNull,                                        // probin
+					parser.DNull,                                        // proconfig
+					parser.DNull,                                        // proacl
 				)
 				if err != nil {
 					return err
@@ -1223,18 +1276,21 @@ CREATE TABLE pg_catalog.pg_roles (
 		// need to do the same. This shouldn't be an issue, because pg_roles doesn't
 		// include sensitive information such as password hashes.
 		h := makeOidHasher()
-		return forEachUser(ctx, p,
-			func(username string) error {
+		return forEachRole(ctx, p,
+			func(username string, isRole bool) error {
 				isRoot := parser.DBool(username == security.RootUser)
+				// Roles cannot log in. Privileges granted to a role are always
+				// inherited by its members.
+				canLogin := parser.DBool(!isRole)
 				return addRow(
 					h.UserOid(username),           // oid
 					parser.NewDName(username),     // rolname
 					parser.MakeDBool(isRoot),      // rolsuper
-					parser.MakeDBool(false),       // rolinherit
+					parser.MakeDBool(true),        // rolinherit
 					parser.MakeDBool(isRoot),      // rolcreaterole
 					parser.MakeDBool(isRoot),      // rolcreatedb
 					parser.MakeDBool(false),       // rolcatupdate
-					parser.MakeDBool(true),        // rolcanlogin
+					parser.MakeDBool(canLogin),    // rolcanlogin
 					negOneVal,                     // rolconnlimit
 					parser.NewDString("********"), // rolpassword
 					parser.DNull,                  // rolvaliduntil
@@ -1319,7 +1375,7 @@ CREATE TABLE pg_catalog.pg_tables (
 `,
 	populate: func(ctx context.Context, p *planner, prefix string, addRow func(...parser.Datum) error) error {
 		return forEachTableDesc(ctx, p, prefix, func(db *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor) error {
-			if table.IsView() {
+			if !table.IsTable() {
 				return nil
 			}
 			return addRow(
@@ -1373,7 +1429,6 @@ var (
 	_ = typCategoryComposite
 	_ = typCategoryEnum
 	_ = typCategoryGeometric
-	_ = typCategoryNetworkAddr
 	_ = typCategoryPseudo
 	_ = typCategoryRange
 	_ = typCategoryBitString
@@ -1450,21 +1505,21 @@ CREATE TABLE pg_catalog.pg_type (
 				typByVal(typ),                  // typbyval
 				typTypeBase,                    // typtype
 				cat,                            // typcategory
-				parser.MakeDBool(false), // typispreferred
-				parser.MakeDBool(true),  // typisdefined
-				typDelim,                // typdelim
-				oidZero,                 // typrelid
-				typElem,                 // typelem
-				oidZero,                 // typarray
+				parser.MakeDBool(false),        // typispreferred
+				parser.MakeDBool(true),         // typisdefined
+				typDelim,                       // typdelim
+				oidZero,                        // typrelid
+				typElem,                        // typelem
+				oidZero,                        // typarray
 
 				// regproc references
 				h.RegProc(builtinPrefix+"in"),   // typinput
 				h.RegProc(builtinPrefix+"out"),  // typoutput
 				h.RegProc(builtinPrefix+"recv"), // typreceive
 				h.RegProc(builtinPrefix+"send"), // typsend
-				oidZero, // typmodin
-				oidZero, // typmodout
-				oidZero, // typanalyze
+				oidZero,                         // typmodin
+				oidZero,                         // typmodout
+				oidZero,                         // typanalyze
 
 				parser.DNull,            // typalign
 				parser.DNull,            // typstorage
@@ -1525,7 +1580,9 @@ var datumToTypeCategory = map[reflect.Type]*parser.DString{
 	reflect.TypeOf(parser.TypeDate):        typCategoryDateTime,
 	reflect.TypeOf(parser.TypeFloat):       typCategoryNumeric,
 	reflect.TypeOf(parser.TypeInt):         typCategoryNumeric,
+	reflect.TypeOf(parser.TypeINet):        typCategoryNetworkAddr,
 	reflect.TypeOf(parser.TypeInterval):    typCategoryTimespan,
+	reflect.TypeOf(parser.TypeJSON):        typCategoryUserDefined,
 	reflect.TypeOf(parser.TypeDecimal):     typCategoryNumeric,
 	reflect.TypeOf(parser.TypeString):      typCategoryString,
 	reflect.TypeOf(parser.TypeTimestamp):   typCategoryDateTime,
@@ -1587,16 +1644,15 @@ CREATE TABLE pg_catalog.pg_views (
 // are unique across all objects and that they are stable across accesses.
 //
 // The type has a few layers of methods:
-// - write<go_type> methods write concrete types to the underlying running hash.
-// - write<db_object> methods account for single database objects like TableDescriptors
-//   or IndexDescriptors in the running hash. These methods aim to write information
-//   that would uniquely fingerprint the object to the hash using the first layer of
-//   methods.
-// - <DB_Object>Oid methods use the second layer of methods to construct a unique
-//   object identifier for the provided database object. This object identifier will
-//   be returned as a *parser.DInt, and the running hash will be reset. These are the
-//   only methods that are part of the oidHasher's external facing interface.
-//
+//   - write<go_type> methods write concrete types to the underlying running hash.
+//   - write<db_object> methods account for single database objects like TableDescriptors
+//     or IndexDescriptors in the running hash. These methods aim to write information
+//     that would uniquely fingerprint the object to the hash using the first layer of
+//     methods.
+//   - <DB_Object>Oid methods use the second layer of methods to construct a unique
+//     object identifier for the provided database object. This object identifier will
+//     be returned as a *parser.DInt, and the running hash will be reset. These are the
+//     only methods that are part of the oidHasher's external facing interface.
 type oidHasher struct {
 	h hash.Hash32
 }
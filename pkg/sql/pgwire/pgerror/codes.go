@@ -88,12 +88,14 @@ const (
 	CodeNonstandardUseOfEscapeCharacterError       = "22P06"
 	CodeInvalidIndicatorParameterValueError        = "22010"
 	CodeInvalidParameterValueError                 = "22023"
+	CodeInvalidPrecedingOrFollowingSizeError       = "22013"
 	CodeInvalidRegularExpressionError              = "2201B"
 	CodeInvalidRowCountInLimitClauseError          = "2201W"
 	CodeInvalidRowCountInResultOffsetClauseError   = "2201X"
 	CodeInvalidTimeZoneDisplacementValueError      = "22009"
 	CodeInvalidUseOfEscapeCharacterError           = "2200C"
 	CodeMostSpecificTypeMismatchError              = "2200G"
+	CodeSequenceGeneratorLimitExceeded             = "2200H"
 	CodeNullValueNotAllowedError                   = "22004"
 	CodeNullValueNoIndicatorParameterError         = "22002"
 	CodeNumericValueOutOfRangeError                = "22003"
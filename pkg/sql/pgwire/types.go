@@ -20,6 +20,7 @@ import (
 	"encoding/hex"
 	"math"
 	"math/big"
+	"net"
 	"strconv"
 	"strings"
 	"time"
@@ -30,6 +31,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/lib/pq"
 	"github.com/lib/pq/oid"
@@ -71,6 +73,16 @@ const (
 // The number of decimal digits per int16 Postgres "digit".
 const pgDecDigits = 4
 
+// The version of the binary format of JSONB documents.
+const jsonbBinaryVersion = 1
+
+// The address families used by the binary format of INET and CIDR values.
+// These are PGSQL_AF_INET and PGSQL_AF_INET6 in Postgres' source.
+const (
+	pgAFINet  = 2
+	pgAFINet6 = 3
+)
+
 type pgNumeric struct {
 	ndigits, weight, dscale int16
 	sign                    pgNumericSign
@@ -138,6 +150,12 @@ func (b *writeBuffer) writeTextDatum(
 	case *parser.DUuid:
 		b.writeLengthPrefixedString(v.UUID.String())
 
+	case *parser.DIPAddr:
+		b.writeLengthPrefixedString(v.IPAddr.String())
+
+	case *parser.DJSON:
+		b.writeLengthPrefixedString(v.JSON.String())
+
 	case *parser.DString:
 		b.writeLengthPrefixedString(string(*v))
 
@@ -311,6 +329,30 @@ func (b *writeBuffer) writeBinaryDatum(
 		b.putInt32(16)
 		b.write(v.GetBytes())
 
+	case *parser.DIPAddr:
+		// The binary format of INET is the address family, the netmask length, a
+		// flag set for CIDR values, the number of address bytes and then the
+		// address itself.
+		addr := v.IP()
+		family := byte(pgAFINet)
+		if v.Family == ipaddr.IPv6family {
+			family = pgAFINet6
+		}
+		b.putInt32(int32(4 + len(addr)))
+		b.writeByte(family)
+		b.writeByte(v.Mask)
+		b.writeByte(0)
+		b.writeByte(byte(len(addr)))
+		b.write(addr)
+
+	case *parser.DJSON:
+		// The binary format of JSONB is a version number followed by the text
+		// representation of the document.
+		s := v.JSON.String()
+		b.putInt32(int32(1 + len(s)))
+		b.writeByte(jsonbBinaryVersion)
+		b.writeString(s)
+
 	case *parser.DString:
 		b.writeLengthPrefixedString(string(*v))
 
@@ -517,6 +559,16 @@ func decodeOidDatum(id oid.Oid, code formatCode, b []byte) (parser.Datum, error)
 				return nil, errors.Errorf("could not parse string %q as uuid", b)
 			}
 			return d, nil
+		case oid.T_inet, oid.T_cidr:
+			if err := validateStringBytes(b); err != nil {
+				return nil, err
+			}
+			return parser.ParseDIPAddrFromINetString(string(b))
+		case oid.T_jsonb:
+			if err := validateStringBytes(b); err != nil {
+				return nil, err
+			}
+			return parser.ParseDJSON(string(b))
 		case oid.T__int2, oid.T__int4, oid.T__int8:
 			var arr pq.Int64Array
 			if err := (&arr).Scan(b); err != nil {
@@ -697,6 +749,16 @@ func decodeOidDatum(id oid.Oid, code formatCode, b []byte) (parser.Datum, error)
 				return nil, err
 			}
 			return u, nil
+		case oid.T_inet, oid.T_cidr:
+			return decodeBinaryIPAddr(b)
+		case oid.T_jsonb:
+			if len(b) < 1 || b[0] != jsonbBinaryVersion {
+				return nil, errors.Errorf("unsupported jsonb binary format version")
+			}
+			if err := validateStringBytes(b[1:]); err != nil {
+				return nil, err
+			}
+			return parser.ParseDJSON(string(b[1:]))
 		case oid.T__int2, oid.T__int4, oid.T__int8, oid.T__text, oid.T__name:
 			return decodeBinaryArray(b, code)
 		}
@@ -732,6 +794,28 @@ func validateStringBytes(b []byte) error {
 	return nil
 }
 
+// decodeBinaryIPAddr decodes an INET or CIDR value in the binary format
+// written by writeBinaryDatum.
+func decodeBinaryIPAddr(b []byte) (parser.Datum, error) {
+	if len(b) < 4 {
+		return nil, errors.Errorf("inet requires at least 4 bytes for binary format")
+	}
+	family, mask, n := b[0], b[1], int(b[3])
+	addr := b[4:]
+	if len(addr) != n {
+		return nil, errors.Errorf("inet address length %d doesn't match %d bytes of data", n, len(addr))
+	}
+	if (family == pgAFINet && n != net.IPv4len) || (family == pgAFINet6 && n != net.IPv6len) ||
+		(family != pgAFINet && family != pgAFINet6) {
+		return nil, errors.Errorf("invalid inet address family %d with %d address bytes", family, n)
+	}
+	ipAddr, err := ipaddr.FromNetIP(net.IP(addr), mask)
+	if err != nil {
+		return nil, err
+	}
+	return parser.NewDIPAddr(parser.DIPAddr{IPAddr: ipAddr}), nil
+}
+
 func decodeBinaryArray(b []byte, code formatCode) (parser.Datum, error) {
 	hdr := struct {
 		Ndims int32
@@ -138,11 +138,15 @@ type planNodeFastPath interface {
 }
 
 var _ planNode = &alterTableNode{}
+var _ planNode = &analyzeNode{}
+var _ planNode = &alterSequenceNode{}
 var _ planNode = &copyNode{}
 var _ planNode = &createDatabaseNode{}
 var _ planNode = &createIndexNode{}
 var _ planNode = &createTableNode{}
 var _ planNode = &createViewNode{}
+var _ planNode = &createSequenceNode{}
+var _ planNode = &createStatsNode{}
 var _ planNode = &delayedNode{}
 var _ planNode = &deleteNode{}
 var _ planNode = &distinctNode{}
@@ -150,6 +154,7 @@ var _ planNode = &dropDatabaseNode{}
 var _ planNode = &dropIndexNode{}
 var _ planNode = &dropTableNode{}
 var _ planNode = &dropViewNode{}
+var _ planNode = &dropSequenceNode{}
 var _ planNode = &zeroNode{}
 var _ planNode = &unaryNode{}
 var _ planNode = &explainDistSQLNode{}
@@ -163,6 +168,8 @@ var _ planNode = &insertNode{}
 var _ planNode = &joinNode{}
 var _ planNode = &limitNode{}
 var _ planNode = &ordinalityNode{}
+var _ planNode = &recursiveCTENode{}
+var _ planNode = &refreshMaterializedViewNode{}
 var _ planNode = &testingRelocateNode{}
 var _ planNode = &renderNode{}
 var _ planNode = &scanNode{}
@@ -170,6 +177,7 @@ var _ planNode = &scatterNode{}
 var _ planNode = &showRangesNode{}
 var _ planNode = &showFingerprintsNode{}
 var _ planNode = &sortNode{}
+var _ planNode = &spoolNode{}
 var _ planNode = &splitNode{}
 var _ planNode = &unionNode{}
 var _ planNode = &updateNode{}
@@ -178,7 +186,14 @@ var _ planNode = &valuesNode{}
 var _ planNode = &windowNode{}
 var _ planNode = &createUserNode{}
 var _ planNode = &dropUserNode{}
-
+var _ planNode = &grantRoleNode{}
+var _ planNode = &revokeRoleNode{}
+var _ planNode = &createFunctionNode{}
+var _ planNode = &dropFunctionNode{}
+var _ planNode = &createTriggerNode{}
+var _ planNode = &dropTriggerNode{}
+
+var _ planNodeFastPath = &analyzeNode{}
 var _ planNodeFastPath = &deleteNode{}
 var _ planNodeFastPath = &dropUserNode{}
 
@@ -326,6 +341,8 @@ func (p *planner) newPlan(
 	switch n := stmt.(type) {
 	case *parser.AlterTable:
 		return p.AlterTable(ctx, n)
+	case *parser.AlterSequence:
+		return p.AlterSequence(ctx, n)
 	case *parser.BeginTransaction:
 		return p.BeginTransaction(n)
 	case *parser.CancelQuery:
@@ -338,14 +355,24 @@ func (p *planner) newPlan(
 		return p.CopyFrom(ctx, n)
 	case *parser.CreateDatabase:
 		return p.CreateDatabase(n)
+	case *parser.CreateFunction:
+		return p.CreateFunction(ctx, n)
 	case *parser.CreateIndex:
 		return p.CreateIndex(ctx, n)
+	case *parser.CreateRole:
+		return p.CreateRole(ctx, n)
 	case *parser.CreateTable:
 		return p.CreateTable(ctx, n)
+	case *parser.CreateTrigger:
+		return p.CreateTrigger(ctx, n)
 	case *parser.CreateUser:
 		return p.CreateUser(ctx, n)
 	case *parser.CreateView:
 		return p.CreateView(ctx, n)
+	case *parser.CreateSequence:
+		return p.CreateSequence(ctx, n)
+	case *parser.CreateStats:
+		return p.CreateStatistics(ctx, n)
 	case *parser.Deallocate:
 		return p.Deallocate(ctx, n)
 	case *parser.Delete:
@@ -354,12 +381,20 @@ func (p *planner) newPlan(
 		return p.Discard(ctx, n)
 	case *parser.DropDatabase:
 		return p.DropDatabase(ctx, n)
+	case *parser.DropFunction:
+		return p.DropFunction(ctx, n)
 	case *parser.DropIndex:
 		return p.DropIndex(ctx, n)
+	case *parser.DropRole:
+		return p.DropRole(ctx, n)
 	case *parser.DropTable:
 		return p.DropTable(ctx, n)
+	case *parser.DropTrigger:
+		return p.DropTrigger(ctx, n)
 	case *parser.DropView:
 		return p.DropView(ctx, n)
+	case *parser.DropSequence:
+		return p.DropSequence(ctx, n)
 	case *parser.DropUser:
 		return p.DropUser(ctx, n)
 	case *parser.Execute:
@@ -368,6 +403,8 @@ func (p *planner) newPlan(
 		return p.Explain(ctx, n)
 	case *parser.Grant:
 		return p.Grant(ctx, n)
+	case *parser.GrantRole:
+		return p.GrantRole(ctx, n)
 	case *parser.Insert:
 		return p.Insert(ctx, n, desiredTypes)
 	case *parser.ParenSelect:
@@ -376,6 +413,11 @@ func (p *planner) newPlan(
 		return p.PauseJob(ctx, n)
 	case *parser.TestingRelocate:
 		return p.TestingRelocate(ctx, n)
+	case *parser.RefreshMaterializedView:
+		if err := p.txn.SetSystemConfigTrigger(); err != nil {
+			return nil, err
+		}
+		return p.RefreshMaterializedView(ctx, n)
 	case *parser.RenameColumn:
 		return p.RenameColumn(ctx, n)
 	case *parser.RenameDatabase:
@@ -388,6 +430,8 @@ func (p *planner) newPlan(
 		return p.ResumeJob(ctx, n)
 	case *parser.Revoke:
 		return p.Revoke(ctx, n)
+	case *parser.RevokeRole:
+		return p.RevokeRole(ctx, n)
 	case *parser.Scatter:
 		return p.Scatter(ctx, n)
 	case *parser.Select:
@@ -414,6 +458,8 @@ func (p *planner) newPlan(
 		return p.ShowCreateTable(ctx, n)
 	case *parser.ShowCreateView:
 		return p.ShowCreateView(ctx, n)
+	case *parser.ShowCreateSequence:
+		return p.ShowCreateSequence(ctx, n)
 	case *parser.ShowDatabases:
 		return p.ShowDatabases(ctx, n)
 	case *parser.ShowGrants:
@@ -422,12 +468,16 @@ func (p *planner) newPlan(
 		return p.ShowIndex(ctx, n)
 	case *parser.ShowQueries:
 		return p.ShowQueries(ctx, n)
+	case *parser.ShowRoles:
+		return p.ShowRoles(ctx, n)
 	case *parser.ShowJobs:
 		return p.ShowJobs(ctx, n)
 	case *parser.ShowSessions:
 		return p.ShowSessions(ctx, n)
 	case *parser.ShowTables:
 		return p.ShowTables(ctx, n)
+	case *parser.ShowTableStats:
+		return p.ShowTableStats(ctx, n)
 	case *parser.ShowTrace:
 		return p.ShowTrace(ctx, n)
 	case *parser.ShowTransactionStatus:
@@ -496,6 +546,8 @@ func (p *planner) prepare(ctx context.Context, stmt parser.Statement) (planNode,
 		return p.ShowCreateTable(ctx, n)
 	case *parser.ShowCreateView:
 		return p.ShowCreateView(ctx, n)
+	case *parser.ShowCreateSequence:
+		return p.ShowCreateSequence(ctx, n)
 	case *parser.ShowColumns:
 		return p.ShowColumns(ctx, n)
 	case *parser.ShowDatabases:
@@ -508,12 +560,16 @@ func (p *planner) prepare(ctx context.Context, stmt parser.Statement) (planNode,
 		return p.ShowConstraints(ctx, n)
 	case *parser.ShowQueries:
 		return p.ShowQueries(ctx, n)
+	case *parser.ShowRoles:
+		return p.ShowRoles(ctx, n)
 	case *parser.ShowJobs:
 		return p.ShowJobs(ctx, n)
 	case *parser.ShowSessions:
 		return p.ShowSessions(ctx, n)
 	case *parser.ShowTables:
 		return p.ShowTables(ctx, n)
+	case *parser.ShowTableStats:
+		return p.ShowTableStats(ctx, n)
 	case *parser.ShowTrace:
 		return p.ShowTrace(ctx, n)
 	case *parser.ShowUsers:
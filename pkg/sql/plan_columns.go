@@ -61,6 +61,8 @@ func getPlanColumns(plan planNode, mut bool) sqlbase.ResultColumns {
 		return n.columns
 	case *ordinalityNode:
 		return n.columns
+	case *recursiveCTENode:
+		return n.columns
 	case *renderNode:
 		return n.columns
 	case *scanNode:
@@ -73,6 +75,8 @@ func getPlanColumns(plan planNode, mut bool) sqlbase.ResultColumns {
 		return n.columns
 	case *explainPlanNode:
 		return n.results.columns
+	case *analyzeNode:
+		return getPlanColumns(n.plan, mut)
 	case *windowNode:
 		return n.values.columns
 	case *traceNode:
@@ -110,6 +114,8 @@ func getPlanColumns(plan planNode, mut bool) sqlbase.ResultColumns {
 		return getPlanColumns(n.table, mut)
 	case *limitNode:
 		return getPlanColumns(n.plan, mut)
+	case *spoolNode:
+		return getPlanColumns(n.source, mut)
 	case *unionNode:
 		return getPlanColumns(n.left, mut)
 
@@ -0,0 +1,523 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"math"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/stats"
+)
+
+// This file contains the cost model used to choose between alternative
+// plans: which index to scan, in which order to join tables and which join
+// algorithm to use. The costs are expressed in arbitrary units, relative to
+// the cost of reading one row sequentially from the KV layer.
+//
+// The estimates are based on the table statistics collected by CREATE
+// STATISTICS (see the stats package). When a table has no statistics, default
+// assumptions are used; the plans that depend on the estimates only change
+// when the estimates of all the tables involved are derived from statistics.
+
+const (
+	// seqRowCost is the cost of reading one row during a scan.
+	seqRowCost = 1.0
+	// randomRowCost is the cost of looking up one row by key, as done by index
+	// joins and lookup joins.
+	randomRowCost = 4.0
+	// cpuRowCost is the cost of processing one row in memory.
+	cpuRowCost = 0.01
+	// hashRowCost is the cost of adding one row to the hash table of a hash
+	// join.
+	hashRowCost = 0.02
+
+	// defaultRowCount is the number of rows assumed for a table without
+	// statistics.
+	defaultRowCount = 1000
+	// defaultDistinctCount is the number of distinct values assumed for a
+	// column without statistics.
+	defaultDistinctCount = 100
+	// defaultSelectivity is the fraction of rows assumed to pass a filter or a
+	// range constraint whose selectivity cannot be estimated otherwise.
+	defaultSelectivity = 1.0 / 3
+)
+
+// planEstimate is the estimated cardinality and cost of a planNode.
+type planEstimate struct {
+	// rowCount is the estimated number of rows produced by the node.
+	rowCount float64
+	// cost is the estimated cost of running the node, including its sources.
+	cost float64
+	// fromStats is set if the estimate is derived from table statistics for
+	// all the tables involved, as opposed to default assumptions.
+	fromStats bool
+}
+
+// estimatePlan computes the estimated cardinality and cost of an expanded
+// plan.
+func (p *planner) estimatePlan(ctx context.Context, plan planNode) planEstimate {
+	switch n := plan.(type) {
+	case *scanNode:
+		if n.estimate == (planEstimate{}) {
+			// The scan was not planned by selectIndex (e.g. it scans an inverted
+			// index).
+			return planEstimate{rowCount: defaultRowCount, cost: defaultRowCount * seqRowCost}
+		}
+		return n.estimate
+
+	case *indexJoinNode:
+		est := p.estimatePlan(ctx, n.index)
+		est.cost += est.rowCount * randomRowCost
+		if n.table.filter != nil {
+			est.rowCount *= defaultSelectivity
+		}
+		return est
+
+	case *joinNode:
+		if n.estimate == (planEstimate{}) {
+			n.estimate = p.estimateJoin(ctx, n, n.algorithm)
+		}
+		return n.estimate
+
+	case *filterNode:
+		est := p.estimatePlan(ctx, n.source.plan)
+		est.cost += est.rowCount * cpuRowCost
+		est.rowCount *= defaultSelectivity
+		return est
+
+	case *renderNode:
+		est := p.estimatePlan(ctx, n.source.plan)
+		est.cost += est.rowCount * cpuRowCost
+		return est
+
+	case *limitNode:
+		est := p.estimatePlan(ctx, n.plan)
+		if n.count > 0 && n.count < math.MaxInt64 {
+			est.rowCount = math.Min(est.rowCount, float64(n.count))
+		}
+		return est
+
+	case *sortNode:
+		est := p.estimatePlan(ctx, n.plan)
+		if n.needSort && est.rowCount > 1 {
+			est.cost += est.rowCount * math.Log2(est.rowCount) * cpuRowCost
+		}
+		return est
+
+	case *groupNode:
+		est := p.estimatePlan(ctx, n.plan)
+		est.cost += est.rowCount * hashRowCost
+		if n.numGroupCols == 0 {
+			est.rowCount = 1
+		} else {
+			est.rowCount = math.Min(est.rowCount, defaultDistinctCount)
+		}
+		return est
+
+	case *distinctNode:
+		est := p.estimatePlan(ctx, n.plan)
+		est.cost += est.rowCount * hashRowCost
+		return est
+
+	case *ordinalityNode:
+		return p.estimatePlan(ctx, n.source)
+
+	case *windowNode:
+		est := p.estimatePlan(ctx, n.plan)
+		est.cost += est.rowCount * hashRowCost
+		return est
+
+	case *valuesNode:
+		numRows := len(n.tuples)
+		if n.rows != nil {
+			numRows = n.rows.Len()
+		}
+		return planEstimate{
+			rowCount:  float64(numRows),
+			cost:      float64(numRows) * cpuRowCost,
+			fromStats: true,
+		}
+
+	case *zeroNode:
+		return planEstimate{fromStats: true}
+
+	case *unaryNode:
+		return planEstimate{rowCount: 1, fromStats: true}
+	}
+	return planEstimate{rowCount: defaultRowCount}
+}
+
+// estimateIndexScan computes the estimate of a scan of the given index of the
+// scanNode's table, restricted by the given constraints and followed by the
+// scanNode's filter.
+func (p *planner) estimateIndexScan(
+	s *scanNode,
+	index *sqlbase.IndexDescriptor,
+	constraints orIndexConstraints,
+	tableStats []*stats.TableStatistic,
+) planEstimate {
+	est := planEstimate{rowCount: defaultRowCount}
+	if len(tableStats) > 0 {
+		est = planEstimate{rowCount: float64(tableStats[0].RowCount), fromStats: true}
+	}
+	est.rowCount *= constraintsSelectivity(&p.evalCtx, s.desc, index, constraints, tableStats)
+	est.cost = est.rowCount * seqRowCost
+	if s.filter != nil {
+		est.cost += est.rowCount * cpuRowCost
+		est.rowCount *= defaultSelectivity
+	}
+	return est
+}
+
+// constraintsSelectivity estimates the fraction of the rows of a table which
+// satisfy the constraints on an index. The selectivities of the disjunctions
+// are added up.
+func constraintsSelectivity(
+	evalCtx *parser.EvalContext,
+	desc *sqlbase.TableDescriptor,
+	index *sqlbase.IndexDescriptor,
+	constraints orIndexConstraints,
+	tableStats []*stats.TableStatistic,
+) float64 {
+	if len(constraints) == 0 {
+		return 1
+	}
+	var sel float64
+	for _, ic := range constraints {
+		sel += indexConstraintsSelectivity(evalCtx, desc, index, ic, tableStats)
+	}
+	return math.Min(sel, 1)
+}
+
+// indexConstraintsSelectivity estimates the fraction of the rows of a table
+// which satisfy a conjunction of constraints on the columns of an index.
+// Exact constraints on a column select one in as many rows as the column has
+// distinct values; the first range constraint is estimated using the
+// column's histogram and ends the constrained prefix of the index.
+func indexConstraintsSelectivity(
+	evalCtx *parser.EvalContext,
+	desc *sqlbase.TableDescriptor,
+	index *sqlbase.IndexDescriptor,
+	ic indexConstraints,
+	tableStats []*stats.TableStatistic,
+) float64 {
+	sel := 1.0
+	colIdx := 0
+	for _, c := range ic {
+		if c.start != nil && c.start == c.end &&
+			(c.start.Operator == parser.EQ || c.start.Operator == parser.In) {
+			numValues := 1
+			if c.start.Operator == parser.In {
+				if t, ok := c.start.Right.(*parser.DTuple); ok {
+					numValues = len(t.D)
+				}
+			}
+			for i := 0; i < c.numColumns(); i++ {
+				sel /= columnDistinctCount(index.ColumnIDs[colIdx+i], tableStats)
+			}
+			sel *= float64(numValues)
+			colIdx += c.numColumns()
+			continue
+		}
+		if c.tupleMap != nil {
+			sel *= defaultSelectivity
+		} else {
+			sel *= rangeSelectivity(evalCtx, desc, index.ColumnIDs[colIdx], c, tableStats)
+		}
+		break
+	}
+	return math.Min(sel, 1)
+}
+
+// rangeSelectivity estimates the fraction of the rows of a table which
+// satisfy a range constraint on a column.
+func rangeSelectivity(
+	evalCtx *parser.EvalContext,
+	desc *sqlbase.TableDescriptor,
+	colID sqlbase.ColumnID,
+	c indexConstraint,
+	tableStats []*stats.TableStatistic,
+) float64 {
+	stat := latestColumnStatistic(colID, tableStats)
+	if stat == nil || stat.RowCount == 0 {
+		return defaultSelectivity
+	}
+	nonNull := float64(stat.RowCount-stat.NullCount) / float64(stat.RowCount)
+
+	var lower, upper parser.Datum
+	if c.start != nil {
+		switch c.start.Operator {
+		case parser.GT, parser.GE:
+			lower, _ = c.start.Right.(parser.Datum)
+		case parser.IsNot:
+		default:
+			return defaultSelectivity
+		}
+	}
+	if c.end != nil {
+		switch c.end.Operator {
+		case parser.LT, parser.LE:
+			upper, _ = c.end.Right.(parser.Datum)
+		case parser.IsNot:
+		default:
+			return defaultSelectivity
+		}
+	}
+	if lower == nil && upper == nil {
+		// The constraint only excludes NULLs.
+		return nonNull
+	}
+	if stat.Histogram == nil {
+		return defaultSelectivity
+	}
+	col, err := desc.FindColumnByID(colID)
+	if err != nil {
+		return defaultSelectivity
+	}
+	frac, err := stat.Histogram.RangeFraction(evalCtx, col.Type.ToDatumType(), lower, upper)
+	if err != nil {
+		return defaultSelectivity
+	}
+	return frac * nonNull
+}
+
+// latestColumnStatistic returns the most recent single-column statistic on
+// the given column, or nil if there is none.
+func latestColumnStatistic(
+	colID sqlbase.ColumnID, tableStats []*stats.TableStatistic,
+) *stats.TableStatistic {
+	// The statistics are ordered by creation time, most recent first.
+	for _, s := range tableStats {
+		if len(s.ColumnIDs) == 1 && s.ColumnIDs[0] == colID {
+			return s
+		}
+	}
+	return nil
+}
+
+// columnDistinctCount returns the estimated number of distinct values of a
+// column.
+func columnDistinctCount(colID sqlbase.ColumnID, tableStats []*stats.TableStatistic) float64 {
+	if s := latestColumnStatistic(colID, tableStats); s != nil {
+		return math.Max(float64(s.DistinctCount), 1)
+	}
+	return defaultDistinctCount
+}
+
+// estimateColumnDistinct estimates the number of distinct values of a column
+// of the results of an expanded plan which produces rowCount rows.
+func (p *planner) estimateColumnDistinct(
+	ctx context.Context, plan planNode, col int, rowCount float64,
+) float64 {
+	distinct := float64(defaultDistinctCount)
+	if colID, desc, ok := sourceTableColumn(plan, col); ok {
+		distinct = columnDistinctCount(colID, p.getTableStatistics(ctx, desc))
+	}
+	return math.Max(math.Min(distinct, rowCount), 1)
+}
+
+// sourceTableColumn traces a column of the results of a plan back to the
+// table column it originates from, if any.
+func sourceTableColumn(
+	plan planNode, col int,
+) (sqlbase.ColumnID, *sqlbase.TableDescriptor, bool) {
+	switch n := plan.(type) {
+	case *scanNode:
+		return n.cols[col].ID, n.desc, true
+	case *indexJoinNode:
+		return sourceTableColumn(n.index, col)
+	case *filterNode:
+		return sourceTableColumn(n.source.plan, col)
+	case *renderNode:
+		if iv, ok := n.render[col].(*parser.IndexedVar); ok {
+			return sourceTableColumn(n.source.plan, iv.Idx)
+		}
+	case *joinNode:
+		if col < n.pred.numMergedEqualityColumns {
+			return sourceTableColumn(n.left.plan, n.pred.leftEqualityIndices[col])
+		}
+		col -= n.pred.numMergedEqualityColumns
+		if col < n.pred.numLeftCols {
+			return sourceTableColumn(n.left.plan, col)
+		}
+		return sourceTableColumn(n.right.plan, col-n.pred.numLeftCols)
+	}
+	return 0, nil, false
+}
+
+// joinAlgorithm identifies the algorithm used to run a join.
+type joinAlgorithm int
+
+const (
+	// hashJoin builds a hash table with the rows of the right side and probes
+	// it with the rows of the left side.
+	hashJoin joinAlgorithm = iota
+	// mergeJoin merges the rows of the two sides, which are ordered on the
+	// equality columns. It is only run by DistSQL.
+	mergeJoin
+	// lookupJoin looks up the rows of the right side which match the rows of
+	// the left side in an index of the right table, in batches of rows of the
+	// left side.
+	lookupJoin
+)
+
+// estimateJoin computes the estimate of a join whose sources are expanded,
+// when run with the given algorithm.
+func (p *planner) estimateJoin(
+	ctx context.Context, n *joinNode, algorithm joinAlgorithm,
+) planEstimate {
+	left := p.estimatePlan(ctx, n.left.plan)
+	right := p.estimatePlan(ctx, n.right.plan)
+	if n.lateral != nil {
+		// The right side is run for every row of the left side.
+		right.cost *= math.Max(left.rowCount, 1)
+	}
+
+	sel := 1.0
+	for i := range n.pred.leftEqualityIndices {
+		sel /= math.Max(
+			p.estimateColumnDistinct(ctx, n.left.plan, n.pred.leftEqualityIndices[i], left.rowCount),
+			p.estimateColumnDistinct(ctx, n.right.plan, n.pred.rightEqualityIndices[i], right.rowCount),
+		)
+	}
+	if n.pred.onCond != nil {
+		sel *= defaultSelectivity
+	}
+	est := joinCost(left, right, sel, algorithm)
+	switch n.joinType {
+	case joinTypeLeftOuter:
+		est.rowCount = math.Max(est.rowCount, left.rowCount)
+	case joinTypeRightOuter:
+		est.rowCount = math.Max(est.rowCount, right.rowCount)
+	case joinTypeFullOuter:
+		est.rowCount = math.Max(est.rowCount, left.rowCount+right.rowCount)
+	}
+	return est
+}
+
+// joinCost computes the estimate of an inner join between sources with the
+// given estimates, for a join predicate with the given selectivity.
+func joinCost(left, right planEstimate, sel float64, algorithm joinAlgorithm) planEstimate {
+	est := planEstimate{
+		rowCount:  left.rowCount * right.rowCount * sel,
+		fromStats: left.fromStats && right.fromStats,
+	}
+	switch algorithm {
+	case hashJoin:
+		est.cost = left.cost + right.cost + right.rowCount*hashRowCost + left.rowCount*cpuRowCost
+	case mergeJoin:
+		est.cost = left.cost + right.cost + (left.rowCount+right.rowCount)*cpuRowCost
+	case lookupJoin:
+		// The right side is not scanned; instead, the matching rows are looked
+		// up for each row of the left side.
+		est.cost = left.cost + left.rowCount*randomRowCost + est.rowCount*seqRowCost
+	}
+	est.cost += est.rowCount * cpuRowCost
+	return est
+}
+
+// chooseJoinAlgorithm selects the cheapest algorithm for a join whose sources
+// are expanded, and records it along with the estimate of the join.
+//
+// A merge join is used whenever the sources are ordered on all the equality
+// columns, and a lookup join is only considered when the estimates of both
+// sources are derived from table statistics.
+func (p *planner) chooseJoinAlgorithm(ctx context.Context, n *joinNode) {
+	n.algorithm = hashJoin
+	n.estimate = p.estimateJoin(ctx, n, hashJoin)
+	if n.lateral != nil {
+		// Lateral joins are always run as hash joins, see runLateral.
+		return
+	}
+
+	numEq := len(n.pred.leftEqualityIndices)
+	if n.joinType == joinTypeInner && numEq > 0 && len(n.mergeJoinOrdering) == numEq {
+		if est := p.estimateJoin(ctx, n, mergeJoin); est.cost <= n.estimate.cost {
+			n.algorithm, n.estimate = mergeJoin, est
+		}
+	}
+	if n.estimate.fromStats && n.lookupIndex() != nil {
+		if est := p.estimateJoin(ctx, n, lookupJoin); est.cost < n.estimate.cost {
+			n.algorithm, n.estimate = lookupJoin, est
+			// The rows of the left side are looked up in batches, and the rows
+			// of a batch are not necessarily produced in the order of the left
+			// side when the join is distributed.
+			n.ordering = orderingInfo{}
+		}
+	}
+}
+
+// lookupIndex returns the index of the right table which can be used to look
+// up the rows matching a left row in a lookup join, or nil if the join cannot
+// be run as a lookup join.
+func (n *joinNode) lookupIndex() *sqlbase.IndexDescriptor {
+	if n.joinType != joinTypeInner {
+		return nil
+	}
+	leftCols := planColumns(n.left.plan)
+	leftTypes := make([]parser.Type, len(n.pred.leftEqualityIndices))
+	for i, colIdx := range n.pred.leftEqualityIndices {
+		leftTypes[i] = leftCols[colIdx].Typ
+	}
+	return lookupJoinIndex(n.right.plan, n.pred.rightEqualityIndices, leftTypes)
+}
+
+// lookupJoinIndex returns the index which can be used to look up the rows of
+// the right side of an inner join which match a row of the left side, given
+// the equality columns on the right side and the types of the corresponding
+// columns on the left side. This is possible when the right side is a scan
+// without limits of an index whose leading columns are exactly the right
+// equality columns; the spans of the lookups are then intersected with the
+// spans of the scan. It returns nil if there is no such index.
+func lookupJoinIndex(
+	right planNode, rightEqCols []int, leftEqTypes []parser.Type,
+) *sqlbase.IndexDescriptor {
+	numEq := len(rightEqCols)
+	if numEq == 0 {
+		return nil
+	}
+	scan, ok := right.(*scanNode)
+	if !ok || scan.hardLimit != 0 || scan.softLimit != 0 {
+		return nil
+	}
+	index := scan.index
+	if len(index.ColumnIDs) < numEq || len(index.Interleave.Ancestors) > 0 {
+		return nil
+	}
+	for _, colID := range index.ColumnIDs[:numEq] {
+		i := lookupEqualityIdx(scan, rightEqCols, colID)
+		if i < 0 {
+			return nil
+		}
+		if !leftEqTypes[i].Equivalent(scan.cols[rightEqCols[i]].Type.ToDatumType()) {
+			return nil
+		}
+	}
+	return index
+}
+
+// lookupEqualityIdx returns the position of the equality column which refers
+// to the given column of the scanned table, or -1 if there is none.
+func lookupEqualityIdx(scan *scanNode, eqCols []int, colID sqlbase.ColumnID) int {
+	for i, colIdx := range eqCols {
+		if scan.cols[colIdx].ID == colID {
+			return i
+		}
+	}
+	return -1
+}
@@ -26,6 +26,8 @@ func planOrdering(plan planNode) orderingInfo {
 	switch n := plan.(type) {
 	case *explainPlanNode:
 		return planOrdering(n.results)
+	case *analyzeNode:
+		return planOrdering(n.plan)
 	case *distinctNode:
 		return planOrdering(n.plan)
 	case *filterNode:
@@ -87,6 +87,10 @@ func collectSpans(params runParams, plan planNode) (reads, writes roachpb.Spans,
 		return concatSpans(params, n.left.plan, n.right.plan)
 	case *unionNode:
 		return concatSpans(params, n.left, n.right)
+	case *recursiveCTENode:
+		return concatSpans(params, n.initial, n.recursive)
+	case *spoolNode:
+		return collectSpans(params, n.source)
 	}
 
 	panic(fmt.Sprintf("don't know how to collect spans for node %T", plan))
@@ -124,8 +128,12 @@ func tableWriterSpans(params runParams, tw tableWriter) (reads, writes roachpb.S
 	// We don't generally know which spans we will be modifying so we must be
 	// conservative and assume anything in the table might change.
 	tableSpans := tw.tableDesc().AllIndexSpans()
-	fkReads := tw.fkSpanCollector().CollectSpans()
-	return fkReads, tableSpans
+	fks := tw.fkSpanCollector()
+	fkReads := fks.CollectSpans()
+	// The referential actions of the foreign keys may read and modify other
+	// tables.
+	cascadeSpans := fks.CollectCascadeSpans()
+	return append(fkReads, cascadeSpans...), append(tableSpans, cascadeSpans...)
 }
 
 // insertNodeWithValuesSpans is a special case of editNodeSpans. It tightens the
@@ -98,6 +98,25 @@ type planner struct {
 	// occurred during logical plan construction.
 	hasSubqueries bool
 
+	// cteNameEnvironment contains the common table expressions that
+	// are visible at the current point of logical plan construction.
+	// See with.go.
+	cteNameEnvironment *cteNameEnvironment
+	// lateralScopes contains the data sources whose columns can be
+	// referred to by the LATERAL data source that is currently being
+	// planned, innermost last. See lateral.go.
+	lateralScopes []*lateralScope
+	// subqueryDepth is the number of sub-queries in scalar expressions
+	// that are currently being planned.
+	subqueryDepth int
+	// functionCallDepth is the number of calls to user-defined functions,
+	// including triggers, that are being evaluated by the planners that
+	// created this one. See functions.go.
+	functionCallDepth int
+	// functionBodies caches the bodies of the user-defined functions called
+	// by the statement. See prepareFunctionBody.
+	functionBodies map[string]*functionBody
+
 	// Avoid allocations by embedding commonly used objects and visitors.
 	parser                parser.Parser
 	subqueryVisitor       subqueryVisitor
@@ -298,8 +317,17 @@ func (p *planner) exec(ctx context.Context, sql string, args ...interface{}) (in
 	return countRowsAffected(params, plan)
 }
 
+// fillFKTableMap looks up the descriptors of the tables in m. The tables
+// needed to carry out the referential actions that modify the tables in m
+// are added to m and looked up as well.
 func (p *planner) fillFKTableMap(ctx context.Context, m sqlbase.TableLookupsByID) error {
+	queue := make([]sqlbase.ID, 0, len(m))
 	for tableID := range m {
+		queue = append(queue, tableID)
+	}
+	for len(queue) > 0 {
+		tableID := queue[0]
+		queue = queue[1:]
 		table, err := p.session.tables.getTableVersionByID(ctx, p.txn, tableID)
 		if err == errTableAdding {
 			m[tableID] = sqlbase.TableLookup{IsAdding: true}
@@ -309,6 +337,12 @@ func (p *planner) fillFKTableMap(ctx context.Context, m sqlbase.TableLookupsByID
 			return err
 		}
 		m[tableID] = sqlbase.TableLookup{Table: table}
+		for id := range sqlbase.TablesNeededForCascades(*table) {
+			if _, ok := m[id]; !ok {
+				m[id] = sqlbase.TableLookup{}
+				queue = append(queue, id)
+			}
+		}
 	}
 	return nil
 }
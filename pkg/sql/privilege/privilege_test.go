@@ -18,6 +18,7 @@ import (
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 )
 
@@ -60,3 +61,32 @@ func TestPrivilegeDecode(t *testing.T) {
 		}
 	}
 }
+
+func TestPrivilegeListFromStrings(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	testCases := []struct {
+		strs       []string
+		privileges privilege.List
+		err        string
+	}{
+		{[]string{}, privilege.List{}, ""},
+		{[]string{"all"}, privilege.List{privilege.ALL}, ""},
+		{[]string{"Select", "INSERT"}, privilege.List{privilege.SELECT, privilege.INSERT}, ""},
+		{[]string{"select", "foo"}, nil, `not a valid privilege: "foo"`},
+	}
+
+	for _, tc := range testCases {
+		pl, err := privilege.ListFromStrings(tc.strs)
+		if !testutils.IsError(err, tc.err) {
+			t.Fatalf("%v: expected error %q, got %v", tc.strs, tc.err, err)
+		}
+		if len(pl) != len(tc.privileges) {
+			t.Fatalf("%v: wrong privilege list: %+v", tc.strs, pl)
+		}
+		for i := range pl {
+			if pl[i] != tc.privileges[i] {
+				t.Fatalf("%v: wrong privilege list: %+v", tc.strs, pl)
+			}
+		}
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// recursiveCTENode executes a recursive common table expression of
+// the form "initial UNION [ALL] recursive", following the algorithm
+// described in the PostgreSQL documentation:
+//
+// - the rows of the initial term are emitted and stored in the
+//   working table;
+// - while the working table is not empty, the recursive term is
+//   evaluated with the working table as the value of the
+//   self-reference; its rows are emitted and stored in the next
+//   working table, which then replaces the working table.
+//
+// For UNION (without ALL), rows that were already emitted are
+// discarded and are not added to the working table.
+type recursiveCTENode struct {
+	name    string
+	columns sqlbase.ResultColumns
+
+	// initial is the plan for the non-recursive term.
+	initial planNode
+	// recursive is the plan for the first evaluation of the recursive
+	// term; it is also used to describe the recursive term in EXPLAIN.
+	recursive planNode
+	// workTableRef is the valuesNode in recursive that refers to the
+	// working table.
+	workTableRef *valuesNode
+	// genIteration plans the recursive term anew for the subsequent
+	// iterations. It returns the plan and its reference to the working
+	// table.
+	genIteration func(ctx context.Context) (planNode, *valuesNode, error)
+
+	unionAll bool
+
+	run recursiveCTERun
+}
+
+// recursiveCTERun contains the run-time state of recursiveCTENode
+// during local execution.
+type recursiveCTERun struct {
+	// workTable contains the rows produced by the previous iteration.
+	workTable *sqlbase.RowContainer
+	// nextWorkTable accumulates the rows produced by the current
+	// iteration.
+	nextWorkTable *sqlbase.RowContainer
+
+	// cur is the plan producing rows for the current iteration, and
+	// curRef its reference to the working table.
+	cur    planNode
+	curRef *valuesNode
+	// iterations is the number of evaluations of the recursive term so
+	// far.
+	iterations int
+
+	// seen contains the encoding of the rows emitted so far, when
+	// duplicates must be eliminated.
+	seen    map[string]struct{}
+	seenAcc WrappableMemoryAccount
+	scratch []byte
+}
+
+func (n *recursiveCTENode) Start(params runParams) error {
+	p := params.p
+	n.run.nextWorkTable = sqlbase.NewRowContainer(
+		p.session.TxnState.makeBoundAccount(), sqlbase.ColTypeInfoFromResCols(n.columns), 0,
+	)
+	if !n.unionAll {
+		n.run.seen = make(map[string]struct{})
+		n.run.seenAcc = p.session.TxnState.OpenAccount()
+	}
+	n.run.cur = n.initial
+	return n.initial.Start(params)
+}
+
+func (n *recursiveCTENode) Values() parser.Datums {
+	return n.run.cur.Values()
+}
+
+func (n *recursiveCTENode) Next(params runParams) (bool, error) {
+	for {
+		if err := params.p.cancelChecker.Check(); err != nil {
+			return false, err
+		}
+		if n.run.cur == nil {
+			return false, nil
+		}
+
+		next, err := n.run.cur.Next(params)
+		if err != nil {
+			return false, err
+		}
+		if !next {
+			if err := n.nextIteration(params); err != nil {
+				return false, err
+			}
+			continue
+		}
+
+		values := n.run.cur.Values()
+		if !n.unionAll {
+			n.run.scratch, err = sqlbase.EncodeDatums(n.run.scratch[:0], values)
+			if err != nil {
+				return false, err
+			}
+			if _, ok := n.run.seen[string(n.run.scratch)]; ok {
+				continue
+			}
+			sKey := string(n.run.scratch)
+			acc := n.run.seenAcc.Wtxn(params.p.session)
+			if err := acc.Grow(params.ctx, int64(len(sKey))); err != nil {
+				return false, err
+			}
+			n.run.seen[sKey] = struct{}{}
+		}
+		if _, err := n.run.nextWorkTable.AddRow(params.ctx, values); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+}
+
+// nextIteration sets up the evaluation of the recursive term over the
+// rows produced by the previous iteration. If there were no such rows,
+// run.cur is set to nil to indicate that the recursion is complete.
+func (n *recursiveCTENode) nextIteration(params runParams) error {
+	n.closeIteration(params.ctx)
+
+	n.run.workTable = n.run.nextWorkTable
+	n.run.nextWorkTable = nil
+	if n.run.workTable.Len() == 0 {
+		return nil
+	}
+	n.run.nextWorkTable = sqlbase.NewRowContainer(
+		params.p.session.TxnState.makeBoundAccount(), sqlbase.ColTypeInfoFromResCols(n.columns), 0,
+	)
+
+	if n.run.iterations == 0 {
+		// The first iteration uses the plan that was prepared along with
+		// the rest of the query.
+		n.workTableRef.rows = n.run.workTable
+		n.run.cur, n.run.curRef = n.recursive, n.workTableRef
+		n.run.iterations++
+		return n.recursive.Start(params)
+	}
+
+	plan, ref, err := n.genIteration(params.ctx)
+	if err != nil {
+		return err
+	}
+	ref.rows = n.run.workTable
+	n.run.cur, n.run.curRef = plan, ref
+	n.run.iterations++
+	return params.p.startPlan(params.ctx, plan)
+}
+
+// closeIteration releases the resources used by the current
+// iteration, including the working table it reads from.
+func (n *recursiveCTENode) closeIteration(ctx context.Context) {
+	if n.run.curRef != nil {
+		// The working table is owned by the recursiveCTENode, not by the
+		// valuesNode that refers to it.
+		n.run.curRef.rows = nil
+		n.run.curRef = nil
+	}
+	if n.run.cur != nil && n.run.cur != n.initial && n.run.cur != n.recursive {
+		n.run.cur.Close(ctx)
+	}
+	n.run.cur = nil
+	if n.run.workTable != nil {
+		n.run.workTable.Close(ctx)
+		n.run.workTable = nil
+	}
+}
+
+func (n *recursiveCTENode) Close(ctx context.Context) {
+	n.closeIteration(ctx)
+	if n.run.nextWorkTable != nil {
+		n.run.nextWorkTable.Close(ctx)
+		n.run.nextWorkTable = nil
+	}
+	n.initial.Close(ctx)
+	n.recursive.Close(ctx)
+	if n.run.seen != nil {
+		n.run.seen = nil
+		n.run.seenAcc.Wtxn(n.workTableRef.p.session).Close(ctx)
+	}
+}
@@ -43,6 +43,10 @@ func (p *planner) RenameDatabase(ctx context.Context, n *parser.RenameDatabase)
 		return nil, errEmptyDatabaseName
 	}
 
+	if err := checkNotTemporarySchemaName(string(n.NewName)); err != nil {
+		return nil, err
+	}
+
 	if err := p.RequireSuperUser("ALTER DATABASE ... RENAME"); err != nil {
 		return nil, err
 	}
@@ -52,7 +56,7 @@ func (p *planner) RenameDatabase(ctx context.Context, n *parser.RenameDatabase)
 		return nil, err
 	}
 
-	if err := p.CheckPrivilege(dbDesc, privilege.DROP); err != nil {
+	if err := p.CheckPrivilege(ctx, dbDesc, privilege.DROP); err != nil {
 		return nil, err
 	}
 
@@ -105,17 +109,17 @@ func (p *planner) RenameDatabase(ctx context.Context, n *parser.RenameDatabase)
 	return &zeroNode{}, nil
 }
 
-// RenameTable renames the table or view.
-// Privileges: DROP on source table/view, CREATE on destination database.
+// RenameTable renames the table, view or sequence.
+// Privileges: DROP on source table/view/sequence, CREATE on destination database.
 //   Notes: postgres requires the table owner.
 //          mysql requires ALTER, DROP on the original table, and CREATE, INSERT
 //          on the new table (and does not copy privileges over).
 func (p *planner) RenameTable(ctx context.Context, n *parser.RenameTable) (planNode, error) {
-	oldTn, err := n.Name.NormalizeWithDatabaseName(p.session.Database)
+	oldTn, err := p.normalizeTableName(ctx, &n.Name)
 	if err != nil {
 		return nil, err
 	}
-	newTn, err := n.NewName.NormalizeWithDatabaseName(p.session.Database)
+	newTn, err := p.normalizeRenamedTableName(oldTn, &n.NewName)
 	if err != nil {
 		return nil, err
 	}
@@ -131,41 +135,30 @@ func (p *planner) RenameTable(ctx context.Context, n *parser.RenameTable) (planN
 	// by running ALTER VIEW. Our behavior is strict for now, but can be
 	// made more lenient down the road if needed.
 	var tableDesc *sqlbase.TableDescriptor
-	if n.IsView {
+	switch {
+	case n.IsView:
 		tableDesc, err = getViewDesc(ctx, p.txn, p.getVirtualTabler(), oldTn)
-		if err != nil {
-			return nil, err
-		}
-		if tableDesc == nil {
-			if n.IfExists {
-				// Noop.
-				return &zeroNode{}, nil
-			}
-			// Key does not exist, but we want it to: error out.
-			return nil, sqlbase.NewUndefinedRelationError(oldTn)
-		}
-		if tableDesc.State != sqlbase.TableDescriptor_PUBLIC {
-			return nil, sqlbase.NewUndefinedRelationError(oldTn)
-		}
-	} else {
+	case n.IsSequence:
+		tableDesc, err = getSequenceDesc(ctx, p.txn, p.getVirtualTabler(), oldTn)
+	default:
 		tableDesc, err = getTableDesc(ctx, p.txn, p.getVirtualTabler(), oldTn)
-		if err != nil {
-			return nil, err
-		}
-		if tableDesc == nil {
-			if n.IfExists {
-				// Noop.
-				return &zeroNode{}, nil
-			}
-			// Key does not exist, but we want it to: error out.
-			return nil, sqlbase.NewUndefinedRelationError(oldTn)
-		}
-		if tableDesc.State != sqlbase.TableDescriptor_PUBLIC {
-			return nil, sqlbase.NewUndefinedRelationError(oldTn)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if tableDesc == nil {
+		if n.IfExists {
+			// Noop.
+			return &zeroNode{}, nil
 		}
+		// Key does not exist, but we want it to: error out.
+		return nil, sqlbase.NewUndefinedRelationError(oldTn)
+	}
+	if tableDesc.State != sqlbase.TableDescriptor_PUBLIC {
+		return nil, sqlbase.NewUndefinedRelationError(oldTn)
 	}
 
-	if err := p.CheckPrivilege(tableDesc, privilege.DROP); err != nil {
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege.DROP); err != nil {
 		return nil, err
 	}
 
@@ -184,7 +177,7 @@ func (p *planner) RenameTable(ctx context.Context, n *parser.RenameTable) (planN
 		return nil, err
 	}
 
-	if err := p.CheckPrivilege(targetDbDesc, privilege.CREATE); err != nil {
+	if err := p.CheckPrivilege(ctx, targetDbDesc, privilege.CREATE); err != nil {
 		return nil, err
 	}
 
@@ -275,7 +268,7 @@ func (p *planner) RenameIndex(ctx context.Context, n *parser.RenameIndex) (planN
 		return nil, err
 	}
 
-	if err := p.CheckPrivilege(tableDesc, privilege.CREATE); err != nil {
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege.CREATE); err != nil {
 		return nil, err
 	}
 
@@ -322,7 +315,7 @@ func (p *planner) RenameIndex(ctx context.Context, n *parser.RenameIndex) (planN
 //          mysql requires ALTER, CREATE, INSERT on the table.
 func (p *planner) RenameColumn(ctx context.Context, n *parser.RenameColumn) (planNode, error) {
 	// Check if table exists.
-	tn, err := n.Table.NormalizeWithDatabaseName(p.session.Database)
+	tn, err := p.normalizeTableName(ctx, &n.Table)
 	if err != nil {
 		return nil, err
 	}
@@ -339,7 +332,7 @@ func (p *planner) RenameColumn(ctx context.Context, n *parser.RenameColumn) (pla
 		return nil, fmt.Errorf("table %q does not exist", tn.Table())
 	}
 
-	if err := p.CheckPrivilege(tableDesc, privilege.CREATE); err != nil {
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege.CREATE); err != nil {
 		return nil, err
 	}
 
@@ -135,12 +135,25 @@ func (r *renderNode) IndexedVarFormat(buf *bytes.Buffer, f parser.FmtFlags, idx
 // Select selects rows from a SELECT/UNION/VALUES, ordering and/or limiting them.
 func (p *planner) Select(
 	ctx context.Context, n *parser.Select, desiredTypes []parser.Type,
-) (planNode, error) {
+) (result planNode, err error) {
+	finishWith, err := p.initWith(ctx, n.With)
+	if err != nil {
+		return nil, err
+	}
+	if finishWith != nil {
+		defer func() { result, err = finishWith(result, err) }()
+	}
+
 	wrapped := n.Select
 	limit := n.Limit
 	orderBy := n.OrderBy
 
 	for s, ok := wrapped.(*parser.ParenSelect); ok; s, ok = wrapped.(*parser.ParenSelect) {
+		if s.Select.With != nil {
+			// The WITH clause must be planned along with the inner select
+			// that carries it.
+			break
+		}
 		wrapped = s.Select.Select
 		if s.Select.OrderBy != nil {
 			if orderBy != nil {
@@ -322,7 +335,7 @@ func (r *renderNode) initTargets(
 
 		// Output column names should exactly match the original expression, so we
 		// have to determine the output column name before we rewrite SRFs below.
-		outputName, err := getRenderColName(r.planner.session.SearchPath, target)
+		outputName, err := getRenderColName(r.planner.session.SearchPath, r.planner, target)
 		if err != nil {
 			return err
 		}
@@ -365,6 +378,7 @@ type srfExtractionVisitor struct {
 	srf        *parser.FuncExpr
 	ivarHelper *parser.IndexedVarHelper
 	searchPath parser.SearchPath
+	functions  parser.FunctionResolver
 }
 
 var _ parser.Visitor = &srfExtractionVisitor{}
@@ -377,7 +391,7 @@ func (v *srfExtractionVisitor) VisitPre(expr parser.Expr) (recurse bool, newNode
 func (v *srfExtractionVisitor) VisitPost(expr parser.Expr) parser.Expr {
 	switch t := expr.(type) {
 	case *parser.FuncExpr:
-		fd, err := t.Func.Resolve(v.searchPath)
+		fd, err := t.Func.ResolveWith(v.searchPath, v.functions)
 		if err != nil {
 			v.err = err
 			return expr
@@ -400,9 +414,10 @@ func (v *srfExtractionVisitor) VisitPost(expr parser.Expr) parser.Expr {
 // the set-returning function replaced by an IndexedVar that points at the new
 // data source.
 //
-// Expressions with more than one SRF require lateral correlated subqueries,
-// which are not yet supported. For now, this function returns an error if more
-// than one SRF is present in the render expression.
+// The arguments of the SRF can refer to the columns of the existing data
+// sources, in which case the join is lateral. Expressions with more than one
+// SRF are not yet supported; this function returns an error if more than one
+// SRF is present in the render expression.
 func (r *renderNode) rewriteSRFs(
 	ctx context.Context, target parser.SelectExpr,
 ) (parser.SelectExpr, error) {
@@ -413,6 +428,7 @@ func (r *renderNode) rewriteSRFs(
 		srf:        nil,
 		ivarHelper: &r.ivarHelper,
 		searchPath: r.planner.session.SearchPath,
+		functions:  r.planner,
 	}
 	expr, _ := parser.WalkExpr(v, target.Expr)
 	if v.err != nil {
@@ -427,19 +443,18 @@ func (r *renderNode) rewriteSRFs(
 
 	// We rewrote exactly one SRF; cross-join it with our sources and return the
 	// new render expression.
-	src, err := r.planner.getDataSource(ctx, v.srf, nil, publicColumns)
+	var src planDataSource
+	var err error
+	if isUnarySource(r.source) {
+		src, err = r.planner.getDataSource(ctx, v.srf, nil, publicColumns)
+	} else {
+		// The FROM clause specifies something. Replace with a cross-join.
+		src, err = r.planner.makeLateralJoin(ctx, "CROSS JOIN", r.source, v.srf, nil, publicColumns)
+	}
 	if err != nil {
 		return target, err
 	}
 
-	if !isUnarySource(r.source) {
-		// The FROM clause specifies something. Replace with a cross-join.
-		src, err = r.planner.makeJoin(ctx, "CROSS JOIN", r.source, src, nil)
-		if err != nil {
-			return target, err
-		}
-	}
-
 	r.source = src
 	r.sourceInfo = multiSourceInfo{r.source.info}
 
@@ -483,7 +498,9 @@ func (r *renderNode) initWhere(ctx context.Context, whereExpr parser.Expr) (*fil
 }
 
 // getRenderColName returns the output column name for a render expression.
-func getRenderColName(searchPath parser.SearchPath, target parser.SelectExpr) (string, error) {
+func getRenderColName(
+	searchPath parser.SearchPath, functions parser.FunctionResolver, target parser.SelectExpr,
+) (string, error) {
 	if target.As != "" {
 		return string(target.As), nil
 	}
@@ -513,7 +530,7 @@ func getRenderColName(searchPath parser.SearchPath, target parser.SelectExpr) (s
 	// For compatibility with Postgres, a render expression rooted by a
 	// set-returning function is named after that SRF.
 	case *parser.FuncExpr:
-		fd, err := t.Func.Resolve(searchPath)
+		fd, err := t.Func.ResolveWith(searchPath, functions)
 		if err != nil {
 			return "", err
 		}
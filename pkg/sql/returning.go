@@ -46,13 +46,13 @@ type returningHelper struct {
 }
 
 // newReturningHelper creates a new returningHelper for use by an
-// insert/update node.
+// insert/update node. The RETURNING expressions are evaluated against rows of
+// the given data source.
 func (p *planner) newReturningHelper(
 	ctx context.Context,
 	r parser.ReturningClause,
 	desiredTypes []parser.Type,
-	tn *parser.TableName,
-	tablecols []sqlbase.ColumnDescriptor,
+	source *dataSourceInfo,
 ) (*returningHelper, error) {
 	rh := &returningHelper{
 		p: p,
@@ -76,11 +76,9 @@ func (p *planner) newReturningHelper(
 	}
 
 	rh.columns = make(sqlbase.ResultColumns, 0, len(rExprs))
-	rh.source = newSourceInfoForSingleTable(
-		*tn, sqlbase.ResultColumnsFromColDescs(tablecols),
-	)
+	rh.source = source
 	rh.exprs = make([]parser.TypedExpr, 0, len(rExprs))
-	ivarHelper := parser.MakeIndexedVarHelper(rh, len(tablecols))
+	ivarHelper := parser.MakeIndexedVarHelper(rh, len(source.sourceColumns))
 	for _, target := range rExprs {
 		cols, typedExprs, _, err := p.computeRenderAllowingStars(
 			ctx, target, parser.TypeAny, multiSourceInfo{rh.source}, ivarHelper,
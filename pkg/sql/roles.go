@@ -0,0 +1,251 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// grantRoleNode implements GRANT <role> TO <member>.
+type grantRoleNode struct {
+	roles       parser.NameList
+	members     parser.NameList
+	adminOption bool
+}
+
+// GrantRole adds members to roles.
+// Privileges: superuser, or ADMIN OPTION on every granted role.
+//   notes: postgres also accepts the CREATEROLE attribute, which we do not
+//          support.
+func (p *planner) GrantRole(ctx context.Context, n *parser.GrantRole) (planNode, error) {
+	roles, err := p.checkRoleAdmin(ctx, n.Roles)
+	if err != nil {
+		return nil, err
+	}
+	return &grantRoleNode{
+		roles:       roles,
+		members:     normalizeNames(n.Members),
+		adminOption: n.AdminOption,
+	}, nil
+}
+
+func (n *grantRoleNode) Start(params runParams) error {
+	users, err := params.p.loadUsersAndRoles(params.ctx)
+	if err != nil {
+		return err
+	}
+	if err := checkRolesAndMembersExist(users, n.roles, n.members); err != nil {
+		return err
+	}
+	if err := params.p.bumpRoleMembershipTableVersion(params.ctx); err != nil {
+		return err
+	}
+
+	internalExecutor := InternalExecutor{LeaseManager: params.p.LeaseMgr()}
+	for _, role := range n.roles {
+		for _, member := range n.members {
+			// Memberships added earlier in this loop are visible to memberOf, so
+			// this also catches cycles formed within a single statement.
+			if role == member {
+				return errors.Errorf("%s cannot be a member of itself", role)
+			}
+			memberOf, err := params.p.memberOf(params.ctx, string(role))
+			if err != nil {
+				return err
+			}
+			if _, ok := memberOf[string(member)]; ok {
+				return errors.Errorf(
+					"making %s a member of %s would create a cycle", member, role)
+			}
+
+			// Granting without ADMIN OPTION must not remove an ADMIN OPTION
+			// granted previously.
+			stmt := `INSERT INTO system.role_members ("role", "member", "isAdmin") ` +
+				`VALUES ($1, $2, false) ON CONFLICT ("role", "member") DO NOTHING`
+			if n.adminOption {
+				stmt = `UPSERT INTO system.role_members ("role", "member", "isAdmin") ` +
+					`VALUES ($1, $2, true)`
+			}
+			if _, err := internalExecutor.ExecuteStatementInTransaction(
+				params.ctx, "grant-role", params.p.txn, stmt, string(role), string(member),
+			); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (*grantRoleNode) Next(runParams) (bool, error) { return false, nil }
+func (*grantRoleNode) Close(context.Context)        {}
+func (*grantRoleNode) Values() parser.Datums        { return parser.Datums{} }
+
+// revokeRoleNode implements REVOKE [ADMIN OPTION FOR] <role> FROM <member>.
+type revokeRoleNode struct {
+	roles       parser.NameList
+	members     parser.NameList
+	adminOption bool
+}
+
+// RevokeRole removes members from roles, or only removes their ADMIN OPTION
+// if ADMIN OPTION FOR is specified.
+// Privileges: superuser, or ADMIN OPTION on every revoked role.
+func (p *planner) RevokeRole(ctx context.Context, n *parser.RevokeRole) (planNode, error) {
+	roles, err := p.checkRoleAdmin(ctx, n.Roles)
+	if err != nil {
+		return nil, err
+	}
+	return &revokeRoleNode{
+		roles:       roles,
+		members:     normalizeNames(n.Members),
+		adminOption: n.AdminOption,
+	}, nil
+}
+
+func (n *revokeRoleNode) Start(params runParams) error {
+	users, err := params.p.loadUsersAndRoles(params.ctx)
+	if err != nil {
+		return err
+	}
+	if err := checkRolesAndMembersExist(users, n.roles, n.members); err != nil {
+		return err
+	}
+	if err := params.p.bumpRoleMembershipTableVersion(params.ctx); err != nil {
+		return err
+	}
+
+	stmt := `DELETE FROM system.role_members WHERE "role" = $1 AND "member" = $2`
+	if n.adminOption {
+		stmt = `UPDATE system.role_members SET "isAdmin" = false ` +
+			`WHERE "role" = $1 AND "member" = $2`
+	}
+	internalExecutor := InternalExecutor{LeaseManager: params.p.LeaseMgr()}
+	for _, role := range n.roles {
+		for _, member := range n.members {
+			// Like postgres, revoking a membership that does not exist is a no-op.
+			if _, err := internalExecutor.ExecuteStatementInTransaction(
+				params.ctx, "revoke-role", params.p.txn, stmt, string(role), string(member),
+			); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (*revokeRoleNode) Next(runParams) (bool, error) { return false, nil }
+func (*revokeRoleNode) Close(context.Context)        {}
+func (*revokeRoleNode) Values() parser.Datums        { return parser.Datums{} }
+
+// bumpRoleMembershipTableVersion marks the system.role_members descriptor as
+// modified by the current transaction. This invalidates the MembershipCache of
+// every node once the transaction commits, and makes memberOf read the
+// memberships from the current transaction until then.
+func (p *planner) bumpRoleMembershipTableVersion(ctx context.Context) error {
+	tableDesc, err := sqlbase.GetTableDescFromID(ctx, p.txn, keys.RoleMembersTableID)
+	if err != nil {
+		return err
+	}
+	if tableDesc.UpVersion {
+		return nil
+	}
+	if err := p.txn.SetSystemConfigTrigger(); err != nil {
+		return errors.Wrap(err,
+			"role membership change cannot follow a statement that has written in the same transaction")
+	}
+	if err := tableDesc.SetUpVersion(); err != nil {
+		return err
+	}
+	if err := p.writeTableDesc(ctx, tableDesc); err != nil {
+		return err
+	}
+	p.notifySchemaChange(tableDesc, sqlbase.InvalidMutationID)
+	return nil
+}
+
+// checkRoleAdmin verifies that the session user is allowed to change the
+// membership of the given roles, and returns the normalized role names.
+func (p *planner) checkRoleAdmin(
+	ctx context.Context, roles parser.NameList,
+) (parser.NameList, error) {
+	roles = normalizeNames(roles)
+	user := p.session.User
+	if user == security.RootUser || user == security.NodeUser {
+		return roles, nil
+	}
+	memberOf, err := p.memberOf(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range roles {
+		if !memberOf[string(role)] {
+			return nil, errors.Errorf("%s is not a superuser or role admin for role %s", user, role)
+		}
+	}
+	return roles, nil
+}
+
+// loadUsersAndRoles returns all entries of system.users, mapped to whether
+// they are roles.
+func (p *planner) loadUsersAndRoles(ctx context.Context) (map[string]bool, error) {
+	internalExecutor := InternalExecutor{LeaseManager: p.LeaseMgr()}
+	rows, err := internalExecutor.QueryRowsInTransaction(
+		ctx, "load-users", p.txn, `SELECT username, "isRole" FROM system.users`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	users := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		users[string(parser.MustBeDString(row[0]))] = row[1] == parser.DBoolTrue
+	}
+	return users, nil
+}
+
+// checkRolesAndMembersExist verifies that every name in roles is a role, and
+// that every name in members is a user or a role.
+func checkRolesAndMembersExist(users map[string]bool, roles, members parser.NameList) error {
+	for _, role := range roles {
+		if isRole, ok := users[string(role)]; !ok || !isRole {
+			return errors.Errorf("role %s does not exist", role)
+		}
+	}
+	for _, member := range members {
+		// The root user is not stored in system.users.
+		if member == security.RootUser {
+			continue
+		}
+		if _, ok := users[string(member)]; !ok {
+			return errors.Errorf("user or role %s does not exist", member)
+		}
+	}
+	return nil
+}
+
+// normalizeNames returns a copy of names with every name normalized.
+func normalizeNames(names parser.NameList) parser.NameList {
+	ret := make(parser.NameList, len(names))
+	for i, name := range names {
+		ret[i] = parser.Name(name.Normalize())
+	}
+	return ret
+}
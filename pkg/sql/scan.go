@@ -95,6 +95,10 @@ type scanNode struct {
 
 	disableBatchLimits bool
 
+	// estimate is the estimated cardinality and cost of the scan, computed
+	// during index selection.
+	estimate planEstimate
+
 	scanVisibility scanVisibility
 	// This struct must be allocated on the heap and its location stay
 	// stable after construction because it implements
@@ -189,6 +193,7 @@ func (n *scanNode) Next(params runParams) (bool, error) {
 
 // Initializes a scanNode with a table descriptor.
 func (n *scanNode) initTable(
+	ctx context.Context,
 	p *planner,
 	desc *sqlbase.TableDescriptor,
 	indexHints *parser.IndexHints,
@@ -198,7 +203,7 @@ func (n *scanNode) initTable(
 	n.desc = desc
 
 	if !p.skipSelectPrivilegeChecks {
-		if err := p.CheckPrivilege(n.desc, privilege.SELECT); err != nil {
+		if err := p.CheckPrivilege(ctx, n.desc, privilege.SELECT); err != nil {
 			return err
 		}
 	}
@@ -384,6 +389,10 @@ func (n *scanNode) computeOrdering(
 	index *sqlbase.IndexDescriptor, exactPrefix int, reverse bool,
 ) orderingInfo {
 	var ordering orderingInfo
+	if index.Type == sqlbase.IndexDescriptor_INVERTED {
+		// The entries of an inverted index are not ordered by any column.
+		return ordering
+	}
 
 	columnIDs, dirs := index.FullColumnIDs()
 
@@ -575,7 +575,15 @@ func (sc *SchemaChanger) waitToUpdateLeases(ctx context.Context, tableID sqlbase
 // schema.
 // Returns the updated of the descriptor.
 func (sc *SchemaChanger) done(ctx context.Context) (*sqlbase.Descriptor, error) {
+	var followUps int
+	var tableSpan roachpb.Span
+	// The indexes rebuilt by ALTER COLUMN ... TYPE, keyed by the ID of the
+	// index they replace, and the resulting descriptor, whose zone config
+	// subzones are moved to the new indexes.
+	var replacedIndexes map[sqlbase.IndexID]sqlbase.IndexID
+	var tableDesc *sqlbase.TableDescriptor
 	return sc.leaseMgr.Publish(ctx, sc.tableID, func(desc *sqlbase.TableDescriptor) error {
+		replacedIndexes, tableDesc = nil, desc
 		i := 0
 		for _, mutation := range desc.Mutations {
 			if mutation.MutationID != sc.mutationID {
@@ -583,6 +591,14 @@ func (sc *SchemaChanger) done(ctx context.Context) (*sqlbase.Descriptor, error)
 				// mutations if they have the mutation ID we're looking for.
 				break
 			}
+			if conv := mutation.Conversion; conv != nil {
+				for j, id := range conv.ReplacedIndexIDs {
+					if replacedIndexes == nil {
+						replacedIndexes = make(map[sqlbase.IndexID]sqlbase.IndexID)
+					}
+					replacedIndexes[id] = conv.ReplacementIndexIDs[j]
+				}
+			}
 			desc.MakeMutationComplete(mutation)
 			i++
 		}
@@ -591,8 +607,28 @@ func (sc *SchemaChanger) done(ctx context.Context) (*sqlbase.Descriptor, error)
 			// the version.
 			return errDidntUpdateDescriptor
 		}
-		// Trim the executed mutations from the descriptor.
-		desc.Mutations = desc.Mutations[i:]
+		// Trim the executed mutations from the descriptor. Completing a
+		// mutation can queue more work under the same mutation ID (the drop
+		// of a column rewritten to a new type), which is moved to the front
+		// so that it runs next.
+		remaining := make([]sqlbase.DescriptorMutation, 0, len(desc.Mutations)-i)
+		for _, mutation := range desc.Mutations[i:] {
+			if mutation.MutationID == sc.mutationID {
+				remaining = append(remaining, mutation)
+			}
+		}
+		followUps = len(remaining)
+		for _, mutation := range desc.Mutations[i:] {
+			if mutation.MutationID != sc.mutationID {
+				remaining = append(remaining, mutation)
+			}
+		}
+		desc.Mutations = remaining
+		if followUps > 0 {
+			// The mutation group isn't done yet; keep its job.
+			tableSpan = desc.PrimaryIndexSpan()
+			return nil
+		}
 
 		for i, g := range desc.MutationJobs {
 			if g.MutationID == sc.mutationID {
@@ -603,6 +639,23 @@ func (sc *SchemaChanger) done(ctx context.Context) (*sqlbase.Descriptor, error)
 		}
 		return nil
 	}, func(txn *client.Txn) error {
+		if len(replacedIndexes) > 0 {
+			if err := remapSubzones(ctx, txn, tableDesc, replacedIndexes); err != nil {
+				return err
+			}
+		}
+		if followUps > 0 {
+			// The follow-up mutations are backfilled over the whole table.
+			details, ok := sc.job.Record.Details.(jobs.SchemaChangeDetails)
+			if !ok {
+				return errors.Errorf("expected SchemaChangeDetails job type, got %T", sc.job.Record.Details)
+			}
+			details.ResumeSpanList = make([]jobs.ResumeSpanList, followUps)
+			for i := range details.ResumeSpanList {
+				details.ResumeSpanList[i].ResumeSpans = []roachpb.Span{tableSpan}
+			}
+			return sc.job.WithTxn(txn).SetDetails(ctx, details)
+		}
 		if err := sc.job.WithTxn(txn).Succeeded(ctx); err != nil {
 			log.Warningf(ctx, "schema change ignoring error while marking job %d as successful: %+v",
 				sc.job.ID(), err)
@@ -652,24 +705,34 @@ func (sc *SchemaChanger) runStateMachineAndBackfill(
 	if fn := sc.testingKnobs.RunBeforePublishWriteAndDelete; fn != nil {
 		fn()
 	}
-	// Run through mutation state machine before backfill.
-	if err := sc.RunStateMachineBeforeBackfill(ctx); err != nil {
-		return err
-	}
+	for {
+		// Run through mutation state machine before backfill.
+		if err := sc.RunStateMachineBeforeBackfill(ctx); err != nil {
+			return err
+		}
 
-	if err := sc.job.Progressed(ctx, .1, jobs.Noop); err != nil {
-		log.Warningf(ctx, "failed to log progress on job %v after completing state machine: %v",
-			sc.job.ID(), err)
-	}
+		if err := sc.job.Progressed(ctx, .1, jobs.Noop); err != nil {
+			log.Warningf(ctx, "failed to log progress on job %v after completing state machine: %v",
+				sc.job.ID(), err)
+		}
 
-	// Run backfill(s).
-	if err := sc.runBackfill(ctx, lease, evalCtx); err != nil {
-		return err
-	}
+		// Run backfill(s).
+		if err := sc.runBackfill(ctx, lease, evalCtx); err != nil {
+			return err
+		}
 
-	// Mark the mutations as completed.
-	_, err := sc.done(ctx)
-	return err
+		// Mark the mutations as completed.
+		desc, err := sc.done(ctx)
+		if err != nil {
+			return err
+		}
+		// Completing the mutations may have queued follow-up mutations with
+		// the same ID; run those through the state machine too.
+		if mutations := desc.GetTable().Mutations; len(mutations) == 0 ||
+			mutations[0].MutationID != sc.mutationID {
+			return nil
+		}
+	}
 }
 
 // reverseMutations reverses the direction of all the mutations with the
@@ -38,6 +38,11 @@ type nameResolutionVisitor struct {
 	colOffsets []int
 	iVarHelper parser.IndexedVarHelper
 	searchPath parser.SearchPath
+	functions  parser.FunctionResolver
+
+	// lateralScopes are searched for the columns that are not found in
+	// sources. See lateral.go.
+	lateralScopes []*lateralScope
 
 	// foundDependentVars is set to true during the analysis if an
 	// expression was found which can change values between rows of the
@@ -117,6 +122,21 @@ func (v *nameResolutionVisitor) VisitPre(expr parser.Expr) (recurse bool, newNod
 
 	case *parser.ColumnItem:
 		srcIdx, colIdx, err := v.sources.findColumn(t)
+		if err != nil && len(v.lateralScopes) > 0 && isUnknownColumnError(err) {
+			// The column may belong to a FROM item preceding an
+			// enclosing LATERAL data source.
+			lc, found, lerr := resolveLateralColumn(v.lateralScopes, t)
+			if lerr != nil {
+				v.err = lerr
+				return false, expr
+			}
+			if found {
+				if _, ok := lc.(*lateralColumn); ok {
+					v.foundDependentVars = true
+				}
+				return false, lc
+			}
+		}
 		if err != nil {
 			v.err = err
 			return false, expr
@@ -126,7 +146,7 @@ func (v *nameResolutionVisitor) VisitPre(expr parser.Expr) (recurse bool, newNod
 		return true, ivar
 
 	case *parser.FuncExpr:
-		fd, err := t.Func.Resolve(v.searchPath)
+		fd, err := t.Func.ResolveWith(v.searchPath, v.functions)
 		if err != nil {
 			v.err = err
 			return false, expr
@@ -222,6 +242,8 @@ func (p *planner) resolveNames(
 		colOffsets:         make([]int, len(sources)),
 		iVarHelper:         ivarHelper,
 		searchPath:         p.session.SearchPath,
+		functions:          p,
+		lateralScopes:      p.lateralScopes,
 		foundDependentVars: false,
 	}
 	colOffset := 0
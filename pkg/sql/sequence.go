@@ -0,0 +1,313 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"math"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// sequenceState stores the session-scoped state of the sequences used by a
+// session: the value most recently obtained by nextval() for each sequence,
+// which is what currval() returns. It is protected by a mutex because
+// parallelized statements may call nextval() concurrently.
+type sequenceState struct {
+	mu syncutil.Mutex
+	// latestValues maps the descriptor ID of a sequence to the value most
+	// recently obtained by nextval() for it in this session.
+	latestValues map[sqlbase.ID]int64
+}
+
+func (ss *sequenceState) recordValue(seqID sqlbase.ID, val int64) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if ss.latestValues == nil {
+		ss.latestValues = make(map[sqlbase.ID]int64)
+	}
+	ss.latestValues[seqID] = val
+}
+
+func (ss *sequenceState) getLastValueByID(seqID sqlbase.ID) (int64, bool) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	val, ok := ss.latestValues[seqID]
+	return val, ok
+}
+
+// The value of a sequence is stored in a single KV pair (see
+// keys.MakeSequenceKey) which is read and written outside of the SQL
+// transaction: like in Postgres, the values handed out by nextval() are
+// never rolled back, so that concurrent transactions using the same sequence
+// do not conflict with each other.
+
+// getSequenceDescForFunction looks up the leased descriptor of the sequence
+// used as argument of a sequence builtin and checks that the current user has
+// the given privilege on it.
+func (p *planner) getSequenceDescForFunction(
+	ctx context.Context, seqName *parser.TableName, priv privilege.Kind,
+) (*sqlbase.TableDescriptor, error) {
+	descriptor, err := p.getTableDesc(ctx, seqName)
+	if err != nil {
+		return nil, err
+	}
+	if !descriptor.IsSequence() {
+		return nil, sqlbase.NewWrongObjectTypeError(seqName, "sequence")
+	}
+	if err := p.CheckPrivilege(ctx, descriptor, priv); err != nil {
+		return nil, err
+	}
+	return descriptor, nil
+}
+
+// IncrementSequence implements the parser.EvalPlanner interface.
+func (p *planner) IncrementSequence(ctx context.Context, seqName *parser.TableName) (int64, error) {
+	descriptor, err := p.getSequenceDescForFunction(ctx, seqName, privilege.UPDATE)
+	if err != nil {
+		return 0, err
+	}
+	seqOpts := descriptor.SequenceOpts
+	seqValueKey := keys.MakeSequenceKey(uint32(descriptor.ID))
+	val, err := client.IncrementValRetryable(
+		ctx, p.session.execCfg.DB, seqValueKey, seqOpts.Increment)
+	if err != nil {
+		return 0, err
+	}
+	if val > seqOpts.MaxValue {
+		return 0, pgerror.NewErrorf(pgerror.CodeSequenceGeneratorLimitExceeded,
+			"reached maximum value of sequence %q (%d)", descriptor.Name, seqOpts.MaxValue)
+	}
+	if val < seqOpts.MinValue {
+		return 0, pgerror.NewErrorf(pgerror.CodeSequenceGeneratorLimitExceeded,
+			"reached minimum value of sequence %q (%d)", descriptor.Name, seqOpts.MinValue)
+	}
+	p.session.sequenceState.recordValue(descriptor.ID, val)
+	return val, nil
+}
+
+// GetLatestValueInSessionForSequence implements the parser.EvalPlanner interface.
+func (p *planner) GetLatestValueInSessionForSequence(
+	ctx context.Context, seqName *parser.TableName,
+) (int64, error) {
+	descriptor, err := p.getSequenceDescForFunction(ctx, seqName, privilege.SELECT)
+	if err != nil {
+		return 0, err
+	}
+	val, ok := p.session.sequenceState.getLastValueByID(descriptor.ID)
+	if !ok {
+		return 0, pgerror.NewErrorf(pgerror.CodeObjectNotInPrerequisiteStateError,
+			"currval of sequence %q is not yet defined in this session", descriptor.Name)
+	}
+	return val, nil
+}
+
+// SetSequenceValue implements the parser.EvalPlanner interface.
+func (p *planner) SetSequenceValue(
+	ctx context.Context, seqName *parser.TableName, newVal int64, isCalled bool,
+) error {
+	descriptor, err := p.getSequenceDescForFunction(ctx, seqName, privilege.UPDATE)
+	if err != nil {
+		return err
+	}
+	seqOpts := descriptor.SequenceOpts
+	if newVal > seqOpts.MaxValue || newVal < seqOpts.MinValue {
+		return pgerror.NewErrorf(pgerror.CodeNumericValueOutOfRangeError,
+			"setval: value %d is out of bounds for sequence %q (%d..%d)",
+			newVal, descriptor.Name, seqOpts.MinValue, seqOpts.MaxValue)
+	}
+	if isCalled {
+		// Like in Postgres, setting a value which counts as already handed out
+		// makes it the value returned by currval().
+		p.session.sequenceState.recordValue(descriptor.ID, newVal)
+	} else {
+		// The next call to nextval() must return newVal.
+		newVal -= seqOpts.Increment
+	}
+	seqValueKey := keys.MakeSequenceKey(uint32(descriptor.ID))
+	return p.session.execCfg.DB.Put(ctx, seqValueKey, newVal)
+}
+
+// assignSequenceOptions applies the options of a CREATE SEQUENCE or ALTER
+// SEQUENCE statement to the given sequence options. When setDefaults is set,
+// the options not specified by the statement are set to their default values,
+// which depend on whether the sequence is ascending or descending.
+func assignSequenceOptions(
+	opts *sqlbase.TableDescriptor_SequenceOpts, optsNode parser.SequenceOptions, setDefaults bool,
+) error {
+	// All other defaults depend on the value of the increment, i.e. whether
+	// the sequence is ascending or descending.
+	for _, option := range optsNode {
+		if option.Name == parser.SeqOptIncrement {
+			opts.Increment = *option.IntVal
+		}
+	}
+	if opts.Increment == 0 {
+		return pgerror.NewError(pgerror.CodeInvalidParameterValueError, "INCREMENT must not be zero")
+	}
+	isAscending := opts.Increment > 0
+
+	if setDefaults {
+		if isAscending {
+			opts.MinValue = 1
+			opts.MaxValue = math.MaxInt64
+		} else {
+			opts.MinValue = math.MinInt64
+			opts.MaxValue = -1
+		}
+	}
+
+	startSet := false
+	for _, option := range optsNode {
+		switch option.Name {
+		case parser.SeqOptCycle:
+			return pgerror.Unimplemented("seq_cycle", "CYCLE option is not supported")
+		case parser.SeqOptNoCycle:
+			// This is the default, nothing to do.
+		case parser.SeqOptCache:
+			v := *option.IntVal
+			if v < 1 {
+				return pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+					"CACHE (%d) must be greater than zero", v)
+			}
+			if v > 1 {
+				return pgerror.Unimplemented("seq_cache", "CACHE values larger than 1 are not supported")
+			}
+		case parser.SeqOptIncrement:
+			// Already handled above.
+		case parser.SeqOptMinValue:
+			// A nil value means NO MINVALUE, i.e. the default.
+			if option.IntVal != nil {
+				opts.MinValue = *option.IntVal
+			} else if isAscending {
+				opts.MinValue = 1
+			} else {
+				opts.MinValue = math.MinInt64
+			}
+		case parser.SeqOptMaxValue:
+			// A nil value means NO MAXVALUE, i.e. the default.
+			if option.IntVal != nil {
+				opts.MaxValue = *option.IntVal
+			} else if isAscending {
+				opts.MaxValue = math.MaxInt64
+			} else {
+				opts.MaxValue = -1
+			}
+		case parser.SeqOptStart:
+			opts.Start = *option.IntVal
+			startSet = true
+		}
+	}
+
+	if setDefaults && !startSet {
+		if isAscending {
+			opts.Start = opts.MinValue
+		} else {
+			opts.Start = opts.MaxValue
+		}
+	}
+
+	if opts.MinValue >= opts.MaxValue {
+		return pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+			"MINVALUE (%d) must be less than MAXVALUE (%d)", opts.MinValue, opts.MaxValue)
+	}
+	if opts.Start < opts.MinValue {
+		return pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+			"START value (%d) cannot be less than MINVALUE (%d)", opts.Start, opts.MinValue)
+	}
+	if opts.Start > opts.MaxValue {
+		return pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+			"START value (%d) cannot be greater than MAXVALUE (%d)", opts.Start, opts.MaxValue)
+	}
+	return nil
+}
+
+type alterSequenceNode struct {
+	n       *parser.AlterSequence
+	seqDesc *sqlbase.TableDescriptor
+}
+
+// AlterSequence changes the options of a sequence.
+// Privileges: CREATE on sequence.
+//   notes: postgres requires the sequence owner.
+func (p *planner) AlterSequence(ctx context.Context, n *parser.AlterSequence) (planNode, error) {
+	tn, err := p.normalizeTableName(ctx, &n.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	seqDesc, err := getSequenceDesc(ctx, p.txn, p.getVirtualTabler(), tn)
+	if err != nil {
+		return nil, err
+	}
+	if seqDesc == nil {
+		if n.IfExists {
+			return &zeroNode{}, nil
+		}
+		return nil, sqlbase.NewUndefinedRelationError(tn)
+	}
+
+	if err := p.CheckPrivilege(ctx, seqDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+	return &alterSequenceNode{n: n, seqDesc: seqDesc}, nil
+}
+
+func (n *alterSequenceNode) Start(params runParams) error {
+	desc := n.seqDesc
+
+	if err := assignSequenceOptions(desc.SequenceOpts, n.n.Options, false /* setDefaults */); err != nil {
+		return err
+	}
+	if err := desc.SetUpVersion(); err != nil {
+		return err
+	}
+	if err := params.p.writeTableDesc(params.ctx, desc); err != nil {
+		return err
+	}
+
+	// Record this sequence alteration in the event log. This is an auditable
+	// log event and is recorded in the same transaction as the table descriptor
+	// update.
+	if err := MakeEventLogger(params.p.LeaseMgr()).InsertEventRecord(
+		params.ctx,
+		params.p.txn,
+		EventLogAlterSequence,
+		int32(desc.ID),
+		int32(params.p.evalCtx.NodeID),
+		struct {
+			SequenceName string
+			Statement    string
+			User         string
+		}{desc.Name, n.n.String(), params.p.session.User},
+	); err != nil {
+		return err
+	}
+
+	params.p.notifySchemaChange(desc, sqlbase.InvalidMutationID)
+
+	return nil
+}
+
+func (n *alterSequenceNode) Next(runParams) (bool, error) { return false, nil }
+func (n *alterSequenceNode) Close(context.Context)        {}
+func (n *alterSequenceNode) Values() parser.Datums        { return parser.Datums{} }
@@ -299,6 +299,10 @@ type Session struct {
 
 	tables TableCollection
 
+	// sequenceState stores state related to the sequences used in this
+	// session, for use by currval().
+	sequenceState sequenceState
+
 	// If set, contains the in progress COPY FROM columns.
 	copyFrom *copyNode
 
@@ -330,6 +334,11 @@ type Session struct {
 		// LastActiveQuery contains a reference to the AST of the last
 		// query that ran on this session.
 		LastActiveQuery parser.Statement
+
+		// TemporarySchemaName is the name of the database holding the
+		// temporary tables of this session, or empty if the session has not
+		// created any. It is read by the temporary schema sweeper.
+		TemporarySchemaName string
 	}
 
 	//
@@ -547,6 +556,14 @@ func (s *Session) Finish(e *Executor) {
 	// addressed, there might be leases accumulated by preparing statements.
 	s.tables.releaseTables(s.context)
 
+	// Drop the temporary tables of the session. If this fails, they are
+	// dropped later by the temporary schema sweeper.
+	if name := s.temporarySchemaName(); name != "" {
+		if err := dropTemporarySchema(s.context, e.cfg.DB, e.cfg.LeaseManager, name); err != nil {
+			log.Warningf(s.context, "error dropping temporary schema %s: %s", name, err)
+		}
+	}
+
 	s.ClearStatementsAndPortals(s.context)
 	s.sessionMon.Stop(s.context)
 	s.mon.Stop(s.context)
@@ -628,6 +645,7 @@ func (s *Session) resetPlanner(p *planner, e *Executor, txn *client.Txn) {
 	p.semaCtx = parser.MakeSemaContext(s.User == security.RootUser)
 	p.semaCtx.Location = &s.Location
 	p.semaCtx.SearchPath = s.SearchPath
+	p.semaCtx.Functions = p
 
 	p.evalCtx = s.evalCtx()
 	p.evalCtx.Planner = p
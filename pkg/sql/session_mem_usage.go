@@ -43,6 +43,12 @@ type WrappableMemoryAccount struct {
 	acc mon.BytesAccount
 }
 
+// CurrentlyAllocated returns the number of bytes currently allocated through
+// the account.
+func (w *WrappableMemoryAccount) CurrentlyAllocated() int64 {
+	return w.acc.CurrentlyAllocated()
+}
+
 // Wsession captures the current session monitor pointer so it can be provided
 // transparently to the other Account APIs below.
 func (w *WrappableMemoryAccount) Wsession(s *Session) WrappedMemoryAccount {
@@ -29,7 +29,6 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
-	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 )
 
 // setNode represents a SET SESSION statement.
@@ -392,16 +391,9 @@ func setTimeZone(_ context.Context, session *Session, values []parser.TypedExpr)
 	switch v := parser.UnwrapDatum(d).(type) {
 	case *parser.DString:
 		location := string(*v)
-		loc, err = timeutil.LoadLocation(location)
+		loc, err = parser.LoadLocation(location)
 		if err != nil {
-			var err1 error
-			loc, err1 = timeutil.LoadLocation(strings.ToUpper(location))
-			if err1 != nil {
-				loc, err1 = timeutil.LoadLocation(strings.ToTitle(location))
-				if err1 != nil {
-					return fmt.Errorf("cannot find time zone %q: %v", location, err)
-				}
-			}
+			return err
 		}
 
 	case *parser.DInterval:
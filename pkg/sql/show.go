@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/stats"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 )
@@ -205,7 +206,7 @@ func (p *planner) ShowColumns(ctx context.Context, n *parser.ShowColumns) (planN
 func (p *planner) showTableDetails(
 	ctx context.Context, showType string, t parser.NormalizableTableName, query string,
 ) (planNode, error) {
-	tn, err := t.NormalizeWithDatabaseName(p.session.Database)
+	tn, err := p.normalizeTableName(ctx, &t)
 	if err != nil {
 		return nil, err
 	}
@@ -219,7 +220,7 @@ func (p *planner) showTableDetails(
 		if err != nil {
 			return err
 		}
-		return p.anyPrivilege(desc)
+		return p.anyPrivilege(ctx, desc)
 	}
 
 	return p.delegateQuery(ctx, showType,
@@ -273,6 +274,28 @@ func (p *planner) ShowCreateView(ctx context.Context, n *parser.ShowCreateView)
 	return p.showTableDetails(ctx, "SHOW CREATE VIEW", n.View, showCreateViewQuery)
 }
 
+// ShowCreateSequence returns a CREATE SEQUENCE statement for the specified sequence.
+// Privileges: Any privilege on sequence.
+func (p *planner) ShowCreateSequence(
+	ctx context.Context, n *parser.ShowCreateSequence,
+) (planNode, error) {
+	// We make the check whether the name points to a sequence or not in
+	// SQL, so as to avoid a double lookup (a first one to check if the
+	// descriptor is of the right type, another to populate the
+	// create_statements vtable).
+	const showCreateSequenceQuery = `
+     SELECT %[3]s AS "Sequence",
+            IFNULL(create_statement,
+                   crdb_internal.force_error('` + pgerror.CodeUndefinedTableError + `',
+                                             %[1]s || '.' || %[2]s || ' is not a sequence')::string
+            ) AS "CreateSequence"
+       FROM (SELECT create_statement FROM %[4]s.crdb_internal.create_statements
+              WHERE database_name = %[1]s AND descriptor_name = %[2]s AND descriptor_type = 'sequence'
+              UNION ALL VALUES (NULL) ORDER BY 1 DESC) LIMIT 1
+  `
+	return p.showTableDetails(ctx, "SHOW CREATE SEQUENCE", n.Sequence, showCreateSequenceQuery)
+}
+
 // ShowTrace shows the current stored session trace.
 // Privileges: None.
 func (p *planner) ShowTrace(ctx context.Context, n *parser.ShowTrace) (planNode, error) {
@@ -518,7 +541,7 @@ func (p *planner) ShowIndex(ctx context.Context, n *parser.ShowIndex) (planNode,
 func (p *planner) ShowConstraints(
 	ctx context.Context, n *parser.ShowConstraints,
 ) (planNode, error) {
-	tn, err := n.Table.NormalizeWithDatabaseName(p.session.Database)
+	tn, err := p.normalizeTableName(ctx, &n.Table)
 	if err != nil {
 		return nil, err
 	}
@@ -527,7 +550,7 @@ func (p *planner) ShowConstraints(
 	if err != nil {
 		return nil, sqlbase.NewUndefinedRelationError(tn)
 	}
-	if err := p.anyPrivilege(desc); err != nil {
+	if err := p.anyPrivilege(ctx, desc); err != nil {
 		return nil, err
 	}
 
@@ -638,6 +661,91 @@ func (p *planner) ShowTables(ctx context.Context, n *parser.ShowTables) (planNod
 		initialCheck, nil)
 }
 
+// ShowTableStats returns the statistics collected for a table.
+// Privileges: Any privilege on table.
+//   Notes: the statistics are read from system.table_statistics as root.
+func (p *planner) ShowTableStats(ctx context.Context, n *parser.ShowTableStats) (planNode, error) {
+	tn, err := p.normalizeTableName(ctx, &n.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	desc, err := MustGetTableDesc(ctx, p.txn, p.getVirtualTabler(), tn, false /*allowAdding*/)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.anyPrivilege(ctx, desc); err != nil {
+		return nil, err
+	}
+
+	columns := sqlbase.ResultColumns{
+		{Name: "statistics_name", Typ: parser.TypeString},
+		{Name: "column_names", Typ: parser.TArray{Typ: parser.TypeString}},
+		{Name: "created", Typ: parser.TypeTimestamp},
+		{Name: "row_count", Typ: parser.TypeInt},
+		{Name: "distinct_count", Typ: parser.TypeInt},
+		{Name: "null_count", Typ: parser.TypeInt},
+		{Name: "histogram_buckets", Typ: parser.TypeInt},
+	}
+
+	return &delayedNode{
+		name:    "SHOW STATISTICS FOR TABLE " + tn.String(),
+		columns: columns,
+		constructor: func(ctx context.Context, p *planner) (planNode, error) {
+			const getTableStatisticsQuery = `
+				SELECT name, "columnIDs", "createdAt", "rowCount", "distinctCount", "nullCount", histogram
+				FROM system.table_statistics
+				WHERE "tableID" = $1
+				ORDER BY "createdAt"`
+			ie := InternalExecutor{LeaseManager: p.LeaseMgr()}
+			rows, err := ie.QueryRowsInTransaction(
+				ctx, "show-statistics", p.txn, getTableStatisticsQuery, desc.ID,
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			v := p.newContainerValuesNode(columns, 0)
+			for _, r := range rows {
+				columnNames := parser.NewDArray(parser.TypeString)
+				for _, d := range parser.MustBeDArray(r[1]).Array {
+					colName := fmt.Sprintf("<unknown column %d>", parser.MustBeDInt(d))
+					if col, err := desc.FindColumnByID(sqlbase.ColumnID(parser.MustBeDInt(d))); err == nil {
+						colName = col.Name
+					}
+					if err := columnNames.Append(parser.NewDString(colName)); err != nil {
+						v.Close(ctx)
+						return nil, err
+					}
+				}
+				histogramBuckets := parser.DNull
+				if r[6] != parser.DNull {
+					var h stats.HistogramData
+					if err := h.Unmarshal([]byte(*r[6].(*parser.DBytes))); err != nil {
+						v.Close(ctx)
+						return nil, err
+					}
+					histogramBuckets = parser.NewDInt(parser.DInt(len(h.Buckets)))
+				}
+				newRow := parser.Datums{
+					r[0],
+					columnNames,
+					r[2],
+					r[3],
+					r[4],
+					r[5],
+					histogramBuckets,
+				}
+				if _, err := v.rows.AddRow(ctx, newRow); err != nil {
+					v.Close(ctx)
+					return nil, err
+				}
+			}
+			return v, nil
+		},
+	}, nil
+}
+
 // ShowTransactionStatus implements the plan for SHOW TRANSACTION STATUS.
 // This statement is usually handled as a special case in Executor,
 // but for FROM [SHOW TRANSACTION STATUS] we will arrive here too.
@@ -649,5 +757,12 @@ func (p *planner) ShowTransactionStatus(ctx context.Context) (planNode, error) {
 // Privileges: SELECT on system.users.
 func (p *planner) ShowUsers(ctx context.Context, n *parser.ShowUsers) (planNode, error) {
 	return p.delegateQuery(ctx, "SHOW USERS",
-		`SELECT username FROM system.users ORDER BY 1`, nil, nil)
+		`SELECT username FROM system.users WHERE "isRole" = false ORDER BY 1`, nil, nil)
+}
+
+// ShowRoles returns all the roles.
+// Privileges: SELECT on system.users.
+func (p *planner) ShowRoles(ctx context.Context, n *parser.ShowRoles) (planNode, error) {
+	return p.delegateQuery(ctx, "SHOW ROLES",
+		`SELECT username AS role FROM system.users WHERE "isRole" = true ORDER BY 1`, nil, nil)
 }
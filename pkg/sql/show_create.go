@@ -30,19 +30,46 @@ func (p *planner) showCreateView(
 	ctx context.Context, tn parser.Name, desc *sqlbase.TableDescriptor,
 ) (string, error) {
 	var buf bytes.Buffer
-	buf.WriteString("CREATE VIEW ")
+	buf.WriteString("CREATE ")
+	if desc.IsMaterializedView() {
+		buf.WriteString("MATERIALIZED ")
+	}
+	buf.WriteString("VIEW ")
 	tn.Format(&buf, parser.FmtSimple)
 	buf.WriteString(" (")
-	for i, col := range desc.Columns {
-		if i > 0 {
+	first := true
+	for _, col := range desc.Columns {
+		// The hidden primary key of a materialized view is not part of its
+		// definition.
+		if col.Hidden {
+			continue
+		}
+		if !first {
 			buf.WriteString(", ")
 		}
+		first = false
 		parser.Name(col.Name).Format(&buf, parser.FmtSimple)
 	}
 	fmt.Fprintf(&buf, ") AS %s", desc.ViewQuery)
 	return buf.String(), nil
 }
 
+// showCreateSequence returns a valid SQL representation of the
+// CREATE SEQUENCE statement used to create the given sequence.
+func (p *planner) showCreateSequence(
+	ctx context.Context, tn parser.Name, desc *sqlbase.TableDescriptor,
+) (string, error) {
+	var buf bytes.Buffer
+	buf.WriteString("CREATE SEQUENCE ")
+	tn.Format(&buf, parser.FmtSimple)
+	opts := desc.SequenceOpts
+	fmt.Fprintf(&buf, " MINVALUE %d", opts.MinValue)
+	fmt.Fprintf(&buf, " MAXVALUE %d", opts.MaxValue)
+	fmt.Fprintf(&buf, " INCREMENT %d", opts.Increment)
+	fmt.Fprintf(&buf, " START %d", opts.Start)
+	return buf.String(), nil
+}
+
 // showCreateTable returns a valid SQL representation of the CREATE
 // TABLE statement used to create the given table.
 //
@@ -67,11 +94,12 @@ func (p *planner) showCreateTable(
 			// Only set primary if the primary key is on a visible column (not rowid).
 			primary = fmt.Sprintf(",\n\tCONSTRAINT %s PRIMARY KEY (%s)",
 				quoteNames(desc.PrimaryIndex.Name),
-				desc.PrimaryIndex.ColNamesString(),
+				desc.PrimaryIndex.ColNamesString(desc),
 			)
 		}
 	}
 	buf.WriteString(primary)
+	var a sqlbase.DatumAlloc
 	for _, idx := range append(desc.Indexes, desc.PrimaryIndex) {
 		if fk := idx.ForeignKey; fk.IsSet() {
 			fkTable, err := p.session.tables.getTableVersionByID(ctx, p.txn, fk.Table)
@@ -97,20 +125,33 @@ func (p *planner) showCreateTable(
 				&fkTableName,
 				quoteNames(fkIdx.ColumnNames...),
 			)
+			actions := parser.ReferenceActions{
+				Delete: referenceActionFromValue(fk.OnDelete),
+				Update: referenceActionFromValue(fk.OnUpdate),
+			}
+			actions.Format(&buf, parser.FmtSimple)
 		}
 		if idx.ID != desc.PrimaryIndex.ID {
 			// Showing the primary index is handled above.
-			fmt.Fprintf(&buf, ",\n\t%s", idx.SQLString(""))
+			fmt.Fprintf(&buf, ",\n\t%s", idx.SQLString(desc, ""))
 			// Showing the INTERLEAVE for the primary index is handled last.
 			if err := p.showCreateInterleave(ctx, &idx, &buf, dbPrefix); err != nil {
 				return "", err
 			}
+			if err := formatPartitioning(&buf, &a, desc, &idx); err != nil {
+				return "", err
+			}
 		}
 	}
 
 	for _, fam := range desc.Families {
 		activeColumnNames := make([]string, 0, len(fam.ColumnNames))
 		for i, colID := range fam.ColumnIDs {
+			if desc.IsIndexExprColumn(colID) {
+				// The columns storing indexed expressions are created along
+				// with their index.
+				continue
+			}
 			if _, err := desc.FindActiveColumnByID(colID); err == nil {
 				activeColumnNames = append(activeColumnNames, fam.ColumnNames[i])
 			}
@@ -134,6 +175,9 @@ func (p *planner) showCreateTable(
 	if err := p.showCreateInterleave(ctx, &desc.PrimaryIndex, &buf, dbPrefix); err != nil {
 		return "", err
 	}
+	if err := formatPartitioning(&buf, &a, desc, &desc.PrimaryIndex); err != nil {
+		return "", err
+	}
 
 	return buf.String(), nil
 }
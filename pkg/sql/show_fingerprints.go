@@ -45,7 +45,7 @@ import (
 func (p *planner) ShowFingerprints(
 	ctx context.Context, n *parser.ShowFingerprints,
 ) (planNode, error) {
-	tn, err := n.Table.NormalizeWithDatabaseName(p.session.Database)
+	tn, err := p.normalizeTableName(ctx, &n.Table)
 	if err != nil {
 		return nil, err
 	}
@@ -56,7 +56,7 @@ func (p *planner) ShowFingerprints(
 		return nil, err
 	}
 
-	if err := p.CheckPrivilege(tableDesc, privilege.SELECT); err != nil {
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege.SELECT); err != nil {
 		return nil, err
 	}
 
@@ -478,6 +478,13 @@ func (n *sortNode) Next(params runParams) (bool, error) {
 	return n.valueIter.Next(params)
 }
 
+func (n *sortNode) memUsage() int64 {
+	if n.sortStrategy == nil {
+		return 0
+	}
+	return n.sortStrategy.memUsage()
+}
+
 func (n *sortNode) Close(ctx context.Context) {
 	n.plan.Close(ctx)
 	if n.sortStrategy != nil {
@@ -507,6 +514,9 @@ type sortingStrategy interface {
 	// not be called more than once, and should only be called after all Add
 	// calls have occurred.
 	Finish(context.Context, *sqlbase.CancelChecker)
+	// memUsage returns the number of bytes of memory used by the values
+	// stored by the sortingStrategy.
+	memUsage() int64
 }
 
 // sortAllStrategy reads in all values into the wrapped valuesNode and
@@ -545,6 +555,10 @@ func (ss *sortAllStrategy) Close(ctx context.Context) {
 	ss.vNode.Close(ctx)
 }
 
+func (ss *sortAllStrategy) memUsage() int64 {
+	return ss.vNode.memUsage()
+}
+
 // iterativeSortStrategy reads in all values into the wrapped valuesNode
 // and turns the underlying slice into a min-heap. It then pops a value
 // off of the heap for each call to Next, meaning that it only needs to
@@ -593,6 +607,10 @@ func (ss *iterativeSortStrategy) Close(ctx context.Context) {
 	ss.vNode.Close(ctx)
 }
 
+func (ss *iterativeSortStrategy) memUsage() int64 {
+	return ss.vNode.memUsage()
+}
+
 // sortTopKStrategy creates a max-heap in its wrapped valuesNode and keeps
 // this heap populated with only the top k values seen. It accomplishes this
 // by comparing new values (before the deep copy) with the top of the heap.
@@ -667,6 +685,10 @@ func (ss *sortTopKStrategy) Close(ctx context.Context) {
 	ss.vNode.Close(ctx)
 }
 
+func (ss *sortTopKStrategy) memUsage() int64 {
+	return ss.vNode.memUsage()
+}
+
 // TODO(pmattis): If the result set is large, we might need to perform the
 // sort on disk. There is no point in doing this while we're buffering the
 // entire result set in memory. If/when we start streaming results back to
@@ -0,0 +1,90 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// spoolNode ensures that its source is run to completion during
+// Start, regardless of how many rows are later consumed. The result
+// rows are buffered in memory.
+//
+// This is used for data-modifying common table expressions, whose
+// side effects must be carried out entirely even when the enclosing
+// query does not read all their rows.
+type spoolNode struct {
+	source planNode
+	rows   *sqlbase.RowContainer
+	curRow int
+}
+
+func (s *spoolNode) Start(params runParams) error {
+	if err := s.source.Start(params); err != nil {
+		return err
+	}
+
+	s.rows = sqlbase.NewRowContainer(
+		params.p.session.TxnState.makeBoundAccount(),
+		sqlbase.ColTypeInfoFromResCols(planColumns(s.source)),
+		0,
+	)
+	for {
+		if err := params.p.cancelChecker.Check(); err != nil {
+			return err
+		}
+		next, err := s.source.Next(params)
+		if err != nil {
+			return err
+		}
+		if !next {
+			break
+		}
+		if _, err := s.rows.AddRow(params.ctx, s.source.Values()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *spoolNode) Next(params runParams) (bool, error) {
+	if s.curRow >= s.rows.Len() {
+		return false, nil
+	}
+	s.curRow++
+	return true, nil
+}
+
+func (s *spoolNode) Values() parser.Datums {
+	return s.rows.At(s.curRow - 1)
+}
+
+func (s *spoolNode) memUsage() int64 {
+	if s.rows == nil {
+		return 0
+	}
+	return s.rows.MemUsage()
+}
+
+func (s *spoolNode) Close(ctx context.Context) {
+	s.source.Close(ctx)
+	if s.rows != nil {
+		s.rows.Close(ctx)
+		s.rows = nil
+	}
+}
@@ -0,0 +1,411 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sqlbase
+
+import (
+	"sort"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+)
+
+// maxCascadeDepth is the maximum number of tables a chain of referential
+// actions can go through, starting from the table modified by the statement.
+const maxCascadeDepth = 32
+
+// isCascadingAction returns true if the referential action modifies the
+// referencing rows, as opposed to rejecting the write.
+func isCascadingAction(action ForeignKeyReference_Action) bool {
+	switch action {
+	case ForeignKeyReference_CASCADE,
+		ForeignKeyReference_SET_NULL,
+		ForeignKeyReference_SET_DEFAULT:
+		return true
+	}
+	return false
+}
+
+// cascadeState is shared by all the fkCascaders involved in the referential
+// actions triggered by the rows of a single row writer.
+type cascadeState struct {
+	// path contains the primary index keys of the rows whose referencing rows
+	// are currently being modified. Reaching one of these rows again means
+	// the foreign keys form a cycle.
+	path map[string]struct{}
+}
+
+// fkCascader carries out the referential action of a foreign key on the
+// referencing rows when a referenced row is deleted or updated. The
+// referencing rows are modified right away in the transaction of the
+// statement, so that the following reads, including the FK checks of the
+// rows modified later, observe the changes.
+type fkCascader struct {
+	txn         *client.Txn
+	otherTables TableLookupsByID
+	evalCtx     *parser.EvalContext
+	alloc       *DatumAlloc
+
+	action ForeignKeyReference_Action
+	// onDelete is set if the action is triggered by deletions rather than
+	// updates of the referenced rows.
+	onDelete bool
+
+	// table and idx are the referencing table and the index holding the FK.
+	table *TableDescriptor
+	idx   *IndexDescriptor
+	// writeTable is the referenced table, and writeColMap maps its column IDs
+	// to the positions in the rows passed to cascade.
+	writeTable  *TableDescriptor
+	writeColMap map[ColumnID]int
+
+	state *cascadeState
+	// depth is the number of cascaders preceding this one in the chain of
+	// referential actions.
+	depth int
+
+	// The following fields are set up by init on the first use.
+	initialized bool
+	// Exactly one of rd and ru is used, depending on whether the referencing
+	// rows are deleted or updated.
+	rd            RowDeleter
+	ru            RowUpdater
+	fetchCols     []ColumnDescriptor
+	fetchColIdx   map[ColumnID]int
+	primaryPrefix []byte
+	// rowFetcher retrieves the referencing rows from the primary index.
+	rowFetcher RowFetcher
+	// idxFetcher retrieves the primary keys of the referencing rows when idx
+	// is a secondary index.
+	idxFetcher   RowFetcher
+	idxColIdx    map[ColumnID]int
+	defaultExprs []parser.TypedExpr
+	updateValues parser.Datums
+}
+
+func (c *fkCascader) deletesRows() bool {
+	return c.onDelete && c.action == ForeignKeyReference_CASCADE
+}
+
+func (c *fkCascader) init(fk *baseFKHelper) error {
+	if c.deletesRows() {
+		var err error
+		c.rd, err = MakeRowDeleter(
+			c.txn, c.table, c.otherTables, c.table.Columns, CheckFKs, c.evalCtx, c.alloc,
+		)
+		if err != nil {
+			return err
+		}
+		c.rd.Fks.setCascadeState(c.state, c.depth+1)
+		c.fetchCols, c.fetchColIdx = c.rd.FetchCols, c.rd.FetchColIDtoRowIndex
+	} else {
+		updateCols := make([]ColumnDescriptor, fk.prefixLen)
+		for i, colID := range c.idx.ColumnIDs[:fk.prefixLen] {
+			col, err := c.table.FindColumnByID(colID)
+			if err != nil {
+				return err
+			}
+			updateCols[i] = *col
+		}
+		var err error
+		c.ru, err = MakeRowUpdater(
+			c.txn, c.table, c.otherTables, updateCols, c.table.Columns,
+			RowUpdaterDefault, c.evalCtx, c.alloc,
+		)
+		if err != nil {
+			return err
+		}
+		c.ru.Fks.inbound.setCascadeState(c.state, c.depth+1)
+		if c.action == ForeignKeyReference_CASCADE {
+			// The new values of the referencing columns are those of the
+			// referenced row, which is only written once the referencing rows
+			// have been updated.
+			delete(c.ru.Fks.outbound, c.idx.ID)
+		}
+		if c.action == ForeignKeyReference_SET_DEFAULT {
+			c.defaultExprs, err = MakeDefaultExprs(updateCols, &parser.Parser{}, c.evalCtx)
+			if err != nil {
+				return err
+			}
+		}
+		c.updateValues = make(parser.Datums, len(updateCols))
+		c.fetchCols, c.fetchColIdx = c.ru.FetchCols, c.ru.FetchColIDtoRowIndex
+	}
+
+	c.primaryPrefix = MakeIndexKeyPrefix(c.table, c.table.PrimaryIndex.ID)
+	needed := make([]bool, len(c.fetchCols))
+	for i := range needed {
+		needed[i] = true
+	}
+	if err := c.rowFetcher.Init(c.table, c.fetchColIdx, &c.table.PrimaryIndex,
+		false /* reverse */, false /* isSecondaryIndex */, c.fetchCols, needed,
+		false /* returnRangeInfo */, c.alloc); err != nil {
+		return err
+	}
+
+	if c.idx.ID != c.table.PrimaryIndex.ID {
+		c.idxColIdx = ColIDtoRowIndexFromCols(c.table.Columns)
+		needed := make([]bool, len(c.table.Columns))
+		for _, colID := range c.table.PrimaryIndex.ColumnIDs {
+			needed[c.idxColIdx[colID]] = true
+		}
+		if err := c.idxFetcher.Init(c.table, c.idxColIdx, c.idx,
+			false /* reverse */, true /* isSecondaryIndex */, c.table.Columns, needed,
+			false /* returnRangeInfo */, c.alloc); err != nil {
+			return err
+		}
+	}
+
+	c.initialized = true
+	return nil
+}
+
+// cascade carries out the referential action on the rows referencing the
+// given row of the referenced table. newRow contains the new values of the
+// row when it is being updated, and is nil when it is being deleted.
+func (c *fkCascader) cascade(ctx context.Context, fk *baseFKHelper, row, newRow parser.Datums) error {
+	changed := newRow == nil
+	for _, colID := range fk.searchIdx.ColumnIDs[:fk.prefixLen] {
+		i := fk.ids[colID]
+		if row[i] == parser.DNull {
+			// The referencing rows cannot match a NULL value.
+			return nil
+		}
+		if !changed && row[i].Compare(c.evalCtx, newRow[i]) != 0 {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	rowKey, _, err := EncodeIndexKey(c.writeTable, &c.writeTable.PrimaryIndex, c.writeColMap,
+		row, MakeIndexKeyPrefix(c.writeTable, c.writeTable.PrimaryIndex.ID))
+	if err != nil {
+		return err
+	}
+	if _, ok := c.state.path[string(rowKey)]; !ok {
+		c.state.path[string(rowKey)] = struct{}{}
+		defer delete(c.state.path, string(rowKey))
+	}
+
+	if !c.initialized {
+		if err := c.init(fk); err != nil {
+			return err
+		}
+	}
+
+	refRows, err := c.fetchReferencingRows(ctx, fk, row)
+	if err != nil {
+		return err
+	}
+	if len(refRows) == 0 {
+		return nil
+	}
+	if c.depth >= maxCascadeDepth {
+		return pgerror.NewErrorf(pgerror.CodeProgramLimitExceededError,
+			"foreign key cascade exceeded the maximum depth of %d at table %q",
+			maxCascadeDepth, c.table.Name)
+	}
+
+	for _, refRow := range refRows {
+		key, _, err := EncodeIndexKey(
+			c.table, &c.table.PrimaryIndex, c.fetchColIdx, refRow, c.primaryPrefix)
+		if err != nil {
+			return err
+		}
+		if _, ok := c.state.path[string(key)]; ok {
+			if newRow == nil {
+				// The referencing row is already being deleted further up in
+				// the chain of referential actions.
+				continue
+			}
+			return pgerror.NewErrorf(pgerror.CodeTriggeredDataChangeViolationError,
+				"foreign key cascade would modify row %v of table %q more than once",
+				refRow, c.table.Name)
+		}
+
+		b := c.txn.NewBatch()
+		if c.deletesRows() {
+			if err := c.rd.DeleteRow(ctx, b, refRow, false /* traceKV */); err != nil {
+				return err
+			}
+		} else {
+			if err := c.fillUpdateValues(fk, row, newRow); err != nil {
+				return err
+			}
+			if _, err := c.ru.UpdateRow(ctx, b, refRow, c.updateValues, false /* traceKV */); err != nil {
+				return err
+			}
+		}
+		// Each referencing row is written on its own, so that the referential
+		// actions on the following rows observe the changes.
+		if err := c.txn.Run(ctx, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fillUpdateValues computes the new values of the referencing columns.
+func (c *fkCascader) fillUpdateValues(fk *baseFKHelper, row, newRow parser.Datums) error {
+	switch c.action {
+	case ForeignKeyReference_SET_NULL:
+		for i := range c.updateValues {
+			c.updateValues[i] = parser.DNull
+		}
+
+	case ForeignKeyReference_SET_DEFAULT:
+		unchanged := true
+		for i := range c.updateValues {
+			c.updateValues[i] = parser.DNull
+			if c.defaultExprs != nil {
+				d, err := c.defaultExprs[i].Eval(c.evalCtx)
+				if err != nil {
+					return err
+				}
+				c.updateValues[i] = d
+			}
+			old := row[fk.ids[c.idx.ColumnIDs[i]]]
+			unchanged = unchanged && c.updateValues[i].Compare(c.evalCtx, old) == 0
+		}
+		if unchanged {
+			// The default values reference the row that is going away.
+			return pgerror.NewErrorf(pgerror.CodeForeignKeyViolationError,
+				"foreign key violation: default values %v in columns %s reference the modified row of table %q",
+				c.updateValues, c.idx.ColumnNames[:fk.prefixLen], c.writeTable.Name)
+		}
+
+	case ForeignKeyReference_CASCADE:
+		for i, colID := range c.idx.ColumnIDs[:fk.prefixLen] {
+			c.updateValues[i] = newRow[fk.ids[colID]]
+		}
+	}
+	return nil
+}
+
+// fetchReferencingRows returns the rows of the referencing table that match
+// the given row of the referenced table.
+func (c *fkCascader) fetchReferencingRows(
+	ctx context.Context, fk *baseFKHelper, row parser.Datums,
+) ([]parser.Datums, error) {
+	span, err := fk.spanForValues(row)
+	if err != nil {
+		return nil, err
+	}
+	spans := roachpb.Spans{span}
+
+	if c.idx.ID != c.table.PrimaryIndex.ID {
+		// Look up the primary keys of the referencing rows in the secondary
+		// index first.
+		if err := c.idxFetcher.StartScan(ctx, c.txn, spans, false /* limitBatches */, 0); err != nil {
+			return nil, err
+		}
+		spans = nil
+		for {
+			idxRow, err := c.idxFetcher.NextRowDecoded(ctx, false /* traceKV */)
+			if err != nil {
+				return nil, err
+			}
+			if idxRow == nil {
+				break
+			}
+			key, _, err := EncodeIndexKey(
+				c.table, &c.table.PrimaryIndex, c.idxColIdx, idxRow, c.primaryPrefix)
+			if err != nil {
+				return nil, err
+			}
+			spans = append(spans, roachpb.Span{Key: key, EndKey: roachpb.Key(key).PrefixEnd()})
+		}
+		if len(spans) == 0 {
+			return nil, nil
+		}
+		sort.Sort(spans)
+	}
+
+	// The rows are collected before any of them is modified, since the
+	// referential actions on a row can modify the others.
+	if err := c.rowFetcher.StartScan(ctx, c.txn, spans, false /* limitBatches */, 0); err != nil {
+		return nil, err
+	}
+	var rows []parser.Datums
+	for {
+		r, err := c.rowFetcher.NextRowDecoded(ctx, false /* traceKV */)
+		if err != nil {
+			return nil, err
+		}
+		if r == nil {
+			break
+		}
+		rows = append(rows, append(parser.Datums(nil), r...))
+	}
+	return rows, nil
+}
+
+// collectCascadeSpansWithFKMap returns the spans of the tables that may be
+// modified by the referential actions of the given helpers, directly or
+// through further referential actions, along with the spans of the tables
+// checked for FK violations when these tables are modified.
+func collectCascadeSpansWithFKMap(fks map[IndexID][]baseFKHelper) roachpb.Spans {
+	var spans roachpb.Spans
+	added := make(map[ID]struct{})
+	addTable := func(table *TableDescriptor) {
+		if _, ok := added[table.ID]; !ok {
+			added[table.ID] = struct{}{}
+			spans = append(spans, table.AllIndexSpans()...)
+		}
+	}
+	visited := make(map[ID]struct{})
+	var visit func(table *TableDescriptor, otherTables TableLookupsByID)
+	visit = func(table *TableDescriptor, otherTables TableLookupsByID) {
+		if _, ok := visited[table.ID]; ok {
+			return
+		}
+		visited[table.ID] = struct{}{}
+		addTable(table)
+		for id := range TablesNeededForFKs(*table, CheckUpdates) {
+			if other := otherTables[id].Table; other != nil {
+				addTable(other)
+			}
+		}
+		for _, idx := range table.AllNonDropIndexes() {
+			for _, ref := range idx.ReferencedBy {
+				other := otherTables[ref.Table].Table
+				if other == nil {
+					continue
+				}
+				refIdx, err := other.FindIndexByID(ref.Index)
+				if err != nil {
+					continue
+				}
+				if isCascadingAction(refIdx.ForeignKey.OnDelete) ||
+					isCascadingAction(refIdx.ForeignKey.OnUpdate) {
+					visit(other, otherTables)
+				}
+			}
+		}
+	}
+	for idx := range fks {
+		for _, fk := range fks[idx] {
+			if fk.cascader != nil {
+				visit(fk.cascader.table, fk.cascader.otherTables)
+			}
+		}
+	}
+	return spans
+}
@@ -0,0 +1,465 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sqlbase
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+)
+
+// ColumnConverter computes the values of a column from the values of other
+// columns of the same row. It is used for the columns being added by
+// ALTER COLUMN ... TYPE, which are computed from the column they replace, and
+// for the hidden columns storing the values of an indexed expression.
+type ColumnConverter struct {
+	// Col is the column being computed. For a conversion, it carries the name
+	// of the column being converted, which is the one errors should refer to.
+	Col ColumnDescriptor
+	// SourceColumnIDs are the IDs of the columns the value of Col is computed
+	// from.
+	SourceColumnIDs []ColumnID
+
+	tableDesc  *TableDescriptor
+	expr       parser.TypedExpr
+	ivarHelper parser.IndexedVarHelper
+
+	// The row being converted, valid during a call to Convert.
+	row             parser.Datums
+	colIDtoRowIndex map[ColumnID]int
+}
+
+var _ parser.IndexedVarContainer = &ColumnConverter{}
+
+// MakeColumnConverter builds the converter for col, which is being added by
+// a mutation carrying the given conversion.
+func MakeColumnConverter(
+	tableDesc *TableDescriptor, col ColumnDescriptor, conv *DescriptorMutation_ColumnConversion,
+) (*ColumnConverter, error) {
+	source, err := tableDesc.FindColumnByID(conv.SourceColumnID)
+	if err != nil {
+		return nil, err
+	}
+	col.Name = source.Name
+	return makeColumnConverter(tableDesc, col, conv.Expr, "USING")
+}
+
+// MakeIndexExprConverter builds the converter for col, the hidden column
+// storing the values of the serialized index expression expr.
+func MakeIndexExprConverter(
+	tableDesc *TableDescriptor, col ColumnDescriptor, expr string,
+) (*ColumnConverter, error) {
+	return makeColumnConverter(tableDesc, col, expr, "index expression")
+}
+
+func makeColumnConverter(
+	tableDesc *TableDescriptor, col ColumnDescriptor, exprStr string, context string,
+) (*ColumnConverter, error) {
+	c, expr, err := bindColumnExpr(tableDesc, exprStr)
+	if err != nil {
+		return nil, err
+	}
+	c.Col = col
+	c.expr, err = parser.TypeCheckAndRequire(expr, nil, col.Type.ToDatumType(), context)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// bindColumnExpr parses the serialized expression, which references the
+// columns of tableDesc by ID (@1 being the column with ID 1), and binds its
+// variables to a new converter. The index of each variable is thus the ID of
+// its column minus one, not the position of the column in tableDesc.Columns.
+func bindColumnExpr(
+	tableDesc *TableDescriptor, exprStr string,
+) (*ColumnConverter, parser.Expr, error) {
+	c := &ColumnConverter{tableDesc: tableDesc}
+	c.ivarHelper = parser.MakeIndexedVarHelper(c, int(tableDesc.NextColumnID))
+
+	expr, err := parser.ParseExpr(exprStr)
+	if err != nil {
+		return nil, nil, err
+	}
+	expr, err = parser.SimpleVisit(expr, func(expr parser.Expr) (error, bool, parser.Expr) {
+		if ivar, ok := expr.(*parser.IndexedVar); ok {
+			newVar, err := c.ivarHelper.BindIfUnbound(ivar)
+			if err != nil {
+				return err, false, expr
+			}
+			c.addSourceColumn(ColumnID(ivar.Idx + 1))
+			return nil, false, newVar
+		}
+		return nil, true, expr
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, expr, nil
+}
+
+// TypeCheckIndexExpr type checks the serialized index expression expr, which
+// references the columns of tableDesc by ID. The values of an indexed
+// expression must only depend on the row, so impure functions are rejected.
+func TypeCheckIndexExpr(tableDesc *TableDescriptor, expr string) (parser.TypedExpr, error) {
+	_, bound, err := bindColumnExpr(tableDesc, expr)
+	if err != nil {
+		return nil, err
+	}
+	typedExpr, err := parser.TypeCheck(bound, nil, parser.TypeAny)
+	if err != nil {
+		return nil, err
+	}
+	_, err = parser.SimpleVisit(typedExpr, func(expr parser.Expr) (error, bool, parser.Expr) {
+		if f, ok := expr.(*parser.FuncExpr); ok && f.IsImpure() {
+			return pgerror.NewErrorf(pgerror.CodeInvalidObjectDefinitionError,
+				"impure functions are not allowed in index expressions: %s", f.Func), false, expr
+		}
+		return nil, true, expr
+	})
+	if err != nil {
+		return nil, err
+	}
+	return typedExpr, nil
+}
+
+// FormatColumnExpr formats the serialized expression, which references the
+// columns of desc by ID, using the names of the columns.
+func (desc *TableDescriptor) FormatColumnExpr(exprStr string) string {
+	_, expr, err := bindColumnExpr(desc, exprStr)
+	if err != nil {
+		return exprStr
+	}
+	return parser.AsStringWithFlags(expr, parser.FmtSimple)
+}
+
+// ColumnExprSourceIDs returns the IDs of the columns of desc referenced by
+// the serialized expression.
+func (desc *TableDescriptor) ColumnExprSourceIDs(exprStr string) ([]ColumnID, error) {
+	c, _, err := bindColumnExpr(desc, exprStr)
+	if err != nil {
+		return nil, err
+	}
+	return c.SourceColumnIDs, nil
+}
+
+// IsIndexExprColumn returns whether the column stores the values of an
+// expression indexed by one of the indexes of desc.
+func (desc *TableDescriptor) IsIndexExprColumn(colID ColumnID) bool {
+	for _, idx := range desc.AllNonDropIndexes() {
+		for i, expr := range idx.Expressions {
+			if expr != "" && idx.ColumnIDs[i] == colID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *ColumnConverter) addSourceColumn(id ColumnID) {
+	for _, existing := range c.SourceColumnIDs {
+		if existing == id {
+			return
+		}
+	}
+	c.SourceColumnIDs = append(c.SourceColumnIDs, id)
+}
+
+// MakeColumnConverters returns the converters of the columns in cols that are
+// being added by a conversion or that store the values of an index
+// expression, keyed by the ID of the computed column.
+func MakeColumnConverters(
+	tableDesc *TableDescriptor, cols []ColumnDescriptor,
+) (map[ColumnID]*ColumnConverter, error) {
+	var converters map[ColumnID]*ColumnConverter
+	add := func(col ColumnDescriptor, conv *ColumnConverter, err error) error {
+		if err != nil {
+			return err
+		}
+		if converters == nil {
+			converters = make(map[ColumnID]*ColumnConverter)
+		}
+		converters[col.ID] = conv
+		return nil
+	}
+	findCol := func(id ColumnID) (ColumnDescriptor, bool) {
+		for _, c := range cols {
+			if c.ID == id {
+				return c, true
+			}
+		}
+		return ColumnDescriptor{}, false
+	}
+	for _, m := range tableDesc.Mutations {
+		col := m.GetColumn()
+		if m.Conversion == nil || col == nil || m.Direction != DescriptorMutation_ADD {
+			continue
+		}
+		if _, ok := findCol(col.ID); ok {
+			conv, err := MakeColumnConverter(tableDesc, *col, m.Conversion)
+			if err := add(*col, conv, err); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, idx := range tableDesc.AllNonDropIndexes() {
+		for i, expr := range idx.Expressions {
+			if expr == "" {
+				continue
+			}
+			if col, ok := findCol(idx.ColumnIDs[i]); ok {
+				if _, ok := converters[col.ID]; ok {
+					continue
+				}
+				conv, err := MakeIndexExprConverter(tableDesc, col, expr)
+				if err := add(col, conv, err); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return converters, nil
+}
+
+// OrderColumnConverters returns the IDs of the columns computed by
+// converters, in an order in which they can be computed: a column computed
+// from other computed columns, such as the hidden column of an index
+// expression over a column being converted, comes after them.
+func OrderColumnConverters(converters map[ColumnID]*ColumnConverter) []ColumnID {
+	pending := make([]ColumnID, 0, len(converters))
+	for colID := range converters {
+		pending = append(pending, colID)
+	}
+	sort.Sort(columnIDs(pending))
+
+	order := make([]ColumnID, 0, len(converters))
+	done := make(map[ColumnID]struct{}, len(converters))
+	for len(pending) > 0 {
+		next := pending[:0]
+		for _, colID := range pending {
+			ready := true
+			for _, sourceID := range converters[colID].SourceColumnIDs {
+				if _, ok := converters[sourceID]; !ok || sourceID == colID {
+					continue
+				}
+				if _, ok := done[sourceID]; !ok {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				order = append(order, colID)
+				done[colID] = struct{}{}
+			} else {
+				next = append(next, colID)
+			}
+		}
+		if len(next) == len(pending) {
+			// The expressions of computed columns can't reference each other
+			// in a cycle, but don't loop forever if they somehow do.
+			panic(fmt.Sprintf("cycle between the computed columns %v", next))
+		}
+		pending = next
+	}
+	return order
+}
+
+// ConversionCheck evaluates a CHECK constraint referencing a column being
+// converted by ALTER COLUMN ... TYPE against the new column, which will
+// replace the old one in the constraint once the conversion completes. The
+// values of the new column are thus checked as they are written, so that the
+// conversion fails instead of leaving the table with values violating the
+// constraint.
+type ConversionCheck struct {
+	// Name is the name of the constraint.
+	Name string
+	// Expr is the expression of the constraint, as declared.
+	Expr string
+
+	eval *ColumnConverter
+}
+
+// MakeConversionChecks returns the checks of the constraints that reference
+// the columns being converted into the columns in cols.
+func MakeConversionChecks(
+	tableDesc *TableDescriptor, cols []ColumnDescriptor,
+) ([]ConversionCheck, error) {
+	// The columns replacing the ones being converted, by name of the column
+	// they replace.
+	var replaced map[string]ColumnID
+	for _, m := range tableDesc.Mutations {
+		col := m.GetColumn()
+		if m.Conversion == nil || col == nil || m.Direction != DescriptorMutation_ADD {
+			continue
+		}
+		for _, c := range cols {
+			if c.ID != col.ID {
+				continue
+			}
+			source, err := tableDesc.FindColumnByID(m.Conversion.SourceColumnID)
+			if err != nil {
+				return nil, err
+			}
+			if replaced == nil {
+				replaced = make(map[string]ColumnID)
+			}
+			replaced[source.Name] = col.ID
+		}
+	}
+	if replaced == nil {
+		return nil, nil
+	}
+
+	var checks []ConversionCheck
+	boolCol := ColumnDescriptor{Type: ColumnType{SemanticType: ColumnType_BOOL}, Nullable: true}
+	for _, check := range tableDesc.Checks {
+		exprStr, ok, err := tableDesc.conversionCheckExpr(check.Expr, replaced)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		boolCol.Name = check.Name
+		eval, err := makeColumnConverter(tableDesc, boolCol, exprStr, "CHECK")
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, ConversionCheck{Name: check.Name, Expr: check.Expr, eval: eval})
+	}
+	return checks, nil
+}
+
+// conversionCheckExpr serializes the expression of a CHECK constraint, which
+// references the active columns of desc by name, into an expression
+// referencing them by ID, with the columns being converted replaced by the
+// new columns in replaced. It returns false if the constraint doesn't
+// reference any of the columns being converted.
+func (desc *TableDescriptor) conversionCheckExpr(
+	exprStr string, replaced map[string]ColumnID,
+) (string, bool, error) {
+	expr, err := parser.ParseExpr(exprStr)
+	if err != nil {
+		return "", false, err
+	}
+	found := false
+	expr, err = parser.SimpleVisit(expr, func(expr parser.Expr) (error, bool, parser.Expr) {
+		vBase, ok := expr.(parser.VarName)
+		if !ok {
+			return nil, true, expr
+		}
+		v, err := vBase.NormalizeVarName()
+		if err != nil {
+			return err, false, nil
+		}
+		c, ok := v.(*parser.ColumnItem)
+		if !ok {
+			return nil, true, expr
+		}
+		if id, ok := replaced[string(c.ColumnName)]; ok {
+			found = true
+			return nil, false, parser.NewOrdinalReference(int(id) - 1)
+		}
+		col, err := desc.FindActiveColumnByName(string(c.ColumnName))
+		if err != nil {
+			return err, false, nil
+		}
+		return nil, false, parser.NewOrdinalReference(int(col.ID) - 1)
+	})
+	if err != nil || !found {
+		return "", false, err
+	}
+	return parser.Serialize(expr), true, nil
+}
+
+// SourceColumnIDs returns the IDs of the columns the constraint references.
+func (c *ConversionCheck) SourceColumnIDs() []ColumnID {
+	return c.eval.SourceColumnIDs
+}
+
+// Check evaluates the constraint on a row. colIDtoRowIndex maps the IDs of
+// the columns referenced by the constraint to their position in row.
+func (c *ConversionCheck) Check(
+	evalCtx *parser.EvalContext, colIDtoRowIndex map[ColumnID]int, row parser.Datums,
+) error {
+	d, err := c.eval.Convert(evalCtx, colIDtoRowIndex, row)
+	if err != nil {
+		return err
+	}
+	if res, err := parser.GetBool(d); err != nil {
+		return err
+	} else if !res && d != parser.DNull {
+		return pgerror.NewErrorf(pgerror.CodeCheckViolationError,
+			"failed to satisfy CHECK constraint (%s)", c.Expr)
+	}
+	return nil
+}
+
+// Convert computes the value of the new column for a row. colIDtoRowIndex
+// maps the IDs of the columns referenced by the conversion to their position
+// in row.
+func (c *ColumnConverter) Convert(
+	evalCtx *parser.EvalContext, colIDtoRowIndex map[ColumnID]int, row parser.Datums,
+) (parser.Datum, error) {
+	c.row, c.colIDtoRowIndex = row, colIDtoRowIndex
+	val, err := c.expr.Eval(evalCtx)
+	c.row, c.colIDtoRowIndex = nil, nil
+	if err != nil {
+		return nil, err
+	}
+	if val == parser.DNull {
+		if !c.Col.Nullable {
+			return nil, NewNonNullViolationError(c.Col.Name)
+		}
+		return val, nil
+	}
+	if err := CheckValueWidth(c.Col, val); err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+// IndexedVarEval implements the parser.IndexedVarContainer interface.
+func (c *ColumnConverter) IndexedVarEval(idx int, ctx *parser.EvalContext) (parser.Datum, error) {
+	rowIdx, ok := c.colIDtoRowIndex[ColumnID(idx+1)]
+	if !ok {
+		// The column was omitted from an INSERT.
+		return parser.DNull, nil
+	}
+	return c.row[rowIdx].Eval(ctx)
+}
+
+// IndexedVarResolvedType implements the parser.IndexedVarContainer interface.
+func (c *ColumnConverter) IndexedVarResolvedType(idx int) parser.Type {
+	col, err := c.tableDesc.FindColumnByID(ColumnID(idx + 1))
+	if err != nil {
+		panic(err)
+	}
+	return col.Type.ToDatumType()
+}
+
+// IndexedVarFormat implements the parser.IndexedVarContainer interface.
+func (c *ColumnConverter) IndexedVarFormat(buf *bytes.Buffer, f parser.FmtFlags, idx int) {
+	if col, err := c.tableDesc.FindColumnByID(ColumnID(idx + 1)); err == nil {
+		parser.FormatNode(buf, f, parser.Name(col.Name))
+		return
+	}
+	buf.WriteByte('@')
+	buf.WriteString(strconv.Itoa(idx + 1))
+}
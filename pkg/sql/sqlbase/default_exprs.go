@@ -94,15 +94,35 @@ func ProcessDefaultColumns(
 		}
 	}
 
-	// Add any column that has a DEFAULT expression.
+	// Add the column if its values are computed by the RowInserter.
+	addComputed := func(col ColumnDescriptor) {
+		if _, ok := colIDSet[col.ID]; !ok {
+			colIDSet[col.ID] = struct{}{}
+			cols = append(cols, col)
+		}
+	}
+
+	// Add any column that has a DEFAULT expression or stores an indexed
+	// expression.
 	for _, col := range tableDesc.Columns {
+		if tableDesc.IsIndexExprColumn(col.ID) {
+			addComputed(col)
+			continue
+		}
 		addIfDefault(col)
 	}
 	// Also add any column in a mutation that is DELETE_AND_WRITE_ONLY and has
-	// a DEFAULT expression.
+	// a DEFAULT expression or is computed, either from the column it is
+	// converting or as an indexed expression. The values of the latter are
+	// filled in by the RowInserter.
 	for _, m := range tableDesc.Mutations {
 		if col := m.GetColumn(); col != nil &&
 			m.State == DescriptorMutation_DELETE_AND_WRITE_ONLY {
+			if m.Direction == DescriptorMutation_ADD &&
+				(m.Conversion != nil || tableDesc.IsIndexExprColumn(col.ID)) {
+				addComputed(*col)
+				continue
+			}
 			addIfDefault(*col)
 		}
 	}
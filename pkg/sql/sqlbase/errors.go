@@ -72,6 +72,25 @@ func NewNonNullViolationError(columnName string) error {
 	return pgerror.NewErrorf(pgerror.CodeNotNullViolationError, "null value in column %q violates not-null constraint", columnName)
 }
 
+// NewNotNullValidationError creates an error for a NOT NULL constraint being
+// added to a column that contains NULL values. pkVals holds the primary key
+// values of a sample of the offending rows.
+func NewNotNullValidationError(
+	columnName string, index *IndexDescriptor, pkVals []parser.Datums,
+) error {
+	rows := make([]string, 0, len(pkVals))
+	for _, vals := range pkVals {
+		valStrs := make([]string, 0, len(vals))
+		for _, val := range vals {
+			valStrs = append(valStrs, val.String())
+		}
+		rows = append(rows, fmt.Sprintf("(%s)=(%s)",
+			strings.Join(index.ColumnNames, ","), strings.Join(valStrs, ",")))
+	}
+	return pgerror.NewErrorf(pgerror.CodeNotNullViolationError,
+		"column %q contains null values: %s", columnName, strings.Join(rows, ", "))
+}
+
 // NewUniquenessConstraintViolationError creates an error that represents a
 // violation of a UNIQUE constraint.
 func NewUniquenessConstraintViolationError(index *IndexDescriptor, vals []parser.Datum) error {
@@ -104,6 +123,7 @@ func NewInvalidSchemaDefinitionError(err error) error {
 func IsPermanentSchemaChangeError(err error) bool {
 	return errHasCode(err, pgerror.CodeNotNullViolationError) ||
 		errHasCode(err, pgerror.CodeUniqueViolationError) ||
+		errHasCode(err, pgerror.CodeCheckViolationError) ||
 		errHasCode(err, pgerror.CodeInvalidSchemaDefinitionError)
 }
 
@@ -80,6 +80,22 @@ func TablesNeededForFKs(table TableDescriptor, usage FKCheck) TableLookupsByID {
 	return ret
 }
 
+// TablesNeededForCascades calculates the IDs of the additional
+// TableDescriptors that will be needed to modify `table` on behalf of the
+// referential actions of its foreign keys. It returns nil if none of the
+// foreign keys of `table` has a referential action that modifies its rows.
+//
+// As with TablesNeededForFKs, the returned map's values are *not* set.
+func TablesNeededForCascades(table TableDescriptor) TableLookupsByID {
+	for _, idx := range table.AllNonDropIndexes() {
+		if fk := idx.ForeignKey; fk.IsSet() &&
+			(isCascadingAction(fk.OnDelete) || isCascadingAction(fk.OnUpdate)) {
+			return TablesNeededForFKs(table, CheckUpdates)
+		}
+	}
+	return nil
+}
+
 type fkInsertHelper map[IndexID][]baseFKHelper
 
 var errSkipUnusedFK = errors.New("no columns involved in FK included in writer")
@@ -160,16 +176,27 @@ func (fks fkInsertHelper) CollectSpansForValues(values parser.Datums) (roachpb.S
 	return collectSpansForValuesWithFKMap(fks, values)
 }
 
+// CollectCascadeSpans implements the FkSpanCollector interface.
+func (fks fkInsertHelper) CollectCascadeSpans() roachpb.Spans {
+	return nil
+}
+
 type fkDeleteHelper map[IndexID][]baseFKHelper
 
+// makeFKDeleteHelper creates the helpers for the foreign keys referencing
+// `table`. The usage determines whether the ON DELETE (CheckDeletes) or the
+// ON UPDATE (CheckUpdates) referential actions are carried out.
 func makeFKDeleteHelper(
 	txn *client.Txn,
 	table TableDescriptor,
 	otherTables TableLookupsByID,
 	colMap map[ColumnID]int,
+	usage FKCheck,
+	evalCtx *parser.EvalContext,
 	alloc *DatumAlloc,
 ) (fkDeleteHelper, error) {
 	var fks fkDeleteHelper
+	var state *cascadeState
 	for _, idx := range table.AllNonDropIndexes() {
 		for _, ref := range idx.ReferencedBy {
 			if otherTables[ref.Table].IsAdding {
@@ -184,6 +211,28 @@ func makeFKDeleteHelper(
 			if err != nil {
 				return fks, err
 			}
+			action := fk.searchIdx.ForeignKey.OnDelete
+			if usage == CheckUpdates {
+				action = fk.searchIdx.ForeignKey.OnUpdate
+			}
+			if isCascadingAction(action) {
+				if state == nil {
+					state = &cascadeState{path: make(map[string]struct{})}
+				}
+				fk.cascader = &fkCascader{
+					txn:         txn,
+					otherTables: otherTables,
+					evalCtx:     evalCtx,
+					alloc:       alloc,
+					action:      action,
+					onDelete:    usage == CheckDeletes,
+					table:       fk.searchTable,
+					idx:         fk.searchIdx,
+					writeTable:  &table,
+					writeColMap: colMap,
+					state:       state,
+				}
+			}
 			if fks == nil {
 				fks = make(fkDeleteHelper)
 			}
@@ -195,15 +244,28 @@ func makeFKDeleteHelper(
 
 func (fks fkDeleteHelper) checkAll(ctx context.Context, row parser.Datums) error {
 	for idx := range fks {
-		if err := fks.checkIdx(ctx, idx, row); err != nil {
+		if err := fks.checkIdx(ctx, idx, row, nil /* newRow */); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (fks fkDeleteHelper) checkIdx(ctx context.Context, idx IndexID, row parser.Datums) error {
-	for _, fk := range fks[idx] {
+// checkIdx checks that the values of row in the given index are not
+// referenced, or carries out the referential actions on the referencing rows.
+// newRow contains the new values of the row when it is being updated, and is
+// nil when it is being deleted.
+func (fks fkDeleteHelper) checkIdx(
+	ctx context.Context, idx IndexID, row, newRow parser.Datums,
+) error {
+	for i := range fks[idx] {
+		fk := &fks[idx][i]
+		if fk.cascader != nil && row != nil {
+			if err := fk.cascader.cascade(ctx, fk, row, newRow); err != nil {
+				return err
+			}
+			continue
+		}
 		found, err := fk.check(ctx, row)
 		if err != nil {
 			return err
@@ -237,6 +299,24 @@ func (fks fkDeleteHelper) CollectSpansForValues(values parser.Datums) (roachpb.S
 	return collectSpansForValuesWithFKMap(fks, values)
 }
 
+// CollectCascadeSpans implements the FkSpanCollector interface.
+func (fks fkDeleteHelper) CollectCascadeSpans() roachpb.Spans {
+	return collectCascadeSpansWithFKMap(fks)
+}
+
+// setCascadeState makes the referential actions carried out by the helpers
+// part of an enclosing cascade.
+func (fks fkDeleteHelper) setCascadeState(state *cascadeState, depth int) {
+	for idx := range fks {
+		for _, fk := range fks[idx] {
+			if fk.cascader != nil {
+				fk.cascader.state = state
+				fk.cascader.depth = depth
+			}
+		}
+	}
+}
+
 type fkUpdateHelper struct {
 	inbound  fkDeleteHelper // Check old values are not referenced.
 	outbound fkInsertHelper // Check rows referenced by new values still exist.
@@ -247,11 +327,14 @@ func makeFKUpdateHelper(
 	table TableDescriptor,
 	otherTables TableLookupsByID,
 	colMap map[ColumnID]int,
+	evalCtx *parser.EvalContext,
 	alloc *DatumAlloc,
 ) (fkUpdateHelper, error) {
 	ret := fkUpdateHelper{}
 	var err error
-	if ret.inbound, err = makeFKDeleteHelper(txn, table, otherTables, colMap, alloc); err != nil {
+	if ret.inbound, err = makeFKDeleteHelper(
+		txn, table, otherTables, colMap, CheckUpdates, evalCtx, alloc,
+	); err != nil {
 		return ret, err
 	}
 	ret.outbound, err = makeFKInsertHelper(txn, table, otherTables, colMap, alloc)
@@ -261,7 +344,7 @@ func makeFKUpdateHelper(
 func (fks fkUpdateHelper) checkIdx(
 	ctx context.Context, idx IndexID, oldValues, newValues parser.Datums,
 ) error {
-	if err := fks.inbound.checkIdx(ctx, idx, oldValues); err != nil {
+	if err := fks.inbound.checkIdx(ctx, idx, oldValues, newValues); err != nil {
 		return err
 	}
 	return fks.outbound.checkIdx(ctx, idx, newValues)
@@ -287,6 +370,11 @@ func (fks fkUpdateHelper) CollectSpansForValues(values parser.Datums) (roachpb.S
 	return append(inboundReads, outboundReads...), nil
 }
 
+// CollectCascadeSpans implements the FkSpanCollector interface.
+func (fks fkUpdateHelper) CollectCascadeSpans() roachpb.Spans {
+	return fks.inbound.CollectCascadeSpans()
+}
+
 type baseFKHelper struct {
 	txn          *client.Txn
 	rf           RowFetcher
@@ -296,6 +384,10 @@ type baseFKHelper struct {
 	writeIdx     IndexDescriptor  // the index we want to modify
 	searchPrefix []byte           // prefix of keys in searchIdx
 	ids          map[ColumnID]int // col IDs
+
+	// cascader is set when the referential action of an inbound FK modifies
+	// the referencing rows instead of rejecting the write.
+	cascader *fkCascader
 }
 
 func makeBaseFKHelper(
@@ -386,6 +478,9 @@ func (f baseFKHelper) span() roachpb.Span {
 type FkSpanCollector interface {
 	CollectSpans() roachpb.Spans
 	CollectSpansForValues(values parser.Datums) (roachpb.Spans, error)
+	// CollectCascadeSpans returns the spans that the referential actions of
+	// the foreign keys may read and modify.
+	CollectCascadeSpans() roachpb.Spans
 }
 
 var _ FkSpanCollector = fkInsertHelper{}
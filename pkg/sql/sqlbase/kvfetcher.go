@@ -79,6 +79,13 @@ func SetKVBatchSize(val int64) func() {
 	return func() { kvBatchSize = oldVal }
 }
 
+// KVFetcherStats counts the batches of requests sent to the KV layer and the
+// bytes of the key/values read by a RowFetcher.
+type KVFetcherStats struct {
+	Batches int64
+	Bytes   int64
+}
+
 // txnKVFetcher handles retrieval of key/values.
 type txnKVFetcher struct {
 	// "Constant" fields, provided by the caller.
@@ -102,6 +109,9 @@ type txnKVFetcher struct {
 	// rangeInfos are deduped, so they're not ordered in any particular way and
 	// they don't map to kvFetcher.spans in any particular way.
 	rangeInfos []roachpb.RangeInfo
+
+	// stats, if set, accumulates the batches sent and the bytes read.
+	stats *KVFetcherStats
 }
 
 func (f *txnKVFetcher) getRangesInfo() []roachpb.RangeInfo {
@@ -238,12 +248,18 @@ func (f *txnKVFetcher) fetch(ctx context.Context) error {
 	for _, resp := range f.responses {
 		reply := resp.GetInner()
 
-		var numKVs int
+		var rows []roachpb.KeyValue
 		switch t := reply.(type) {
 		case *roachpb.ScanResponse:
-			numKVs = len(t.Rows)
+			rows = t.Rows
 		case *roachpb.ReverseScanResponse:
-			numKVs = len(t.Rows)
+			rows = t.Rows
+		}
+		numKVs := len(rows)
+		if f.stats != nil {
+			for i := range rows {
+				f.stats.Bytes += int64(len(rows[i].Key) + len(rows[i].Value.RawBytes))
+			}
 		}
 
 		if numKVs > 0 && sawResumeSpan {
@@ -270,6 +286,9 @@ func (f *txnKVFetcher) fetch(ctx context.Context) error {
 	}
 
 	f.batchIdx++
+	if f.stats != nil {
+		f.stats.Batches++
+	}
 
 	// TODO(radu): We should fetch the next chunk in the background instead of waiting for the next
 	// call to fetch(). We can use a pool of workers to issue the KV ops which will also limit the
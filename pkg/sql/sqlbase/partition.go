@@ -0,0 +1,469 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sqlbase
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+)
+
+// PartitionSpecialValCode identifies a special value in a partition tuple.
+type PartitionSpecialValCode uint64
+
+const (
+	// PartitionDefaultVal represents the special DEFAULT value.
+	PartitionDefaultVal PartitionSpecialValCode = 0
+	// PartitionMaxVal represents the special MAXVALUE value.
+	PartitionMaxVal PartitionSpecialValCode = 1
+	// PartitionMinVal represents the special MINVALUE value.
+	PartitionMinVal PartitionSpecialValCode = 2
+)
+
+func (c PartitionSpecialValCode) String() string {
+	switch c {
+	case PartitionDefaultVal:
+		return "DEFAULT"
+	case PartitionMaxVal:
+		return "MAXVALUE"
+	case PartitionMinVal:
+		return "MINVALUE"
+	}
+	panic(fmt.Sprintf("unknown PartitionSpecialValCode: %d", uint64(c)))
+}
+
+// PartitionTuple is the decoded form of a tuple in a PartitioningDescriptor:
+// a (possibly empty) list of datums followed by SpecialCount copies of
+// Special.
+type PartitionTuple struct {
+	Datums       parser.Datums
+	Special      PartitionSpecialValCode
+	SpecialCount int
+}
+
+func (t *PartitionTuple) String() string {
+	var buf bytes.Buffer
+	buf.WriteByte('(')
+	for i := 0; i < len(t.Datums)+t.SpecialCount; i++ {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		if i < len(t.Datums) {
+			t.Datums[i].Format(&buf, parser.FmtSimple)
+		} else {
+			buf.WriteString(t.Special.String())
+		}
+	}
+	buf.WriteByte(')')
+	return buf.String()
+}
+
+// EncodePartitionSpecialVal appends the encoding of a special value to b.
+func EncodePartitionSpecialVal(b []byte, code PartitionSpecialValCode) []byte {
+	b = encoding.EncodeValueTag(b, uint32(NoColumnID), encoding.NotNull)
+	return encoding.EncodeNonsortingUvarint(b, uint64(code))
+}
+
+// EncodePartitionTuple returns the value encoding of a partition tuple, as
+// stored in a PartitioningDescriptor.
+func EncodePartitionTuple(t *PartitionTuple, scratch []byte) ([]byte, error) {
+	var b []byte
+	for _, d := range t.Datums {
+		var err error
+		if b, err = EncodeTableValue(b, NoColumnID, d, scratch); err != nil {
+			return nil, err
+		}
+	}
+	for i := 0; i < t.SpecialCount; i++ {
+		b = EncodePartitionSpecialVal(b, t.Special)
+	}
+	return b, nil
+}
+
+// DecodePartitionTuple decodes a tuple of the given index's partitioning. It
+// returns the tuple along with the index key prefix of its datums, which is
+// the start of the keys contained by the tuple.
+func DecodePartitionTuple(
+	a *DatumAlloc, desc *TableDescriptor, idx *IndexDescriptor, valueEncBuf []byte,
+) (*PartitionTuple, []byte, error) {
+	numColumns := int(idx.Partitioning.NumColumns)
+	if numColumns > len(idx.ColumnIDs) {
+		return nil, nil, errors.Errorf("partitioning has %d columns but index has only %d columns",
+			numColumns, len(idx.ColumnIDs))
+	}
+
+	t := &PartitionTuple{Datums: make(parser.Datums, 0, numColumns)}
+	for i := 0; i < numColumns; i++ {
+		_, dataOffset, _, typ, err := encoding.DecodeValueTag(valueEncBuf)
+		if err != nil {
+			return nil, nil, err
+		}
+		if typ == encoding.NotNull {
+			var code uint64
+			valueEncBuf, _, code, err = encoding.DecodeNonsortingUvarint(valueEncBuf[dataOffset:])
+			if err != nil {
+				return nil, nil, err
+			}
+			special := PartitionSpecialValCode(code)
+			if t.SpecialCount > 0 && special != t.Special {
+				return nil, nil, errors.Errorf("non-%[1]s value (%[2]s) not allowed after %[1]s",
+					t.Special, special)
+			}
+			t.Special = special
+			t.SpecialCount++
+			continue
+		}
+		if t.SpecialCount > 0 {
+			return nil, nil, errors.Errorf("non-%[1]s value not allowed after %[1]s", t.Special)
+		}
+		col, err := desc.FindColumnByID(idx.ColumnIDs[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		var datum parser.Datum
+		datum, valueEncBuf, err = DecodeTableValue(a, col.Type.ToDatumType(), valueEncBuf)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "decoding")
+		}
+		t.Datums = append(t.Datums, datum)
+	}
+	if len(valueEncBuf) > 0 {
+		return nil, nil, errors.New("superfluous data in encoded value")
+	}
+
+	key := MakeIndexKeyPrefix(desc, idx.ID)
+	for i, datum := range t.Datums {
+		dir, err := idx.ColumnDirections[i].ToEncodingDirection()
+		if err != nil {
+			return nil, nil, err
+		}
+		if key, err = EncodeTableKey(key, datum, dir); err != nil {
+			return nil, nil, err
+		}
+	}
+	return t, key, nil
+}
+
+// PartitionSpan is a span of an index's keys belonging to a partition.
+type PartitionSpan struct {
+	Name string
+	Span roachpb.Span
+}
+
+// prioritizedSpan is a span of keys claimed by a partition. When the spans
+// of two partitions overlap, the one with the higher priority wins.
+type prioritizedSpan struct {
+	PartitionSpan
+	priority int
+}
+
+// PartitionSpans returns the spans of the given index's keys that belong to
+// each of its partitions, sorted by key. Keys not belonging to any partition
+// are not covered. An error is returned if the partitioning is invalid, such
+// as when two partitions contain the same value.
+func (desc *TableDescriptor) PartitionSpans(
+	a *DatumAlloc, idx *IndexDescriptor,
+) ([]PartitionSpan, error) {
+	part := &idx.Partitioning
+	if part.NumColumns == 0 {
+		return nil, nil
+	}
+	if len(part.List) > 0 && len(part.Range) > 0 {
+		return nil, errors.Errorf("index %q has both list and range partitions", idx.Name)
+	}
+
+	var spans []prioritizedSpan
+	if len(part.List) > 0 {
+		seen := make(map[string]struct{})
+		for _, p := range part.List {
+			for _, valueEncBuf := range p.Values {
+				t, key, err := DecodePartitionTuple(a, desc, idx, valueEncBuf)
+				if err != nil {
+					return nil, errors.Wrapf(err, "PARTITION %s", p.Name)
+				}
+				if t.SpecialCount > 0 && t.Special != PartitionDefaultVal {
+					return nil, errors.Errorf("PARTITION %s: %s cannot be used with PARTITION BY LIST",
+						p.Name, t.Special)
+				}
+				if _, ok := seen[string(key)]; ok {
+					return nil, errors.Errorf("%s cannot be present in more than one partition", t)
+				}
+				seen[string(key)] = struct{}{}
+				k := roachpb.Key(key)
+				spans = append(spans, prioritizedSpan{
+					PartitionSpan: PartitionSpan{
+						Name: p.Name,
+						Span: roachpb.Span{Key: k, EndKey: k.PrefixEnd()},
+					},
+					priority: len(t.Datums),
+				})
+			}
+		}
+		return coverPrioritizedSpans(spans), nil
+	}
+
+	for _, p := range part.Range {
+		from, err := decodeRangeBound(a, desc, idx, p.FromInclusive)
+		if err != nil {
+			return nil, errors.Wrapf(err, "PARTITION %s", p.Name)
+		}
+		to, err := decodeRangeBound(a, desc, idx, p.ToExclusive)
+		if err != nil {
+			return nil, errors.Wrapf(err, "PARTITION %s", p.Name)
+		}
+		if from.Compare(to) >= 0 {
+			return nil, errors.Errorf("PARTITION %s: empty range: lower bound is not less than upper bound",
+				p.Name)
+		}
+		spans = append(spans, prioritizedSpan{
+			PartitionSpan: PartitionSpan{Name: p.Name, Span: roachpb.Span{Key: from, EndKey: to}},
+		})
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].Span.Key.Compare(spans[j].Span.Key) < 0
+	})
+	result := make([]PartitionSpan, len(spans))
+	for i := range spans {
+		if i > 0 && spans[i-1].Span.EndKey.Compare(spans[i].Span.Key) > 0 {
+			return nil, errors.Errorf("partitions %s and %s overlap",
+				spans[i-1].Name, spans[i].Name)
+		}
+		result[i] = spans[i].PartitionSpan
+	}
+	return result, nil
+}
+
+// decodeRangeBound decodes a bound of a range partition into a key. The
+// bound must supply a value for every partitioning column.
+func decodeRangeBound(
+	a *DatumAlloc, desc *TableDescriptor, idx *IndexDescriptor, valueEncBuf []byte,
+) (roachpb.Key, error) {
+	t, key, err := DecodePartitionTuple(a, desc, idx, valueEncBuf)
+	if err != nil {
+		return nil, err
+	}
+	if len(t.Datums)+t.SpecialCount != int(idx.Partitioning.NumColumns) {
+		return nil, errors.Errorf("range bound %s must have %d values",
+			t, idx.Partitioning.NumColumns)
+	}
+	if t.SpecialCount > 0 {
+		switch t.Special {
+		case PartitionMinVal:
+		case PartitionMaxVal:
+			return roachpb.Key(key).PrefixEnd(), nil
+		default:
+			return nil, errors.Errorf("%s cannot be used with PARTITION BY RANGE", t.Special)
+		}
+	}
+	return roachpb.Key(key), nil
+}
+
+// coverPrioritizedSpans flattens the possibly overlapping spans into
+// non-overlapping spans sorted by key, where each key is assigned to the
+// highest priority span containing it. Adjacent spans of the same partition
+// are merged.
+func coverPrioritizedSpans(spans []prioritizedSpan) []PartitionSpan {
+	bounds := make([]roachpb.Key, 0, 2*len(spans))
+	for _, s := range spans {
+		bounds = append(bounds, s.Span.Key, s.Span.EndKey)
+	}
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i].Compare(bounds[j]) < 0 })
+
+	var result []PartitionSpan
+	for i := 0; i+1 < len(bounds); i++ {
+		start, end := bounds[i], bounds[i+1]
+		if start.Equal(end) {
+			continue
+		}
+		best := -1
+		for j, s := range spans {
+			if s.Span.Key.Compare(start) <= 0 && s.Span.EndKey.Compare(end) >= 0 &&
+				(best == -1 || s.priority > spans[best].priority) {
+				best = j
+			}
+		}
+		if best == -1 {
+			continue
+		}
+		if n := len(result); n > 0 && result[n-1].Name == spans[best].Name &&
+			result[n-1].Span.EndKey.Equal(start) {
+			result[n-1].Span.EndKey = end
+			continue
+		}
+		result = append(result, PartitionSpan{
+			Name: spans[best].Name,
+			Span: roachpb.Span{Key: start, EndKey: end},
+		})
+	}
+	return result
+}
+
+// FindIndexByPartitionName returns the index containing the partition with
+// the given name, or nil if there is no such partition.
+func (desc *TableDescriptor) FindIndexByPartitionName(name string) *IndexDescriptor {
+	for _, idx := range desc.AllNonDropIndexes() {
+		for _, p := range idx.Partitioning.List {
+			if p.Name == name {
+				return &idx
+			}
+		}
+		for _, p := range idx.Partitioning.Range {
+			if p.Name == name {
+				return &idx
+			}
+		}
+	}
+	return nil
+}
+
+// validatePartitioning validates the partitioning of each of the table's
+// indexes. Partition names must be unique within the table.
+func (desc *TableDescriptor) validatePartitioning() error {
+	var a DatumAlloc
+	partitionNames := make(map[string]struct{})
+	for _, idx := range desc.AllNonDropIndexes() {
+		part := &idx.Partitioning
+		if part.NumColumns == 0 {
+			continue
+		}
+		if len(idx.Interleave.Ancestors) > 0 {
+			return errors.Errorf("index %q: cannot partition an interleaved index", idx.Name)
+		}
+		if int(part.NumColumns) > len(idx.ColumnIDs) {
+			return errors.Errorf("index %q: partitioning has %d columns but index has only %d columns",
+				idx.Name, part.NumColumns, len(idx.ColumnIDs))
+		}
+		var names []string
+		for _, p := range part.List {
+			names = append(names, p.Name)
+		}
+		for _, p := range part.Range {
+			names = append(names, p.Name)
+		}
+		for _, name := range names {
+			if _, ok := partitionNames[name]; ok {
+				return errors.Errorf("PARTITION %s: name must be unique", name)
+			}
+			partitionNames[name] = struct{}{}
+		}
+		if _, err := desc.PartitionSpans(&a, &idx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findNonDropIndexByID returns the index with the given ID, or nil if there
+// is no such index or it is being dropped.
+func (desc *TableDescriptor) findNonDropIndexByID(id IndexID) *IndexDescriptor {
+	for _, idx := range desc.AllNonDropIndexes() {
+		if idx.ID == id {
+			return &idx
+		}
+	}
+	return nil
+}
+
+// GenerateSubzoneSpans constructs the spans of keys covered by each of the
+// given subzones of a table. The returned spans are sorted and
+// non-overlapping. A subzone for a partition takes precedence over a subzone
+// for the whole index containing it. Subzones of indexes which are being
+// dropped cover no keys.
+func GenerateSubzoneSpans(
+	desc *TableDescriptor, subzones []config.Subzone,
+) ([]config.SubzoneSpan, error) {
+	var a DatumAlloc
+	var spans []prioritizedSpan
+	subzoneIndexByName := make(map[string]int32)
+	for i, subzone := range subzones {
+		idx := desc.findNonDropIndexByID(IndexID(subzone.IndexID))
+		if idx == nil {
+			continue
+		}
+		name := fmt.Sprintf("%d", i)
+		subzoneIndexByName[name] = int32(i)
+		if subzone.PartitionName == "" {
+			spans = append(spans, prioritizedSpan{
+				PartitionSpan: PartitionSpan{Name: name, Span: desc.IndexSpan(idx.ID)},
+			})
+			continue
+		}
+		partSpans, err := desc.PartitionSpans(&a, idx)
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for _, s := range partSpans {
+			if s.Name == subzone.PartitionName {
+				spans = append(spans, prioritizedSpan{
+					PartitionSpan: PartitionSpan{Name: name, Span: s.Span},
+					priority:      1,
+				})
+				found = true
+			}
+		}
+		if !found {
+			// A partition whose values are all claimed by more specific
+			// partitions covers no keys.
+			if desc.FindIndexByPartitionName(subzone.PartitionName) == nil {
+				return nil, errors.Errorf("partition %q does not exist on table %q",
+					subzone.PartitionName, desc.Name)
+			}
+		}
+	}
+
+	var result []config.SubzoneSpan
+	for _, s := range coverPrioritizedSpans(spans) {
+		result = append(result, config.SubzoneSpan{
+			Key:          s.Span.Key,
+			EndKey:       s.Span.EndKey,
+			SubzoneIndex: subzoneIndexByName[s.Name],
+		})
+	}
+	return result, nil
+}
+
+// PruneSubzones removes the subzones of a table's zone config which refer to
+// indexes or partitions of the table that no longer exist, and regenerates
+// the zone config's SubzoneSpans. It must be used whenever the indexes of a
+// table or their partitioning change, since stale spans would apply the
+// subzones to the wrong keys.
+func PruneSubzones(desc *TableDescriptor, zone *config.ZoneConfig) error {
+	subzones := zone.Subzones[:0]
+	for _, subzone := range zone.Subzones {
+		idx := desc.findNonDropIndexByID(IndexID(subzone.IndexID))
+		if idx == nil {
+			continue
+		}
+		if subzone.PartitionName != "" {
+			partIdx := desc.FindIndexByPartitionName(subzone.PartitionName)
+			if partIdx == nil || partIdx.ID != idx.ID {
+				continue
+			}
+		}
+		subzones = append(subzones, subzone)
+	}
+	zone.Subzones = subzones
+	var err error
+	zone.SubzoneSpans, err = GenerateSubzoneSpans(desc, zone.Subzones)
+	return err
+}
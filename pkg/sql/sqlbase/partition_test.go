@@ -0,0 +1,174 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sqlbase
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestGenerateSubzoneSpans(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	encodeTuple := func(t *PartitionTuple) []byte {
+		b, err := EncodePartitionTuple(t, nil /* scratch */)
+		if err != nil {
+			panic(err)
+		}
+		return b
+	}
+	value := func(i int) []byte {
+		return encodeTuple(&PartitionTuple{Datums: parser.Datums{parser.NewDInt(parser.DInt(i))}})
+	}
+
+	desc := TableDescriptor{
+		ID:       keys.MaxReservedDescID + 2,
+		ParentID: keys.MaxReservedDescID + 1,
+		Name:     "foo",
+		Columns: []ColumnDescriptor{
+			{Name: "a", Type: ColumnType{SemanticType: ColumnType_INT}},
+			{Name: "b", Type: ColumnType{SemanticType: ColumnType_INT}},
+		},
+		PrimaryIndex:  makeIndexDescriptor("primary", []string{"a"}),
+		Indexes:       []IndexDescriptor{makeIndexDescriptor("foo_b", []string{"b"})},
+		Privileges:    NewDefaultPrivilegeDescriptor(),
+		FormatVersion: FamilyFormatVersion,
+	}
+	desc.PrimaryIndex.Partitioning = PartitioningDescriptor{
+		NumColumns: 1,
+		List: []PartitioningDescriptor_List{
+			{Name: "p1", Values: [][]byte{value(1)}},
+			{Name: "p3", Values: [][]byte{value(3)}},
+			{Name: "pd", Values: [][]byte{
+				encodeTuple(&PartitionTuple{Special: PartitionDefaultVal, SpecialCount: 1}),
+			}},
+		},
+	}
+	if err := desc.AllocateIDs(); err != nil {
+		t.Fatal(err)
+	}
+
+	indexID := uint32(desc.PrimaryIndex.ID)
+	prefix := roachpb.Key(MakeIndexKeyPrefix(&desc, desc.PrimaryIndex.ID))
+	k1 := roachpb.Key(encoding.EncodeVarintAscending(append(roachpb.Key(nil), prefix...), 1))
+	k3 := roachpb.Key(encoding.EncodeVarintAscending(append(roachpb.Key(nil), prefix...), 3))
+	span := func(key, endKey roachpb.Key, subzoneIndex int32) config.SubzoneSpan {
+		return config.SubzoneSpan{Key: key, EndKey: endKey, SubzoneIndex: subzoneIndex}
+	}
+
+	testCases := []struct {
+		subzones []config.Subzone
+		expected []config.SubzoneSpan
+		err      string
+	}{
+		{
+			subzones: []config.Subzone{{IndexID: indexID, PartitionName: "p1"}},
+			expected: []config.SubzoneSpan{span(k1, k1.PrefixEnd(), 0)},
+		},
+		// The DEFAULT partition covers everything not covered by the other
+		// partitions, whether or not they have subzones.
+		{
+			subzones: []config.Subzone{
+				{IndexID: indexID, PartitionName: "p1"},
+				{IndexID: indexID, PartitionName: "pd"},
+			},
+			expected: []config.SubzoneSpan{
+				span(prefix, k1, 1),
+				span(k1, k1.PrefixEnd(), 0),
+				span(k1.PrefixEnd(), k3, 1),
+				span(k3.PrefixEnd(), prefix.PrefixEnd(), 1),
+			},
+		},
+		// A partition's subzone takes precedence over its index's.
+		{
+			subzones: []config.Subzone{
+				{IndexID: indexID},
+				{IndexID: indexID, PartitionName: "p3"},
+			},
+			expected: []config.SubzoneSpan{
+				span(prefix, k3, 0),
+				span(k3, k3.PrefixEnd(), 1),
+				span(k3.PrefixEnd(), prefix.PrefixEnd(), 0),
+			},
+		},
+		{
+			subzones: []config.Subzone{{IndexID: indexID, PartitionName: "p2"}},
+			err:      `partition "p2" does not exist on table "foo"`,
+		},
+	}
+	for i, c := range testCases {
+		spans, err := GenerateSubzoneSpans(&desc, c.subzones)
+		if c.err != "" {
+			if !testutils.IsError(err, c.err) {
+				t.Errorf("%d: expected error %q, but found %v", i, c.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%d: %+v", i, err)
+		}
+		if !reflect.DeepEqual(c.expected, spans) {
+			t.Errorf("%d: expected spans\n%+v\nbut found\n%+v", i, c.expected, spans)
+		}
+	}
+
+	// Once an index is dropped, its subzones cover no keys and are pruned.
+	secondary := desc.Indexes[0]
+	secondarySpan := desc.IndexSpan(secondary.ID)
+	zone := config.ZoneConfig{Subzones: []config.Subzone{
+		{IndexID: uint32(secondary.ID)},
+		{IndexID: indexID, PartitionName: "p1"},
+	}}
+	if err := PruneSubzones(&desc, &zone); err != nil {
+		t.Fatal(err)
+	}
+	expected := []config.SubzoneSpan{
+		span(k1, k1.PrefixEnd(), 1),
+		span(secondarySpan.Key, secondarySpan.EndKey, 0),
+	}
+	if !reflect.DeepEqual(expected, zone.SubzoneSpans) {
+		t.Errorf("expected spans\n%+v\nbut found\n%+v", expected, zone.SubzoneSpans)
+	}
+
+	if err := desc.AddIndexMutation(secondary, DescriptorMutation_DROP); err != nil {
+		t.Fatal(err)
+	}
+	desc.Indexes = nil
+	spans, err := GenerateSubzoneSpans(&desc, zone.Subzones)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []config.SubzoneSpan{span(k1, k1.PrefixEnd(), 1)}; !reflect.DeepEqual(expected, spans) {
+		t.Errorf("expected spans\n%+v\nbut found\n%+v", expected, spans)
+	}
+	if err := PruneSubzones(&desc, &zone); err != nil {
+		t.Fatal(err)
+	}
+	expectedSubzones := []config.Subzone{{IndexID: indexID, PartitionName: "p1"}}
+	if !reflect.DeepEqual(expectedSubzones, zone.Subzones) {
+		t.Errorf("expected subzones %+v, but found %+v", expectedSubzones, zone.Subzones)
+	}
+	if expected := []config.SubzoneSpan{span(k1, k1.PrefixEnd(), 0)}; !reflect.DeepEqual(expected, zone.SubzoneSpans) {
+		t.Errorf("expected spans\n%+v\nbut found\n%+v", expected, zone.SubzoneSpans)
+	}
+}
@@ -93,6 +93,9 @@ type RowFetcher struct {
 
 	// Buffered allocation of decoded datums.
 	alloc *DatumAlloc
+
+	// kvStats accumulates the batches sent and the bytes read by the scans.
+	kvStats KVFetcherStats
 }
 
 type kvFetcher interface {
@@ -150,6 +153,17 @@ func (rf *RowFetcher) Init(
 		rf.indexColIdx[i] = rf.colIdxMap[id]
 	}
 
+	if index.Type == IndexDescriptor_INVERTED {
+		// The keys of an inverted index store the paths of the indexed
+		// document rather than its value, so the indexed column can't be
+		// decoded from them.
+		id := index.ColumnIDs[0]
+		if rf.neededCols.Contains(uint32(id)) {
+			return fmt.Errorf("requested column %s not in index", rf.cols[rf.colIdxMap[id]].Name)
+		}
+		rf.indexColIdx[0] = -1
+	}
+
 	if isSecondaryIndex {
 		for i := range rf.cols {
 			if rf.neededCols.Contains(uint32(rf.cols[i].ID)) && !index.ContainsColumnID(rf.cols[i].ID) {
@@ -204,9 +218,16 @@ func (rf *RowFetcher) StartScan(
 	if err != nil {
 		return err
 	}
+	f.stats = &rf.kvStats
 	return rf.StartScanFrom(ctx, &f)
 }
 
+// KVStats returns the number of batches sent and of bytes read by the scans
+// of the RowFetcher so far.
+func (rf *RowFetcher) KVStats() KVFetcherStats {
+	return rf.kvStats
+}
+
 // StartScanFrom initializes and starts a scan from the given kvFetcher. Can be
 // used multiple times.
 func (rf *RowFetcher) StartScanFrom(ctx context.Context, f kvFetcher) error {
@@ -304,7 +325,9 @@ func (rf *RowFetcher) processKV(
 
 		// Fill in the column values that are part of the index key.
 		for i, v := range rf.keyVals {
-			rf.row[rf.indexColIdx[i]] = v
+			if idx := rf.indexColIdx[i]; idx >= 0 {
+				rf.row[idx] = v
+			}
 		}
 	}
 
@@ -55,12 +55,7 @@ type rowHelper struct {
 func (rh *rowHelper) encodeIndexes(
 	colIDtoRowIndex map[ColumnID]int, values []parser.Datum,
 ) (primaryIndexKey []byte, secondaryIndexEntries []IndexEntry, err error) {
-	if rh.primaryIndexKeyPrefix == nil {
-		rh.primaryIndexKeyPrefix = MakeIndexKeyPrefix(rh.TableDesc,
-			rh.TableDesc.PrimaryIndex.ID)
-	}
-	primaryIndexKey, _, err = EncodeIndexKey(
-		rh.TableDesc, &rh.TableDesc.PrimaryIndex, colIDtoRowIndex, values, rh.primaryIndexKeyPrefix)
+	primaryIndexKey, err = rh.encodePrimaryIndex(colIDtoRowIndex, values)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -71,16 +66,26 @@ func (rh *rowHelper) encodeIndexes(
 	return primaryIndexKey, secondaryIndexEntries, nil
 }
 
+// encodePrimaryIndex encodes the primary index key.
+func (rh *rowHelper) encodePrimaryIndex(
+	colIDtoRowIndex map[ColumnID]int, values []parser.Datum,
+) (primaryIndexKey []byte, err error) {
+	if rh.primaryIndexKeyPrefix == nil {
+		rh.primaryIndexKeyPrefix = MakeIndexKeyPrefix(rh.TableDesc,
+			rh.TableDesc.PrimaryIndex.ID)
+	}
+	primaryIndexKey, _, err = EncodeIndexKey(
+		rh.TableDesc, &rh.TableDesc.PrimaryIndex, colIDtoRowIndex, values, rh.primaryIndexKeyPrefix)
+	return primaryIndexKey, err
+}
+
 // encodeSecondaryIndexes encodes the secondary index keys. The
 // secondaryIndexEntries are only valid until the next call to encodeIndexes or
 // encodeSecondaryIndexes.
 func (rh *rowHelper) encodeSecondaryIndexes(
 	colIDtoRowIndex map[ColumnID]int, values []parser.Datum,
 ) (secondaryIndexEntries []IndexEntry, err error) {
-	if len(rh.indexEntries) != len(rh.Indexes) {
-		rh.indexEntries = make([]IndexEntry, len(rh.Indexes))
-	}
-	err = EncodeSecondaryIndexes(
+	rh.indexEntries, err = EncodeSecondaryIndexes(
 		rh.TableDesc, rh.Indexes, colIDtoRowIndex, values, rh.indexEntries)
 	if err != nil {
 		return nil, err
@@ -88,6 +93,25 @@ func (rh *rowHelper) encodeSecondaryIndexes(
 	return rh.indexEntries, nil
 }
 
+// encodeSecondaryIndexesByIndex encodes the secondary index keys, grouped by
+// index: the i-th element of the result holds the entries of rh.Indexes[i].
+// buf is reused if it has the right length.
+func (rh *rowHelper) encodeSecondaryIndexesByIndex(
+	colIDtoRowIndex map[ColumnID]int, values []parser.Datum, buf [][]IndexEntry,
+) ([][]IndexEntry, error) {
+	if len(buf) != len(rh.Indexes) {
+		buf = make([][]IndexEntry, len(rh.Indexes))
+	}
+	for i := range rh.Indexes {
+		var err error
+		buf[i], err = EncodeSecondaryIndex(rh.TableDesc, &rh.Indexes[i], colIDtoRowIndex, values)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
+
 // skipColumnInPK returns true if the value at column colID does not need
 // to be encoded because it is already part of the primary key. Composite
 // datums are considered too, so a composite datum in a PK will return false.
@@ -144,6 +168,16 @@ type RowInserter struct {
 	InsertColIDtoRowIndex map[ColumnID]int
 	Fks                   fkInsertHelper
 
+	// converters computes the values of the columns in InsertCols that are
+	// being added by ALTER COLUMN ... TYPE or that store an indexed
+	// expression, in the order of converterOrder.
+	converters     map[ColumnID]*ColumnConverter
+	converterOrder []ColumnID
+	// checks are the CHECK constraints referencing the columns being
+	// converted, evaluated against the new columns.
+	checks  []ConversionCheck
+	evalCtx *parser.EvalContext
+
 	// For allocation avoidance.
 	marshalled []roachpb.Value
 	key        roachpb.Key
@@ -155,12 +189,16 @@ type RowInserter struct {
 // MakeRowInserter creates a RowInserter for the given table.
 //
 // insertCols must contain every column in the primary key.
+//
+// The evalCtx is used to compute the values of the columns that are being
+// rewritten to a new type.
 func MakeRowInserter(
 	txn *client.Txn,
 	tableDesc *TableDescriptor,
 	fkTables TableLookupsByID,
 	insertCols []ColumnDescriptor,
 	checkFKs bool,
+	evalCtx *parser.EvalContext,
 	alloc *DatumAlloc,
 ) (RowInserter, error) {
 	indexes := tableDesc.Indexes
@@ -179,6 +217,7 @@ func MakeRowInserter(
 		InsertCols:            insertCols,
 		InsertColIDtoRowIndex: ColIDtoRowIndexFromCols(insertCols),
 		marshalled:            make([]roachpb.Value, len(insertCols)),
+		evalCtx:               evalCtx,
 	}
 
 	for i, col := range tableDesc.PrimaryIndex.ColumnIDs {
@@ -187,8 +226,16 @@ func MakeRowInserter(
 		}
 	}
 
+	var err error
+	if ri.converters, err = MakeColumnConverters(tableDesc, insertCols); err != nil {
+		return RowInserter{}, err
+	}
+	ri.converterOrder = OrderColumnConverters(ri.converters)
+	if ri.checks, err = MakeConversionChecks(tableDesc, insertCols); err != nil {
+		return RowInserter{}, err
+	}
+
 	if checkFKs {
-		var err error
 		if ri.Fks, err = makeFKInsertHelper(txn, *tableDesc, fkTables,
 			ri.InsertColIDtoRowIndex, alloc); err != nil {
 			return ri, err
@@ -226,7 +273,8 @@ type putter interface {
 }
 
 // InsertRow adds to the batch the kv operations necessary to insert a table row
-// with the given values.
+// with the given values. The values of the columns being rewritten to a new
+// type are computed in place.
 func (ri *RowInserter) InsertRow(
 	ctx context.Context, b putter, values []parser.Datum, ignoreConflicts bool, traceKV bool,
 ) error {
@@ -239,6 +287,19 @@ func (ri *RowInserter) InsertRow(
 		putFn = insertPutFn
 	}
 
+	for _, colID := range ri.converterOrder {
+		val, err := ri.converters[colID].Convert(ri.evalCtx, ri.InsertColIDtoRowIndex, values)
+		if err != nil {
+			return err
+		}
+		values[ri.InsertColIDtoRowIndex[colID]] = val
+	}
+	for i := range ri.checks {
+		if err := ri.checks[i].Check(ri.evalCtx, ri.InsertColIDtoRowIndex, values); err != nil {
+			return err
+		}
+	}
+
 	// Encode the values to the expected column type. This needs to
 	// happen before index encoding because certain datum types (i.e. tuple)
 	// cannot be used as index values.
@@ -362,6 +423,16 @@ type RowUpdater struct {
 	deleteOnlyIndex       map[int]struct{}
 	primaryKeyColChange   bool
 
+	// converters computes the values of the columns that are being added by
+	// ALTER COLUMN ... TYPE or that store an indexed expression, and whose
+	// source columns are being updated, in the order of converterOrder.
+	converters     map[ColumnID]*ColumnConverter
+	converterOrder []ColumnID
+	// checks are the CHECK constraints referencing the columns being
+	// converted whose columns are being updated.
+	checks  []ConversionCheck
+	evalCtx *parser.EvalContext
+
 	// rd and ri are used when the update this RowUpdater is created for modifies
 	// the primary key of the table. In that case, rows must be deleted and
 	// re-added instead of merely updated, since the keys are changing.
@@ -371,13 +442,14 @@ type RowUpdater struct {
 	Fks fkUpdateHelper
 
 	// For allocation avoidance.
-	marshalled      []roachpb.Value
-	newValues       []parser.Datum
-	key             roachpb.Key
-	indexEntriesBuf []IndexEntry
-	valueBuf        []byte
-	scratch         []byte
-	value           roachpb.Value
+	marshalled         []roachpb.Value
+	newValues          []parser.Datum
+	key                roachpb.Key
+	oldIndexEntriesBuf [][]IndexEntry
+	newIndexEntriesBuf [][]IndexEntry
+	valueBuf           []byte
+	scratch            []byte
+	value              roachpb.Value
 }
 
 type rowUpdaterType int
@@ -399,6 +471,10 @@ const (
 // The returned RowUpdater contains a FetchCols field that defines the
 // expectation of which values are passed as oldValues to UpdateRow. Any column
 // passed in requestedCols will be included in FetchCols.
+//
+// The evalCtx is used to evaluate the default values of the referencing
+// columns for the ON UPDATE SET DEFAULT referential action, and to compute
+// the values of the columns that are being rewritten to a new type.
 func MakeRowUpdater(
 	txn *client.Txn,
 	tableDesc *TableDescriptor,
@@ -406,6 +482,7 @@ func MakeRowUpdater(
 	updateCols []ColumnDescriptor,
 	requestedCols []ColumnDescriptor,
 	updateType rowUpdaterType,
+	evalCtx *parser.EvalContext,
 	alloc *DatumAlloc,
 ) (RowUpdater, error) {
 	updateColIDtoRowIndex := ColIDtoRowIndexFromCols(updateCols)
@@ -423,6 +500,64 @@ func MakeRowUpdater(
 		}
 	}
 
+	// Computed columns whose source columns are being updated, directly or
+	// because they are themselves computed from updated columns.
+	var converters map[ColumnID]*ColumnConverter
+	var checks []ConversionCheck
+	if updateType != RowUpdaterOnlyColumns {
+		allCols := tableDesc.Columns
+		if len(tableDesc.Mutations) > 0 {
+			allCols = append([]ColumnDescriptor(nil), tableDesc.Columns...)
+			for _, m := range tableDesc.Mutations {
+				if col := m.GetColumn(); col != nil {
+					allCols = append(allCols, *col)
+				}
+			}
+		}
+		allConverters, err := MakeColumnConverters(tableDesc, allCols)
+		if err != nil {
+			return RowUpdater{}, err
+		}
+		updated := func(colID ColumnID) bool {
+			if _, ok := updateColIDtoRowIndex[colID]; ok {
+				return true
+			}
+			_, ok := converters[colID]
+			return ok
+		}
+		for changed := true; changed; {
+			changed = false
+			for colID, conv := range allConverters {
+				if _, ok := converters[colID]; ok {
+					continue
+				}
+				for _, sourceID := range conv.SourceColumnIDs {
+					if updated(sourceID) {
+						if converters == nil {
+							converters = make(map[ColumnID]*ColumnConverter)
+						}
+						converters[colID] = conv
+						changed = true
+						break
+					}
+				}
+			}
+		}
+
+		allChecks, err := MakeConversionChecks(tableDesc, allCols)
+		if err != nil {
+			return RowUpdater{}, err
+		}
+		for _, check := range allChecks {
+			for _, sourceID := range check.SourceColumnIDs() {
+				if updated(sourceID) {
+					checks = append(checks, check)
+					break
+				}
+			}
+		}
+	}
+
 	// Secondary indexes needing updating.
 	needsUpdate := func(index IndexDescriptor) bool {
 		if updateType == RowUpdaterOnlyColumns {
@@ -433,10 +568,17 @@ func MakeRowUpdater(
 		if primaryKeyColChange {
 			return true
 		}
+		// An index encoded like a primary index stores all the columns.
+		if index.EncodingType == PrimaryIndexEncoding {
+			return true
+		}
 		return index.RunOverAllColumns(func(id ColumnID) error {
 			if _, ok := updateColIDtoRowIndex[id]; ok {
 				return returnTruePseudoError
 			}
+			if _, ok := converters[id]; ok {
+				return returnTruePseudoError
+			}
 			return nil
 		}) != nil
 	}
@@ -485,6 +627,10 @@ func MakeRowUpdater(
 		primaryKeyColChange:   primaryKeyColChange,
 		marshalled:            make([]roachpb.Value, len(updateCols)),
 		newValues:             make([]parser.Datum, len(tableCols)),
+		converters:            converters,
+		converterOrder:        OrderColumnConverters(converters),
+		checks:                checks,
+		evalCtx:               evalCtx,
 	}
 
 	if primaryKeyColChange {
@@ -493,13 +639,13 @@ func MakeRowUpdater(
 		// them, so request them all.
 		var err error
 		if ru.rd, err = MakeRowDeleter(txn, tableDesc, fkTables,
-			tableCols, SkipFKs, alloc); err != nil {
+			tableCols, SkipFKs, evalCtx, alloc); err != nil {
 			return RowUpdater{}, err
 		}
 		ru.FetchCols = ru.rd.FetchCols
 		ru.FetchColIDtoRowIndex = ColIDtoRowIndexFromCols(ru.FetchCols)
 		if ru.ri, err = MakeRowInserter(txn, tableDesc, fkTables,
-			tableCols, SkipFKs, alloc); err != nil {
+			tableCols, SkipFKs, evalCtx, alloc); err != nil {
 			return RowUpdater{}, err
 		}
 	} else {
@@ -525,7 +671,7 @@ func MakeRowUpdater(
 		for _, fam := range tableDesc.Families {
 			familyBeingUpdated := false
 			for _, colID := range fam.ColumnIDs {
-				if _, ok := ru.updateColIDtoRowIndex[colID]; ok {
+				if ru.columnBeingUpdated(colID) {
 					familyBeingUpdated = true
 					break
 				}
@@ -542,17 +688,54 @@ func MakeRowUpdater(
 			if err := index.RunOverAllColumns(maybeAddCol); err != nil {
 				return RowUpdater{}, err
 			}
+			if index.EncodingType == PrimaryIndexEncoding {
+				for _, fam := range tableDesc.Families {
+					for _, colID := range fam.ColumnIDs {
+						if err := maybeAddCol(colID); err != nil {
+							return RowUpdater{}, err
+						}
+					}
+				}
+			}
+		}
+		for _, check := range ru.checks {
+			for _, sourceID := range check.SourceColumnIDs() {
+				if err := maybeAddCol(sourceID); err != nil {
+					return RowUpdater{}, err
+				}
+			}
+		}
+		for colID, conv := range ru.converters {
+			if err := maybeAddCol(colID); err != nil {
+				return RowUpdater{}, err
+			}
+			for _, sourceID := range conv.SourceColumnIDs {
+				if err := maybeAddCol(sourceID); err != nil {
+					return RowUpdater{}, err
+				}
+			}
 		}
 	}
 
 	var err error
 	if ru.Fks, err = makeFKUpdateHelper(txn, *tableDesc, fkTables,
-		ru.FetchColIDtoRowIndex, alloc); err != nil {
+		ru.FetchColIDtoRowIndex, evalCtx, alloc); err != nil {
 		return RowUpdater{}, err
 	}
 	return ru, nil
 }
 
+// columnBeingUpdated returns whether the value of the column is changed by
+// the update, either directly or because it is computed from an updated
+// column.
+func (ru *RowUpdater) columnBeingUpdated(colID ColumnID) bool {
+	if _, ok := ru.updateColIDtoRowIndex[colID]; ok {
+		return true
+	}
+	_, ok := ru.converters[colID]
+	return ok
+}
+
 // UpdateRow adds to the batch the kv operations necessary to update a table row
 // with the given values.
 //
@@ -574,16 +757,18 @@ func (ru *RowUpdater) UpdateRow(
 		return nil, errors.Errorf("got %d values but expected %d", len(updateValues), len(ru.UpdateCols))
 	}
 
-	primaryIndexKey, secondaryIndexEntries, err := ru.Helper.encodeIndexes(ru.FetchColIDtoRowIndex, oldValues)
+	primaryIndexKey, err := ru.Helper.encodePrimaryIndex(ru.FetchColIDtoRowIndex, oldValues)
 	if err != nil {
 		return nil, err
 	}
-
-	// The secondary index entries returned by rowHelper.encodeIndexes are only
-	// valid until the next call to encodeIndexes. We need to copy them so that
-	// we can compare against the new secondary index entries.
-	secondaryIndexEntries = append(ru.indexEntriesBuf[:0], secondaryIndexEntries...)
-	ru.indexEntriesBuf = secondaryIndexEntries
+	// The secondary index entries are kept separately for each index, so that
+	// the old ones can be compared against the new ones.
+	ru.oldIndexEntriesBuf, err = ru.Helper.encodeSecondaryIndexesByIndex(
+		ru.FetchColIDtoRowIndex, oldValues, ru.oldIndexEntriesBuf)
+	if err != nil {
+		return nil, err
+	}
+	secondaryIndexEntries := ru.oldIndexEntriesBuf
 
 	// Check that the new value types match the column types. This needs to
 	// happen before index encoding because certain datum types (i.e. tuple)
@@ -599,31 +784,40 @@ func (ru *RowUpdater) UpdateRow(
 	for i, updateCol := range ru.UpdateCols {
 		ru.newValues[ru.FetchColIDtoRowIndex[updateCol.ID]] = updateValues[i]
 	}
+	for _, colID := range ru.converterOrder {
+		val, err := ru.converters[colID].Convert(ru.evalCtx, ru.FetchColIDtoRowIndex, ru.newValues)
+		if err != nil {
+			return nil, err
+		}
+		ru.newValues[ru.FetchColIDtoRowIndex[colID]] = val
+	}
+	for i := range ru.checks {
+		if err := ru.checks[i].Check(ru.evalCtx, ru.FetchColIDtoRowIndex, ru.newValues); err != nil {
+			return nil, err
+		}
+	}
 
 	rowPrimaryKeyChanged := false
-	var newSecondaryIndexEntries []IndexEntry
 	if ru.primaryKeyColChange {
-		var newPrimaryIndexKey []byte
-		newPrimaryIndexKey, newSecondaryIndexEntries, err =
-			ru.Helper.encodeIndexes(ru.FetchColIDtoRowIndex, ru.newValues)
+		newPrimaryIndexKey, err := ru.Helper.encodePrimaryIndex(ru.FetchColIDtoRowIndex, ru.newValues)
 		if err != nil {
 			return nil, err
 		}
 		rowPrimaryKeyChanged = !bytes.Equal(primaryIndexKey, newPrimaryIndexKey)
-	} else {
-		newSecondaryIndexEntries, err =
-			ru.Helper.encodeSecondaryIndexes(ru.FetchColIDtoRowIndex, ru.newValues)
-		if err != nil {
-			return nil, err
-		}
 	}
+	ru.newIndexEntriesBuf, err = ru.Helper.encodeSecondaryIndexesByIndex(
+		ru.FetchColIDtoRowIndex, ru.newValues, ru.newIndexEntriesBuf)
+	if err != nil {
+		return nil, err
+	}
+	newSecondaryIndexEntries := ru.newIndexEntriesBuf
 
 	if rowPrimaryKeyChanged {
 		if err := ru.Fks.checkIdx(ctx, ru.Helper.TableDesc.PrimaryIndex.ID, oldValues, ru.newValues); err != nil {
 			return nil, err
 		}
 		for i := range newSecondaryIndexEntries {
-			if !bytes.Equal(newSecondaryIndexEntries[i].Key, secondaryIndexEntries[i].Key) {
+			if !indexEntryKeysEqual(newSecondaryIndexEntries[i], secondaryIndexEntries[i]) {
 				if err := ru.Fks.checkIdx(ctx, ru.Helper.Indexes[i].ID, oldValues, ru.newValues); err != nil {
 					return nil, err
 				}
@@ -646,7 +840,7 @@ func (ru *RowUpdater) UpdateRow(
 	for i, family := range ru.Helper.TableDesc.Families {
 		update := false
 		for _, colID := range family.ColumnIDs {
-			if _, ok := ru.updateColIDtoRowIndex[colID]; ok {
+			if ru.columnBeingUpdated(colID) {
 				update = true
 				break
 			}
@@ -666,16 +860,22 @@ func (ru *RowUpdater) UpdateRow(
 			// Storage optimization to store DefaultColumnID directly as a value. Also
 			// backwards compatible with the original BaseFormatVersion.
 
-			idx, ok := ru.updateColIDtoRowIndex[family.DefaultColumnID]
-			if !ok {
-				continue
+			marshalled := &ru.value
+			if idx, ok := ru.updateColIDtoRowIndex[family.DefaultColumnID]; ok {
+				marshalled = &ru.marshalled[idx]
+			} else {
+				// The column is computed from the updated ones.
+				idx := ru.FetchColIDtoRowIndex[family.DefaultColumnID]
+				if ru.value, err = MarshalColumnValue(ru.FetchCols[idx], ru.newValues[idx]); err != nil {
+					return nil, err
+				}
 			}
 
 			ru.key = keys.MakeFamilyKey(primaryIndexKey, uint32(family.ID))
 			if traceKV {
-				log.VEventf(ctx, 2, "Put %s -> %v", ru.key, ru.marshalled[idx].PrettyPrint())
+				log.VEventf(ctx, 2, "Put %s -> %v", ru.key, marshalled.PrettyPrint())
 			}
-			b.Put(&ru.key, &ru.marshalled[idx])
+			b.Put(&ru.key, marshalled)
 			ru.key = nil
 
 			continue
@@ -737,8 +937,25 @@ func (ru *RowUpdater) UpdateRow(
 	}
 
 	// Update secondary indexes.
-	for i, newSecondaryIndexEntry := range newSecondaryIndexEntries {
-		secondaryIndexEntry := secondaryIndexEntries[i]
+	for i, newEntries := range newSecondaryIndexEntries {
+		if ru.Helper.Indexes[i].EncodingType == PrimaryIndexEncoding {
+			if err := ru.updatePrimaryEncodedIndex(
+				ctx, b, i, secondaryIndexEntries[i], newEntries, oldValues, traceKV,
+			); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if ru.Helper.Indexes[i].Type == IndexDescriptor_INVERTED {
+			if err := ru.updateInvertedIndex(
+				ctx, b, i, secondaryIndexEntries[i], newEntries, oldValues, traceKV,
+			); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		newSecondaryIndexEntry := newEntries[0]
+		secondaryIndexEntry := secondaryIndexEntries[i][0]
 		var expValue interface{}
 		if !bytes.Equal(newSecondaryIndexEntry.Key, secondaryIndexEntry.Key) {
 			if err := ru.Fks.checkIdx(ctx, ru.Helper.Indexes[i].ID, oldValues, ru.newValues); err != nil {
@@ -766,6 +983,143 @@ func (ru *RowUpdater) UpdateRow(
 	return ru.newValues, nil
 }
 
+// updateInvertedIndex adds to the batch the kv operations necessary to update
+// the entries of the i-th index, which is inverted, from oldEntries to
+// newEntries. Both are sorted by key, and since the entries of an inverted
+// index have no value, only the keys that are not in both need to be touched.
+func (ru *RowUpdater) updateInvertedIndex(
+	ctx context.Context,
+	b *client.Batch,
+	i int,
+	oldEntries, newEntries []IndexEntry,
+	oldValues []parser.Datum,
+	traceKV bool,
+) error {
+	if indexEntryKeysEqual(oldEntries, newEntries) {
+		return nil
+	}
+	if err := ru.Fks.checkIdx(ctx, ru.Helper.Indexes[i].ID, oldValues, ru.newValues); err != nil {
+		return err
+	}
+	_, deleteOnly := ru.deleteOnlyIndex[i]
+	for len(oldEntries) > 0 || len(newEntries) > 0 {
+		var c int
+		switch {
+		case len(oldEntries) == 0:
+			c = 1
+		case len(newEntries) == 0:
+			c = -1
+		default:
+			c = bytes.Compare(oldEntries[0].Key, newEntries[0].Key)
+		}
+		switch {
+		case c < 0:
+			if traceKV {
+				log.VEventf(ctx, 2, "Del %s", oldEntries[0].Key)
+			}
+			b.Del(oldEntries[0].Key)
+			oldEntries = oldEntries[1:]
+		case c > 0:
+			// Do not update Indexes in the DELETE_ONLY state.
+			if !deleteOnly {
+				e := &newEntries[0]
+				if traceKV {
+					log.VEventf(ctx, 2, "CPut %s -> %v", e.Key, e.Value.PrettyPrint())
+				}
+				b.CPut(e.Key, &e.Value, nil)
+			}
+			newEntries = newEntries[1:]
+		default:
+			oldEntries, newEntries = oldEntries[1:], newEntries[1:]
+		}
+	}
+	return nil
+}
+
+// updatePrimaryEncodedIndex adds to the batch the kv operations necessary to
+// update the entries of the i-th index, which has PrimaryIndexEncoding, from
+// oldEntries to newEntries. Both hold one entry per non-empty column family,
+// sorted by key: as in the primary index, the families that became empty are
+// deleted and the others are only written if their value changed.
+func (ru *RowUpdater) updatePrimaryEncodedIndex(
+	ctx context.Context,
+	b *client.Batch,
+	i int,
+	oldEntries, newEntries []IndexEntry,
+	oldValues []parser.Datum,
+	traceKV bool,
+) error {
+	// The entry of family 0 is always present, so the row is keyed
+	// differently if the first keys differ.
+	rowKeyChanged := !bytes.Equal(oldEntries[0].Key, newEntries[0].Key)
+	if rowKeyChanged {
+		if err := ru.Fks.checkIdx(ctx, ru.Helper.Indexes[i].ID, oldValues, ru.newValues); err != nil {
+			return err
+		}
+	}
+	_, deleteOnly := ru.deleteOnlyIndex[i]
+	for len(oldEntries) > 0 || len(newEntries) > 0 {
+		var c int
+		switch {
+		case len(oldEntries) == 0:
+			c = 1
+		case len(newEntries) == 0:
+			c = -1
+		default:
+			c = bytes.Compare(oldEntries[0].Key, newEntries[0].Key)
+		}
+		switch {
+		case c < 0:
+			if traceKV {
+				log.VEventf(ctx, 2, "Del %s", oldEntries[0].Key)
+			}
+			b.Del(oldEntries[0].Key)
+			oldEntries = oldEntries[1:]
+		case c > 0:
+			// Do not update Indexes in the DELETE_ONLY state.
+			if !deleteOnly {
+				e := &newEntries[0]
+				if rowKeyChanged {
+					// The new row must not collide with an existing one.
+					if traceKV {
+						log.VEventf(ctx, 2, "CPut %s -> %v", e.Key, e.Value.PrettyPrint())
+					}
+					b.CPut(e.Key, &e.Value, nil)
+				} else {
+					if traceKV {
+						log.VEventf(ctx, 2, "Put %s -> %v", e.Key, e.Value.PrettyPrint())
+					}
+					b.Put(e.Key, &e.Value)
+				}
+			}
+			newEntries = newEntries[1:]
+		default:
+			e := &newEntries[0]
+			if !deleteOnly && !bytes.Equal(oldEntries[0].Value.RawBytes, e.Value.RawBytes) {
+				if traceKV {
+					log.VEventf(ctx, 2, "Put %s -> %v", e.Key, e.Value.PrettyPrint())
+				}
+				b.Put(e.Key, &e.Value)
+			}
+			oldEntries, newEntries = oldEntries[1:], newEntries[1:]
+		}
+	}
+	return nil
+}
+
+// indexEntryKeysEqual returns whether a and b have the same keys.
+func indexEntryKeysEqual(a, b []IndexEntry) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i].Key, b[i].Key) {
+			return false
+		}
+	}
+	return true
+}
+
 // IsColumnOnlyUpdate returns true if this RowUpdater is only updating column
 // data (in contrast to updating the primary key or other indexes).
 func (ru *RowUpdater) IsColumnOnlyUpdate() bool {
@@ -784,6 +1138,10 @@ type RowDeleter struct {
 	FetchCols            []ColumnDescriptor
 	FetchColIDtoRowIndex map[ColumnID]int
 	Fks                  fkDeleteHelper
+	// primaryEncodedIndexes are the indexes with PrimaryIndexEncoding, whose
+	// rows are deleted like the rows of the primary index. They are not part
+	// of Helper.Indexes.
+	primaryEncodedIndexes []IndexDescriptor
 	// For allocation avoidance.
 	startKey roachpb.Key
 	endKey   roachpb.Key
@@ -794,17 +1152,26 @@ type RowDeleter struct {
 // The returned RowDeleter contains a FetchCols field that defines the
 // expectation of which values are passed as values to DeleteRow. Any column
 // passed in requestedCols will be included in FetchCols.
+//
+// The evalCtx is used to evaluate the default values of the referencing
+// columns for the ON DELETE SET DEFAULT referential action.
 func MakeRowDeleter(
 	txn *client.Txn,
 	tableDesc *TableDescriptor,
 	fkTables TableLookupsByID,
 	requestedCols []ColumnDescriptor,
 	checkFKs bool,
+	evalCtx *parser.EvalContext,
 	alloc *DatumAlloc,
 ) (RowDeleter, error) {
 	indexes := tableDesc.Indexes
+	var primaryEncodedIndexes []IndexDescriptor
 	for _, m := range tableDesc.Mutations {
 		if index := m.GetIndex(); index != nil {
+			if index.EncodingType == PrimaryIndexEncoding {
+				primaryEncodedIndexes = append(primaryEncodedIndexes, *index)
+				continue
+			}
 			indexes = append(indexes, *index)
 		}
 	}
@@ -841,16 +1208,24 @@ func MakeRowDeleter(
 			}
 		}
 	}
+	for _, index := range primaryEncodedIndexes {
+		for _, colID := range index.ColumnIDs {
+			if err := maybeAddCol(colID); err != nil {
+				return RowDeleter{}, err
+			}
+		}
+	}
 
 	rd := RowDeleter{
-		Helper:               rowHelper{TableDesc: tableDesc, Indexes: indexes},
-		FetchCols:            fetchCols,
-		FetchColIDtoRowIndex: fetchColIDtoRowIndex,
+		Helper:                rowHelper{TableDesc: tableDesc, Indexes: indexes},
+		FetchCols:             fetchCols,
+		FetchColIDtoRowIndex:  fetchColIDtoRowIndex,
+		primaryEncodedIndexes: primaryEncodedIndexes,
 	}
 	if checkFKs {
 		var err error
 		if rd.Fks, err = makeFKDeleteHelper(txn, *tableDesc, fkTables,
-			fetchColIDtoRowIndex, alloc); err != nil {
+			fetchColIDtoRowIndex, CheckDeletes, evalCtx, alloc); err != nil {
 			return RowDeleter{}, err
 		}
 	}
@@ -859,7 +1234,9 @@ func MakeRowDeleter(
 }
 
 // DeleteRow adds to the batch the kv operations necessary to delete a table row
-// with the given values.
+// with the given values. The referential actions of the foreign keys
+// referencing the row, if any, are carried out right away in the transaction
+// of the RowDeleter.
 func (rd *RowDeleter) DeleteRow(
 	ctx context.Context, b *client.Batch, values []parser.Datum, traceKV bool,
 ) error {
@@ -879,16 +1256,50 @@ func (rd *RowDeleter) DeleteRow(
 		b.Del(secondaryIndexEntry.Key)
 	}
 
+	for i := range rd.primaryEncodedIndexes {
+		if err := rd.deletePrimaryEncodedRow(ctx, b, &rd.primaryEncodedIndexes[i], values, traceKV); err != nil {
+			return err
+		}
+	}
+
 	// Delete the row.
-	rd.startKey = roachpb.Key(primaryIndexKey)
-	rd.endKey = roachpb.Key(encoding.EncodeNotNullDescending(primaryIndexKey))
+	rd.deleteRowRange(ctx, b, primaryIndexKey, traceKV)
+
+	return nil
+}
+
+// deletePrimaryEncodedRow adds to the batch the kv operations necessary to
+// delete a table row from an index with PrimaryIndexEncoding. As in the
+// primary index, the row is deleted as a whole, since the families whose
+// columns were not fetched may have entries.
+func (rd *RowDeleter) deletePrimaryEncodedRow(
+	ctx context.Context,
+	b *client.Batch,
+	idx *IndexDescriptor,
+	values []parser.Datum,
+	traceKV bool,
+) error {
+	indexKey, _, err := EncodeIndexKey(rd.Helper.TableDesc, idx, rd.FetchColIDtoRowIndex, values,
+		MakeIndexKeyPrefix(rd.Helper.TableDesc, idx.ID))
+	if err != nil {
+		return err
+	}
+	rd.deleteRowRange(ctx, b, indexKey, traceKV)
+	return nil
+}
+
+// deleteRowRange adds to the batch the DelRange of all the family entries of
+// the row with the given key.
+func (rd *RowDeleter) deleteRowRange(
+	ctx context.Context, b *client.Batch, rowKey []byte, traceKV bool,
+) {
+	rd.startKey = roachpb.Key(rowKey)
+	rd.endKey = roachpb.Key(encoding.EncodeNotNullDescending(rowKey))
 	if traceKV {
 		log.VEventf(ctx, 2, "DelRange %s - %s", rd.startKey, rd.endKey)
 	}
 	b.DelRange(&rd.startKey, &rd.endKey, false)
 	rd.startKey, rd.endKey = nil, nil
-
-	return nil
 }
 
 // DeleteIndexRow adds to the batch the kv operations necessary to delete a
@@ -899,15 +1310,20 @@ func (rd *RowDeleter) DeleteIndexRow(
 	if err := rd.Fks.checkAll(ctx, values); err != nil {
 		return err
 	}
-	secondaryIndexEntry, err := EncodeSecondaryIndex(
+	if idx.EncodingType == PrimaryIndexEncoding {
+		return rd.deletePrimaryEncodedRow(ctx, b, idx, values, traceKV)
+	}
+	secondaryIndexEntries, err := EncodeSecondaryIndex(
 		rd.Helper.TableDesc, idx, rd.FetchColIDtoRowIndex, values)
 	if err != nil {
 		return err
 	}
-	if traceKV {
-		log.VEventf(ctx, 2, "Del %s", secondaryIndexEntry.Key)
+	for _, secondaryIndexEntry := range secondaryIndexEntries {
+		if traceKV {
+			log.VEventf(ctx, 2, "Del %s", secondaryIndexEntry.Key)
+		}
+		b.Del(secondaryIndexEntry.Key)
 	}
-	b.Del(secondaryIndexEntry.Key)
 	return nil
 }
 
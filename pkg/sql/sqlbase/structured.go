@@ -64,6 +64,21 @@ type FamilyID uint32
 // IndexID is a custom type for IndexDescriptor IDs.
 type IndexID parser.IndexID
 
+// IndexDescriptorEncodingType is a custom type for the encoding of the
+// entries of an index.
+type IndexDescriptorEncodingType uint32
+
+const (
+	// SecondaryIndexEncoding is the encoding of secondary indexes: one entry
+	// per row (or per path, for inverted indexes). It is the zero value, used
+	// by all existing indexes.
+	SecondaryIndexEncoding IndexDescriptorEncodingType = iota
+	// PrimaryIndexEncoding is the encoding of the rows of the primary index,
+	// with one entry per column family. It is used by an index being built to
+	// replace the primary index of a table.
+	PrimaryIndexEncoding
+)
+
 // DescriptorVersion is a custom type for TableDescriptor Versions.
 type DescriptorVersion uint32
 
@@ -210,11 +225,17 @@ func (desc *IndexDescriptor) allocateName(tableDesc *TableDescriptor) {
 	desc.Name = name
 }
 
-// FillColumns sets the column names and directions in desc.
+// FillColumns sets the column names and directions in desc. The elements
+// must all be columns; expressions are replaced by the hidden columns storing
+// their values before the index is filled.
 func (desc *IndexDescriptor) FillColumns(elems parser.IndexElemList) error {
 	desc.ColumnNames = make([]string, 0, len(elems))
 	desc.ColumnDirections = make([]IndexDescriptor_Direction, 0, len(elems))
 	for _, c := range elems {
+		if c.Expr != nil {
+			return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"expressions are not allowed in this index: %s", c.Expr)
+		}
 		desc.ColumnNames = append(desc.ColumnNames, string(c.Column))
 		switch c.Direction {
 		case parser.Ascending, parser.DefaultDirection:
@@ -273,23 +294,49 @@ func (desc *IndexDescriptor) FullColumnIDs() ([]ColumnID, []encoding.Direction)
 }
 
 // ColNamesString returns a string describing the column names and directions
-// in this index.
-func (desc *IndexDescriptor) ColNamesString() string {
+// in this index. The indexed expressions are formatted with the names of the
+// columns of tableDesc.
+func (desc *IndexDescriptor) ColNamesString(tableDesc *TableDescriptor) string {
 	var buf bytes.Buffer
 	for i, name := range desc.ColumnNames {
 		if i > 0 {
 			buf.WriteString(", ")
 		}
+		if expr := desc.Expression(i); expr != "" {
+			fmt.Fprintf(&buf, "(%s) %s", tableDesc.FormatColumnExpr(expr), desc.ColumnDirections[i])
+			continue
+		}
 		fmt.Fprintf(&buf, "%s %s", parser.Name(name), desc.ColumnDirections[i])
 	}
 	return buf.String()
 }
 
+// Expression returns the serialized expression indexed by the i-th column of
+// the index, or an empty string if the column is indexed directly.
+func (desc *IndexDescriptor) Expression(i int) string {
+	if i < len(desc.Expressions) {
+		return desc.Expressions[i]
+	}
+	return ""
+}
+
+// HasExpressions returns whether some of the columns of the index store the
+// values of an expression.
+func (desc *IndexDescriptor) HasExpressions() bool {
+	for _, expr := range desc.Expressions {
+		if expr != "" {
+			return true
+		}
+	}
+	return false
+}
+
 var isUnique = map[bool]string{true: "UNIQUE "}
+var isInverted = map[bool]string{true: "INVERTED "}
 
-// SQLString returns the SQL string describing this index. If non-empty,
-// "ON tableName" is included in the output in the correct place.
-func (desc *IndexDescriptor) SQLString(tableName string) string {
+// SQLString returns the SQL string describing this index of tableDesc. If
+// non-empty, "ON tableName" is included in the output in the correct place.
+func (desc *IndexDescriptor) SQLString(tableDesc *TableDescriptor, tableName string) string {
 	var storing string
 	if len(desc.StoreColumnNames) > 0 {
 		colNames := make(parser.NameList, len(desc.StoreColumnNames))
@@ -302,11 +349,12 @@ func (desc *IndexDescriptor) SQLString(tableName string) string {
 	if tableName != "" {
 		onTable = fmt.Sprintf("ON %s ", tableName)
 	}
-	return fmt.Sprintf("%sINDEX %s%s (%s)%s",
+	return fmt.Sprintf("%s%sINDEX %s%s (%s)%s",
 		isUnique[desc.Unique],
+		isInverted[desc.Type == IndexDescriptor_INVERTED],
 		onTable,
 		parser.AsString(parser.Name(desc.Name)),
-		desc.ColNamesString(),
+		desc.ColNamesString(tableDesc),
 		storing,
 	)
 }
@@ -335,7 +383,7 @@ func (desc *TableDescriptor) IsEmpty() bool {
 // IsTable returns true if the TableDescriptor actually describes a
 // Table resource, as opposed to a different resource (like a View).
 func (desc *TableDescriptor) IsTable() bool {
-	return !desc.IsView()
+	return !desc.IsView() && !desc.IsSequence()
 }
 
 // IsView returns true if the TableDescriptor actually describes a
@@ -344,6 +392,29 @@ func (desc *TableDescriptor) IsView() bool {
 	return desc.ViewQuery != ""
 }
 
+// IsMaterializedView returns true if the TableDescriptor describes a
+// materialized view, i.e. a View whose results are stored like the rows of a
+// Table.
+func (desc *TableDescriptor) IsMaterializedView() bool {
+	return desc.IsView() && desc.MaterializedView
+}
+
+// IsSequence returns true if the TableDescriptor actually describes a
+// Sequence resource rather than a Table.
+func (desc *TableDescriptor) IsSequence() bool {
+	return desc.SequenceOpts != nil
+}
+
+// HasEvent returns true if the trigger fires for the given event.
+func (t *TableDescriptor_Trigger) HasEvent(event TableDescriptor_Trigger_Event) bool {
+	for _, e := range t.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
 // IsVirtualTable returns true if the TableDescriptor describes a
 // virtual Table (like the information_schema tables) and thus doesn't
 // need to be physically stored.
@@ -355,8 +426,9 @@ func (desc *TableDescriptor) IsVirtualTable() bool {
 // physical Table that needs to be stored in the kv layer, as opposed to a
 // different resource like a view or a virtual table. Physical tables have
 // primary keys, column families, and indexes (unlike virtual tables).
+// Materialized views store their rows and are physical tables as well.
 func (desc *TableDescriptor) IsPhysicalTable() bool {
-	return desc.IsTable() && !desc.IsVirtualTable()
+	return (desc.IsTable() || desc.IsMaterializedView()) && !desc.IsVirtualTable()
 }
 
 // KeysPerRow returns the maximum number of keys used to encode a row for the
@@ -596,7 +668,7 @@ func HasCompositeKeyEncoding(semanticType ColumnType_SemanticType) bool {
 // MustBeValueEncoded returns true if columns of the given kind can only be value
 // encoded.
 func MustBeValueEncoded(semanticType ColumnType_SemanticType) bool {
-	return semanticType == ColumnType_ARRAY
+	return semanticType == ColumnType_ARRAY || semanticType == ColumnType_JSON
 }
 
 // HasOldStoredColumns returns whether the index has stored columns in the old
@@ -625,9 +697,27 @@ func (desc *TableDescriptor) allocateIndexIDs(columnNames map[string]ColumnID) e
 	for i := range desc.Indexes {
 		collectIndexes(&desc.Indexes[i])
 	}
+	// The indexes being dropped keep the columns they were built with. The
+	// indexes added along with an index replacing the primary index, by
+	// ALTER COLUMN ... TYPE, are built against the new primary index.
+	dropped := make(map[*IndexDescriptor]struct{})
+	newPrimaryIndexes := make(map[MutationID]*IndexDescriptor)
 	for _, m := range desc.Mutations {
 		if index := m.GetIndex(); index != nil {
 			collectIndexes(index)
+			if m.Direction == DescriptorMutation_DROP {
+				dropped[index] = struct{}{}
+			} else if index.EncodingType == PrimaryIndexEncoding {
+				newPrimaryIndexes[m.MutationID] = index
+			}
+		}
+	}
+	primaryIndexOf := make(map[*IndexDescriptor]*IndexDescriptor)
+	for _, m := range desc.Mutations {
+		if index := m.GetIndex(); index != nil && m.Direction == DescriptorMutation_ADD {
+			if primary, ok := newPrimaryIndexes[m.MutationID]; ok && primary != index {
+				primaryIndexOf[index] = primary
+			}
 		}
 	}
 
@@ -641,6 +731,11 @@ func (desc *TableDescriptor) allocateIndexIDs(columnNames map[string]ColumnID) e
 			isCompositeColumn[col.ID] = struct{}{}
 		}
 	}
+	for _, m := range desc.Mutations {
+		if col := m.GetColumn(); col != nil && HasCompositeKeyEncoding(col.Type.SemanticType) {
+			isCompositeColumn[col.ID] = struct{}{}
+		}
+	}
 
 	// Populate IDs.
 	for _, index := range indexes {
@@ -656,15 +751,23 @@ func (desc *TableDescriptor) allocateIndexIDs(columnNames map[string]ColumnID) e
 				index.ColumnIDs[j] = columnNames[colName]
 			}
 		}
+	}
 
-		if index != &desc.PrimaryIndex {
+	// Populate the columns each index stores besides its own.
+	for _, index := range indexes {
+		_, isDropped := dropped[index]
+		if index != &desc.PrimaryIndex && index.EncodingType != PrimaryIndexEncoding && !isDropped {
+			primaryIndex := &desc.PrimaryIndex
+			if primary, ok := primaryIndexOf[index]; ok {
+				primaryIndex = primary
+			}
 			indexHasOldStoredColumns := index.HasOldStoredColumns()
 			// Need to clear ExtraColumnIDs and StoreColumnIDs because they are used
 			// by ContainsColumnID.
 			index.ExtraColumnIDs = nil
 			index.StoreColumnIDs = nil
 			var extraColumnIDs []ColumnID
-			for _, primaryColID := range desc.PrimaryIndex.ColumnIDs {
+			for _, primaryColID := range primaryIndex.ColumnIDs {
 				if !index.ContainsColumnID(primaryColID) {
 					extraColumnIDs = append(extraColumnIDs, primaryColID)
 				}
@@ -676,7 +779,7 @@ func (desc *TableDescriptor) allocateIndexIDs(columnNames map[string]ColumnID) e
 				if err != nil {
 					return err
 				}
-				if desc.PrimaryIndex.ContainsColumnID(col.ID) {
+				if primaryIndex.ContainsColumnID(col.ID) {
 					continue
 				}
 				if index.ContainsColumnID(col.ID) {
@@ -1003,6 +1106,10 @@ func (desc *TableDescriptor) ValidateTable() error {
 			desc.Name, desc.GetFormatVersion(), FamilyFormatVersion, InterleavedFormatVersion)
 	}
 
+	if desc.IsSequence() {
+		return desc.validateSequence()
+	}
+
 	if len(desc.Columns) == 0 {
 		return ErrMissingColumns
 	}
@@ -1057,8 +1164,12 @@ func (desc *TableDescriptor) ValidateTable() error {
 				idx := desc.Index
 				return errors.Errorf("mutation in state %s, direction %s, index %s, id %v", m.State, m.Direction, idx.Name, idx.ID)
 			}
+		case *DescriptorMutation_NotNull:
+			if unSetEnums {
+				return errors.Errorf("mutation in state %s, direction %s, not null constraint on column id %v", m.State, m.Direction, desc.NotNull.ColumnID)
+			}
 		default:
-			return errors.Errorf("mutation in state %s, direction %s, and no column/index descriptor", m.State, m.Direction)
+			return errors.Errorf("mutation in state %s, direction %s, and no column/index/constraint descriptor", m.State, m.Direction)
 		}
 	}
 
@@ -1074,12 +1185,37 @@ func (desc *TableDescriptor) ValidateTable() error {
 		if err := desc.validateTableIndexes(columnNames, colIDToFamilyID); err != nil {
 			return err
 		}
+		if err := desc.validatePartitioning(); err != nil {
+			return err
+		}
 	}
 
 	// Validate the privilege descriptor.
 	return desc.Privileges.Validate(desc.GetID())
 }
 
+// validateSequence validates the options of a sequence descriptor. Sequences
+// have no columns, indexes or mutations.
+func (desc *TableDescriptor) validateSequence() error {
+	if len(desc.Columns) > 0 || len(desc.Indexes) > 0 || len(desc.Mutations) > 0 {
+		return fmt.Errorf("sequence %q cannot have columns, indexes or mutations", desc.Name)
+	}
+	opts := desc.SequenceOpts
+	if opts.Increment == 0 {
+		return fmt.Errorf("INCREMENT must not be zero")
+	}
+	if opts.MinValue >= opts.MaxValue {
+		return fmt.Errorf("MINVALUE (%d) must be less than MAXVALUE (%d)", opts.MinValue, opts.MaxValue)
+	}
+	if opts.Start < opts.MinValue {
+		return fmt.Errorf("START value (%d) cannot be less than MINVALUE (%d)", opts.Start, opts.MinValue)
+	}
+	if opts.Start > opts.MaxValue {
+		return fmt.Errorf("START value (%d) cannot be greater than MAXVALUE (%d)", opts.Start, opts.MaxValue)
+	}
+	return desc.Privileges.Validate(desc.GetID())
+}
+
 func (desc *TableDescriptor) validateColumnFamilies(
 	columnIDs map[ColumnID]string,
 ) (map[ColumnID]FamilyID, error) {
@@ -1193,6 +1329,10 @@ func (desc *TableDescriptor) validateTableIndexes(
 			return fmt.Errorf("mismatched column IDs (%d) and directions (%d)",
 				len(index.ColumnIDs), len(index.ColumnDirections))
 		}
+		if len(index.Expressions) > 0 && len(index.ColumnIDs) != len(index.Expressions) {
+			return fmt.Errorf("mismatched column IDs (%d) and expressions (%d)",
+				len(index.ColumnIDs), len(index.Expressions))
+		}
 
 		if len(index.ColumnIDs) == 0 {
 			return fmt.Errorf("index %q must contain at least 1 column", index.Name)
@@ -1239,6 +1379,8 @@ func upperBoundColumnValueEncodedSize(col ColumnDescriptor) (int, bool) {
 		typ = encoding.Float
 	case ColumnType_INTERVAL:
 		typ = encoding.Duration
+	case ColumnType_INET:
+		typ = encoding.IPAddr
 	case ColumnType_STRING, ColumnType_BYTES, ColumnType_COLLATEDSTRING, ColumnType_NAME, ColumnType_UUID:
 		// STRINGs are counted as runes, so this isn't totally correct, but this
 		// seems better than always assuming the maximum rune width.
@@ -1315,10 +1457,16 @@ func notIndexableError(cols []ColumnDescriptor) error {
 	return errors.New(result)
 }
 
-func checkColumnsValidForIndex(tableDesc *TableDescriptor, indexColNames []string) error {
-	invalidColumns := make([]ColumnDescriptor, 0, len(indexColNames))
-	for _, indexCol := range indexColNames {
-		for _, col := range tableDesc.Columns {
+func checkColumnsValidForIndex(tableDesc *TableDescriptor, idx *IndexDescriptor) error {
+	if idx.Type == IndexDescriptor_INVERTED {
+		return checkColumnsValidForInvertedIndex(tableDesc, idx)
+	}
+	invalidColumns := make([]ColumnDescriptor, 0, len(idx.ColumnNames))
+	// The columns being added are checked too, for the indexes rebuilt over
+	// a column being rewritten to a new type.
+	cols := tableDesc.allNonDropColumns()
+	for _, indexCol := range idx.ColumnNames {
+		for _, col := range cols {
 			if col.Name == indexCol {
 				if !columnTypeIsIndexable(col.Type) {
 					invalidColumns = append(invalidColumns, col)
@@ -1332,6 +1480,32 @@ func checkColumnsValidForIndex(tableDesc *TableDescriptor, indexColNames []strin
 	return nil
 }
 
+// checkColumnsValidForInvertedIndex verifies that an inverted index is
+// defined on a single JSON column, which is the only kind of column that can
+// be indexed this way.
+func checkColumnsValidForInvertedIndex(tableDesc *TableDescriptor, idx *IndexDescriptor) error {
+	if len(idx.ColumnNames) != 1 {
+		return pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+			"inverted indexes don't support multi-column indexes")
+	}
+	if idx.Unique {
+		return pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+			"inverted indexes can't be unique")
+	}
+	if len(idx.StoreColumnNames) > 0 {
+		return pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+			"inverted indexes don't support stored columns")
+	}
+	for _, col := range tableDesc.allNonDropColumns() {
+		if col.Name == idx.ColumnNames[0] && col.Type.SemanticType != ColumnType_JSON {
+			return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"column %s of type %s is not allowed as the last column in an inverted index",
+				col.Name, col.Type.SemanticType)
+		}
+	}
+	return nil
+}
+
 // AddColumn adds a column to the table.
 func (desc *TableDescriptor) AddColumn(col ColumnDescriptor) {
 	desc.Columns = append(desc.Columns, col)
@@ -1344,10 +1518,14 @@ func (desc *TableDescriptor) AddFamily(fam ColumnFamilyDescriptor) {
 
 // AddIndex adds an index to the table.
 func (desc *TableDescriptor) AddIndex(idx IndexDescriptor, primary bool) error {
-	if err := checkColumnsValidForIndex(desc, idx.ColumnNames); err != nil {
+	if err := checkColumnsValidForIndex(desc, &idx); err != nil {
 		return err
 	}
 	if primary {
+		if idx.Type == IndexDescriptor_INVERTED {
+			return pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+				"primary keys can't be inverted indexes")
+		}
 		// PrimaryIndex is unset.
 		if desc.PrimaryIndex.Name == "" {
 			if idx.Name == "" {
@@ -1640,12 +1818,26 @@ func (desc *TableDescriptor) MakeMutationComplete(m DescriptorMutation) {
 	case DescriptorMutation_ADD:
 		switch t := m.Descriptor_.(type) {
 		case *DescriptorMutation_Column:
+			if m.Conversion != nil {
+				desc.completeColumnConversion(*t.Column, m)
+				break
+			}
 			desc.AddColumn(*t.Column)
 
 		case *DescriptorMutation_Index:
+			if desc.completeIndexReplacement(*t.Index, m) {
+				break
+			}
 			if err := desc.AddIndex(*t.Index, false); err != nil {
 				panic(err)
 			}
+
+		case *DescriptorMutation_NotNull:
+			col, err := desc.FindActiveColumnByID(t.NotNull.ColumnID)
+			if err != nil {
+				panic(err)
+			}
+			col.Nullable = false
 		}
 
 	case DescriptorMutation_DROP:
@@ -1658,6 +1850,121 @@ func (desc *TableDescriptor) MakeMutationComplete(m DescriptorMutation) {
 	}
 }
 
+// completeColumnConversion puts col, which was added by a mutation rewriting
+// another column to a new type, in place of that column. The two columns
+// exchange their names, and the source column is queued to be dropped as part
+// of the same mutation.
+func (desc *TableDescriptor) completeColumnConversion(col ColumnDescriptor, m DescriptorMutation) {
+	for i := range desc.Columns {
+		source := desc.Columns[i]
+		if source.ID != m.Conversion.SourceColumnID {
+			continue
+		}
+		desc.RenameColumnDescriptor(source, col.Name)
+		desc.Columns[i] = col
+		desc.RenameColumnDescriptor(col, source.Name)
+		desc.Columns[i].Hidden = source.Hidden
+
+		source.Name = col.Name
+		desc.Mutations = append(desc.Mutations, DescriptorMutation{
+			Descriptor_: &DescriptorMutation_Column{Column: &source},
+			Direction:   DescriptorMutation_DROP,
+			State:       DescriptorMutation_DELETE_AND_WRITE_ONLY,
+			MutationID:  m.MutationID,
+		})
+		return
+	}
+	panic(fmt.Sprintf("column-id \"%d\" converted by column %q does not exist",
+		m.Conversion.SourceColumnID, col.Name))
+}
+
+// completeIndexReplacement puts idx, if it was added by a mutation rewriting
+// a column to a new type to replace an index referring to that column, in
+// place of that index, and returns true. The replacement takes the name of
+// the index it replaces, which is queued to be dropped as part of the same
+// mutation along with the columns storing its expressions, if any.
+//
+// The replaced index is dropped in the DELETE_ONLY state: the writers using
+// the new descriptor no longer maintain the values of the rewritten column,
+// so they can't write its entries. The readers using the previous version of
+// the descriptor may thus miss the rows written in the meantime, until the
+// new version is the only one in use.
+func (desc *TableDescriptor) completeIndexReplacement(
+	idx IndexDescriptor, m DescriptorMutation,
+) bool {
+	var replacedID IndexID
+	for _, other := range desc.Mutations {
+		if other.Conversion == nil || other.MutationID != m.MutationID {
+			continue
+		}
+		for i, id := range other.Conversion.ReplacementIndexIDs {
+			if id == idx.ID {
+				replacedID = other.Conversion.ReplacedIndexIDs[i]
+			}
+		}
+	}
+	if replacedID == 0 {
+		return false
+	}
+
+	var replaced IndexDescriptor
+	if desc.PrimaryIndex.ID == replacedID {
+		replaced = desc.PrimaryIndex
+		// The entries of the old primary index are deleted like rows, until
+		// the index is truncated.
+		replaced.EncodingType = PrimaryIndexEncoding
+		idx.EncodingType = SecondaryIndexEncoding
+		idx.Name = replaced.Name
+		desc.PrimaryIndex = idx
+	} else {
+		found := false
+		for i := range desc.Indexes {
+			if desc.Indexes[i].ID == replacedID {
+				replaced = desc.Indexes[i]
+				desc.Indexes = append(desc.Indexes[:i], desc.Indexes[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			panic(fmt.Sprintf("index-id \"%d\" replaced by index %q does not exist", replacedID, idx.Name))
+		}
+		idx.Name = replaced.Name
+		if err := desc.AddIndex(idx, false); err != nil {
+			panic(err)
+		}
+	}
+	desc.Mutations = append(desc.Mutations, DescriptorMutation{
+		Descriptor_: &DescriptorMutation_Index{Index: &replaced},
+		Direction:   DescriptorMutation_DROP,
+		State:       DescriptorMutation_DELETE_ONLY,
+		MutationID:  m.MutationID,
+	})
+
+	for i, expr := range replaced.Expressions {
+		if expr == "" || idx.ContainsColumnID(replaced.ColumnIDs[i]) {
+			// The expressions that don't refer to the rewritten column are
+			// stored in the same column by the replacement.
+			continue
+		}
+		for j := range desc.Columns {
+			col := desc.Columns[j]
+			if col.ID != replaced.ColumnIDs[i] {
+				continue
+			}
+			desc.Columns = append(desc.Columns[:j], desc.Columns[j+1:]...)
+			desc.Mutations = append(desc.Mutations, DescriptorMutation{
+				Descriptor_: &DescriptorMutation_Column{Column: &col},
+				Direction:   DescriptorMutation_DROP,
+				State:       DescriptorMutation_DELETE_AND_WRITE_ONLY,
+				MutationID:  m.MutationID,
+			})
+			break
+		}
+	}
+	return true
+}
+
 // AddColumnMutation adds a column mutation to desc.Mutations.
 func (desc *TableDescriptor) AddColumnMutation(
 	c ColumnDescriptor, direction DescriptorMutation_Direction,
@@ -1666,11 +1973,55 @@ func (desc *TableDescriptor) AddColumnMutation(
 	desc.addMutation(m)
 }
 
+// AddColumnConversionMutation adds a mutation to desc.Mutations that adds c,
+// computing its values from an existing column as described by conv.
+func (desc *TableDescriptor) AddColumnConversionMutation(
+	c ColumnDescriptor, conv DescriptorMutation_ColumnConversion,
+) {
+	m := DescriptorMutation{
+		Descriptor_: &DescriptorMutation_Column{Column: &c},
+		Direction:   DescriptorMutation_ADD,
+		Conversion:  &conv,
+	}
+	desc.addMutation(m)
+}
+
+// AddNotNullMutation adds a mutation to desc.Mutations that adds or drops
+// the NOT NULL constraint of a column.
+func (desc *TableDescriptor) AddNotNullMutation(
+	colID ColumnID, direction DescriptorMutation_Direction,
+) {
+	m := DescriptorMutation{
+		Descriptor_: &DescriptorMutation_NotNull{
+			NotNull: &DescriptorMutation_NotNullConstraint{ColumnID: colID},
+		},
+		Direction: direction,
+	}
+	desc.addMutation(m)
+}
+
+// ColumnAcceptsNulls returns whether NULL may be written to col. This is not
+// the case for non-nullable columns, nor for columns whose NOT NULL
+// constraint is being added and must already be honored by writes while the
+// existing rows are validated.
+func (desc *TableDescriptor) ColumnAcceptsNulls(col *ColumnDescriptor) bool {
+	if !col.Nullable {
+		return false
+	}
+	for _, m := range desc.Mutations {
+		if nn := m.GetNotNull(); nn != nil && nn.ColumnID == col.ID &&
+			m.Direction == DescriptorMutation_ADD && m.State == DescriptorMutation_DELETE_AND_WRITE_ONLY {
+			return false
+		}
+	}
+	return true
+}
+
 // AddIndexMutation adds an index mutation to desc.Mutations.
 func (desc *TableDescriptor) AddIndexMutation(
 	idx IndexDescriptor, direction DescriptorMutation_Direction,
 ) error {
-	if err := checkColumnsValidForIndex(desc, idx.ColumnNames); err != nil {
+	if err := checkColumnsValidForIndex(desc, &idx); err != nil {
 		return err
 	}
 	m := DescriptorMutation{Descriptor_: &DescriptorMutation_Index{Index: &idx}, Direction: direction}
@@ -1791,6 +2142,8 @@ func (c *ColumnType) SQLString() string {
 		return fmt.Sprintf("%s COLLATE %s", ColumnType_STRING.String(), *c.Locale)
 	case ColumnType_ARRAY:
 		return c.ArrayContents.String() + "[]"
+	case ColumnType_JSON:
+		return "JSONB"
 	}
 	if c.VisibleType != ColumnType_NONE {
 		return c.VisibleType.String()
@@ -1886,12 +2239,16 @@ func DatumTypeToColumnSemanticType(ptyp parser.Type) (ColumnType_SemanticType, e
 		return ColumnType_INTERVAL, nil
 	case parser.TypeUUID:
 		return ColumnType_UUID, nil
+	case parser.TypeINet:
+		return ColumnType_INET, nil
 	case parser.TypeOid:
 		return ColumnType_OID, nil
 	case parser.TypeNull:
 		return ColumnType_NULL, nil
 	case parser.TypeIntVector:
 		return ColumnType_INT2VECTOR, nil
+	case parser.TypeJSON:
+		return ColumnType_JSON, nil
 	default:
 		if ptyp.FamilyEqual(parser.TypeCollatedString) {
 			return ColumnType_COLLATEDSTRING, nil
@@ -1952,6 +2309,8 @@ func columnSemanticTypeToDatumType(c *ColumnType, k ColumnType_SemanticType) par
 		return parser.TypeInterval
 	case ColumnType_UUID:
 		return parser.TypeUUID
+	case ColumnType_INET:
+		return parser.TypeINet
 	case ColumnType_COLLATEDSTRING:
 		if c.Locale == nil {
 			panic("locale is required for COLLATEDSTRING")
@@ -1965,6 +2324,8 @@ func columnSemanticTypeToDatumType(c *ColumnType, k ColumnType_SemanticType) par
 		return parser.TypeNull
 	case ColumnType_INT2VECTOR:
 		return parser.TypeIntVector
+	case ColumnType_JSON:
+		return parser.TypeJSON
 	}
 	return nil
 }
@@ -49,7 +49,8 @@ CREATE TABLE system.descriptor (
 	UsersTableSchema = `
 CREATE TABLE system.users (
   username         STRING PRIMARY KEY,
-  "hashedPassword" BYTES
+  "hashedPassword" BYTES,
+  "isRole"         BOOL NOT NULL DEFAULT false
 );`
 
 	// Zone settings per DB/Table.
@@ -140,6 +141,51 @@ CREATE TABLE system.web_sessions (
 	INDEX("createdAt"),
 	FAMILY(id, "hashedSecret", username, "createdAt", "expiresAt", "revokedAt", "lastUsedAt", "auditInfo")
 );`
+
+	// table_statistics is used to track statistics collected about individual
+	// columns or groups of columns from every table in the database. Each row
+	// contains the number of distinct values of the column (group) and the
+	// number of null values, along with an optional histogram of the values.
+	TableStatisticsTableSchema = `
+CREATE TABLE system.table_statistics (
+	"tableID"       INT        NOT NULL,
+	"statisticID"   INT        NOT NULL DEFAULT unique_rowid(),
+	name            STRING,
+	"columnIDs"     INT[]      NOT NULL,
+	"createdAt"     TIMESTAMP  NOT NULL DEFAULT now(),
+	"rowCount"      INT        NOT NULL,
+	"distinctCount" INT        NOT NULL,
+	"nullCount"     INT        NOT NULL,
+	histogram       BYTES,
+	PRIMARY KEY ("tableID", "statisticID"),
+	FAMILY ("tableID", "statisticID", name, "columnIDs", "createdAt", "rowCount", "distinctCount", "nullCount", histogram)
+);`
+
+	// role_members stores role memberships. Both roles and their members are
+	// entries of system.users; isAdmin records whether the member may grant
+	// and revoke membership in the role.
+	RoleMembersTableSchema = `
+CREATE TABLE system.role_members (
+	"role"    STRING NOT NULL,
+	"member"  STRING NOT NULL,
+	"isAdmin" BOOL   NOT NULL,
+	PRIMARY KEY ("role", "member"),
+	INDEX ("member"),
+	FAMILY ("role", "member", "isAdmin")
+);`
+
+	// functions stores the functions created with CREATE FUNCTION. Overloads
+	// of a function are told apart by argTypes, the comma-separated names of
+	// their argument types; definition holds the CREATE FUNCTION statement.
+	FunctionsTableSchema = `
+CREATE TABLE system.functions (
+	"parentID"   INT    NOT NULL,
+	name         STRING NOT NULL,
+	"argTypes"   STRING NOT NULL,
+	definition   STRING NOT NULL,
+	PRIMARY KEY ("parentID", name, "argTypes"),
+	FAMILY ("parentID", name, "argTypes", definition)
+);`
 )
 
 func pk(name string) IndexDescriptor {
@@ -191,8 +237,11 @@ var SystemAllowedPrivileges = map[ID]privilege.Lists{
 	// users will be able to modify system tables' schemas at will. CREATE and
 	// DROP privileges are allowed on the above system tables for backwards
 	// compatibility reasons only!
-	keys.JobsTableID:        {privilege.ReadWriteData},
-	keys.WebSessionsTableID: {privilege.ReadWriteData},
+	keys.JobsTableID:            {privilege.ReadWriteData},
+	keys.WebSessionsTableID:     {privilege.ReadWriteData},
+	keys.TableStatisticsTableID: {privilege.ReadWriteData},
+	keys.RoleMembersTableID:     {privilege.ReadWriteData},
+	keys.FunctionsTableID:       {privilege.ReadWriteData},
 }
 
 // SystemDesiredPrivileges returns the desired privilege list (i.e., the
@@ -206,12 +255,19 @@ func SystemDesiredPrivileges(id ID) privilege.List {
 
 // Helpers used to make some of the TableDescriptor literals below more concise.
 var (
+	colTypeBool      = ColumnType{SemanticType: ColumnType_BOOL}
 	colTypeInt       = ColumnType{SemanticType: ColumnType_INT}
 	colTypeString    = ColumnType{SemanticType: ColumnType_STRING}
 	colTypeBytes     = ColumnType{SemanticType: ColumnType_BYTES}
 	colTypeTimestamp = ColumnType{SemanticType: ColumnType_TIMESTAMP}
-	singleASC        = []IndexDescriptor_Direction{IndexDescriptor_ASC}
-	singleID1        = []ColumnID{1}
+	colTypeIntArray  = ColumnType{
+		SemanticType:    ColumnType_ARRAY,
+		ArrayDimensions: []int32{-1},
+		ArrayContents:   &colSemanticTypeInt,
+	}
+	colSemanticTypeInt = ColumnType_INT
+	singleASC          = []IndexDescriptor_Direction{IndexDescriptor_ASC}
+	singleID1          = []ColumnID{1}
 )
 
 // These system config TableDescriptor literals should match the descriptor
@@ -282,6 +338,8 @@ var (
 		NextMutationID: 1,
 	}
 
+	falseBoolString = "false"
+
 	// UsersTable is the descriptor for the users table.
 	UsersTable = TableDescriptor{
 		Name:     "users",
@@ -291,14 +349,16 @@ var (
 		Columns: []ColumnDescriptor{
 			{Name: "username", ID: 1, Type: colTypeString},
 			{Name: "hashedPassword", ID: 2, Type: colTypeBytes, Nullable: true},
+			{Name: "isRole", ID: 3, Type: colTypeBool, DefaultExpr: &falseBoolString},
 		},
-		NextColumnID: 3,
+		NextColumnID: 4,
 		Families: []ColumnFamilyDescriptor{
 			{Name: "primary", ID: 0, ColumnNames: []string{"username"}, ColumnIDs: singleID1},
 			{Name: "fam_2_hashedPassword", ID: 2, ColumnNames: []string{"hashedPassword"}, ColumnIDs: []ColumnID{2}, DefaultColumnID: 2},
+			{Name: "fam_3_isRole", ID: 3, ColumnNames: []string{"isRole"}, ColumnIDs: []ColumnID{3}, DefaultColumnID: 3},
 		},
 		PrimaryIndex:   pk("username"),
-		NextFamilyID:   3,
+		NextFamilyID:   4,
 		NextIndexID:    2,
 		Privileges:     NewPrivilegeDescriptor(security.RootUser, SystemDesiredPrivileges(keys.UsersTableID)),
 		FormatVersion:  InterleavedFormatVersion,
@@ -606,6 +666,139 @@ var (
 		NextMutationID: 1,
 		FormatVersion:  3,
 	}
+
+	// TableStatistics table to hold statistics about columns and column groups.
+	TableStatisticsTable = TableDescriptor{
+		Name:     "table_statistics",
+		ID:       keys.TableStatisticsTableID,
+		ParentID: 1,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "tableID", ID: 1, Type: colTypeInt},
+			{Name: "statisticID", ID: 2, Type: colTypeInt, DefaultExpr: &uniqueRowIDString},
+			{Name: "name", ID: 3, Type: colTypeString, Nullable: true},
+			{Name: "columnIDs", ID: 4, Type: colTypeIntArray},
+			{Name: "createdAt", ID: 5, Type: colTypeTimestamp, DefaultExpr: &nowString},
+			{Name: "rowCount", ID: 6, Type: colTypeInt},
+			{Name: "distinctCount", ID: 7, Type: colTypeInt},
+			{Name: "nullCount", ID: 8, Type: colTypeInt},
+			{Name: "histogram", ID: 9, Type: colTypeBytes, Nullable: true},
+		},
+		NextColumnID: 10,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name: "fam_0_tableID_statisticID_name_columnIDs_createdAt_rowCount_distinctCount_nullCount_histogram",
+				ID:   0,
+				ColumnNames: []string{
+					"tableID",
+					"statisticID",
+					"name",
+					"columnIDs",
+					"createdAt",
+					"rowCount",
+					"distinctCount",
+					"nullCount",
+					"histogram",
+				},
+				ColumnIDs: []ColumnID{1, 2, 3, 4, 5, 6, 7, 8, 9},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: IndexDescriptor{
+			Name:             "primary",
+			ID:               1,
+			Unique:           true,
+			ColumnNames:      []string{"tableID", "statisticID"},
+			ColumnDirections: []IndexDescriptor_Direction{IndexDescriptor_ASC, IndexDescriptor_ASC},
+			ColumnIDs:        []ColumnID{1, 2},
+		},
+		NextIndexID:    2,
+		Privileges:     NewPrivilegeDescriptor(security.RootUser, SystemDesiredPrivileges(keys.TableStatisticsTableID)),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
+
+	// RoleMembersTable is the descriptor for the role_members table.
+	RoleMembersTable = TableDescriptor{
+		Name:     "role_members",
+		ID:       keys.RoleMembersTableID,
+		ParentID: 1,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "role", ID: 1, Type: colTypeString},
+			{Name: "member", ID: 2, Type: colTypeString},
+			{Name: "isAdmin", ID: 3, Type: colTypeBool},
+		},
+		NextColumnID: 4,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name:        "fam_0_role_member_isAdmin",
+				ID:          0,
+				ColumnNames: []string{"role", "member", "isAdmin"},
+				ColumnIDs:   []ColumnID{1, 2, 3},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: IndexDescriptor{
+			Name:             "primary",
+			ID:               1,
+			Unique:           true,
+			ColumnNames:      []string{"role", "member"},
+			ColumnDirections: []IndexDescriptor_Direction{IndexDescriptor_ASC, IndexDescriptor_ASC},
+			ColumnIDs:        []ColumnID{1, 2},
+		},
+		Indexes: []IndexDescriptor{
+			{
+				Name:             "role_members_member_idx",
+				ID:               2,
+				Unique:           false,
+				ColumnNames:      []string{"member"},
+				ColumnDirections: []IndexDescriptor_Direction{IndexDescriptor_ASC},
+				ColumnIDs:        []ColumnID{2},
+				ExtraColumnIDs:   []ColumnID{1},
+			},
+		},
+		NextIndexID:    3,
+		Privileges:     NewPrivilegeDescriptor(security.RootUser, SystemDesiredPrivileges(keys.RoleMembersTableID)),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
+
+	// FunctionsTable is the descriptor for the functions table.
+	FunctionsTable = TableDescriptor{
+		Name:     "functions",
+		ID:       keys.FunctionsTableID,
+		ParentID: 1,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "parentID", ID: 1, Type: colTypeInt},
+			{Name: "name", ID: 2, Type: colTypeString},
+			{Name: "argTypes", ID: 3, Type: colTypeString},
+			{Name: "definition", ID: 4, Type: colTypeString},
+		},
+		NextColumnID: 5,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name:        "fam_0_parentID_name_argTypes_definition",
+				ID:          0,
+				ColumnNames: []string{"parentID", "name", "argTypes", "definition"},
+				ColumnIDs:   []ColumnID{1, 2, 3, 4},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: IndexDescriptor{
+			Name:             "primary",
+			ID:               1,
+			Unique:           true,
+			ColumnNames:      []string{"parentID", "name", "argTypes"},
+			ColumnDirections: []IndexDescriptor_Direction{IndexDescriptor_ASC, IndexDescriptor_ASC, IndexDescriptor_ASC},
+			ColumnIDs:        []ColumnID{1, 2, 3},
+		},
+		NextIndexID:    2,
+		Privileges:     NewPrivilegeDescriptor(security.RootUser, SystemDesiredPrivileges(keys.FunctionsTableID)),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
 )
 
 // Create the key/value pair for the default zone config entry.
@@ -30,6 +30,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
 
@@ -141,6 +143,7 @@ func MakeColumnDefDescs(
 	case *parser.TimestampTZColType:
 	case *parser.IntervalColType:
 	case *parser.UUIDColType:
+	case *parser.IPAddrColType:
 	case *parser.StringColType:
 		col.Type.Width = int32(t.N)
 	case *parser.NameColType:
@@ -166,6 +169,7 @@ func MakeColumnDefDescs(
 			return nil, nil, errors.Errorf("vectors of type %s are unsupported", t.ParamType)
 		}
 	case *parser.OidColType:
+	case *parser.JSONColType:
 	default:
 		return nil, nil, errors.Errorf("unexpected type %T", t)
 	}
@@ -544,6 +548,11 @@ func EncodeTableKey(b []byte, val parser.Datum, dir encoding.Direction) ([]byte,
 			return encoding.EncodeBytesAscending(b, t.GetBytes()), nil
 		}
 		return encoding.EncodeBytesDescending(b, t.GetBytes()), nil
+	case *parser.DIPAddr:
+		if dir == encoding.Ascending {
+			return encoding.EncodeIPAddrAscending(b, t.IPAddr), nil
+		}
+		return encoding.EncodeIPAddrDescending(b, t.IPAddr), nil
 	case *parser.DTuple:
 		for _, datum := range t.D {
 			var err error
@@ -610,6 +619,8 @@ func EncodeTableValue(
 		return encoding.EncodeDurationValue(appendTo, uint32(colID), t.Duration), nil
 	case *parser.DUuid:
 		return encoding.EncodeUUIDValue(appendTo, uint32(colID), t.UUID), nil
+	case *parser.DIPAddr:
+		return encoding.EncodeIPAddrValue(appendTo, uint32(colID), t.IPAddr), nil
 	case *parser.DArray:
 		a, err := encodeArray(t, scratch)
 		if err != nil {
@@ -620,6 +631,8 @@ func EncodeTableValue(
 		return encoding.EncodeBytesValue(appendTo, uint32(colID), []byte(t.Contents)), nil
 	case *parser.DOid:
 		return encoding.EncodeIntValue(appendTo, uint32(colID), int64(t.DInt)), nil
+	case *parser.DJSON:
+		return encoding.EncodeJSONValue(appendTo, uint32(colID), json.EncodeJSON(scratch[:0], t.JSON)), nil
 	}
 	return nil, errors.Errorf("unable to encode table value: %T", val)
 }
@@ -917,6 +930,7 @@ type DatumAlloc struct {
 	dtimestampTzAlloc []parser.DTimestampTZ
 	dintervalAlloc    []parser.DInterval
 	duuidAlloc        []parser.DUuid
+	dipnetAlloc       []parser.DIPAddr
 	doidAlloc         []parser.DOid
 	scratch           []byte
 	env               parser.CollationEnvironment
@@ -1047,6 +1061,18 @@ func (a *DatumAlloc) NewDUuid(v parser.DUuid) *parser.DUuid {
 	return r
 }
 
+// NewDIPAddr allocates a DIPAddr.
+func (a *DatumAlloc) NewDIPAddr(v parser.DIPAddr) *parser.DIPAddr {
+	buf := &a.dipnetAlloc
+	if len(*buf) == 0 {
+		*buf = make([]parser.DIPAddr, datumAllocSize)
+	}
+	r := &(*buf)[0]
+	*r = v
+	*buf = (*buf)[1:]
+	return r
+}
+
 // NewDOid allocates a DOid.
 func (a *DatumAlloc) NewDOid(v parser.DOid) parser.Datum {
 	buf := &a.doidAlloc
@@ -1176,6 +1202,14 @@ func DecodeTableKey(
 		}
 		u, err := uuid.FromBytes(r)
 		return a.NewDUuid(parser.DUuid{UUID: u}), rkey, err
+	case parser.TypeINet:
+		var ipAddr ipaddr.IPAddr
+		if dir == encoding.Ascending {
+			rkey, ipAddr, err = encoding.DecodeIPAddrAscending(key)
+		} else {
+			rkey, ipAddr, err = encoding.DecodeIPAddrDescending(key)
+		}
+		return a.NewDIPAddr(parser.DIPAddr{IPAddr: ipAddr}), rkey, err
 	case parser.TypeOid:
 		var i int64
 		if dir == encoding.Ascending {
@@ -1360,9 +1394,22 @@ func decodeUntaggedDatum(a *DatumAlloc, t parser.Type, buf []byte) (parser.Datum
 	case parser.TypeUUID:
 		b, data, err := encoding.DecodeUntaggedUUIDValue(buf)
 		return a.NewDUuid(parser.DUuid{UUID: data}), b, err
+	case parser.TypeINet:
+		b, data, err := encoding.DecodeUntaggedIPAddrValue(buf)
+		return a.NewDIPAddr(parser.DIPAddr{IPAddr: data}), b, err
 	case parser.TypeOid:
 		b, data, err := encoding.DecodeUntaggedIntValue(buf)
 		return a.NewDOid(parser.MakeDOid(parser.DInt(data))), b, err
+	case parser.TypeJSON:
+		b, data, err := encoding.DecodeUntaggedBytesValue(buf)
+		if err != nil {
+			return nil, b, err
+		}
+		_, j, err := json.DecodeJSON(data)
+		if err != nil {
+			return nil, b, err
+		}
+		return parser.NewDJSON(j), b, nil
 	default:
 		switch typ := t.(type) {
 		case parser.TCollatedString:
@@ -1397,18 +1444,20 @@ func (a byID) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a byID) Less(i, j int) bool { return a[i].id < a[j].id }
 
 // EncodeSecondaryIndex encodes key/values for a secondary index. colMap maps
-// ColumnIDs to indices in `values`.
+// ColumnIDs to indices in `values`. A forward index has exactly one entry per
+// row, while an inverted index has one entry per path of the indexed document
+// and an index with PrimaryIndexEncoding one entry per column family.
 func EncodeSecondaryIndex(
 	tableDesc *TableDescriptor,
 	secondaryIndex *IndexDescriptor,
 	colMap map[ColumnID]int,
 	values []parser.Datum,
-) (IndexEntry, error) {
+) ([]IndexEntry, error) {
 	secondaryIndexKeyPrefix := MakeIndexKeyPrefix(tableDesc, secondaryIndex.ID)
-	secondaryIndexKey, containsNull, err := EncodeIndexKey(
-		tableDesc, secondaryIndex, colMap, values, secondaryIndexKeyPrefix)
-	if err != nil {
-		return IndexEntry{}, err
+
+	if secondaryIndex.EncodingType == PrimaryIndexEncoding {
+		return encodePrimaryIndexEntries(
+			tableDesc, secondaryIndex, colMap, values, secondaryIndexKeyPrefix)
 	}
 
 	// Add the extra columns - they are encoded ascendingly which is done by
@@ -1416,7 +1465,18 @@ func EncodeSecondaryIndex(
 	extraKey, _, err := EncodeColumns(secondaryIndex.ExtraColumnIDs, nil,
 		colMap, values, nil)
 	if err != nil {
-		return IndexEntry{}, err
+		return nil, err
+	}
+
+	if secondaryIndex.Type == IndexDescriptor_INVERTED {
+		return encodeInvertedIndexEntries(
+			secondaryIndex, colMap, values, secondaryIndexKeyPrefix, extraKey)
+	}
+
+	secondaryIndexKey, containsNull, err := EncodeIndexKey(
+		tableDesc, secondaryIndex, colMap, values, secondaryIndexKeyPrefix)
+	if err != nil {
+		return nil, err
 	}
 
 	entry := IndexEntry{Key: secondaryIndexKey}
@@ -1468,33 +1528,177 @@ func EncodeSecondaryIndex(
 		lastColID = col.id
 		entryValue, err = EncodeTableValue(entryValue, colIDDiff, val, nil)
 		if err != nil {
-			return IndexEntry{}, err
+			return nil, err
 		}
 	}
 	entry.Value.SetBytes(entryValue)
 
-	return entry, nil
+	return []IndexEntry{entry}, nil
+}
+
+// encodeInvertedIndexEntries encodes the key/values of an inverted index, one
+// for each path of the JSON document in its only column. A NULL value has a
+// single entry, keyed by the encoded NULL.
+func encodeInvertedIndexEntries(
+	index *IndexDescriptor,
+	colMap map[ColumnID]int,
+	values []parser.Datum,
+	keyPrefix []byte,
+	extraKey []byte,
+) ([]IndexEntry, error) {
+	val := parser.Datum(parser.DNull)
+	if i, ok := colMap[index.ColumnIDs[0]]; ok {
+		val = values[i]
+	}
+
+	var paths [][]byte
+	switch t := parser.UnwrapDatum(val).(type) {
+	case *parser.DJSON:
+		paths = json.EncodeInvertedIndexKeys(keyPrefix, t.JSON)
+	default:
+		if val != parser.DNull {
+			return nil, errors.Errorf("unable to encode inverted index key: %T", val)
+		}
+		paths = [][]byte{encoding.EncodeNullAscending(keyPrefix)}
+	}
+
+	entries := make([]IndexEntry, len(paths))
+	for i, path := range paths {
+		// Inverted indexes are never unique, so the extra columns are always
+		// needed to make the key unique. Index keys are considered "sentinel"
+		// keys in that they do not have a column ID suffix.
+		entries[i].Key = keys.MakeFamilyKey(append(path, extraKey...), 0)
+		// The zero value for an index-key is a 0-length bytes value.
+		entries[i].Value.SetBytes([]byte{})
+	}
+	return entries, nil
+}
+
+// encodePrimaryIndexEntries encodes the key/values of a row in an index with
+// PrimaryIndexEncoding, the same way InsertRow encodes the row in the primary
+// index: one entry per column family, holding the values of the columns of
+// the family that aren't encoded in the key. The entry of family 0 is always
+// present and acts as a sentinel, while the entries of the other families are
+// omitted when all their columns are NULL.
+func encodePrimaryIndexEntries(
+	tableDesc *TableDescriptor,
+	index *IndexDescriptor,
+	colMap map[ColumnID]int,
+	values []parser.Datum,
+	keyPrefix []byte,
+) ([]IndexEntry, error) {
+	indexKey, _, err := EncodeIndexKey(tableDesc, index, colMap, values, keyPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]IndexEntry, 0, len(tableDesc.Families))
+	for _, family := range tableDesc.Families {
+		// MakeFamilyKey appends to its argument, so trim indexKey so nothing
+		// gets overwritten by the key of the next family.
+		familyKey := keys.MakeFamilyKey(indexKey[:len(indexKey):len(indexKey)], uint32(family.ID))
+
+		if len(family.ColumnIDs) == 1 && family.ColumnIDs[0] == family.DefaultColumnID {
+			// Storage optimization to store DefaultColumnID directly as a value,
+			// as in the primary index.
+			idx, ok := colMap[family.DefaultColumnID]
+			if !ok || values[idx] == parser.DNull {
+				continue
+			}
+			col, err := tableDesc.FindColumnByID(family.DefaultColumnID)
+			if err != nil {
+				return nil, err
+			}
+			value, err := MarshalColumnValue(*col, values[idx])
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, IndexEntry{Key: familyKey, Value: value})
+			continue
+		}
+
+		familyColumnIDs := append([]ColumnID(nil), family.ColumnIDs...)
+		sort.Sort(columnIDs(familyColumnIDs))
+
+		var valueBuf []byte
+		var lastColID ColumnID
+		for _, colID := range familyColumnIDs {
+			idx, ok := colMap[colID]
+			if !ok || values[idx] == parser.DNull {
+				continue
+			}
+			if skip, err := skipColumnInIndexKey(index, colID, family.ID, values[idx]); err != nil {
+				return nil, err
+			} else if skip {
+				continue
+			}
+			if lastColID > colID {
+				panic(fmt.Errorf("cannot write column id %d after %d", colID, lastColID))
+			}
+			colIDDiff := colID - lastColID
+			lastColID = colID
+			valueBuf, err = EncodeTableValue(valueBuf, colIDDiff, values[idx], nil)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if family.ID == 0 || len(valueBuf) > 0 {
+			entry := IndexEntry{Key: familyKey}
+			entry.Value.SetTuple(valueBuf)
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// skipColumnInIndexKey returns whether the value of a column can be omitted
+// from the entry of its family in an index with PrimaryIndexEncoding, because
+// it is already encoded in the key. See rowHelper.skipColumnInPK.
+func skipColumnInIndexKey(
+	index *IndexDescriptor, colID ColumnID, family FamilyID, value parser.Datum,
+) (bool, error) {
+	found := false
+	for _, id := range index.ColumnIDs {
+		if id == colID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return false, nil
+	}
+	if family != 0 {
+		return false, errors.Errorf("index column %d must be in family 0, was %d", colID, family)
+	}
+	if cdatum, ok := value.(parser.CompositeDatum); ok {
+		// Composite columns are encoded in both the key and the value.
+		return !cdatum.IsComposite(), nil
+	}
+	return true, nil
 }
 
 // EncodeSecondaryIndexes encodes key/values for the secondary indexes. colMap
-// maps ColumnIDs to indices in `values`. secondaryIndexEntries is the return
-// value (passed as a parameter so the caller can reuse between rows) and is
-// expected to be the same length as indexes.
+// maps ColumnIDs to indices in `values`. The entries of all the indexes are
+// appended to secondaryIndexEntries (passed as a parameter so the caller can
+// reuse it between rows), in the order of indexes, and the resulting slice is
+// returned.
 func EncodeSecondaryIndexes(
 	tableDesc *TableDescriptor,
 	indexes []IndexDescriptor,
 	colMap map[ColumnID]int,
 	values []parser.Datum,
 	secondaryIndexEntries []IndexEntry,
-) error {
+) ([]IndexEntry, error) {
+	secondaryIndexEntries = secondaryIndexEntries[:0]
 	for i := range indexes {
-		var err error
-		secondaryIndexEntries[i], err = EncodeSecondaryIndex(tableDesc, &indexes[i], colMap, values)
+		entries, err := EncodeSecondaryIndex(tableDesc, &indexes[i], colMap, values)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		secondaryIndexEntries = append(secondaryIndexEntries, entries...)
 	}
-	return nil
+	return secondaryIndexEntries, nil
 }
 
 // CheckColumnType verifies that a given value is compatible
@@ -1605,6 +1809,11 @@ func MarshalColumnValue(col ColumnDescriptor, val parser.Datum) (roachpb.Value,
 			r.SetBytes(v.GetBytes())
 			return r, nil
 		}
+	case ColumnType_INET:
+		if v, ok := val.(*parser.DIPAddr); ok {
+			r.SetBytes(v.ToBuffer(nil))
+			return r, nil
+		}
 	case ColumnType_ARRAY:
 		if v, ok := val.(*parser.DArray); ok {
 			if err := checkElementType(v.ParamTyp, col.Type); err != nil {
@@ -1634,6 +1843,11 @@ func MarshalColumnValue(col ColumnDescriptor, val parser.Datum) (roachpb.Value,
 			r.SetInt(int64(v.DInt))
 			return r, nil
 		}
+	case ColumnType_JSON:
+		if v, ok := val.(*parser.DJSON); ok {
+			r.SetBytes(json.EncodeJSON(nil, v.JSON))
+			return r, nil
+		}
 	default:
 		return r, errors.Errorf("unsupported column type: %s", col.Type.SemanticType)
 	}
@@ -1730,6 +1944,8 @@ func parserTypeToEncodingType(t parser.Type) (encoding.Type, error) {
 		return encoding.True, nil
 	case parser.TypeUUID:
 		return encoding.UUID, nil
+	case parser.TypeINet:
+		return encoding.IPAddr, nil
 	default:
 		if t.FamilyEqual(parser.TypeCollatedString) {
 			return encoding.Bytes, nil
@@ -1766,6 +1982,8 @@ func encodeArrayElement(b []byte, d parser.Datum) ([]byte, error) {
 		return encoding.EncodeUntaggedDurationValue(b, t.Duration), nil
 	case *parser.DUuid:
 		return encoding.EncodeUntaggedUUIDValue(b, t.UUID), nil
+	case *parser.DIPAddr:
+		return encoding.EncodeUntaggedIPAddrValue(b, t.IPAddr), nil
 	case *parser.DOid:
 		return encoding.EncodeUntaggedIntValue(b, int64(t.DInt)), nil
 	case *parser.DCollatedString:
@@ -1862,6 +2080,16 @@ func UnmarshalColumnValue(
 			return nil, err
 		}
 		return a.NewDUuid(parser.DUuid{UUID: u}), nil
+	case ColumnType_INET:
+		v, err := value.GetBytes()
+		if err != nil {
+			return nil, err
+		}
+		ipAddr, _, err := ipaddr.FromBuffer(v)
+		if err != nil {
+			return nil, err
+		}
+		return a.NewDIPAddr(parser.DIPAddr{IPAddr: ipAddr}), nil
 	case ColumnType_NAME:
 		v, err := value.GetBytes()
 		if err != nil {
@@ -1874,6 +2102,16 @@ func UnmarshalColumnValue(
 			return nil, err
 		}
 		return a.NewDOid(parser.MakeDOid(parser.DInt(v))), nil
+	case ColumnType_JSON:
+		v, err := value.GetBytes()
+		if err != nil {
+			return nil, err
+		}
+		_, j, err := json.DecodeJSON(v)
+		if err != nil {
+			return nil, err
+		}
+		return parser.NewDJSON(j), nil
 	default:
 		return nil, errors.Errorf("unsupported column type: %s", typ.SemanticType)
 	}
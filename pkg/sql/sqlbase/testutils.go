@@ -27,6 +27,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
@@ -95,6 +96,8 @@ func RandDatum(rng *rand.Rand, typ ColumnType, null bool) parser.Datum {
 		}}
 	case ColumnType_UUID:
 		return parser.NewDUuid(parser.DUuid{UUID: uuid.MakeV4()})
+	case ColumnType_INET:
+		return parser.NewDIPAddr(parser.DIPAddr{IPAddr: ipaddr.RandIPAddr(rng)})
 	case ColumnType_STRING:
 		// Generate a random ASCII string.
 		p := make([]byte, rng.Intn(10))
@@ -142,6 +145,10 @@ func RandDatum(rng *rand.Rand, typ ColumnType, null bool) parser.Datum {
 		return parser.DNull
 	case ColumnType_INT2VECTOR:
 		return parser.DNull
+	case ColumnType_JSON:
+		// JSON values don't have a key encoding, which most users of random
+		// datums rely on.
+		return parser.DNull
 	default:
 		panic(fmt.Sprintf("invalid type %s", typ.String()))
 	}
@@ -0,0 +1,182 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// AutomaticStatisticsClusterMode controls the cluster setting for enabling
+// automatic table statistics collection.
+var AutomaticStatisticsClusterMode = settings.RegisterBoolSetting(
+	"sql.stats.automatic_collection.enabled",
+	"automatic statistics collection mode",
+	true,
+)
+
+// AutomaticStatisticsFractionStaleRows controls the cluster setting for
+// the target fraction of rows in a table that should be stale before
+// statistics on that table are refreshed.
+var AutomaticStatisticsFractionStaleRows = settings.RegisterNonNegativeFloatSetting(
+	"sql.stats.automatic_collection.fraction_stale_rows",
+	"target fraction of stale rows per table that will trigger a statistics refresh",
+	0.2,
+)
+
+// AutomaticStatisticsMinStaleRows controls the cluster setting for the target
+// number of rows that should be updated before a table is refreshed, in
+// addition to the fraction AutomaticStatisticsFractionStaleRows.
+var AutomaticStatisticsMinStaleRows = settings.RegisterValidatedIntSetting(
+	"sql.stats.automatic_collection.min_stale_rows",
+	"target minimum number of stale rows per table that will trigger a statistics refresh",
+	500,
+	func(v int64) error {
+		if v < 0 {
+			return errors.Errorf("cannot set sql.stats.automatic_collection.min_stale_rows to a negative value: %d", v)
+		}
+		return nil
+	},
+)
+
+// DefaultRefreshInterval is the frequency at which the Refresher checks
+// whether the statistics of any table need to be refreshed.
+const DefaultRefreshInterval = time.Minute
+
+// RefreshFn re-collects all the statistics of a table.
+type RefreshFn func(ctx context.Context, tableID sqlbase.ID) error
+
+// Refresher is responsible for automatically refreshing the table statistics
+// that are stored in the cache. It keeps track of the number of rows modified
+// in each table since the last refresh, and refreshes the statistics of a
+// table once that number exceeds a fraction of the table's row count (as
+// estimated by its most recent statistics).
+//
+// Only tables that already have statistics are refreshed, and only the
+// statistics that already exist are re-collected.
+type Refresher struct {
+	st    *cluster.Settings
+	cache *TableStatisticsCache
+
+	mu struct {
+		syncutil.Mutex
+		// mutationCounts contains the number of rows modified in each table
+		// since its statistics were last refreshed.
+		mutationCounts map[sqlbase.ID]int64
+	}
+}
+
+// MakeRefresher creates a new Refresher.
+func MakeRefresher(st *cluster.Settings, cache *TableStatisticsCache) *Refresher {
+	r := &Refresher{st: st, cache: cache}
+	r.mu.mutationCounts = make(map[sqlbase.ID]int64)
+	return r
+}
+
+// Start starts the loop that periodically refreshes the statistics of the
+// tables that have been modified, using refreshFn.
+func (r *Refresher) Start(
+	ctx context.Context, stopper *stop.Stopper, refreshFn RefreshFn, refreshInterval time.Duration,
+) {
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		ticker := time.NewTicker(refreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if !AutomaticStatisticsClusterMode.Get(&r.st.SV) {
+					continue
+				}
+				r.maybeRefreshStats(ctx, stopper, refreshFn)
+			case <-stopper.ShouldStop():
+				return
+			}
+		}
+	})
+}
+
+// NotifyMutation is called by SQL mutation operations to signal to the
+// Refresher that a table has been mutated.
+func (r *Refresher) NotifyMutation(tableID sqlbase.ID, rowsAffected int) {
+	if r == nil || rowsAffected <= 0 || !AutomaticStatisticsClusterMode.Get(&r.st.SV) {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mu.mutationCounts[tableID] += int64(rowsAffected)
+}
+
+// maybeRefreshStats refreshes the statistics of the tables which have had
+// enough rows modified since their last refresh.
+func (r *Refresher) maybeRefreshStats(
+	ctx context.Context, stopper *stop.Stopper, refreshFn RefreshFn,
+) {
+	r.mu.Lock()
+	mutationCounts := r.mu.mutationCounts
+	r.mu.mutationCounts = make(map[sqlbase.ID]int64, len(mutationCounts))
+	r.mu.Unlock()
+
+	for tableID, count := range mutationCounts {
+		select {
+		case <-stopper.ShouldQuiesce():
+			return
+		default:
+		}
+
+		stats, err := r.cache.GetTableStats(ctx, tableID)
+		if err != nil {
+			log.Warningf(ctx, "failed to get statistics for table %d: %v", tableID, err)
+			continue
+		}
+		if len(stats) == 0 {
+			// Statistics are only refreshed once they have been created
+			// explicitly.
+			continue
+		}
+		if !r.shouldRefresh(stats[0].RowCount, count) {
+			// Not enough rows were modified yet; keep counting.
+			r.mu.Lock()
+			r.mu.mutationCounts[tableID] += count
+			r.mu.Unlock()
+			continue
+		}
+		if log.V(1) {
+			log.Infof(ctx, "refreshing statistics for table %d after %d modified rows", tableID, count)
+		}
+		if err := refreshFn(ctx, tableID); err != nil {
+			log.Warningf(ctx, "failed to refresh statistics for table %d: %v", tableID, err)
+		}
+	}
+}
+
+// shouldRefresh returns whether the statistics of a table with the given
+// estimated row count need to be refreshed after the given number of rows
+// was modified.
+func (r *Refresher) shouldRefresh(rowCount, mutationCount int64) bool {
+	targetRows := int64(float64(rowCount) * AutomaticStatisticsFractionStaleRows.Get(&r.st.SV))
+	if minRows := AutomaticStatisticsMinStaleRows.Get(&r.st.SV); targetRows < minRows {
+		targetRows = minRows
+	}
+	return mutationCount >= targetRows
+}
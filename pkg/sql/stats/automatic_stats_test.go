@@ -0,0 +1,81 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestRefresherShouldRefresh(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	st := cluster.MakeTestingClusterSettings()
+	r := MakeRefresher(st, nil /* cache */)
+
+	testCases := []struct {
+		rowCount, mutationCount int64
+		expected                bool
+	}{
+		// The minimum number of stale rows applies to small tables.
+		{0, 499, false},
+		{0, 500, true},
+		{1000, 499, false},
+		{1000, 500, true},
+		// The fraction of stale rows applies to large tables.
+		{10000, 1999, false},
+		{10000, 2000, true},
+	}
+	for _, tc := range testCases {
+		if res := r.shouldRefresh(tc.rowCount, tc.mutationCount); res != tc.expected {
+			t.Errorf("rowCount=%d mutationCount=%d: expected %t, got %t",
+				tc.rowCount, tc.mutationCount, tc.expected, res)
+		}
+	}
+
+	AutomaticStatisticsMinStaleRows.Override(&st.SV, 0)
+	if !r.shouldRefresh(0, 1) {
+		t.Errorf("expected a refresh of an empty table")
+	}
+}
+
+func TestRefresherNotifyMutation(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	st := cluster.MakeTestingClusterSettings()
+	r := MakeRefresher(st, nil /* cache */)
+
+	const tableID = sqlbase.ID(51)
+	r.NotifyMutation(tableID, 10)
+	r.NotifyMutation(tableID, 5)
+	r.NotifyMutation(tableID, 0)
+	if c := r.mu.mutationCounts[tableID]; c != 15 {
+		t.Errorf("expected 15 mutations, got %d", c)
+	}
+
+	// Mutations are not tracked when automatic collection is disabled.
+	AutomaticStatisticsClusterMode.Override(&st.SV, false)
+	r.NotifyMutation(tableID, 10)
+	if c := r.mu.mutationCounts[tableID]; c != 15 {
+		t.Errorf("expected 15 mutations, got %d", c)
+	}
+
+	// A nil Refresher ignores mutations.
+	var nilRefresher *Refresher
+	nilRefresher.NotifyMutation(tableID, 10)
+}
@@ -0,0 +1,137 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+)
+
+// EquiDepthHistogram creates a histogram where each bucket contains roughly
+// the same number of samples (though the count can vary when a boundary value
+// has a high frequency).
+//
+// numRows is the total number of rows from which the values were sampled; the
+// counts in the buckets are scaled accordingly. The samples must not contain
+// NULLs and are sorted in place.
+func EquiDepthHistogram(
+	evalCtx *parser.EvalContext, samples parser.Datums, numRows int64, maxBuckets int,
+) (HistogramData, error) {
+	numSamples := len(samples)
+	if maxBuckets < 1 {
+		return HistogramData{}, errors.Errorf("invalid maxBuckets %d", maxBuckets)
+	}
+	if numSamples == 0 {
+		return HistogramData{}, nil
+	}
+	if int64(numSamples) > numRows {
+		numRows = int64(numSamples)
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].Compare(evalCtx, samples[j]) < 0
+	})
+
+	var h HistogramData
+	for i := 0; i < numSamples; {
+		num := (numSamples - i) / (maxBuckets - len(h.Buckets))
+		if num < 1 {
+			num = 1
+		}
+		upper := samples[i+num-1]
+		// numLess is the number of samples in the bucket that are less than the
+		// upper bound.
+		numLess := 0
+		for ; numLess < num-1; numLess++ {
+			if samples[i+numLess].Compare(evalCtx, upper) == 0 {
+				break
+			}
+		}
+		// Extend the bucket to include all the samples equal to the upper bound.
+		for ; i+num < numSamples; num++ {
+			if samples[i+num].Compare(evalCtx, upper) != 0 {
+				break
+			}
+		}
+
+		encoded, err := sqlbase.EncodeTableKey(nil, upper, encoding.Ascending)
+		if err != nil {
+			return HistogramData{}, err
+		}
+		h.Buckets = append(h.Buckets, HistogramData_Bucket{
+			NumEq:      int64(num-numLess) * numRows / int64(numSamples),
+			NumRange:   int64(numLess) * numRows / int64(numSamples),
+			UpperBound: encoded,
+		})
+		i += num
+	}
+	return h, nil
+}
+
+// RangeFraction estimates the fraction of the values counted in the histogram
+// which lie between lower and upper, inclusively. A nil bound means that the
+// range is unbounded on that side. Values which fall strictly between two
+// bucket boundaries are assumed to be spread evenly, so a range which only
+// partially overlaps a bucket is credited with half of the bucket's range
+// count.
+func (h *HistogramData) RangeFraction(
+	evalCtx *parser.EvalContext, colType parser.Type, lower, upper parser.Datum,
+) (float64, error) {
+	if lower == parser.DNull || upper == parser.DNull {
+		return 0, nil
+	}
+	for _, d := range []parser.Datum{lower, upper} {
+		if d != nil && !d.ResolvedType().Equivalent(colType) {
+			return 0, errors.Errorf("cannot compare %s bound to histogram of type %s",
+				d.ResolvedType(), colType)
+		}
+	}
+
+	var a sqlbase.DatumAlloc
+	var total, count float64
+	var prev parser.Datum
+	for _, b := range h.Buckets {
+		bound, _, err := sqlbase.DecodeTableKey(&a, colType, b.UpperBound, encoding.Ascending)
+		if err != nil {
+			return 0, err
+		}
+		total += float64(b.NumEq + b.NumRange)
+
+		afterLower := lower == nil || bound.Compare(evalCtx, lower) >= 0
+		beforeUpper := upper == nil || bound.Compare(evalCtx, upper) <= 0
+		if afterLower && beforeUpper {
+			count += float64(b.NumEq)
+		}
+
+		// The range part of the bucket covers the values in (prev, bound).
+		rangeAfterLower := lower == nil || (prev != nil && prev.Compare(evalCtx, lower) >= 0)
+		rangeOverlaps := (lower == nil || bound.Compare(evalCtx, lower) > 0) &&
+			(upper == nil || prev == nil || prev.Compare(evalCtx, upper) < 0)
+		if rangeAfterLower && beforeUpper {
+			count += float64(b.NumRange)
+		} else if rangeOverlaps {
+			count += float64(b.NumRange) / 2
+		}
+		prev = bound
+	}
+	if total == 0 {
+		return 0, nil
+	}
+	return count / total, nil
+}
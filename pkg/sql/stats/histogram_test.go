@@ -0,0 +1,182 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestEquiDepthHistogram(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	type expBucket struct {
+		upper    int
+		numEq    int64
+		numRange int64
+	}
+
+	testCases := []struct {
+		samples    []int
+		numRows    int64
+		maxBuckets int
+		buckets    []expBucket
+	}{
+		{
+			samples:    []int{1, 2, 4, 5, 5, 9},
+			numRows:    6,
+			maxBuckets: 2,
+			buckets: []expBucket{
+				{upper: 4, numEq: 1, numRange: 2},
+				{upper: 9, numEq: 1, numRange: 2},
+			},
+		},
+		{
+			// Same as above, but the samples are unsorted and the counts are
+			// scaled up.
+			samples:    []int{9, 1, 5, 2, 5, 4},
+			numRows:    600,
+			maxBuckets: 2,
+			buckets: []expBucket{
+				{upper: 4, numEq: 100, numRange: 200},
+				{upper: 9, numEq: 100, numRange: 200},
+			},
+		},
+		{
+			// A boundary value with a high frequency extends its bucket.
+			samples:    []int{1, 1, 2, 2, 2, 2, 3, 4},
+			numRows:    8,
+			maxBuckets: 4,
+			buckets: []expBucket{
+				{upper: 1, numEq: 2, numRange: 0},
+				{upper: 2, numEq: 4, numRange: 0},
+				{upper: 3, numEq: 1, numRange: 0},
+				{upper: 4, numEq: 1, numRange: 0},
+			},
+		},
+		{
+			// More buckets than distinct values.
+			samples:    []int{7, 7, 7},
+			numRows:    30,
+			maxBuckets: 10,
+			buckets: []expBucket{
+				{upper: 7, numEq: 30, numRange: 0},
+			},
+		},
+		{
+			samples:    []int{},
+			numRows:    0,
+			maxBuckets: 10,
+			buckets:    nil,
+		},
+	}
+
+	evalCtx := parser.NewTestingEvalContext()
+	defer evalCtx.Stop(context.Background())
+
+	for i, tc := range testCases {
+		samples := make(parser.Datums, len(tc.samples))
+		for j := range tc.samples {
+			samples[j] = parser.NewDInt(parser.DInt(tc.samples[j]))
+		}
+		h, err := EquiDepthHistogram(evalCtx, samples, tc.numRows, tc.maxBuckets)
+		if err != nil {
+			t.Fatalf("%d: %s", i, err)
+		}
+		if len(h.Buckets) != len(tc.buckets) {
+			t.Fatalf("%d: expected %d buckets, got %d: %v", i, len(tc.buckets), len(h.Buckets), h)
+		}
+		var a sqlbase.DatumAlloc
+		for j, b := range h.Buckets {
+			upper, _, err := sqlbase.DecodeTableKey(&a, parser.TypeInt, b.UpperBound, encoding.Ascending)
+			if err != nil {
+				t.Fatal(err)
+			}
+			exp := tc.buckets[j]
+			if int(*upper.(*parser.DInt)) != exp.upper || b.NumEq != exp.numEq || b.NumRange != exp.numRange {
+				t.Errorf("%d: bucket %d: expected upper=%d numEq=%d numRange=%d, got upper=%s numEq=%d numRange=%d",
+					i, j, exp.upper, exp.numEq, exp.numRange, upper, b.NumEq, b.NumRange)
+			}
+		}
+	}
+
+	t.Run("invalid-buckets", func(t *testing.T) {
+		if _, err := EquiDepthHistogram(
+			evalCtx, parser.Datums{parser.NewDInt(1)}, 1, 0,
+		); !testutils.IsError(err, "invalid maxBuckets") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestHistogramRangeFraction(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	evalCtx := parser.NewTestingEvalContext()
+	defer evalCtx.Stop(context.Background())
+
+	// Five buckets with upper bounds 2, 4, 6, 8, 10, each counting one row equal
+	// to the upper bound and one row in the range below it.
+	samples := make(parser.Datums, 10)
+	for i := range samples {
+		samples[i] = parser.NewDInt(parser.DInt(i + 1))
+	}
+	h, err := EquiDepthHistogram(evalCtx, samples, 10, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	datum := func(v int) parser.Datum {
+		if v < 0 {
+			return nil
+		}
+		return parser.NewDInt(parser.DInt(v))
+	}
+	testCases := []struct {
+		lower, upper int // -1 means unbounded
+		expected     float64
+	}{
+		{-1, -1, 1},
+		{-1, 4, 0.4},
+		{3, 6, 0.35},
+		{6, 6, 0.1},
+		{11, -1, 0},
+	}
+	for _, tc := range testCases {
+		res, err := h.RangeFraction(evalCtx, parser.TypeInt, datum(tc.lower), datum(tc.upper))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res != tc.expected {
+			t.Errorf("[%d, %d]: expected %f, got %f", tc.lower, tc.upper, tc.expected, res)
+		}
+	}
+
+	if res, err := h.RangeFraction(evalCtx, parser.TypeInt, parser.DNull, nil); err != nil || res != 0 {
+		t.Errorf("expected 0 for a NULL bound, got %f (%v)", res, err)
+	}
+	if _, err := h.RangeFraction(
+		evalCtx, parser.TypeInt, parser.NewDString("a"), nil,
+	); !testutils.IsError(err, "cannot compare") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+)
+
+// TestSampleReservoir verifies that the reservoir retains the rows with the
+// smallest ranks.
+func TestSampleReservoir(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	rng, _ := randutil.NewPseudoRand()
+	for _, n := range []int{10, 100, 1000} {
+		for _, k := range []int{1, 5, 10, 100} {
+			ranks := make([]int, n)
+			var sr SampleReservoir
+			sr.Init(k)
+			for i := 0; i < n; i++ {
+				ranks[i] = rng.Int()
+				row := sqlbase.EncDatumRow{
+					sqlbase.DatumToEncDatum(sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_INT}, parser.NewDInt(parser.DInt(ranks[i]))),
+				}
+				sr.SampleRow(row, uint64(ranks[i]))
+			}
+			samples := sr.Get()
+			sampledRanks := make([]int, len(samples))
+			for i, s := range samples {
+				if int(*s.Row[0].Datum.(*parser.DInt)) != int(s.Rank) {
+					t.Fatalf("sampled row %s does not match its rank %d", s.Row, s.Rank)
+				}
+				sampledRanks[i] = int(s.Rank)
+			}
+			sort.Ints(ranks)
+			sort.Ints(sampledRanks)
+			expected := ranks
+			if len(expected) > k {
+				expected = expected[:k]
+			}
+			if len(sampledRanks) != len(expected) {
+				t.Fatalf("n=%d k=%d: expected %d samples, got %d", n, k, len(expected), len(sampledRanks))
+			}
+			for i := range expected {
+				if expected[i] != sampledRanks[i] {
+					t.Fatalf("n=%d k=%d: expected ranks %v, got %v", n, k, expected, sampledRanks)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// sketchPrecision is the number of bits of the hash used to select a
+	// register of a dense sketch.
+	sketchPrecision = 14
+	sketchRegisters = 1 << sketchPrecision
+	// sketchSparseMax is the number of hashes above which a sparse sketch is
+	// converted to a dense one; both representations then take about the same
+	// amount of memory.
+	sketchSparseMax = sketchRegisters / 8
+)
+
+// Sketch formats, stored in the first byte of an encoded sketch.
+const (
+	sketchSparse byte = iota
+	sketchDense
+)
+
+// Sketch is a HyperLogLog sketch that estimates the number of distinct values
+// inserted into it. Small sets of values are tracked exactly by keeping their
+// hashes (the "sparse" representation); once there are more than
+// sketchSparseMax of them, the sketch switches to an array of registers.
+//
+// Sketches built on different subsets of the values can be merged, which
+// allows them to be computed by multiple processors.
+type Sketch struct {
+	sparse    map[uint64]struct{}
+	registers []uint8
+}
+
+// NewSketch creates an empty sketch.
+func NewSketch() *Sketch {
+	return &Sketch{sparse: make(map[uint64]struct{})}
+}
+
+// Insert adds an encoded value to the sketch.
+func (s *Sketch) Insert(value []byte) {
+	h := fnv.New64a()
+	_, _ = h.Write(value)
+	s.insertHash(mix64(h.Sum64()))
+}
+
+func (s *Sketch) insertHash(hash uint64) {
+	if s.sparse != nil {
+		s.sparse[hash] = struct{}{}
+		if len(s.sparse) > sketchSparseMax {
+			s.toDense()
+		}
+		return
+	}
+	idx := hash >> (64 - sketchPrecision)
+	// The rank is the position of the leftmost 1 bit in the remaining bits of
+	// the hash; the sentinel bit bounds it.
+	rank := leadingZeros64(hash<<sketchPrecision|1<<(sketchPrecision-1)) + 1
+	if rank > s.registers[idx] {
+		s.registers[idx] = rank
+	}
+}
+
+func (s *Sketch) toDense() {
+	sparse := s.sparse
+	s.sparse = nil
+	s.registers = make([]uint8, sketchRegisters)
+	for hash := range sparse {
+		s.insertHash(hash)
+	}
+}
+
+// Merge adds all the values of another sketch to this one.
+func (s *Sketch) Merge(other *Sketch) {
+	if other.sparse != nil {
+		for hash := range other.sparse {
+			s.insertHash(hash)
+		}
+		return
+	}
+	if s.sparse != nil {
+		s.toDense()
+	}
+	for i, r := range other.registers {
+		if r > s.registers[i] {
+			s.registers[i] = r
+		}
+	}
+}
+
+// Estimate returns the estimated number of distinct values in the sketch.
+func (s *Sketch) Estimate() int64 {
+	if s.sparse != nil {
+		return int64(len(s.sparse))
+	}
+	const m = float64(sketchRegisters)
+	sum := 0.0
+	zeros := 0
+	for _, r := range s.registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	alpha := 0.7213 / (1 + 1.079/m)
+	estimate := alpha * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		// Use linear counting for small cardinalities.
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return int64(estimate + 0.5)
+}
+
+// MarshalBinary encodes the sketch so that it can be sent to another
+// processor.
+func (s *Sketch) MarshalBinary() ([]byte, error) {
+	if s.sparse != nil {
+		data := make([]byte, 1, 1+8*len(s.sparse))
+		data[0] = sketchSparse
+		for hash := range s.sparse {
+			data = encodeUint64(data, hash)
+		}
+		return data, nil
+	}
+	data := make([]byte, 1+sketchRegisters)
+	data[0] = sketchDense
+	copy(data[1:], s.registers)
+	return data, nil
+}
+
+// UnmarshalBinary decodes a sketch encoded with MarshalBinary.
+func (s *Sketch) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return errors.Errorf("empty sketch")
+	}
+	switch data[0] {
+	case sketchSparse:
+		if (len(data)-1)%8 != 0 {
+			return errors.Errorf("invalid sparse sketch length %d", len(data))
+		}
+		s.registers = nil
+		s.sparse = make(map[uint64]struct{}, (len(data)-1)/8)
+		for i := 1; i < len(data); i += 8 {
+			s.sparse[binary.BigEndian.Uint64(data[i:])] = struct{}{}
+		}
+	case sketchDense:
+		if len(data) != 1+sketchRegisters {
+			return errors.Errorf("invalid dense sketch length %d", len(data))
+		}
+		s.sparse = nil
+		s.registers = append([]uint8(nil), data[1:]...)
+	default:
+		return errors.Errorf("unknown sketch format %d", data[0])
+	}
+	return nil
+}
+
+func encodeUint64(b []byte, v uint64) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], v)
+	return append(b, buf[:]...)
+}
+
+// mix64 is the finalizer of MurmurHash3; it makes every bit of the result
+// depend on every bit of the input, which FNV alone does not guarantee for the
+// high bits used to select registers.
+func mix64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+func leadingZeros64(x uint64) uint8 {
+	var n uint8
+	for x&(1<<63) == 0 && n < 64 {
+		x <<= 1
+		n++
+	}
+	return n
+}
@@ -0,0 +1,74 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestSketch(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, numDistinct := range []int{0, 1, 10, 1000, 5000, 100000} {
+		t.Run(fmt.Sprint(numDistinct), func(t *testing.T) {
+			// Insert every value twice, split across two sketches that are
+			// merged through their encodings.
+			s1, s2 := NewSketch(), NewSketch()
+			for i := 0; i < numDistinct; i++ {
+				v := []byte(fmt.Sprintf("value-%d", i))
+				s1.Insert(v)
+				if i%2 == 0 {
+					s1.Insert(v)
+				} else {
+					s2.Insert(v)
+				}
+			}
+			data, err := s2.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded Sketch
+			if err := decoded.UnmarshalBinary(data); err != nil {
+				t.Fatal(err)
+			}
+			s1.Merge(&decoded)
+
+			estimate := s1.Estimate()
+			if numDistinct <= sketchSparseMax {
+				// The sparse representation is exact.
+				if estimate != int64(numDistinct) {
+					t.Fatalf("expected %d distinct values, got %d", numDistinct, estimate)
+				}
+				return
+			}
+			// The standard error of a dense sketch is about 1%.
+			if errPct := 100 * float64(estimate-int64(numDistinct)) / float64(numDistinct); errPct < -5 || errPct > 5 {
+				t.Fatalf("expected about %d distinct values, got %d", numDistinct, estimate)
+			}
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		var s Sketch
+		for _, data := range [][]byte{nil, {sketchSparse, 1}, {sketchDense, 1}, {7}} {
+			if err := s.UnmarshalBinary(data); err == nil {
+				t.Errorf("expected error decoding %v", data)
+			}
+		}
+	})
+}
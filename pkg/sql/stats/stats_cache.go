@@ -0,0 +1,252 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/cache"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// TableStatistic is a statistic for a column (or group of columns) of a
+// table, as stored in system.table_statistics.
+type TableStatistic struct {
+	TableID     sqlbase.ID
+	StatisticID int64
+	// Name is the name of the statistic; it may be empty.
+	Name      string
+	ColumnIDs []sqlbase.ColumnID
+	CreatedAt time.Time
+
+	// RowCount is the estimated number of rows in the table.
+	RowCount int64
+	// DistinctCount is the estimated number of distinct values of the columns
+	// in ColumnIDs.
+	DistinctCount int64
+	// NullCount is the estimated number of rows which have a NULL value in any
+	// of the columns in ColumnIDs.
+	NullCount int64
+
+	// Histogram is only set for single-column statistics; it is nil if no
+	// histogram was collected.
+	Histogram *HistogramData
+}
+
+// statsCacheEntryTTL is the amount of time after which a cache entry is
+// refreshed. Statistics created on other nodes only invalidate the cache
+// locally, so this bounds how long a node can use stale statistics.
+const statsCacheEntryTTL = time.Minute
+
+type cacheEntry struct {
+	stats     []*TableStatistic
+	fetchedAt time.Time
+}
+
+// A TableStatisticsCache is an LRU cache of the statistics in
+// system.table_statistics, keyed by table ID. Each entry consists of all the
+// statistics for the different columns and column groups of a table.
+type TableStatisticsCache struct {
+	// NB: This can't be a RWMutex for lookup because UnorderedCache.Get
+	// manipulates an internal LRU list.
+	mu struct {
+		syncutil.Mutex
+		cache *cache.UnorderedCache
+	}
+	db       *client.DB
+	executor sqlutil.InternalExecutor
+}
+
+// NewTableStatisticsCache creates a new TableStatisticsCache that can hold
+// statistics for <cacheSize> tables.
+func NewTableStatisticsCache(
+	cacheSize int, db *client.DB, executor sqlutil.InternalExecutor,
+) *TableStatisticsCache {
+	sc := &TableStatisticsCache{db: db, executor: executor}
+	sc.mu.cache = cache.NewUnorderedCache(cache.Config{
+		Policy:      cache.CacheLRU,
+		ShouldEvict: func(s int, key, value interface{}) bool { return s > cacheSize },
+	})
+	return sc
+}
+
+// GetTableStats looks up statistics for the requested table ID in the cache,
+// and if the stats are not present in the cache, it looks them up in
+// system.table_statistics. The statistics are ordered by their creation time,
+// most recent first.
+//
+// System tables never have statistics; looking them up would require reading
+// system.table_statistics while planning queries against it.
+func (sc *TableStatisticsCache) GetTableStats(
+	ctx context.Context, tableID sqlbase.ID,
+) ([]*TableStatistic, error) {
+	if tableID <= keys.MaxReservedDescID {
+		return nil, nil
+	}
+	sc.mu.Lock()
+	if v, ok := sc.mu.cache.Get(tableID); ok {
+		e := v.(*cacheEntry)
+		if timeutil.Since(e.fetchedAt) < statsCacheEntryTTL {
+			sc.mu.Unlock()
+			return e.stats, nil
+		}
+	}
+	sc.mu.Unlock()
+
+	stats, err := sc.getTableStatsFromDB(ctx, tableID)
+	if err != nil {
+		return nil, err
+	}
+	sc.mu.Lock()
+	sc.mu.cache.Add(tableID, &cacheEntry{stats: stats, fetchedAt: timeutil.Now()})
+	sc.mu.Unlock()
+	return stats, nil
+}
+
+// InvalidateTableStats invalidates the cached statistics for the given table
+// ID.
+func (sc *TableStatisticsCache) InvalidateTableStats(ctx context.Context, tableID sqlbase.ID) {
+	if log.V(1) {
+		log.Infof(ctx, "evicting statistics for table %d", tableID)
+	}
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.mu.cache.Del(tableID)
+}
+
+const (
+	tableIDIndex = iota
+	statisticsIDIndex
+	nameIndex
+	columnIDsIndex
+	createdAtIndex
+	rowCountIndex
+	distinctCountIndex
+	nullCountIndex
+	histogramIndex
+	statsLen
+)
+
+// parseStats converts the given datums to a TableStatistic object.
+func parseStats(datums parser.Datums) (*TableStatistic, error) {
+	if datums == nil || datums.Len() == 0 {
+		return nil, nil
+	}
+
+	// Validate the input length.
+	if datums.Len() != statsLen {
+		return nil, errors.Errorf("%d values returned from table statistics lookup. Expected %d", datums.Len(), statsLen)
+	}
+
+	// Validate the input types.
+	expectedTypes := []struct {
+		fieldName    string
+		fieldIndex   int
+		expectedType parser.Type
+		nullable     bool
+	}{
+		{"tableID", tableIDIndex, parser.TypeInt, false},
+		{"statisticsID", statisticsIDIndex, parser.TypeInt, false},
+		{"name", nameIndex, parser.TypeString, true},
+		{"columnIDs", columnIDsIndex, parser.TArray{Typ: parser.TypeInt}, false},
+		{"createdAt", createdAtIndex, parser.TypeTimestamp, false},
+		{"rowCount", rowCountIndex, parser.TypeInt, false},
+		{"distinctCount", distinctCountIndex, parser.TypeInt, false},
+		{"nullCount", nullCountIndex, parser.TypeInt, false},
+		{"histogram", histogramIndex, parser.TypeBytes, true},
+	}
+	for _, v := range expectedTypes {
+		if datums[v.fieldIndex] == parser.DNull {
+			if !v.nullable {
+				return nil, errors.Errorf("%s returned from table statistics lookup is NULL", v.fieldName)
+			}
+			continue
+		}
+		if !datums[v.fieldIndex].ResolvedType().Equivalent(v.expectedType) {
+			return nil, errors.Errorf("%s returned from table statistics lookup has type %s. Expected %s",
+				v.fieldName, datums[v.fieldIndex].ResolvedType(), v.expectedType)
+		}
+	}
+
+	// Extract datum values.
+	res := &TableStatistic{
+		TableID:       sqlbase.ID(*datums[tableIDIndex].(*parser.DInt)),
+		StatisticID:   int64(*datums[statisticsIDIndex].(*parser.DInt)),
+		CreatedAt:     datums[createdAtIndex].(*parser.DTimestamp).Time,
+		RowCount:      int64(*datums[rowCountIndex].(*parser.DInt)),
+		DistinctCount: int64(*datums[distinctCountIndex].(*parser.DInt)),
+		NullCount:     int64(*datums[nullCountIndex].(*parser.DInt)),
+	}
+	columnIDs := datums[columnIDsIndex].(*parser.DArray)
+	res.ColumnIDs = make([]sqlbase.ColumnID, len(columnIDs.Array))
+	for i, d := range columnIDs.Array {
+		res.ColumnIDs[i] = sqlbase.ColumnID(*d.(*parser.DInt))
+	}
+	if datums[nameIndex] != parser.DNull {
+		res.Name = string(*datums[nameIndex].(*parser.DString))
+	}
+	if datums[histogramIndex] != parser.DNull {
+		res.Histogram = &HistogramData{}
+		if err := res.Histogram.Unmarshal([]byte(*datums[histogramIndex].(*parser.DBytes))); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// getTableStatsFromDB retrieves the statistics in system.table_statistics
+// for the given table ID.
+func (sc *TableStatisticsCache) getTableStatsFromDB(
+	ctx context.Context, tableID sqlbase.ID,
+) ([]*TableStatistic, error) {
+	const getTableStatisticsStmt = `
+SELECT "tableID", "statisticID", name, "columnIDs", "createdAt", "rowCount", "distinctCount",
+       "nullCount", histogram
+FROM system.table_statistics
+WHERE "tableID" = $1
+ORDER BY "createdAt" DESC
+`
+	var rows []parser.Datums
+	if err := sc.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		var err error
+		rows, err = sc.executor.QueryRowsInTransaction(
+			ctx, "get-table-statistics", txn, getTableStatisticsStmt, tableID,
+		)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	var statsList []*TableStatistic
+	for _, row := range rows {
+		stats, err := parseStats(row)
+		if err != nil {
+			return nil, err
+		}
+		statsList = append(statsList, stats)
+	}
+	return statsList, nil
+}
@@ -353,7 +353,9 @@ func (v *subqueryVisitor) VisitPre(expr parser.Expr) (recurse bool, newExpr pars
 	// Calling newPlan() might recursively invoke expandSubqueries, so we need to preserve
 	// the state of the visitor across the call to newPlan().
 	visitorCopy := v.planner.subqueryVisitor
+	v.planner.subqueryDepth++
 	plan, err := v.planner.newPlan(v.ctx, sq.Select, nil)
+	v.planner.subqueryDepth--
 	v.planner.subqueryVisitor = visitorCopy
 	if err != nil {
 		v.err = err
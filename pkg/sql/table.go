@@ -161,6 +161,24 @@ func getViewDesc(
 	return desc, nil
 }
 
+// getSequenceDesc returns a table descriptor for a sequence, or nil if the
+// descriptor is not found.
+//
+// Returns an error if the underlying table descriptor actually
+// represents a table or view rather than a sequence.
+func getSequenceDesc(
+	ctx context.Context, txn *client.Txn, vt VirtualTabler, tn *parser.TableName,
+) (*sqlbase.TableDescriptor, error) {
+	desc, err := getTableOrViewDesc(ctx, txn, vt, tn)
+	if err != nil {
+		return desc, err
+	}
+	if desc != nil && !desc.IsSequence() {
+		return nil, sqlbase.NewWrongObjectTypeError(tn, "sequence")
+	}
+	return desc, nil
+}
+
 // MustGetTableOrViewDesc returns a table descriptor for either a table or
 // view, or an error if the descriptor is not found. allowAdding when set allows
 // a table descriptor in the ADD state to also be returned.
@@ -548,7 +566,9 @@ func getTableNames(
 	return tableNames, nil
 }
 
-func (p *planner) getAliasedTableName(n parser.TableExpr) (*parser.TableName, error) {
+func (p *planner) getAliasedTableName(
+	ctx context.Context, n parser.TableExpr,
+) (*parser.TableName, error) {
 	if ate, ok := n.(*parser.AliasedTableExpr); ok {
 		n = ate.Expr
 	}
@@ -556,7 +576,7 @@ func (p *planner) getAliasedTableName(n parser.TableExpr) (*parser.TableName, er
 	if !ok {
 		return nil, errors.Errorf("TODO(pmattis): unsupported FROM: %s", n)
 	}
-	return table.NormalizeWithDatabaseName(p.session.Database)
+	return p.normalizeTableName(ctx, table)
 }
 
 // createSchemaChangeJob finalizes the current mutations in the table
@@ -666,8 +686,9 @@ func expandTableGlob(
 }
 
 // searchAndQualifyDatabase augments the table name with the database
-// where it was found. It searches first in the session current
-// database, if that's defined, otherwise the search path.  The
+// where it was found. It searches first in the temporary schema of the
+// session, then in the session current database, if that's defined,
+// otherwise the search path.  The
 // provided TableName is modified in-place in case of success, and
 // left unchanged otherwise.
 // The table name must not be qualified already.
@@ -683,6 +704,10 @@ func (p *planner) searchAndQualifyDatabase(ctx context.Context, tn *parser.Table
 		descFunc = getTableOrViewDesc
 	}
 
+	if found, err := p.findTemporaryTable(ctx, tn, descFunc); err != nil || found {
+		return err
+	}
+
 	if p.session.Database != "" {
 		t.DatabaseName = parser.Name(p.session.Database)
 		desc, err := descFunc(ctx, p.txn, p.getVirtualTabler(), &t)
@@ -779,7 +804,7 @@ func (p *planner) findTableContainingIndex(
 func (p *planner) expandIndexName(
 	ctx context.Context, index *parser.TableNameWithIndex,
 ) (*parser.TableName, error) {
-	tn, err := index.Table.NormalizeWithDatabaseName(p.session.Database)
+	tn, err := p.normalizeTableName(ctx, &index.Table)
 	if err != nil {
 		return nil, err
 	}
@@ -819,7 +844,7 @@ func (p *planner) getTableAndIndex(
 	var err error
 	if tableWithIndex == nil {
 		// Variant: ALTER TABLE
-		tn, err = table.NormalizeWithDatabaseName(p.session.Database)
+		tn, err = p.normalizeTableName(ctx, table)
 	} else {
 		// Variant: ALTER INDEX
 		tn, err = p.expandIndexName(ctx, tableWithIndex)
@@ -834,7 +859,7 @@ func (p *planner) getTableAndIndex(
 	if tableDesc == nil {
 		return nil, nil, sqlbase.NewUndefinedRelationError(tn)
 	}
-	if err := p.CheckPrivilege(tableDesc, privilege); err != nil {
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege); err != nil {
 		return nil, nil, err
 	}
 
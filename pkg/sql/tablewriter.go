@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/mon"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -94,6 +95,7 @@ var _ tableWriter = (*tableDeleter)(nil)
 type tableInserter struct {
 	ri         sqlbase.RowInserter
 	autoCommit bool
+	triggers   *rowTriggers
 
 	// Set by init.
 	txn *client.Txn
@@ -111,6 +113,13 @@ func (ti *tableInserter) init(txn *client.Txn) error {
 func (ti *tableInserter) row(
 	ctx context.Context, values parser.Datums, traceKV bool,
 ) (parser.Datums, error) {
+	if ti.triggers != nil {
+		if ok, err := ti.triggers.fireRow(
+			ctx, parser.TriggerInsert, values, ti.ri.InsertColIDtoRowIndex, nil, nil,
+		); err != nil || !ok {
+			return nil, err
+		}
+	}
 	return nil, ti.ri.InsertRow(ctx, ti.b, values, false, traceKV)
 }
 
@@ -128,6 +137,9 @@ func (ti *tableInserter) finalize(ctx context.Context, _ bool) (*sqlbase.RowCont
 	if err != nil {
 		return nil, sqlbase.ConvertBatchError(ctx, ti.ri.Helper.TableDesc, ti.b)
 	}
+	if ti.triggers != nil {
+		return nil, ti.triggers.fireQueued(ctx)
+	}
 	return nil, nil
 }
 
@@ -143,13 +155,18 @@ func (ti *tableInserter) fkSpanCollector() sqlbase.FkSpanCollector {
 type tableUpdater struct {
 	ru         sqlbase.RowUpdater
 	autoCommit bool
+	triggers   *rowTriggers
 
 	// Set by init.
 	txn *client.Txn
 	b   *client.Batch
 }
 
-func (ti *tableInserter) close(_ context.Context) {}
+func (ti *tableInserter) close(ctx context.Context) {
+	if ti.triggers != nil {
+		ti.triggers.close(ctx)
+	}
+}
 
 func (tu *tableUpdater) walkExprs(_ func(desc string, index int, expr parser.TypedExpr)) {}
 
@@ -164,9 +181,55 @@ func (tu *tableUpdater) row(
 ) (parser.Datums, error) {
 	oldValues := values[:len(tu.ru.FetchCols)]
 	updateValues := values[len(tu.ru.FetchCols):]
+	if tu.triggers != nil {
+		if ok, err := fireUpdateTriggers(
+			ctx, tu.triggers, &tu.ru, oldValues, updateValues,
+		); err != nil || !ok {
+			return nil, err
+		}
+	}
 	return tu.ru.UpdateRow(ctx, tu.b, oldValues, updateValues, traceKV)
 }
 
+// fireUpdateTriggers fires the triggers for a row updated by ru, and stores
+// the values returned by the BEFORE triggers in updateValues. It returns
+// false if the row is skipped.
+func fireUpdateTriggers(
+	ctx context.Context,
+	triggers *rowTriggers,
+	ru *sqlbase.RowUpdater,
+	oldValues, updateValues parser.Datums,
+) (bool, error) {
+	newValues := append(parser.Datums(nil), oldValues...)
+	for i, col := range ru.UpdateCols {
+		newValues[ru.FetchColIDtoRowIndex[col.ID]] = updateValues[i]
+	}
+	if ok, err := triggers.fireRow(
+		ctx, parser.TriggerUpdate,
+		newValues, ru.FetchColIDtoRowIndex, oldValues, ru.FetchColIDtoRowIndex,
+	); err != nil || !ok {
+		return false, err
+	}
+	updated := make(map[sqlbase.ColumnID]struct{}, len(ru.UpdateCols))
+	for i, col := range ru.UpdateCols {
+		updateValues[i] = newValues[ru.FetchColIDtoRowIndex[col.ID]]
+		updated[col.ID] = struct{}{}
+	}
+	// The RowUpdater only writes the columns updated by the statement.
+	for _, col := range ru.FetchCols {
+		if _, ok := updated[col.ID]; ok {
+			continue
+		}
+		idx := ru.FetchColIDtoRowIndex[col.ID]
+		if newValues[idx].Compare(&triggers.p.evalCtx, oldValues[idx]) != 0 {
+			return false, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"BEFORE trigger cannot set column %q, which is not updated by the statement",
+				col.Name)
+		}
+	}
+	return true, nil
+}
+
 func (tu *tableUpdater) finalize(ctx context.Context, _ bool) (*sqlbase.RowContainer, error) {
 	var err error
 	if tu.autoCommit {
@@ -181,6 +244,9 @@ func (tu *tableUpdater) finalize(ctx context.Context, _ bool) (*sqlbase.RowConta
 	if err != nil {
 		return nil, sqlbase.ConvertBatchError(ctx, tu.ru.Helper.TableDesc, tu.b)
 	}
+	if tu.triggers != nil {
+		return nil, tu.triggers.fireQueued(ctx)
+	}
 	return nil, nil
 }
 
@@ -192,7 +258,11 @@ func (tu *tableUpdater) fkSpanCollector() sqlbase.FkSpanCollector {
 	return tu.ru.Fks
 }
 
-func (tu *tableUpdater) close(_ context.Context) {}
+func (tu *tableUpdater) close(ctx context.Context) {
+	if tu.triggers != nil {
+		tu.triggers.close(ctx)
+	}
+}
 
 type tableUpsertEvaler interface {
 	expressionCarrier
@@ -236,6 +306,7 @@ type tableUpserter struct {
 	alloc         *sqlbase.DatumAlloc
 	mon           *mon.BytesMonitor
 	collectRows   bool
+	triggers      *rowTriggers
 
 	// These are set for ON CONFLICT DO UPDATE, but not for DO NOTHING
 	updateCols []sqlbase.ColumnDescriptor
@@ -244,6 +315,7 @@ type tableUpserter struct {
 	// Set by init.
 	txn                   *client.Txn
 	fkTables              sqlbase.TableLookupsByID // for fk checks in update case
+	evalCtx               *parser.EvalContext      // for fk actions in update case
 	ru                    sqlbase.RowUpdater
 	updateColIDtoRowIndex map[sqlbase.ColumnID]int
 	fetchCols             []sqlbase.ColumnDescriptor
@@ -297,7 +369,9 @@ func (tu *tableUpserter) init(txn *client.Txn) error {
 		// path is disabled during all mutations.
 		len(tableDesc.Mutations) == 0 &&
 		// For the fast path, all columns must be specified in the insert.
-		len(tu.ri.InsertCols) == len(tableDesc.Columns)
+		len(tu.ri.InsertCols) == len(tableDesc.Columns) &&
+		// Triggers need to know whether each row is inserted or updated.
+		tu.triggers == nil
 	if enableFastPath {
 		tu.fastPathBatch = tu.txn.NewBatch()
 		tu.fastPathKeys = make(map[string]struct{})
@@ -315,7 +389,7 @@ func (tu *tableUpserter) init(txn *client.Txn) error {
 		var err error
 		tu.ru, err = sqlbase.MakeRowUpdater(
 			txn, tableDesc, tu.fkTables, tu.updateCols, requestedCols,
-			sqlbase.RowUpdaterDefault, tu.alloc,
+			sqlbase.RowUpdaterDefault, tu.evalCtx, tu.alloc,
 		)
 		if err != nil {
 			return err
@@ -422,6 +496,17 @@ func (tu *tableUpserter) flush(
 		existingRow := existingRows[i]
 
 		if existingRow == nil {
+			if tu.triggers != nil {
+				ok, err := tu.triggers.fireRow(
+					ctx, parser.TriggerInsert, insertRow, tu.ri.InsertColIDtoRowIndex, nil, nil,
+				)
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					continue
+				}
+			}
 			err := tu.ri.InsertRow(ctx, b, insertRow, false, traceKV)
 			if err != nil {
 				return nil, err
@@ -454,6 +539,17 @@ func (tu *tableUpserter) flush(
 				if err != nil {
 					return nil, err
 				}
+				if tu.triggers != nil {
+					ok, err := fireUpdateTriggers(
+						ctx, tu.triggers, &tu.ru, existingValues, updateValues,
+					)
+					if err != nil {
+						return nil, err
+					}
+					if !ok {
+						continue
+					}
+				}
 				updatedRow, err := tu.ru.UpdateRow(ctx, b, existingValues, updateValues, traceKV)
 				if err != nil {
 					return nil, err
@@ -479,6 +575,11 @@ func (tu *tableUpserter) flush(
 	if err != nil {
 		return nil, sqlbase.ConvertBatchError(ctx, tableDesc, b)
 	}
+	if tu.triggers != nil {
+		if err := tu.triggers.fireQueued(ctx); err != nil {
+			return nil, err
+		}
+	}
 	return tu.rowsUpserted, nil
 }
 
@@ -511,11 +612,13 @@ func (tu *tableUpserter) upsertRowPKs(ctx context.Context, traceKV bool) ([]roac
 	b := tu.txn.NewBatch()
 	for i := 0; i < tu.insertRows.Len(); i++ {
 		insertRow := tu.insertRows.At(i)
-		entry, err := sqlbase.EncodeSecondaryIndex(
+		entries, err := sqlbase.EncodeSecondaryIndex(
 			tableDesc, &tu.conflictIndex, tu.ri.InsertColIDtoRowIndex, insertRow)
 		if err != nil {
 			return nil, err
 		}
+		// The conflict index is unique, so it has a single entry per row.
+		entry := entries[0]
 		if traceKV {
 			log.VEventf(ctx, 2, "Get %s", entry.Key)
 		}
@@ -646,6 +749,9 @@ func (tu *tableUpserter) close(ctx context.Context) {
 	if tu.rowsUpserted != nil {
 		tu.rowsUpserted.Close(ctx)
 	}
+	if tu.triggers != nil {
+		tu.triggers.close(ctx)
+	}
 }
 
 // tableDeleter handles writing kvs and forming table rows for deletes.
@@ -653,6 +759,7 @@ type tableDeleter struct {
 	rd         sqlbase.RowDeleter
 	autoCommit bool
 	alloc      *sqlbase.DatumAlloc
+	triggers   *rowTriggers
 
 	// Set by init.
 	txn *client.Txn
@@ -670,6 +777,13 @@ func (td *tableDeleter) init(txn *client.Txn) error {
 func (td *tableDeleter) row(
 	ctx context.Context, values parser.Datums, traceKV bool,
 ) (parser.Datums, error) {
+	if td.triggers != nil {
+		if ok, err := td.triggers.fireRow(
+			ctx, parser.TriggerDelete, nil, nil, values, td.rd.FetchColIDtoRowIndex,
+		); err != nil || !ok {
+			return nil, err
+		}
+	}
 	return nil, td.rd.DeleteRow(ctx, td.b, values, traceKV)
 }
 
@@ -680,7 +794,13 @@ func (td *tableDeleter) finalize(ctx context.Context, _ bool) (*sqlbase.RowConta
 		// coordinator.
 		return nil, td.txn.CommitInBatch(ctx, td.b)
 	}
-	return nil, td.txn.Run(ctx, td.b)
+	if err := td.txn.Run(ctx, td.b); err != nil {
+		return nil, err
+	}
+	if td.triggers != nil {
+		return nil, td.triggers.fireQueued(ctx)
+	}
+	return nil, nil
 }
 
 // fastPathAvailable returns true if the fastDelete optimization can be used.
@@ -942,4 +1062,8 @@ func (td *tableDeleter) fkSpanCollector() sqlbase.FkSpanCollector {
 	return td.rd.Fks
 }
 
-func (td *tableDeleter) close(_ context.Context) {}
+func (td *tableDeleter) close(ctx context.Context) {
+	if td.triggers != nil {
+		td.triggers.close(ctx)
+	}
+}
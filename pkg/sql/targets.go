@@ -38,7 +38,7 @@ func (p *planner) computeRender(
 	// column name, we determine the name before we perform any manipulations to
 	// the expression.
 	if outputName == autoGenerateRenderOutputName {
-		if outputName, err = getRenderColName(p.session.SearchPath, target); err != nil {
+		if outputName, err = getRenderColName(p.session.SearchPath, p, target); err != nil {
 			return sqlbase.ResultColumn{}, nil, err
 		}
 	}
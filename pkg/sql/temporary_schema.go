@@ -0,0 +1,442 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// The temporary tables of a session live in a database private to that
+// session, its temporary schema. The temporary schema is created along
+// with the first temporary table of the session and is dropped when the
+// session finishes. Its name identifies the node the session runs on, so
+// that temporary schemas left behind by a crashed node or a failed cleanup
+// can be found and dropped by the sweeper.
+
+// temporarySchemaPrefix is the prefix of the names of temporary schemas.
+const temporarySchemaPrefix = "pg_temp_"
+
+// temporarySchemaAlias can be used in place of a database name to refer
+// to the temporary schema of the current session.
+const temporarySchemaAlias = "pg_temp"
+
+// TemporarySchemaSweepInterval is the interval at which each node looks
+// for orphaned temporary schemas.
+//
+// TemporarySchemaSweepInterval is mutable for testing. NB: Updates to this
+// value after Executor.Start has been called will not have any effect.
+var TemporarySchemaSweepInterval = 5 * time.Minute
+
+// temporarySchemaGracePeriod is how long a node must have failed to
+// heartbeat its liveness record before its temporary schemas are
+// considered orphaned.
+const temporarySchemaGracePeriod = time.Minute
+
+// makeTemporarySchemaName returns the name of the temporary schema of a
+// session running on the given node. The timestamp makes the name unique
+// across the sessions of the node, including across restarts.
+func makeTemporarySchemaName(nodeID roachpb.NodeID, ts hlc.Timestamp) string {
+	return fmt.Sprintf("%s%d_%d_%d", temporarySchemaPrefix, nodeID, ts.WallTime, ts.Logical)
+}
+
+// parseTemporarySchemaName returns the ID of the node owning the temporary
+// schema of the given name, or false if the name is not that of a temporary
+// schema.
+func parseTemporarySchemaName(name string) (roachpb.NodeID, bool) {
+	if !strings.HasPrefix(name, temporarySchemaPrefix) {
+		return 0, false
+	}
+	parts := strings.Split(name[len(temporarySchemaPrefix):], "_")
+	if len(parts) != 3 {
+		return 0, false
+	}
+	for _, part := range parts[1:] {
+		if _, err := strconv.ParseInt(part, 10, 64); err != nil {
+			return 0, false
+		}
+	}
+	nodeID, err := strconv.ParseInt(parts[0], 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return roachpb.NodeID(nodeID), true
+}
+
+// isTemporarySchemaName returns true if name is the name of a temporary
+// schema.
+func isTemporarySchemaName(name string) bool {
+	_, ok := parseTemporarySchemaName(name)
+	return ok
+}
+
+// checkNotTemporarySchemaName returns an error if the given database name
+// is reserved for temporary schemas.
+func checkNotTemporarySchemaName(name string) error {
+	if isTemporarySchemaName(name) || name == temporarySchemaAlias {
+		return pgerror.NewErrorf(pgerror.CodeReservedNameError,
+			"database name %q is reserved for temporary schemas", name)
+	}
+	return nil
+}
+
+// temporarySchemaName returns the name of the temporary schema of the
+// session, or an empty string if the session never created a temporary
+// table.
+func (s *Session) temporarySchemaName() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.mu.TemporarySchemaName
+}
+
+// ensureTemporarySchemaName returns the name of the temporary schema of the
+// session, choosing one if necessary. The name is registered before the
+// schema is created so that the sweeper never mistakes the schema of a live
+// session for an orphaned one.
+func (s *Session) ensureTemporarySchemaName() (string, error) {
+	if s.execCfg == nil {
+		return "", errors.New("temporary tables are not supported in internal sessions")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.mu.TemporarySchemaName == "" {
+		s.mu.TemporarySchemaName = makeTemporarySchemaName(
+			s.execCfg.NodeID.Get(), s.execCfg.Clock.Now())
+	}
+	return s.mu.TemporarySchemaName, nil
+}
+
+// resetTemporarySchemaName forgets the temporary schema of the session. It
+// is used once the schema is dropped; the session will use a new one if it
+// creates another temporary table.
+func (s *Session) resetTemporarySchemaName() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.TemporarySchemaName = ""
+}
+
+// temporarySchemaNames returns the names of the temporary schemas of all
+// sessions on this node.
+func (r *SessionRegistry) temporarySchemaNames() map[string]struct{} {
+	r.Lock()
+	defer r.Unlock()
+	names := make(map[string]struct{})
+	for s := range r.store {
+		if name := s.temporarySchemaName(); name != "" {
+			names[name] = struct{}{}
+		}
+	}
+	return names
+}
+
+// isOtherSessionTemporarySchema returns true if the database of the given
+// name is the temporary schema of another session.
+func (p *planner) isOtherSessionTemporarySchema(name string) bool {
+	return isTemporarySchemaName(name) && name != p.session.temporarySchemaName()
+}
+
+// resolveTemporarySchema replaces the pg_temp alias in an explicitly
+// qualified table name with the temporary schema of the session, and
+// prevents access to the temporary schemas of other sessions.
+func (p *planner) resolveTemporarySchema(tn *parser.TableName) error {
+	if tn.DBNameOriginallyOmitted {
+		return nil
+	}
+	name := string(tn.DatabaseName)
+	if name == temporarySchemaAlias {
+		tempName := p.session.temporarySchemaName()
+		if tempName == "" {
+			return sqlbase.NewUndefinedRelationError(tn)
+		}
+		tn.DatabaseName = parser.Name(tempName)
+		return nil
+	}
+	if p.isOtherSessionTemporarySchema(name) {
+		return pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+			"cannot access temporary tables of other sessions")
+	}
+	return nil
+}
+
+// findTemporaryTable qualifies tn with the temporary schema of the session
+// if the schema contains a table or view of that name. descFunc is used to
+// look up the descriptor. The table name must not be qualified already.
+func (p *planner) findTemporaryTable(
+	ctx context.Context,
+	tn *parser.TableName,
+	descFunc func(context.Context, *client.Txn, VirtualTabler, *parser.TableName) (*sqlbase.TableDescriptor, error),
+) (bool, error) {
+	tempName := p.session.temporarySchemaName()
+	if tempName == "" {
+		return false, nil
+	}
+	t := *tn
+	t.DatabaseName = parser.Name(tempName)
+	desc, err := descFunc(ctx, p.txn, p.getVirtualTabler(), &t)
+	if err != nil && !sqlbase.IsUndefinedRelationError(err) && !sqlbase.IsUndefinedDatabaseError(err) {
+		return false, err
+	}
+	if desc == nil {
+		return false, nil
+	}
+	*tn = t
+	return true, nil
+}
+
+// qualifyTableName qualifies tn with the database holding the table. Like
+// in postgres, the temporary tables of the session shadow the tables of the
+// current database.
+func (p *planner) qualifyTableName(ctx context.Context, tn *parser.TableName) error {
+	if err := p.resolveTemporarySchema(tn); err != nil {
+		return err
+	}
+	if tn.DBNameOriginallyOmitted {
+		found, err := p.findTemporaryTable(ctx, tn, getTableOrViewDesc)
+		if err != nil || found {
+			return err
+		}
+	}
+	return tn.QualifyWithDatabase(p.session.Database)
+}
+
+// normalizeTableName normalizes t and qualifies it with the database
+// holding the table. See qualifyTableName().
+func (p *planner) normalizeTableName(
+	ctx context.Context, t *parser.NormalizableTableName,
+) (*parser.TableName, error) {
+	tn, err := t.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.qualifyTableName(ctx, tn); err != nil {
+		return nil, err
+	}
+	return tn, nil
+}
+
+// normalizeNewTableName normalizes the name of a relation about to be
+// created and qualifies it with the current database. Only temporary tables
+// can be created in the temporary schema, see qualifyTemporaryTableName().
+func (p *planner) normalizeNewTableName(t *parser.NormalizableTableName) (*parser.TableName, error) {
+	tn, err := t.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	if !tn.DBNameOriginallyOmitted &&
+		(tn.DatabaseName == temporarySchemaAlias || isTemporarySchemaName(string(tn.DatabaseName))) {
+		return nil, pgerror.NewError(pgerror.CodeInvalidTableDefinitionError,
+			"only temporary tables can be created in temporary schemas")
+	}
+	if err := tn.QualifyWithDatabase(p.session.Database); err != nil {
+		return nil, err
+	}
+	return tn, nil
+}
+
+// qualifyTemporaryTableName normalizes the name of a temporary table about
+// to be created and qualifies it with the temporary schema of the session.
+func (p *planner) qualifyTemporaryTableName(t *parser.NormalizableTableName) error {
+	tn, err := t.Normalize()
+	if err != nil {
+		return err
+	}
+	if !tn.DBNameOriginallyOmitted && tn.DatabaseName != temporarySchemaAlias &&
+		string(tn.DatabaseName) != p.session.temporarySchemaName() {
+		return pgerror.NewError(pgerror.CodeInvalidTableDefinitionError,
+			"cannot create temporary relation in non-temporary schema")
+	}
+	name, err := p.session.ensureTemporarySchemaName()
+	if err != nil {
+		return err
+	}
+	tn.DatabaseName = parser.Name(name)
+	return nil
+}
+
+// normalizeRenamedTableName normalizes the new name of the relation oldTn
+// being renamed. Relations cannot be moved into or out of the temporary
+// schema.
+func (p *planner) normalizeRenamedTableName(
+	oldTn *parser.TableName, t *parser.NormalizableTableName,
+) (*parser.TableName, error) {
+	if !isTemporarySchemaName(oldTn.Database()) {
+		return p.normalizeNewTableName(t)
+	}
+	tn, err := t.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.resolveTemporarySchema(tn); err != nil {
+		return nil, err
+	}
+	if tn.DBNameOriginallyOmitted {
+		tn.DatabaseName = oldTn.DatabaseName
+	}
+	if tn.Database() != oldTn.Database() {
+		return nil, pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+			"cannot move temporary tables out of the temporary schema")
+	}
+	return tn, nil
+}
+
+// getOrCreateTemporarySchema returns the descriptor of the temporary schema
+// of the session, creating the schema if it does not exist yet.
+func (p *planner) getOrCreateTemporarySchema(
+	ctx context.Context,
+) (*sqlbase.DatabaseDescriptor, error) {
+	name, err := p.session.ensureTemporarySchemaName()
+	if err != nil {
+		return nil, err
+	}
+	desc, err := getDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), name)
+	if err != nil || desc != nil {
+		return desc, err
+	}
+
+	desc = &sqlbase.DatabaseDescriptor{
+		Name:       name,
+		Privileges: sqlbase.NewDefaultPrivilegeDescriptor(),
+	}
+	// The session user owns the temporary schema, and thereby the temporary
+	// tables created in it.
+	desc.Privileges.Grant(p.session.User, privilege.List{privilege.ALL})
+	if _, err := p.createDatabase(ctx, desc, false /* ifNotExists */); err != nil {
+		return nil, err
+	}
+	p.session.tables.addUncommittedDatabase(desc.Name, desc.ID, false /* dropped */)
+	return desc, nil
+}
+
+// discardTemporarySchema returns a plan that drops the temporary schema of
+// the session along with all its temporary tables.
+func (p *planner) discardTemporarySchema(ctx context.Context) (planNode, error) {
+	name := p.session.temporarySchemaName()
+	if name == "" {
+		return &zeroNode{}, nil
+	}
+	plan, err := p.DropDatabase(ctx, &parser.DropDatabase{
+		Name:         parser.Name(name),
+		IfExists:     true,
+		DropBehavior: parser.DropCascade,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Temporary tables created from now on go into a new temporary schema. If
+	// the transaction does not commit, the old one is left for the sweeper.
+	p.session.resetTemporarySchemaName()
+	return plan, nil
+}
+
+// dropTemporarySchema drops the temporary schema of the given name along
+// with all its temporary tables.
+func dropTemporarySchema(
+	ctx context.Context, db *client.DB, leaseMgr *LeaseManager, name string,
+) error {
+	stmt := fmt.Sprintf(`DROP DATABASE IF EXISTS %s CASCADE`, parser.AsString(parser.Name(name)))
+	return db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		_, err := InternalExecutor{LeaseManager: leaseMgr}.ExecuteStatementInTransaction(
+			ctx, "drop-temp-schema", txn, stmt)
+		return err
+	})
+}
+
+// startTemporarySchemaSweeper periodically drops orphaned temporary schemas.
+func (e *Executor) startTemporarySchemaSweeper(ctx context.Context, interval time.Duration) {
+	e.stopper.RunWorker(ctx, func(ctx context.Context) {
+		for {
+			select {
+			case <-time.After(interval):
+				if err := e.sweepTemporarySchemas(ctx); err != nil {
+					log.Warningf(ctx, "error while sweeping temporary schemas: %s", err)
+				}
+			case <-e.stopper.ShouldStop():
+				return
+			}
+		}
+	})
+}
+
+// sweepTemporarySchemas drops the temporary schemas whose session is gone:
+// the schemas of this node that belong to no active session, typically left
+// behind by a restart of this node, and the schemas of nodes that are dead.
+func (e *Executor) sweepTemporarySchemas(ctx context.Context) error {
+	var names []string
+	if err := e.cfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		dbDescs, err := getAllDatabaseDescs(ctx, txn)
+		if err != nil {
+			return err
+		}
+		names = names[:0]
+		for _, dbDesc := range dbDescs {
+			if _, ok := parseTemporarySchemaName(dbDesc.Name); ok {
+				names = append(names, dbDesc.Name)
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	// The sessions are listed after the schemas: a session registers the name
+	// of its temporary schema before creating it, so every schema listed above
+	// whose session is still active is found here.
+	active := e.cfg.SessionRegistry.temporarySchemaNames()
+	isLive := make(map[roachpb.NodeID]bool)
+	if e.cfg.NodeLiveness != nil {
+		// A node is only considered dead once it has failed to heartbeat for
+		// temporarySchemaGracePeriod, so that a slow heartbeat does not cost
+		// its sessions their temporary tables.
+		now := e.cfg.Clock.Now().Add(-temporarySchemaGracePeriod.Nanoseconds(), 0)
+		for _, liveness := range e.cfg.NodeLiveness.GetLivenesses() {
+			isLive[liveness.NodeID] = liveness.IsLive(now, e.cfg.Clock.MaxOffset())
+		}
+	}
+
+	self := e.cfg.NodeID.Get()
+	for _, name := range names {
+		nodeID, _ := parseTemporarySchemaName(name)
+		if nodeID == self {
+			if _, ok := active[name]; ok {
+				continue
+			}
+		} else if live, ok := isLive[nodeID]; !ok || live {
+			// Without a liveness record the node cannot be known to be dead.
+			continue
+		}
+		log.Infof(ctx, "dropping orphaned temporary schema %s", name)
+		if err := dropTemporarySchema(ctx, e.cfg.DB, e.cfg.LeaseManager, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestParseTemporarySchemaName(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	name := makeTemporarySchemaName(3, hlc.Timestamp{WallTime: 1500000000000000000, Logical: 7})
+	if expected := "pg_temp_3_1500000000000000000_7"; name != expected {
+		t.Fatalf("expected %s, got %s", expected, name)
+	}
+
+	testCases := []struct {
+		name   string
+		nodeID roachpb.NodeID
+		ok     bool
+	}{
+		{name, 3, true},
+		{"pg_temp_12_1_0", 12, true},
+		{"pg_temp", 0, false},
+		{"pg_temp_", 0, false},
+		{"pg_temp_1_2", 0, false},
+		{"pg_temp_1_2_3_4", 0, false},
+		{"pg_temp_a_2_3", 0, false},
+		{"pg_temp_1_2_x", 0, false},
+		{"test", 0, false},
+	}
+	for _, tc := range testCases {
+		nodeID, ok := parseTemporarySchemaName(tc.name)
+		if nodeID != tc.nodeID || ok != tc.ok {
+			t.Errorf("%s: expected (%d, %t), got (%d, %t)", tc.name, tc.nodeID, tc.ok, nodeID, ok)
+		}
+	}
+}
+
+// TestTemporarySchemaCleanup verifies that the temporary schema of a session
+// is dropped when the session finishes, and that the sweeper drops orphaned
+// temporary schemas but leaves those of active sessions alone.
+func TestTemporarySchemaCleanup(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, sqlDB, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+	ctx := context.TODO()
+	e := s.Executor().(*Executor)
+	sqlRunner := sqlutils.MakeSQLRunner(t, sqlDB)
+
+	schemaExists := func(name string) bool {
+		var count int
+		sqlRunner.QueryRow(
+			`SELECT COUNT(*) FROM system.namespace WHERE "parentID" = 0 AND name = $1`, name,
+		).Scan(&count)
+		return count == 1
+	}
+
+	newSessionWithTempTable := func() *Session {
+		session := NewSession(
+			ctx, SessionArgs{User: security.RootUser}, e, nil, &MemoryMetrics{})
+		session.StartUnlimitedMonitor()
+		res, err := e.ExecuteStatementsBuffered(
+			session, "CREATE TEMP TABLE t (a INT); INSERT INTO t VALUES (1)", nil, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Close(ctx)
+		for _, result := range res.ResultList {
+			if result.Err != nil {
+				t.Fatal(result.Err)
+			}
+		}
+		if !schemaExists(session.temporarySchemaName()) {
+			t.Fatalf("temporary schema %q was not created", session.temporarySchemaName())
+		}
+		return session
+	}
+
+	active := newSessionWithTempTable()
+	orphaned := newSessionWithTempTable()
+	activeName, orphanedName := active.temporarySchemaName(), orphaned.temporarySchemaName()
+	if activeName == orphanedName {
+		t.Fatalf("sessions share temporary schema %s", activeName)
+	}
+
+	// Forget about the second session without finishing it, as if the node
+	// had restarted.
+	e.cfg.SessionRegistry.deregister(orphaned)
+	if err := e.sweepTemporarySchemas(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !schemaExists(activeName) {
+		t.Fatalf("temporary schema %s of an active session was dropped", activeName)
+	}
+	if schemaExists(orphanedName) {
+		t.Fatalf("orphaned temporary schema %s was not dropped", orphanedName)
+	}
+
+	active.Finish(e)
+	if schemaExists(activeName) {
+		t.Fatalf("temporary schema %s was not dropped when its session finished", activeName)
+	}
+	orphaned.Finish(e)
+}
@@ -0,0 +1,532 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"sort"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/mon"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+var triggerTimings = map[parser.TriggerTiming]sqlbase.TableDescriptor_Trigger_Timing{
+	parser.TriggerBefore: sqlbase.TableDescriptor_Trigger_BEFORE,
+	parser.TriggerAfter:  sqlbase.TableDescriptor_Trigger_AFTER,
+}
+
+var triggerEvents = map[parser.TriggerEvent]sqlbase.TableDescriptor_Trigger_Event{
+	parser.TriggerInsert: sqlbase.TableDescriptor_Trigger_INSERT,
+	parser.TriggerUpdate: sqlbase.TableDescriptor_Trigger_UPDATE,
+	parser.TriggerDelete: sqlbase.TableDescriptor_Trigger_DELETE,
+}
+
+// createTriggerNode implements CREATE TRIGGER.
+type createTriggerNode struct {
+	tableDesc *sqlbase.TableDescriptor
+	trigger   sqlbase.TableDescriptor_Trigger
+}
+
+// CreateTrigger adds a trigger to a table.
+// Privileges: CREATE on table.
+//   Notes: postgres requires TRIGGER on the table and EXECUTE on the
+//          function.
+func (p *planner) CreateTrigger(ctx context.Context, n *parser.CreateTrigger) (planNode, error) {
+	tn, err := p.normalizeTableName(ctx, &n.Table)
+	if err != nil {
+		return nil, err
+	}
+	tableDesc, err := getTableDesc(ctx, p.txn, p.getVirtualTabler(), tn)
+	if err != nil {
+		return nil, err
+	}
+	if tableDesc == nil {
+		return nil, sqlbase.NewUndefinedRelationError(tn)
+	}
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	name := n.Name.Normalize()
+	for _, t := range tableDesc.Triggers {
+		if t.Name == name {
+			return nil, pgerror.NewErrorf(pgerror.CodeDuplicateObjectError,
+				"trigger %q for relation %q already exists", name, tableDesc.Name)
+		}
+	}
+
+	dbDesc, funcName, err := p.resolveFunctionName(ctx, n.Func)
+	if err != nil {
+		return nil, err
+	}
+	if dbDesc.ID != tableDesc.ParentID {
+		return nil, pgerror.NewErrorf(pgerror.CodeInvalidObjectDefinitionError,
+			"trigger function %s() must be in the database of table %q", funcName, tableDesc.Name)
+	}
+	if _, err := p.lookupTriggerFunction(ctx, dbDesc, funcName); err != nil {
+		return nil, err
+	}
+
+	trigger := sqlbase.TableDescriptor_Trigger{
+		Name:         name,
+		FunctionName: funcName,
+		Timing:       triggerTimings[n.Timing],
+	}
+	for _, event := range n.Events {
+		trigger.Events = append(trigger.Events, triggerEvents[event])
+	}
+	return &createTriggerNode{tableDesc: tableDesc, trigger: trigger}, nil
+}
+
+func (n *createTriggerNode) Start(params runParams) error {
+	n.tableDesc.Triggers = append(n.tableDesc.Triggers, n.trigger)
+	return params.p.saveNonmutationAndNotify(params.ctx, n.tableDesc)
+}
+
+func (*createTriggerNode) Next(runParams) (bool, error) { return false, nil }
+func (*createTriggerNode) Close(context.Context)        {}
+func (*createTriggerNode) Values() parser.Datums        { return parser.Datums{} }
+
+// dropTriggerNode implements DROP TRIGGER.
+type dropTriggerNode struct {
+	tableDesc *sqlbase.TableDescriptor
+	idx       int
+}
+
+// DropTrigger removes a trigger from a table.
+// Privileges: CREATE on table.
+//   Notes: postgres requires ownership of the table.
+func (p *planner) DropTrigger(ctx context.Context, n *parser.DropTrigger) (planNode, error) {
+	tn, err := p.normalizeTableName(ctx, &n.Table)
+	if err != nil {
+		return nil, err
+	}
+	tableDesc, err := getTableDesc(ctx, p.txn, p.getVirtualTabler(), tn)
+	if err != nil {
+		return nil, err
+	}
+	if tableDesc == nil {
+		if n.IfExists {
+			return &zeroNode{}, nil
+		}
+		return nil, sqlbase.NewUndefinedRelationError(tn)
+	}
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	name := n.Name.Normalize()
+	for i, t := range tableDesc.Triggers {
+		if t.Name == name {
+			return &dropTriggerNode{tableDesc: tableDesc, idx: i}, nil
+		}
+	}
+	if n.IfExists {
+		return &zeroNode{}, nil
+	}
+	return nil, pgerror.NewErrorf(pgerror.CodeUndefinedObjectError,
+		"trigger %q for table %q does not exist", name, tableDesc.Name)
+}
+
+func (n *dropTriggerNode) Start(params runParams) error {
+	triggers := n.tableDesc.Triggers
+	n.tableDesc.Triggers = append(triggers[:n.idx:n.idx], triggers[n.idx+1:]...)
+	return params.p.saveNonmutationAndNotify(params.ctx, n.tableDesc)
+}
+
+func (*dropTriggerNode) Next(runParams) (bool, error) { return false, nil }
+func (*dropTriggerNode) Close(context.Context)        {}
+func (*dropTriggerNode) Values() parser.Datums        { return parser.Datums{} }
+
+// lookupTriggerFunction returns the function with the given name that can
+// be executed by triggers, that is the overload that takes no arguments and
+// returns TRIGGER.
+func (p *planner) lookupTriggerFunction(
+	ctx context.Context, dbDesc *sqlbase.DatabaseDescriptor, name string,
+) (*parser.UserDefinedFunction, error) {
+	fns, err := p.lookupUserDefinedFunctions(ctx, dbDesc, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, fn := range fns {
+		if len(fn.ArgTypes) > 0 {
+			continue
+		}
+		if fn.Def.ReturnKind != parser.FunctionReturnsTrigger {
+			return nil, pgerror.NewErrorf(pgerror.CodeInvalidObjectDefinitionError,
+				"function %s() must return type trigger", name)
+		}
+		return fn, nil
+	}
+	return nil, pgerror.NewErrorf(pgerror.CodeUndefinedFunctionError,
+		"function %s() does not exist", name)
+}
+
+// checkNoTriggerUsesFunction returns an error if a table of the given
+// database has a trigger executing the function with the given name.
+func (p *planner) checkNoTriggerUsesFunction(
+	ctx context.Context, dbDesc *sqlbase.DatabaseDescriptor, name string,
+) error {
+	tbNames, err := getTableNames(ctx, p.txn, p.getVirtualTabler(), dbDesc, false)
+	if err != nil {
+		return err
+	}
+	for i := range tbNames {
+		tableDesc, err := getTableOrViewDesc(ctx, p.txn, p.getVirtualTabler(), &tbNames[i])
+		if err != nil {
+			return err
+		}
+		if tableDesc == nil {
+			continue
+		}
+		for _, t := range tableDesc.Triggers {
+			if t.FunctionName == name {
+				return pgerror.NewErrorf(pgerror.CodeDependentObjectsStillExistError,
+					"cannot drop function %s() because trigger %q on table %q depends on it",
+					name, t.Name, tableDesc.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// rowTriggers fires the triggers of a table for the rows modified by a
+// tableWriter, in the transaction of the statement. BEFORE triggers fire
+// when the tableWriter is handed a row. AFTER triggers fire once the
+// tableWriter has sent its batch, so that they see the effects of the
+// statement.
+//
+// The trigger functions refer to the columns of the new and old versions
+// of the row as NEW.<column> and OLD.<column>. The new row is NULL for
+// DELETE and the old row is NULL for INSERT.
+//
+// Like in postgres, the first row returned by the last statement of a BEFORE
+// trigger function is the row to write: it must have a value for each column
+// of the table, e.g. `SELECT NEW.k, NEW.v || '!'`. If the function returns no
+// rows or NULL, the row is skipped: it is not written, and no other trigger
+// fires for it. The row returned for DELETE is only tested for NULL. The
+// result of AFTER trigger functions is ignored.
+type rowTriggers struct {
+	p         *planner
+	tableDesc *sqlbase.TableDescriptor
+
+	// triggers are the triggers that can fire, in the order in which they
+	// fire, along with their functions.
+	triggers []sqlbase.TableDescriptor_Trigger
+	funcs    []*parser.UserDefinedFunction
+	// hasAfter is set if any of the triggers fires AFTER the rows are written.
+	hasAfter bool
+
+	// colIdx maps the column names to their position in the rows visible
+	// to trigger functions, and types holds the column types.
+	colIdx map[string]int
+	types  []parser.Type
+
+	// checkHelper validates the rows returned by BEFORE triggers against the
+	// CHECK constraints of the table.
+	checkHelper checkHelper
+
+	// skipped is set if a BEFORE trigger skipped the row last passed to
+	// fireRow.
+	skipped bool
+
+	// queued holds the rows for which AFTER triggers have yet to fire, and
+	// queuedAcc accounts for their memory.
+	queued    []queuedTriggerRow
+	queuedAcc mon.BoundAccount
+}
+
+type queuedTriggerRow struct {
+	event          sqlbase.TableDescriptor_Trigger_Event
+	newRow, oldRow parser.Datums
+}
+
+// makeRowTriggers returns the rowTriggers for a statement modifying the
+// given table by the given kinds of events, or nil if no trigger can fire.
+// The rowTriggers must be closed by the tableWriter using them.
+func (p *planner) makeRowTriggers(
+	ctx context.Context, tableDesc *sqlbase.TableDescriptor, events ...parser.TriggerEvent,
+) (*rowTriggers, error) {
+	var triggers []sqlbase.TableDescriptor_Trigger
+	for _, t := range tableDesc.Triggers {
+		for _, event := range events {
+			if t.HasEvent(triggerEvents[event]) {
+				triggers = append(triggers, t)
+				break
+			}
+		}
+	}
+	if len(triggers) == 0 {
+		return nil, nil
+	}
+	// Like in postgres, triggers with the same timing fire in the order of
+	// their names.
+	sort.Slice(triggers, func(i, j int) bool { return triggers[i].Name < triggers[j].Name })
+
+	dbDesc, err := MustGetDatabaseDescByID(ctx, p.txn, tableDesc.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	rt := &rowTriggers{
+		p:         p,
+		tableDesc: tableDesc,
+		triggers:  triggers,
+		funcs:     make([]*parser.UserDefinedFunction, len(triggers)),
+		colIdx:    make(map[string]int, len(tableDesc.Columns)),
+		types:     make([]parser.Type, len(tableDesc.Columns)),
+		queuedAcc: p.session.TxnState.makeBoundAccount(),
+	}
+	for i, t := range triggers {
+		if rt.funcs[i], err = p.lookupTriggerFunction(ctx, dbDesc, t.FunctionName); err != nil {
+			return nil, err
+		}
+		if t.Timing == sqlbase.TableDescriptor_Trigger_AFTER {
+			rt.hasAfter = true
+		}
+	}
+	for i, col := range tableDesc.Columns {
+		rt.colIdx[col.Name] = i
+		rt.types[i] = col.Type.ToDatumType()
+	}
+	tn := parser.TableName{
+		DatabaseName: parser.Name(dbDesc.Name),
+		TableName:    parser.Name(tableDesc.Name),
+	}
+	if err := rt.checkHelper.init(ctx, p, &tn, tableDesc); err != nil {
+		return nil, err
+	}
+	return rt, nil
+}
+
+// fireRow fires the BEFORE triggers for a row modified by the given event,
+// and queues the row for the AFTER triggers. The values of the new and old
+// rows are located using the given maps; either row can be nil.
+//
+// The values of the new row returned by the BEFORE triggers are stored in
+// newValues. fireRow returns false if a BEFORE trigger skipped the row, in
+// which case the row must not be written.
+func (rt *rowTriggers) fireRow(
+	ctx context.Context,
+	event parser.TriggerEvent,
+	newValues parser.Datums,
+	newColIDtoRowIndex map[sqlbase.ColumnID]int,
+	oldValues parser.Datums,
+	oldColIDtoRowIndex map[sqlbase.ColumnID]int,
+) (bool, error) {
+	e := triggerEvents[event]
+	newRow := rt.makeRow(newValues, newColIDtoRowIndex)
+	oldRow := rt.makeRow(oldValues, oldColIDtoRowIndex)
+	newRow, replaced, err := rt.fire(ctx, sqlbase.TableDescriptor_Trigger_BEFORE, e, newRow, oldRow)
+	if err != nil {
+		return false, err
+	}
+	rt.skipped = newRow == nil
+	if rt.skipped {
+		return false, nil
+	}
+	if replaced && newValues != nil {
+		if err := rt.setNewValues(newRow, newValues, newColIDtoRowIndex); err != nil {
+			return false, err
+		}
+	}
+	if rt.hasAfter {
+		if err := rt.queuedAcc.Grow(ctx, rt.rowSize(newRow)+rt.rowSize(oldRow)); err != nil {
+			return false, err
+		}
+		rt.queued = append(rt.queued, queuedTriggerRow{event: e, newRow: newRow, oldRow: oldRow})
+	}
+	return true, nil
+}
+
+// rowSkipped returns whether a BEFORE trigger skipped the row last passed to
+// fireRow. It can be called on a nil rowTriggers.
+func (rt *rowTriggers) rowSkipped() bool {
+	return rt != nil && rt.skipped
+}
+
+// fireQueued fires the AFTER triggers for the rows queued by fireRow. It
+// must be called once the tableWriter has written the rows.
+func (rt *rowTriggers) fireQueued(ctx context.Context) error {
+	queued := rt.queued
+	rt.queued = nil
+	for _, q := range queued {
+		if _, _, err := rt.fire(
+			ctx, sqlbase.TableDescriptor_Trigger_AFTER, q.event, q.newRow, q.oldRow,
+		); err != nil {
+			return err
+		}
+	}
+	rt.queuedAcc.Clear(ctx)
+	return nil
+}
+
+// close releases the memory accounted for the queued rows.
+func (rt *rowTriggers) close(ctx context.Context) {
+	rt.queued = nil
+	rt.queuedAcc.Close(ctx)
+}
+
+// makeRow returns a copy of a row with the columns ordered like the table
+// columns, or a row of NULLs if values is nil.
+func (rt *rowTriggers) makeRow(
+	values parser.Datums, colIDtoRowIndex map[sqlbase.ColumnID]int,
+) parser.Datums {
+	row := make(parser.Datums, len(rt.tableDesc.Columns))
+	for i, col := range rt.tableDesc.Columns {
+		row[i] = parser.DNull
+		if values == nil {
+			continue
+		}
+		if idx, ok := colIDtoRowIndex[col.ID]; ok {
+			row[i] = values[idx]
+		}
+	}
+	return row
+}
+
+// rowSize returns the memory size of a row made by makeRow.
+func (rt *rowTriggers) rowSize(row parser.Datums) int64 {
+	sz := sqlbase.SizeOfDatums + sqlbase.SizeOfDatum*int64(len(row))
+	for _, d := range row {
+		sz += int64(d.Size())
+	}
+	return sz
+}
+
+// setNewValues stores the values of a new row returned by BEFORE triggers in
+// the values written by the tableWriter, and validates them like the
+// statement validated the values it computed.
+func (rt *rowTriggers) setNewValues(
+	newRow, values parser.Datums, colIDtoRowIndex map[sqlbase.ColumnID]int,
+) error {
+	for i := range rt.tableDesc.Columns {
+		col := &rt.tableDesc.Columns[i]
+		idx, ok := colIDtoRowIndex[col.ID]
+		if !ok {
+			if newRow[i] != parser.DNull {
+				return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+					"BEFORE trigger cannot set column %q, which is not written by the statement",
+					col.Name)
+			}
+			continue
+		}
+		if newRow[i] == parser.DNull && !rt.tableDesc.ColumnAcceptsNulls(col) {
+			return sqlbase.NewNonNullViolationError(col.Name)
+		}
+		if err := sqlbase.CheckValueWidth(*col, newRow[i]); err != nil {
+			return err
+		}
+		values[idx] = newRow[i]
+	}
+	if err := rt.checkHelper.loadRow(colIDtoRowIndex, values, false); err != nil {
+		return err
+	}
+	return rt.checkHelper.check(&rt.p.evalCtx)
+}
+
+// fire runs the functions of the triggers with the given timing for a row
+// modified by the given event. For BEFORE triggers, it returns the new row
+// to write, nil if the row is skipped, and whether a trigger returned a new
+// row for INSERT or UPDATE.
+func (rt *rowTriggers) fire(
+	ctx context.Context,
+	timing sqlbase.TableDescriptor_Trigger_Timing,
+	event sqlbase.TableDescriptor_Trigger_Event,
+	newRow, oldRow parser.Datums,
+) (_ parser.Datums, replaced bool, _ error) {
+	// NEW.<column> refers to the values at the start of the placeholder
+	// values, and OLD.<column> to the values following them.
+	numCols := len(rt.tableDesc.Columns)
+	bind := func(n parser.UnresolvedName) (int, bool, error) {
+		if len(n) != 2 {
+			return 0, false, nil
+		}
+		record, ok := n[0].(parser.Name)
+		if !ok {
+			return 0, false, nil
+		}
+		offset := 0
+		switch record.Normalize() {
+		case "new":
+		case "old":
+			offset = numCols
+		default:
+			return 0, false, nil
+		}
+		col, _ := n[1].(parser.Name)
+		idx, ok := rt.colIdx[col.Normalize()]
+		if !ok {
+			return 0, false, pgerror.NewErrorf(pgerror.CodeUndefinedColumnError,
+				"record %q has no field %q", record.Normalize(), col.Normalize())
+		}
+		return offset + idx, true, nil
+	}
+	values := append(append(parser.Datums(nil), newRow...), oldRow...)
+	types := append(append([]parser.Type(nil), rt.types...), rt.types...)
+
+	for i, t := range rt.triggers {
+		if t.Timing != timing || !t.HasEvent(event) {
+			continue
+		}
+		row, err := rt.p.runFunctionBody(ctx, rt.funcs[i], bind, types, values)
+		if err != nil {
+			return nil, false, err
+		}
+		if timing != sqlbase.TableDescriptor_Trigger_BEFORE {
+			continue
+		}
+		if row == nil || (len(row) == 1 && row[0] == parser.DNull) {
+			return nil, false, nil
+		}
+		if event == sqlbase.TableDescriptor_Trigger_DELETE {
+			continue
+		}
+		if err := rt.checkReturnedRow(t, row); err != nil {
+			return nil, false, err
+		}
+		// The following triggers see the row returned by this one.
+		newRow = append(parser.Datums(nil), row...)
+		copy(values, newRow)
+		replaced = true
+	}
+	return newRow, replaced, nil
+}
+
+// checkReturnedRow verifies that the row returned by a BEFORE trigger has the
+// structure of the rows of the table.
+func (rt *rowTriggers) checkReturnedRow(
+	t sqlbase.TableDescriptor_Trigger, row parser.Datums,
+) error {
+	if len(row) != len(rt.types) {
+		return pgerror.NewErrorf(pgerror.CodeDatatypeMismatchError,
+			"row returned by trigger %q does not match the structure of table %q: "+
+				"expected %d values, got %d",
+			t.Name, rt.tableDesc.Name, len(rt.types), len(row))
+	}
+	for i, d := range row {
+		if d != parser.DNull && !d.ResolvedType().Equivalent(rt.types[i]) {
+			return pgerror.NewErrorf(pgerror.CodeDatatypeMismatchError,
+				"row returned by trigger %q does not match the structure of table %q: "+
+					"expected %s for column %q, got %s",
+				t.Name, rt.tableDesc.Name, rt.types[i], rt.tableDesc.Columns[i].Name,
+				d.ResolvedType())
+		}
+	}
+	return nil
+}
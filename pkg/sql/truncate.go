@@ -47,7 +47,7 @@ func (p *planner) Truncate(ctx context.Context, n *parser.Truncate) (planNode, e
 		if err != nil {
 			return nil, err
 		}
-		if err := tn.QualifyWithDatabase(p.session.Database); err != nil {
+		if err := p.qualifyTableName(ctx, tn); err != nil {
 			return nil, err
 		}
 
@@ -62,7 +62,7 @@ func (p *planner) Truncate(ctx context.Context, n *parser.Truncate) (planNode, e
 			return nil, errors.Errorf("cannot run TRUNCATE on view %q - views are not updateable", tn)
 		}
 
-		if err := p.CheckPrivilege(tableDesc, privilege.DROP); err != nil {
+		if err := p.CheckPrivilege(ctx, tableDesc, privilege.DROP); err != nil {
 			return nil, err
 		}
 
@@ -91,7 +91,7 @@ func (p *planner) Truncate(ctx context.Context, n *parser.Truncate) (planNode, e
 				if n.DropBehavior != parser.DropCascade {
 					return nil, errors.Errorf("%q is referenced by foreign key from table %q", tableDesc.Name, other.Name)
 				}
-				if err := p.CheckPrivilege(other, privilege.DROP); err != nil {
+				if err := p.CheckPrivilege(ctx, other, privilege.DROP); err != nil {
 					return nil, err
 				}
 				toTruncate[other.ID] = struct{}{}
@@ -120,11 +120,39 @@ func (p *planner) Truncate(ctx context.Context, n *parser.Truncate) (planNode, e
 // drops the table and recreates it with a new ID. The dropped table is
 // GC-ed later through an asynchrnous schema change.
 func (p *planner) truncateTable(ctx context.Context, id sqlbase.ID, traceKV bool) error {
+	_, err := p.recreateTable(ctx, id, traceKV)
+	return err
+}
+
+// recreateTable drops a table and recreates it, without its data, with a new
+// ID. The references to the table from other descriptors and its zone config
+// are moved to the new ID. The new descriptor is returned; it is added through
+// an asynchronous schema change.
+func (p *planner) recreateTable(
+	ctx context.Context, id sqlbase.ID, traceKV bool,
+) (*sqlbase.TableDescriptor, error) {
+	newID, err := GenerateUniqueDescID(ctx, p.session.execCfg.DB)
+	if err != nil {
+		return nil, err
+	}
+	return p.replaceTable(ctx, id, newID, sqlbase.TableDescriptor_ADD, traceKV)
+}
+
+// replaceTable drops a table and recreates it with the given ID, which may
+// already contain data. The new descriptor is created in the given state. The
+// references to the table from other descriptors and its zone config are moved
+// to the new ID. The new descriptor is returned.
+func (p *planner) replaceTable(
+	ctx context.Context,
+	id, newID sqlbase.ID,
+	newState sqlbase.TableDescriptor_State,
+	traceKV bool,
+) (*sqlbase.TableDescriptor, error) {
 	// Read the table descriptor because it might have changed
 	// while another table in the truncation list was truncated.
 	tableDesc, err := sqlbase.GetTableDescFromID(ctx, p.txn, id)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	newTableDesc := *tableDesc
 	newTableDesc.SetID(0)
@@ -140,47 +168,42 @@ func (p *planner) truncateTable(ctx context.Context, id sqlbase.ID, traceKV bool
 	}
 	b.CPut(nameKey, nil, tableDesc.ID)
 	if err := p.txn.Run(ctx, b); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Drop table.
 	if err := p.initiateDropTable(ctx, tableDesc); err != nil {
-		return err
-	}
-
-	newID, err := GenerateUniqueDescID(ctx, p.session.execCfg.DB)
-	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// update all the references to this table.
 	tables, err := p.findAllReferences(ctx, *tableDesc)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if err := reassignReferencedTables(tables, tableDesc.ID, newID); err != nil {
-		return err
+		return nil, err
 	}
 
 	for _, table := range tables {
 		if err := table.SetUpVersion(); err != nil {
-			return err
+			return nil, err
 		}
 		if err := p.writeTableDesc(ctx, table); err != nil {
-			return err
+			return nil, err
 		}
 		p.notifySchemaChange(table, sqlbase.InvalidMutationID)
 	}
 
 	// Add new descriptor.
-	newTableDesc.State = sqlbase.TableDescriptor_ADD
+	newTableDesc.State = newState
 	if err := newTableDesc.SetUpVersion(); err != nil {
-		return err
+		return nil, err
 	}
 	tKey := tableKey{parentID: newTableDesc.ParentID, name: newTableDesc.Name}
 	key := tKey.Key()
 	if err := p.createDescriptorWithID(ctx, key, newID, &newTableDesc); err != nil {
-		return err
+		return nil, err
 	}
 	p.notifySchemaChange(&newTableDesc, sqlbase.InvalidMutationID)
 
@@ -189,19 +212,28 @@ func (p *planner) truncateTable(ctx context.Context, id sqlbase.ID, traceKV bool
 	b = &client.Batch{}
 	b.Get(zoneKey)
 	if err := p.txn.Run(ctx, b); err != nil {
-		return err
+		return nil, err
 	}
 	val := b.Results[0].Rows[0].Value
 	if val == nil {
-		return nil
+		return &newTableDesc, nil
 	}
 	zoneCfg, err := config.MigrateZoneConfig(val)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if len(zoneCfg.Subzones) > 0 {
+		// The subzone spans are keys of the table, which has a new ID.
+		if err := sqlbase.PruneSubzones(&newTableDesc, &zoneCfg); err != nil {
+			return nil, err
+		}
 	}
 	b = &client.Batch{}
 	b.CPut(newZoneKey, zoneCfg, nil)
-	return p.txn.Run(ctx, b)
+	if err := p.txn.Run(ctx, b); err != nil {
+		return nil, err
+	}
+	return &newTableDesc, nil
 }
 
 // For all the references from a table
@@ -325,7 +357,7 @@ func truncateTableInChunks(
 			log.VEventf(ctx, 2, "table %s truncate at row: %d, span: %s", tableDesc.Name, row, resume)
 		}
 		if err := db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
-			rd, err := sqlbase.MakeRowDeleter(txn, tableDesc, nil, nil, false, alloc)
+			rd, err := sqlbase.MakeRowDeleter(txn, tableDesc, nil, nil, false, nil, alloc)
 			if err != nil {
 				return err
 			}
@@ -52,13 +52,17 @@ func (p *planner) makeEditNode(
 	if err != nil {
 		return editNodeBase{}, err
 	}
-	// We don't support update on views, only real tables.
+	// We don't support update on views or sequences, only real tables.
+	if tableDesc.IsSequence() {
+		return editNodeBase{},
+			errors.Errorf("cannot run %s on sequence %q - use setval() instead", priv, tn)
+	}
 	if !tableDesc.IsTable() {
 		return editNodeBase{},
 			errors.Errorf("cannot run %s on view %q - views are not updateable", priv, tn)
 	}
 
-	if err := p.CheckPrivilege(tableDesc, priv); err != nil {
+	if err := p.CheckPrivilege(ctx, tableDesc, priv); err != nil {
 		return editNodeBase{}, err
 	}
 
@@ -68,12 +72,20 @@ func (p *planner) makeEditNode(
 	}, nil
 }
 
+// tableSourceInfo returns the data source info for the rows of the modified
+// table, against which RETURNING expressions are evaluated.
+func (en *editNodeBase) tableSourceInfo(tn *parser.TableName) *dataSourceInfo {
+	return newSourceInfoForSingleTable(*tn, sqlbase.ResultColumnsFromColDescs(en.tableDesc.Columns))
+}
+
 // editNodeRun holds the runtime (execute) state needed to run
 // row-modifying statements.
 type editNodeRun struct {
 	rows      planNode
 	tw        tableWriter
 	resultRow parser.Datums
+	// numRows is the number of rows written so far.
+	numRows int
 }
 
 func (r *editNodeRun) initEditNode(
@@ -81,14 +93,14 @@ func (r *editNodeRun) initEditNode(
 	en *editNodeBase,
 	rows planNode,
 	tw tableWriter,
-	tn *parser.TableName,
+	source *dataSourceInfo,
 	re parser.ReturningClause,
 	desiredTypes []parser.Type,
 ) error {
 	r.rows = rows
 	r.tw = tw
 
-	rh, err := en.p.newReturningHelper(ctx, re, desiredTypes, tn, en.tableDesc.Columns)
+	rh, err := en.p.newReturningHelper(ctx, re, desiredTypes, source)
 	if err != nil {
 		return err
 	}
@@ -97,6 +109,14 @@ func (r *editNodeRun) initEditNode(
 	return nil
 }
 
+// notifyMutation informs the table statistics Refresher that rows of the
+// table were modified, so that it can refresh stale statistics.
+func (en *editNodeBase) notifyMutation(rowsAffected int) {
+	if execCfg := en.p.ExecCfg(); execCfg != nil {
+		execCfg.TableStatsRefresher.NotifyMutation(en.tableDesc.ID, rowsAffected)
+	}
+}
+
 func (r *editNodeRun) startEditNode(params runParams, en *editNodeBase) error {
 	if sqlbase.IsSystemConfigID(en.tableDesc.GetID()) {
 		// Mark transaction as operating on the system DB.
@@ -117,11 +137,69 @@ type updateNode struct {
 	tw            tableUpdater
 	checkHelper   checkHelper
 	sourceSlots   []sourceSlot
+	// fromColIdxs holds the indices in the source rows of the columns of the
+	// FROM sources, when RETURNING can refer to them.
+	fromColIdxs []int
 
 	run struct {
 		// The following fields are populated during Start().
 		editNodeRun
+
+		// updatedKeys holds the encoded primary keys of the rows updated so
+		// far when the statement has a FROM clause; it is nil otherwise.
+		updatedKeys    map[string]struct{}
+		updatedKeysAcc WrappableMemoryAccount
+	}
+}
+
+// updateSourceName returns the name under which the columns of the table
+// targeted by an UPDATE, with the normalized name tn, can be referenced.
+func updateSourceName(table parser.TableExpr, tn *parser.TableName) parser.TableName {
+	if ate, ok := table.(*parser.AliasedTableExpr); ok && ate.As.Alias != "" {
+		return parser.TableName{TableName: ate.As.Alias}
 	}
+	return *tn
+}
+
+// renderUpdateFromColumns adds renders for the columns of the FROM sources of
+// an UPDATE to the render node producing its source rows. It returns the data
+// source against which RETURNING expressions are evaluated, in which the
+// columns of the updated table are followed by those of the FROM sources, and
+// the indices of the renders of the latter.
+func renderUpdateFromColumns(
+	render *renderNode, tableAlias parser.TableName, tableCols []sqlbase.ColumnDescriptor,
+) (*dataSourceInfo, []int, error) {
+	src := render.sourceInfo[0]
+	// The updated table is the first of the joined sources, so its columns
+	// come first.
+	tableRange, ok := src.sourceAliases.columnRange(tableAlias)
+	if !ok {
+		return nil, nil, newUnknownSourceError(&tableAlias)
+	}
+	numTableCols := len(tableRange)
+
+	info := newSourceInfoForSingleTable(tableAlias, sqlbase.ResultColumnsFromColDescs(tableCols))
+	offset := len(tableCols) - numTableCols
+	for _, alias := range src.sourceAliases {
+		var colRange columnRange
+		for _, colIdx := range alias.columnRange {
+			if colIdx >= numTableCols {
+				colRange = append(colRange, colIdx+offset)
+			}
+		}
+		if len(colRange) > 0 {
+			info.sourceAliases = append(info.sourceAliases,
+				sourceAlias{name: alias.name, columnRange: colRange})
+		}
+	}
+
+	colIdxs := make([]int, 0, len(src.sourceColumns)-numTableCols)
+	for i := numTableCols; i < len(src.sourceColumns); i++ {
+		col := src.sourceColumns[i]
+		info.sourceColumns = append(info.sourceColumns, col)
+		colIdxs = append(colIdxs, render.addOrReuseRender(col, render.ivarHelper.IndexedVar(i), true))
+	}
+	return info, colIdxs, nil
 }
 
 // sourceSlot abstracts the idea that our update sources can either be tuples
@@ -198,20 +276,32 @@ func addOrMergeExpr(
 	return render.addOrReuseRender(col, expr, true), nil
 }
 
-// Update updates columns for a selection of rows from a table.
-// Privileges: UPDATE and SELECT on table. We currently always use a select statement.
+// Update updates columns for a selection of rows from a table. With a FROM
+// clause, the rows are selected by joining the table with the FROM sources, and
+// each row is updated at most once even when it matches several rows of the
+// sources. RETURNING can then refer to the columns of the sources as well.
+// Privileges: UPDATE and SELECT on table, SELECT on the FROM sources. We
+// currently always use a select statement.
 //   Notes: postgres requires UPDATE. Requires SELECT with WHERE clause with table.
 //          mysql requires UPDATE. Also requires SELECT with WHERE clause with table.
 func (p *planner) Update(
 	ctx context.Context, n *parser.Update, desiredTypes []parser.Type,
-) (planNode, error) {
+) (result planNode, err error) {
 	if n.Where == nil && p.session.SafeUpdates {
 		return nil, pgerror.NewDangerousStatementErrorf("UPDATE without WHERE clause")
 	}
 
+	finishWith, err := p.initWith(ctx, n.With)
+	if err != nil {
+		return nil, err
+	}
+	if finishWith != nil {
+		defer func() { result, err = finishWith(result, err) }()
+	}
+
 	tracing.AnnotateTrace()
 
-	tn, err := p.getAliasedTableName(n.Table)
+	tn, err := p.getAliasedTableName(ctx, n.Table)
 	if err != nil {
 		return nil, err
 	}
@@ -247,8 +337,14 @@ func (p *planner) Update(
 		return nil, err
 	}
 
+	triggers, err := p.makeRowTriggers(ctx, en.tableDesc, parser.TriggerUpdate)
+	if err != nil {
+		return nil, err
+	}
+
 	var requestedCols []sqlbase.ColumnDescriptor
-	if _, retExprs := n.Returning.(*parser.ReturningExprs); retExprs || len(en.tableDesc.Checks) > 0 {
+	if _, retExprs := n.Returning.(*parser.ReturningExprs); retExprs ||
+		len(en.tableDesc.Checks) > 0 || triggers != nil {
 		// TODO(dan): This could be made tighter, just the rows needed for RETURNING
 		// exprs.
 		requestedCols = en.tableDesc.Columns
@@ -259,19 +355,30 @@ func (p *planner) Update(
 		return nil, err
 	}
 	ru, err := sqlbase.MakeRowUpdater(p.txn, en.tableDesc, fkTables, updateCols,
-		requestedCols, sqlbase.RowUpdaterDefault, &p.alloc)
+		requestedCols, sqlbase.RowUpdaterDefault, &p.evalCtx, &p.alloc)
 	if err != nil {
 		return nil, err
 	}
-	tw := tableUpdater{ru: ru, autoCommit: p.autoCommit}
+	// The AFTER triggers run once the rows are written, so the transaction
+	// cannot be committed along with them.
+	tw := tableUpdater{ru: ru, autoCommit: p.autoCommit && triggers == nil, triggers: triggers}
 
 	tracing.AnnotateTrace()
 
 	// We construct a query containing the columns being updated, and then later merge the values
 	// they are being updated with into that renderNode to ideally reuse some of the queries.
+	// With a FROM clause, the query joins the table with the additional sources,
+	// and the columns of the table are qualified to keep them unambiguous.
+	fetchExprs := sqlbase.ColumnsSelectors(ru.FetchCols)
+	sourceName := updateSourceName(n.Table, tn)
+	if len(n.From) > 0 {
+		for _, e := range fetchExprs {
+			e.Expr.(*parser.ColumnItem).TableName = sourceName
+		}
+	}
 	rows, err := p.SelectClause(ctx, &parser.SelectClause{
-		Exprs: sqlbase.ColumnsSelectors(ru.FetchCols),
-		From:  &parser.From{Tables: []parser.TableExpr{n.Table}},
+		Exprs: fetchExprs,
+		From:  &parser.From{Tables: append(parser.TableExprs{n.Table}, n.From...)},
 		Where: n.Where,
 	}, nil, nil, nil, publicAndNonPublicColumns)
 	if err != nil {
@@ -356,6 +463,18 @@ func (p *planner) Update(
 		}
 	}
 
+	// With a FROM clause, RETURNING can also refer to the columns of the FROM
+	// sources, which are rendered after the update expressions.
+	returningSource := en.tableSourceInfo(tn)
+	var fromColIdxs []int
+	if _, retExprs := n.Returning.(*parser.ReturningExprs); retExprs && len(n.From) > 0 {
+		returningSource, fromColIdxs, err = renderUpdateFromColumns(
+			render, sourceName, en.tableDesc.Columns)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	updateColsIdx := make(map[sqlbase.ColumnID]int, len(ru.UpdateCols))
 	for i, col := range ru.UpdateCols {
 		updateColsIdx[col.ID] = i
@@ -369,12 +488,19 @@ func (p *planner) Update(
 		updateColsIdx: updateColsIdx,
 		tw:            tw,
 		sourceSlots:   sourceSlots,
+		fromColIdxs:   fromColIdxs,
+	}
+	if len(n.From) > 0 {
+		// A row of the table can be joined with several rows of the FROM
+		// sources, in which case it is only updated using the first of them.
+		un.run.updatedKeysAcc = p.session.TxnState.OpenAccount()
+		un.run.updatedKeys = make(map[string]struct{})
 	}
 	if err := un.checkHelper.init(ctx, p, tn, en.tableDesc); err != nil {
 		return nil, err
 	}
 	if err := un.run.initEditNode(
-		ctx, &un.editNodeBase, rows, &un.tw, tn, n.Returning, desiredTypes); err != nil {
+		ctx, &un.editNodeBase, rows, &un.tw, returningSource, n.Returning, desiredTypes); err != nil {
 		return nil, err
 	}
 	return un, nil
@@ -387,83 +513,148 @@ func (u *updateNode) Start(params runParams) error {
 	return u.run.tw.init(params.p.txn)
 }
 
+func (u *updateNode) memUsage() int64 {
+	return u.run.updatedKeysAcc.CurrentlyAllocated()
+}
+
 func (u *updateNode) Close(ctx context.Context) {
 	u.run.rows.Close(ctx)
 	u.tw.close(ctx)
+	if u.run.updatedKeys != nil {
+		u.run.updatedKeysAcc.Wtxn(u.p.session).Close(ctx)
+	}
 	*u = updateNode{}
 	updateNodePool.Put(u)
 }
 
+// nextSourceRow advances the source of the updated rows, skipping the rows
+// of the table which were already updated.
+func (u *updateNode) nextSourceRow(params runParams) (bool, error) {
+	for {
+		next, err := u.run.rows.Next(params)
+		if !next || u.run.updatedKeys == nil {
+			return next, err
+		}
+		key, err := u.encodePrimaryKey(u.run.rows.Values())
+		if err != nil {
+			return false, err
+		}
+		if _, ok := u.run.updatedKeys[string(key)]; ok {
+			continue
+		}
+		acc := u.run.updatedKeysAcc.Wtxn(params.p.session)
+		if err := acc.Grow(params.ctx, int64(len(key))); err != nil {
+			return false, err
+		}
+		u.run.updatedKeys[string(key)] = struct{}{}
+		return true, nil
+	}
+}
+
+// encodePrimaryKey encodes the values of the primary key columns of a source
+// row.
+func (u *updateNode) encodePrimaryKey(row parser.Datums) ([]byte, error) {
+	var key []byte
+	for _, colID := range u.tableDesc.PrimaryIndex.ColumnIDs {
+		var err error
+		key, err = sqlbase.EncodeDatum(key, row[u.tw.ru.FetchColIDtoRowIndex[colID]])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
 func (u *updateNode) Next(params runParams) (bool, error) {
-	next, err := u.run.rows.Next(params)
-	if !next {
-		if err == nil {
-			if err := params.p.cancelChecker.Check(); err != nil {
-				return false, err
+	for {
+		next, err := u.nextSourceRow(params)
+		if !next {
+			if err == nil {
+				if err := params.p.cancelChecker.Check(); err != nil {
+					return false, err
+				}
+				// We're done. Finish the batch.
+				_, err = u.tw.finalize(params.ctx, params.p.session.Tracing.KVTracingEnabled())
+				if err == nil {
+					u.notifyMutation(u.run.numRows)
+				}
 			}
-			// We're done. Finish the batch.
-			_, err = u.tw.finalize(params.ctx, params.p.session.Tracing.KVTracingEnabled())
+			return false, err
 		}
-		return false, err
-	}
 
-	tracing.AnnotateTrace()
+		tracing.AnnotateTrace()
 
-	entireRow := u.run.rows.Values()
+		entireRow := u.run.rows.Values()
 
-	// Our updated value expressions occur immediately after the plain
-	// columns in the output.
-	oldValues := entireRow[:len(u.tw.ru.FetchCols)]
+		// Our updated value expressions occur immediately after the plain
+		// columns in the output.
+		oldValues := entireRow[:len(u.tw.ru.FetchCols)]
 
-	updateValues := make(parser.Datums, len(u.tw.ru.UpdateCols))
-	valueIdx := 0
+		updateValues := make(parser.Datums, len(u.tw.ru.UpdateCols))
+		valueIdx := 0
 
-	for _, slot := range u.sourceSlots {
-		for _, value := range slot.extractValues(entireRow) {
-			updateValues[valueIdx] = value
-			valueIdx++
+		for _, slot := range u.sourceSlots {
+			for _, value := range slot.extractValues(entireRow) {
+				updateValues[valueIdx] = value
+				valueIdx++
+			}
 		}
-	}
 
-	if err := u.checkHelper.loadRow(u.tw.ru.FetchColIDtoRowIndex, oldValues, false); err != nil {
-		return false, err
-	}
-	if err := u.checkHelper.loadRow(u.updateColsIdx, updateValues, true); err != nil {
-		return false, err
-	}
-	if err := u.checkHelper.check(&params.p.evalCtx); err != nil {
-		return false, err
-	}
-
-	// Ensure that the values honor the specified column widths.
-	for i := range updateValues {
-		if err := sqlbase.CheckValueWidth(u.tw.ru.UpdateCols[i], updateValues[i]); err != nil {
+		if err := u.checkHelper.loadRow(u.tw.ru.FetchColIDtoRowIndex, oldValues, false); err != nil {
+			return false, err
+		}
+		if err := u.checkHelper.loadRow(u.updateColsIdx, updateValues, true); err != nil {
+			return false, err
+		}
+		if err := u.checkHelper.check(&params.p.evalCtx); err != nil {
 			return false, err
 		}
-	}
 
-	for i, col := range u.tw.ru.UpdateCols {
-		val := updateValues[i]
-		if !col.Nullable && val == parser.DNull {
-			return false, sqlbase.NewNonNullViolationError(col.Name)
+		// Ensure that the values honor the specified column widths.
+		for i := range updateValues {
+			if err := sqlbase.CheckValueWidth(u.tw.ru.UpdateCols[i], updateValues[i]); err != nil {
+				return false, err
+			}
 		}
-	}
 
-	// Update the row values.
-	newValues, err := u.tw.row(
-		params.ctx, append(oldValues, updateValues...), params.p.session.Tracing.KVTracingEnabled(),
-	)
-	if err != nil {
-		return false, err
-	}
+		for i, col := range u.tw.ru.UpdateCols {
+			val := updateValues[i]
+			if val == parser.DNull && !u.tableDesc.ColumnAcceptsNulls(&col) {
+				return false, sqlbase.NewNonNullViolationError(col.Name)
+			}
+		}
 
-	resultRow, err := u.rh.cookResultRow(newValues)
-	if err != nil {
-		return false, err
-	}
-	u.run.resultRow = resultRow
+		// Update the row values.
+		newValues, err := u.tw.row(
+			params.ctx, append(oldValues, updateValues...), params.p.session.Tracing.KVTracingEnabled(),
+		)
+		if err != nil {
+			return false, err
+		}
+		if u.tw.triggers.rowSkipped() {
+			// A BEFORE trigger skipped the row.
+			continue
+		}
+		u.run.numRows++
+
+		returningRow := newValues
+		if u.fromColIdxs != nil {
+			// RETURNING sees the updated row followed by the row of the FROM
+			// sources it was joined with.
+			returningRow = make(parser.Datums, len(newValues), len(newValues)+len(u.fromColIdxs))
+			copy(returningRow, newValues)
+			for _, idx := range u.fromColIdxs {
+				returningRow = append(returningRow, entireRow[idx])
+			}
+		}
+		resultRow, err := u.rh.cookResultRow(returningRow)
+		if err != nil {
+			return false, err
+		}
+		u.run.resultRow = resultRow
 
-	return true, nil
+		return true, nil
+	}
 }
 
 // namesForExprs expands names in the tuples and subqueries in exprs.
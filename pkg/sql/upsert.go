@@ -215,6 +215,10 @@ func upsertExprsAndIndex(
 		}
 		updateExprs := make(parser.UpdateExprs, 0, len(insertCols))
 		for _, c := range insertCols {
+			if tableDesc.IsIndexExprColumn(c.ID) {
+				// Recomputed from the updated columns.
+				continue
+			}
 			if _, ok := indexColSet[c.ID]; !ok {
 				names := parser.UnresolvedNames{
 					parser.UnresolvedName{parser.Name(c.Name)},
@@ -205,6 +205,13 @@ func (n *valuesNode) Close(ctx context.Context) {
 	}
 }
 
+func (n *valuesNode) memUsage() int64 {
+	if n.rows == nil {
+		return 0
+	}
+	return n.rows.MemUsage()
+}
+
 func (n *valuesNode) Len() int {
 	return n.rows.Len() - n.rowsPopped
 }
@@ -83,6 +83,12 @@ func (v *planVisitor) visit(plan planNode) {
 		return
 	}
 
+	if a, ok := plan.(*analyzeNode); ok {
+		// The analyzeNodes of EXPLAIN ANALYZE are transparent.
+		v.visit(a.plan)
+		return
+	}
+
 	name := nodeName(plan)
 	recurse := true
 	if v.observer.enterNode != nil {
@@ -187,7 +193,15 @@ func (v *planVisitor) visit(plan planNode) {
 			case joinTypeFullOuter:
 				jType = "full outer"
 			}
+			if n.lateral != nil {
+				jType += " lateral"
+			}
 			v.observer.attr(name, "type", jType)
+			if n.algorithm == lookupJoin {
+				// Hash and merge joins are not distinguished here, as the local
+				// execution engine always runs a hash join.
+				v.observer.attr(name, "algorithm", "lookup")
+			}
 
 			if len(n.pred.leftColNames) > 0 {
 				var buf bytes.Buffer
@@ -214,7 +228,12 @@ func (v *planVisitor) visit(plan planNode) {
 		subplans := v.expr(name, "pred", -1, n.pred.onCond, nil)
 		v.subqueries(name, subplans)
 		v.visit(n.left.plan)
-		v.visit(n.right.plan)
+		if n.lateral == nil || v.observer.subqueryNode == nil {
+			// The right side of a lateral join is planned anew for every
+			// row of the left side, along with its sub-queries; those of
+			// the plan that describes it are never run.
+			v.visit(n.right.plan)
+		}
 
 	case *limitNode:
 		subplans := v.expr(name, "count", -1, n.countExpr, nil)
@@ -269,6 +288,23 @@ func (v *planVisitor) visit(plan planNode) {
 		if v.observer.attr != nil && n.numGroupCols > 0 {
 			v.observer.attr(name, "group by", fmt.Sprintf("@1-@%d", n.numGroupCols))
 		}
+		if v.observer.attr != nil && n.groupingSets != nil {
+			var buf bytes.Buffer
+			for i, s := range n.groupingSets {
+				if i > 0 {
+					buf.WriteString(", ")
+				}
+				buf.WriteByte('(')
+				for j, c := range s.Ordered() {
+					if j > 0 {
+						buf.WriteString(", ")
+					}
+					fmt.Fprintf(&buf, "@%d", c+1)
+				}
+				buf.WriteByte(')')
+			}
+			v.observer.attr(name, "grouping sets", buf.String())
+		}
 
 		v.visit(n.plan)
 
@@ -287,6 +323,16 @@ func (v *planVisitor) visit(plan planNode) {
 		v.visit(n.left)
 		v.visit(n.right)
 
+	case *recursiveCTENode:
+		if v.observer.attr != nil {
+			v.observer.attr(name, "name", n.name)
+		}
+		v.visit(n.initial)
+		v.visit(n.recursive)
+
+	case *spoolNode:
+		v.visit(n.source)
+
 	case *splitNode:
 		v.visit(n.rows)
 
@@ -518,49 +564,62 @@ func nodeName(plan planNode) string {
 // strings are constant and not precomputed so that the type names can
 // be changed without changing the output of "EXPLAIN".
 var planNodeNames = map[reflect.Type]string{
-	reflect.TypeOf(&alterTableNode{}):        "alter table",
-	reflect.TypeOf(&cancelQueryNode{}):       "cancel query",
-	reflect.TypeOf(&controlJobNode{}):        "control job",
-	reflect.TypeOf(&copyNode{}):              "copy",
-	reflect.TypeOf(&createDatabaseNode{}):    "create database",
-	reflect.TypeOf(&createIndexNode{}):       "create index",
-	reflect.TypeOf(&createTableNode{}):       "create table",
-	reflect.TypeOf(&createUserNode{}):        "create user",
-	reflect.TypeOf(&createViewNode{}):        "create view",
-	reflect.TypeOf(&delayedNode{}):           "virtual table",
-	reflect.TypeOf(&deleteNode{}):            "delete",
-	reflect.TypeOf(&distinctNode{}):          "distinct",
-	reflect.TypeOf(&dropDatabaseNode{}):      "drop database",
-	reflect.TypeOf(&dropIndexNode{}):         "drop index",
-	reflect.TypeOf(&dropTableNode{}):         "drop table",
-	reflect.TypeOf(&dropViewNode{}):          "drop view",
-	reflect.TypeOf(&dropUserNode{}):          "drop user",
-	reflect.TypeOf(&explainDistSQLNode{}):    "explain dist_sql",
-	reflect.TypeOf(&explainPlanNode{}):       "explain plan",
-	reflect.TypeOf(&traceNode{}):             "show trace for",
-	reflect.TypeOf(&filterNode{}):            "filter",
-	reflect.TypeOf(&groupNode{}):             "group",
-	reflect.TypeOf(&unaryNode{}):             "emptyrow",
-	reflect.TypeOf(&hookFnNode{}):            "plugin",
-	reflect.TypeOf(&indexJoinNode{}):         "index-join",
-	reflect.TypeOf(&insertNode{}):            "insert",
-	reflect.TypeOf(&joinNode{}):              "join",
-	reflect.TypeOf(&limitNode{}):             "limit",
-	reflect.TypeOf(&ordinalityNode{}):        "ordinality",
-	reflect.TypeOf(&testingRelocateNode{}):   "testingRelocate",
-	reflect.TypeOf(&renderNode{}):            "render",
-	reflect.TypeOf(&scanNode{}):              "scan",
-	reflect.TypeOf(&scatterNode{}):           "scatter",
-	reflect.TypeOf(&setNode{}):               "set",
-	reflect.TypeOf(&setClusterSettingNode{}): "set cluster setting",
-	reflect.TypeOf(&showRangesNode{}):        "showRanges",
-	reflect.TypeOf(&showFingerprintsNode{}):  "showFingerprints",
-	reflect.TypeOf(&sortNode{}):              "sort",
-	reflect.TypeOf(&splitNode{}):             "split",
-	reflect.TypeOf(&unionNode{}):             "union",
-	reflect.TypeOf(&updateNode{}):            "update",
-	reflect.TypeOf(&valueGenerator{}):        "generator",
-	reflect.TypeOf(&valuesNode{}):            "values",
-	reflect.TypeOf(&windowNode{}):            "window",
-	reflect.TypeOf(&zeroNode{}):              "norows",
+	reflect.TypeOf(&alterTableNode{}):              "alter table",
+	reflect.TypeOf(&alterSequenceNode{}):           "alter sequence",
+	reflect.TypeOf(&cancelQueryNode{}):             "cancel query",
+	reflect.TypeOf(&controlJobNode{}):              "control job",
+	reflect.TypeOf(&copyNode{}):                    "copy",
+	reflect.TypeOf(&createDatabaseNode{}):          "create database",
+	reflect.TypeOf(&createFunctionNode{}):          "create function",
+	reflect.TypeOf(&createIndexNode{}):             "create index",
+	reflect.TypeOf(&createTableNode{}):             "create table",
+	reflect.TypeOf(&createTriggerNode{}):           "create trigger",
+	reflect.TypeOf(&createUserNode{}):              "create user",
+	reflect.TypeOf(&createViewNode{}):              "create view",
+	reflect.TypeOf(&createSequenceNode{}):          "create sequence",
+	reflect.TypeOf(&createStatsNode{}):             "create statistics",
+	reflect.TypeOf(&delayedNode{}):                 "virtual table",
+	reflect.TypeOf(&deleteNode{}):                  "delete",
+	reflect.TypeOf(&distinctNode{}):                "distinct",
+	reflect.TypeOf(&dropDatabaseNode{}):            "drop database",
+	reflect.TypeOf(&dropFunctionNode{}):            "drop function",
+	reflect.TypeOf(&dropIndexNode{}):               "drop index",
+	reflect.TypeOf(&dropTableNode{}):               "drop table",
+	reflect.TypeOf(&dropTriggerNode{}):             "drop trigger",
+	reflect.TypeOf(&dropViewNode{}):                "drop view",
+	reflect.TypeOf(&dropSequenceNode{}):            "drop sequence",
+	reflect.TypeOf(&dropUserNode{}):                "drop user",
+	reflect.TypeOf(&explainDistSQLNode{}):          "explain dist_sql",
+	reflect.TypeOf(&explainPlanNode{}):             "explain plan",
+	reflect.TypeOf(&traceNode{}):                   "show trace for",
+	reflect.TypeOf(&filterNode{}):                  "filter",
+	reflect.TypeOf(&grantRoleNode{}):               "grant role",
+	reflect.TypeOf(&groupNode{}):                   "group",
+	reflect.TypeOf(&unaryNode{}):                   "emptyrow",
+	reflect.TypeOf(&hookFnNode{}):                  "plugin",
+	reflect.TypeOf(&indexJoinNode{}):               "index-join",
+	reflect.TypeOf(&insertNode{}):                  "insert",
+	reflect.TypeOf(&joinNode{}):                    "join",
+	reflect.TypeOf(&limitNode{}):                   "limit",
+	reflect.TypeOf(&ordinalityNode{}):              "ordinality",
+	reflect.TypeOf(&recursiveCTENode{}):            "recursive cte",
+	reflect.TypeOf(&refreshMaterializedViewNode{}): "refresh materialized view",
+	reflect.TypeOf(&testingRelocateNode{}):         "testingRelocate",
+	reflect.TypeOf(&renderNode{}):                  "render",
+	reflect.TypeOf(&revokeRoleNode{}):              "revoke role",
+	reflect.TypeOf(&scanNode{}):                    "scan",
+	reflect.TypeOf(&scatterNode{}):                 "scatter",
+	reflect.TypeOf(&setNode{}):                     "set",
+	reflect.TypeOf(&setClusterSettingNode{}):       "set cluster setting",
+	reflect.TypeOf(&showRangesNode{}):              "showRanges",
+	reflect.TypeOf(&showFingerprintsNode{}):        "showFingerprints",
+	reflect.TypeOf(&sortNode{}):                    "sort",
+	reflect.TypeOf(&spoolNode{}):                   "spool",
+	reflect.TypeOf(&splitNode{}):                   "split",
+	reflect.TypeOf(&unionNode{}):                   "union",
+	reflect.TypeOf(&updateNode{}):                  "update",
+	reflect.TypeOf(&valueGenerator{}):              "generator",
+	reflect.TypeOf(&valuesNode{}):                  "values",
+	reflect.TypeOf(&windowNode{}):                  "window",
+	reflect.TypeOf(&zeroNode{}):                    "norows",
 }
@@ -24,6 +24,7 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 )
@@ -31,8 +32,8 @@ import (
 // window constructs a windowNode according to window function applications. This may
 // adjust the render targets in the renderNode as necessary. The use of window functions
 // will run with a space complexity of O(NW) (N = number of rows, W = number of windows)
-// and a time complexity of O(NW) (no ordering), O(W*NlogN) (with ordering), and
-// O(W*N^2) (with constant or variable sized window-frames, which are not yet supported).
+// and a time complexity of O(NW) (no ordering), O(W*NlogN) (with ordering, or with
+// window frames whose start moves from one row to the next).
 //
 // This code uses the following terminology throughout:
 // - window:
@@ -208,6 +209,13 @@ func (n *windowNode) constructWindowDefinitions(
 			}
 		}
 
+		// Validate the frame clause.
+		if windowDef.Frame != nil {
+			if err := windowFn.analyzeFrame(ctx, s, windowDef.Frame); err != nil {
+				return err
+			}
+		}
+
 		windowFn.windowDef = windowDef
 	}
 	return nil
@@ -259,9 +267,110 @@ func constructWindowDef(
 		}
 		def.OrderBy = referencedSpec.OrderBy
 	}
+
+	// referencedSpec.Frame is never used.
+	if referencedSpec.Frame != nil {
+		return def, errors.Errorf("cannot copy window %q because it has a frame clause", refName)
+	}
 	return def, nil
 }
 
+// rangeOffsetType returns the type of the offsets that can be used in the
+// RANGE frame clause of a window definition ordered by a column of the given
+// type.
+func rangeOffsetType(colTyp parser.Type) (parser.Type, bool) {
+	switch colTyp {
+	case parser.TypeInt, parser.TypeFloat, parser.TypeDecimal, parser.TypeInterval:
+		return colTyp, true
+	case parser.TypeDate:
+		return parser.TypeInt, true
+	case parser.TypeTimestamp, parser.TypeTimestampTZ:
+		return parser.TypeInterval, true
+	}
+	return nil, false
+}
+
+// analyzeFrame type checks the offsets of the bounds of a window frame
+// clause.
+func (w *windowFuncHolder) analyzeFrame(
+	ctx context.Context, s *renderNode, frame *parser.WindowFrame,
+) error {
+	w.frame = frame
+	bounds := []struct {
+		bound *parser.WindowFrameBound
+		dst   *parser.TypedExpr
+	}{
+		{frame.Bounds.StartBound, &w.frameStartOffset},
+		{frame.Bounds.EndBound, &w.frameEndOffset},
+	}
+	for _, b := range bounds {
+		if b.bound == nil || b.bound.OffsetExpr == nil {
+			continue
+		}
+		offsetType := parser.TypeInt
+		if frame.Mode == parser.RangeMode {
+			if len(w.columnOrdering) != 1 {
+				return pgerror.NewErrorf(pgerror.CodeWindowingError,
+					"RANGE with offset PRECEDING/FOLLOWING requires exactly one ORDER BY column")
+			}
+			colTyp := s.columns[w.columnOrdering[0].ColIdx].Typ
+			var ok bool
+			if offsetType, ok = rangeOffsetType(colTyp); !ok {
+				return pgerror.NewErrorf(pgerror.CodeWindowingError,
+					"RANGE with offset PRECEDING/FOLLOWING is not supported for column type %s", colTyp)
+			}
+		}
+		typingContext := frame.Mode.String()
+		if err := s.planner.parser.AssertNoAggregationOrWindowing(
+			b.bound.OffsetExpr, typingContext, s.planner.session.SearchPath,
+		); err != nil {
+			return err
+		}
+		typedOffset, err := s.planner.analyzeExpr(ctx, b.bound.OffsetExpr, nil,
+			parser.IndexedVarHelper{}, offsetType, true, typingContext)
+		if err != nil {
+			return err
+		}
+		*b.dst = typedOffset
+	}
+	return nil
+}
+
+// evalFrameOffset evaluates the offset of a bound of the window frame.
+func (w *windowFuncHolder) evalFrameOffset(
+	evalCtx *parser.EvalContext, name string, offsetExpr parser.TypedExpr,
+) (parser.Datum, error) {
+	if offsetExpr == nil {
+		return nil, nil
+	}
+	offset, err := offsetExpr.Eval(evalCtx)
+	if err != nil {
+		return nil, err
+	}
+	if offset == parser.DNull {
+		return nil, pgerror.NewErrorf(pgerror.CodeNullValueNotAllowedError,
+			"frame %s offset must not be null", name)
+	}
+	negative := false
+	switch t := offset.(type) {
+	case *parser.DInt:
+		negative = *t < 0
+	case *parser.DFloat:
+		negative = *t < 0
+	case *parser.DDecimal:
+		negative = t.Negative
+	case *parser.DInterval:
+		negative = t.Compare(evalCtx, zeroInterval) < 0
+	}
+	if negative {
+		return nil, pgerror.NewErrorf(pgerror.CodeInvalidPrecedingOrFollowingSizeError,
+			"frame %s offset must not be negative", name)
+	}
+	return offset, nil
+}
+
+var zeroInterval = &parser.DInterval{}
+
 // Once the extractWindowFunctions has been run over each render, the remaining
 // render expressions will either be nil or contain an expression. If one is nil,
 // that means the render will not be touched by windowNode, and will be passed on
@@ -552,6 +661,7 @@ type peerGroupChecker interface {
 //     for each partition
 //       sort partition
 //       evaluate window frame over partition per cell, keeping track of peer groups
+//         and of the bounds of the window frame of each row
 func (n *windowNode) computeWindows(ctx context.Context) error {
 	rowCount := n.wrappedRenderVals.Len()
 	if rowCount == 0 {
@@ -573,9 +683,19 @@ func (n *windowNode) computeWindows(ctx context.Context) error {
 		n.windowValues[i] = windowAlloc[i*windowCount : (i+1)*windowCount]
 	}
 
+	evalCtx := &n.planner.evalCtx
 	var scratchBytes []byte
 	var scratchDatum []parser.Datum
 	for windowIdx, windowFn := range n.funcs {
+		startOffset, err := windowFn.evalFrameOffset(evalCtx, "starting", windowFn.frameStartOffset)
+		if err != nil {
+			return err
+		}
+		endOffset, err := windowFn.evalFrameOffset(evalCtx, "ending", windowFn.frameEndOffset)
+		if err != nil {
+			return err
+		}
+
 		partitions := make(map[string][]parser.IndexedRow)
 
 		if len(windowFn.partitionIdxs) == 0 {
@@ -644,18 +764,11 @@ func (n *windowNode) computeWindows(ctx context.Context) error {
 		//   * Segment Tree
 		// See Leis et al. [http://www.vldb.org/pvldb/vol8/p1058-leis.pdf]
 		for _, partition := range partitions {
-			// TODO(nvanbenschoten): Handle framing here. Right now we only handle the default
-			// framing option of RANGE UNBOUNDED PRECEDING. With ORDER BY, this sets the frame
-			// to be all rows from the partition start up through the current row's last ORDER BY
-			// peer. Without ORDER BY, all rows of the partition are included in the window frame,
-			// since all rows become peers of the current row. Once we add better framing support,
-			// we should flesh this logic out more.
 			builtin := windowFn.expr.GetWindowConstructor()(&n.planner.evalCtx)
 			defer builtin.Close(ctx, &n.planner.evalCtx)
 
-			// Since we only support two types of window frames (see TODO above), we only
-			// need two possible types of peerGroupChecker's to help determine peer groups
-			// for given tuples.
+			// Peer groups are determined by the ORDER BY clause: without it, all
+			// the rows of the partition are peers of each other.
 			var peerGrouper peerGroupChecker
 			if windowFn.columnOrdering != nil {
 				// If an ORDER BY clause is provided, order the partition and use the
@@ -680,7 +793,7 @@ func (n *windowNode) computeWindows(ctx context.Context) error {
 			}
 
 			// Iterate over peer groups within partition using a window frame.
-			frame := parser.WindowFrame{
+			frame := parser.WindowFrameRun{
 				Rows:        partition,
 				ArgIdxStart: windowFn.argIdxStart,
 				ArgCount:    windowFn.argCount,
@@ -699,6 +812,12 @@ func (n *windowNode) computeWindows(ctx context.Context) error {
 
 				// Perform calculations on each row in the current peer group.
 				for ; frame.RowIdx < frame.FirstPeerIdx+frame.PeerRowCount; frame.RowIdx++ {
+					frame.FrameStartIdx, frame.FrameEndIdx, err = n.frameBounds(
+						windowFn, frame, startOffset, endOffset)
+					if err != nil {
+						return err
+					}
+
 					res, err := builtin.Compute(ctx, &n.planner.evalCtx, frame)
 					if err != nil {
 						return err
@@ -720,6 +839,152 @@ func (n *windowNode) computeWindows(ctx context.Context) error {
 	return nil
 }
 
+// frameBounds returns the bounds of the window frame of the current row of a
+// sorted partition, as the half-open interval of the indexes of its rows in the
+// partition. startOffset and endOffset are the evaluated offsets of the bounds
+// of the frame clause of the window function, if any.
+func (n *windowNode) frameBounds(
+	windowFn *windowFuncHolder, wf parser.WindowFrameRun, startOffset, endOffset parser.Datum,
+) (int, int, error) {
+	if windowFn.frame == nil {
+		// The default frame is RANGE UNBOUNDED PRECEDING. With ORDER BY, this is
+		// all rows from the partition start up through the current row's last
+		// ORDER BY peer. Without ORDER BY, all rows of the partition are included
+		// in the window frame, since all rows become peers of the current row.
+		return 0, wf.FirstPeerIdx + wf.PeerRowCount, nil
+	}
+	start, err := n.frameBound(windowFn, wf, windowFn.frame.Bounds.StartBound, startOffset, true)
+	if err != nil {
+		return 0, 0, err
+	}
+	endBound := windowFn.frame.Bounds.EndBound
+	if endBound == nil {
+		endBound = &parser.WindowFrameBound{BoundType: parser.CurrentRow}
+	}
+	end, err := n.frameBound(windowFn, wf, endBound, endOffset, false)
+	if err != nil {
+		return 0, 0, err
+	}
+	if end < start {
+		// The frame is empty, e.g. with ROWS BETWEEN 1 PRECEDING AND 2 PRECEDING.
+		end = start
+	}
+	return start, end, nil
+}
+
+// frameBound returns the index in the sorted partition of the first row of the
+// window frame of the current row if isStart is set, or of the first row past
+// the window frame otherwise.
+func (n *windowNode) frameBound(
+	windowFn *windowFuncHolder,
+	wf parser.WindowFrameRun,
+	bound *parser.WindowFrameBound,
+	offset parser.Datum,
+	isStart bool,
+) (int, error) {
+	rowCount := len(wf.Rows)
+	switch bound.BoundType {
+	case parser.UnboundedPreceding:
+		return 0, nil
+
+	case parser.UnboundedFollowing:
+		return rowCount, nil
+
+	case parser.CurrentRow:
+		if windowFn.frame.Mode == parser.RowsMode {
+			if isStart {
+				return wf.RowIdx, nil
+			}
+			return wf.RowIdx + 1, nil
+		}
+		if isStart {
+			return wf.FirstPeerIdx, nil
+		}
+		return wf.FirstPeerIdx + wf.PeerRowCount, nil
+
+	case parser.ValuePreceding, parser.ValueFollowing:
+		if windowFn.frame.Mode == parser.RowsMode {
+			// Offsets past the end of the partition all amount to the same
+			// bound, so clamp the offset to avoid overflows.
+			o := rowCount + 1
+			if i := parser.MustBeDInt(offset); i < parser.DInt(o) {
+				o = int(i)
+			}
+			idx := wf.RowIdx + o
+			if bound.BoundType == parser.ValuePreceding {
+				idx = wf.RowIdx - o
+			}
+			if !isStart {
+				idx++
+			}
+			if idx < 0 {
+				return 0, nil
+			}
+			if idx > rowCount {
+				return rowCount, nil
+			}
+			return idx, nil
+		}
+		return n.rangeFrameBound(windowFn, wf, bound, offset, isStart)
+
+	default:
+		panic(fmt.Sprintf("unexpected window frame bound type %d", bound.BoundType))
+	}
+}
+
+// rangeFrameBound returns the bound of the window frame of the current row
+// for a RANGE frame clause with an offset bound. The rows of the frame are
+// those whose value of the ORDER BY column is within the offset of the value
+// of the current row.
+func (n *windowNode) rangeFrameBound(
+	windowFn *windowFuncHolder,
+	wf parser.WindowFrameRun,
+	bound *parser.WindowFrameBound,
+	offset parser.Datum,
+	isStart bool,
+) (int, error) {
+	evalCtx := &n.planner.evalCtx
+	ordering := windowFn.columnOrdering[0]
+	orderValue := func(rowIdx int) parser.Datum {
+		return n.wrappedRenderVals.At(wf.Rows[rowIdx].Idx)[ordering.ColIdx]
+	}
+
+	cur := orderValue(wf.RowIdx)
+	if cur == parser.DNull {
+		// NULL values are not within any offset of other values, so the frame
+		// of a NULL row consists of its peers.
+		if isStart {
+			return wf.FirstPeerIdx, nil
+		}
+		return wf.FirstPeerIdx + wf.PeerRowCount, nil
+	}
+
+	// The rows preceding the current row have smaller values in ascending
+	// order and larger values in descending order.
+	op := parser.Plus
+	if (bound.BoundType == parser.ValuePreceding) == (ordering.Direction == encoding.Ascending) {
+		op = parser.Minus
+	}
+	target, err := parser.NewTypedBinaryExpr(op, cur, offset, cur.ResolvedType()).Eval(evalCtx)
+	if err != nil {
+		return 0, err
+	}
+
+	// The rows are sorted on the ORDER BY column, so we can binary search for
+	// the first row past the target value, or not before it for the start
+	// bound.
+	return sort.Search(len(wf.Rows), func(i int) bool {
+		c := orderValue(i).Compare(evalCtx, target)
+		if ordering.Direction != encoding.Ascending {
+			c = -c
+		}
+		if isStart {
+			return c >= 0
+		}
+		return c > 0
+	}), nil
+}
+
 // populateValues populates n.values with final datum values after computing
 // window result values in n.windowValues.
 func (n *windowNode) populateValues(ctx context.Context) error {
@@ -783,6 +1048,14 @@ func (n *windowNode) populateValues(ctx context.Context) error {
 	return nil
 }
 
+func (n *windowNode) memUsage() int64 {
+	usage := n.windowsAcc.CurrentlyAllocated() + n.values.memUsage()
+	if n.wrappedRenderVals != nil {
+		usage += n.wrappedRenderVals.MemUsage()
+	}
+	return usage
+}
+
 func (n *windowNode) Close(ctx context.Context) {
 	n.plan.Close(ctx)
 	if n.wrappedRenderVals != nil {
@@ -903,6 +1176,12 @@ type windowFuncHolder struct {
 	windowDef      parser.WindowDef
 	partitionIdxs  []int
 	columnOrdering sqlbase.ColumnOrdering
+
+	// frame is the frame clause of the window definition, if any. The offsets
+	// of its bounds, if any, are in frameStartOffset and frameEndOffset.
+	frame            *parser.WindowFrame
+	frameStartOffset parser.TypedExpr
+	frameEndOffset   parser.TypedExpr
 }
 
 func (*windowFuncHolder) Variable() {}
@@ -0,0 +1,426 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// This file implements the planning of common table expressions
+// (CTEs), that is, the WITH clause that can prefix SELECT, INSERT,
+// UPSERT, UPDATE and DELETE statements.
+//
+// While the statement that carries a WITH clause is being planned,
+// the CTEs it defines are registered in the planner's CTE name
+// environment. When a table name is resolved in a FROM clause, in
+// this statement or any of its sub-queries, the environment is
+// searched before the name is looked up as a virtual table, a table
+// or a view. The most recent definition of a name shadows the outer
+// definitions.
+//
+// A CTE without side effects is planned anew every time it is
+// referenced, in the same way as a view. A CTE whose statement has
+// side effects (INSERT/UPSERT/UPDATE/DELETE) must be executed exactly
+// once, so it must be referenced exactly once.
+//
+// A CTE in a WITH RECURSIVE clause whose statement has the form
+// "non-recursive-term UNION [ALL] recursive-term", where the
+// recursive term refers to the CTE itself, is executed by a
+// recursiveCTENode (see recursive_cte.go).
+
+// cteSource describes a common table expression defined by a WITH
+// clause.
+type cteSource struct {
+	// name is the name of the CTE, together with its optional column
+	// names.
+	name parser.AliasClause
+	// stmt is the statement that defines the CTE.
+	stmt parser.Statement
+
+	// scope is the name environment in which stmt must be planned.
+	// For a WITH RECURSIVE clause, this includes the CTE itself.
+	scope *cteNameEnvironment
+
+	// recursive is set when the CTE was defined by a WITH RECURSIVE
+	// clause.
+	recursive bool
+	// mutation is set when stmt has side effects.
+	mutation bool
+	// used is set when the CTE has been planned at least once.
+	used bool
+
+	// recursion indicates how references to the CTE must be resolved
+	// while its own statement is being planned.
+	recursion cteRecursionState
+	// workTableColumns is the schema of the working table of a
+	// recursive CTE, i.e. the result columns of its non-recursive term.
+	workTableColumns sqlbase.ResultColumns
+	// workTable is the valuesNode that stands for the working table
+	// in the plan of the recursive term, once the reference to the CTE
+	// has been resolved while planning that term.
+	workTable *valuesNode
+	// termSubqueryDepth is the planner's subqueryDepth when the
+	// planning of the recursive term started.
+	termSubqueryDepth int
+}
+
+// cteRecursionState describes which part of a CTE's statement, if
+// any, is currently being planned.
+type cteRecursionState int
+
+const (
+	// cteNotPlanning: the CTE's statement is not being planned.
+	cteNotPlanning cteRecursionState = iota
+	// cteInBody: a statement that does not have the form required for
+	// recursion is being planned.
+	cteInBody
+	// cteInInitialTerm: the non-recursive term of a recursive CTE is
+	// being planned.
+	cteInInitialTerm
+	// cteInRecursiveTerm: the recursive term of a recursive CTE is
+	// being planned; a reference to the CTE designates the working
+	// table.
+	cteInRecursiveTerm
+)
+
+// cteNameEnvironment is a linked list of the CTEs visible at a given
+// point during planning. The innermost definition is at the head of
+// the list.
+type cteNameEnvironment struct {
+	cte    *cteSource
+	parent *cteNameEnvironment
+}
+
+// lookup finds the innermost CTE with the given name, or returns nil
+// if there is none.
+func (e *cteNameEnvironment) lookup(name parser.Name) *cteSource {
+	for ; e != nil; e = e.parent {
+		if e.cte.name.Alias == name {
+			return e.cte
+		}
+	}
+	return nil
+}
+
+// withFinisher is returned by initWith. It must be called with the
+// result of planning the statement that carries the WITH clause.
+type withFinisher func(plan planNode, err error) (planNode, error)
+
+// initWith registers the CTEs defined by the given WITH clause in the
+// planner's name environment. If the returned function is not nil, it
+// must be called once the statement that carries the WITH clause has
+// been planned: it restores the previous name environment and reports
+// an error for the CTEs that were not referenced but cannot be
+// ignored, closing the plan in that case.
+func (p *planner) initWith(ctx context.Context, with *parser.With) (withFinisher, error) {
+	if with == nil {
+		return nil, nil
+	}
+
+	prevEnv := p.cteNameEnvironment
+	env := prevEnv
+	ctes := make([]*cteSource, len(with.CTEList))
+	for i, cte := range with.CTEList {
+		for _, prev := range ctes[:i] {
+			if prev.name.Alias == cte.Name.Alias {
+				return nil, pgerror.NewErrorf(pgerror.CodeDuplicateAliasError,
+					"WITH query name %q specified more than once", cte.Name.Alias)
+			}
+		}
+		src := &cteSource{
+			name:      cte.Name,
+			stmt:      cte.Stmt,
+			recursive: with.Recursive,
+			scope:     env,
+		}
+		switch cte.Stmt.(type) {
+		case *parser.Insert, *parser.Update, *parser.Delete:
+			src.mutation = true
+		}
+		env = &cteNameEnvironment{cte: src, parent: env}
+		if with.Recursive {
+			src.scope = env
+		}
+		ctes[i] = src
+	}
+	p.cteNameEnvironment = env
+
+	return func(plan planNode, err error) (planNode, error) {
+		defer func() { p.cteNameEnvironment = prevEnv }()
+		if err != nil {
+			return plan, err
+		}
+		if err := p.checkUnusedCTEs(ctx, ctes); err != nil {
+			plan.Close(ctx)
+			return nil, err
+		}
+		return plan, nil
+	}, nil
+}
+
+// checkUnusedCTEs reports an error for the CTEs that were not
+// referenced by the statement that defines them and either have side
+// effects or contain semantic errors.
+func (p *planner) checkUnusedCTEs(ctx context.Context, ctes []*cteSource) error {
+	for _, cte := range ctes {
+		if cte.used {
+			continue
+		}
+		if cte.mutation {
+			return pgerror.Unimplemented("cte", fmt.Sprintf(
+				"common table expression %q with side effects was not used in query",
+				cte.name.Alias))
+		}
+		ds, err := p.planCTE(ctx, cte)
+		if err != nil {
+			return err
+		}
+		ds.plan.Close(ctx)
+	}
+	return nil
+}
+
+// getCTEDataSource attempts to resolve the given table name to a CTE
+// visible in the current name environment.
+func (p *planner) getCTEDataSource(
+	ctx context.Context, t *parser.NormalizableTableName,
+) (planDataSource, bool, error) {
+	if p.cteNameEnvironment == nil {
+		return planDataSource{}, false, nil
+	}
+	tn, err := t.Normalize()
+	if err != nil {
+		return planDataSource{}, false, err
+	}
+	if !tn.DBNameOriginallyOmitted || tn.PrefixOriginallySpecified {
+		// CTE names are never qualified.
+		return planDataSource{}, false, nil
+	}
+	cte := p.cteNameEnvironment.lookup(tn.TableName)
+	if cte == nil {
+		return planDataSource{}, false, nil
+	}
+	ds, err := p.planCTE(ctx, cte)
+	return ds, true, err
+}
+
+// planCTE builds a planDataSource for a reference to the given CTE.
+func (p *planner) planCTE(ctx context.Context, cte *cteSource) (planDataSource, error) {
+	switch cte.recursion {
+	case cteInBody:
+		return planDataSource{}, pgerror.NewErrorf(pgerror.CodeInvalidRecursionError,
+			"recursive query %q does not have the form non-recursive-term UNION [ALL] recursive-term",
+			cte.name.Alias)
+	case cteInInitialTerm:
+		return planDataSource{}, pgerror.NewErrorf(pgerror.CodeInvalidRecursionError,
+			"recursive reference to query %q must not appear within its non-recursive term",
+			cte.name.Alias)
+	case cteInRecursiveTerm:
+		return p.getWorkTableSource(cte)
+	}
+
+	if cte.mutation && cte.used {
+		return planDataSource{}, pgerror.Unimplemented("cte", fmt.Sprintf(
+			"multiple references to common table expression %q with side effects are not supported",
+			cte.name.Alias))
+	}
+	cte.used = true
+
+	// The statement of the CTE is planned in the name environment where
+	// the CTE was defined, not the one where it is referenced.
+	defer func(prev *cteNameEnvironment) { p.cteNameEnvironment = prev }(p.cteNameEnvironment)
+	p.cteNameEnvironment = cte.scope
+
+	if cte.mutation {
+		// The enclosing statement may write after the CTE, so the CTE
+		// cannot commit the transaction.
+		defer func(prev bool) { p.autoCommit = prev }(p.autoCommit)
+		p.autoCommit = false
+	}
+
+	var plan planNode
+	var err error
+	if union := cte.recursiveUnion(); union != nil {
+		plan, err = p.newRecursiveCTEPlan(ctx, cte, union)
+	} else {
+		cte.recursion = cteInBody
+		plan, err = p.newPlan(ctx, cte.stmt, nil)
+		cte.recursion = cteNotPlanning
+	}
+	if err != nil {
+		return planDataSource{}, err
+	}
+
+	columns := planColumns(plan)
+	if cte.mutation {
+		if len(columns) == 0 {
+			plan.Close(ctx)
+			return planDataSource{}, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"WITH query %q does not have a RETURNING clause", cte.name.Alias)
+		}
+		// The side effects must be carried out entirely even if the
+		// enclosing statement does not consume all the rows.
+		plan = &spoolNode{source: plan}
+	}
+	ds, err := renameSource(planDataSource{
+		info: newSourceInfoForSingleTable(anonymousTable, columns),
+		plan: plan,
+	}, cte.name, false)
+	if err != nil {
+		plan.Close(ctx)
+		return planDataSource{}, err
+	}
+	return ds, nil
+}
+
+// recursiveUnion returns the UNION clause that defines a recursive
+// CTE, or nil if the CTE's statement does not have the form
+// "non-recursive-term UNION [ALL] recursive-term".
+func (cte *cteSource) recursiveUnion() *parser.UnionClause {
+	if !cte.recursive {
+		return nil
+	}
+	sel, ok := cte.stmt.(*parser.Select)
+	if !ok || sel.With != nil || sel.OrderBy != nil || sel.Limit != nil {
+		return nil
+	}
+	union, ok := sel.Select.(*parser.UnionClause)
+	if !ok || union.Type != parser.UnionOp {
+		return nil
+	}
+	return union
+}
+
+// getWorkTableSource builds the data source for the reference to a
+// recursive CTE within its own recursive term. The resulting
+// valuesNode is populated with the working table by the
+// recursiveCTENode before each iteration.
+func (p *planner) getWorkTableSource(cte *cteSource) (planDataSource, error) {
+	if p.subqueryDepth != cte.termSubqueryDepth {
+		return planDataSource{}, pgerror.NewErrorf(pgerror.CodeInvalidRecursionError,
+			"recursive reference to query %q must not appear within a subquery", cte.name.Alias)
+	}
+	if cte.workTable != nil {
+		return planDataSource{}, pgerror.NewErrorf(pgerror.CodeInvalidRecursionError,
+			"recursive reference to query %q must not appear more than once", cte.name.Alias)
+	}
+	columns := append(sqlbase.ResultColumns(nil), cte.workTableColumns...)
+	cte.workTable = &valuesNode{
+		p:       p,
+		columns: columns,
+		isConst: true,
+	}
+	return renameSource(planDataSource{
+		info: newSourceInfoForSingleTable(anonymousTable, columns),
+		plan: cte.workTable,
+	}, cte.name, false)
+}
+
+// planRecursiveTerm plans the recursive term of a recursive CTE. It
+// also returns the valuesNode that stands for the working table, or
+// nil if the term does not refer to the CTE.
+func (p *planner) planRecursiveTerm(
+	ctx context.Context, cte *cteSource, stmt *parser.Select,
+) (planNode, *valuesNode, error) {
+	cte.recursion = cteInRecursiveTerm
+	cte.workTable = nil
+	cte.termSubqueryDepth = p.subqueryDepth
+	defer func() {
+		cte.recursion = cteNotPlanning
+		cte.workTable = nil
+	}()
+
+	plan, err := p.newPlan(ctx, stmt, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return plan, cte.workTable, nil
+}
+
+// newRecursiveCTEPlan plans a recursive CTE.
+func (p *planner) newRecursiveCTEPlan(
+	ctx context.Context, cte *cteSource, union *parser.UnionClause,
+) (planNode, error) {
+	cte.recursion = cteInInitialTerm
+	initial, err := p.newPlan(ctx, union.Left, nil)
+	cte.recursion = cteNotPlanning
+	if err != nil {
+		return nil, err
+	}
+
+	columns := append(sqlbase.ResultColumns(nil), planColumns(initial)...)
+	cte.workTableColumns = columns
+	recursive, workTable, err := p.planRecursiveTerm(ctx, cte, union.Right)
+	if err != nil {
+		initial.Close(ctx)
+		return nil, err
+	}
+	if workTable == nil {
+		// The statement does not actually refer to the CTE; plan it as
+		// a regular UNION.
+		initial.Close(ctx)
+		recursive.Close(ctx)
+		return p.newPlan(ctx, cte.stmt, nil)
+	}
+
+	recursiveColumns := planColumns(recursive)
+	if len(columns) != len(recursiveColumns) {
+		initial.Close(ctx)
+		recursive.Close(ctx)
+		return nil, fmt.Errorf("each UNION query must have the same number of columns: %d vs %d",
+			len(columns), len(recursiveColumns))
+	}
+	for i := range columns {
+		l, r := columns[i].Typ, recursiveColumns[i].Typ
+		if !(l.Equivalent(r) || r == parser.TypeNull) {
+			initial.Close(ctx)
+			recursive.Close(ctx)
+			return nil, pgerror.NewErrorf(pgerror.CodeDatatypeMismatchError,
+				"recursive query %q column %d has type %s in non-recursive term but type %s overall",
+				cte.name.Alias, i+1, l, r)
+		}
+	}
+
+	scope := p.cteNameEnvironment
+	return &recursiveCTENode{
+		name:         string(cte.name.Alias),
+		columns:      columns,
+		initial:      initial,
+		recursive:    recursive,
+		workTableRef: workTable,
+		unionAll:     union.All,
+		genIteration: func(ctx context.Context) (planNode, *valuesNode, error) {
+			defer func(prev *cteNameEnvironment) { p.cteNameEnvironment = prev }(p.cteNameEnvironment)
+			p.cteNameEnvironment = scope
+
+			plan, workTable, err := p.planRecursiveTerm(ctx, cte, union.Right)
+			if err != nil {
+				return nil, nil, err
+			}
+			plan, err = p.optimizePlan(ctx, plan, allColumns(plan))
+			if err != nil {
+				plan.Close(ctx)
+				return nil, nil, err
+			}
+			return plan, workTable, nil
+		},
+	}, nil
+}
@@ -88,6 +88,28 @@ var backwardCompatibleMigrations = []migrationDescriptor{
 		name:   "persist trace.debug.enable = 'false'",
 		workFn: disableNetTrace,
 	},
+	{
+		name:           "create system.table_statistics table",
+		workFn:         createTableStatisticsTable,
+		newDescriptors: 1,
+		newRanges:      1,
+	},
+	{
+		name:   "add system.users isRole column",
+		workFn: addIsRoleColumnToUsersTable,
+	},
+	{
+		name:           "create system.role_members table",
+		workFn:         createRoleMembersTable,
+		newDescriptors: 1,
+		newRanges:      1,
+	},
+	{
+		name:           "create system.functions table",
+		workFn:         createFunctionsTable,
+		newDescriptors: 1,
+		newRanges:      1,
+	},
 }
 
 // migrationDescriptor describes a single migration hook that's used to modify
@@ -365,6 +387,24 @@ func createWebSessionsTable(ctx context.Context, r runner) error {
 	return createSystemTable(ctx, r, sqlbase.WebSessionsTable)
 }
 
+func createTableStatisticsTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, sqlbase.TableStatisticsTable)
+}
+
+func createRoleMembersTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, sqlbase.RoleMembersTable)
+}
+
+func createFunctionsTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, sqlbase.FunctionsTable)
+}
+
+func addIsRoleColumnToUsersTable(ctx context.Context, r runner) error {
+	// Fresh clusters are bootstrapped with the column already present.
+	return runStmtAsRootWithRetry(ctx, r,
+		`ALTER TABLE system.users ADD COLUMN IF NOT EXISTS "isRole" BOOL NOT NULL DEFAULT false`)
+}
+
 func createSystemTable(ctx context.Context, r runner, desc sqlbase.TableDescriptor) error {
 	// We install the table at the KV layer so that we can choose a known ID in
 	// the reserved ID space. (The SQL layer doesn't allow this.)
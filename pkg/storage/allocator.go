@@ -46,6 +46,11 @@ const (
 	// store.
 	baseLeaseRebalanceThreshold = 0.05
 
+	// minQPSDifferenceForLeaseTransfer is the minimum number of queries per
+	// second by which a store must exceed the mean before leases are moved
+	// away from it to balance load.
+	minQPSDifferenceForLeaseTransfer = 100
+
 	// minReplicaWeight sets a floor for how low a replica weight can be. This is
 	// needed because a weight of zero doesn't work in the current lease scoring
 	// algorithm.
@@ -278,7 +283,7 @@ func (a *Allocator) ComputeAction(
 			// we'll up-replicate to, just an indication that such a target exists.
 			if _, _, err := a.AllocateTarget(
 				ctx,
-				zone.Constraints,
+				zone,
 				liveReplicas,
 				rangeInfo,
 				true, /* relaxConstraints */
@@ -331,16 +336,18 @@ type decisionDetails struct {
 // passed in to ensure that we don't try to replace an existing dead replica on
 // a store. If relaxConstraints is true, then the required attributes will be
 // relaxed as necessary, from least specific to most specific, in order to
-// allocate a target.
+// allocate a target. If one of the replica constraints of the zone has fewer
+// existing replicas than it asks for, the target satisfies its constraints.
 func (a *Allocator) AllocateTarget(
 	ctx context.Context,
-	constraints config.Constraints,
+	zone config.ZoneConfig,
 	existing []roachpb.ReplicaDescriptor,
 	rangeInfo RangeInfo,
 	relaxConstraints bool,
 ) (*roachpb.StoreDescriptor, string, error) {
 	sl, _, throttledStoreCount := a.storePool.getStoreList(rangeInfo.Desc.RangeID, storeFilterThrottled)
 
+	constraints := analyzeConstraints(zone, a.storeDescriptors(existing))
 	candidates := allocateCandidates(
 		a.storePool.st,
 		sl,
@@ -370,18 +377,33 @@ func (a *Allocator) AllocateTarget(
 		return nil, "", errors.Errorf("%d matching stores are currently throttled", throttledStoreCount)
 	}
 	return nil, "", &allocatorError{
-		required: constraints.Constraints,
+		required: constraints.newReplicaConstraints().Constraints,
 	}
 }
 
+// storeDescriptors returns the descriptors of the stores of the given
+// replicas, omitting the stores which are not known to the store pool.
+func (a *Allocator) storeDescriptors(
+	replicas []roachpb.ReplicaDescriptor,
+) []roachpb.StoreDescriptor {
+	descs := make([]roachpb.StoreDescriptor, 0, len(replicas))
+	for _, repl := range replicas {
+		if desc, ok := a.storePool.getStoreDescriptor(repl.StoreID); ok {
+			descs = append(descs, desc)
+		}
+	}
+	return descs
+}
+
 // RemoveTarget returns a suitable replica to remove from the provided replica
 // set. It first attempts to randomly select a target from the set of stores
 // that have greater than the average number of replicas. Failing that, it
 // falls back to selecting a random target from any of the existing
-// replicas.
+// replicas. Replicas needed to satisfy the replica constraints of the zone
+// are only removed if no other replica can be.
 func (a Allocator) RemoveTarget(
 	ctx context.Context,
-	constraints config.Constraints,
+	zone config.ZoneConfig,
 	candidates []roachpb.ReplicaDescriptor,
 	rangeInfo RangeInfo,
 ) (roachpb.ReplicaDescriptor, string, error) {
@@ -399,7 +421,7 @@ func (a Allocator) RemoveTarget(
 	rankedCandidates := removeCandidates(
 		a.storePool.st,
 		sl,
-		constraints,
+		analyzeConstraints(zone, a.storeDescriptors(rangeInfo.Desc.Replicas)),
 		rangeInfo,
 		a.storePool.getLocalities(rangeInfo.Desc.Replicas),
 		a.storePool.deterministic,
@@ -431,8 +453,10 @@ func (a Allocator) RemoveTarget(
 // criteria. Namely, if chosen, it must further the goal of balancing the
 // cluster.
 //
-// The supplied parameters are the required attributes for the range and
-// information about the range being considered for rebalancing.
+// The supplied parameters are the zone config of the range and information
+// about the range being considered for rebalancing. A replica which isn't
+// needed to satisfy the replica constraints of the zone is rebalanced if one
+// of them has fewer replicas than it asks for.
 //
 // The existing replicas modulo any store with dead replicas are candidates for
 // rebalancing. Note that rebalancing is accomplished by first adding a new
@@ -444,7 +468,7 @@ func (a Allocator) RemoveTarget(
 // rebalance. This helps prevent a stampeding herd targeting an abnormally
 // under-utilized store.
 func (a Allocator) RebalanceTarget(
-	ctx context.Context, constraints config.Constraints, rangeInfo RangeInfo, filter storeFilter,
+	ctx context.Context, zone config.ZoneConfig, rangeInfo RangeInfo, filter storeFilter,
 ) (*roachpb.StoreDescriptor, string) {
 	sl, _, _ := a.storePool.getStoreList(rangeInfo.Desc.RangeID, filter)
 
@@ -452,7 +476,7 @@ func (a Allocator) RebalanceTarget(
 		ctx,
 		a.storePool.st,
 		sl,
-		constraints,
+		analyzeConstraints(zone, a.storeDescriptors(rangeInfo.Desc.Replicas)),
 		rangeInfo.Desc.Replicas,
 		rangeInfo,
 		a.storePool.getLocalities(rangeInfo.Desc.Replicas),
@@ -506,9 +530,13 @@ func (a Allocator) RebalanceTarget(
 // TransferLeaseTarget returns a suitable replica to transfer the range lease
 // to from the provided list. It excludes the current lease holder replica
 // unless asked to do otherwise by the checkTransferLeaseSource parameter.
+//
+// If the zone has lease preferences, only the replicas matching the first
+// preference which any of them matches are considered, and the lease is
+// moved to one of them if the lease holder doesn't match it.
 func (a *Allocator) TransferLeaseTarget(
 	ctx context.Context,
-	constraints config.Constraints,
+	zone config.ZoneConfig,
 	existing []roachpb.ReplicaDescriptor,
 	leaseStoreID roachpb.StoreID,
 	rangeID roachpb.RangeID,
@@ -518,7 +546,7 @@ func (a *Allocator) TransferLeaseTarget(
 	alwaysAllowDecisionWithoutStats bool,
 ) roachpb.ReplicaDescriptor {
 	sl, _, _ := a.storePool.getStoreList(rangeID, storeFilterNone)
-	sl = sl.filter(constraints)
+	sl = sl.filter(zone.Constraints)
 
 	// Filter stores that are on nodes containing existing replicas, but leave
 	// the stores containing the existing replicas in place. This excludes stores
@@ -548,12 +576,26 @@ func (a *Allocator) TransferLeaseTarget(
 		return roachpb.ReplicaDescriptor{}
 	}
 
+	if preferred := a.preferredLeaseholders(zone, sl, existing); len(preferred) > 0 {
+		if !replicasContainStore(preferred, leaseStoreID) {
+			repl := a.leastLeasesReplica(preferred)
+			log.VEventf(ctx, 3, "transferring lease to s%d to satisfy lease preferences", repl.StoreID)
+			return repl
+		}
+		// Unless the lease must move, keep it on the preferred replicas even
+		// if the lease holder is the only one of them.
+		if checkTransferLeaseSource || len(preferred) > 1 {
+			existing = preferred
+		}
+	}
+
 	// Try to pick a replica to transfer the lease to while also determining
 	// whether we actually should be transferring the lease. The transfer
 	// decision is only needed if we've been asked to check the source.
 	transferDec, repl := a.shouldTransferLeaseUsingStats(
 		ctx, sl, source, existing, stats,
 	)
+	rangeQPS := rangeQueriesPerSecond(stats)
 	if checkTransferLeaseSource {
 		switch transferDec {
 		case shouldNotTransfer:
@@ -562,7 +604,7 @@ func (a *Allocator) TransferLeaseTarget(
 			}
 			fallthrough
 		case decideWithoutStats:
-			if !a.shouldTransferLeaseWithoutStats(ctx, sl, source, existing) {
+			if !a.shouldTransferLeaseWithoutStats(ctx, sl, source, existing, rangeQPS) {
 				return roachpb.ReplicaDescriptor{}
 			}
 		case shouldTransfer:
@@ -575,6 +617,12 @@ func (a *Allocator) TransferLeaseTarget(
 		return repl
 	}
 
+	// Prefer moving the lease of a busy range off a store serving many more
+	// queries than the others.
+	if repl, ok := a.transferLeaseTargetForLoad(sl, source, existing, rangeQPS); ok {
+		return repl
+	}
+
 	// Fall back to logic that doesn't take request counts and latency into
 	// account if the counts/latency-based logic couldn't pick a best replica.
 	candidates := make([]roachpb.ReplicaDescriptor, 0, len(existing))
@@ -600,10 +648,11 @@ func (a *Allocator) TransferLeaseTarget(
 
 // ShouldTransferLease returns true if the specified store is overfull in terms
 // of leases with respect to the other stores matching the specified
-// attributes.
+// attributes, or if it doesn't match the lease preferences of the zone while
+// another replica does.
 func (a *Allocator) ShouldTransferLease(
 	ctx context.Context,
-	constraints config.Constraints,
+	zone config.ZoneConfig,
 	existing []roachpb.ReplicaDescriptor,
 	leaseStoreID roachpb.StoreID,
 	rangeID roachpb.RangeID,
@@ -614,7 +663,15 @@ func (a *Allocator) ShouldTransferLease(
 		return false
 	}
 	sl, _, _ := a.storePool.getStoreList(rangeID, storeFilterNone)
-	sl = sl.filter(constraints)
+	sl = sl.filter(zone.Constraints)
+	if preferred := a.preferredLeaseholders(zone, sl, existing); len(preferred) > 0 {
+		if !replicasContainStore(preferred, leaseStoreID) {
+			log.VEventf(ctx, 3, "ShouldTransferLease (lease-holder=%d): lease preferences not satisfied",
+				leaseStoreID)
+			return true
+		}
+		existing = preferred
+	}
 	log.VEventf(ctx, 3, "ShouldTransferLease (lease-holder=%d):\n%s", leaseStoreID, sl)
 
 	transferDec, _ := a.shouldTransferLeaseUsingStats(ctx, sl, source, existing, stats)
@@ -625,7 +682,7 @@ func (a *Allocator) ShouldTransferLease(
 	case shouldTransfer:
 		result = true
 	case decideWithoutStats:
-		result = a.shouldTransferLeaseWithoutStats(ctx, sl, source, existing)
+		result = a.shouldTransferLeaseWithoutStats(ctx, sl, source, existing, rangeQueriesPerSecond(stats))
 	default:
 		log.Fatalf(ctx, "unexpected transfer decision %d", transferDec)
 	}
@@ -634,6 +691,58 @@ func (a *Allocator) ShouldTransferLease(
 	return result
 }
 
+// preferredLeaseholders returns the replicas whose stores match the first
+// lease preference of the zone which any of the replicas matches. Only
+// replicas on the stores in sl, which can receive the lease, are considered.
+// It returns nil if the zone has no lease preferences or no replica matches
+// any of them.
+func (a *Allocator) preferredLeaseholders(
+	zone config.ZoneConfig, sl StoreList, existing []roachpb.ReplicaDescriptor,
+) []roachpb.ReplicaDescriptor {
+	for _, preference := range zone.LeasePreferences {
+		var preferred []roachpb.ReplicaDescriptor
+		for _, repl := range existing {
+			for _, storeDesc := range sl.stores {
+				if storeDesc.StoreID == repl.StoreID && storeMatchesConstraints(storeDesc, preference) {
+					preferred = append(preferred, repl)
+					break
+				}
+			}
+		}
+		if len(preferred) > 0 {
+			return preferred
+		}
+	}
+	return nil
+}
+
+// leastLeasesReplica returns the replica on the store holding the fewest
+// leases. Replicas on stores unknown to the store pool are only returned if
+// there are no others.
+func (a *Allocator) leastLeasesReplica(
+	replicas []roachpb.ReplicaDescriptor,
+) roachpb.ReplicaDescriptor {
+	best := replicas[0]
+	bestLeases := int32(math.MaxInt32)
+	for _, repl := range replicas {
+		storeDesc, ok := a.storePool.getStoreDescriptor(repl.StoreID)
+		if ok && storeDesc.Capacity.LeaseCount < bestLeases {
+			best, bestLeases = repl, storeDesc.Capacity.LeaseCount
+		}
+	}
+	return best
+}
+
+// replicasContainStore returns true if one of the replicas is on the store.
+func replicasContainStore(replicas []roachpb.ReplicaDescriptor, storeID roachpb.StoreID) bool {
+	for _, repl := range replicas {
+		if repl.StoreID == storeID {
+			return true
+		}
+	}
+	return false
+}
+
 func (a Allocator) shouldTransferLeaseUsingStats(
 	ctx context.Context,
 	sl StoreList,
@@ -806,7 +915,12 @@ func (a Allocator) shouldTransferLeaseWithoutStats(
 	sl StoreList,
 	source roachpb.StoreDescriptor,
 	existing []roachpb.ReplicaDescriptor,
+	rangeQPS float64,
 ) bool {
+	if _, ok := a.transferLeaseTargetForLoad(sl, source, existing, rangeQPS); ok {
+		return true
+	}
+
 	// Allow lease transfer if we're above the overfull threshold, which is
 	// mean*(1+baseLeaseRebalanceThreshold).
 	overfullLeaseThreshold := int32(math.Ceil(sl.candidateLeases.mean * (1 + baseLeaseRebalanceThreshold)))
@@ -838,6 +952,62 @@ func (a Allocator) shouldTransferLeaseWithoutStats(
 	return false
 }
 
+// transferLeaseTargetForLoad returns the replica on the store serving the
+// fewest queries per second if the source store serves substantially more
+// queries per second than the mean of the candidate stores. Ranges split
+// because of their load start out with their leases on the same store; this
+// spreads them out. A lease is only moved if that does not leave the target
+// busier than the source.
+func (a Allocator) transferLeaseTargetForLoad(
+	sl StoreList,
+	source roachpb.StoreDescriptor,
+	existing []roachpb.ReplicaDescriptor,
+	rangeQPS float64,
+) (roachpb.ReplicaDescriptor, bool) {
+	if rangeQPS <= 0 {
+		return roachpb.ReplicaDescriptor{}, false
+	}
+	mean := sl.candidateQueriesPerSecond.mean
+	overfullThreshold := math.Max(
+		mean*(1+baseLeaseRebalanceThreshold), mean+minQPSDifferenceForLeaseTransfer)
+	if source.Capacity.QueriesPerSecond <= overfullThreshold {
+		return roachpb.ReplicaDescriptor{}, false
+	}
+
+	var target roachpb.ReplicaDescriptor
+	targetQPS := math.MaxFloat64
+	for _, repl := range existing {
+		if repl.StoreID == source.StoreID {
+			continue
+		}
+		storeDesc, ok := a.storePool.getStoreDescriptor(repl.StoreID)
+		if !ok {
+			continue
+		}
+		qps := storeDesc.Capacity.QueriesPerSecond
+		if qps >= mean || qps+rangeQPS >= source.Capacity.QueriesPerSecond-rangeQPS {
+			continue
+		}
+		if qps < targetQPS {
+			target, targetQPS = repl, qps
+		}
+	}
+	return target, target != (roachpb.ReplicaDescriptor{})
+}
+
+// rangeQueriesPerSecond returns the average number of queries per second
+// recorded by the supplied leaseholder stats, or 0 if there are none.
+func rangeQueriesPerSecond(stats *replicaStats) float64 {
+	if stats == nil {
+		return 0
+	}
+	qps, dur := stats.avgQPS()
+	if dur < MinStatsDuration {
+		return 0
+	}
+	return qps
+}
+
 // computeQuorum computes the quorum value for the given number of nodes.
 func computeQuorum(nodes int) int {
 	return (nodes / 2) + 1
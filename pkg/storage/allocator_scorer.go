@@ -98,8 +98,11 @@ func (bd balanceDimensions) String() string {
 
 // candidate store for allocation.
 type candidate struct {
-	store           roachpb.StoreDescriptor
-	valid           bool
+	store roachpb.StoreDescriptor
+	valid bool
+	// necessary is set for stores which are needed to satisfy the replica
+	// constraints of the zone.
+	necessary       bool
 	constraintScore float64
 	convergesScore  int
 	balanceScore    balanceDimensions
@@ -108,10 +111,11 @@ type candidate struct {
 }
 
 func (c candidate) String() string {
-	return fmt.Sprintf("s%d, valid:%t, constraint:%.2f, converges:%d, balance:%s, rangeCount:%d, "+
-		"logicalBytes:%s, writesPerSecond:%.2f, details:(%s)",
-		c.store.StoreID, c.valid, c.constraintScore, c.convergesScore, c.balanceScore, c.rangeCount,
-		humanizeutil.IBytes(c.store.Capacity.LogicalBytes), c.store.Capacity.WritesPerSecond, c.details)
+	return fmt.Sprintf("s%d, valid:%t, necessary:%t, constraint:%.2f, converges:%d, balance:%s, "+
+		"rangeCount:%d, logicalBytes:%s, writesPerSecond:%.2f, details:(%s)",
+		c.store.StoreID, c.valid, c.necessary, c.constraintScore, c.convergesScore, c.balanceScore,
+		c.rangeCount, humanizeutil.IBytes(c.store.Capacity.LogicalBytes),
+		c.store.Capacity.WritesPerSecond, c.details)
 }
 
 // less returns true if o is a better fit for some range than c is.
@@ -122,6 +126,9 @@ func (c candidate) less(o candidate) bool {
 	if !c.valid {
 		return true
 	}
+	if c.necessary != o.necessary {
+		return o.necessary
+	}
 	if c.constraintScore != o.constraintScore {
 		return c.constraintScore < o.constraintScore
 	}
@@ -166,7 +173,8 @@ var _ sort.Interface = byScoreAndID(nil)
 
 func (c byScoreAndID) Len() int { return len(c) }
 func (c byScoreAndID) Less(i, j int) bool {
-	if c[i].constraintScore == c[j].constraintScore &&
+	if c[i].necessary == c[j].necessary &&
+		c[i].constraintScore == c[j].constraintScore &&
 		c[i].convergesScore == c[j].convergesScore &&
 		c[i].balanceScore.totalScore() == c[j].balanceScore.totalScore() &&
 		c[i].rangeCount == c[j].rangeCount &&
@@ -196,7 +204,8 @@ func (cl candidateList) best() candidateList {
 		return cl
 	}
 	for i := 1; i < len(cl); i++ {
-		if cl[i].constraintScore < cl[0].constraintScore ||
+		if cl[i].necessary != cl[0].necessary ||
+			cl[i].constraintScore < cl[0].constraintScore ||
 			(cl[i].constraintScore == cl[len(cl)-1].constraintScore &&
 				cl[i].convergesScore < cl[len(cl)-1].convergesScore) {
 			return cl[:i]
@@ -221,7 +230,8 @@ func (cl candidateList) worst() candidateList {
 	}
 	// Find the worst constraint values.
 	for i := len(cl) - 2; i >= 0; i-- {
-		if cl[i].constraintScore > cl[len(cl)-1].constraintScore ||
+		if cl[i].necessary != cl[len(cl)-1].necessary ||
+			cl[i].constraintScore > cl[len(cl)-1].constraintScore ||
 			(cl[i].constraintScore == cl[len(cl)-1].constraintScore &&
 				cl[i].convergesScore > cl[len(cl)-1].convergesScore) {
 			return cl[i+1:]
@@ -291,18 +301,19 @@ func (cl candidateList) selectBad(randGen allocatorRand) *candidate {
 func allocateCandidates(
 	st *cluster.Settings,
 	sl StoreList,
-	constraints config.Constraints,
+	constraints analyzedConstraints,
 	existing []roachpb.ReplicaDescriptor,
 	rangeInfo RangeInfo,
 	existingNodeLocalities map[roachpb.NodeID]roachpb.Locality,
 	deterministic bool,
 ) candidateList {
+	newReplicaConstraints := constraints.newReplicaConstraints()
 	var candidates candidateList
 	for _, s := range sl.stores {
 		if !preexistingReplicaCheck(s.Node.NodeID, existing) {
 			continue
 		}
-		constraintsOk, preferredMatched := constraintCheck(s, constraints)
+		constraintsOk, preferredMatched := constraintCheck(s, newReplicaConstraints)
 		if !constraintsOk {
 			continue
 		}
@@ -334,14 +345,14 @@ func allocateCandidates(
 func removeCandidates(
 	st *cluster.Settings,
 	sl StoreList,
-	constraints config.Constraints,
+	constraints analyzedConstraints,
 	rangeInfo RangeInfo,
 	existingNodeLocalities map[roachpb.NodeID]roachpb.Locality,
 	deterministic bool,
 ) candidateList {
 	var candidates candidateList
 	for _, s := range sl.stores {
-		constraintsOk, preferredMatched := constraintCheck(s, constraints)
+		constraintsOk, preferredMatched := constraintCheck(s, constraints.constraints)
 		if !constraintsOk {
 			candidates = append(candidates, candidate{
 				store:   s,
@@ -372,6 +383,7 @@ func removeCandidates(
 		candidates = append(candidates, candidate{
 			store:           s,
 			valid:           true,
+			necessary:       constraints.necessary[s.StoreID],
 			constraintScore: diversityScore + float64(preferredMatched),
 			convergesScore:  convergesScore,
 			balanceScore:    balanceScore,
@@ -395,7 +407,7 @@ func rebalanceCandidates(
 	ctx context.Context,
 	st *cluster.Settings,
 	sl StoreList,
-	constraints config.Constraints,
+	constraints analyzedConstraints,
 	existing []roachpb.ReplicaDescriptor,
 	rangeInfo RangeInfo,
 	existingNodeLocalities map[roachpb.NodeID]roachpb.Locality,
@@ -417,10 +429,10 @@ func rebalanceCandidates(
 		matched int
 	}
 	storeInfos := make(map[roachpb.StoreID]constraintInfo)
+	newReplicaConstraints := constraints.newReplicaConstraints()
 	var rebalanceConstraintsCheck bool
 	for _, s := range sl.stores {
-		constraintsOk, preferredMatched := constraintCheck(s, constraints)
-		storeInfos[s.StoreID] = constraintInfo{ok: constraintsOk, matched: preferredMatched}
+		constraintsOk, preferredMatched := constraintCheck(s, constraints.constraints)
 		_, exists := existingStoreIDs[s.StoreID]
 		if constraintsOk {
 			constraintsOkStoreDescriptors = append(constraintsOkStoreDescriptors, s)
@@ -428,6 +440,15 @@ func rebalanceCandidates(
 			rebalanceConstraintsCheck = true
 			log.VEventf(ctx, 2, "must rebalance from s%d due to constraint check", s.StoreID)
 		}
+		if !exists {
+			// A replica which is added must also satisfy the replica
+			// constraints which the existing replicas don't.
+			constraintsOk, preferredMatched = constraintCheck(s, newReplicaConstraints)
+		} else if constraints.missing >= 0 && !constraints.necessary[s.StoreID] {
+			rebalanceConstraintsCheck = true
+			log.VEventf(ctx, 2, "must rebalance from s%d to satisfy replica constraints", s.StoreID)
+		}
+		storeInfos[s.StoreID] = constraintInfo{ok: constraintsOk, matched: preferredMatched}
 	}
 
 	constraintsOkStoreList := makeStoreList(constraintsOkStoreDescriptors)
@@ -485,6 +506,7 @@ func rebalanceCandidates(
 			existingCandidates = append(existingCandidates, candidate{
 				store:           s,
 				valid:           true,
+				necessary:       constraints.necessary[s.StoreID],
 				constraintScore: diversityScore + float64(storeInfo.matched),
 				convergesScore:  convergesScore,
 				balanceScore:    balanceScore,
@@ -513,6 +535,7 @@ func rebalanceCandidates(
 			candidates = append(candidates, candidate{
 				store:           s,
 				valid:           true,
+				necessary:       constraints.missing >= 0 || constraints.groupOf(s) >= 0,
 				constraintScore: diversityScore + float64(storeInfo.matched),
 				convergesScore:  convergesScore,
 				balanceScore:    balanceScore,
@@ -687,6 +710,100 @@ func constraintCheck(store roachpb.StoreDescriptor, constraints config.Constrain
 	return true, positive
 }
 
+// analyzedConstraints holds the constraints of a zone along with the way the
+// existing replicas of a range satisfy its replica constraints.
+type analyzedConstraints struct {
+	// constraints apply to all of the replicas of the range.
+	constraints config.Constraints
+	// groups are the replica constraints of the zone and counts holds the
+	// number of existing replicas which count towards each of them.
+	groups []config.ReplicaConstraints
+	counts []int
+	// missing is the index of the first group with fewer replicas than it
+	// asks for, or -1 if there is no such group.
+	missing int
+	// necessary holds the stores of the existing replicas whose removal would
+	// leave their group with fewer replicas than it asks for.
+	necessary map[roachpb.StoreID]bool
+}
+
+// analyzeConstraints matches the stores of the existing replicas of a range
+// against the replica constraints of its zone. A replica counts towards the
+// first group all of whose constraints its store satisfies.
+func analyzeConstraints(
+	zone config.ZoneConfig, existing []roachpb.StoreDescriptor,
+) analyzedConstraints {
+	ac := analyzedConstraints{
+		constraints: zone.Constraints,
+		groups:      zone.ReplicaConstraints,
+		counts:      make([]int, len(zone.ReplicaConstraints)),
+		missing:     -1,
+		necessary:   make(map[roachpb.StoreID]bool),
+	}
+	groupOf := make([]int, len(existing))
+	for i, s := range existing {
+		groupOf[i] = ac.groupOf(s)
+		if groupOf[i] >= 0 {
+			ac.counts[groupOf[i]]++
+		}
+	}
+	for i, s := range existing {
+		if g := groupOf[i]; g >= 0 && ac.counts[g] <= int(ac.groups[g].NumReplicas) {
+			ac.necessary[s.StoreID] = true
+		}
+	}
+	for g := range ac.groups {
+		if ac.counts[g] < int(ac.groups[g].NumReplicas) {
+			ac.missing = g
+			break
+		}
+	}
+	return ac
+}
+
+// groupOf returns the index of the first replica constraint group all of
+// whose constraints the store satisfies, or -1.
+func (ac analyzedConstraints) groupOf(store roachpb.StoreDescriptor) int {
+	for g := range ac.groups {
+		if storeMatchesConstraints(store, ac.groups[g].Constraints) {
+			return g
+		}
+	}
+	return -1
+}
+
+// newReplicaConstraints returns the constraints which a new replica of the
+// range must satisfy: those of the zone and, if a replica constraint group
+// is missing replicas, those of the group.
+func (ac analyzedConstraints) newReplicaConstraints() config.Constraints {
+	if ac.missing < 0 {
+		return ac.constraints
+	}
+	group := ac.groups[ac.missing].Constraints.Constraints
+	combined := make([]config.Constraint, 0, len(ac.constraints.Constraints)+len(group))
+	combined = append(combined, ac.constraints.Constraints...)
+	for _, c := range group {
+		// The replica only counts towards the group if it satisfies all of
+		// its constraints.
+		if c.Type == config.Constraint_POSITIVE {
+			c.Type = config.Constraint_REQUIRED
+		}
+		combined = append(combined, c)
+	}
+	return config.Constraints{Constraints: combined}
+}
+
+// storeMatchesConstraints returns true iff the store has all of the given
+// positive and required constraints and none of the prohibited ones.
+func storeMatchesConstraints(store roachpb.StoreDescriptor, constraints config.Constraints) bool {
+	for _, c := range constraints.Constraints {
+		if storeHasConstraint(store, c) == (c.Type == config.Constraint_PROHIBITED) {
+			return false
+		}
+	}
+	return true
+}
+
 // diversityScore returns a score between 1 and 0 where higher scores are stores
 // with the fewest locality tiers in common with already existing replicas.
 func diversityScore(
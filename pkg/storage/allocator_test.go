@@ -265,7 +265,7 @@ func TestAllocatorSimpleRetrieval(t *testing.T) {
 	gossiputil.NewStoreGossiper(g).GossipStores(singleStore, t)
 	result, _, err := a.AllocateTarget(
 		context.Background(),
-		simpleZoneConfig.Constraints,
+		simpleZoneConfig,
 		[]roachpb.ReplicaDescriptor{},
 		firstRangeInfo,
 		false,
@@ -299,7 +299,7 @@ func TestAllocatorCorruptReplica(t *testing.T) {
 
 	result, _, err := a.AllocateTarget(
 		context.Background(),
-		simpleZoneConfig.Constraints,
+		simpleZoneConfig,
 		[]roachpb.ReplicaDescriptor{},
 		firstRangeInfo,
 		true,
@@ -319,7 +319,7 @@ func TestAllocatorNoAvailableDisks(t *testing.T) {
 	defer stopper.Stop(context.Background())
 	result, _, err := a.AllocateTarget(
 		context.Background(),
-		simpleZoneConfig.Constraints,
+		simpleZoneConfig,
 		[]roachpb.ReplicaDescriptor{},
 		firstRangeInfo,
 		false,
@@ -341,7 +341,7 @@ func TestAllocatorTwoDatacenters(t *testing.T) {
 	ctx := context.Background()
 	result1, _, err := a.AllocateTarget(
 		ctx,
-		multiDCConfig.Constraints,
+		multiDCConfig,
 		[]roachpb.ReplicaDescriptor{},
 		firstRangeInfo,
 		false,
@@ -351,7 +351,7 @@ func TestAllocatorTwoDatacenters(t *testing.T) {
 	}
 	result2, _, err := a.AllocateTarget(
 		ctx,
-		multiDCConfig.Constraints,
+		multiDCConfig,
 		[]roachpb.ReplicaDescriptor{{
 			NodeID:  result1.Node.NodeID,
 			StoreID: result1.StoreID,
@@ -370,7 +370,7 @@ func TestAllocatorTwoDatacenters(t *testing.T) {
 	// Verify that no result is forthcoming if we already have a replica.
 	result3, _, err := a.AllocateTarget(
 		ctx,
-		multiDCConfig.Constraints,
+		multiDCConfig,
 		[]roachpb.ReplicaDescriptor{
 			{
 				NodeID:  result1.Node.NodeID,
@@ -397,10 +397,12 @@ func TestAllocatorExistingReplica(t *testing.T) {
 	gossiputil.NewStoreGossiper(g).GossipStores(sameDCStores, t)
 	result, _, err := a.AllocateTarget(
 		context.Background(),
-		config.Constraints{
-			Constraints: []config.Constraint{
-				{Value: "a"},
-				{Value: "hdd"},
+		config.ZoneConfig{
+			Constraints: config.Constraints{
+				Constraints: []config.Constraint{
+					{Value: "a"},
+					{Value: "hdd"},
+				},
 			},
 		},
 		[]roachpb.ReplicaDescriptor{
@@ -581,7 +583,7 @@ func TestAllocatorRelaxConstraints(t *testing.T) {
 			}
 			result, _, err := a.AllocateTarget(
 				context.Background(),
-				config.Constraints{Constraints: test.constraints},
+				config.ZoneConfig{Constraints: config.Constraints{Constraints: test.constraints}},
 				existing,
 				firstRangeInfo,
 				false,
@@ -595,6 +597,122 @@ func TestAllocatorRelaxConstraints(t *testing.T) {
 	}
 }
 
+// regionStores returns five stores on five nodes, the first three of which
+// are in region us-east and the last two in region us-west. The lease count
+// of each store is 10x its store ID.
+func regionStores() []*roachpb.StoreDescriptor {
+	var stores []*roachpb.StoreDescriptor
+	for i := 1; i <= 5; i++ {
+		region := "us-east"
+		if i > 3 {
+			region = "us-west"
+		}
+		stores = append(stores, &roachpb.StoreDescriptor{
+			StoreID: roachpb.StoreID(i),
+			Node: roachpb.NodeDescriptor{
+				NodeID: roachpb.NodeID(i),
+				Locality: roachpb.Locality{
+					Tiers: []roachpb.Tier{{Key: "region", Value: region}},
+				},
+			},
+			Capacity: roachpb.StoreCapacity{
+				Capacity:   200,
+				Available:  100,
+				RangeCount: 10,
+				LeaseCount: int32(10 * i),
+			},
+		})
+	}
+	return stores
+}
+
+func regionConstraints(regions ...string) config.Constraints {
+	var c config.Constraints
+	for _, region := range regions {
+		c.Constraints = append(c.Constraints, config.Constraint{
+			Type: config.Constraint_REQUIRED, Key: "region", Value: region,
+		})
+	}
+	return c
+}
+
+// TestAllocatorReplicaConstraints verifies that the allocator places the
+// replicas of a range according to the replica constraints of its zone.
+func TestAllocatorReplicaConstraints(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper, g, _, a, _ := createTestAllocator( /* deterministic */ true)
+	defer stopper.Stop(context.Background())
+	gossiputil.NewStoreGossiper(g).GossipStores(regionStores(), t)
+	ctx := context.Background()
+
+	// Two replicas in us-east and one in us-west.
+	zone := config.ZoneConfig{
+		NumReplicas: 3,
+		ReplicaConstraints: []config.ReplicaConstraints{
+			{NumReplicas: 2, Constraints: regionConstraints("us-east")},
+			{NumReplicas: 1, Constraints: regionConstraints("us-west")},
+		},
+	}
+	replicas := func(storeIDs ...roachpb.StoreID) []roachpb.ReplicaDescriptor {
+		var repls []roachpb.ReplicaDescriptor
+		for _, storeID := range storeIDs {
+			repls = append(repls, roachpb.ReplicaDescriptor{
+				NodeID: roachpb.NodeID(storeID), StoreID: storeID, ReplicaID: roachpb.ReplicaID(storeID),
+			})
+		}
+		return repls
+	}
+	inRegion := func(storeID roachpb.StoreID, region string) bool {
+		return (storeID <= 3) == (region == "us-east")
+	}
+
+	allocateTestCases := []struct {
+		existing []roachpb.StoreID
+		region   string
+	}{
+		{existing: nil, region: "us-east"},
+		{existing: []roachpb.StoreID{1}, region: "us-east"},
+		{existing: []roachpb.StoreID{4}, region: "us-east"},
+		{existing: []roachpb.StoreID{1, 2}, region: "us-west"},
+		{existing: []roachpb.StoreID{1, 4}, region: "us-east"},
+	}
+	for _, c := range allocateTestCases {
+		existing := replicas(c.existing...)
+		target, _, err := a.AllocateTarget(ctx, zone, existing, testRangeInfo(existing, firstRange), false)
+		if err != nil {
+			t.Fatalf("%v: unable to allocate: %s", c.existing, err)
+		}
+		if !inRegion(target.StoreID, c.region) {
+			t.Errorf("%v: expected a target in %s, but found s%d", c.existing, c.region, target.StoreID)
+		}
+	}
+
+	removeTestCases := []struct {
+		existing []roachpb.StoreID
+		region   string
+	}{
+		{existing: []roachpb.StoreID{1, 2, 3, 4}, region: "us-east"},
+		{existing: []roachpb.StoreID{1, 2, 4, 5}, region: "us-west"},
+	}
+	for _, c := range removeTestCases {
+		existing := replicas(c.existing...)
+		target, _, err := a.RemoveTarget(ctx, zone, existing, testRangeInfo(existing, firstRange))
+		if err != nil {
+			t.Fatalf("%v: unable to remove: %s", c.existing, err)
+		}
+		if !inRegion(target.StoreID, c.region) {
+			t.Errorf("%v: expected to remove a replica in %s, but found s%d", c.existing, c.region, target.StoreID)
+		}
+	}
+
+	// All of the replicas are in us-east, so one of them is moved to us-west.
+	existing := replicas(1, 2, 3)
+	target, _ := a.RebalanceTarget(ctx, zone, testRangeInfo(existing, firstRange), storeFilterThrottled)
+	if target == nil || !inRegion(target.StoreID, "us-west") {
+		t.Errorf("expected a rebalance target in us-west, but found %+v", target)
+	}
+}
+
 // TestAllocatorRebalance verifies that rebalance targets are chosen
 // randomly from amongst stores over the minAvailCapacityThreshold.
 func TestAllocatorRebalance(t *testing.T) {
@@ -656,7 +774,7 @@ func TestAllocatorRebalance(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		result, _ := a.RebalanceTarget(
 			ctx,
-			config.Constraints{},
+			config.ZoneConfig{},
 			testRangeInfo([]roachpb.ReplicaDescriptor{{StoreID: 3}}, firstRange),
 			storeFilterThrottled,
 		)
@@ -754,7 +872,7 @@ func TestAllocatorRebalanceDeadNodes(t *testing.T) {
 	for _, c := range testCases {
 		t.Run("", func(t *testing.T) {
 			result, _ := a.RebalanceTarget(
-				ctx, config.Constraints{}, testRangeInfo(c.existing, firstRange), storeFilterThrottled)
+				ctx, config.ZoneConfig{}, testRangeInfo(c.existing, firstRange), storeFilterThrottled)
 			if c.expected > 0 {
 				if result == nil {
 					t.Fatalf("expected %d, but found nil", c.expected)
@@ -948,7 +1066,7 @@ func TestAllocatorRebalanceByCount(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		result, _ := a.RebalanceTarget(
 			ctx,
-			config.Constraints{},
+			config.ZoneConfig{},
 			testRangeInfo([]roachpb.ReplicaDescriptor{{StoreID: stores[0].StoreID}}, firstRange),
 			storeFilterThrottled,
 		)
@@ -1018,7 +1136,7 @@ func TestAllocatorTransferLeaseTarget(t *testing.T) {
 		t.Run("", func(t *testing.T) {
 			target := a.TransferLeaseTarget(
 				context.Background(),
-				config.Constraints{},
+				config.ZoneConfig{},
 				c.existing,
 				c.leaseholder,
 				0,
@@ -1072,7 +1190,7 @@ func TestAllocatorTransferLeaseTargetMultiStore(t *testing.T) {
 		t.Run("", func(t *testing.T) {
 			target := a.TransferLeaseTarget(
 				context.Background(),
-				config.Constraints{},
+				config.ZoneConfig{},
 				existing,
 				c.leaseholder,
 				0,
@@ -1088,6 +1206,129 @@ func TestAllocatorTransferLeaseTargetMultiStore(t *testing.T) {
 	}
 }
 
+// TestAllocatorLeasePreferences verifies that leases are kept on the replicas
+// matching the first lease preference of the zone which any replica matches.
+func TestAllocatorLeasePreferences(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper, g, _, a, _ := createTestAllocator( /* deterministic */ true)
+	defer stopper.Stop(context.Background())
+	gossiputil.NewStoreGossiper(g).GossipStores(regionStores(), t)
+
+	existing := []roachpb.ReplicaDescriptor{
+		{NodeID: 1, StoreID: 1},
+		{NodeID: 3, StoreID: 3},
+		{NodeID: 4, StoreID: 4},
+	}
+	west := []config.Constraints{regionConstraints("us-west")}
+	east := []config.Constraints{regionConstraints("us-east")}
+	euThenEast := []config.Constraints{regionConstraints("eu"), regionConstraints("us-east")}
+
+	testCases := []struct {
+		preferences    []config.Constraints
+		leaseholder    roachpb.StoreID
+		check          bool
+		expectedTarget roachpb.StoreID
+		expectedShould bool
+	}{
+		// The lease holder doesn't match the preference.
+		{preferences: west, leaseholder: 1, check: true, expectedTarget: 4, expectedShould: true},
+		{preferences: euThenEast, leaseholder: 4, check: true, expectedTarget: 1, expectedShould: true},
+		// The lease holder is the only replica matching the preference: the
+		// lease only moves if it must.
+		{preferences: west, leaseholder: 4, check: true, expectedTarget: 0, expectedShould: true},
+		{preferences: west, leaseholder: 4, check: false, expectedTarget: 1, expectedShould: true},
+		// The lease only moves between the replicas matching the preference.
+		{preferences: east, leaseholder: 1, check: true, expectedTarget: 0, expectedShould: false},
+		{preferences: east, leaseholder: 3, check: false, expectedTarget: 1, expectedShould: false},
+	}
+	for _, c := range testCases {
+		t.Run("", func(t *testing.T) {
+			zone := config.ZoneConfig{LeasePreferences: c.preferences}
+			target := a.TransferLeaseTarget(
+				context.Background(),
+				zone,
+				existing,
+				c.leaseholder,
+				0,
+				nil, /* replicaStats */
+				c.check,
+				true,  /* checkCandidateFullness */
+				false, /* !alwaysAllowDecisionWithoutStats */
+			)
+			if c.expectedTarget != target.StoreID {
+				t.Errorf("expected target %d, but found %d", c.expectedTarget, target.StoreID)
+			}
+			should := a.ShouldTransferLease(
+				context.Background(),
+				zone,
+				existing,
+				c.leaseholder,
+				0,
+				nil, /* replicaStats */
+			)
+			if c.expectedShould != should {
+				t.Errorf("expected should transfer %t, but found %t", c.expectedShould, should)
+			}
+		})
+	}
+}
+
+// TestAllocatorLeasePreferencesDeadStore verifies that replicas on stores
+// which cannot receive the lease do not satisfy lease preferences.
+func TestAllocatorLeasePreferencesDeadStore(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper, g, _, storePool, mnl := createTestStorePool(
+		TestTimeUntilStoreDeadOff, true /* deterministic */, nodeStatusLive)
+	defer stopper.Stop(context.Background())
+	a := MakeAllocator(storePool, func(string) (time.Duration, bool) {
+		return 0, true
+	})
+	gossiputil.NewStoreGossiper(g).GossipStores(regionStores(), t)
+	// The only replica in us-west is dead.
+	mnl.setNodeStatus(4, nodeStatusDead)
+
+	existing := []roachpb.ReplicaDescriptor{
+		{NodeID: 1, StoreID: 1},
+		{NodeID: 3, StoreID: 3},
+		{NodeID: 4, StoreID: 4},
+	}
+	testCases := []struct {
+		preferences []config.Constraints
+	}{
+		{preferences: []config.Constraints{regionConstraints("us-west")}},
+		{preferences: []config.Constraints{regionConstraints("us-west"), regionConstraints("us-east")}},
+	}
+	for _, c := range testCases {
+		t.Run("", func(t *testing.T) {
+			zone := config.ZoneConfig{LeasePreferences: c.preferences}
+			target := a.TransferLeaseTarget(
+				context.Background(),
+				zone,
+				existing,
+				1, /* leaseStoreID */
+				0,
+				nil,   /* replicaStats */
+				true,  /* checkTransferLeaseSource */
+				true,  /* checkCandidateFullness */
+				false, /* !alwaysAllowDecisionWithoutStats */
+			)
+			if target.StoreID != 0 {
+				t.Errorf("expected no lease transfer, but found target %d", target.StoreID)
+			}
+			if a.ShouldTransferLease(
+				context.Background(),
+				zone,
+				existing,
+				1, /* leaseStoreID */
+				0,
+				nil, /* replicaStats */
+			) {
+				t.Error("expected the lease to stay on s1")
+			}
+		})
+	}
+}
+
 func TestAllocatorShouldTransferLease(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	stopper, g, _, a, _ := createTestAllocator( /* deterministic */ true)
@@ -1135,7 +1376,7 @@ func TestAllocatorShouldTransferLease(t *testing.T) {
 		t.Run("", func(t *testing.T) {
 			result := a.ShouldTransferLease(
 				context.Background(),
-				config.Constraints{},
+				config.ZoneConfig{},
 				c.existing,
 				c.leaseholder,
 				0,
@@ -1148,6 +1389,90 @@ func TestAllocatorShouldTransferLease(t *testing.T) {
 	}
 }
 
+// TestAllocatorTransferLeaseTargetQPS verifies that leases of busy ranges are
+// moved off stores serving many more queries per second than the others.
+func TestAllocatorTransferLeaseTargetQPS(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper, g, _, a, _ := createTestAllocator( /* deterministic */ true)
+	defer stopper.Stop(context.Background())
+
+	// 3 stores with the same number of leases but very different loads.
+	qps := []float64{1000, 100, 400}
+	var stores []*roachpb.StoreDescriptor
+	for i := 1; i <= 3; i++ {
+		stores = append(stores, &roachpb.StoreDescriptor{
+			StoreID: roachpb.StoreID(i),
+			Node:    roachpb.NodeDescriptor{NodeID: roachpb.NodeID(i)},
+			Capacity: roachpb.StoreCapacity{
+				LeaseCount:       10,
+				QueriesPerSecond: qps[i-1],
+			},
+		})
+	}
+	sg := gossiputil.NewStoreGossiper(g)
+	sg.GossipStores(stores, t)
+
+	manual := hlc.NewManualClock(123)
+	clock := hlc.NewClock(manual.UnixNano, time.Nanosecond)
+	const statsSeconds = 10
+	warm := newReplicaStats(clock, nil)
+	warm.recordCount(200*statsSeconds, 0)
+	hot := newReplicaStats(clock, nil)
+	hot.recordCount(500*statsSeconds, 0)
+	manual.Increment(int64(statsSeconds * time.Second))
+
+	existing := []roachpb.ReplicaDescriptor{
+		{NodeID: 1, StoreID: 1},
+		{NodeID: 2, StoreID: 2},
+		{NodeID: 3, StoreID: 3},
+	}
+
+	testCases := []struct {
+		leaseholder roachpb.StoreID
+		stats       *replicaStats
+		expected    roachpb.StoreID
+	}{
+		// The busiest store moves the lease to the least busy one.
+		{leaseholder: 1, stats: warm, expected: 2},
+		// Without stats, the load of the range is unknown.
+		{leaseholder: 1, stats: nil, expected: 0},
+		// Moving a very busy range would only move the imbalance.
+		{leaseholder: 1, stats: hot, expected: 0},
+		// The other stores are not overloaded.
+		{leaseholder: 2, stats: warm, expected: 0},
+		{leaseholder: 3, stats: warm, expected: 0},
+	}
+	for _, c := range testCases {
+		t.Run("", func(t *testing.T) {
+			target := a.TransferLeaseTarget(
+				context.Background(),
+				config.ZoneConfig{},
+				existing,
+				c.leaseholder,
+				0,
+				c.stats,
+				true,  /* checkTransferLeaseSource */
+				true,  /* checkCandidateFullness */
+				false, /* !alwaysAllowDecisionWithoutStats */
+			)
+			if c.expected != target.StoreID {
+				t.Fatalf("expected %d, but found %d", c.expected, target.StoreID)
+			}
+			should := a.ShouldTransferLease(
+				context.Background(),
+				config.ZoneConfig{},
+				existing,
+				c.leaseholder,
+				0,
+				c.stats,
+			)
+			if expected := c.expected != 0; expected != should {
+				t.Fatalf("expected ShouldTransferLease to return %t, but found %t", expected, should)
+			}
+		})
+	}
+}
+
 // Test out the load-based lease transfer algorithm against a variety of
 // request distributions and inter-node latencies.
 func TestAllocatorTransferLeaseTargetLoadBased(t *testing.T) {
@@ -1301,7 +1626,7 @@ func TestAllocatorTransferLeaseTargetLoadBased(t *testing.T) {
 			})
 			target := a.TransferLeaseTarget(
 				context.Background(),
-				config.Constraints{},
+				config.ZoneConfig{},
 				existing,
 				c.leaseholder,
 				0,
@@ -1502,7 +1827,7 @@ func TestAllocatorRemoveTarget(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		targetRepl, _, err := a.RemoveTarget(
 			ctx,
-			config.Constraints{},
+			config.ZoneConfig{},
 			replicas,
 			testRangeInfo(replicas, firstRange),
 		)
@@ -2248,7 +2573,7 @@ func TestAllocatorThrottled(t *testing.T) {
 	// First test to make sure we would send the replica to purgatory.
 	_, _, err := a.AllocateTarget(
 		ctx,
-		simpleZoneConfig.Constraints,
+		simpleZoneConfig,
 		[]roachpb.ReplicaDescriptor{},
 		firstRangeInfo,
 		false,
@@ -2261,7 +2586,7 @@ func TestAllocatorThrottled(t *testing.T) {
 	gossiputil.NewStoreGossiper(g).GossipStores(singleStore, t)
 	result, _, err := a.AllocateTarget(
 		ctx,
-		simpleZoneConfig.Constraints,
+		simpleZoneConfig,
 		[]roachpb.ReplicaDescriptor{},
 		firstRangeInfo,
 		false,
@@ -2284,7 +2609,7 @@ func TestAllocatorThrottled(t *testing.T) {
 	a.storePool.detailsMu.Unlock()
 	_, _, err = a.AllocateTarget(
 		ctx,
-		simpleZoneConfig.Constraints,
+		simpleZoneConfig,
 		[]roachpb.ReplicaDescriptor{},
 		firstRangeInfo,
 		false,
@@ -2543,15 +2868,17 @@ func TestAllocatorRebalanceAway(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.constraint.String(), func(t *testing.T) {
-			constraints := config.Constraints{
-				Constraints: []config.Constraint{
-					tc.constraint,
+			zone := config.ZoneConfig{
+				Constraints: config.Constraints{
+					Constraints: []config.Constraint{
+						tc.constraint,
+					},
 				},
 			}
 
 			actual, _ := a.RebalanceTarget(
 				ctx,
-				constraints,
+				zone,
 				testRangeInfo(existingReplicas, firstRange),
 				storeFilterThrottled,
 			)
@@ -2671,7 +2998,7 @@ func Example_rebalancing() {
 			ts := &testStores[j]
 			target, _ := alloc.RebalanceTarget(
 				context.Background(),
-				config.Constraints{},
+				config.ZoneConfig{},
 				testRangeInfo([]roachpb.ReplicaDescriptor{{NodeID: ts.Node.NodeID, StoreID: ts.StoreID}}, firstRange),
 				storeFilterThrottled,
 			)
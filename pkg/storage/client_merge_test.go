@@ -19,11 +19,16 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/gossip"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -432,6 +437,190 @@ func TestStoreRangeMergeStats(t *testing.T) {
 	}
 }
 
+// TestStoreRangeMergeQueueWithTraffic runs the merge queue over two small
+// replicated ranges while they serve writes and while the lease of the
+// right-hand range moves between its replicas. The right-hand range starts
+// out on a different set of stores than the left-hand range, none of which
+// is the left-hand lease holder, so the queue has to co-locate the replicas
+// first. The test verifies that no acknowledged write is lost, neither on
+// the lease holder nor on the followers of the merged range.
+func TestStoreRangeMergeQueueWithTraffic(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	storeCfg := storage.TestStoreConfig(nil)
+	storeCfg.TestingKnobs.DisableSplitQueue = true
+	storeCfg.TestingKnobs.DisableReplicateQueue = true
+	mtc := &multiTestContext{storeConfig: &storeCfg}
+	defer mtc.Stop()
+	mtc.Start(t, 4)
+	store := mtc.stores[0]
+	ctx := context.Background()
+
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	config.TestingSetupZoneConfigHook(stopper)
+	descID := uint32(keys.MaxReservedDescID + 1)
+	zone := config.ZoneConfig{NumReplicas: 3, RangeMinBytes: 1 << 20, RangeMaxBytes: 64 << 20}
+	config.TestingSetZoneConfig(descID, zone)
+	config.TestingSetZoneConfig(descID+1, zone)
+	if err := store.Gossip().AddInfoProto(gossip.KeySystemConfig, &config.SystemConfig{}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Split the table into two ranges, which are separated from their other
+	// neighbors by table boundaries.
+	tableStart := roachpb.Key(keys.MakeTablePrefix(descID))
+	tableMid := append(tableStart[:len(tableStart):len(tableStart)], 'm')
+	nextTableStart := roachpb.Key(keys.MakeTablePrefix(descID + 1))
+	for _, key := range []roachpb.Key{tableStart, tableMid, nextTableStart} {
+		if err := store.DB().AdminSplit(ctx, key, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Replicate the left-hand range to stores 0, 1 and 2, and move the
+	// right-hand range to stores 1, 2 and 3, with its lease on store 3.
+	lhsID := store.LookupReplica(roachpb.RKey(tableStart), nil).RangeID
+	rhsID := store.LookupReplica(roachpb.RKey(tableMid), nil).RangeID
+	mtc.replicateRange(lhsID, 1, 2)
+	mtc.replicateRange(rhsID, 1, 2, 3)
+	mtc.transferLease(ctx, rhsID, 0, 3)
+	mtc.unreplicateRange(rhsID, 0)
+
+	// Each worker increments a counter on one side of the merge through the
+	// gateway of a different store, and checks that the counter never goes
+	// backwards.
+	const numWorkers = 4
+	done := make(chan struct{})
+	errs := make(chan error, numWorkers+1)
+	var wg sync.WaitGroup
+	counts := make([]int64, numWorkers)
+	counterKeys := make([]roachpb.Key, numWorkers)
+	for i := range counterKeys {
+		prefix := tableStart
+		if i%2 == 1 {
+			prefix = tableMid
+		}
+		counterKeys[i] = append(prefix[:len(prefix):len(prefix)], fmt.Sprintf("counter-%d", i)...)
+	}
+	var ops int64
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			db := mtc.dbs[i%len(mtc.dbs)]
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				kv, err := db.Inc(ctx, counterKeys[i], 1)
+				_, ambiguous := errors.Cause(err).(*roachpb.AmbiguousResultError)
+				if err != nil && !ambiguous {
+					errs <- err
+					return
+				}
+				if err == nil {
+					counts[i]++
+					if v := kv.ValueInt(); v != counts[i] {
+						errs <- errors.Errorf("%s: expected %d, but found %d", counterKeys[i], counts[i], v)
+						return
+					}
+				}
+				kv, err = db.Get(ctx, counterKeys[i])
+				if err != nil {
+					errs <- err
+					return
+				}
+				v := kv.ValueInt()
+				if ambiguous && v == counts[i]+1 {
+					// The increment was applied even though its result was lost.
+					counts[i]++
+				}
+				if v != counts[i] {
+					errs <- errors.Errorf("%s: read %d after writing %d", counterKeys[i], v, counts[i])
+					return
+				}
+				atomic.AddInt64(&ops, 1)
+			}
+		}(i)
+	}
+
+	// Move the lease of the right-hand range between the stores of the
+	// left-hand range until the ranges are merged, including while the
+	// right-hand range is frozen by the merge. Transfers to stores without a
+	// replica fail, which is fine.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+			repl := store.LookupReplica(roachpb.RKey(tableMid), nil)
+			if repl != nil && repl.RangeID != rhsID {
+				// The ranges have been merged.
+				return
+			}
+			target := mtc.idents[i%3].StoreID
+			if err := mtc.dbs[0].AdminTransferLease(ctx, tableMid, target); err != nil {
+				log.Infof(ctx, "transferring lease to s%d: %s", target, err)
+			}
+		}
+	}()
+
+	waitForOps := func(n int64) {
+		testutils.SucceedsSoon(t, func() error {
+			if cur := atomic.LoadInt64(&ops); cur < n {
+				return errors.Errorf("%d operations so far", cur)
+			}
+			return nil
+		})
+	}
+	waitForOps(10 * numWorkers)
+
+	store.SetMergeQueueEnabled(true)
+	testutils.SucceedsSoon(t, func() error {
+		store.ForceMergeScanAndProcess()
+		repl := store.LookupReplica(roachpb.RKey(tableStart), nil)
+		if desc := repl.Desc(); !desc.EndKey.Equal(roachpb.RKey(nextTableStart)) {
+			return errors.Errorf("ranges not merged yet: %s", desc)
+		}
+		return nil
+	})
+
+	waitForOps(atomic.LoadInt64(&ops) + 10*numWorkers)
+	close(done)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	// The ranges of the other tables must not have been merged.
+	if repl := store.LookupReplica(roachpb.RKey(nextTableStart), nil); !repl.Desc().StartKey.Equal(roachpb.RKey(nextTableStart)) {
+		t.Errorf("range %s was merged across a table boundary", repl)
+	}
+	// Every replica of the merged range must have applied all writes.
+	for i, key := range counterKeys {
+		kv, err := store.DB().Get(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := kv.ValueInt(); v != counts[i] {
+			t.Errorf("%s: expected %d, but found %d", key, counts[i], v)
+		}
+		testutils.SucceedsSoon(t, func() error {
+			if values := mtc.readIntFromEngines(key)[:3]; !reflect.DeepEqual(values, []int64{counts[i], counts[i], counts[i]}) {
+				return errors.Errorf("%s: expected %d on all replicas, but found %v", key, counts[i], values)
+			}
+			return nil
+		})
+	}
+}
+
 func BenchmarkStoreRangeMerge(b *testing.B) {
 	storeCfg := storage.TestStoreConfig(nil)
 	storeCfg.TestingKnobs.DisableSplitQueue = true
@@ -33,12 +33,14 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/testcluster"
 	"github.com/cockroachdb/cockroach/pkg/util/caller"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -1594,3 +1596,72 @@ func TestSystemZoneConfigs(t *testing.T) {
 	expectedReplicas += 8
 	testutils.SucceedsSoon(t, waitForReplicas)
 }
+
+// TestFollowerReadsAsOfSystemTime verifies that historical reads issued
+// through SQL on a node which holds a follower replica of the data are
+// eventually served by that replica instead of the lease holder.
+func TestFollowerReadsAsOfSystemTime(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	tc := testcluster.StartTestCluster(t, 3,
+		base.TestClusterArgs{
+			ReplicationMode: base.ReplicationManual,
+		})
+	defer tc.Stopper().Stop(context.TODO())
+
+	db0 := sqlutils.MakeSQLRunner(t, tc.ServerConn(0))
+	db0.Exec(`SET CLUSTER SETTING kv.closed_timestamp.follower_reads_enabled = true`)
+	db0.Exec(`SET CLUSTER SETTING kv.closed_timestamp.target_duration = '100ms'`)
+	db0.Exec(`CREATE DATABASE d`)
+	db0.Exec(`CREATE TABLE d.t (k INT PRIMARY KEY, v INT)`)
+
+	// Give the table its own range, with the lease on server 0 and followers
+	// on the other servers.
+	desc := sqlbase.GetTableDescriptor(tc.Server(0).DB(), "d", "t")
+	tableKey := roachpb.Key(keys.MakeTablePrefix(uint32(desc.ID)))
+	if _, _, err := tc.SplitRange(tableKey); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tc.AddReplicas(tableKey, tc.Target(1), tc.Target(2)); err != nil {
+		t.Fatal(err)
+	}
+
+	db0.Exec(`INSERT INTO d.t VALUES (1, 2)`)
+	var ts string
+	db0.QueryRow(`SELECT cluster_logical_timestamp()`).Scan(&ts)
+
+	store, err := tc.Server(2).GetStores().(*storage.Stores).GetStore(tc.Server(2).GetFirstStoreID())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A consistent read at the current time cannot be served by a follower.
+	get := getArgs(tableKey)
+	_, pErr := client.SendWrappedWith(context.Background(), store, roachpb.Header{
+		RangeID:   store.LookupReplica(roachpb.RKey(tableKey), nil).RangeID,
+		Timestamp: tc.Server(2).Clock().Now(),
+	}, get)
+	if _, ok := pErr.GetDetail().(*roachpb.NotLeaseHolderError); !ok {
+		t.Fatalf("expected a NotLeaseHolderError, but found %v", pErr)
+	}
+
+	// Run the query on server 2 without DistSQL, so that the scan is issued
+	// by its own DistSender, which sends it to the local replica first. The
+	// first attempts may be redirected to the lease holder until the closed
+	// timestamp of the range has advanced past the read timestamp.
+	conn := tc.ServerConn(2)
+	conn.SetMaxOpenConns(1)
+	db2 := sqlutils.MakeSQLRunner(t, conn)
+	db2.Exec(`SET distsql = off`)
+	query := fmt.Sprintf(`SELECT v FROM d.t AS OF SYSTEM TIME %s WHERE k = 1`, ts)
+	testutils.SucceedsSoon(t, func() error {
+		var v int
+		db2.QueryRow(query).Scan(&v)
+		if v != 2 {
+			t.Fatalf("expected 2, but found %d", v)
+		}
+		if n := store.Metrics().FollowerReadsCount.Count(); n == 0 {
+			return errors.New("read not yet served by the follower")
+		}
+		return nil
+	})
+}
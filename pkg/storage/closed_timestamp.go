@@ -0,0 +1,225 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// closedTimestampProposalInterval is the minimum interval between the empty
+// commands proposed by a lease holder to advance the closed timestamp of a
+// range which receives historical reads but no writes.
+const closedTimestampProposalInterval = time.Second
+
+// closedTimestampFloor is the closed timestamp promised by a lease holder
+// when a number of writes which are still in flight started evaluating.
+type closedTimestampFloor struct {
+	ts   hlc.Timestamp
+	refs int
+}
+
+// closedTimestampTracker tracks the closed timestamp of a range on its lease
+// holder. A timestamp is closed once the lease holder has promised not to
+// accept any more writes at or below it.
+//
+// The closed timestamp is published to the followers as part of the Raft
+// commands proposed by the lease holder. The timestamp published with a
+// command must not exceed the timestamp of any write which is proposed
+// after it. Writes are therefore tracked from the time they are forced
+// above the current promise until they are done, and a command only
+// publishes the lowest promise any tracked write started under.
+type closedTimestampTracker struct {
+	mu struct {
+		syncutil.Mutex
+		// closed is the promise: writes which start evaluating are forced
+		// above it.
+		closed hlc.Timestamp
+		// inflight holds the promises under which the writes in flight
+		// started, in increasing order, along with the number of writes.
+		inflight []closedTimestampFloor
+		// lastProposal is the time at which the last empty command was
+		// proposed to advance the closed timestamp.
+		lastProposal time.Time
+	}
+}
+
+// track registers a write which is about to be evaluated. It returns the
+// timestamp the write must be forced above and a function which must be
+// called once the write is done, whether it applied or not.
+func (t *closedTimestampTracker) track() (hlc.Timestamp, func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	floor := t.mu.closed
+	if n := len(t.mu.inflight); n > 0 && t.mu.inflight[n-1].ts == floor {
+		t.mu.inflight[n-1].refs++
+	} else {
+		t.mu.inflight = append(t.mu.inflight, closedTimestampFloor{ts: floor, refs: 1})
+	}
+	return floor, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		for i := range t.mu.inflight {
+			if t.mu.inflight[i].ts == floor {
+				t.mu.inflight[i].refs--
+				break
+			}
+		}
+		for len(t.mu.inflight) > 0 && t.mu.inflight[0].refs == 0 {
+			t.mu.inflight = t.mu.inflight[1:]
+		}
+	}
+}
+
+// close advances the promise to the given target and returns the closed
+// timestamp which may be published with the command being proposed.
+func (t *closedTimestampTracker) close(target hlc.Timestamp) hlc.Timestamp {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.mu.closed.Forward(target)
+	if len(t.mu.inflight) > 0 {
+		return t.mu.inflight[0].ts
+	}
+	return t.mu.closed
+}
+
+// forward advances the promise to the given timestamp. It is used when
+// the range absorbs the keyspace of another range which has closed the
+// timestamp.
+func (t *closedTimestampTracker) forward(ts hlc.Timestamp) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.mu.closed.Forward(ts)
+}
+
+// shouldPropose returns true if the lease holder should propose an empty
+// command to advance the closed timestamp, and rate limits such commands.
+func (t *closedTimestampTracker) shouldPropose(now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if now.Sub(t.mu.lastProposal) < closedTimestampProposalInterval {
+		return false
+	}
+	t.mu.lastProposal = now
+	return true
+}
+
+// closedTimestampTarget returns the timestamp up to which the lease holder
+// closes timestamps, or the zero timestamp if it does not.
+func (r *Replica) closedTimestampTarget() hlc.Timestamp {
+	return storagebase.ClosedTimestampTarget(&r.store.ClusterSettings().SV, r.store.Clock().Now())
+}
+
+// forwardToClosedTimestamp forces the write timestamp of the batch above
+// the given closed timestamp, in the same way the timestamp cache forces it
+// above the timestamps of previous reads. It returns whether the timestamp
+// was moved forward.
+func forwardToClosedTimestamp(ba *roachpb.BatchRequest, closed hlc.Timestamp) bool {
+	if ba.Txn != nil {
+		if !closed.Less(ba.Txn.Timestamp) {
+			txn := ba.Txn.Clone()
+			txn.Timestamp.Forward(closed.Next())
+			ba.Txn = &txn
+			return true
+		}
+		return false
+	}
+	return ba.Timestamp.Forward(closed.Next())
+}
+
+// canServeFollowerRead returns true if the replica can serve the batch
+// without holding the lease because all of the values the batch may
+// observe are at or below the closed timestamp of the range. If the batch
+// could have been served by a follower but the closed timestamp is too
+// low, the reason is returned instead.
+func (r *Replica) canServeFollowerRead(ba roachpb.BatchRequest) (bool, string) {
+	if !storagebase.FollowerReadsEnabled.Get(&r.store.ClusterSettings().SV) {
+		return false, ""
+	}
+	ts, ok := storagebase.FollowerReadTimestamp(ba)
+	if !ok {
+		return false, ""
+	}
+	r.mu.RLock()
+	closed := r.mu.closedTimestamp
+	r.mu.RUnlock()
+	if closed.Less(ts) {
+		return false, fmt.Sprintf("r%d: follower read at %s not possible: closed timestamp is %s",
+			r.RangeID, ts, closed)
+	}
+	return true, ""
+}
+
+// maybeProposeClosedTimestamp is called on the lease holder when it serves
+// a read which could have been served by a follower if the closed timestamp
+// had been high enough. It proposes an empty command publishing the current
+// closed timestamp: without it, the closed timestamp of a range only
+// advances with its writes, and historical reads of a range without writes
+// would never be served by followers.
+func (r *Replica) maybeProposeClosedTimestamp(ctx context.Context, ba roachpb.BatchRequest) {
+	ts, _ := storagebase.FollowerReadTimestamp(ba)
+	if r.closedTimestampTarget().Less(ts) ||
+		!r.closedTS.shouldPropose(r.store.Clock().PhysicalTime()) {
+		return
+	}
+
+	r.raftMu.Lock()
+	defer r.raftMu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.mu.destroyed != nil {
+		return
+	}
+	now := r.store.Clock().Now()
+	lease := *r.mu.state.Lease
+	if !lease.OwnedBy(r.store.StoreID()) || !r.isLeaseValidRLocked(lease, now) {
+		return
+	}
+	repDesc, err := r.getReplicaDescriptorRLocked()
+	if err != nil {
+		return
+	}
+	desc := r.mu.state.Desc
+	proposal := &ProposalData{
+		ctx:     r.AnnotateCtx(context.Background()),
+		idKey:   makeIDKey(),
+		doneCh:  make(chan proposalResult, 1),
+		Local:   &LocalEvalResult{Reply: &roachpb.BatchResponse{}},
+		Request: &roachpb.BatchRequest{},
+		command: storagebase.RaftCommand{
+			ReplicatedEvalResult: storagebase.ReplicatedEvalResult{
+				Timestamp: now,
+				StartKey:  desc.StartKey,
+				EndKey:    desc.StartKey.Next(),
+			},
+		},
+	}
+	r.insertProposalLocked(proposal, repDesc, lease)
+	if err := r.submitProposalLocked(proposal); err != nil {
+		delete(r.mu.proposals, proposal.idKey)
+		log.VEventf(ctx, 2, "unable to propose closed timestamp: %s", err)
+		return
+	}
+	log.VEventf(ctx, 2, "proposed closed timestamp %s",
+		proposal.command.ReplicatedEvalResult.ClosedTimestamp)
+}
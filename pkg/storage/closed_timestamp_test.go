@@ -0,0 +1,207 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+)
+
+// TestClosedTimestampTracker verifies that the closed timestamp published
+// by the tracker never exceeds the timestamp below which a write in flight
+// was allowed to write.
+func TestClosedTimestampTracker(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ts := func(wallTime int64) hlc.Timestamp { return hlc.Timestamp{WallTime: wallTime} }
+	var tr closedTimestampTracker
+
+	if closed := tr.close(ts(10)); closed != ts(10) {
+		t.Fatalf("expected closed timestamp %s, but found %s", ts(10), closed)
+	}
+
+	// A write which starts now is forced above 10; while it is in flight,
+	// the published closed timestamp stays at 10.
+	floor1, untrack1 := tr.track()
+	if floor1 != ts(10) {
+		t.Fatalf("expected floor %s, but found %s", ts(10), floor1)
+	}
+	if closed := tr.close(ts(20)); closed != ts(10) {
+		t.Fatalf("expected closed timestamp %s, but found %s", ts(10), closed)
+	}
+
+	// Two writes start under the promise of 20.
+	floor2, untrack2 := tr.track()
+	floor3, untrack3 := tr.track()
+	if floor2 != ts(20) || floor3 != ts(20) {
+		t.Fatalf("expected floors %s, but found %s and %s", ts(20), floor2, floor3)
+	}
+	if closed := tr.close(ts(30)); closed != ts(10) {
+		t.Fatalf("expected closed timestamp %s, but found %s", ts(10), closed)
+	}
+
+	untrack1()
+	if closed := tr.close(ts(30)); closed != ts(20) {
+		t.Fatalf("expected closed timestamp %s, but found %s", ts(20), closed)
+	}
+	untrack2()
+	if closed := tr.close(ts(30)); closed != ts(20) {
+		t.Fatalf("expected closed timestamp %s, but found %s", ts(20), closed)
+	}
+	untrack3()
+	if closed := tr.close(ts(30)); closed != ts(30) {
+		t.Fatalf("expected closed timestamp %s, but found %s", ts(30), closed)
+	}
+
+	// The promise never regresses.
+	if closed := tr.close(ts(5)); closed != ts(30) {
+		t.Fatalf("expected closed timestamp %s, but found %s", ts(30), closed)
+	}
+	tr.forward(ts(40))
+	if floor, untrack := tr.track(); floor != ts(40) {
+		t.Fatalf("expected floor %s, but found %s", ts(40), floor)
+	} else {
+		untrack()
+	}
+
+	// Empty commands are rate limited.
+	now := time.Unix(100, 0)
+	if !tr.shouldPropose(now) {
+		t.Fatal("expected a proposal")
+	}
+	if tr.shouldPropose(now.Add(closedTimestampProposalInterval / 2)) {
+		t.Fatal("expected no proposal within the proposal interval")
+	}
+	if !tr.shouldPropose(now.Add(closedTimestampProposalInterval)) {
+		t.Fatal("expected a proposal after the proposal interval")
+	}
+}
+
+// TestForwardToClosedTimestamp verifies that writes are forced strictly
+// above the closed timestamp.
+func TestForwardToClosedTimestamp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	closed := hlc.Timestamp{WallTime: 10}
+	testCases := []struct {
+		ts       hlc.Timestamp
+		txn      bool
+		expTS    hlc.Timestamp
+		expMoved bool
+	}{
+		{hlc.Timestamp{WallTime: 5}, false, closed.Next(), true},
+		{closed, false, closed.Next(), true},
+		{hlc.Timestamp{WallTime: 15}, false, hlc.Timestamp{WallTime: 15}, false},
+		{hlc.Timestamp{WallTime: 5}, true, closed.Next(), true},
+		{closed, true, closed.Next(), true},
+		{hlc.Timestamp{WallTime: 15}, true, hlc.Timestamp{WallTime: 15}, false},
+	}
+	for i, c := range testCases {
+		var ba roachpb.BatchRequest
+		if c.txn {
+			txn := roachpb.MakeTransaction("test", roachpb.Key("a"), 0, enginepb.SERIALIZABLE, c.ts, 0)
+			ba.Txn = &txn
+		} else {
+			ba.Timestamp = c.ts
+		}
+		orig := ba.Txn
+		moved := forwardToClosedTimestamp(&ba, closed)
+		if moved != c.expMoved {
+			t.Errorf("%d: expected moved=%t, but found %t", i, c.expMoved, moved)
+		}
+		ts := ba.Timestamp
+		if c.txn {
+			ts = ba.Txn.Timestamp
+			// The caller's transaction must not be mutated.
+			if moved && orig.Timestamp != c.ts {
+				t.Errorf("%d: original transaction was mutated", i)
+			}
+		}
+		if ts != c.expTS {
+			t.Errorf("%d: expected timestamp %s, but found %s", i, c.expTS, ts)
+		}
+	}
+}
+
+// TestReplicaCanServeFollowerRead verifies that a replica only serves
+// consistent reads at or below its closed timestamp without the lease, and
+// that it explains why it cannot serve the reads above it.
+func TestReplicaCanServeFollowerRead(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	tc := testContext{}
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	tc.Start(t, stopper)
+
+	closed := hlc.Timestamp{WallTime: 10}
+	tc.repl.mu.Lock()
+	tc.repl.mu.closedTimestamp = closed
+	tc.repl.mu.Unlock()
+
+	key := roachpb.Key("a")
+	batch := func(args roachpb.Request, h roachpb.Header) roachpb.BatchRequest {
+		ba := roachpb.BatchRequest{Header: h}
+		ba.Add(args)
+		return ba
+	}
+	get := &roachpb.GetRequest{Span: roachpb.Span{Key: key}}
+	put := &roachpb.PutRequest{Span: roachpb.Span{Key: key}}
+	txn := roachpb.MakeTransaction("test", key, 0, enginepb.SERIALIZABLE, hlc.Timestamp{WallTime: 5}, 0)
+	txn.MaxTimestamp = hlc.Timestamp{WallTime: 15}
+
+	testCases := []struct {
+		ba        roachpb.BatchRequest
+		exp       bool
+		expReason string
+	}{
+		{batch(get, roachpb.Header{Timestamp: hlc.Timestamp{WallTime: 5}}), true, ""},
+		{batch(get, roachpb.Header{Timestamp: closed}), true, ""},
+		{batch(get, roachpb.Header{Timestamp: closed.Next()}), false, "follower read at"},
+		// A transaction may observe values up to its max timestamp.
+		{batch(get, roachpb.Header{Timestamp: txn.Timestamp, Txn: &txn}), false, "follower read at"},
+		{batch(get, roachpb.Header{Timestamp: closed, ReadConsistency: roachpb.INCONSISTENT}), false, ""},
+		{batch(put, roachpb.Header{Timestamp: closed}), false, ""},
+	}
+
+	for i, c := range testCases {
+		if ok, reason := tc.repl.canServeFollowerRead(c.ba); ok || reason != "" {
+			t.Errorf("%d: expected no follower read with follower reads disabled, but found %t, %q", i, ok, reason)
+		}
+	}
+	storagebase.FollowerReadsEnabled.Override(&tc.store.ClusterSettings().SV, true)
+	for i, c := range testCases {
+		ok, reason := tc.repl.canServeFollowerRead(c.ba)
+		if ok != c.exp {
+			t.Errorf("%d: expected %t, but found %t", i, c.exp, ok)
+		}
+		if c.expReason == "" {
+			if reason != "" {
+				t.Errorf("%d: expected no reason, but found %q", i, reason)
+			}
+		} else if !strings.Contains(reason, c.expReason) {
+			t.Errorf("%d: expected reason containing %q, but found %q", i, c.expReason, reason)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/gossip"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+const (
+	// mergeQueueTimerDuration is the duration between merges of queued ranges.
+	mergeQueueTimerDuration = 5 * time.Second
+)
+
+// mergeQueueEnabled controls whether the merge queue merges small ranges.
+// It is off by default: ranges split manually (e.g. with ALTER TABLE ...
+// SPLIT AT) are otherwise merged away as soon as they are found to be
+// small.
+var mergeQueueEnabled = settings.RegisterBoolSetting(
+	"kv.range_merge.queue_enabled",
+	"whether the automatic merge queue is enabled",
+	false,
+)
+
+// mergeQueue manages a queue of ranges slated to be merged with their
+// right-hand neighbor because their combined size is below the minimum
+// size of their zone.
+//
+// The queue processes the left-hand side of a merge. The right-hand side
+// need not have a replica on this store: it is looked up in the range
+// addressing records, and its replicas are relocated onto the stores of
+// the left-hand side, which is a requirement of AdminMerge. AdminMerge
+// freezes the right-hand side while the merge is in progress, so ranges
+// can be merged while they serve traffic.
+type mergeQueue struct {
+	*baseQueue
+	db *client.DB
+}
+
+// newMergeQueue returns a new instance of mergeQueue.
+func newMergeQueue(store *Store, db *client.DB, gossip *gossip.Gossip) *mergeQueue {
+	mq := &mergeQueue{
+		db: db,
+	}
+	mq.baseQueue = newBaseQueue(
+		"merge", mq, store, gossip,
+		queueConfig{
+			maxSize:              defaultQueueMaxSize,
+			needsLease:           true,
+			needsSystemConfig:    true,
+			acceptsUnsplitRanges: false,
+			successes:            store.metrics.MergeQueueSuccesses,
+			failures:             store.metrics.MergeQueueFailures,
+			pending:              store.metrics.MergeQueuePending,
+			processingNanos:      store.metrics.MergeQueueProcessingNanos,
+		},
+	)
+	return mq
+}
+
+// shouldQueue determines whether a range should be queued for merging
+// with its right-hand neighbor. This is the case if the range is below
+// the minimum size of its zone and no zone or table boundary lies at its
+// end key. Smaller ranges have higher priority. The size of the
+// right-hand neighbor is only checked when the range is processed.
+func (mq *mergeQueue) shouldQueue(
+	ctx context.Context, now hlc.Timestamp, repl *Replica, sysCfg config.SystemConfig,
+) (shouldQ bool, priority float64) {
+	if !mergeQueueEnabled.Get(&mq.store.ClusterSettings().SV) {
+		return false, 0
+	}
+	desc := repl.Desc()
+	if desc.EndKey.Equal(roachpb.RKeyMax) {
+		return false, 0
+	}
+	if sysCfg.NeedsSplit(desc.StartKey, desc.EndKey.Next()) {
+		return false, 0
+	}
+	zone, err := sysCfg.GetZoneConfigForKey(desc.StartKey)
+	if err != nil || zone.RangeMinBytes <= 0 {
+		return false, 0
+	}
+	size := repl.GetMVCCStats().Total()
+	if size >= zone.RangeMinBytes {
+		return false, 0
+	}
+	return true, 1 - float64(size)/float64(zone.RangeMinBytes)
+}
+
+// lookupRightHandSide returns the range descriptor and the size of the
+// right-hand neighbor of the range described by lhsDesc. The range
+// addressing records are read consistently.
+func (mq *mergeQueue) lookupRightHandSide(
+	ctx context.Context, lhsDesc *roachpb.RangeDescriptor,
+) (roachpb.RangeDescriptor, int64, error) {
+	b := &client.Batch{}
+	b.AddRawRequest(&roachpb.RangeLookupRequest{
+		Span: roachpb.Span{
+			Key: keys.RangeMetaKey(lhsDesc.EndKey),
+		},
+		MaxRanges: 1,
+	})
+	if err := mq.db.Run(ctx, b); err != nil {
+		return roachpb.RangeDescriptor{}, 0, err
+	}
+	lookupReply := b.RawResponse().Responses[0].GetInner().(*roachpb.RangeLookupResponse)
+	if len(lookupReply.Ranges) != 1 {
+		return roachpb.RangeDescriptor{}, 0,
+			errors.Errorf("expected 1 range descriptor, got %d", len(lookupReply.Ranges))
+	}
+	rhsDesc := lookupReply.Ranges[0]
+
+	b = &client.Batch{}
+	b.AddRawRequest(&roachpb.RangeStatsRequest{
+		Span: roachpb.Span{Key: rhsDesc.StartKey.AsRawKey()},
+	})
+	if err := mq.db.Run(ctx, b); err != nil {
+		return roachpb.RangeDescriptor{}, 0, err
+	}
+	statsReply := b.RawResponse().Responses[0].GetInner().(*roachpb.RangeStatsResponse)
+	return rhsDesc, statsReply.MVCCStats.Total(), nil
+}
+
+// process co-locates the replicas of the right-hand neighbor of the range
+// with those of the range, then merges the two ranges.
+func (mq *mergeQueue) process(ctx context.Context, lhsRepl *Replica, sysCfg config.SystemConfig) error {
+	if shouldQ, _ := mq.shouldQueue(ctx, hlc.Timestamp{}, lhsRepl, sysCfg); !shouldQ {
+		return nil
+	}
+	lhsDesc := lhsRepl.Desc()
+	rhsDesc, rhsSize, err := mq.lookupRightHandSide(ctx, lhsDesc)
+	if err != nil {
+		return err
+	}
+	if !rhsDesc.StartKey.Equal(lhsDesc.EndKey) {
+		// The range or its neighbor changed since the lookup.
+		return nil
+	}
+
+	// A range which would be split along a zone config or table boundary
+	// must not be created by a merge. If no such boundary exists, both
+	// ranges are also governed by the same zone config.
+	if sysCfg.NeedsSplit(lhsDesc.StartKey, rhsDesc.EndKey) {
+		return nil
+	}
+	zone, err := sysCfg.GetZoneConfigForKey(lhsDesc.StartKey)
+	if err != nil {
+		return err
+	}
+	lhsSize := lhsRepl.GetMVCCStats().Total()
+	if lhsSize+rhsSize >= zone.RangeMinBytes {
+		log.VEventf(ctx, 2, "not merging: combined size of %s and r%d is %d bytes",
+			lhsRepl, rhsDesc.RangeID, lhsSize+rhsSize)
+		return nil
+	}
+
+	// Leave ranges which need to be repaired to the replicate queue; it
+	// would undo the co-location otherwise.
+	allocator := &mq.store.allocator
+	for _, info := range []RangeInfo{
+		rangeInfoForRepl(lhsRepl, lhsDesc),
+		{Desc: &rhsDesc, LogicalBytes: rhsSize},
+	} {
+		if action, _ := allocator.ComputeAction(ctx, zone, info); action != AllocatorNoop &&
+			action != AllocatorConsiderRebalance {
+			log.VEventf(ctx, 2, "not merging: r%d needs repair (%s)", info.Desc.RangeID, action)
+			return nil
+		}
+	}
+
+	if !replicaSetsEqual(lhsDesc.Replicas, rhsDesc.Replicas) {
+		var storeIDs roachpb.StoreIDSlice
+		var targets []roachpb.ReplicationTarget
+		for _, r := range lhsDesc.Replicas {
+			storeIDs = append(storeIDs, r.StoreID)
+			targets = append(targets, roachpb.ReplicationTarget{NodeID: r.NodeID, StoreID: r.StoreID})
+		}
+		// Only relocate the right-hand side onto stores which the allocator
+		// considers able to receive a replica.
+		if allocator.storePool == nil {
+			return errors.Errorf("unable to relocate r%d: no store pool", rhsDesc.RangeID)
+		}
+		if sl, _, _ := allocator.storePool.getStoreListFromIDs(
+			storeIDs, rhsDesc.RangeID, storeFilterThrottled,
+		); len(sl.stores) != len(storeIDs) {
+			log.VEventf(ctx, 2, "not merging: not all stores of %s can receive a replica of r%d",
+				lhsRepl, rhsDesc.RangeID)
+			return nil
+		}
+		log.VEventf(ctx, 1, "relocating r%d to %v", rhsDesc.RangeID, targets)
+		if err := relocateRange(ctx, mq.db, rhsDesc, targets); err != nil {
+			return errors.Wrapf(err, "unable to relocate r%d", rhsDesc.RangeID)
+		}
+	}
+
+	log.VEventf(ctx, 1, "merging r%d into %s", rhsDesc.RangeID, lhsRepl)
+	if _, pErr := lhsRepl.AdminMerge(ctx, roachpb.AdminMergeRequest{
+		Span: roachpb.Span{Key: lhsDesc.StartKey.AsRawKey()},
+	}); pErr != nil {
+		return errors.Wrapf(pErr.GoError(), "unable to merge r%d into %s", rhsDesc.RangeID, lhsRepl)
+	}
+	return nil
+}
+
+// timer returns interval between processing successive queued merges.
+func (*mergeQueue) timer(_ time.Duration) time.Duration {
+	return mergeQueueTimerDuration
+}
+
+// purgatoryChan returns nil.
+func (*mergeQueue) purgatoryChan() <-chan struct{} {
+	return nil
+}
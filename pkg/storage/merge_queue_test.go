@@ -0,0 +1,107 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"math"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+)
+
+// TestMergeQueueShouldQueue verifies that shouldQueue only accepts ranges
+// below the minimum range size of their zone which are not separated from
+// their right-hand neighbor by a table boundary.
+func TestMergeQueueShouldQueue(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	tc := testContext{}
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	tc.Start(t, stopper)
+
+	config.TestingSetZoneConfig(2000, config.ZoneConfig{RangeMinBytes: 1 << 20, RangeMaxBytes: 64 << 20})
+	config.TestingSetZoneConfig(2001, config.ZoneConfig{RangeMinBytes: 1 << 20, RangeMaxBytes: 64 << 20})
+
+	tableStart := roachpb.RKey(keys.MakeTablePrefix(2000))
+	tableMid := roachpb.RKey(append(keys.MakeTablePrefix(2000), 'b'))
+	nextTableStart := roachpb.RKey(keys.MakeTablePrefix(2001))
+	splitTestRange(tc.store, roachpb.RKeyMin, tableStart, t)
+	splitTestRange(tc.store, tableStart, tableMid, t)
+	last := splitTestRange(tc.store, tableMid, nextTableStart, t)
+	lhs := tc.store.LookupReplica(tableStart, nil)
+	rhs := tc.store.LookupReplica(tableMid, nil)
+
+	setBytes := func(repl *Replica, bytes int64) {
+		repl.mu.Lock()
+		repl.mu.state.Stats = enginepb.MVCCStats{KeyBytes: bytes}
+		repl.mu.Unlock()
+	}
+
+	mergeQ := newMergeQueue(tc.store, nil, tc.gossip)
+
+	cfg, ok := tc.gossip.GetSystemConfig()
+	if !ok {
+		t.Fatal("config not set")
+	}
+
+	testCases := []struct {
+		enabled  bool
+		repl     *Replica
+		bytes    int64
+		shouldQ  bool
+		priority float64
+	}{
+		// Merge queue disabled.
+		{false, lhs, 0, false, 0},
+		// Empty range.
+		{true, lhs, 0, true, 1},
+		// Size at half the minimum.
+		{true, lhs, 512 << 10, true, 0.5},
+		// Size at the minimum.
+		{true, lhs, 1 << 20, false, 0},
+		// Size above the minimum.
+		{true, lhs, 2 << 20, false, 0},
+		// Table boundary between the range and its neighbor.
+		{true, rhs, 0, false, 0},
+		// Final range.
+		{true, last, 0, false, 0},
+	}
+
+	for i, test := range testCases {
+		mergeQueueEnabled.Override(&tc.store.ClusterSettings().SV, test.enabled)
+		setBytes(test.repl, test.bytes)
+
+		shouldQ, priority := mergeQ.shouldQueue(context.TODO(), hlc.Timestamp{}, test.repl, cfg)
+		if shouldQ != test.shouldQ {
+			t.Errorf("%d: should queue expected %t; got %t", i, test.shouldQ, shouldQ)
+		}
+		if math.Abs(priority-test.priority) > 0.00001 {
+			t.Errorf("%d: priority expected %f; got %f", i, test.priority, priority)
+		}
+	}
+}
+
+////
+// NOTE: tests which actually verify merging of ranges are in
+// client_merge_test.go, which is in a different test package in order to
+// allow for distributed transactions with a proper client.
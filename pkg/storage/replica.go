@@ -51,6 +51,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
@@ -234,6 +235,12 @@ type Replica struct {
 	// writeStats tracks the number of keys written by applied raft commands
 	// in order to aid in replica rebalancing decisions.
 	writeStats *replicaStats
+	// loadSplitter samples the incoming BatchRequests while the replica
+	// receives enough of them to be split by load.
+	loadSplitter loadSplitter
+	// closedTS tracks the closed timestamp of the range while the replica
+	// holds the lease.
+	closedTS closedTimestampTracker
 
 	// creatingReplica is set when a replica is created as uninitialized
 	// via a raft message.
@@ -340,6 +347,15 @@ type Replica struct {
 		// lease extension that were in flight at the time of the transfer cannot be
 		// used, if they eventually apply.
 		minLeaseProposedTS hlc.Timestamp
+		// mergeComplete is non-nil while the range is frozen for a merge with
+		// its left-hand neighbor (see maybeWatchForMerge). It is closed when
+		// the merge transaction aborts or when the merge applies and the
+		// replica is destroyed. Commands wait for it before they evaluate.
+		mergeComplete chan struct{}
+		// closedTimestamp is the highest closed timestamp published by a lease
+		// holder in the commands applied so far. Consistent reads at or below
+		// it may be served without holding the lease.
+		closedTimestamp hlc.Timestamp
 		// Max bytes before split.
 		maxBytes int64
 		// proposals stores the Raft in-flight commands which
@@ -1741,6 +1757,9 @@ func (r *Replica) Send(
 	if r.leaseholderStats != nil && ba.Header.GatewayNodeID != 0 {
 		r.leaseholderStats.record(ba.Header.GatewayNodeID)
 	}
+	if r.leaseholderStats != nil && splitByLoadEnabled.Get(&r.store.ClusterSettings().SV) {
+		r.recordLoadForSplit(ba)
+	}
 
 	if err := r.checkBatchRequest(ba); err != nil {
 		return nil, roachpb.NewError(err)
@@ -2145,6 +2164,24 @@ func (r *Replica) beginCmds(
 		if fn := r.store.cfg.TestingKnobs.OnCommandQueueAction; fn != nil {
 			fn(ba, storagebase.CommandQueueBeginExecuting)
 		}
+
+		// A range that is frozen for a merge serves no commands other than
+		// the Subsume which froze it and lease requests (a new lease holder
+		// freezes itself again, see leasePostApply). Release the command queue
+		// so as not to block the merge, wait for it to complete and try again.
+		if _, isTransfer := ba.GetArg(roachpb.TransferLease); !isTransfer &&
+			!ba.IsLeaseRequest() && !ba.IsSingleSubsumeRequest() {
+			r.mu.RLock()
+			mergeInProgress := r.mu.mergeComplete != nil
+			r.mu.RUnlock()
+			if mergeInProgress {
+				r.removeCmdsFromCommandQueue(newCmds)
+				if err := r.maybeWaitForMerge(ctx); err != nil {
+					return nil, err
+				}
+				return r.beginCmds(ctx, ba, spans)
+			}
+		}
 	} else {
 		log.Event(ctx, "operation accepts inconsistent results")
 	}
@@ -2312,6 +2349,13 @@ func (r *Replica) executeAdminBatch(
 	if pErr != nil {
 		return nil, pErr
 	}
+	// Admin commands must not run while the range is frozen for a merge, with
+	// the exception of lease transfers.
+	if _, ok := args.(*roachpb.AdminTransferLeaseRequest); !ok {
+		if err := r.maybeWaitForMerge(ctx); err != nil {
+			return nil, roachpb.NewError(err)
+		}
+	}
 
 	var resp roachpb.Response
 	switch tArgs := args.(type) {
@@ -2380,10 +2424,21 @@ func (r *Replica) executeAdminBatch(
 func (r *Replica) executeReadOnlyBatch(
 	ctx context.Context, ba roachpb.BatchRequest,
 ) (br *roachpb.BatchResponse, pErr *roachpb.Error) {
-	// If the read is consistent, the read requires the range lease.
+	// If the read is consistent, the read requires the range lease, unless
+	// it can be served below the closed timestamp of the range.
 	if ba.ReadConsistency != roachpb.INCONSISTENT {
-		if _, pErr = r.redirectOnOrAcquireLease(ctx); pErr != nil {
+		if ok, fallbackReason := r.canServeFollowerRead(ba); ok {
+			log.Event(ctx, "serving follower read")
+			r.store.metrics.FollowerReadsCount.Inc(1)
+		} else if _, pErr = r.redirectOnOrAcquireLease(ctx); pErr != nil {
+			if nlhe, ok := pErr.GetDetail().(*roachpb.NotLeaseHolderError); ok && fallbackReason != "" {
+				// Let the client know why it has to fall back to the lease holder.
+				nlhe.CustomMsg = fallbackReason
+				pErr = roachpb.NewError(nlhe)
+			}
 			return nil, pErr
+		} else if fallbackReason != "" {
+			r.maybeProposeClosedTimestamp(ctx, ba)
 		}
 	}
 
@@ -2434,6 +2489,13 @@ func (r *Replica) executeReadOnlyBatch(
 	defer readOnly.Close()
 	br, result, pErr = evaluateBatch(ctx, storagebase.CmdIDKey(""), readOnly, rec, nil, ba)
 
+	if pErr == nil && ba.IsSingleSubsumeRequest() {
+		// Freeze the range before the command queue is released.
+		if err := r.maybeWatchForMerge(ctx); err != nil {
+			pErr = roachpb.NewError(err)
+		}
+	}
+
 	if intents := result.Local.detachIntents(pErr != nil); len(intents) > 0 {
 		log.Eventf(ctx, "submitting %d intents to asynchronous processing", len(intents))
 		// Do not allow synchronous intent resolution for RangeLookup requests as
@@ -2573,6 +2635,7 @@ func (r *Replica) tryExecuteWriteBatch(
 	}()
 
 	var lease roachpb.Lease
+	var closed hlc.Timestamp
 	// For lease commands, use the provided previous lease for verification.
 	if ba.IsSingleSkipLeaseCheckRequest() {
 		lease = ba.GetPrevLeaseForLeaseRequest()
@@ -2584,6 +2647,12 @@ func (r *Replica) tryExecuteWriteBatch(
 			return nil, pErr, proposalNoRetry
 		}
 		lease = status.lease
+
+		// The write must not be proposed below the closed timestamp, which
+		// may not advance past it until the write is done.
+		var untrack func()
+		closed, untrack = r.closedTS.track()
+		defer untrack()
 	}
 
 	// Examine the read and write timestamp caches for preceding
@@ -2592,7 +2661,7 @@ func (r *Replica) tryExecuteWriteBatch(
 	// timestamp and possible write-too-old bool.
 	if bumped, pErr := r.applyTimestampCache(&ba); pErr != nil {
 		return nil, pErr, proposalNoRetry
-	} else if bumped {
+	} else if forwardToClosedTimestamp(&ba, closed) || bumped {
 		// If we bump the transaction's timestamp, we must absolutely
 		// tell the client in a response transaction (for otherwise it
 		// doesn't know about the incremented timestamp). Response
@@ -2803,6 +2872,9 @@ func (r *Replica) insertProposalLocked(
 	}
 	if !proposal.Request.IsLeaseRequest() {
 		r.mu.lastAssignedLeaseIndex++
+		// Lease requests are not proposed by the lease holder, which is the
+		// only replica to close timestamps.
+		proposal.command.ReplicatedEvalResult.ClosedTimestamp = r.closedTS.close(r.closedTimestampTarget())
 	}
 	proposal.command.MaxLeaseIndex = r.mu.lastAssignedLeaseIndex
 	proposal.command.ProposerReplica = proposerReplica
@@ -5567,6 +5639,160 @@ func (r *Replica) QueriesPerSecond() float64 {
 	return qps
 }
 
+// recordLoadForSplit records the request for the purpose of load-based
+// splitting, and queues the range for splitting once a split key is
+// available.
+func (r *Replica) recordLoadForSplit(ba roachpb.BatchRequest) {
+	threshold := float64(splitByLoadQPSThreshold.Get(&r.store.ClusterSettings().SV))
+	qpsFn := func() float64 {
+		qps, dur := r.leaseholderStats.avgQPS()
+		if dur < MinStatsDuration {
+			return 0
+		}
+		return qps
+	}
+	spanFn := func() roachpb.RSpan {
+		span, err := keys.Range(ba)
+		if err != nil {
+			return roachpb.RSpan{}
+		}
+		return span
+	}
+	if r.loadSplitter.record(r.store.Clock().PhysicalTime(), threshold, qpsFn, spanFn) &&
+		r.store.splitQueue != nil {
+		r.store.splitQueue.MaybeAdd(r, r.store.Clock().Now())
+	}
+}
+
+// loadSplitKey returns the key at which the range should be split to divide
+// its load, or nil if its load does not warrant a split.
+func (r *Replica) loadSplitKey(now time.Time) roachpb.Key {
+	if !splitByLoadEnabled.Get(&r.store.ClusterSettings().SV) {
+		return nil
+	}
+	splitKey := r.loadSplitter.splitKey(now)
+	if splitKey == nil {
+		return nil
+	}
+	// Avoid splitting in the middle of a SQL row.
+	key, err := keys.EnsureSafeSplitKey(splitKey.AsRawKey())
+	if err != nil {
+		return nil
+	}
+	desc := r.Desc()
+	if rKey := roachpb.RKey(key); !desc.ContainsKey(rKey) || rKey.Equal(desc.StartKey) {
+		return nil
+	}
+	return key
+}
+
+// maybeWatchForMerge checks whether the range is being subsumed by its
+// left-hand neighbor, which is the case if its range descriptor is covered by
+// a deletion intent. If so, the replica is frozen until the merge transaction
+// is finalized: if the merge aborts, the replica resumes serving commands; if
+// it commits, the freeze lasts until the replica is destroyed when the merge
+// applies. It is called on the lease holder when Subsume is evaluated and
+// whenever a lease is applied, as a new lease holder must honor the freeze
+// of its predecessor.
+func (r *Replica) maybeWatchForMerge(ctx context.Context) error {
+	desc := r.Desc()
+	descKey := keys.RangeDescriptorKey(desc.StartKey)
+	_, intents, err := engine.MVCCGet(ctx, r.store.Engine(), descKey, hlc.MaxTimestamp,
+		false /* consistent */, nil /* txn */)
+	if err != nil {
+		return err
+	} else if len(intents) != 1 {
+		return nil
+	}
+	intent := intents[0]
+	val, _, err := engine.MVCCGetAsTxn(ctx, r.store.Engine(), descKey, intent.Txn.Timestamp, intent.Txn)
+	if err != nil {
+		return err
+	} else if val != nil {
+		// The intent belongs to a split or replica change.
+		return nil
+	}
+
+	r.mu.Lock()
+	if r.mu.mergeComplete != nil {
+		// Another goroutine is already watching the merge.
+		r.mu.Unlock()
+		return nil
+	}
+	mergeCompleteCh := make(chan struct{})
+	r.mu.mergeComplete = mergeCompleteCh
+	r.mu.Unlock()
+	log.Infof(ctx, "range frozen for merge by txn %s", intent.Txn.ID.Short())
+
+	taskCtx := r.AnnotateCtx(context.Background())
+	return r.store.stopper.RunAsyncTask(taskCtx, "wait-for-merge", func(ctx context.Context) {
+		for retry := retry.StartWithCtx(ctx, base.DefaultRetryOptions()); retry.Next(); {
+			// Wait for the merge transaction to be finalized. A PUSH_ABORT with
+			// the lowest possible priority waits in the push txn queue of the
+			// transaction record until the transaction commits or aborts, unless
+			// the transaction has been abandoned.
+			b := &client.Batch{}
+			b.AddRawRequest(&roachpb.PushTxnRequest{
+				Span: roachpb.Span{Key: intent.Txn.Key},
+				PusherTxn: roachpb.Transaction{
+					TxnMeta: enginepb.TxnMeta{Priority: roachpb.MinTxnPriority},
+				},
+				PusheeTxn: intent.Txn,
+				Now:       r.store.Clock().Now(),
+				PushType:  roachpb.PUSH_ABORT,
+			})
+			if err := r.store.DB().Run(ctx, b); err != nil {
+				log.Warningf(ctx, "error pushing merge txn %s: %s", intent.Txn.ID.Short(), err)
+				continue
+			}
+
+			// The transaction record may have been removed after a successful
+			// merge, in which case the push reports the transaction as aborted.
+			// The meta addressing record of this range tells the outcomes apart:
+			// it only survives if the merge aborted.
+			var meta roachpb.RangeDescriptor
+			if err := r.store.DB().GetProto(ctx, keys.RangeMetaKey(desc.EndKey), &meta); err != nil {
+				log.Warningf(ctx, "error looking up range descriptor for %s: %s", desc, err)
+				continue
+			}
+			if meta.RangeID == desc.RangeID {
+				log.Infof(ctx, "merge txn %s aborted; unfreezing range", intent.Txn.ID.Short())
+				r.mu.Lock()
+				if r.mu.mergeComplete == mergeCompleteCh {
+					r.mu.mergeComplete = nil
+					close(mergeCompleteCh)
+				}
+				r.mu.Unlock()
+			}
+			// Otherwise the merge committed. Store.removeReplicaImpl closes the
+			// channel once the merge has applied to this replica.
+			return
+		}
+	})
+}
+
+// maybeWaitForMerge blocks while the replica is frozen for a merge. It
+// returns an error if the replica was destroyed by the merge, in which case
+// the command must be redirected to the subsuming range, or if the context
+// was canceled.
+func (r *Replica) maybeWaitForMerge(ctx context.Context) error {
+	r.mu.RLock()
+	mergeCompleteCh := r.mu.mergeComplete
+	r.mu.RUnlock()
+	if mergeCompleteCh == nil {
+		return nil
+	}
+	log.Event(ctx, "waiting on in-progress merge")
+	select {
+	case <-mergeCompleteCh:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-r.store.stopper.ShouldQuiesce():
+		return &roachpb.NodeUnavailableError{}
+	}
+	return r.IsDestroyed()
+}
+
 // WritesPerSecond returns the range's average keys written per second.
 func (r *Replica) WritesPerSecond() float64 {
 	wps, _ := r.writeStats.avgQPS()
@@ -143,6 +143,8 @@ var commands = map[roachpb.Method]Command{
 	roachpb.WriteBatch:         writeBatchCmd,
 	roachpb.Export:             exportCmd,
 	roachpb.AddSSTable:         addSSTableCmd,
+	roachpb.Subsume:            {DeclareKeys: declareKeysSubsume, Eval: evalSubsume},
+	roachpb.RangeStats:         {DeclareKeys: declareKeysRangeStats, Eval: evalRangeStats},
 
 	roachpb.DeprecatedVerifyChecksum: {
 		DeclareKeys: DefaultDeclareKeys,
@@ -3196,6 +3198,13 @@ func splitTrigger(
 // merge requires that the two ranges are collocated on the same set
 // of replicas.
 //
+// Before the transaction commits, the right hand side is frozen by a
+// Subsume request: its lease holder stops serving commands until the
+// transaction is finalized. AdminMerge then waits until every replica of
+// the right hand side has applied all commands evaluated before the freeze,
+// so that no replica can apply the merge trigger while it is missing writes
+// to the subsumed range.
+//
 // The supplied RangeDescriptor is used as a form of optimistic lock. See the
 // comment of "AdminSplit" for more information on this pattern.
 func (r *Replica) AdminMerge(
@@ -3209,28 +3218,33 @@ func (r *Replica) AdminMerge(
 		return reply, roachpb.NewErrorf("cannot merge final range")
 	}
 
-	updatedLeftDesc := *origLeftDesc
+	if err := r.store.DB().Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		log.Event(ctx, "merge closure begins")
+		txn.SetDebugName(mergeTxnName)
 
-	// Lookup right hand side range (subsumed). This really belongs
-	// inside the transaction for consistency, but it is important (for
-	// transaction record placement) that the first action inside the
-	// transaction is the conditional put to change the left hand side's
-	// descriptor end key. We look up the descriptor here only to get
-	// the new end key and then repeat the lookup inside the
-	// transaction.
-	{
-		rightRng := r.store.LookupReplica(origLeftDesc.EndKey, nil)
-		if rightRng == nil {
-			return reply, roachpb.NewErrorf("ranges not collocated")
+		// Do a consistent read of the right hand side's range descriptor. Reads
+		// do not anchor the transaction record, which is created on the left
+		// hand side along with the first write below (our triggers rely on
+		// this).
+		rightDescKey := keys.RangeDescriptorKey(origLeftDesc.EndKey)
+		var rightDesc roachpb.RangeDescriptor
+		if err := txn.GetProto(ctx, rightDescKey, &rightDesc); err != nil {
+			return err
 		}
 
-		updatedLeftDesc.EndKey = rightRng.Desc().EndKey
-		log.Infof(ctx, "initiating a merge of %s into this range", rightRng)
-	}
+		// Verify that the two ranges are mergeable.
+		if !bytes.Equal(origLeftDesc.EndKey, rightDesc.StartKey) {
+			// This merge raced with a split or merge of the right-hand range.
+			return errors.Errorf("ranges are not adjacent; %s != %s", origLeftDesc.EndKey, rightDesc.StartKey)
+		}
+		if !replicaSetsEqual(origLeftDesc.Replicas, rightDesc.Replicas) {
+			return errors.Errorf("ranges not collocated")
+		}
+
+		updatedLeftDesc := *origLeftDesc
+		updatedLeftDesc.EndKey = rightDesc.EndKey
+		log.Infof(ctx, "initiating a merge of %s into this range", rightDesc)
 
-	if err := r.store.DB().Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
-		log.Event(ctx, "merge closure begins")
-		txn.SetDebugName(mergeTxnName)
 		// Update the range descriptor for the receiving range.
 		{
 			b := txn.NewBatch()
@@ -3246,43 +3260,56 @@ func (r *Replica) AdminMerge(
 			}
 		}
 
-		// Do a consistent read of the right hand side's range descriptor.
-		rightDescKey := keys.RangeDescriptorKey(origLeftDesc.EndKey)
-		var rightDesc roachpb.RangeDescriptor
-		if err := txn.GetProto(ctx, rightDescKey, &rightDesc); err != nil {
-			return err
+		// Remove the range descriptor for the deleted range. The deletion
+		// intent is what keeps the right hand side frozen once it has been
+		// subsumed below.
+		{
+			b := txn.NewBatch()
+			b.Del(rightDescKey)
+			if err := mergeRangeAddressing(b, origLeftDesc, &updatedLeftDesc); err != nil {
+				return err
+			}
+			if err := txn.Run(ctx, b); err != nil {
+				return err
+			}
 		}
 
-		// Verify that the two ranges are mergeable.
-		if !bytes.Equal(origLeftDesc.EndKey, rightDesc.StartKey) {
-			// Should never happen, but just in case.
-			return errors.Errorf("ranges are not adjacent; %s != %s", origLeftDesc.EndKey, rightDesc.StartKey)
-		}
-		if !bytes.Equal(rightDesc.EndKey, updatedLeftDesc.EndKey) {
-			// This merge raced with a split of the right-hand range.
-			// TODO(bdarnell): needs a test.
-			return errors.Errorf("range changed during merge; %s != %s", rightDesc.EndKey, updatedLeftDesc.EndKey)
-		}
-		if !replicaSetsEqual(origLeftDesc.Replicas, rightDesc.Replicas) {
-			return errors.Errorf("ranges not collocated")
+		// Freeze the right hand side. Its lease holder serves no further
+		// commands until this transaction commits or aborts.
+		var subsumeReply *roachpb.SubsumeResponse
+		{
+			b := txn.NewBatch()
+			b.AddRawRequest(&roachpb.SubsumeRequest{
+				Span:      roachpb.Span{Key: rightDesc.StartKey.AsRawKey()},
+				LeftRange: *origLeftDesc,
+			})
+			log.Event(ctx, "subsuming RHS")
+			if err := txn.Run(ctx, b); err != nil {
+				return err
+			}
+			subsumeReply = b.RawResponse().Responses[0].GetInner().(*roachpb.SubsumeResponse)
 		}
 
-		b := txn.NewBatch()
-
-		// Remove the range descriptor for the deleted range.
-		b.Del(rightDescKey)
-
-		if err := mergeRangeAddressing(b, origLeftDesc, &updatedLeftDesc); err != nil {
-			return err
+		// Wait until every replica of the right hand side has caught up with
+		// the freeze. Each of them applies the merge trigger using its local
+		// copy of the subsumed range's data.
+		log.Event(ctx, "waiting for RHS replicas to catch up")
+		if err := waitForApplication(
+			ctx, r.store, rightDesc, subsumeReply.LeaseAppliedIndex,
+		); err != nil {
+			return errors.Wrap(err, "waiting for all right-hand replicas to catch up")
 		}
+
 		// End the transaction manually instead of letting RunTransaction
 		// loop do it, in order to provide a merge trigger.
+		b := txn.NewBatch()
 		b.AddRawRequest(&roachpb.EndTransactionRequest{
 			Commit: true,
 			InternalCommitTrigger: &roachpb.InternalCommitTrigger{
 				MergeTrigger: &roachpb.MergeTrigger{
-					LeftDesc:  updatedLeftDesc,
-					RightDesc: rightDesc,
+					LeftDesc:    updatedLeftDesc,
+					RightDesc:   rightDesc,
+					FreezeStart: subsumeReply.FreezeStart,
 				},
 			},
 		})
@@ -3295,6 +3322,38 @@ func (r *Replica) AdminMerge(
 	return reply, nil
 }
 
+// waitForApplication waits until every replica of the range described by
+// desc has applied the command with the given lease applied index. Replicas
+// on other stores are waited for through the Consistency service.
+func waitForApplication(
+	ctx context.Context, s *Store, desc roachpb.RangeDescriptor, leaseIndex uint64,
+) error {
+	for _, replica := range desc.Replicas {
+		if replica.StoreID == s.StoreID() {
+			if err := s.waitForLeaseAppliedIndex(ctx, desc.RangeID, leaseIndex); err != nil {
+				return errors.Wrapf(err, "replica %s", replica)
+			}
+			continue
+		}
+		addr, err := s.cfg.Transport.resolver(replica.NodeID)
+		if err != nil {
+			return errors.Wrapf(err, "could not resolve node ID %d", replica.NodeID)
+		}
+		conn, err := s.cfg.Transport.rpcContext.GRPCDial(addr.String())
+		if err != nil {
+			return errors.Wrapf(err, "could not dial node ID %d address %s", replica.NodeID, addr)
+		}
+		if _, err := NewConsistencyClient(conn).WaitForApplication(ctx, &WaitForApplicationRequest{
+			StoreRequestHeader: StoreRequestHeader{NodeID: replica.NodeID, StoreID: replica.StoreID},
+			RangeID:            desc.RangeID,
+			LeaseIndex:         leaseIndex,
+		}); err != nil {
+			return errors.Wrapf(err, "replica %s", replica)
+		}
+	}
+	return nil
+}
+
 // mergeTrigger is called on a successful commit of an AdminMerge
 // transaction. It recomputes stats for the receiving range.
 //
@@ -3867,6 +3926,85 @@ func evalLeaseInfo(
 	return EvalResult{}, nil
 }
 
+func declareKeysSubsume(
+	desc roachpb.RangeDescriptor, header roachpb.Header, req roachpb.Request, spans *SpanSet,
+) {
+	// Subsume must not run concurrently with any other command. It declares
+	// that it writes to every key in the range so that it waits for all
+	// in-flight commands and blocks new ones until it has frozen the range.
+	for _, keyRange := range makeReplicatedKeyRanges(&desc) {
+		spans.Add(SpanReadWrite, roachpb.Span{Key: keyRange.start.Key, EndKey: keyRange.end.Key})
+	}
+}
+
+// evalSubsume freezes a range for merging with its left-hand neighbor. It is
+// sent by AdminMerge after the merge transaction has laid down a deletion
+// intent on the range's descriptor, and must be evaluated by the lease holder.
+//
+// Once it returns, the lease holder will not serve any further commands until
+// the merge transaction is finalized (see Replica.maybeWatchForMerge). The
+// response carries the lease applied index of the last command evaluated
+// before the freeze, which AdminMerge waits for on every replica before
+// committing, and the freeze timestamp, above which no command has been
+// served; the merged range raises its timestamp cache to it.
+func evalSubsume(
+	ctx context.Context, batch engine.ReadWriter, cArgs CommandArgs, resp roachpb.Response,
+) (EvalResult, error) {
+	args := cArgs.Args.(*roachpb.SubsumeRequest)
+	reply := resp.(*roachpb.SubsumeResponse)
+	desc, err := cArgs.EvalCtx.Desc()
+	if err != nil {
+		return EvalResult{}, err
+	}
+
+	if !bytes.Equal(args.LeftRange.EndKey, desc.StartKey) {
+		return EvalResult{}, errors.Errorf("%s is not adjacent to the subsuming range %s",
+			desc, &args.LeftRange)
+	}
+
+	// Verify that the merge transaction has deleted our range descriptor.
+	// Without the intent the freeze could not be lifted again.
+	descKey := keys.RangeDescriptorKey(desc.StartKey)
+	_, intents, err := engine.MVCCGet(ctx, batch, descKey, hlc.MaxTimestamp,
+		false /* consistent */, nil /* txn */)
+	if err != nil {
+		return EvalResult{}, err
+	}
+	if len(intents) != 1 {
+		return EvalResult{}, errors.Errorf("range descriptor of %s has no merge intent", desc)
+	}
+	val, _, err := engine.MVCCGetAsTxn(ctx, batch, descKey, intents[0].Txn.Timestamp, intents[0].Txn)
+	if err != nil {
+		return EvalResult{}, err
+	} else if val != nil {
+		return EvalResult{}, errors.Errorf("range descriptor of %s has a non-deletion intent", desc)
+	}
+
+	reply.LeaseAppliedIndex = cArgs.EvalCtx.GetLeaseAppliedIndex()
+	reply.FreezeStart = cArgs.EvalCtx.Clock().Now()
+	return EvalResult{}, nil
+}
+
+func declareKeysRangeStats(
+	desc roachpb.RangeDescriptor, header roachpb.Header, req roachpb.Request, spans *SpanSet,
+) {
+	DefaultDeclareKeys(desc, header, req, spans)
+	spans.Add(SpanReadOnly, roachpb.Span{Key: keys.RangeStatsKey(header.RangeID)})
+}
+
+// evalRangeStats returns the MVCC statistics for a range.
+func evalRangeStats(
+	ctx context.Context, batch engine.ReadWriter, cArgs CommandArgs, resp roachpb.Response,
+) (EvalResult, error) {
+	reply := resp.(*roachpb.RangeStatsResponse)
+	ms, err := cArgs.EvalCtx.GetMVCCStats()
+	if err != nil {
+		return EvalResult{}, err
+	}
+	reply.MVCCStats = ms
+	return EvalResult{}, nil
+}
+
 // TestingRelocateRange relocates a given range to a given set of stores. The first
 // store in the slice becomes the new leaseholder.
 //
@@ -3877,6 +4015,18 @@ func TestingRelocateRange(
 	db *client.DB,
 	rangeDesc roachpb.RangeDescriptor,
 	targets []roachpb.ReplicationTarget,
+) error {
+	return relocateRange(ctx, db, rangeDesc, targets)
+}
+
+// relocateRange relocates a given range to a given set of stores. The first
+// store in the slice becomes the new leaseholder. It is used by the merge
+// queue to co-locate the replicas of adjacent ranges.
+func relocateRange(
+	ctx context.Context,
+	db *client.DB,
+	rangeDesc roachpb.RangeDescriptor,
+	targets []roachpb.ReplicationTarget,
 ) error {
 	// Step 1: Add any stores that don't already have a replica in of the range.
 	//
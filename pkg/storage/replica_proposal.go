@@ -484,6 +484,15 @@ func (r *Replica) leasePostApply(ctx context.Context, newLease roachpb.Lease) {
 		}
 	}
 
+	if iAmTheLeaseHolder {
+		// If the range is being subsumed by a merge, the previous lease holder
+		// has frozen it. The freeze must carry over to this lease before the
+		// lease is used.
+		if err := r.maybeWatchForMerge(ctx); err != nil {
+			log.Error(ctx, err)
+		}
+	}
+
 	// We're setting the new lease after we've updated the timestamp cache in
 	// order to avoid race conditions where a replica starts serving requests
 	// for a lease without first having taken into account requests served
@@ -671,6 +680,9 @@ func (r *Replica) handleReplicatedEvalResult(
 	if rResult.State.LeaseAppliedIndex != 0 {
 		r.mu.state.LeaseAppliedIndex = rResult.State.LeaseAppliedIndex
 	}
+	// Now that the command has been committed, reads below its closed
+	// timestamp may be served by this replica.
+	r.mu.closedTimestamp.Forward(rResult.ClosedTimestamp)
 	needsSplitBySize := r.needsSplitBySizeRLocked()
 	r.mu.Unlock()
 
@@ -684,6 +696,7 @@ func (r *Replica) handleReplicatedEvalResult(
 	rResult.State.Stats = enginepb.MVCCStats{}
 	rResult.State.LeaseAppliedIndex = 0
 	rResult.State.RaftAppliedIndex = 0
+	rResult.ClosedTimestamp = hlc.Timestamp{}
 
 	// The above are always present, so we assert only if there are
 	// "nontrivial" actions below.
@@ -721,7 +734,7 @@ func (r *Replica) handleReplicatedEvalResult(
 
 	if rResult.Merge != nil {
 		if err := r.store.MergeRange(ctx, r, rResult.Merge.LeftDesc.EndKey,
-			rResult.Merge.RightDesc.RangeID,
+			rResult.Merge.RightDesc.RangeID, rResult.Merge.FreezeStart,
 		); err != nil {
 			// Our in-memory state has diverged from the on-disk state.
 			log.Fatalf(ctx, "failed to update store after merging range: %s", err)
@@ -687,6 +687,18 @@ func (rec ReplicaEvalContext) Term(i uint64) (uint64, error) {
 	return rec.repl.raftTermRLocked(i)
 }
 
+// Clock returns the hlc clock shared by this replica.
+func (rec ReplicaEvalContext) Clock() *hlc.Clock {
+	return rec.repl.store.Clock()
+}
+
+// GetLeaseAppliedIndex returns the lease index of the last applied command.
+func (rec ReplicaEvalContext) GetLeaseAppliedIndex() uint64 {
+	rec.repl.mu.RLock()
+	defer rec.repl.mu.RUnlock()
+	return rec.repl.mu.state.LeaseAppliedIndex
+}
+
 // Fields backed by on-disk data must be registered in the SpanSet.
 
 // Desc returns the Replica's RangeDescriptor.
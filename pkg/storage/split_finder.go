@@ -0,0 +1,200 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+const (
+	// splitKeySampleSize is the number of candidate split keys sampled from
+	// the requests to a range.
+	splitKeySampleSize = 20
+	// splitKeyMinCounter is the minimum number of requests which must have
+	// been compared against a candidate split key before it can be chosen.
+	splitKeyMinCounter = 100
+	// splitKeyThreshold is the maximum difference between the number of
+	// requests on the left and on the right of a split key, as a fraction
+	// of all the requests compared against it.
+	splitKeyThreshold = 0.25
+	// splitKeyContainedThreshold is the maximum fraction of requests which
+	// may span a split key.
+	splitKeyContainedThreshold = 0.5
+)
+
+// LoadSplitSustainDuration is the amount of time the QPS of a range must
+// stay above the load-based splitting threshold before it is split.
+// Made configurable for the sake of testing.
+var LoadSplitSustainDuration = 10 * time.Second
+
+// splitKeySample is a candidate split key and the number of requests seen
+// since it was sampled which fell entirely to its left, entirely to its
+// right, or which spanned it.
+type splitKeySample struct {
+	key                    roachpb.RKey
+	left, right, contained int
+}
+
+// splitFinder finds a key which divides the requests to a range into two
+// halves of similar load. Candidate keys are reservoir-sampled from the
+// start keys of the requests; every request is then counted against each
+// candidate.
+type splitFinder struct {
+	rand    *rand.Rand
+	samples [splitKeySampleSize]splitKeySample
+	count   int
+}
+
+// newSplitFinder returns a splitFinder which samples candidate keys using
+// the given source of randomness.
+func newSplitFinder(rng *rand.Rand) *splitFinder {
+	return &splitFinder{rand: rng}
+}
+
+// record counts the request spanning the given keys against the candidate
+// split keys, and possibly samples its start key as a new candidate.
+func (f *splitFinder) record(span roachpb.RSpan) {
+	if len(span.Key) == 0 {
+		return
+	}
+	n := f.count
+	if n > splitKeySampleSize {
+		n = splitKeySampleSize
+	}
+	for i := range f.samples[:n] {
+		s := &f.samples[i]
+		switch {
+		case !s.key.Less(span.EndKey):
+			s.left++
+		case !span.Key.Less(s.key):
+			s.right++
+		default:
+			s.contained++
+		}
+	}
+
+	idx := f.count
+	f.count++
+	if idx >= splitKeySampleSize {
+		if idx = f.rand.Intn(f.count); idx >= splitKeySampleSize {
+			return
+		}
+	}
+	f.samples[idx] = splitKeySample{key: span.Key}
+}
+
+// key returns the candidate split key which most evenly divides the
+// requests, or nil if no candidate divides them evenly enough.
+func (f *splitFinder) key() roachpb.RKey {
+	n := f.count
+	if n > splitKeySampleSize {
+		n = splitKeySampleSize
+	}
+	var bestKey roachpb.RKey
+	bestScore := math.Inf(1)
+	for i := range f.samples[:n] {
+		s := &f.samples[i]
+		total := s.left + s.right + s.contained
+		if total < splitKeyMinCounter {
+			continue
+		}
+		imbalance := math.Abs(float64(s.left-s.right)) / float64(total)
+		contained := float64(s.contained) / float64(total)
+		if imbalance > splitKeyThreshold || contained > splitKeyContainedThreshold {
+			continue
+		}
+		if score := imbalance + contained; score < bestScore {
+			bestKey, bestScore = s.key, score
+		}
+	}
+	return bestKey
+}
+
+// loadSplitter decides when a range has been under enough load for long
+// enough to be split, and where. It only samples requests while the QPS of
+// the range is above the threshold.
+type loadSplitter struct {
+	mu struct {
+		syncutil.Mutex
+		// lastQPSCheck is the last time the QPS of the range was compared
+		// against the threshold.
+		lastQPSCheck time.Time
+		// hotSince is the time since which the QPS of the range has been
+		// above the threshold.
+		hotSince time.Time
+		// finder is non-nil while the QPS of the range is above the threshold.
+		finder *splitFinder
+	}
+}
+
+// record records a request to the range. The QPS of the range, as returned
+// by qpsFn, is compared against threshold at most once per second; spanFn
+// returns the span of the request and is only called while the range is
+// being sampled. It returns true when a split key has become available.
+func (ls *loadSplitter) record(
+	now time.Time, threshold float64, qpsFn func() float64, spanFn func() roachpb.RSpan,
+) bool {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	var splitKeyAvailable bool
+	if now.Sub(ls.mu.lastQPSCheck) >= time.Second {
+		ls.mu.lastQPSCheck = now
+		if qpsFn() >= threshold {
+			if ls.mu.finder == nil {
+				ls.mu.finder = newSplitFinder(rand.New(rand.NewSource(now.UnixNano())))
+				ls.mu.hotSince = now
+			}
+			splitKeyAvailable = ls.splitKeyLocked(now) != nil
+		} else {
+			ls.mu.finder = nil
+		}
+	}
+	if ls.mu.finder != nil {
+		ls.mu.finder.record(spanFn())
+	}
+	return splitKeyAvailable
+}
+
+// splitKey returns the key at which the range should be split, or nil if
+// its QPS has not been above the threshold for LoadSplitSustainDuration or
+// no key divides its requests evenly.
+func (ls *loadSplitter) splitKey(now time.Time) roachpb.RKey {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	return ls.splitKeyLocked(now)
+}
+
+func (ls *loadSplitter) splitKeyLocked(now time.Time) roachpb.RKey {
+	if ls.mu.finder == nil || now.Sub(ls.mu.hotSince) < LoadSplitSustainDuration {
+		return nil
+	}
+	return ls.mu.finder.key()
+}
+
+// reset discards the samples collected so far. It is called when the range
+// is split.
+func (ls *loadSplitter) reset() {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	ls.mu.lastQPSCheck = time.Time{}
+	ls.mu.hotSince = time.Time{}
+	ls.mu.finder = nil
+}
@@ -0,0 +1,155 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func splitFinderTestKey(i int) roachpb.RKey {
+	return roachpb.RKey(fmt.Sprintf("%05d", i))
+}
+
+// TestSplitFinder verifies that the split finder only proposes keys which
+// divide the sampled requests evenly.
+func TestSplitFinder(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numKeys = 10000
+	const numRequests = 5000
+	rng := rand.New(rand.NewSource(1))
+
+	testCases := []struct {
+		name string
+		// span returns the span of the i-th request.
+		span func(i int) roachpb.RSpan
+		// expectKey is true if a split key is expected.
+		expectKey bool
+	}{
+		{
+			name: "uniform point requests",
+			span: func(int) roachpb.RSpan {
+				key := splitFinderTestKey(rng.Intn(numKeys))
+				return roachpb.RSpan{Key: key, EndKey: key.Next()}
+			},
+			expectKey: true,
+		},
+		{
+			name: "sequential point requests",
+			span: func(i int) roachpb.RSpan {
+				key := splitFinderTestKey(i)
+				return roachpb.RSpan{Key: key, EndKey: key.Next()}
+			},
+			expectKey: false,
+		},
+		{
+			name: "full range scans",
+			span: func(int) roachpb.RSpan {
+				return roachpb.RSpan{Key: splitFinderTestKey(0), EndKey: splitFinderTestKey(numKeys)}
+			},
+			expectKey: false,
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			f := newSplitFinder(rand.New(rand.NewSource(1)))
+			for i := 0; i < numRequests; i++ {
+				f.record(c.span(i))
+			}
+			key := f.key()
+			if !c.expectKey {
+				if key != nil {
+					t.Fatalf("expected no split key, but found %s", key)
+				}
+				return
+			}
+			if key == nil {
+				t.Fatal("expected a split key, but found none")
+			}
+			// The key must divide the keyspace roughly in half.
+			if key.Less(splitFinderTestKey(numKeys/4)) || splitFinderTestKey(3*numKeys/4).Less(key) {
+				t.Fatalf("expected a split key near the middle of the keyspace, but found %s", key)
+			}
+		})
+	}
+}
+
+// TestLoadSplitter verifies that a split key is only proposed once the QPS
+// of a range has stayed above the threshold for LoadSplitSustainDuration.
+func TestLoadSplitter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const threshold = 100
+	rng := rand.New(rand.NewSource(1))
+	qps := float64(2 * threshold)
+	qpsFn := func() float64 { return qps }
+	spanFn := func() roachpb.RSpan {
+		key := splitFinderTestKey(rng.Intn(1000))
+		return roachpb.RSpan{Key: key, EndKey: key.Next()}
+	}
+
+	var ls loadSplitter
+	now := time.Unix(0, 0)
+	// recordFor records 1000 requests per second for the given number of
+	// seconds and returns whether a split key became available.
+	recordFor := func(seconds int) bool {
+		var available bool
+		for s := 0; s < seconds; s++ {
+			now = now.Add(time.Second)
+			for i := 0; i < 1000; i++ {
+				if ls.record(now, threshold, qpsFn, spanFn) {
+					available = true
+				}
+			}
+		}
+		return available
+	}
+
+	sustainSeconds := int(LoadSplitSustainDuration.Seconds())
+	if recordFor(sustainSeconds - 1) {
+		t.Fatal("expected no split key before the sustain duration")
+	}
+	if key := ls.splitKey(now); key != nil {
+		t.Fatalf("expected no split key before the sustain duration, but found %s", key)
+	}
+	if !recordFor(2) {
+		t.Fatal("expected a split key after the sustain duration")
+	}
+	if key := ls.splitKey(now); key == nil {
+		t.Fatal("expected a split key after the sustain duration")
+	}
+
+	// Dropping below the threshold discards the samples.
+	qps = threshold / 2
+	recordFor(1)
+	if key := ls.splitKey(now); key != nil {
+		t.Fatalf("expected no split key below the threshold, but found %s", key)
+	}
+
+	// So does a split of the range.
+	qps = 2 * threshold
+	recordFor(sustainSeconds + 1)
+	ls.reset()
+	if key := ls.splitKey(now); key != nil {
+		t.Fatalf("expected no split key after a reset, but found %s", key)
+	}
+}
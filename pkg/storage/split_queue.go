@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/gossip"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 )
 
@@ -32,6 +33,22 @@ const (
 	splitQueueTimerDuration = 0 // zero duration to process splits greedily.
 )
 
+// splitByLoadEnabled controls whether ranges are split when their QPS stays
+// above splitByLoadQPSThreshold.
+var splitByLoadEnabled = settings.RegisterBoolSetting(
+	"kv.range_split.by_load_enabled",
+	"set to enable splitting of ranges based on the number of requests they receive",
+	false,
+)
+
+// splitByLoadQPSThreshold is the QPS above which a range becomes a candidate
+// for load-based splitting.
+var splitByLoadQPSThreshold = settings.RegisterIntSetting(
+	"kv.range_split.load_qps_threshold",
+	"the number of queries per second above which a range is split if load-based splitting is enabled",
+	250,
+)
+
 // splitQueue manages a queue of ranges slated to be split due to size
 // or along intersecting zone config boundaries.
 type splitQueue struct {
@@ -62,7 +79,9 @@ func newSplitQueue(store *Store, db *client.DB, gossip *gossip.Gossip) *splitQue
 
 // shouldQueue determines whether a range should be queued for
 // splitting. This is true if the range is intersected by a zone config
-// prefix or if the range's size in bytes exceeds the limit for the zone.
+// prefix, if the range's size in bytes exceeds the limit for the zone or
+// if the range has been receiving more requests than the load-based
+// splitting threshold.
 func (sq *splitQueue) shouldQueue(
 	ctx context.Context, now hlc.Timestamp, repl *Replica, sysCfg config.SystemConfig,
 ) (shouldQ bool, priority float64) {
@@ -79,6 +98,12 @@ func (sq *splitQueue) shouldQueue(
 		priority += ratio
 		shouldQ = true
 	}
+
+	// Add priority for ranges which should be split because of their load.
+	if repl.loadSplitKey(now.GoTime()) != nil {
+		priority++
+		shouldQ = true
+	}
 	return
 }
 
@@ -127,6 +152,23 @@ func (sq *splitQueue) process(ctx context.Context, r *Replica, sysCfg config.Sys
 			}
 			r.SetMaxBytes(zone.RangeMaxBytes)
 		}
+		return nil
+	}
+
+	// Finally handle the case of splitting due to load.
+	if splitKey := r.loadSplitKey(r.store.Clock().PhysicalTime()); splitKey != nil {
+		if _, _, pErr := r.adminSplitWithDescriptor(
+			ctx,
+			roachpb.AdminSplitRequest{
+				Span: roachpb.Span{
+					Key: splitKey,
+				},
+				SplitKey: splitKey,
+			},
+			desc,
+		); pErr != nil {
+			return errors.Wrapf(pErr.GoError(), "unable to split %s at key %q", r, splitKey)
+		}
 	}
 	return nil
 }
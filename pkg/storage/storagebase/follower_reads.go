@@ -0,0 +1,82 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storagebase
+
+import (
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+)
+
+// FollowerReadsEnabled controls whether lease holders advance the closed
+// timestamp of their ranges and whether consistent historical reads are
+// served by (and routed to) followers.
+var FollowerReadsEnabled = settings.RegisterBoolSetting(
+	"kv.closed_timestamp.follower_reads_enabled",
+	"allow all replicas to serve consistent historical reads below the closed timestamp",
+	false,
+)
+
+// ClosedTimestampTargetDuration is how far behind the current time lease
+// holders close timestamps, i.e. stop accepting writes.
+var ClosedTimestampTargetDuration = settings.RegisterNonNegativeDurationSetting(
+	"kv.closed_timestamp.target_duration",
+	"if nonzero, close timestamps trailing the current time by approximately this duration",
+	30*time.Second,
+)
+
+// ClosedTimestampTarget returns the timestamp up to which lease holders
+// should close timestamps at the given time, or the zero timestamp if
+// closing timestamps is disabled.
+func ClosedTimestampTarget(sv *settings.Values, now hlc.Timestamp) hlc.Timestamp {
+	target := ClosedTimestampTargetDuration.Get(sv)
+	if !FollowerReadsEnabled.Get(sv) || target == 0 {
+		return hlc.Timestamp{}
+	}
+	return now.Add(-target.Nanoseconds(), 0)
+}
+
+// FollowerReadThreshold returns the timestamp at or below which reads are
+// routed to the nearest replica instead of the lease holder, or the zero
+// timestamp if follower reads are disabled. It trails the closed timestamp
+// target by another half of the target duration to leave time for the
+// closed timestamp to reach the followers.
+func FollowerReadThreshold(sv *settings.Values, now hlc.Timestamp) hlc.Timestamp {
+	target := ClosedTimestampTargetDuration.Get(sv)
+	if !FollowerReadsEnabled.Get(sv) || target == 0 {
+		return hlc.Timestamp{}
+	}
+	return now.Add(-(target + target/2).Nanoseconds(), 0)
+}
+
+// FollowerReadTimestamp returns the highest timestamp the given batch may
+// observe and whether the batch may be served by a follower at all, which
+// is the case for consistent read-only batches with a timestamp. Subsume
+// requests are excluded as they freeze the lease holder.
+func FollowerReadTimestamp(ba roachpb.BatchRequest) (hlc.Timestamp, bool) {
+	if !ba.IsReadOnly() || ba.ReadConsistency != roachpb.CONSENSUS || ba.IsSingleSubsumeRequest() {
+		return hlc.Timestamp{}, false
+	}
+	ts := ba.Timestamp
+	if ba.Txn != nil {
+		// A transaction observes values up to its uncertainty limit.
+		ts.Forward(ba.Txn.Timestamp)
+		ts.Forward(ba.Txn.MaxTimestamp)
+	}
+	// A batch without a timestamp is served at the current time.
+	return ts, ts != hlc.Timestamp{}
+}
@@ -351,6 +351,7 @@ type Store struct {
 	rangeIDAlloc       *idAllocator                // Range ID allocator
 	gcQueue            *gcQueue                    // Garbage collection queue
 	splitQueue         *splitQueue                 // Range splitting queue
+	mergeQueue         *mergeQueue                 // Range merging queue
 	replicateQueue     *replicateQueue             // Replication queue
 	replicaGCQueue     *replicaGCQueue             // Replica GC queue
 	raftLogQueue       *raftLogQueue               // Raft log truncation queue
@@ -724,6 +725,8 @@ type StoreTestingKnobs struct {
 	DisableReplicaRebalancing bool
 	// DisableSplitQueue disables the split queue.
 	DisableSplitQueue bool
+	// DisableMergeQueue disables the merge queue.
+	DisableMergeQueue bool
 	// DisableTimeSeriesMaintenanceQueue disables the time series maintenance
 	// queue.
 	DisableTimeSeriesMaintenanceQueue bool
@@ -879,13 +882,14 @@ func NewStore(cfg StoreConfig, eng engine.Engine, nodeDesc *roachpb.NodeDescript
 		)
 		s.gcQueue = newGCQueue(s, s.cfg.Gossip)
 		s.splitQueue = newSplitQueue(s, s.db, s.cfg.Gossip)
+		s.mergeQueue = newMergeQueue(s, s.db, s.cfg.Gossip)
 		s.replicateQueue = newReplicateQueue(s, s.cfg.Gossip, s.allocator, s.cfg.Clock)
 		s.replicaGCQueue = newReplicaGCQueue(s, s.db, s.cfg.Gossip)
 		s.raftLogQueue = newRaftLogQueue(s, s.db, s.cfg.Gossip)
 		s.raftSnapshotQueue = newRaftSnapshotQueue(s, s.cfg.Gossip, s.cfg.Clock)
 		s.consistencyQueue = newConsistencyQueue(s, s.cfg.Gossip)
 		s.scanner.AddQueues(
-			s.gcQueue, s.splitQueue, s.replicateQueue, s.replicaGCQueue,
+			s.gcQueue, s.splitQueue, s.mergeQueue, s.replicateQueue, s.replicaGCQueue,
 			s.raftLogQueue, s.raftSnapshotQueue, s.consistencyQueue)
 
 		if s.cfg.TimeSeriesDataStore != nil {
@@ -905,6 +909,9 @@ func NewStore(cfg StoreConfig, eng engine.Engine, nodeDesc *roachpb.NodeDescript
 	if cfg.TestingKnobs.DisableSplitQueue {
 		s.setSplitQueueActive(false)
 	}
+	if cfg.TestingKnobs.DisableMergeQueue {
+		s.setMergeQueueActive(false)
+	}
 	if cfg.TestingKnobs.DisableTimeSeriesMaintenanceQueue {
 		s.setTimeSeriesMaintenanceQueueActive(false)
 	}
@@ -1585,6 +1592,30 @@ func (s *Store) GetReplica(rangeID roachpb.RangeID) (*Replica, error) {
 	return nil, roachpb.NewRangeNotFoundError(rangeID)
 }
 
+// waitForLeaseAppliedIndex blocks until the replica of the given range has
+// applied the command with the given lease applied index. The replica may
+// not have applied its first snapshot yet, so it is waited for as well.
+func (s *Store) waitForLeaseAppliedIndex(
+	ctx context.Context, rangeID roachpb.RangeID, leaseIndex uint64,
+) error {
+	for r := retry.StartWithCtx(ctx, base.DefaultRetryOptions()); r.Next(); {
+		repl, err := s.GetReplica(rangeID)
+		if err != nil {
+			if _, ok := err.(*roachpb.RangeNotFoundError); ok {
+				continue
+			}
+			return err
+		}
+		repl.mu.RLock()
+		leaseAppliedIndex := repl.mu.state.LeaseAppliedIndex
+		repl.mu.RUnlock()
+		if leaseAppliedIndex >= leaseIndex {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
+
 // LookupReplica looks up a replica via binary search over the
 // "replicasByKey" btree. Returns nil if no replica is found for
 // specified key range. Note that the specified keys are transformed
@@ -1846,6 +1877,11 @@ func splitPostApply(
 	rightRng.mu.Lock()
 	// Copy the minLeaseProposedTS from the LHS.
 	rightRng.mu.minLeaseProposedTS = r.mu.minLeaseProposedTS
+	// Copy the closed timestamp from the LHS. Reads of the RHS keyspace may
+	// have been served below it, so the lease holder of the RHS must not
+	// accept writes below it either.
+	rightRng.mu.closedTimestamp = r.mu.closedTimestamp
+	rightRng.closedTS.forward(r.mu.closedTimestamp)
 	rightLease := *rightRng.mu.state.Lease
 	rightRng.mu.Unlock()
 	r.mu.Unlock()
@@ -1938,6 +1974,7 @@ func (s *Store) SplitRange(ctx context.Context, origRng, newRng *Replica) error
 	// Clear the original range's request stats, since they include requests for
 	// spans that are now owned by the new range.
 	origRng.leaseholderStats.resetRequestCounts()
+	origRng.loadSplitter.reset()
 	origRng.writeStats.splitRequestCounts(newRng.writeStats)
 
 	if kr := s.mu.replicasByKey.ReplaceOrInsert(origRng); kr != nil {
@@ -1965,13 +2002,15 @@ func (s *Store) SplitRange(ctx context.Context, origRng, newRng *Replica) error
 
 // MergeRange expands the subsuming range to absorb the subsumed range. This
 // merge operation will fail if the two ranges are not collocated on the same
-// store.
+// store. The timestamp cache of the subsumed key span is raised to freezeStart,
+// the time at which the subsumed range stopped serving commands.
 // The subsumed range's raftMu is assumed held.
 func (s *Store) MergeRange(
 	ctx context.Context,
 	subsumingRng *Replica,
 	updatedEndKey roachpb.RKey,
 	subsumedRangeID roachpb.RangeID,
+	freezeStart hlc.Timestamp,
 ) error {
 	subsumingDesc := subsumingRng.Desc()
 
@@ -2001,10 +2040,41 @@ func (s *Store) MergeRange(
 		subsumingRng.writeStats.resetRequestCounts()
 	}
 
-	if err := s.maybeMergeTimestampCaches(ctx, subsumingRng, subsumedRng); err != nil {
-		return err
+	// The subsumed range may have served reads and writes up to freezeStart,
+	// possibly on another store. If this store holds the lease of the
+	// subsuming range, it must not allow writes at or below freezeStart to the
+	// subsumed key span. Other stores need no entry: a new lease starts after
+	// freezeStart, and the timestamp cache is initialized to its start.
+	if lease, _ := subsumingRng.getLease(); lease.OwnedBy(s.StoreID()) {
+		spans := []roachpb.Span{
+			{Key: subsumedDesc.StartKey.AsRawKey(), EndKey: subsumedDesc.EndKey.AsRawKey()},
+			{
+				Key:    keys.MakeRangeKeyPrefix(subsumedDesc.StartKey),
+				EndKey: keys.MakeRangeKeyPrefix(subsumedDesc.EndKey),
+			},
+		}
+		s.tsCacheMu.Lock()
+		for _, span := range spans {
+			for _, readOnly := range []bool{true, false} {
+				s.tsCacheMu.cache.add(span.Key, span.EndKey, freezeStart, lowWaterTxnIDMarker, readOnly)
+			}
+		}
+		s.tsCacheMu.Unlock()
 	}
 
+	// Reads of the subsumed keyspace may have been served below the closed
+	// timestamp of the subsumed range, but not above it. The subsuming range
+	// must neither accept writes below it nor serve reads above it.
+	subsumedRng.mu.Lock()
+	subsumedClosed := subsumedRng.mu.closedTimestamp
+	subsumedRng.mu.Unlock()
+	subsumingRng.closedTS.forward(subsumedClosed)
+	subsumingRng.mu.Lock()
+	if subsumedClosed.Less(subsumingRng.mu.closedTimestamp) {
+		subsumingRng.mu.closedTimestamp = subsumedClosed
+	}
+	subsumingRng.mu.Unlock()
+
 	// Remove and destroy the subsumed range. Note that we were called
 	// (indirectly) from raft processing so we must call removeReplicaImpl
 	// directly to avoid deadlocking on Replica.raftMu.
@@ -2022,33 +2092,6 @@ func (s *Store) MergeRange(
 	return subsumingRng.setDesc(&copy)
 }
 
-// If the subsuming replica has the range lease, we update its timestamp cache
-// with the entries from the subsumed. Otherwise, then the timestamp cache
-// doesn't matter (in fact it should be empty, to save memory).
-func (s *Store) maybeMergeTimestampCaches(
-	ctx context.Context, subsumingRep *Replica, subsumedRep *Replica,
-) error {
-	subsumingRep.mu.Lock()
-	defer subsumingRep.mu.Unlock()
-	subsumingLease := subsumingRep.mu.state.Lease
-
-	subsumedRep.mu.Lock()
-	defer subsumedRep.mu.Unlock()
-	subsumedLease := *subsumedRep.mu.state.Lease
-
-	// Merge support is currently incomplete and incorrect. In particular, the
-	// lease holders must be colocated and the subsumed range appropriately
-	// quiesced. See also #2433.
-	now := s.Clock().Now()
-	if subsumedRep.isLeaseValidRLocked(subsumedLease, now) &&
-		subsumingLease.Replica.StoreID != subsumedLease.Replica.StoreID {
-		log.Fatalf(ctx, "cannot merge ranges with non-colocated leases. "+
-			"Subsuming lease: %s. Subsumed lease: %s.", subsumingLease, subsumedLease)
-	}
-
-	return nil
-}
-
 // addReplicaInternalLocked adds the replica to the replicas map and the
 // replicasByKey btree. Returns an error if a replica with
 // the same Range ID or a KeyRange that overlaps has already been added to
@@ -2211,6 +2254,12 @@ func (s *Store) removeReplicaImpl(
 	rep.cancelPendingCommandsLocked()
 	rep.mu.internalRaftGroup = nil
 	rep.mu.destroyed = roachpb.NewRangeNotFoundError(rep.RangeID)
+	if rep.mu.mergeComplete != nil {
+		// Commands waiting for a merge of this replica are redirected to the
+		// subsuming range.
+		close(rep.mu.mergeComplete)
+		rep.mu.mergeComplete = nil
+	}
 	rep.mu.Unlock()
 	rep.readOnlyCmdMu.Unlock()
 
@@ -2299,11 +2348,17 @@ func (s *Store) Capacity() (roachpb.StoreCapacity, error) {
 	var leaseCount int32
 	var logicalBytes int64
 	var totalWritesPerSecond float64
+	var totalQueriesPerSecond float64
 	bytesPerReplica := make([]float64, 0, capacity.RangeCount)
 	writesPerReplica := make([]float64, 0, capacity.RangeCount)
 	newStoreReplicaVisitor(s).Visit(func(r *Replica) bool {
 		if r.OwnsValidLease(now) {
 			leaseCount++
+			if r.leaseholderStats != nil {
+				if qps, dur := r.leaseholderStats.avgQPS(); dur >= MinStatsDuration {
+					totalQueriesPerSecond += qps
+				}
+			}
 		}
 		mvccStats := r.GetMVCCStats()
 		logicalBytes += mvccStats.Total()
@@ -2320,6 +2375,7 @@ func (s *Store) Capacity() (roachpb.StoreCapacity, error) {
 	capacity.LeaseCount = leaseCount
 	capacity.LogicalBytes = logicalBytes
 	capacity.WritesPerSecond = totalWritesPerSecond
+	capacity.QueriesPerSecond = totalQueriesPerSecond
 	capacity.BytesPerReplica = roachpb.PercentilesFromData(bytesPerReplica)
 	capacity.WritesPerReplica = roachpb.PercentilesFromData(writesPerReplica)
 	s.recordNewWritesPerSecond(totalWritesPerSecond)
@@ -4006,6 +4062,7 @@ func (s *Store) updateReplicationGauges(ctx context.Context) error {
 		raftLeaderNotLeaseHolderCount int64
 		quiescentCount                int64
 		averageWritesPerSecond        float64
+		averageQueriesPerSecond       float64
 
 		rangeCount                int64
 		unavailableRangeCount     int64
@@ -4055,6 +4112,11 @@ func (s *Store) updateReplicationGauges(ctx context.Context) error {
 		if qps, dur := rep.writeStats.avgQPS(); dur >= MinStatsDuration {
 			averageWritesPerSecond += qps
 		}
+		if metrics.Leaseholder && rep.leaseholderStats != nil {
+			if qps, dur := rep.leaseholderStats.avgQPS(); dur >= MinStatsDuration {
+				averageQueriesPerSecond += qps
+			}
+		}
 		return true // more
 	})
 
@@ -4065,6 +4127,7 @@ func (s *Store) updateReplicationGauges(ctx context.Context) error {
 	s.metrics.LeaseEpochCount.Update(leaseEpochCount)
 	s.metrics.QuiescentCount.Update(quiescentCount)
 	s.metrics.AverageWritesPerSecond.Update(averageWritesPerSecond)
+	s.metrics.AverageQueriesPerSecond.Update(averageQueriesPerSecond)
 	s.recordNewWritesPerSecond(averageWritesPerSecond)
 
 	s.metrics.RangeCount.Update(rangeCount)
@@ -4256,6 +4319,9 @@ func (s *Store) setReplicateQueueActive(active bool) {
 func (s *Store) setSplitQueueActive(active bool) {
 	s.splitQueue.SetDisabled(!active)
 }
+func (s *Store) setMergeQueueActive(active bool) {
+	s.mergeQueue.SetDisabled(!active)
+}
 func (s *Store) setTimeSeriesMaintenanceQueueActive(active bool) {
 	s.tsMaintenanceQueue.SetDisabled(!active)
 }
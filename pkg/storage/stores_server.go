@@ -74,3 +74,16 @@ func (is Server) CollectChecksum(
 		})
 	return resp, err
 }
+
+// WaitForApplication implements ConsistencyServer. It blocks until the
+// addressed replica has applied the command with the requested lease applied
+// index.
+func (is Server) WaitForApplication(
+	ctx context.Context, req *WaitForApplicationRequest,
+) (*WaitForApplicationResponse, error) {
+	resp := &WaitForApplicationResponse{}
+	err := is.execStoreCommand(req.StoreRequestHeader, func(s *Store) error {
+		return s.waitForLeaseAppliedIndex(ctx, req.RangeID, req.LeaseIndex)
+	})
+	return resp, err
+}
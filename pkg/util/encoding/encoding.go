@@ -30,6 +30,7 @@ import (
 
 	"github.com/cockroachdb/apd"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
 
@@ -68,6 +69,20 @@ const (
 	decimalNaNDesc          = decimalInfinity + 1 // NaN encoded descendingly
 	decimalTerminator       = 0x00
 
+	// Markers used in the keys of inverted indexes on JSON columns. Such a key
+	// encodes a path from the root of a JSON document to one of its leaves as a
+	// sequence of components: each object key is encoded as
+	// jsonObjectKeyMarker followed by the key string, each array as
+	// jsonArrayElementMarker, and the path ends with the leaf itself. Scalar
+	// leaves use the regular key encodings (or the jsonFalse and jsonTrue
+	// markers), while empty containers use jsonEmptyObject and jsonEmptyArray.
+	jsonObjectKeyMarker    = decimalNaNDesc + 1
+	jsonArrayElementMarker = jsonObjectKeyMarker + 1
+	jsonEmptyObject        = jsonArrayElementMarker + 1
+	jsonEmptyArray         = jsonEmptyObject + 1
+	jsonFalse              = jsonEmptyArray + 1
+	jsonTrue               = jsonFalse + 1
+
 	// IntMin is chosen such that the range of int tags does not overlap the
 	// ascii character set that is frequently used in testing.
 	IntMin      = 0x80
@@ -518,6 +533,42 @@ func DecodeBytesDescending(b []byte, r []byte) ([]byte, []byte, error) {
 	return b, r, err
 }
 
+// EncodeIPAddrAscending encodes an ipaddr.IPAddr value, appends it to the
+// supplied buffer, and returns the final buffer. The encoded keys sort in the
+// same order as ipaddr.IPAddr.Compare.
+func EncodeIPAddrAscending(b []byte, ipAddr ipaddr.IPAddr) []byte {
+	return EncodeBytesAscending(b, ipAddr.ToKeyBuffer(nil))
+}
+
+// EncodeIPAddrDescending is the descending version of EncodeIPAddrAscending.
+func EncodeIPAddrDescending(b []byte, ipAddr ipaddr.IPAddr) []byte {
+	return EncodeBytesDescending(b, ipAddr.ToKeyBuffer(nil))
+}
+
+// DecodeIPAddrAscending decodes an ipaddr.IPAddr value which was encoded
+// using EncodeIPAddrAscending. The remainder of the input buffer and the
+// decoded value are returned.
+func DecodeIPAddrAscending(b []byte) ([]byte, ipaddr.IPAddr, error) {
+	b, data, err := DecodeBytesAscending(b, nil)
+	if err != nil {
+		return b, ipaddr.IPAddr{}, err
+	}
+	ipAddr, err := ipaddr.FromKeyBuffer(data)
+	return b, ipAddr, err
+}
+
+// DecodeIPAddrDescending decodes an ipaddr.IPAddr value which was encoded
+// using EncodeIPAddrDescending. The remainder of the input buffer and the
+// decoded value are returned.
+func DecodeIPAddrDescending(b []byte) ([]byte, ipaddr.IPAddr, error) {
+	b, data, err := DecodeBytesDescending(b, nil)
+	if err != nil {
+		return b, ipaddr.IPAddr{}, err
+	}
+	ipAddr, err := ipaddr.FromKeyBuffer(data)
+	return b, ipAddr, err
+}
+
 func decodeBytesInternal(b []byte, r []byte, e escapes, expectMarker bool) ([]byte, []byte, error) {
 	if expectMarker {
 		if len(b) == 0 || b[0] != e.marker {
@@ -646,6 +697,43 @@ func EncodeNullAscending(b []byte) []byte {
 	return append(b, encodedNull)
 }
 
+// EncodeJSONObjectKeyAscending encodes the key of a JSON object as a component
+// of the path stored in the key of a JSON inverted index entry. The encoded
+// bytes are appended to the supplied buffer and the final buffer is returned.
+func EncodeJSONObjectKeyAscending(b []byte, key string) []byte {
+	b = append(b, jsonObjectKeyMarker)
+	return EncodeStringAscending(b, key)
+}
+
+// EncodeJSONArrayElementAscending encodes the descent into the elements of a
+// JSON array as a component of the path stored in the key of a JSON inverted
+// index entry. The encoded bytes are appended to the supplied buffer and the
+// final buffer is returned.
+func EncodeJSONArrayElementAscending(b []byte) []byte {
+	return append(b, jsonArrayElementMarker)
+}
+
+// EncodeJSONEmptyObjectAscending encodes an empty JSON object found at the end
+// of the path stored in the key of a JSON inverted index entry.
+func EncodeJSONEmptyObjectAscending(b []byte) []byte {
+	return append(b, jsonEmptyObject)
+}
+
+// EncodeJSONEmptyArrayAscending encodes an empty JSON array found at the end
+// of the path stored in the key of a JSON inverted index entry.
+func EncodeJSONEmptyArrayAscending(b []byte) []byte {
+	return append(b, jsonEmptyArray)
+}
+
+// EncodeJSONBoolAscending encodes a JSON boolean found at the end of the path
+// stored in the key of a JSON inverted index entry.
+func EncodeJSONBoolAscending(b []byte, v bool) []byte {
+	if v {
+		return append(b, jsonTrue)
+	}
+	return append(b, jsonFalse)
+}
+
 // EncodeNullDescending is the descending equivalent of EncodeNullAscending.
 func EncodeNullDescending(b []byte) []byte {
 	return append(b, encodedNullDesc)
@@ -859,7 +947,14 @@ const (
 	False
 	UUID
 	Array
+	JSON
 	SentinelType Type = 15 // Used in the Value encoding.
+
+	// JSONInvertedIndex is only used by PeekType, for the components of the
+	// paths stored in the keys of JSON inverted indexes.
+	JSONInvertedIndex Type = 16
+
+	IPAddr Type = 17
 )
 
 // PeekType peeks at the type of the value encoded at the start of b.
@@ -885,6 +980,8 @@ func PeekType(b []byte) Type {
 			return Float
 		case m >= decimalNaN && m <= decimalNaNDesc:
 			return Decimal
+		case m >= jsonObjectKeyMarker && m <= jsonTrue:
+			return JSONInvertedIndex
 		}
 	}
 	return Unknown
@@ -943,6 +1040,24 @@ func PeekLength(b []byte) (int, error) {
 			return 0, errors.Errorf("slice too short for float (%d)", len(b))
 		}
 		return 9, nil
+	case jsonEmptyObject, jsonEmptyArray, jsonFalse, jsonTrue:
+		return 1, nil
+	case jsonObjectKeyMarker, jsonArrayElementMarker:
+		// A path component is always followed by the rest of the path, so the
+		// whole path is considered a single value.
+		n := 1
+		if m == jsonObjectKeyMarker {
+			l, err := getBytesLength(b[1:], ascendingEscapes)
+			if err != nil {
+				return 0, err
+			}
+			n += l
+		}
+		l, err := PeekLength(b[n:])
+		if err != nil {
+			return 0, err
+		}
+		return n + l, nil
 	}
 	if m >= IntMin && m <= IntMax {
 		return getVarintLen(b)
@@ -1030,12 +1145,40 @@ func prettyPrintFirstValue(b []byte) ([]byte, string, error) {
 			return b, "", err
 		}
 		return b, d.String(), nil
+	case JSONInvertedIndex:
+		return prettyPrintJSONPathComponent(b)
 	default:
 		// This shouldn't ever happen, but if it does, return an empty slice.
 		return nil, strconv.Quote(string(b)), nil
 	}
 }
 
+// prettyPrintJSONPathComponent returns a string representation of the first
+// component of a path stored in the key of a JSON inverted index entry, along
+// with the remaining byte slice after decoding.
+func prettyPrintJSONPathComponent(b []byte) ([]byte, string, error) {
+	switch b[0] {
+	case jsonObjectKeyMarker:
+		b, s, err := DecodeUnsafeStringAscending(b[1:], nil)
+		if err != nil {
+			return b, "", err
+		}
+		return b, strconv.Quote(s), nil
+	case jsonArrayElementMarker:
+		return b[1:], "Arr", nil
+	case jsonEmptyObject:
+		return b[1:], "{}", nil
+	case jsonEmptyArray:
+		return b[1:], "[]", nil
+	case jsonFalse:
+		return b[1:], "false", nil
+	case jsonTrue:
+		return b[1:], "true", nil
+	default:
+		return b, "", errors.Errorf("unknown JSON path component %d", b[0])
+	}
+}
+
 // NonsortingVarintMaxLen is the maximum length of an EncodeNonsortingVarint
 // encoded value.
 const NonsortingVarintMaxLen = binary.MaxVarintLen64
@@ -1230,6 +1373,13 @@ func EncodeArrayValue(appendTo []byte, colID uint32, data []byte) []byte {
 	return EncodeUntaggedBytesValue(appendTo, data)
 }
 
+// EncodeJSONValue encodes an already-encoded JSON value with its value tag,
+// appends it to the supplied buffer, and returns the final buffer.
+func EncodeJSONValue(appendTo []byte, colID uint32, data []byte) []byte {
+	appendTo = EncodeValueTag(appendTo, colID, JSON)
+	return EncodeUntaggedBytesValue(appendTo, data)
+}
+
 // EncodeTimeValue encodes a time.Time value with its value tag, appends it to
 // the supplied buffer, and returns the final buffer.
 func EncodeTimeValue(appendTo []byte, colID uint32, t time.Time) []byte {
@@ -1296,6 +1446,19 @@ func EncodeUntaggedUUIDValue(appendTo []byte, u uuid.UUID) []byte {
 	return append(appendTo, u.GetBytes()...)
 }
 
+// EncodeIPAddrValue encodes a ipaddr.IPAddr value with its value tag, appends
+// it to the supplied buffer, and returns the final buffer.
+func EncodeIPAddrValue(appendTo []byte, colID uint32, ipAddr ipaddr.IPAddr) []byte {
+	appendTo = EncodeValueTag(appendTo, colID, IPAddr)
+	return EncodeUntaggedIPAddrValue(appendTo, ipAddr)
+}
+
+// EncodeUntaggedIPAddrValue encodes a ipaddr.IPAddr value, appends it to the
+// supplied buffer, and returns the final buffer.
+func EncodeUntaggedIPAddrValue(appendTo []byte, ipAddr ipaddr.IPAddr) []byte {
+	return EncodeUntaggedBytesValue(appendTo, ipAddr.ToBuffer(nil))
+}
+
 // DecodeValueTag decodes a value encoded by EncodeValueTag, used as a prefix in
 // each of the other EncodeFooValue methods.
 //
@@ -1412,6 +1575,16 @@ func DecodeUntaggedBytesValue(b []byte) (remaining, data []byte, err error) {
 	return b[int(i):], b[:int(i)], nil
 }
 
+// DecodeJSONValue decodes a value encoded by EncodeJSONValue, returning the
+// encoded JSON value.
+func DecodeJSONValue(b []byte) (remaining []byte, data []byte, err error) {
+	b, err = decodeValueTypeAssert(b, JSON)
+	if err != nil {
+		return b, nil, err
+	}
+	return DecodeUntaggedBytesValue(b)
+}
+
 // DecodeTimeValue decodes a value encoded by EncodeTimeValue.
 func DecodeTimeValue(b []byte) (remaining []byte, t time.Time, err error) {
 	b, err = decodeValueTypeAssert(b, Time)
@@ -1504,6 +1677,31 @@ func DecodeUntaggedUUIDValue(b []byte) (remaining []byte, u uuid.UUID, err error
 	return b[uuidValueEncodedLength:], u, nil
 }
 
+// ipAddrValueEncodedMaxLength is the length of the largest (IPv6) address
+// encoded by ipaddr.IPAddr.ToBuffer.
+const ipAddrValueEncodedMaxLength = 18
+
+// DecodeIPAddrValue decodes a value encoded by EncodeIPAddrValue.
+func DecodeIPAddrValue(b []byte) (remaining []byte, ipAddr ipaddr.IPAddr, err error) {
+	b, err = decodeValueTypeAssert(b, IPAddr)
+	if err != nil {
+		return b, ipAddr, err
+	}
+	return DecodeUntaggedIPAddrValue(b)
+}
+
+// DecodeUntaggedIPAddrValue decodes a value encoded by
+// EncodeUntaggedIPAddrValue.
+func DecodeUntaggedIPAddrValue(b []byte) (remaining []byte, ipAddr ipaddr.IPAddr, err error) {
+	var data []byte
+	b, data, err = DecodeUntaggedBytesValue(b)
+	if err != nil {
+		return b, ipAddr, err
+	}
+	ipAddr, _, err = ipaddr.FromBuffer(data)
+	return b, ipAddr, err
+}
+
 func decodeValueTypeAssert(b []byte, expected Type) ([]byte, error) {
 	_, dataOffset, _, typ, err := DecodeValueTag(b)
 	if err != nil {
@@ -1547,7 +1745,7 @@ func PeekValueLength(b []byte) (typeOffset int, length int, err error) {
 		return typeOffset, dataOffset + n, err
 	case Float:
 		return typeOffset, dataOffset + floatValueEncodedLength, nil
-	case Bytes, Array:
+	case Bytes, Array, JSON, IPAddr:
 		_, n, i, err := DecodeNonsortingUvarint(b)
 		return typeOffset, dataOffset + n + int(i), err
 	case Decimal:
@@ -1593,6 +1791,8 @@ func UpperBoundValueEncodingSize(colID uint32, typ Type, size int) (int, bool) {
 		return len(encodedTag) + 2*maxVarintSize, true
 	case Duration:
 		return len(encodedTag) + 3*maxVarintSize, true
+	case IPAddr:
+		return len(encodedTag) + maxVarintSize + ipAddrValueEncodedMaxLength, true
 	default:
 		panic(fmt.Errorf("unknown type: %s", typ))
 	}
@@ -1669,6 +1869,22 @@ func PrettyPrintValueEncoded(b []byte) ([]byte, string, error) {
 			return b, "", err
 		}
 		return b, u.String(), nil
+	case JSON:
+		// The JSON values are encoded by the json package, which this package
+		// cannot depend on: print the raw encoding.
+		var data []byte
+		b, data, err = DecodeJSONValue(b)
+		if err != nil {
+			return b, "", err
+		}
+		return b, hex.EncodeToString(data), nil
+	case IPAddr:
+		var ipAddr ipaddr.IPAddr
+		b, ipAddr, err = DecodeIPAddrValue(b)
+		if err != nil {
+			return b, "", err
+		}
+		return b, ipAddr.String(), nil
 	default:
 		return b, "", errors.Errorf("unknown type %s", typ)
 	}
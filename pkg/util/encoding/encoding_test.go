@@ -26,6 +26,7 @@ import (
 
 	"github.com/cockroachdb/apd"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
 	"github.com/cockroachdb/cockroach/pkg/util/randutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
@@ -826,6 +827,62 @@ func TestEncodeDecodeDuration(t *testing.T) {
 	testCustomEncodeDuration(testCases, EncodeDurationAscending, DecodeDurationAscending, t)
 }
 
+func TestEncodeDecodeIPAddr(t *testing.T) {
+	// The test cases are in ascending order.
+	testCases := []string{
+		"0.0.0.0/0",
+		"10.0.0.0/8",
+		"10.2.0.0/8",
+		"10.1.0.0/16",
+		"10.1.0.0",
+		"10.1.0.1",
+		"::/0",
+		"::ffff:0.0.0.0",
+		"2001:db8::1/64",
+	}
+	var lastAsc, lastDesc []byte
+	for i, s := range testCases {
+		ipAddr, err := ipaddr.ParseINet(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		asc := EncodeIPAddrAscending(nil, ipAddr)
+		desc := EncodeIPAddrDescending(nil, ipAddr)
+		if i > 0 {
+			if bytes.Compare(lastAsc, asc) >= 0 {
+				t.Errorf("%s: expected ascending encoding to sort after %q", s, lastAsc)
+			}
+			if bytes.Compare(lastDesc, desc) <= 0 {
+				t.Errorf("%s: expected descending encoding to sort before %q", s, lastDesc)
+			}
+		}
+		lastAsc, lastDesc = asc, desc
+
+		if _, res, err := DecodeIPAddrAscending(asc); err != nil {
+			t.Fatal(err)
+		} else if res != ipAddr {
+			t.Errorf("expected %s, got %s", ipAddr, res)
+		}
+		if _, res, err := DecodeIPAddrDescending(desc); err != nil {
+			t.Fatal(err)
+		} else if res != ipAddr {
+			t.Errorf("expected %s, got %s", ipAddr, res)
+		}
+
+		val := EncodeIPAddrValue(nil, NoColumnID, ipAddr)
+		if _, length, err := PeekValueLength(val); err != nil {
+			t.Fatal(err)
+		} else if length != len(val) {
+			t.Errorf("%s: expected value length %d, got %d", s, len(val), length)
+		}
+		if rem, res, err := DecodeIPAddrValue(val); err != nil {
+			t.Fatal(err)
+		} else if len(rem) != 0 || res != ipAddr {
+			t.Errorf("expected %s, got %s (remaining %v)", ipAddr, res, rem)
+		}
+	}
+}
+
 func TestEncodeDecodeDescending(t *testing.T) {
 	testCases := []testCaseDuration{
 		{duration.Duration{Months: 0, Days: 40, Nanos: 0}, []byte{0x16, 0x81, 0xf3, 0xb8, 0xc9, 0x4b, 0xa7, 0xff, 0xff, 0x87, 0xff, 0x87, 0xd7}},
@@ -1778,6 +1835,8 @@ func TestUpperBoundValueEncodingSize(t *testing.T) {
 func TestPrettyPrintValueEncoded(t *testing.T) {
 	uuidStr := "63616665-6630-3064-6465-616462656562"
 	u, _ := uuid.FromString(uuidStr)
+	ipAddrStr := "2001:db8::1/64"
+	ipAddr, _ := ipaddr.ParseINet(ipAddrStr)
 	tests := []struct {
 		buf      []byte
 		expected string
@@ -1795,6 +1854,7 @@ func TestPrettyPrintValueEncoded(t *testing.T) {
 		{EncodeBytesValue(nil, NoColumnID, []byte{0x1, 0x2, 0xF, 0xFF}), "01020fff"},
 		{EncodeBytesValue(nil, NoColumnID, []byte("foo")), "foo"},
 		{EncodeUUIDValue(nil, NoColumnID, u), uuidStr},
+		{EncodeIPAddrValue(nil, NoColumnID, ipAddr), ipAddrStr},
 	}
 	for i, test := range tests {
 		remaining, str, err := PrettyPrintValueEncoded(test.buf)
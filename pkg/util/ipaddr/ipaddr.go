@@ -0,0 +1,332 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package ipaddr implements the IPv4 and IPv6 network addresses, with an
+// optional netmask, stored in INET columns.
+package ipaddr
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/util/uint128"
+)
+
+// IPFamily denotes the address family of an IPAddr. The values are the ones
+// returned by family(), and IPv4 addresses sort before IPv6 addresses.
+type IPFamily byte
+
+const (
+	// IPv4family is for IPv4 addresses.
+	IPv4family IPFamily = 4
+	// IPv6family is for IPv6 addresses.
+	IPv6family IPFamily = 6
+)
+
+// IPAddr is an IPv4 or IPv6 address along with the length of its netmask.
+// IPv4 addresses are stored in the low 32 bits of Addr. Bits outside of the
+// netmask (the host bits) are preserved.
+type IPAddr struct {
+	Family IPFamily
+	Addr   uint128.Uint128
+	Mask   byte
+}
+
+// addrLen returns the length in bytes of an address of the given family.
+func (f IPFamily) addrLen() int {
+	if f == IPv4family {
+		return net.IPv4len
+	}
+	return net.IPv6len
+}
+
+// bitLen returns the length in bits of an address of the given family.
+func (f IPFamily) bitLen() byte {
+	return byte(f.addrLen() * 8)
+}
+
+// ParseINet parses the textual representation of an INET, i.e. an IPv4 or
+// IPv6 address optionally followed by a slash and the length of the netmask.
+// If the netmask is omitted, the address is assumed to be a single host. As in
+// Postgres, trailing zero octets of an IPv4 address can be omitted when the
+// netmask is given, e.g. 10.1/16.
+func ParseINet(s string) (IPAddr, error) {
+	addrStr, maskStr := s, ""
+	i := strings.IndexByte(s, '/')
+	if i >= 0 {
+		addrStr, maskStr = s[:i], s[i+1:]
+		if n := strings.Count(addrStr, "."); n < 3 && strings.IndexByte(addrStr, ':') < 0 {
+			addrStr += strings.Repeat(".0", 3-n)
+		}
+	}
+	ip := net.ParseIP(addrStr)
+	if ip == nil {
+		return IPAddr{}, errors.Errorf("invalid IP address: %q", addrStr)
+	}
+	var ipAddr IPAddr
+	if strings.IndexByte(addrStr, ':') < 0 {
+		ipAddr.Family = IPv4family
+		ipAddr.Addr = uint128.FromInts(0, uint64(binary.BigEndian.Uint32(ip.To4())))
+	} else {
+		ipAddr.Family = IPv6family
+		ipAddr.Addr = uint128.FromBytes(ip.To16())
+	}
+	ipAddr.Mask = ipAddr.Family.bitLen()
+	if i >= 0 {
+		mask, err := strconv.ParseUint(maskStr, 10, 8)
+		if err != nil || byte(mask) > ipAddr.Family.bitLen() {
+			return IPAddr{}, errors.Errorf("invalid netmask: %q", maskStr)
+		}
+		ipAddr.Mask = byte(mask)
+	}
+	return ipAddr, nil
+}
+
+// FromNetIP returns the IPAddr for the given address and netmask length. The
+// family is determined by the length of the address: 4 bytes for IPv4 and 16
+// bytes for IPv6.
+func FromNetIP(ip net.IP, mask byte) (IPAddr, error) {
+	var ipAddr IPAddr
+	switch len(ip) {
+	case net.IPv4len:
+		ipAddr.Family = IPv4family
+		ipAddr.Addr = uint128.FromInts(0, uint64(binary.BigEndian.Uint32(ip)))
+	case net.IPv6len:
+		ipAddr.Family = IPv6family
+		ipAddr.Addr = uint128.FromBytes(ip)
+	default:
+		return IPAddr{}, errors.Errorf("invalid IP address length: %d", len(ip))
+	}
+	if mask > ipAddr.Family.bitLen() {
+		return IPAddr{}, errors.Errorf("invalid netmask: %d", mask)
+	}
+	ipAddr.Mask = mask
+	return ipAddr, nil
+}
+
+// RandIPAddr generates a random IPv4 or IPv6 address with a random netmask.
+func RandIPAddr(rng *rand.Rand) IPAddr {
+	var ipAddr IPAddr
+	if rng.Intn(2) == 0 {
+		ipAddr.Family = IPv4family
+		ipAddr.Addr = uint128.FromInts(0, uint64(rng.Uint32()))
+	} else {
+		ipAddr.Family = IPv6family
+		ipAddr.Addr = uint128.FromInts(rng.Uint64(), rng.Uint64())
+	}
+	ipAddr.Mask = byte(rng.Intn(int(ipAddr.Family.bitLen()) + 1))
+	return ipAddr
+}
+
+// IP returns the address as a net.IP.
+func (ipAddr IPAddr) IP() net.IP {
+	b := ipAddr.Addr.GetBytes()
+	if ipAddr.Family == IPv4family {
+		return net.IP(b[net.IPv6len-net.IPv4len:])
+	}
+	return net.IP(b)
+}
+
+// Host returns the address without the netmask, as returned by host().
+func (ipAddr IPAddr) Host() string {
+	ip := ipAddr.IP()
+	if ipAddr.Family == IPv6family {
+		if v4 := ip.To4(); v4 != nil {
+			// net.IP prints IPv4-mapped IPv6 addresses as plain IPv4 addresses,
+			// which would lose the family.
+			return "::ffff:" + v4.String()
+		}
+	}
+	return ip.String()
+}
+
+// String implements the fmt.Stringer interface. The netmask is omitted if the
+// address is a single host.
+func (ipAddr IPAddr) String() string {
+	if ipAddr.Mask == ipAddr.Family.bitLen() {
+		return ipAddr.Host()
+	}
+	return ipAddr.Host() + "/" + strconv.Itoa(int(ipAddr.Mask))
+}
+
+// lowBits returns a Uint128 with the lowest n bits set.
+func lowBits(n byte) uint128.Uint128 {
+	switch {
+	case n == 0:
+		return uint128.Uint128{}
+	case n < 64:
+		return uint128.FromInts(0, 1<<n-1)
+	case n < 128:
+		return uint128.FromInts(1<<(n-64)-1, 1<<64-1)
+	default:
+		return uint128.FromInts(1<<64-1, 1<<64-1)
+	}
+}
+
+// hostmask returns the bits of an address of the given family that lie
+// outside of a netmask of the given length.
+func (f IPFamily) hostmask(mask byte) uint128.Uint128 {
+	return lowBits(f.bitLen() - mask)
+}
+
+// netmask returns the bits of an address of the given family that lie inside
+// a netmask of the given length.
+func (f IPFamily) netmask(mask byte) uint128.Uint128 {
+	return lowBits(f.bitLen()).Xor(f.hostmask(mask))
+}
+
+// Network returns the network part of the address, i.e. the address with all
+// of its host bits cleared.
+func (ipAddr IPAddr) Network() IPAddr {
+	ipAddr.Addr = ipAddr.Addr.And(ipAddr.Family.netmask(ipAddr.Mask))
+	return ipAddr
+}
+
+// Broadcast returns the broadcast address of the network, i.e. the address
+// with all of its host bits set.
+func (ipAddr IPAddr) Broadcast() IPAddr {
+	ipAddr.Addr = ipAddr.Addr.Or(ipAddr.Family.hostmask(ipAddr.Mask))
+	return ipAddr
+}
+
+// Compare returns -1, 0 or 1 if ipAddr sorts before, the same as or after
+// other. Addresses sort by family, then by network part, then by netmask
+// length and finally by host bits, which is the order used by Postgres.
+func (ipAddr IPAddr) Compare(other IPAddr) int {
+	if ipAddr.Family != other.Family {
+		if ipAddr.Family < other.Family {
+			return -1
+		}
+		return 1
+	}
+	if c := ipAddr.Network().Addr.Compare(other.Network().Addr); c != 0 {
+		return c
+	}
+	if ipAddr.Mask != other.Mask {
+		if ipAddr.Mask < other.Mask {
+			return -1
+		}
+		return 1
+	}
+	return ipAddr.Addr.Compare(other.Addr)
+}
+
+// ContainsOrEquals returns whether the network of ipAddr contains, or is equal
+// to, the network of other.
+func (ipAddr IPAddr) ContainsOrEquals(other IPAddr) bool {
+	if ipAddr.Family != other.Family || ipAddr.Mask > other.Mask {
+		return false
+	}
+	netmask := ipAddr.Family.netmask(ipAddr.Mask)
+	return ipAddr.Addr.And(netmask).Equal(other.Addr.And(netmask))
+}
+
+// Contains returns whether the network of ipAddr strictly contains the
+// network of other.
+func (ipAddr IPAddr) Contains(other IPAddr) bool {
+	return ipAddr.Mask < other.Mask && ipAddr.ContainsOrEquals(other)
+}
+
+// ContainedByOrEquals returns whether the network of ipAddr is contained by,
+// or is equal to, the network of other.
+func (ipAddr IPAddr) ContainedByOrEquals(other IPAddr) bool {
+	return other.ContainsOrEquals(ipAddr)
+}
+
+// ContainedBy returns whether the network of ipAddr is strictly contained by
+// the network of other.
+func (ipAddr IPAddr) ContainedBy(other IPAddr) bool {
+	return other.Contains(ipAddr)
+}
+
+// appendAddr appends the big-endian bytes of the given address, 4 of them for
+// IPv4 and 16 for IPv6.
+func (f IPFamily) appendAddr(appendTo []byte, addr uint128.Uint128) []byte {
+	return append(appendTo, addr.GetBytes()[net.IPv6len-f.addrLen():]...)
+}
+
+// readAddr reads an address of the given family written by appendAddr.
+func (f IPFamily) readAddr(b []byte) (uint128.Uint128, []byte, error) {
+	n := f.addrLen()
+	if len(b) < n {
+		return uint128.Uint128{}, nil, errors.Errorf("insufficient bytes to decode IP address: %d", len(b))
+	}
+	var buf [net.IPv6len]byte
+	copy(buf[net.IPv6len-n:], b[:n])
+	return uint128.FromBytes(buf[:]), b[n:], nil
+}
+
+func checkFamily(f IPFamily) error {
+	if f != IPv4family && f != IPv6family {
+		return errors.Errorf("unknown IP family: %d", f)
+	}
+	return nil
+}
+
+// ToBuffer appends the compact binary representation of the address to
+// appendTo: the family, the netmask length and then the address bytes.
+func (ipAddr IPAddr) ToBuffer(appendTo []byte) []byte {
+	appendTo = append(appendTo, byte(ipAddr.Family), ipAddr.Mask)
+	return ipAddr.Family.appendAddr(appendTo, ipAddr.Addr)
+}
+
+// FromBuffer decodes an address written by ToBuffer, returning the remaining
+// bytes.
+func FromBuffer(b []byte) (IPAddr, []byte, error) {
+	if len(b) < 2 {
+		return IPAddr{}, nil, errors.Errorf("insufficient bytes to decode IP address: %d", len(b))
+	}
+	ipAddr := IPAddr{Family: IPFamily(b[0]), Mask: b[1]}
+	if err := checkFamily(ipAddr.Family); err != nil {
+		return IPAddr{}, nil, err
+	}
+	var err error
+	ipAddr.Addr, b, err = ipAddr.Family.readAddr(b[2:])
+	return ipAddr, b, err
+}
+
+// ToKeyBuffer appends a binary representation of the address to appendTo
+// whose bytewise ordering matches Compare: the family, the network part, the
+// netmask length and then the full address.
+func (ipAddr IPAddr) ToKeyBuffer(appendTo []byte) []byte {
+	appendTo = append(appendTo, byte(ipAddr.Family))
+	appendTo = ipAddr.Family.appendAddr(appendTo, ipAddr.Network().Addr)
+	appendTo = append(appendTo, ipAddr.Mask)
+	return ipAddr.Family.appendAddr(appendTo, ipAddr.Addr)
+}
+
+// FromKeyBuffer decodes an address written by ToKeyBuffer.
+func FromKeyBuffer(b []byte) (IPAddr, error) {
+	if len(b) < 1 {
+		return IPAddr{}, errors.Errorf("insufficient bytes to decode IP address: %d", len(b))
+	}
+	ipAddr := IPAddr{Family: IPFamily(b[0])}
+	if err := checkFamily(ipAddr.Family); err != nil {
+		return IPAddr{}, err
+	}
+	n := ipAddr.Family.addrLen()
+	b = b[1:]
+	if len(b) != 2*n+1 {
+		return IPAddr{}, errors.Errorf("invalid IP address key of length %d", len(b))
+	}
+	ipAddr.Mask = b[n]
+	var err error
+	ipAddr.Addr, _, err = ipAddr.Family.readAddr(b[n+1:])
+	return ipAddr, err
+}
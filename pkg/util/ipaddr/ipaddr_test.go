@@ -0,0 +1,178 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ipaddr
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseINet(t *testing.T) {
+	testCases := []struct {
+		in       string
+		expected string
+		family   IPFamily
+		mask     byte
+	}{
+		{"192.168.1.2", "192.168.1.2", IPv4family, 32},
+		{"192.168.1.2/24", "192.168.1.2/24", IPv4family, 24},
+		{"10.0.0.0/8", "10.0.0.0/8", IPv4family, 8},
+		{"0.0.0.0/0", "0.0.0.0/0", IPv4family, 0},
+		{"::1", "::1", IPv6family, 128},
+		{"2001:db8::1/64", "2001:db8::1/64", IPv6family, 64},
+		{"2001:DB8:0:0:0:0:0:1", "2001:db8::1", IPv6family, 128},
+		{"::ffff:1.2.3.4", "::ffff:1.2.3.4", IPv6family, 128},
+		{"::ffff:1.2.3.4/100", "::ffff:1.2.3.4/100", IPv6family, 100},
+		{"192.168.1/24", "192.168.1.0/24", IPv4family, 24},
+		{"10/8", "10.0.0.0/8", IPv4family, 8},
+	}
+	for _, tc := range testCases {
+		ipAddr, err := ParseINet(tc.in)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", tc.in, err)
+		}
+		if s := ipAddr.String(); s != tc.expected {
+			t.Errorf("%s: expected %s, got %s", tc.in, tc.expected, s)
+		}
+		if ipAddr.Family != tc.family || ipAddr.Mask != tc.mask {
+			t.Errorf("%s: expected family %d and mask %d, got %d and %d",
+				tc.in, tc.family, tc.mask, ipAddr.Family, ipAddr.Mask)
+		}
+	}
+}
+
+func TestParseINetError(t *testing.T) {
+	for _, s := range []string{
+		"", "1.2.3", "1.2.3/", "1.2.3.4.5", "256.0.0.1", "1.2.3.4/33", "::1/129", "1.2.3.4/-1", "1.2.3.4/", "abc", ":::",
+	} {
+		if _, err := ParseINet(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
+
+func mustParse(t *testing.T, s string) IPAddr {
+	ipAddr, err := ParseINet(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ipAddr
+}
+
+func TestCompare(t *testing.T) {
+	// Each address sorts strictly before the next one.
+	ordered := []string{
+		"0.0.0.0/0",
+		"10.0.0.0/8",
+		"10.2.0.0/8",
+		"10.1.0.0/16",
+		"10.1.0.1/16",
+		"10.1.0.0",
+		"10.1.0.1",
+		"11.0.0.0/8",
+		"255.255.255.255",
+		"::/0",
+		"::1",
+		"2001:db8::/32",
+		"2001:db8::1",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			a, b := mustParse(t, ordered[i]), mustParse(t, ordered[j])
+			expected := 0
+			if i < j {
+				expected = -1
+			} else if i > j {
+				expected = 1
+			}
+			if c := a.Compare(b); c != expected {
+				t.Errorf("%s cmp %s: expected %d, got %d", a, b, expected, c)
+			}
+			if c := bytes.Compare(a.ToKeyBuffer(nil), b.ToKeyBuffer(nil)); c != expected {
+				t.Errorf("%s cmp %s: expected key comparison %d, got %d", a, b, expected, c)
+			}
+		}
+	}
+}
+
+func TestContains(t *testing.T) {
+	testCases := []struct {
+		a, b             string
+		contains, equals bool
+	}{
+		{"10.0.0.0/8", "10.1.2.3", true, false},
+		{"10.0.0.0/8", "10.1.0.0/16", true, false},
+		{"10.0.0.0/8", "10.0.0.0/8", false, true},
+		{"10.1.2.3/8", "10.0.0.0/8", false, true},
+		{"10.0.0.0/8", "11.0.0.0/16", false, false},
+		{"10.1.0.0/16", "10.0.0.0/8", false, false},
+		{"0.0.0.0/0", "255.255.255.255", true, false},
+		{"2001:db8::/32", "2001:db8::1", true, false},
+		{"::/0", "10.0.0.0", false, false},
+	}
+	for _, tc := range testCases {
+		a, b := mustParse(t, tc.a), mustParse(t, tc.b)
+		if r := a.Contains(b); r != tc.contains {
+			t.Errorf("%s >> %s: expected %t, got %t", a, b, tc.contains, r)
+		}
+		if r := b.ContainedBy(a); r != tc.contains {
+			t.Errorf("%s << %s: expected %t, got %t", b, a, tc.contains, r)
+		}
+		if r := a.ContainsOrEquals(b); r != (tc.contains || tc.equals) {
+			t.Errorf("%s >>= %s: expected %t, got %t", a, b, tc.contains || tc.equals, r)
+		}
+		if r := b.ContainedByOrEquals(a); r != (tc.contains || tc.equals) {
+			t.Errorf("%s <<= %s: expected %t, got %t", b, a, tc.contains || tc.equals, r)
+		}
+	}
+}
+
+func TestBroadcast(t *testing.T) {
+	testCases := []struct {
+		in, expected string
+	}{
+		{"192.168.1.5/24", "192.168.1.255/24"},
+		{"10.0.0.0/8", "10.255.255.255/8"},
+		{"1.2.3.4", "1.2.3.4"},
+		{"0.0.0.0/0", "255.255.255.255/0"},
+		{"2001:db8::/32", "2001:db8:ffff:ffff:ffff:ffff:ffff:ffff/32"},
+		{"2001:db8::/96", "2001:db8::ffff:ffff/96"},
+	}
+	for _, tc := range testCases {
+		if s := mustParse(t, tc.in).Broadcast().String(); s != tc.expected {
+			t.Errorf("broadcast(%s): expected %s, got %s", tc.in, tc.expected, s)
+		}
+	}
+}
+
+func TestBufferRoundTrip(t *testing.T) {
+	for _, s := range []string{"0.0.0.0/0", "1.2.3.4", "10.1.2.3/8", "::", "2001:db8::1/64", "::ffff:1.2.3.4"} {
+		ipAddr := mustParse(t, s)
+		res, rem, err := FromBuffer(ipAddr.ToBuffer([]byte(nil)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rem) != 0 || res != ipAddr {
+			t.Errorf("%s: expected %s, got %s (remaining %v)", s, ipAddr, res, rem)
+		}
+		res, err = FromKeyBuffer(ipAddr.ToKeyBuffer(nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res != ipAddr {
+			t.Errorf("%s: expected %s, got %s", s, ipAddr, res)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package json
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+)
+
+// The binary encoding of a JSON document starts with one of these tags,
+// followed by:
+// - nothing for null, false and true;
+// - the length-prefixed bytes of strings;
+// - the nonsorting encoding of the decimal of numbers;
+// - the number of elements of arrays, followed by each element;
+// - the number of pairs of objects, followed by the length-prefixed key and
+//   the value of each pair, in the order in which they are stored.
+const (
+	nullTag   byte = 0x00
+	falseTag  byte = 0x01
+	trueTag   byte = 0x02
+	stringTag byte = 0x03
+	numberTag byte = 0x04
+	arrayTag  byte = 0x05
+	objectTag byte = 0x06
+)
+
+// EncodeJSON appends the binary encoding of j to appendTo and returns the
+// final buffer. This is the encoding stored in the values of JSONB columns.
+func EncodeJSON(appendTo []byte, j JSON) []byte {
+	return j.encode(appendTo)
+}
+
+// DecodeJSON decodes a document encoded by EncodeJSON, returning the remaining
+// bytes.
+func DecodeJSON(b []byte) ([]byte, JSON, error) {
+	if len(b) == 0 {
+		return b, nil, errors.New("insufficient bytes to decode JSON")
+	}
+	tag := b[0]
+	b = b[1:]
+	switch tag {
+	case nullTag:
+		return b, NullJSONValue, nil
+	case falseTag:
+		return b, FalseJSONValue, nil
+	case trueTag:
+		return b, TrueJSONValue, nil
+	case stringTag:
+		b, data, err := encoding.DecodeUntaggedBytesValue(b)
+		if err != nil {
+			return b, nil, err
+		}
+		return b, jsonString(data), nil
+	case numberTag:
+		b, d, err := encoding.DecodeUntaggedDecimalValue(b)
+		if err != nil {
+			return b, nil, err
+		}
+		return b, FromDecimal(d), nil
+	case arrayTag:
+		b, _, n, err := encoding.DecodeNonsortingUvarint(b)
+		if err != nil {
+			return b, nil, err
+		}
+		arr := make(jsonArray, n)
+		for i := range arr {
+			if b, arr[i], err = DecodeJSON(b); err != nil {
+				return b, nil, err
+			}
+		}
+		return b, arr, nil
+	case objectTag:
+		b, _, n, err := encoding.DecodeNonsortingUvarint(b)
+		if err != nil {
+			return b, nil, err
+		}
+		obj := make(jsonObject, n)
+		for i := range obj {
+			var k []byte
+			if b, k, err = encoding.DecodeUntaggedBytesValue(b); err != nil {
+				return b, nil, err
+			}
+			obj[i].k = jsonString(k)
+			if b, obj[i].v, err = DecodeJSON(b); err != nil {
+				return b, nil, err
+			}
+		}
+		return b, obj, nil
+	default:
+		return b, nil, errors.Errorf("unknown JSON tag %d", tag)
+	}
+}
+
+func (jsonNull) encode(appendTo []byte) []byte  { return append(appendTo, nullTag) }
+func (jsonFalse) encode(appendTo []byte) []byte { return append(appendTo, falseTag) }
+func (jsonTrue) encode(appendTo []byte) []byte  { return append(appendTo, trueTag) }
+
+func (j jsonString) encode(appendTo []byte) []byte {
+	appendTo = append(appendTo, stringTag)
+	return encoding.EncodeUntaggedBytesValue(appendTo, []byte(j))
+}
+
+func (j *jsonNumber) encode(appendTo []byte) []byte {
+	appendTo = append(appendTo, numberTag)
+	return encoding.EncodeUntaggedDecimalValue(appendTo, j.decimal())
+}
+
+func (j jsonArray) encode(appendTo []byte) []byte {
+	appendTo = append(appendTo, arrayTag)
+	appendTo = encoding.EncodeNonsortingUvarint(appendTo, uint64(len(j)))
+	for _, e := range j {
+		appendTo = e.encode(appendTo)
+	}
+	return appendTo
+}
+
+func (j jsonObject) encode(appendTo []byte) []byte {
+	appendTo = append(appendTo, objectTag)
+	appendTo = encoding.EncodeNonsortingUvarint(appendTo, uint64(len(j)))
+	for _, kv := range j {
+		appendTo = encoding.EncodeUntaggedBytesValue(appendTo, []byte(kv.k))
+		appendTo = kv.v.encode(appendTo)
+	}
+	return appendTo
+}
+
+// EncodeInvertedIndexKeys returns the keys of the entries of an inverted index
+// for j, each one prefixed by b. There is one key per distinct path from the
+// root of the document to one of its leaves, i.e. its scalars and empty
+// containers (see encoding.EncodeJSONObjectKeyAscending). The keys are sorted
+// and unique.
+func EncodeInvertedIndexKeys(b []byte, j JSON) [][]byte {
+	return uniqueKeys(j.encodeInvertedIndexKeys(nil, b, true /* emptyContainers */))
+}
+
+// InvertedIndexSpanKeys returns the keys, each one prefixed by b, for which
+// all the documents containing j (in the sense of Contains) have an inverted
+// index entry. The paths ending with empty containers are excluded, as a
+// document can contain an empty container without having an entry for it.
+// No key is returned if j is a scalar, as the top-level arrays which contain
+// it do not have an entry for its path either.
+func InvertedIndexSpanKeys(b []byte, j JSON) [][]byte {
+	if isScalar(j) {
+		return nil
+	}
+	return uniqueKeys(j.encodeInvertedIndexKeys(nil, b, false /* emptyContainers */))
+}
+
+func uniqueKeys(keys [][]byte) [][]byte {
+	sort.Slice(keys, func(i, k int) bool { return bytes.Compare(keys[i], keys[k]) < 0 })
+	unique := keys[:0]
+	for i := range keys {
+		if i == 0 || !bytes.Equal(keys[i], keys[i-1]) {
+			unique = append(unique, keys[i])
+		}
+	}
+	return unique
+}
+
+// clipCap returns b with its capacity limited to its length, so that the
+// keys of the different paths sharing the prefix b do not share storage.
+func clipCap(b []byte) []byte {
+	return b[:len(b):len(b)]
+}
+
+func (jsonNull) encodeInvertedIndexKeys(keys [][]byte, b []byte, _ bool) [][]byte {
+	return append(keys, encoding.EncodeNullAscending(clipCap(b)))
+}
+
+func (jsonFalse) encodeInvertedIndexKeys(keys [][]byte, b []byte, _ bool) [][]byte {
+	return append(keys, encoding.EncodeJSONBoolAscending(clipCap(b), false))
+}
+
+func (jsonTrue) encodeInvertedIndexKeys(keys [][]byte, b []byte, _ bool) [][]byte {
+	return append(keys, encoding.EncodeJSONBoolAscending(clipCap(b), true))
+}
+
+func (j jsonString) encodeInvertedIndexKeys(keys [][]byte, b []byte, _ bool) [][]byte {
+	return append(keys, encoding.EncodeStringAscending(clipCap(b), string(j)))
+}
+
+func (j *jsonNumber) encodeInvertedIndexKeys(keys [][]byte, b []byte, _ bool) [][]byte {
+	return append(keys, encoding.EncodeDecimalAscending(clipCap(b), j.decimal()))
+}
+
+func (j jsonArray) encodeInvertedIndexKeys(
+	keys [][]byte, b []byte, emptyContainers bool,
+) [][]byte {
+	if len(j) == 0 {
+		if !emptyContainers {
+			return keys
+		}
+		return append(keys, encoding.EncodeJSONEmptyArrayAscending(clipCap(b)))
+	}
+	prefix := encoding.EncodeJSONArrayElementAscending(clipCap(b))
+	for _, e := range j {
+		keys = e.encodeInvertedIndexKeys(keys, prefix, emptyContainers)
+	}
+	return keys
+}
+
+func (j jsonObject) encodeInvertedIndexKeys(
+	keys [][]byte, b []byte, emptyContainers bool,
+) [][]byte {
+	if len(j) == 0 {
+		if !emptyContainers {
+			return keys
+		}
+		return append(keys, encoding.EncodeJSONEmptyObjectAscending(clipCap(b)))
+	}
+	for _, kv := range j {
+		prefix := encoding.EncodeJSONObjectKeyAscending(clipCap(b), string(kv.k))
+		keys = kv.v.encodeInvertedIndexKeys(keys, prefix, emptyContainers)
+	}
+	return keys
+}
@@ -0,0 +1,731 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package json implements the JSON documents stored in JSONB columns, along
+// with their binary encoding and the keys of the inverted indexes built on
+// them.
+package json
+
+import (
+	"bytes"
+	gojson "encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"sort"
+	"strings"
+	"unsafe"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/apd"
+)
+
+// Type represents a JSON type.
+type Type int
+
+// The JSON types, in the order in which they sort.
+const (
+	NullJSONType Type = iota
+	StringJSONType
+	NumberJSONType
+	FalseJSONType
+	TrueJSONType
+	ArrayJSONType
+	ObjectJSONType
+)
+
+// String returns the name of the type, as returned by jsonb_typeof().
+func (t Type) String() string {
+	switch t {
+	case NullJSONType:
+		return "null"
+	case StringJSONType:
+		return "string"
+	case NumberJSONType:
+		return "number"
+	case FalseJSONType, TrueJSONType:
+		return "boolean"
+	case ArrayJSONType:
+		return "array"
+	case ObjectJSONType:
+		return "object"
+	default:
+		return fmt.Sprintf("Type(%d)", int(t))
+	}
+}
+
+// JSON represents a JSON document. JSON values are immutable.
+type JSON interface {
+	fmt.Stringer
+
+	// Type returns the type of the document.
+	Type() Type
+
+	// Compare returns -1, 0 or 1 depending on whether the document sorts
+	// before, together with or after other. Like in Postgres, objects sort
+	// after arrays, which sort after booleans, numbers, strings and null.
+	Compare(other JSON) int
+
+	// Format writes the document to buf.
+	Format(buf *bytes.Buffer)
+
+	// Size returns an estimate of the memory used by the document.
+	Size() uintptr
+
+	// FetchValKey implements the `->` operator for strings: it returns the
+	// value of the given key if the document is an object, or nil otherwise.
+	FetchValKey(key string) JSON
+
+	// FetchValIdx implements the `->` operator for integers: it returns the
+	// element at the given position if the document is an array, or nil
+	// otherwise. Negative positions count from the end of the array.
+	FetchValIdx(idx int) JSON
+
+	// Exists implements the `?` operator: it returns whether the given string
+	// is a key of the document if it is an object, an element of the document
+	// if it is an array, or the document itself if it is a string.
+	Exists(key string) bool
+
+	// AsText returns the text representation of the document used by the `->>`
+	// operator: strings are returned without quotes, and null as nil.
+	AsText() *string
+
+	// AsArray returns the elements of the document if it is an array.
+	AsArray() ([]JSON, bool)
+
+	// ObjectKeys returns the keys of the document if it is an object, in the
+	// order in which they are stored.
+	ObjectKeys() ([]string, bool)
+
+	// encode appends the binary encoding of the document to appendTo.
+	encode(appendTo []byte) []byte
+
+	// encodeInvertedIndexKeys appends to keys the inverted index keys of the
+	// document, each one prefixed by b. The paths ending with empty
+	// containers are omitted unless emptyContainers is set.
+	encodeInvertedIndexKeys(keys [][]byte, b []byte, emptyContainers bool) [][]byte
+}
+
+type jsonNull struct{}
+
+type jsonFalse struct{}
+
+type jsonTrue struct{}
+
+type jsonString string
+
+type jsonNumber apd.Decimal
+
+type jsonArray []JSON
+
+type jsonKeyValuePair struct {
+	k jsonString
+	v JSON
+}
+
+// jsonObject is sorted by key (see keyLess), without duplicate keys.
+type jsonObject []jsonKeyValuePair
+
+// NullJSONValue is JSON `null`.
+var NullJSONValue = JSON(jsonNull{})
+
+// FalseJSONValue is JSON `false`.
+var FalseJSONValue = JSON(jsonFalse{})
+
+// TrueJSONValue is JSON `true`.
+var TrueJSONValue = JSON(jsonTrue{})
+
+var _ JSON = jsonNull{}
+var _ JSON = jsonFalse{}
+var _ JSON = jsonTrue{}
+var _ JSON = jsonString("")
+var _ JSON = &jsonNumber{}
+var _ JSON = jsonArray{}
+var _ JSON = jsonObject{}
+
+// FromString returns a JSON string.
+func FromString(s string) JSON {
+	return jsonString(s)
+}
+
+// FromBool returns a JSON boolean.
+func FromBool(b bool) JSON {
+	if b {
+		return TrueJSONValue
+	}
+	return FalseJSONValue
+}
+
+// FromDecimal returns a JSON number.
+func FromDecimal(d apd.Decimal) JSON {
+	n := jsonNumber(d)
+	return &n
+}
+
+// FromInt64 returns a JSON number.
+func FromInt64(i int64) JSON {
+	var d apd.Decimal
+	d.SetInt64(i)
+	return FromDecimal(d)
+}
+
+// FromFloat64 returns a JSON number. NaN and infinite values cannot be
+// represented in JSON and result in an error.
+func FromFloat64(f float64) (JSON, error) {
+	var d apd.Decimal
+	if _, err := d.SetFloat64(f); err != nil {
+		return nil, err
+	}
+	if d.Form != apd.Finite {
+		return nil, errors.Errorf("cannot convert %v to JSON", f)
+	}
+	return FromDecimal(d), nil
+}
+
+// FromArray returns a JSON array with the given elements.
+func FromArray(elems []JSON) JSON {
+	return jsonArray(elems)
+}
+
+// ParseJSON parses the text representation of a JSON document.
+func ParseJSON(s string) (JSON, error) {
+	reader := strings.NewReader(s)
+	decoder := gojson.NewDecoder(reader)
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		if err == io.EOF {
+			return nil, errors.New("unable to decode JSON: empty input")
+		}
+		return nil, errors.Wrap(err, "unable to decode JSON")
+	}
+	// The decoder stops after the first value: anything but whitespace after
+	// it is an error.
+	rest, err := ioutil.ReadAll(io.MultiReader(decoder.Buffered(), reader))
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(rest)) > 0 {
+		return nil, errors.Errorf("unable to decode JSON: trailing data %q", rest)
+	}
+	return MakeJSON(v)
+}
+
+// MakeJSON converts a Go value, as returned by the encoding/json package when
+// decoding into an interface{}, into a JSON document. JSON values may be
+// nested in the maps and slices.
+func MakeJSON(d interface{}) (JSON, error) {
+	switch v := d.(type) {
+	case JSON:
+		return v, nil
+	case nil:
+		return NullJSONValue, nil
+	case bool:
+		return FromBool(v), nil
+	case string:
+		return FromString(v), nil
+	case int:
+		return FromInt64(int64(v)), nil
+	case int64:
+		return FromInt64(v), nil
+	case float64:
+		return FromFloat64(v)
+	case gojson.Number:
+		var dec apd.Decimal
+		if _, _, err := dec.SetString(string(v)); err != nil {
+			return nil, errors.Wrapf(err, "unable to decode JSON number %s", v)
+		}
+		return FromDecimal(dec), nil
+	case []interface{}:
+		elems := make([]JSON, len(v))
+		for i := range v {
+			var err error
+			if elems[i], err = MakeJSON(v[i]); err != nil {
+				return nil, err
+			}
+		}
+		return jsonArray(elems), nil
+	case map[string]interface{}:
+		obj := make(jsonObject, 0, len(v))
+		for k, e := range v {
+			j, err := MakeJSON(e)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, jsonKeyValuePair{k: jsonString(k), v: j})
+		}
+		sort.Sort(obj)
+		return obj, nil
+	default:
+		return nil, errors.Errorf("cannot make JSON from %T", d)
+	}
+}
+
+// keyLess orders the keys of objects like Postgres does: shorter keys first,
+// then bytewise.
+func keyLess(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+func (o jsonObject) Len() int           { return len(o) }
+func (o jsonObject) Swap(i, j int)      { o[i], o[j] = o[j], o[i] }
+func (o jsonObject) Less(i, j int) bool { return keyLess(string(o[i].k), string(o[j].k)) }
+
+func (jsonNull) Type() Type    { return NullJSONType }
+func (jsonFalse) Type() Type   { return FalseJSONType }
+func (jsonTrue) Type() Type    { return TrueJSONType }
+func (jsonString) Type() Type  { return StringJSONType }
+func (*jsonNumber) Type() Type { return NumberJSONType }
+func (jsonArray) Type() Type   { return ArrayJSONType }
+func (jsonObject) Type() Type  { return ObjectJSONType }
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareType compares j and other if they have different types. ok is false
+// if they have the same type.
+func compareType(j, other JSON) (c int, ok bool) {
+	c = cmpInt(int(j.Type()), int(other.Type()))
+	return c, c != 0
+}
+
+func (j jsonNull) Compare(other JSON) int {
+	c, _ := compareType(j, other)
+	return c
+}
+
+func (j jsonFalse) Compare(other JSON) int {
+	c, _ := compareType(j, other)
+	return c
+}
+
+func (j jsonTrue) Compare(other JSON) int {
+	c, _ := compareType(j, other)
+	return c
+}
+
+func (j jsonString) Compare(other JSON) int {
+	if c, ok := compareType(j, other); ok {
+		return c
+	}
+	return strings.Compare(string(j), string(other.(jsonString)))
+}
+
+func (j *jsonNumber) Compare(other JSON) int {
+	if c, ok := compareType(j, other); ok {
+		return c
+	}
+	return j.decimal().Cmp(other.(*jsonNumber).decimal())
+}
+
+// Compare implements the JSON interface. Like in Postgres, longer arrays sort
+// after shorter ones, and arrays of the same length are compared element by
+// element.
+func (j jsonArray) Compare(other JSON) int {
+	if c, ok := compareType(j, other); ok {
+		return c
+	}
+	o := other.(jsonArray)
+	if c := cmpInt(len(j), len(o)); c != 0 {
+		return c
+	}
+	for i := range j {
+		if c := j[i].Compare(o[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// Compare implements the JSON interface. Like in Postgres, objects with more
+// pairs sort after objects with fewer pairs, and objects with the same number
+// of pairs are compared pair by pair, in the order in which they are stored.
+func (j jsonObject) Compare(other JSON) int {
+	if c, ok := compareType(j, other); ok {
+		return c
+	}
+	o := other.(jsonObject)
+	if c := cmpInt(len(j), len(o)); c != 0 {
+		return c
+	}
+	for i := range j {
+		if j[i].k != o[i].k {
+			if keyLess(string(j[i].k), string(o[i].k)) {
+				return -1
+			}
+			return 1
+		}
+		if c := j[i].v.Compare(o[i].v); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func (j *jsonNumber) decimal() *apd.Decimal {
+	return (*apd.Decimal)(j)
+}
+
+func (jsonNull) Format(buf *bytes.Buffer)  { buf.WriteString("null") }
+func (jsonFalse) Format(buf *bytes.Buffer) { buf.WriteString("false") }
+func (jsonTrue) Format(buf *bytes.Buffer)  { buf.WriteString("true") }
+
+func (j jsonString) Format(buf *bytes.Buffer) {
+	encodeJSONString(buf, string(j))
+}
+
+func (j *jsonNumber) Format(buf *bytes.Buffer) {
+	buf.WriteString(j.decimal().Text('f'))
+}
+
+func (j jsonArray) Format(buf *bytes.Buffer) {
+	buf.WriteByte('[')
+	for i, e := range j {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		e.Format(buf)
+	}
+	buf.WriteByte(']')
+}
+
+func (j jsonObject) Format(buf *bytes.Buffer) {
+	buf.WriteByte('{')
+	for i, kv := range j {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		kv.k.Format(buf)
+		buf.WriteString(": ")
+		kv.v.Format(buf)
+	}
+	buf.WriteByte('}')
+}
+
+const hexDigits = "0123456789abcdef"
+
+// encodeJSONString writes s to buf as a JSON string literal. Unlike the
+// encoding/json package, it does not escape the HTML special characters.
+func encodeJSONString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hexDigits[r>>4])
+				buf.WriteByte(hexDigits[r&0xf])
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+func asString(j JSON) string {
+	var buf bytes.Buffer
+	j.Format(&buf)
+	return buf.String()
+}
+
+func (j jsonNull) String() string    { return asString(j) }
+func (j jsonFalse) String() string   { return asString(j) }
+func (j jsonTrue) String() string    { return asString(j) }
+func (j jsonString) String() string  { return asString(j) }
+func (j *jsonNumber) String() string { return asString(j) }
+func (j jsonArray) String() string   { return asString(j) }
+func (j jsonObject) String() string  { return asString(j) }
+
+// Pretty returns the text representation of the document indented over
+// multiple lines, as returned by jsonb_pretty().
+func Pretty(j JSON) string {
+	var buf bytes.Buffer
+	prettyFormat(&buf, j, "")
+	return buf.String()
+}
+
+func prettyFormat(buf *bytes.Buffer, j JSON, indent string) {
+	const indentStep = "    "
+	switch t := j.(type) {
+	case jsonArray:
+		if len(t) == 0 {
+			buf.WriteString("[]")
+			return
+		}
+		buf.WriteString("[\n")
+		for i, e := range t {
+			if i != 0 {
+				buf.WriteString(",\n")
+			}
+			buf.WriteString(indent + indentStep)
+			prettyFormat(buf, e, indent+indentStep)
+		}
+		buf.WriteString("\n" + indent + "]")
+	case jsonObject:
+		if len(t) == 0 {
+			buf.WriteString("{}")
+			return
+		}
+		buf.WriteString("{\n")
+		for i, kv := range t {
+			if i != 0 {
+				buf.WriteString(",\n")
+			}
+			buf.WriteString(indent + indentStep)
+			kv.k.Format(buf)
+			buf.WriteString(": ")
+			prettyFormat(buf, kv.v, indent+indentStep)
+		}
+		buf.WriteString("\n" + indent + "}")
+	default:
+		j.Format(buf)
+	}
+}
+
+func (jsonNull) Size() uintptr  { return 0 }
+func (jsonFalse) Size() uintptr { return 0 }
+func (jsonTrue) Size() uintptr  { return 0 }
+
+func (j jsonString) Size() uintptr {
+	return unsafe.Sizeof(j) + uintptr(len(j))
+}
+
+func (j *jsonNumber) Size() uintptr {
+	intVal := j.Coeff
+	return unsafe.Sizeof(*j) + uintptr(cap(intVal.Bits()))*unsafe.Sizeof(big.Word(0))
+}
+
+func (j jsonArray) Size() uintptr {
+	valSize := uintptr(0)
+	for _, elem := range j {
+		valSize += elem.Size()
+	}
+	return unsafe.Sizeof(j) + uintptr(cap(j))*unsafe.Sizeof(JSON(nil)) + valSize
+}
+
+func (j jsonObject) Size() uintptr {
+	valSize := uintptr(0)
+	for _, kv := range j {
+		valSize += kv.k.Size() + kv.v.Size()
+	}
+	return unsafe.Sizeof(j) + uintptr(cap(j))*unsafe.Sizeof(jsonKeyValuePair{}) + valSize
+}
+
+func (jsonNull) FetchValKey(string) JSON    { return nil }
+func (jsonFalse) FetchValKey(string) JSON   { return nil }
+func (jsonTrue) FetchValKey(string) JSON    { return nil }
+func (jsonString) FetchValKey(string) JSON  { return nil }
+func (*jsonNumber) FetchValKey(string) JSON { return nil }
+func (jsonArray) FetchValKey(string) JSON   { return nil }
+
+func (j jsonObject) FetchValKey(key string) JSON {
+	i := sort.Search(len(j), func(i int) bool { return !keyLess(string(j[i].k), key) })
+	if i < len(j) && string(j[i].k) == key {
+		return j[i].v
+	}
+	return nil
+}
+
+func (jsonNull) FetchValIdx(int) JSON    { return nil }
+func (jsonFalse) FetchValIdx(int) JSON   { return nil }
+func (jsonTrue) FetchValIdx(int) JSON    { return nil }
+func (jsonString) FetchValIdx(int) JSON  { return nil }
+func (*jsonNumber) FetchValIdx(int) JSON { return nil }
+func (jsonObject) FetchValIdx(int) JSON  { return nil }
+
+func (j jsonArray) FetchValIdx(idx int) JSON {
+	if idx < 0 {
+		idx = len(j) + idx
+	}
+	if idx >= 0 && idx < len(j) {
+		return j[idx]
+	}
+	return nil
+}
+
+func (jsonNull) Exists(string) bool    { return false }
+func (jsonFalse) Exists(string) bool   { return false }
+func (jsonTrue) Exists(string) bool    { return false }
+func (*jsonNumber) Exists(string) bool { return false }
+
+func (j jsonString) Exists(key string) bool {
+	return string(j) == key
+}
+
+func (j jsonArray) Exists(key string) bool {
+	for _, e := range j {
+		if s, ok := e.(jsonString); ok && string(s) == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (j jsonObject) Exists(key string) bool {
+	return j.FetchValKey(key) != nil
+}
+
+func (jsonNull) AsText() *string { return nil }
+
+func (j jsonString) AsText() *string {
+	s := string(j)
+	return &s
+}
+
+func (j jsonFalse) AsText() *string   { s := j.String(); return &s }
+func (j jsonTrue) AsText() *string    { s := j.String(); return &s }
+func (j *jsonNumber) AsText() *string { s := j.String(); return &s }
+func (j jsonArray) AsText() *string   { s := j.String(); return &s }
+func (j jsonObject) AsText() *string  { s := j.String(); return &s }
+
+func (jsonNull) AsArray() ([]JSON, bool)    { return nil, false }
+func (jsonFalse) AsArray() ([]JSON, bool)   { return nil, false }
+func (jsonTrue) AsArray() ([]JSON, bool)    { return nil, false }
+func (jsonString) AsArray() ([]JSON, bool)  { return nil, false }
+func (*jsonNumber) AsArray() ([]JSON, bool) { return nil, false }
+func (jsonObject) AsArray() ([]JSON, bool)  { return nil, false }
+func (j jsonArray) AsArray() ([]JSON, bool) { return j, true }
+
+func (jsonNull) ObjectKeys() ([]string, bool)    { return nil, false }
+func (jsonFalse) ObjectKeys() ([]string, bool)   { return nil, false }
+func (jsonTrue) ObjectKeys() ([]string, bool)    { return nil, false }
+func (jsonString) ObjectKeys() ([]string, bool)  { return nil, false }
+func (*jsonNumber) ObjectKeys() ([]string, bool) { return nil, false }
+func (jsonArray) ObjectKeys() ([]string, bool)   { return nil, false }
+
+func (j jsonObject) ObjectKeys() ([]string, bool) {
+	keys := make([]string, len(j))
+	for i, kv := range j {
+		keys[i] = string(kv.k)
+	}
+	return keys, true
+}
+
+// FetchPath returns the value found by following the given path of object
+// keys and array positions from the root of the document, or nil if there is
+// no such value. It implements jsonb_extract_path() and the `#>` operator.
+func FetchPath(j JSON, path []string) JSON {
+	for _, p := range path {
+		if j == nil {
+			return nil
+		}
+		switch j.Type() {
+		case ObjectJSONType:
+			j = j.FetchValKey(p)
+		case ArrayJSONType:
+			var idx int
+			if _, err := fmt.Sscanf(p, "%d", &idx); err != nil {
+				return nil
+			}
+			j = j.FetchValIdx(idx)
+		default:
+			return nil
+		}
+	}
+	return j
+}
+
+func isScalar(j JSON) bool {
+	switch j.Type() {
+	case ArrayJSONType, ObjectJSONType:
+		return false
+	default:
+		return true
+	}
+}
+
+// Contains implements the `@>` operator: it returns whether a contains b.
+//
+// Like in Postgres, an object contains another object if all the keys of the
+// latter are present in the former with a value which contains their value
+// in the latter, and an array contains another array if each element of the
+// latter is contained in some element of the former. Scalars only contain
+// equal scalars, except that an array contains the scalars which are among
+// its elements, as long as the array is at the top level of the document.
+func Contains(a, b JSON) bool {
+	if arr, ok := a.(jsonArray); ok && isScalar(b) {
+		for _, e := range arr {
+			if e.Compare(b) == 0 {
+				return true
+			}
+		}
+		return false
+	}
+	return contains(a, b)
+}
+
+func contains(a, b JSON) bool {
+	switch t := a.(type) {
+	case jsonObject:
+		o, ok := b.(jsonObject)
+		if !ok {
+			return false
+		}
+		for _, kv := range o {
+			v := t.FetchValKey(string(kv.k))
+			if v == nil || !contains(v, kv.v) {
+				return false
+			}
+		}
+		return true
+	case jsonArray:
+		o, ok := b.(jsonArray)
+		if !ok {
+			return false
+		}
+		for _, be := range o {
+			found := false
+			for _, ae := range t {
+				if contains(ae, be) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	default:
+		return a.Compare(b) == 0
+	}
+}
@@ -0,0 +1,289 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package json
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+)
+
+func mustParse(t *testing.T, s string) JSON {
+	j, err := ParseJSON(s)
+	if err != nil {
+		t.Fatalf("%s: %v", s, err)
+	}
+	return j
+}
+
+func TestParseJSON(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{`null`, `null`},
+		{` true `, `true`},
+		{`false`, `false`},
+		{`1`, `1`},
+		{`-1.50`, `-1.50`},
+		{`1e2`, `100`},
+		{`"a\nb\"c<>"`, `"a\nb\"c<>"`},
+		{`[]`, `[]`},
+		{`[1, "a", [null]]`, `[1, "a", [null]]`},
+		{`{}`, `{}`},
+		// Keys are sorted by length first, and the last duplicate key wins.
+		{`{"bb": 1, "a": 2, "c": {"x": []}, "a": 3}`, `{"a": 3, "c": {"x": []}, "bb": 1}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			j := mustParse(t, tc.input)
+			if s := j.String(); s != tc.expected {
+				t.Fatalf("expected %s, got %s", tc.expected, s)
+			}
+		})
+	}
+
+	errCases := []struct {
+		input string
+		err   string
+	}{
+		{``, `empty input`},
+		{`{`, `unable to decode JSON`},
+		{`1 2`, `trailing data`},
+		{`[1]]`, `trailing data`},
+		{`{a: 1}`, `unable to decode JSON`},
+	}
+	for _, tc := range errCases {
+		t.Run(tc.input, func(t *testing.T) {
+			if _, err := ParseJSON(tc.input); !testutils.IsError(err, tc.err) {
+				t.Fatalf("expected %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestJSONCompare(t *testing.T) {
+	// Each document sorts after the previous one.
+	ordered := []string{
+		`null`,
+		`""`,
+		`"a"`,
+		`"b"`,
+		`-1`,
+		`1`,
+		`1.5`,
+		`false`,
+		`true`,
+		`[]`,
+		`[2]`,
+		`[1, 1]`,
+		`[1, 2]`,
+		`{}`,
+		`{"b": 1}`,
+		`{"b": 2}`,
+		`{"aa": 1}`,
+		`{"a": 1, "b": 1}`,
+	}
+	for i := range ordered {
+		for k := range ordered {
+			a, b := mustParse(t, ordered[i]), mustParse(t, ordered[k])
+			expected := cmpInt(i, k)
+			if c := a.Compare(b); c != expected {
+				t.Errorf("%s vs %s: expected %d, got %d", a, b, expected, c)
+			}
+		}
+	}
+	if c := mustParse(t, `1.0`).Compare(mustParse(t, `1`)); c != 0 {
+		t.Errorf("expected 1.0 and 1 to be equal, got %d", c)
+	}
+}
+
+func TestJSONFetch(t *testing.T) {
+	j := mustParse(t, `{"a": [1, {"b": "c"}], "d": null}`)
+	if v := j.FetchValKey("a"); v == nil || v.String() != `[1, {"b": "c"}]` {
+		t.Errorf("unexpected value for a: %v", v)
+	}
+	if v := j.FetchValKey("d"); v == nil || v.String() != `null` {
+		t.Errorf("unexpected value for d: %v", v)
+	}
+	if v := j.FetchValKey("e"); v != nil {
+		t.Errorf("unexpected value for e: %v", v)
+	}
+	if v := j.FetchValIdx(0); v != nil {
+		t.Errorf("unexpected value for index 0 of an object: %v", v)
+	}
+	arr := j.FetchValKey("a")
+	if v := arr.FetchValIdx(-1); v == nil || v.String() != `{"b": "c"}` {
+		t.Errorf("unexpected value for index -1: %v", v)
+	}
+	if v := arr.FetchValIdx(2); v != nil {
+		t.Errorf("unexpected value for index 2: %v", v)
+	}
+	if v := FetchPath(j, []string{"a", "1", "b"}); v == nil || *v.AsText() != "c" {
+		t.Errorf("unexpected value for path a,1,b: %v", v)
+	}
+	if v := FetchPath(j, []string{"a", "x"}); v != nil {
+		t.Errorf("unexpected value for path a,x: %v", v)
+	}
+	if v := j.FetchValKey("d").AsText(); v != nil {
+		t.Errorf("expected null text to be nil, got %s", *v)
+	}
+	if !j.Exists("a") || j.Exists("b") {
+		t.Errorf("unexpected key existence in %s", j)
+	}
+	if !mustParse(t, `["a", 1]`).Exists("a") || mustParse(t, `["a", 1]`).Exists("1") {
+		t.Errorf("unexpected element existence")
+	}
+}
+
+func TestJSONContains(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected bool
+	}{
+		{`1`, `1`, true},
+		{`1`, `1.0`, true},
+		{`1`, `2`, false},
+		{`"a"`, `["a"]`, false},
+		{`["a", "b"]`, `"a"`, true},
+		{`["a", "b"]`, `"c"`, false},
+		{`[["a"]]`, `"a"`, false},
+		{`[1, 2, 3]`, `[3, 1]`, true},
+		{`[1, 2, 3]`, `[1, 1]`, true},
+		{`[1, 2, 3]`, `[]`, true},
+		{`[1, [2, 3]]`, `[[3]]`, true},
+		{`[1, [2, 3]]`, `[3]`, false},
+		{`{"a": 1, "b": {"c": [1, 2]}}`, `{"b": {"c": [2]}}`, true},
+		{`{"a": 1, "b": {"c": [1, 2]}}`, `{"b": {}}`, true},
+		{`{"a": 1, "b": {"c": [1, 2]}}`, `{"b": {"c": 2}}`, false},
+		{`{"a": 1}`, `{"a": 1, "b": 2}`, false},
+		{`{"a": 1}`, `{}`, true},
+		{`{"a": 1}`, `[]`, false},
+		{`[{"a": 1, "b": 2}]`, `[{"a": 1}]`, true},
+	}
+	for _, tc := range testCases {
+		a, b := mustParse(t, tc.a), mustParse(t, tc.b)
+		if c := Contains(a, b); c != tc.expected {
+			t.Errorf("%s @> %s: expected %t, got %t", a, b, tc.expected, c)
+		}
+	}
+}
+
+func TestJSONEncodeDecode(t *testing.T) {
+	for _, s := range []string{
+		`null`, `true`, `false`, `"foo"`, `""`, `-1.25`, `12345678901234567890`,
+		`[]`, `{}`, `[1, [2, "3"], {"a": null}]`, `{"a": {"b": [true, false]}, "cc": "d"}`,
+	} {
+		t.Run(s, func(t *testing.T) {
+			j := mustParse(t, s)
+			enc := EncodeJSON([]byte("prefix"), j)
+			rest, decoded, err := DecodeJSON(enc[len("prefix"):])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rest) != 0 {
+				t.Fatalf("unexpected remaining bytes %v", rest)
+			}
+			if decoded.Compare(j) != 0 || decoded.String() != j.String() {
+				t.Fatalf("expected %s, got %s", j, decoded)
+			}
+		})
+	}
+}
+
+func TestJSONInvertedIndexKeys(t *testing.T) {
+	prefix := []byte("prefix")
+	testCases := []struct {
+		doc     string
+		numKeys int
+	}{
+		{`null`, 1},
+		{`1`, 1},
+		{`[]`, 1},
+		{`{}`, 1},
+		{`[1, 1, 2]`, 2},
+		{`{"a": [1, {"b": "c"}], "d": {}}`, 3},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.doc, func(t *testing.T) {
+			keys := EncodeInvertedIndexKeys(prefix, mustParse(t, tc.doc))
+			if len(keys) != tc.numKeys {
+				t.Fatalf("expected %d keys, got %d", tc.numKeys, len(keys))
+			}
+			for i, k := range keys {
+				if !bytes.HasPrefix(k, prefix) {
+					t.Fatalf("key %v does not start with the prefix", k)
+				}
+				if i > 0 && bytes.Compare(keys[i-1], k) >= 0 {
+					t.Fatalf("keys are not sorted and unique")
+				}
+			}
+		})
+	}
+
+	// All the documents containing a non-scalar document have an entry for each
+	// of its span keys.
+	containmentCases := []struct {
+		doc, contained string
+	}{
+		{`{"a": [1, {"b": "c"}], "d": {"e": 1}}`, `{"a": [{"b": "c"}]}`},
+		{`{"a": [1, {"b": "c"}], "d": {"e": 1}}`, `{"d": {}, "a": [1]}`},
+		{`[[1, 2], 3]`, `[[2]]`},
+		{`{"a": 1.0}`, `{"a": 1}`},
+	}
+	for _, tc := range containmentCases {
+		doc, contained := mustParse(t, tc.doc), mustParse(t, tc.contained)
+		if !Contains(doc, contained) {
+			t.Fatalf("expected %s to contain %s", doc, contained)
+		}
+		docKeys := EncodeInvertedIndexKeys(prefix, doc)
+		spanKeys := InvertedIndexSpanKeys(prefix, contained)
+		if len(spanKeys) == 0 {
+			t.Fatalf("expected span keys for %s", contained)
+		}
+		for _, k := range spanKeys {
+			found := false
+			for _, dk := range docKeys {
+				if bytes.Equal(k, dk) {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("%s: no entry for span key %v of %s", doc, k, contained)
+			}
+		}
+	}
+
+	if keys := InvertedIndexSpanKeys(prefix, mustParse(t, `"a"`)); len(keys) != 0 {
+		t.Errorf("expected no span keys for a scalar, got %v", keys)
+	}
+	if keys := InvertedIndexSpanKeys(prefix, mustParse(t, `{"a": {}}`)); len(keys) != 0 {
+		t.Errorf("expected no span keys for empty containers, got %v", keys)
+	}
+}
+
+func TestPretty(t *testing.T) {
+	expected := `{
+    "a": [
+        1,
+        {}
+    ],
+    "bb": "c"
+}`
+	if s := Pretty(mustParse(t, `{"bb": "c", "a": [1, {}]}`)); s != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, s)
+	}
+}
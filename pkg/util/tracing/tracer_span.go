@@ -182,6 +182,13 @@ func IsRecordable(os opentracing.Span) bool {
 	return isCockroachSpan
 }
 
+// IsRecording returns true if recording is enabled on the span (either
+// directly or because a parent span is recording).
+func IsRecording(os opentracing.Span) bool {
+	s, ok := os.(*span)
+	return ok && s.isRecording()
+}
+
 // GetRecording retrieves the current recording, if the span has
 // recording enabled. This can be called while spans that are part of the
 // record are still open; it can run concurrently with operations on those
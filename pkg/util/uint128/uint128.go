@@ -59,6 +59,41 @@ func (u Uint128) Sub(n uint64) Uint128 {
 	return Uint128{hi, lo}
 }
 
+// Equal returns whether or not the Uint128 are equivalent.
+func (u Uint128) Equal(o Uint128) bool {
+	return u.Hi == o.Hi && u.Lo == o.Lo
+}
+
+// Compare compares the two Uint128, returning -1, 0 or 1 if u is less than,
+// equal to or greater than o respectively.
+func (u Uint128) Compare(o Uint128) int {
+	if u.Hi > o.Hi {
+		return 1
+	} else if u.Hi < o.Hi {
+		return -1
+	} else if u.Lo > o.Lo {
+		return 1
+	} else if u.Lo < o.Lo {
+		return -1
+	}
+	return 0
+}
+
+// And returns the bitwise AND of u and o.
+func (u Uint128) And(o Uint128) Uint128 {
+	return Uint128{u.Hi & o.Hi, u.Lo & o.Lo}
+}
+
+// Or returns the bitwise OR of u and o.
+func (u Uint128) Or(o Uint128) Uint128 {
+	return Uint128{u.Hi | o.Hi, u.Lo | o.Lo}
+}
+
+// Xor returns the bitwise XOR of u and o.
+func (u Uint128) Xor(o Uint128) Uint128 {
+	return Uint128{u.Hi ^ o.Hi, u.Lo ^ o.Lo}
+}
+
 // FromBytes parses the byte slice as a 128 bit big-endian unsigned integer.
 func FromBytes(b []byte) Uint128 {
 	hi := binary.BigEndian.Uint64(b[:8])
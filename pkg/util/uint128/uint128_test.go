@@ -101,3 +101,40 @@ func TestAdd(t *testing.T) {
 		}
 	}
 }
+
+func TestCompare(t *testing.T) {
+	testData := []struct {
+		a, b     Uint128
+		expected int
+	}{
+		{Uint128{0, 0}, Uint128{0, 0}, 0},
+		{Uint128{0, 1}, Uint128{0, 0}, 1},
+		{Uint128{0, 18446744073709551615}, Uint128{1, 0}, -1},
+		{Uint128{1, 0}, Uint128{0, 18446744073709551615}, 1},
+		{Uint128{5, 3}, Uint128{5, 3}, 0},
+	}
+
+	for _, test := range testData {
+		if res := test.a.Compare(test.b); res != test.expected {
+			t.Errorf("expected: %v cmp %v = %d but got %d", test.a, test.b, test.expected, res)
+		}
+		if res := test.a.Equal(test.b); res != (test.expected == 0) {
+			t.Errorf("expected: %v == %v to be %t but got %t", test.a, test.b, test.expected == 0, res)
+		}
+	}
+}
+
+func TestBitwise(t *testing.T) {
+	a := Uint128{0xF0F0, 0xFF00}
+	b := Uint128{0x0FF0, 0x0F0F}
+
+	if res, expected := a.And(b), (Uint128{0x00F0, 0x0F00}); res != expected {
+		t.Errorf("expected: %v & %v = %v but got %v", a, b, expected, res)
+	}
+	if res, expected := a.Or(b), (Uint128{0xFFF0, 0xFF0F}); res != expected {
+		t.Errorf("expected: %v | %v = %v but got %v", a, b, expected, res)
+	}
+	if res, expected := a.Xor(b), (Uint128{0xFF00, 0xF00F}); res != expected {
+		t.Errorf("expected: %v ^ %v = %v but got %v", a, b, expected, res)
+	}
+}